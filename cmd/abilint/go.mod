@@ -0,0 +1,3 @@
+module wasmbench/abilint
+
+go 1.25.0
@@ -0,0 +1,123 @@
+// Package lint checks a task module against the task ABI before any runtime
+// loads it: that it exports the functions a run needs, that every ABI
+// function it exports has the ABI's signature, and that it exports a 32-bit
+// linear memory. A module that fails would otherwise fail later and less
+// clearly, in a call with the wrong arguments or in a trap mid-run.
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Required are the exports every runner calls
+var Required = []string{"init", "alloc", "run_task"}
+
+// ABI is the signature of each function of the task ABI, as configs/tasks.json
+// records the TinyGo builds' exports. The Rust modules export a subset.
+var ABI = map[string]Signature{
+	"abi_version":            {nil, []string{"i32"}},
+	"alloc_uninitialized":    {[]string{"i32"}, []string{"i32"}},
+	"alloc":                  {[]string{"i32"}, []string{"i32"}},
+	"dealloc":                {[]string{"i32"}, nil},
+	"get_cancel_ptr":         {nil, []string{"i32"}},
+	"get_checkpoints":        {nil, []string{"i32"}},
+	"get_error_code":         {nil, []string{"i32"}},
+	"get_last_error_len":     {nil, []string{"i32"}},
+	"get_last_error_ptr":     {nil, []string{"i32"}},
+	"get_limits":             {nil, []string{"i32"}},
+	"get_max_memory_pages":   {nil, []string{"i32"}},
+	"get_memory_stats":       {nil, []string{"i32"}},
+	"get_output":             {nil, []string{"i32"}},
+	"get_panic_len":          {nil, []string{"i32"}},
+	"get_panic_ptr":          {nil, []string{"i32"}},
+	"get_result_ptr":         {nil, []string{"i32"}},
+	"get_scale_factor":       {nil, []string{"i32"}},
+	"get_task_info":          {nil, []string{"i32"}},
+	"get_work_metrics":       {nil, []string{"i32"}},
+	"has_simd":               {nil, []string{"i32"}},
+	"has_threads":            {nil, []string{"i32"}},
+	"hash_input":             {nil, []string{"i32"}},
+	"init":                   {[]string{"i32"}, nil},
+	"init64":                 {[]string{"i64"}, nil},
+	"params_fingerprint":     {nil, []string{"i32"}},
+	"reserve_memory":         {[]string{"i32"}, []string{"i32"}},
+	"reset_arena":            {nil, nil},
+	"reset_iteration_buffer": {nil, nil},
+	"run_task":               {[]string{"i32"}, []string{"i32"}},
+	"run_task64":             {[]string{"i32"}, []string{"i64"}},
+	"run_task_packed":        {[]string{"i32"}, []string{"i64"}},
+	"run_task_timed":         {[]string{"i32", "i32"}, []string{"i32"}},
+	"run_task_v2":            {[]string{"i32", "i32"}, []string{"i32"}},
+	"self_test":              {nil, []string{"i32"}},
+	"set_checkpoints":        {[]string{"i32"}, nil},
+	"set_memory_budget":      {[]string{"i32"}, nil},
+	"set_output":             {[]string{"i32"}, nil},
+	"set_thread_count":       {[]string{"i32"}, []string{"i32"}},
+	"validate_params":        {[]string{"i32"}, []string{"i32"}},
+}
+
+// pairs are exports a host only uses together, a pointer and its length
+var pairs = [][2]string{
+	{"get_last_error_ptr", "get_last_error_len"},
+	{"get_panic_ptr", "get_panic_len"},
+}
+
+// Check returns the ways m breaks the ABI, in a fixed order, none for a
+// module a runner can load
+func Check(m *Module) []string {
+	var problems []string
+	for _, name := range Required {
+		if _, ok := m.Exports[name]; !ok {
+			problems = append(problems, "missing export "+name)
+		}
+	}
+	if _, ok := m.Exports["_start"]; ok && len(problems) > 0 {
+		problems = append(problems, "it exports _start (a WASI command build?)")
+	}
+
+	if memory, ok := m.Exports["memory"]; !ok {
+		problems = append(problems, "missing export memory; the host writes the params into it")
+	} else if memory.Kind != externKinds[kindMemory] {
+		problems = append(problems, fmt.Sprintf("export memory is a %s, not a memory", memory.Kind))
+	} else if int(memory.index) < len(m.Memories) && m.Memories[memory.index].Memory64 {
+		problems = append(problems, "memory is 64-bit; the ABI passes 32-bit pointers")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(m.Exports)) {
+		want, ok := ABI[name]
+		export := m.Exports[name]
+		switch {
+		case !ok:
+		case export.Kind != externKinds[kindFunc]:
+			problems = append(problems, fmt.Sprintf("export %s is a %s, not a function", name, export.Kind))
+		case !slices.Equal(export.Signature.Params, want.Params) || !slices.Equal(export.Signature.Results, want.Results):
+			problems = append(problems, fmt.Sprintf("export %s has signature %s, expected %s", name, export.Signature, want))
+		}
+	}
+
+	for _, pair := range pairs {
+		_, first := m.Exports[pair[0]]
+		_, second := m.Exports[pair[1]]
+		if first != second {
+			problems = append(problems, fmt.Sprintf("exports only one of %s and %s", pair[0], pair[1]))
+		}
+	}
+	return problems
+}
+
+// Lint parses the wasm binary data and checks it, returning an error that
+// lists every problem, or nil for a module that keeps to the ABI
+func Lint(data []byte) error {
+	m, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	if problems := Check(m); len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sec encodes a section; contents are under 128 bytes, so the size is one byte
+func sec(id byte, contents ...byte) []byte {
+	return append([]byte{id, byte(len(contents))}, contents...)
+}
+
+// str encodes a name
+func str(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+// export encodes an export of kind and index
+func export(name string, kind, index byte) []byte {
+	return append(str(name), kind, index)
+}
+
+// taskModule imports env.now_ms, so its own functions start at index 1, and
+// exports a 1-page memory, init, alloc and run_task, plus extra
+func taskModule(memory []byte, extra ...[]byte) []byte {
+	exports := slices.Concat(
+		export("memory", kindMemory, 0),
+		export("init", kindFunc, 1),
+		export("alloc", kindFunc, 2),
+		export("run_task", kindFunc, 2),
+		slices.Concat(extra...))
+	return slices.Concat(
+		wasmHeader,
+		// Types: () -> f64, (i32) -> (), (i32) -> i32, (i64) -> i64
+		sec(1, 0x04, 0x60, 0x00, 0x01, 0x7c, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x01, 0x7e, 0x01, 0x7e),
+		sec(2, slices.Concat([]byte{0x01}, str("env"), str("now_ms"), []byte{kindFunc, 0x00})...),
+		// Functions: init, alloc/run_task, wide
+		sec(3, 0x03, 0x01, 0x02, 0x03),
+		sec(5, slices.Concat([]byte{0x01}, memory)...),
+		sec(7, slices.Concat([]byte{byte(4 + len(extra))}, exports)...),
+	)
+}
+
+func TestLint(t *testing.T) {
+	if err := Lint(taskModule([]byte{0x00, 0x01})); err != nil {
+		t.Errorf("a module that keeps to the ABI: %v", err)
+	}
+	m, err := Parse(taskModule([]byte{0x01, 0x01, 0x10}, export("get_last_error_ptr", kindFunc, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Exports["get_last_error_ptr"].Signature.String(); got != "(i32) -> (i32)" {
+		t.Errorf("signature %s, expected the imported function skipped", got)
+	}
+	if m.Memories[0] != (Memory{Min: 1, Max: 16, HasMax: true}) {
+		t.Errorf("memory %+v, expected 1 to 16 pages", m.Memories[0])
+	}
+
+	for _, c := range []struct {
+		name string
+		wasm []byte
+		want string
+	}{
+		{"signature", taskModule([]byte{0x00, 0x01}, export("run_task64", kindFunc, 3)),
+			"export run_task64 has signature (i64) -> (i64), expected (i32) -> (i64)"},
+		{"kind", taskModule([]byte{0x00, 0x01}, export("self_test", kindMemory, 0)),
+			"export self_test is a memory, not a function"},
+		{"memory64", taskModule([]byte{0x04, 0x01}), "memory is 64-bit; the ABI passes 32-bit pointers"},
+		{"pair", taskModule([]byte{0x00, 0x01}, export("get_panic_len", kindFunc, 2)),
+			"export get_panic_len has signature (i32) -> (i32), expected () -> (i32); exports only one of get_panic_ptr and get_panic_len"},
+		{"command", slices.Concat(wasmHeader, sec(1, 0x01, 0x60, 0x00, 0x00), sec(3, 0x01, 0x00),
+			sec(7, slices.Concat([]byte{0x01}, export("_start", kindFunc, 0))...)),
+			"missing export init; missing export alloc; missing export run_task; it exports _start (a WASI command build?); missing export memory; the host writes the params into it"},
+	} {
+		if err := Lint(c.wasm); err == nil || err.Error() != c.want {
+			t.Errorf("%s: %v, expected %q", c.name, err, c.want)
+		}
+	}
+
+	if err := Lint(taskModule([]byte{0x00, 0x01}, export("init64", kindFunc, 9))); err == nil || !strings.Contains(err.Error(), "names function 9") {
+		t.Errorf("export of an undefined function: %v", err)
+	}
+	if err := Lint([]byte("\x00asm\x02\x00\x00\x00")); err == nil {
+		t.Error("a version 2 module passed")
+	}
+}
+
+// ABI agrees with the exports configs/tasks.json records from the TinyGo
+// source, so a change to a task's exports fails here until the table follows
+func TestABIMatchesManifest(t *testing.T) {
+	data, err := os.ReadFile("../../../configs/tasks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Tasks map[string]struct {
+			Exports []struct {
+				Name    string   `json:"name"`
+				Params  []string `json:"params"`
+				Results []string `json:"results"`
+			} `json:"exports"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	exported := map[string]bool{}
+	for task, spec := range manifest.Tasks {
+		for _, e := range spec.Exports {
+			exported[e.Name] = true
+			want, ok := ABI[e.Name]
+			if !ok {
+				t.Errorf("%s exports %s, which ABI does not list", task, e.Name)
+			} else if !slices.Equal(want.Params, e.Params) || !slices.Equal(want.Results, e.Results) {
+				t.Errorf("%s exports %s as (%v) -> (%v), ABI lists %s", task, e.Name, e.Params, e.Results, want)
+			}
+		}
+	}
+	for name := range ABI {
+		if !exported[name] {
+			t.Errorf("ABI lists %s, which no task exports", name)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// wasmHeader is the magic number and version 1 every module starts with
+var wasmHeader = []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+
+// Names of the value types by their byte, as configs/tasks.json writes them
+var valueTypes = map[byte]string{0x7f: "i32", 0x7e: "i64", 0x7d: "f32", 0x7c: "f64", 0x7b: "v128", 0x70: "funcref", 0x6f: "externref"}
+
+// Names of the import and export kinds by their byte
+var externKinds = []string{"func", "table", "memory", "global", "tag"}
+
+const (
+	kindFunc   = 0
+	kindMemory = 2
+)
+
+// Signature is the type of a function, its params and results by value type
+type Signature struct {
+	Params  []string
+	Results []string
+}
+
+func (s Signature) String() string {
+	return fmt.Sprintf("(%s) -> (%s)", strings.Join(s.Params, ", "), strings.Join(s.Results, ", "))
+}
+
+// Export is an export of a module; a function's carries its signature
+type Export struct {
+	Kind      string
+	Signature Signature
+	index     uint32
+}
+
+// Memory is a linear memory of a module, in 64KiB pages
+type Memory struct {
+	Min, Max uint64
+	HasMax   bool
+	Shared   bool
+	Memory64 bool
+}
+
+// Module is what the lint reads of a wasm binary
+type Module struct {
+	Exports  map[string]Export
+	Memories []Memory // Imported ones first, in index order
+}
+
+// Parse reads the exports of a wasm binary, each function's with its
+// signature, and its memories
+func Parse(data []byte) (*Module, error) {
+	if !bytes.HasPrefix(data, wasmHeader) {
+		return nil, errors.New("not a version 1 wasm module")
+	}
+	m := &Module{Exports: map[string]Export{}}
+	var types []Signature
+	var functions []uint32 // Type index of each function, imported ones first
+	r := &reader{data: data, pos: len(wasmHeader)}
+	for r.pos < len(data) {
+		start := r.pos
+		id := r.byte()
+		size := r.u32()
+		end := r.pos + int(size)
+		if r.err != nil || end > len(data) {
+			return nil, fmt.Errorf("section at offset %d runs past the end of the module", start)
+		}
+		contents := &reader{data: data[:end], pos: r.pos}
+		switch id {
+		case 1:
+			types = contents.types()
+		case 2:
+			functions = contents.imports(m, functions)
+		case 3:
+			for n := contents.u32(); n > 0 && contents.err == nil; n-- {
+				functions = append(functions, contents.u32())
+			}
+		case 5:
+			for n := contents.u32(); n > 0 && contents.err == nil; n-- {
+				m.Memories = append(m.Memories, contents.memory())
+			}
+		case 7:
+			contents.exports(m)
+		}
+		if contents.err != nil {
+			return nil, fmt.Errorf("section %d at offset %d: %w", id, start, contents.err)
+		}
+		r.pos = end
+	}
+
+	for name, export := range m.Exports {
+		if export.Kind != externKinds[kindFunc] {
+			continue
+		}
+		if int(export.index) >= len(functions) || int(functions[export.index]) >= len(types) {
+			return nil, fmt.Errorf("export %s names function %d, which the module does not define", name, export.index)
+		}
+		export.Signature = types[functions[export.index]]
+		m.Exports[name] = export
+	}
+	return m, nil
+}
+
+// reader decodes the binary format, keeping the first error
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errTruncated = errors.New("truncated")
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.data) {
+		r.err = errTruncated
+		return 0
+	}
+	r.pos++
+	return r.data[r.pos-1]
+}
+
+// u64 reads an unsigned LEB128 number
+func (r *reader) u64() uint64 {
+	var n uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b := r.byte()
+		n |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n
+		}
+	}
+	if r.err == nil {
+		r.err = errors.New("LEB128 number too long")
+	}
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	n := r.u64()
+	if n > 1<<32-1 && r.err == nil {
+		r.err = errors.New("u32 out of range")
+	}
+	return uint32(n)
+}
+
+func (r *reader) name() string {
+	n := int(r.u32())
+	if r.err != nil {
+		return ""
+	}
+	if n > len(r.data)-r.pos {
+		r.err = errTruncated
+		return ""
+	}
+	r.pos += n
+	return string(r.data[r.pos-n : r.pos])
+}
+
+func (r *reader) valueTypes() []string {
+	var names []string
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		b := r.byte()
+		name, ok := valueTypes[b]
+		if !ok {
+			name = fmt.Sprintf("type %#x", b)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (r *reader) types() []Signature {
+	var types []Signature
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		if form := r.byte(); form != 0x60 && r.err == nil {
+			r.err = fmt.Errorf("type %d is not a function type (form %#x)", len(types), form)
+			return nil
+		}
+		params := r.valueTypes()
+		types = append(types, Signature{params, r.valueTypes()})
+	}
+	return types
+}
+
+// memory reads the limits of a memory type
+func (r *reader) memory() Memory {
+	flags := r.byte()
+	m := Memory{Min: r.u64(), HasMax: flags&1 != 0, Shared: flags&2 != 0, Memory64: flags&4 != 0}
+	if m.HasMax {
+		m.Max = r.u64()
+	}
+	return m
+}
+
+// imports adds the imported memories to m and the type indices of the
+// imported functions to functions
+func (r *reader) imports(m *Module, functions []uint32) []uint32 {
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		module, name := r.name(), r.name()
+		switch kind := r.byte(); kind {
+		case kindFunc:
+			functions = append(functions, r.u32())
+		case 1: // Element type, limits
+			r.byte()
+			r.memory()
+		case kindMemory:
+			m.Memories = append(m.Memories, r.memory())
+		case 3: // Value type, mutability
+			r.byte()
+			r.byte()
+		case 4: // Attribute, type index
+			r.byte()
+			r.u32()
+		default:
+			r.err = fmt.Errorf("import %s.%s has unknown kind %d", module, name, kind)
+		}
+	}
+	return functions
+}
+
+func (r *reader) exports(m *Module) {
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		name := r.name()
+		kind := r.byte()
+		index := r.u32()
+		kindName := fmt.Sprintf("kind %d", kind)
+		if int(kind) < len(externKinds) {
+			kindName = externKinds[kind]
+		}
+		m.Exports[name] = Export{Kind: kindName, index: index}
+	}
+}
@@ -0,0 +1,84 @@
+// Command abilint checks built task modules against the task ABI without
+// running them: that each exports init, alloc, run_task and a 32-bit memory,
+// that every ABI function it exports has the signature configs/tasks.json
+// records for it, and that it exports the pointer and length of a message
+// together. cmd/bench makes the same check before it loads a module, so a
+// module that would fail this one fails there up front too, with the same
+// message, rather than with a runtime's trap.
+//
+// Usage:
+//
+//	abilint [-builds dir] [module.wasm ...]
+//
+// With no modules, every .wasm under the language directories of -builds is
+// checked, the WASI command builds (-wasi.wasm) left out. Each problem is
+// printed on a line of its own after its module's path, and the exit status
+// is 1 if any module has one.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wasmbench/abilint/lint"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("abilint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	builds := flags.String("builds", "builds", "directory searched when no modules are given")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		matches, _ := filepath.Glob(filepath.Join(*builds, "*", "*.wasm"))
+		for _, path := range matches {
+			if !strings.HasSuffix(path, "-wasi.wasm") {
+				paths = append(paths, path)
+			}
+		}
+		if len(paths) == 0 {
+			fmt.Fprintf(stderr, "abilint: no modules under %s; build them first or name them\n", *builds)
+			return 1
+		}
+	}
+
+	status := 0
+	for _, path := range paths {
+		problems, err := check(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "abilint: %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		for _, problem := range problems {
+			fmt.Fprintf(stdout, "%s: %s\n", path, problem)
+			status = 1
+		}
+	}
+	return status
+}
+
+// check reads and parses the module at path and returns its problems
+func check(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := lint.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return lint.Check(m), nil
+}
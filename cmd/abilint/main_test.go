@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// abiModule is the smallest module of the ABI: a memory, init, alloc and
+// run_task
+var abiModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> (), (i32) -> i32
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// Functions: init, alloc, run_task
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	// Memory: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Exports: memory, init, alloc, run_task
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+}
+
+func TestRun(t *testing.T) {
+	builds := t.TempDir()
+	for path, data := range map[string][]byte{
+		"tinygo/matrix_mul-o2.wasm":   abiModule,
+		"tinygo/matrix_mul-wasi.wasm": abiModule[:8],
+		"rust/matrix_mul-o3.wasm":     abiModule[:8],
+	} {
+		path = filepath.Join(builds, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-builds", builds}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1: %s", code, stderr.String())
+	}
+	rust := filepath.Join(builds, "rust", "matrix_mul-o3.wasm")
+	want := rust + ": missing export init\n" + rust + ": missing export alloc\n" + rust + ": missing export run_task\n" +
+		rust + ": missing export memory; the host writes the params into it\n"
+	if stdout.String() != want {
+		t.Errorf("output:\n%s\nexpected the Rust build's problems alone:\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if code := run([]string{filepath.Join(builds, "tinygo", "matrix_mul-o2.wasm")}, &stdout, &stderr); code != 0 || stdout.Len() != 0 {
+		t.Errorf("exit status %d for a module that keeps to the ABI: %s", code, stdout.String())
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"wasmbench/bench/internal/config"
+)
+
+// agentFlags are the flags a coordinator sends its agents: those shaping
+// what is measured and how. None names a file, a program or a process
+// priority of the agent's host, so a job can only run the agent's own
+// modules and JavaScript implementations, under its own -builds and
+// checkout.
+var agentFlags = []string{"runtime", "task", "category", "size", "language", "warmup", "warmup-cv", "max-warmup",
+	"runs", "native", "js", "commit", "determinism", "overhead", "fuzz", "fuzz-seed", "cold", "timeout", "parallel",
+	"interleave", "strict", "perf", "energy"}
+
+// coordinatorFlags are the flags a coordinator keeps for itself besides the
+// agents'; -agents takes no others
+var coordinatorFlags = []string{"agents", "plan", "json", "csv", "stream"}
+
+// agentTokenVariable names the environment variable holding the token an
+// agent and its coordinators share. Every job carries it as a bearer token,
+// and the agent refuses a job without it.
+const agentTokenVariable = "WASMBENCH_AGENT_TOKEN"
+
+// agentAddr returns addr with a host, loopback if it names none, so an agent
+// listens on another interface only when told which
+func agentAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// agentJob is what a coordinator sends an agent: the plan, the flags of the
+// session by name, the agent's assignment among them as -task and -runtime,
+// and the modules named, if any, as paths under the agent's -builds
+type agentJob struct {
+	Plan    string            `json:"plan"`
+	Flags   map[string]string `json:"flags"`
+	Modules []string          `json:"modules,omitempty"`
+}
+
+// agentEnd is the last line of an agent's stream: the exit status of its
+// session with its host, or why it ran nothing
+type agentEnd struct {
+	Record      string       `json:"record"` // "end"
+	Status      int          `json:"status"`
+	Environment *Environment `json:"environment,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// AgentSession is an agent of a -agents session and the host it ran on
+type AgentSession struct {
+	Name        string       `json:"name"`
+	URL         string       `json:"url"`
+	Environment *Environment `json:"environment,omitempty"` // Nil for an agent that ran nothing
+	Error       string       `json:"error,omitempty"`
+}
+
+// agent is bench -agent: it runs the sessions coordinators send it, one at a
+// time so that none disturbs another's timings, and streams each back as
+// -stream writes it, run by run
+type agent struct {
+	builds  string   // Holds the modules a job names, searched when it names none
+	plugins []string // Modules of the agent's -tasks
+	token   string   // Of agentTokenVariable, which every job must carry
+	log     io.Writer
+	busy    sync.Mutex
+}
+
+// serveAgent serves a at http://addr/run until the server fails, on
+// loopback when addr names no host
+func serveAgent(addr string, a *agent, stderr io.Writer) error {
+	addr, err := agentAddr(addr)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/run", a)
+	fmt.Fprintf(stderr, "bench: agent at http://%s/run\n", listener.Addr())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return server.Serve(listener)
+}
+
+func (a *agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a job", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorized(r, a.token) {
+		http.Error(w, "no or wrong "+agentTokenVariable, http.StatusUnauthorized)
+		return
+	}
+	var job agentJob
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		http.Error(w, "bad job: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name := range job.Flags {
+		if !slices.Contains(agentFlags, name) {
+			http.Error(w, fmt.Sprintf("-%s is not a flag an agent takes", name), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, module := range job.Modules {
+		if !filepath.IsLocal(module) {
+			http.Error(w, fmt.Sprintf("module %s is not a path under the agent's -builds", module), http.StatusBadRequest)
+			return
+		}
+	}
+	if !a.busy.TryLock() {
+		http.Error(w, "busy with another session", http.StatusConflict)
+		return
+	}
+	defer a.busy.Unlock()
+
+	dir, err := os.MkdirTemp("", "bench-agent-")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+	planPath, sessionPath := filepath.Join(dir, "plan.yaml"), filepath.Join(dir, "session.json")
+	if err := os.WriteFile(planPath, []byte(job.Plan), 0o644); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	args := []string{"-plan", planPath, "-json", sessionPath, "-stream"}
+	for _, name := range slices.Sorted(maps.Keys(job.Flags)) {
+		args = append(args, "-"+name+"="+job.Flags[name])
+	}
+	var modules []string
+	for _, module := range job.Modules {
+		modules = append(modules, filepath.Join(a.builds, module))
+	}
+	if len(modules) == 0 {
+		modules = append(findModules(a.builds), a.plugins...)
+	}
+	args = append(append(args, "--"), modules...)
+
+	fmt.Fprintf(a.log, "bench: agent: session from %s\n", r.RemoteAddr)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	out := flushWriter{w, http.NewResponseController(w)}
+	var stderr bytes.Buffer
+	end := agentEnd{Record: "end", Status: run(args, out, io.MultiWriter(a.log, &stderr))}
+	var s Session
+	if data, err := os.ReadFile(sessionPath); err == nil && json.Unmarshal(data, &s) == nil {
+		end.Environment = &s.Environment
+	} else {
+		// The session ended before writing anything; its last words say why
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		end.Error = strings.TrimPrefix(lines[len(lines)-1], "bench: ")
+	}
+	json.NewEncoder(out).Encode(end)
+}
+
+// authorized reports whether r carries token as its bearer token
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// flushWriter sends each write to the client as it is made
+type flushWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil {
+		err = f.controller.Flush()
+	}
+	return n, err
+}
+
+// agentEvent is a line of agent i's stream: a run, a result or the end,
+// which is also sent for an agent that failed
+type agentEvent struct {
+	agent  int
+	run    *runRecord
+	result *Result
+	end    *agentEnd
+}
+
+// dispatch sends each agent its job and returns their streams, merged as
+// the lines arrive. The channel is closed after every agent's end.
+func dispatch(ctx context.Context, agents []config.Agent, jobs []agentJob, token string) <-chan agentEvent {
+	events := make(chan agentEvent)
+	var wg sync.WaitGroup
+	for i, a := range agents {
+		wg.Go(func() {
+			end, err := follow(ctx, a.URL, jobs[i], token, func(e agentEvent) {
+				e.agent = i
+				events <- e
+			})
+			if err != nil {
+				end = &agentEnd{Record: "end", Status: 1, Error: err.Error()}
+			}
+			events <- agentEvent{agent: i, end: end}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return events
+}
+
+// follow sends job to the agent at url, authorized by token, and calls emit
+// with each run and result it streams back, returning the end of its session
+func follow(ctx context.Context, url string, job agentJob, token string, emit func(agentEvent)) (*agentEnd, error) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/run", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(response.Body, 1<<10))
+		return nil, fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(text)))
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var line json.RawMessage
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = errors.New("the stream ended before the session")
+			}
+			return nil, err
+		}
+		var record struct {
+			Record string `json:"record"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, err
+		}
+		var e agentEvent
+		switch record.Record {
+		case "run":
+			e.run = &runRecord{}
+			err = json.Unmarshal(line, e.run)
+		case "result":
+			e.result = &Result{}
+			err = json.Unmarshal(line, e.result)
+		case "end":
+			var end agentEnd
+			if err := json.Unmarshal(line, &end); err != nil {
+				return nil, err
+			}
+			return &end, nil
+		default:
+			err = fmt.Errorf("unknown record %q", record.Record)
+		}
+		if err != nil {
+			return nil, err
+		}
+		emit(e)
+	}
+}
+
+// planAgents returns the agents of the plan at path whose names match glob,
+// with the plan. An agent with tasks or runtimes of its own cannot also take
+// -task or -runtime, set says whether they were given.
+func planAgents(path, glob string, set map[string]bool) ([]config.Agent, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := config.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var agents []config.Agent
+	for _, a := range plan.Agents {
+		if !match(glob, a.Name) {
+			continue
+		}
+		if len(a.Tasks) > 0 && set["task"] || len(a.Runtimes) > 0 && set["runtime"] {
+			return nil, nil, fmt.Errorf("agent %s is assigned its own tasks or runtimes; -task and -runtime do not apply", a.Name)
+		}
+		agents = append(agents, a)
+	}
+	if len(agents) == 0 {
+		return nil, nil, fmt.Errorf("%s: no agent matches -agents %s", path, glob)
+	}
+	return agents, data, nil
+}
+
+// coordinate is -agents: it runs job on each of agents, authorized by token,
+// its assignment added, and gathers what they stream back into session,
+// printing each result, and each run with stream, as it arrives. It returns
+// the exit status.
+func coordinate(ctx context.Context, agents []config.Agent, job agentJob, token string, session *Session, stdout, stderr io.Writer, stream *runStream) int {
+	jobs := make([]agentJob, len(agents))
+	session.Agents = make([]AgentSession, len(agents))
+	for i, a := range agents {
+		jobs[i] = job
+		jobs[i].Flags = maps.Clone(job.Flags)
+		if len(a.Tasks) > 0 {
+			jobs[i].Flags["task"] = strings.Join(a.Tasks, ",")
+		}
+		if len(a.Runtimes) > 0 {
+			jobs[i].Flags["runtime"] = strings.Join(a.Runtimes, ",")
+		}
+		session.Agents[i] = AgentSession{Name: a.Name, URL: a.URL}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := dispatch(ctx, agents, jobs, token)
+	encoder := json.NewEncoder(stdout)
+	status := 0
+	for e := range events {
+		name := agents[e.agent].Name
+		var err error
+		switch {
+		case e.run != nil:
+			e.run.Agent = name
+			err = stream.forward(*e.run)
+		case e.result != nil:
+			result := *e.result
+			result.Agent = name
+			session.Results = append(session.Results, result)
+			if result.Error != "" {
+				fmt.Fprintf(stderr, "bench: %s: %s: %s\n", name, result.Module, result.Error)
+				status = 1
+			}
+			if stream != nil {
+				err = stream.result(result)
+			} else {
+				err = encoder.Encode(result)
+			}
+		case e.end != nil:
+			a := &session.Agents[e.agent]
+			a.Environment, a.Error = e.end.Environment, e.end.Error
+			if e.end.Error != "" {
+				fmt.Fprintf(stderr, "bench: agent %s: %s\n", name, e.end.Error)
+			}
+			if e.end.Status != 0 {
+				status = 1
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			cancel()
+			for range events {
+			}
+			return 1
+		}
+	}
+	return status
+}
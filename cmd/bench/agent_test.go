@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testToken is the token the tests' agents and coordinators share
+const testToken = "test-token"
+
+// writeAgentPlan writes a plan of matrix_mul at one scale run by the agents,
+// each a name and a URL
+func writeAgentPlan(t *testing.T, agents ...string) string {
+	t.Helper()
+	plan := "environment: {warmup_runs: 0, measure_runs: 2}\ntasks: {matrix_mul: {scales: {small: {dimension: 4}}}}\nagents:\n"
+	for i := 0; i < len(agents); i += 2 {
+		plan += "  - {name: " + agents[i] + ", url: '" + agents[i+1] + "', tasks: [matrix_*]}\n"
+	}
+	path := filepath.Join(t.TempDir(), "plan.yaml")
+	if err := os.WriteFile(path, []byte(plan), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunAgents(t *testing.T) {
+	t.Setenv(agentTokenVariable, testToken)
+	module := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	builds := filepath.Dir(module)
+	x86 := httptest.NewServer(&agent{builds: builds, token: testToken, log: io.Discard})
+	defer x86.Close()
+	arm := httptest.NewServer(&agent{builds: builds, token: testToken, log: io.Discard})
+	defer arm.Close()
+	plan := writeAgentPlan(t, "x86", x86.URL, "arm", arm.URL)
+	sessionPath := filepath.Join(t.TempDir(), "session.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-agents", "*", "-plan", plan, "-json", sessionPath, filepath.Base(module)}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	agents := map[string]bool{}
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result.SamplesMs) != 2 || result.Scale != "small" || result.Hash != 12 {
+			t.Errorf("result %+v, expected the plan's 2 runs at dimension 4", result)
+		}
+		agents[result.Agent] = true
+	}
+	if len(agents) != 2 || !agents["x86"] || !agents["arm"] {
+		t.Errorf("results of agents %v, expected one of x86 and one of arm", agents)
+	}
+
+	data, err := os.ReadFile(sessionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if len(session.Agents) != 2 || session.Agents[0].Name != "x86" || session.Agents[1].URL != arm.URL {
+		t.Fatalf("agents %+v, expected x86 and arm in plan order", session.Agents)
+	}
+	for _, a := range session.Agents {
+		if a.Environment == nil || a.Environment.GoVersion == "" || a.Error != "" {
+			t.Errorf("agent %+v, expected its host's environment", a)
+		}
+	}
+}
+
+func TestRunAgentsFailures(t *testing.T) {
+	t.Setenv(agentTokenVariable, testToken)
+	module := filepath.Base(writeModule(t, "matrix_mul-o2.wasm", fakeTask))
+	closed := httptest.NewServer(&agent{token: testToken, log: io.Discard})
+	closed.Close()
+	busy := &agent{token: testToken, log: io.Discard}
+	busy.busy.Lock()
+	server := httptest.NewServer(busy)
+	defer server.Close()
+	plan := writeAgentPlan(t, "gone", closed.URL, "busy", server.URL)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-agents", "*", "-plan", plan, module}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d with agents that ran nothing, expected 1", code)
+	}
+	for _, line := range []string{"bench: agent gone: ", "bench: agent busy: 409 Conflict: busy with another session"} {
+		if !strings.Contains(stderr.String(), line) {
+			t.Errorf("stderr %q, expected %q", stderr.String(), line)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-agents", "*", module},
+		{"-agents", "*", "-plan", plan, "-history", "history.db"},
+		{"-agents", "*", "-plan", plan, "-js-tasks", "elsewhere"},
+		{"-agents", "*", "-plan", plan, "-strict", "-nice", "-20"},
+		{"-agents", "*", "-plan", plan, "/builds/tinygo/matrix_mul-o2.wasm"},
+		{"-agents", "*", "-plan", plan, "../builds/tinygo/matrix_mul-o2.wasm"},
+		{"-agents", "nothing", "-plan", plan},
+		{"-agents", "*", "-plan", plan, "-task", "mandelbrot"},
+		{"-agent", ":0", "-runs", "3"},
+	} {
+		stderr.Reset()
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("bench %v: exit status %d, expected 2: %s", args, code, stderr.String())
+		}
+	}
+
+	t.Setenv(agentTokenVariable, "")
+	for _, args := range [][]string{{"-agents", "*", "-plan", plan, module}, {"-agent", ":0"}} {
+		stderr.Reset()
+		if code := run(args, &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), agentTokenVariable) {
+			t.Errorf("bench %v without a token: exit status %d, expected 2 naming %s: %s", args, code, agentTokenVariable, stderr.String())
+		}
+	}
+}
+
+func TestAgentRejectsUnsafeJobs(t *testing.T) {
+	server := httptest.NewServer(&agent{token: testToken, log: io.Discard})
+	defer server.Close()
+	for _, c := range []struct {
+		name   string
+		token  string
+		job    string
+		status int
+	}{
+		{"no token", "", `{"plan": ""}`, http.StatusUnauthorized},
+		{"wrong token", "guess", `{"plan": ""}`, http.StatusUnauthorized},
+		{"session file", testToken, `{"plan": "", "flags": {"json": "/etc/passwd"}}`, http.StatusBadRequest},
+		{"javascript directory", testToken, `{"plan": "", "flags": {"js": "node", "js-tasks": "/tmp"}}`, http.StatusBadRequest},
+		{"reference directory", testToken, `{"plan": "", "flags": {"verify": "/"}}`, http.StatusBadRequest},
+		{"priority", testToken, `{"plan": "", "flags": {"strict": "true", "nice": "19"}}`, http.StatusBadRequest},
+		{"absolute module", testToken, `{"plan": "", "modules": ["/etc/passwd"]}`, http.StatusBadRequest},
+		{"module outside -builds", testToken, `{"plan": "", "modules": ["tinygo/../../secret.wasm"]}`, http.StatusBadRequest},
+	} {
+		request, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(c.job))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.token != "" {
+			request.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != c.status {
+			t.Errorf("%s: status %s, expected %d", c.name, response.Status, c.status)
+		}
+	}
+}
+
+func TestAgentAddr(t *testing.T) {
+	for addr, expected := range map[string]string{
+		":9470":         "127.0.0.1:9470",
+		"0.0.0.0:9470":  "0.0.0.0:9470",
+		"[::1]:9470":    "[::1]:9470",
+		"10.0.0.5:9470": "10.0.0.5:9470",
+	} {
+		if got, err := agentAddr(addr); err != nil || got != expected {
+			t.Errorf("agentAddr(%q) = %q, %v, expected %q", addr, got, err, expected)
+		}
+	}
+	if _, err := agentAddr("9470"); err == nil {
+		t.Error("agentAddr accepted an address without a port")
+	}
+}
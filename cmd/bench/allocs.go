@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	"wasmbench/stats"
+)
+
+// memoryStatsSize is the get_memory_stats block of an instrumented allocator
+// build: {u64 heap in use, total alloc, mallocs, GC cycles, run mallocs, run
+// bytes, run GC cycles}. Other builds publish the first four only.
+const memoryStatsSize = 56
+
+// Allocations are the heap allocations and GC cycles of each measured run of
+// an instrumented allocator build (cmd/build -tags allocstats), which says so
+// in get_task_info. The module counts them around the timed section of
+// run_task only, so its setup and warm-up iterations are left out.
+type Allocations struct {
+	Mallocs     []uint64 `json:"mallocs"` // Heap objects each run allocated
+	Bytes       []uint64 `json:"bytes"`
+	GCs         []uint64 `json:"gcs"` // GC cycles during each run
+	MedianBytes float64  `json:"median_bytes"`
+	GCRuns      int      `json:"gc_runs"` // Runs that collected at least once
+	// Median time of the runs that collected less that of the runs that did
+	// not, when there are both: the cost of the GC to one run
+	GCImpactMs float64 `json:"gc_impact_ms,omitempty"`
+
+	read func() (mallocs, bytes, gcs uint64, err error) // The last run's counts
+}
+
+// record adds the counts of the run that just ended
+func (a *Allocations) record() error {
+	mallocs, bytes, gcs, err := a.read()
+	if err != nil {
+		return err
+	}
+	a.Mallocs = append(a.Mallocs, mallocs)
+	a.Bytes = append(a.Bytes, bytes)
+	a.GCs = append(a.GCs, gcs)
+	return nil
+}
+
+// summarize fills in the medians and the GC's impact on the runs, which took
+// samples ms each
+func (a *Allocations) summarize(samples []float64) {
+	if a == nil || len(a.Bytes) == 0 {
+		return
+	}
+	bytes := make([]float64, len(a.Bytes))
+	var collected, uncollected []float64
+	for i, b := range a.Bytes {
+		bytes[i] = float64(b)
+		if i >= len(samples) {
+			continue
+		}
+		if a.GCs[i] > 0 {
+			collected = append(collected, samples[i])
+		} else {
+			uncollected = append(uncollected, samples[i])
+		}
+	}
+	a.MedianBytes = stats.Median(bytes)
+	a.GCRuns = len(collected)
+	a.GCImpactMs = 0
+	if len(collected) > 0 && len(uncollected) > 0 {
+		a.GCImpactMs = stats.Median(collected) - stats.Median(uncollected)
+	}
+}
+
+// runAllocations reads the counts of the last run from get_memory_stats
+func (m *module) runAllocations(ctx context.Context) (mallocs, bytes, gcs uint64, err error) {
+	ptr, err := m.call(ctx, "get_memory_stats")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	block, ok := m.readMemory(ptr, memoryStatsSize)
+	if !ok {
+		return 0, 0, 0, errors.New("get_memory_stats points outside memory")
+	}
+	return binary.LittleEndian.Uint64(block[32:]), binary.LittleEndian.Uint64(block[40:]), binary.LittleEndian.Uint64(block[48:]), nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// allocStatsTask is an instrumented allocator build of matrix_mul in
+// miniature: fakeTask's exports, with run_task always hashing 7, plus a
+// get_task_info saying alloc_stats and a get_memory_stats whose every run
+// allocated 3 objects of 96 bytes in all and collected once
+var allocStatsTask = slices.Concat([]byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> (), (i32) -> i32, () -> i32
+	0x01, 0x0e, 0x03, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x01, 0x7f,
+	// Functions: init, alloc, run_task, get_task_info, get_memory_stats
+	0x03, 0x06, 0x05, 0x00, 0x01, 0x01, 0x02, 0x02,
+	// Memory: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Exports: memory, init, alloc, run_task, get_task_info, get_memory_stats
+	0x07, 0x47, 0x06,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0d, 'g', 'e', 't', '_', 't', 'a', 's', 'k', '_', 'i', 'n', 'f', 'o', 0x00, 0x03,
+	0x10, 'g', 'e', 't', '_', 'm', 'e', 'm', 'o', 'r', 'y', '_', 's', 't', 'a', 't', 's', 0x00, 0x04,
+	// Code
+	0x0a, 0x1b, 0x05,
+	0x02, 0x00, 0x0b, // init: nop
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b, // alloc: i32.const 1024
+	0x04, 0x00, 0x41, 0x07, 0x0b, // run_task: i32.const 7
+	0x05, 0x00, 0x41, 0x80, 0x10, 0x0b, // get_task_info: i32.const 2048
+	0x05, 0x00, 0x41, 0x80, 0x20, 0x0b, // get_memory_stats: i32.const 4096
+	// Data: the task info at 2048, the memory stats at 4096
+	0x0b, 0xa7, 0x01, 0x02,
+	0x00, 0x41, 0x80, 0x10, 0x0b, 0x62,
+	0x5e, 0x00, 0x00, 0x00,
+}, []byte(`{"task":"matrix_mul","language":"tinygo","variant":"naive","abi_version":2,"alloc_stats":true}`), []byte{
+	0x00, 0x41, 0x80, 0x20, 0x0b, 0x38,
+	// {heap in use, total alloc, mallocs, GC cycles, run mallocs, run bytes, run GC cycles}
+	0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x28, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x60, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+})
+
+func TestRunAllocations(t *testing.T) {
+	instrumented := writeModule(t, "matrix_mul-o2-allocstats.wasm", allocStatsTask)
+	plain := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-warmup", "1", "-runs", "3", instrumented, plain}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var results []Result
+	for decoder := json.NewDecoder(&stdout); decoder.More(); {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 2 {
+		t.Fatalf("%d results, expected 2", len(results))
+	}
+	a := results[0].Allocations
+	if a == nil || !slices.Equal(a.Mallocs, []uint64{3, 3, 3}) || !slices.Equal(a.Bytes, []uint64{96, 96, 96}) || !slices.Equal(a.GCs, []uint64{1, 1, 1}) {
+		t.Fatalf("allocations %+v, expected the measured runs' counts from get_memory_stats", a)
+	}
+	if a.MedianBytes != 96 || a.GCRuns != 3 || a.GCImpactMs != 0 {
+		t.Errorf("allocations %+v, expected 96 bytes and every run collecting", a)
+	}
+	if results[1].Allocations != nil {
+		t.Errorf("allocations %+v of a build that does not count them", results[1].Allocations)
+	}
+}
+
+func TestAllocationsSummarize(t *testing.T) {
+	a := &Allocations{Mallocs: []uint64{5, 5, 5, 5}, Bytes: []uint64{100, 300, 200, 100}, GCs: []uint64{0, 2, 1, 0}}
+	a.summarize([]float64{1, 4, 3, 1.5})
+	if a.MedianBytes != 150 || a.GCRuns != 2 || a.GCImpactMs != 3.5-1.25 {
+		t.Errorf("summary %+v, expected median 150 bytes, 2 collecting runs and 2.25 ms of GC", a)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// checkpoint is the -checkpoint file: every result of the session as a JSON
+// line, appended and synced to disk as it is reported, so a session stopped
+// by a crash or a reboot resumes where it stopped when run again with the
+// same file. A benchmark the file has a result for is not run again, and its
+// result is reported as it was, marked resumed; failed ones run again.
+type checkpoint struct {
+	mu   sync.Mutex
+	file *os.File
+	done map[checkpointJob]Result
+}
+
+// checkpointJob is what a result was measured with: a module, or the native
+// baseline of a task, and the options that change its numbers. A result
+// resumes only a job with the same.
+type checkpointJob struct {
+	Module        string  `json:"module,omitempty"`
+	Native        string  `json:"native,omitempty"` // Task of a native baseline, which has no module
+	JS            string  `json:"js,omitempty"`     // Task of a JavaScript baseline, which has none either
+	Runtime       string  `json:"runtime"`
+	Task          string  `json:"task,omitempty"`   // -task, "" when inferred from the module
+	Params        string  `json:"params,omitempty"` // As given, before the task defaults
+	Scale         string  `json:"scale,omitempty"`
+	Repetition    int     `json:"repetition,omitempty"`
+	WarmupRuns    int     `json:"warmup_runs"`
+	WarmupCV      float64 `json:"warmup_cv,omitempty"`
+	MaxWarmupRuns int     `json:"max_warmup_runs,omitempty"`
+	Runs          int     `json:"runs"`
+	Determinism   int     `json:"determinism,omitempty"`
+	Verify        bool    `json:"verify,omitempty"`
+	Tolerance     string  `json:"tolerance,omitempty"`
+	Perf          bool    `json:"perf,omitempty"`
+	Energy        bool    `json:"energy,omitempty"`
+	ColdStarts    int     `json:"cold_starts,omitempty"`
+	Fuzz          int     `json:"fuzz,omitempty"`
+	FuzzSeed      uint64  `json:"fuzz_seed,omitempty"`
+}
+
+// checkpointEntry is a line of the file
+type checkpointEntry struct {
+	Job    checkpointJob `json:"job"`
+	Result Result        `json:"result"`
+}
+
+// jobOf returns the job of benchmarking module with opts
+func jobOf(module string, opts options) checkpointJob {
+	job := checkpointJob{Module: module, Runtime: opts.runtime, Task: opts.task, Params: opts.params, Scale: opts.scale,
+		Repetition: opts.repetition, WarmupRuns: opts.warmupRuns, Runs: opts.runs, Determinism: opts.determinism,
+		Verify: opts.references != nil, Perf: opts.perf, Energy: opts.energy, ColdStarts: opts.coldStarts}
+	if opts.tolerance != nil {
+		job.Tolerance = opts.tolerance.String()
+	}
+	if opts.fuzz > 0 {
+		job.Fuzz, job.FuzzSeed = opts.fuzz, opts.fuzzSeed
+	}
+	if opts.warmupCV > 0 {
+		job.WarmupCV, job.MaxWarmupRuns = opts.warmupCV, opts.maxWarmupRuns
+	}
+	return job
+}
+
+// nativeJob returns the job of the native baseline of task with opts
+func nativeJob(task string, opts options) checkpointJob {
+	job := jobOf("", opts)
+	job.Native = task
+	return job
+}
+
+// jsJob returns the job of the JavaScript baseline of task with opts, under
+// the engine of opts.js
+func jsJob(task string, opts options) checkpointJob {
+	job := jobOf("", opts)
+	job.JS, job.Runtime = task, opts.js
+	return job
+}
+
+// openCheckpoint opens the checkpoint at path, creating it if there is none,
+// and reads the results it has. A last line cut short by a crash is dropped.
+func openCheckpoint(path string) (*checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	c := &checkpoint{file: file, done: map[checkpointJob]Result{}}
+	reader := bufio.NewReader(file)
+	var kept int64 // Bytes of whole lines
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		kept += int64(len(line))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry checkpointEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		// A later result of the same job replaces a failed one
+		if entry.Result.Error == "" {
+			c.done[entry.Job] = entry.Result
+		}
+	}
+	if err := file.Truncate(kept); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(kept, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// completed returns the result the checkpoint has for job, marked resumed
+func (c *checkpoint) completed(job checkpointJob) (Result, bool) {
+	if c == nil {
+		return Result{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.done[job]
+	result.Resumed = ok
+	return result, ok
+}
+
+// record appends the result of job and syncs the file, unless the result
+// came from it
+func (c *checkpoint) record(job checkpointJob, result Result) error {
+	if c == nil || result.Resumed {
+		return nil
+	}
+	line, err := json.Marshal(checkpointEntry{job, result})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if result.Error == "" {
+		c.done[job] = result
+	}
+	return c.file.Sync()
+}
+
+func (c *checkpoint) close() error {
+	return c.file.Close()
+}
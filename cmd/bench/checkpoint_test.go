@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRunCheckpoint(t *testing.T) {
+	first := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	second := writeModule(t, "matrix_mul-os.wasm", fakeTask)
+	path := filepath.Join(t.TempDir(), "session.checkpoint")
+	bench := func(runs string) []Result {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		if code := run([]string{"-checkpoint", path, "-warmup", "0", "-runs", runs, first, second}, &stdout, &stderr); code != 0 {
+			t.Fatalf("exit status %d: %s", code, stderr.String())
+		}
+		var results []Result
+		for decoder := json.NewDecoder(&stdout); decoder.More(); {
+			var result Result
+			if err := decoder.Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, result)
+		}
+		return results
+	}
+
+	done := bench("3")
+	// A crash while the second result was written leaves part of its line
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cut := bytes.IndexByte(data, '\n') + 20
+	if err := os.WriteFile(path, data[:cut], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := bench("3")
+	if len(resumed) != 2 || !resumed[0].Resumed || resumed[1].Resumed {
+		t.Fatalf("results %+v, expected the first resumed and the second run again", resumed)
+	}
+	if !slices.Equal(resumed[0].SamplesMs, done[0].SamplesMs) {
+		t.Errorf("resumed samples %v, expected the checkpoint's %v", resumed[0].SamplesMs, done[0].SamplesMs)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("%d checkpoint lines, expected the first result's and the second's, without the cut one", lines)
+	}
+
+	// Other run counts are another job
+	if again := bench("2"); again[0].Resumed || again[1].Resumed || len(again[0].SamplesMs) != 2 {
+		t.Errorf("results %+v with -runs 2, expected both run again", again)
+	}
+	if again := bench("3"); !again[0].Resumed || !again[1].Resumed {
+		t.Errorf("results %+v, expected both resumed", again)
+	}
+}
+
+func TestOpenCheckpointCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.checkpoint")
+	if err := os.WriteFile(path, []byte("not json\n{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openCheckpoint(path); err == nil {
+		t.Error("a whole line that is not JSON should fail the checkpoint")
+	}
+}
@@ -0,0 +1,391 @@
+//go:build chromedp
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	cdpruntime "github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+func init() {
+	runtimes["chrome"] = instantiateChrome
+	jsEngines["chrome"] = startChromeJS
+}
+
+// chromeInstance is a module instantiated in the harness page of its own
+// headless Chrome. Every call is evaluated in the page, which times run_task
+// with performance.now() so the DevTools round trip stays out of the samples.
+type chromeInstance struct {
+	tab       context.Context // chromedp context of the page
+	stop      func()          // Closes the browser and the page's server
+	functions map[string]bool // Exported functions
+	runMs     float64         // Of the last run_task call
+}
+
+// instantiateChrome serves the module and a generated harness page on a
+// loopback port, launches headless Chrome on it and instantiates the module
+// there. Console messages, the module's env.log and WASI output among them,
+// go to log. chromedp speaks the DevTools protocol, so Firefox, which no
+// longer does, cannot be driven this way.
+func instantiateChrome(ctx context.Context, wasm []byte, log io.Writer) (instance, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: harnessHandler(wasm)}
+	go server.Serve(listener)
+
+	browser, cancelBrowser := chromedp.NewExecAllocator(ctx, chromedp.DefaultExecAllocatorOptions[:]...)
+	tab, cancelTab := chromedp.NewContext(browser)
+	c := &chromeInstance{tab: tab, stop: func() {
+		cancelTab()
+		cancelBrowser()
+		server.Close()
+	}}
+	forwardConsole(tab, log)
+
+	var loadErr string
+	var functions []string
+	err = chromedp.Run(tab,
+		chromedp.Navigate("http://"+listener.Addr().String()+"/"),
+		chromedp.Evaluate("bench.load()", &loadErr, awaitPromise),
+		chromedp.Evaluate("bench.functions()", &functions))
+	switch {
+	case err != nil:
+	case loadErr == "gojs":
+		err = errGoJS
+	case loadErr != "":
+		err = errors.New(loadErr)
+	}
+	if err != nil {
+		c.stop()
+		return nil, err
+	}
+	c.functions = map[string]bool{}
+	for _, name := range functions {
+		c.functions[name] = true
+	}
+	return c, nil
+}
+
+// forwardConsole writes the console messages of the page in tab to log
+func forwardConsole(tab context.Context, log io.Writer) {
+	chromedp.ListenTarget(tab, func(ev any) {
+		if ev, ok := ev.(*cdpruntime.EventConsoleAPICalled); ok {
+			for _, arg := range ev.Args {
+				var message string
+				if json.Unmarshal(arg.Value, &message) != nil {
+					message = string(arg.Value)
+				}
+				fmt.Fprintln(log, message)
+			}
+		}
+	})
+}
+
+func awaitPromise(p *cdpruntime.EvaluateParams) *cdpruntime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
+
+// harnessHandler serves the harness page and the module. The page is
+// cross-origin isolated, which gives performance.now() its finest resolution.
+func harnessHandler(wasm []byte) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
+		w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, harnessPage)
+	})
+	mux.HandleFunc("GET /module.wasm", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/wasm")
+		w.Write(wasm)
+	})
+	return mux
+}
+
+// harnessPage provides the host imports the other runtimes do, stubs the rest
+// so that only calling them fails, and exposes the module to the runner as
+// bench. Results are u32s; a trap comes back as the call's error.
+const harnessPage = `<!DOCTYPE html>
+<meta charset="utf-8">
+<title>wasmbench runner</title>
+<script>
+const decoder = new TextDecoder();
+const view = () => new DataView(bench.exports.memory.buffer);
+const text = (ptr, len) => decoder.decode(new Uint8Array(bench.exports.memory.buffer, ptr, len));
+
+const host = {
+    env: {
+        now_ms: () => performance.now(),
+        report_progress: () => {},
+        next_random: () => {
+            throw new Error('env.next_random: the runner supplies no host random data');
+        },
+        log: (ptr, len) => console.log(text(ptr, len))
+    },
+    gojs: {
+        'runtime.ticks': () => performance.now(),
+        'runtime.sleepTicks': () => {}
+    },
+    wasi_snapshot_preview1: {
+        fd_write: (fd, iovs, count, written) => {
+            let output = '';
+            let total = 0;
+            for (let i = 0; i < count; i++) {
+                const len = view().getUint32(iovs + 8 * i + 4, true);
+                output += text(view().getUint32(iovs + 8 * i, true), len);
+                total += len;
+            }
+            view().setUint32(written, total, true);
+            console.log(output.replace(/\n$/, ''));
+            return 0;
+        },
+        proc_exit: code => {
+            throw new Error('proc_exit(' + code + ')');
+        },
+        random_get: (ptr, len) => {
+            for (let done = 0; done < len; done += 65536) {
+                crypto.getRandomValues(new Uint8Array(bench.exports.memory.buffer, ptr + done, Math.min(65536, len - done)));
+            }
+            return 0;
+        },
+        clock_time_get: (id, precision, ptr) => {
+            const ns = BigInt(Math.round((performance.timeOrigin + performance.now()) * 1e6));
+            view().setBigUint64(ptr, ns, true);
+            return 0;
+        },
+        args_sizes_get: (count, size) => {
+            view().setUint32(count, 0, true);
+            view().setUint32(size, 0, true);
+            return 0;
+        },
+        environ_sizes_get: (count, size) => {
+            view().setUint32(count, 0, true);
+            view().setUint32(size, 0, true);
+            return 0;
+        },
+        args_get: () => 0,
+        environ_get: () => 0
+    }
+};
+
+const bench = {
+    exports: null,
+
+    async load() {
+        try {
+            const module = await WebAssembly.compileStreaming(fetch('/module.wasm'));
+            const imports = {};
+            for (const entry of WebAssembly.Module.imports(module)) {
+                if (entry.module === 'gojs' && entry.name === 'runtime.wasmExit') {
+                    return 'gojs';
+                }
+                if (entry.kind === 'function') {
+                    imports[entry.module] ??= {};
+                    imports[entry.module][entry.name] = host[entry.module]?.[entry.name] ?? (() => {
+                        throw new Error(entry.module + '.' + entry.name + ' is not provided by the runner');
+                    });
+                }
+            }
+            this.exports = (await WebAssembly.instantiate(module, imports)).exports;
+            this.exports._initialize?.();
+            return '';
+        } catch (e) {
+            return String(e);
+        }
+    },
+
+    functions() {
+        return Object.keys(this.exports).filter(name => typeof this.exports[name] === 'function');
+    },
+
+    call(name, params) {
+        try {
+            const start = performance.now();
+            const result = this.exports[name](...params);
+            const ms = performance.now() - start;
+            return { result: (result ?? 0) >>> 0, ms };
+        } catch (e) {
+            return { error: String(e) };
+        }
+    },
+
+    memorySize() {
+        return this.exports.memory?.buffer.byteLength ?? 0;
+    },
+
+    read(ptr, len) {
+        if (!this.exports.memory || ptr + len > this.exports.memory.buffer.byteLength) {
+            return null;
+        }
+        const bytes = new Uint8Array(this.exports.memory.buffer, ptr, len);
+        let binary = '';
+        for (let i = 0; i < bytes.length; i += 8192) {
+            binary += String.fromCharCode(...bytes.subarray(i, i + 8192));
+        }
+        return btoa(binary);
+    },
+
+    write(ptr, data) {
+        const bytes = Uint8Array.from(atob(data), c => c.charCodeAt(0));
+        if (!this.exports.memory || ptr + bytes.length > this.exports.memory.buffer.byteLength) {
+            return false;
+        }
+        new Uint8Array(this.exports.memory.buffer, ptr, bytes.length).set(bytes);
+        return true;
+    }
+};
+</script>
+`
+
+func (c *chromeInstance) exports(name string) bool {
+	return c.functions[name]
+}
+
+func (c *chromeInstance) call(ctx context.Context, name string, params ...uint32) (uint32, error) {
+	if !c.functions[name] {
+		return 0, fmt.Errorf("missing export %s", name)
+	}
+	args, err := json.Marshal(append([]uint32{}, params...))
+	if err != nil {
+		return 0, err
+	}
+	var out struct {
+		Result uint32  `json:"result"`
+		Ms     float64 `json:"ms"`
+		Error  string  `json:"error"`
+	}
+	if err := chromedp.Run(c.tab, chromedp.Evaluate(fmt.Sprintf("bench.call(%q, %s)", name, args), &out)); err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if out.Error != "" {
+		return 0, fmt.Errorf("%s: %s", name, out.Error)
+	}
+	if name == "run_task" {
+		c.runMs = out.Ms
+	}
+	return out.Result, nil
+}
+
+// lastRunMs returns the page's performance.now() time of the last run_task
+func (c *chromeInstance) lastRunMs() float64 {
+	return c.runMs
+}
+
+func (c *chromeInstance) readMemory(ptr, length uint32) ([]byte, bool) {
+	var data *string
+	if err := chromedp.Run(c.tab, chromedp.Evaluate(fmt.Sprintf("bench.read(%d, %d)", ptr, length), &data)); err != nil || data == nil {
+		return nil, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(*data)
+	return decoded, err == nil
+}
+
+func (c *chromeInstance) writeMemory(ptr uint32, data []byte) bool {
+	var ok bool
+	expression := fmt.Sprintf("bench.write(%d, %q)", ptr, base64.StdEncoding.EncodeToString(data))
+	return chromedp.Run(c.tab, chromedp.Evaluate(expression, &ok)) == nil && ok
+}
+
+func (c *chromeInstance) memorySize() uint64 {
+	var size uint64
+	if chromedp.Run(c.tab, chromedp.Evaluate("bench.memorySize()", &size)) != nil {
+		return 0
+	}
+	return size
+}
+
+func (c *chromeInstance) close(ctx context.Context) error {
+	c.stop()
+	return nil
+}
+
+// chromeJS is a JavaScript baseline in a page of its own headless Chrome
+type chromeJS struct {
+	tab  context.Context // chromedp context of the page
+	stop func()          // Closes the browser and the page's server
+}
+
+// startChromeJS serves the tasks directory dir and a page running jsDriver
+// on a loopback port, launches headless Chrome on it and loads task there.
+// Console messages go to log.
+func startChromeJS(ctx context.Context, dir, task string, log io.Writer) (jsEngine, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: jsHarnessHandler(dir)}
+	go server.Serve(listener)
+
+	browser, cancelBrowser := chromedp.NewExecAllocator(ctx, chromedp.DefaultExecAllocatorOptions[:]...)
+	tab, cancelTab := chromedp.NewContext(browser)
+	c := &chromeJS{tab: tab, stop: func() {
+		cancelTab()
+		cancelBrowser()
+		server.Close()
+	}}
+	forwardConsole(tab, log)
+
+	var loadErr string
+	err = chromedp.Run(tab,
+		chromedp.Navigate("http://"+listener.Addr().String()+"/"),
+		chromedp.Evaluate(fmt.Sprintf("bench.load(%q)", "/tasks/"+task+"/js/"+task+".js"), &loadErr, awaitPromise))
+	if err == nil && loadErr != "" {
+		err = errors.New(loadErr)
+	}
+	if err != nil {
+		c.stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+// jsHarnessHandler serves the driver's page, cross-origin isolated as the
+// modules' is, and the tasks directory dir under /tasks/ for its imports
+func jsHarnessHandler(dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
+		w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, jsHarnessPage)
+	})
+	mux.Handle("GET /tasks/", http.StripPrefix("/tasks/", http.FileServer(http.Dir(dir))))
+	return mux
+}
+
+// jsHarnessPage runs the driver as a module script, which has run by the
+// time the page has loaded
+const jsHarnessPage = `<!DOCTYPE html>
+<meta charset="utf-8">
+<title>wasmbench JavaScript baseline</title>
+<script type="module">` + jsDriver + `
+window.bench = bench;
+</script>
+`
+
+func (c *chromeJS) call(ctx context.Context, out any, method string, args ...any) error {
+	data, err := json.Marshal(append([]any{}, args...))
+	if err != nil {
+		return err
+	}
+	if err := chromedp.Run(c.tab, chromedp.Evaluate(fmt.Sprintf("bench.%s(...%s)", method, data), out, awaitPromise)); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+func (c *chromeJS) close() error {
+	c.stop()
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build chromedp
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"testing"
+)
+
+func TestChromeRunsModule(t *testing.T) {
+	found := false
+	for _, name := range []string{"headless-shell", "chromium", "chromium-browser", "google-chrome", "google-chrome-stable"} {
+		if _, err := exec.LookPath(name); err == nil {
+			found = true
+		}
+	}
+	if !found {
+		t.Skip("no Chrome or Chromium installed")
+	}
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-runtime", "chrome", "-warmup", "1", "-runs", "3", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a JSON result: %v\n%s", err, stdout.String())
+	}
+	if result.Hash != 15 || len(result.SamplesMs) != 3 {
+		t.Errorf("hash %d with %d runs, expected 15 with 3", result.Hash, len(result.SamplesMs))
+	}
+	if m := result.Memory; m == nil || m.InitialBytes != 65536 {
+		t.Errorf("memory %+v, expected fakeTask's single page", m)
+	}
+}
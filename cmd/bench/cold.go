@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// ColdStart is what starting a module costs, with -cold: each of Starts
+// fresh instances timed from the module's bytes to the end of its first
+// run_task, in three parts, apart from the steady-state runs that follow the
+// warm-up. Every runtime compiles the module anew for each instance.
+type ColdStart struct {
+	Starts        int           `json:"starts"`
+	InstantiateMs []float64     `json:"instantiate_ms"` // Compiling and instantiating the module, _initialize included
+	SetupMs       []float64     `json:"setup_ms"`       // get_task_info, init, self_test, and writing and validating the params
+	FirstRunMs    []float64     `json:"first_run_ms"`   // The first run_task of the instance
+	Instantiate   stats.Summary `json:"instantiate"`
+	Setup         stats.Summary `json:"setup"`
+	FirstRun      stats.Summary `json:"first_run"`
+	// Median first run over the median steady-state run: what a cold call
+	// costs beyond a warm one
+	FirstRunRatio float64 `json:"first_run_ratio,omitempty"`
+
+	hash uint32 // Of the first runs
+}
+
+// measureColdStarts loads the module opts.coldStarts times, each time into a
+// fresh instance, and times its instantiation, its setup and its first run
+func (r *Result) measureColdStarts(ctx context.Context, wasm []byte, opts options) error {
+	c := &ColdStart{Starts: opts.coldStarts}
+	r.progress.setPhase(r.Task, "cold", opts.coldStarts)
+	for i := range opts.coldStarts {
+		start := time.Now()
+		m, ptr, err := r.load(ctx, wasm, opts)
+		if err != nil {
+			return err
+		}
+		loaded := time.Since(start)
+		start = time.Now()
+		hash, err := m.runTask(ctx, ptr)
+		firstRun := time.Since(start)
+		m.close(ctx)
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			c.hash = hash
+		} else if hash != c.hash {
+			return fmt.Errorf("nondeterministic: cold start %d hashed %d, the first %d", i+1, hash, c.hash)
+		}
+		c.InstantiateMs = append(c.InstantiateMs, float64(m.instantiated)/float64(time.Millisecond))
+		c.SetupMs = append(c.SetupMs, float64(loaded-m.instantiated)/float64(time.Millisecond))
+		c.FirstRunMs = append(c.FirstRunMs, float64(firstRun)/float64(time.Millisecond))
+		r.progress.run(c.FirstRunMs[i])
+	}
+	c.Instantiate, c.Setup, c.FirstRun = stats.Summarize(c.InstantiateMs), stats.Summarize(c.SetupMs), stats.Summarize(c.FirstRunMs)
+	r.ColdStart = c
+	return nil
+}
+
+// compare checks the first runs' hash against the steady-state runs' and
+// sets FirstRunRatio; a cold run that hashes differently found state that
+// only a fresh instance has
+func (c *ColdStart) compare(r *Result) error {
+	if c == nil {
+		return nil
+	}
+	if c.hash != r.Hash {
+		return fmt.Errorf("cold first runs hashed %d, the steady-state runs %d", c.hash, r.Hash)
+	}
+	if r.Stats.Median > 0 {
+		c.FirstRunRatio = c.FirstRun.Median / r.Stats.Median
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRunColdStarts(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-cold", "3", "-warmup", "1", "-runs", "2", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	c := result.ColdStart
+	if c == nil || c.Starts != 3 || len(c.InstantiateMs) != 3 || len(c.SetupMs) != 3 || len(c.FirstRunMs) != 3 {
+		t.Fatalf("cold start %+v, expected 3 timed starts", c)
+	}
+	if c.Instantiate.Median <= 0 || c.FirstRun.N == 0 || c.FirstRunRatio <= 0 {
+		t.Errorf("cold start %+v, expected instantiation times and a first run ratio", c)
+	}
+	// The steady-state runs are the usual ones
+	if len(result.SamplesMs) != 2 || result.Hash != 15 {
+		t.Errorf("%d samples hashing %d, expected 2 hashing 15", len(result.SamplesMs), result.Hash)
+	}
+
+	if code := run([]string{"-cold", "3", "-determinism", "2", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -determinism, expected 2", code)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// determinismRuns are the runs of each instantiation in -determinism mode:
+// the second catches state the first leaves behind
+const determinismRuns = 2
+
+// checkDeterminism loads the module opts.determinism times, each time into a
+// fresh instance, and runs the task determinismRuns times in each. Every run
+// has the same params and seed, so every hash must equal the first one; one
+// that differs points at uninitialized memory, state leaking between runs,
+// or float results that depend on evaluation order.
+func (r *Result) checkDeterminism(ctx context.Context, wasm []byte, opts options) error {
+	for i := 1; i <= opts.determinism; i++ {
+		m, ptr, err := r.load(ctx, wasm, opts)
+		if err != nil {
+			return err
+		}
+		err = r.compareRuns(i, determinismRuns, func() (uint32, error) { return m.runTask(ctx, ptr) })
+		m.close(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	r.Instantiations = opts.determinism
+	return nil
+}
+
+// compareRuns runs the task runs times as instantiation i, checking each
+// hash against r.Hash, which the first run of instantiation 1 sets
+func (r *Result) compareRuns(i, runs int, runTask func() (uint32, error)) error {
+	for run := 1; run <= runs; run++ {
+		hash, err := runTask()
+		if err != nil {
+			return err
+		}
+		r.verify(hash)
+		if i == 1 && run == 1 {
+			r.Hash = hash
+		} else if hash != r.Hash {
+			return fmt.Errorf("nondeterministic: instantiation %d run %d hashed %d, the first run %d", i, run, hash, r.Hash)
+		}
+	}
+	return r.verificationError()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// counterTask is fakeTask with a run_task that returns a count of its calls,
+// kept in a mutable global: the state one run leaves to the next
+var counterTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Globals: mutable i32 0
+	0x06, 0x06, 0x01, 0x7f, 0x01, 0x41, 0x00, 0x0b,
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0a, 0x16, 0x03,
+	0x02, 0x00, 0x0b,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x0b, 0x00, 0x23, 0x00, 0x41, 0x01, 0x6a, 0x24, 0x00, 0x23, 0x00, 0x0b, // run_task: ++count
+}
+
+func TestRunDeterminism(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-determinism", "3", "-params", `{"dimension": 7}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Instantiations != 3 || result.Hash != 21 || len(result.SamplesMs) != 0 {
+		t.Errorf("result %+v, expected hash 21 from 3 instantiations and no timed runs", result)
+	}
+}
+
+func TestRunDeterminismCatchesLeakedState(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", counterTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-determinism", "2", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1", code)
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Error, "nondeterministic: instantiation 1 run 2 hashed 2, the first run 1") {
+		t.Errorf("error %q, expected the second run's hash", result.Error)
+	}
+}
+
+func TestRunDeterminismNative(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	// The native hash is a real matrix product, which fakeTask's is not
+	if code := run([]string{"-determinism", "2", "-native", "-params", `{"dimension": 4}`, path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1", code)
+	}
+	decoder := json.NewDecoder(&stdout)
+	var native, result Result
+	if err := decoder.Decode(&native); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if native.Error != "" || native.Instantiations != 2 {
+		t.Errorf("native %+v, expected 2 matching rounds", native)
+	}
+	if !strings.Contains(result.Error, "differs from native") {
+		t.Errorf("error %q, expected a hash mismatch with native Go", result.Error)
+	}
+}
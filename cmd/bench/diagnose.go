@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"wasmbench/common"
+)
+
+// statusNames and errorCodeNames are the names data/error_codes.json gives
+// the statuses and the parameter error codes of common
+var (
+	statusNames    = []string{"ok", "invalid_params", "overflow", "verification_failed", "panicked", "cancelled"}
+	errorCodeNames = []string{"none", "null_params", "bad_encoding", "zero_dimension", "too_large", "non_finite",
+		"non_positive", "unknown_scale", "unknown_profile", "unknown_verification", "unknown_allocator",
+		"unknown_hash_algorithm", "unknown_generator"}
+)
+
+// optionFields are the params fields an unknown_* error code is about, with
+// the values the field takes
+var optionFields = map[uint32]struct {
+	field string
+	valid string
+}{
+	common.ErrUnknownScale:         {"scale", fmt.Sprintf("0 (the custom sizes) to %d", common.ScaleLarge)},
+	common.ErrUnknownProfile:       {"profile", "0 (default), 1 (compute) or 2 (memory)"},
+	common.ErrUnknownVerification:  {"verification", "0 (hash), 1 (none) or 2 (full)"},
+	common.ErrUnknownAllocator:     {"allocator", "0 (heap) or 1 (arena)"},
+	common.ErrUnknownHashAlgorithm: {"hash_algorithm", "0 (FNV-1a) or 1 (xxHash32)"},
+	common.ErrUnknownGenerator:     {"generator", "0 (LCG), 1 (PCG32) or 2 (host, which needs the env.next_random import)"},
+}
+
+// taxonomyName returns names[i], or i itself for a value the taxonomy does
+// not know, from a module newer than the runner
+func taxonomyName(names []string, i uint32) string {
+	if int(i) < len(names) {
+		return names[i]
+	}
+	return strconv.FormatUint(uint64(i), 10)
+}
+
+// diagnose explains a task's rejection of params, reported by what with status
+// and, from get_error_code, code: the taxonomy's names for both, the module's
+// own message from get_last_error and what to change in -params. A code of 0
+// leaves the module's message alone, since a module without get_error_code
+// reports no more.
+func diagnose(what string, status, code uint32, message string, params map[string]json.Number) error {
+	if message == "" {
+		message = "no message"
+	}
+	if code == common.ErrNone {
+		return fmt.Errorf("%s (status %d %s): %s", what, status, taxonomyName(statusNames, status), message)
+	}
+	return fmt.Errorf("%s (status %d %s, error code %d %s): %s; %s", what, status, taxonomyName(statusNames, status),
+		code, taxonomyName(errorCodeNames, code), message, remedy(code, params))
+}
+
+// remedy suggests the change to params that gets past code
+func remedy(code uint32, params map[string]json.Number) string {
+	if option, ok := optionFields[code]; ok {
+		return fmt.Sprintf("%s is %s, expected %s", option.field, params[option.field], option.valid)
+	}
+	switch code {
+	case common.ErrNullParams, common.ErrBadEncoding:
+		return "the module and the runner disagree on the params layout; rebuild the module against configs/tasks.json"
+	case common.ErrZeroDimension:
+		return `set the field to at least 1 in -params, or set "scale" to a tier for the task's own sizes`
+	case common.ErrTooLarge:
+		if warmups, _ := params["warmup_iterations"].Int64(); warmups > common.MaxWarmupIterations {
+			return fmt.Sprintf("warmup_iterations is %d, over the maximum of %d", warmups, common.MaxWarmupIterations)
+		}
+		return `lower the field under the task's limit in -params, or set "scale" to a tier, whose sizes stay within it`
+	case common.ErrNonFinite:
+		return "give the float fields finite values in -params"
+	case common.ErrNonPositive:
+		return "give the field a value above 0 in -params"
+	}
+	return "see data/error_codes.json"
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"wasmbench/common"
+)
+
+func TestDiagnose(t *testing.T) {
+	params := map[string]json.Number{"dimension": "64", "profile": "7", "warmup_iterations": "500"}
+	for _, c := range []struct {
+		status, code uint32
+		message      string
+		want         string
+	}{
+		{common.StatusInvalidParams, common.ErrUnknownProfile, "unknown workload profile",
+			"invalid parameters (status 1 invalid_params, error code 8 unknown_profile): unknown workload profile; profile is 7, expected 0 (default), 1 (compute) or 2 (memory)"},
+		{common.StatusOverflow, common.ErrTooLarge, "warm-up iterations exceed the maximum",
+			"invalid parameters (status 2 overflow, error code 4 too_large): warm-up iterations exceed the maximum; warmup_iterations is 500, over the maximum of 100"},
+		{common.StatusVerificationFailed, common.ErrNone, "",
+			"invalid parameters (status 3 verification_failed): no message"},
+		{9, 40, "from a newer module",
+			"invalid parameters (status 9 9, error code 40 40): from a newer module; see data/error_codes.json"},
+	} {
+		if err := diagnose("invalid parameters", c.status, c.code, c.message, params); err.Error() != c.want {
+			t.Errorf("status %d, code %d: %q, expected %q", c.status, c.code, err, c.want)
+		}
+	}
+}
+
+// The runner's names of the statuses and codes are the taxonomy file's
+func TestDiagnoseTaxonomy(t *testing.T) {
+	data, err := os.ReadFile("../../data/error_codes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var taxonomy struct {
+		Statuses   []string `json:"statuses"`
+		ErrorCodes []string `json:"error_codes"`
+	}
+	if err := json.Unmarshal(data, &taxonomy); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(statusNames, taxonomy.Statuses) || !slices.Equal(errorCodeNames, taxonomy.ErrorCodes) {
+		t.Errorf("statuses %v and codes %v, expected data/error_codes.json's %v and %v",
+			statusNames, errorCodeNames, taxonomy.Statuses, taxonomy.ErrorCodes)
+	}
+}
+
+func TestNativeRejectionDiagnosed(t *testing.T) {
+	opts := options{params: `{"dimension": 5000}`, runs: 1, log: io.Discard}
+	result := benchNative(context.Background(), "matrix_mul", opts)
+	if !strings.Contains(result.Error, "run_task failed (status 2 overflow, error code 4 too_large): dimension exceeds the maximum matrix dimension; lower") {
+		t.Errorf("error %q, expected the too_large diagnosis", result.Error)
+	}
+}
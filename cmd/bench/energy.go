@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"wasmbench/stats"
+)
+
+// powercapDir is where Linux exposes RAPL (Running Average Power Limit)
+// energy counters, on Intel and, since 5.8, AMD processors
+var powercapDir = "/sys/class/powercap"
+
+// EnergyUsage is the energy of each measured run, with -energy: what the
+// processor packages consumed from the start of run_task to its return, read
+// from their RAPL counters. The counters cover the whole package, every core
+// and process, so an idle host is needed for the joules to be the module's.
+type EnergyUsage struct {
+	Domains      []string  `json:"domains"` // The RAPL domains summed, e.g. package-0
+	Joules       []float64 `json:"joules"`
+	MedianJoules float64   `json:"median_joules"`
+	Watts        float64   `json:"watts"` // Mean power over every measured run
+
+	seconds float64 // Of every measured run
+}
+
+// record adds a run's joules, taken over ms
+func (e *EnergyUsage) record(joules, ms float64) {
+	e.Joules = append(e.Joules, joules)
+	e.MedianJoules = stats.Median(e.Joules)
+	var total float64
+	for _, j := range e.Joules {
+		total += j
+	}
+	e.seconds += ms / 1000
+	if e.seconds > 0 {
+		e.Watts = total / e.seconds
+	}
+}
+
+// raplDomain is a package's energy counter, in microjoules, which wraps
+// around after maxRange
+type raplDomain struct {
+	name     string
+	counter  string
+	maxRange uint64
+}
+
+// energyMeter reads the RAPL counters of every processor package
+type energyMeter struct {
+	domains []raplDomain
+}
+
+// openEnergy finds the package domains under dir. Their subdomains (core,
+// uncore, dram) and the platform's psys domain overlap the packages, so only
+// the packages are summed.
+func openEnergy(dir string) (*energyMeter, error) {
+	zones, err := filepath.Glob(filepath.Join(dir, "intel-rapl:*"))
+	if err != nil {
+		return nil, err
+	}
+	e := &energyMeter{}
+	for _, zone := range zones {
+		if strings.Count(filepath.Base(zone), ":") != 1 {
+			continue
+		}
+		name, err := os.ReadFile(filepath.Join(zone, "name"))
+		if err != nil || !strings.HasPrefix(string(name), "package") {
+			continue
+		}
+		maxRange, err := readUint(filepath.Join(zone, "max_energy_range_uj"))
+		if err != nil {
+			return nil, err
+		}
+		e.domains = append(e.domains, raplDomain{strings.TrimSpace(string(name)), filepath.Join(zone, "energy_uj"), maxRange})
+	}
+	if len(e.domains) == 0 {
+		return nil, fmt.Errorf("no RAPL package domains under %s", dir)
+	}
+	// Since Linux 5.10 the counters are readable by root only
+	if _, err := e.read(); errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("%w; energy_uj is readable by root only, so run as root or make it readable", err)
+	} else if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// names returns the domains' names
+func (e *energyMeter) names() []string {
+	names := make([]string, len(e.domains))
+	for i, d := range e.domains {
+		names[i] = d.name
+	}
+	return names
+}
+
+// read returns every domain's counter
+func (e *energyMeter) read() ([]uint64, error) {
+	counters := make([]uint64, len(e.domains))
+	for i, d := range e.domains {
+		var err error
+		if counters[i], err = readUint(d.counter); err != nil {
+			return nil, err
+		}
+	}
+	return counters, nil
+}
+
+// joules returns the energy between two reads, through any wraparound
+func (e *energyMeter) joules(before, after []uint64) float64 {
+	var microjoules uint64
+	for i, d := range e.domains {
+		if after[i] >= before[i] {
+			microjoules += after[i] - before[i]
+		} else {
+			microjoules += d.maxRange - before[i] + after[i]
+		}
+	}
+	return float64(microjoules) / 1e6
+}
+
+// readUint reads a sysfs file of one decimal number
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writePowercap writes a powercap tree of zones, each name and energy_uj
+func writePowercap(t *testing.T, zones map[string][2]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for zone, files := range zones {
+		path := filepath.Join(dir, zone)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string]string{"name": files[0] + "\n", "energy_uj": files[1] + "\n", "max_energy_range_uj": "1000000\n"} {
+			if err := os.WriteFile(filepath.Join(path, name), []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+func TestOpenEnergy(t *testing.T) {
+	dir := writePowercap(t, map[string][2]string{
+		"intel-rapl:0":   {"package-0", "5000"},
+		"intel-rapl:0:0": {"core", "2000"},
+		"intel-rapl:1":   {"package-1", "999000"},
+		"intel-rapl:2":   {"psys", "7000"},
+	})
+	meter, err := openEnergy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The core subdomain and psys overlap the packages
+	if names := meter.names(); !slices.Equal(names, []string{"package-0", "package-1"}) {
+		t.Errorf("domains %v, expected the two packages", names)
+	}
+	before, err := meter.read()
+	if err != nil || !slices.Equal(before, []uint64{5000, 999000}) {
+		t.Fatalf("counters %v, %v", before, err)
+	}
+	// package-1 wraps around its range of 1 J
+	if joules := meter.joules(before, []uint64{255000, 4000}); joules != 0.255 {
+		t.Errorf("%g J, expected 0.25 from package-0 and 0.005 from package-1", joules)
+	}
+
+	if _, err := openEnergy(t.TempDir()); err == nil {
+		t.Error("opened an empty powercap tree")
+	}
+}
+
+func TestEnergyUsageRecord(t *testing.T) {
+	var e EnergyUsage
+	e.record(0.5, 100)
+	e.record(1.5, 300)
+	e.record(1, 100)
+	if len(e.Joules) != 3 || e.MedianJoules != 1 || e.Watts != 6 {
+		t.Errorf("usage %+v, expected a median of 1 J and 3 J over 0.5 s", e)
+	}
+}
+
+func TestRunEnergy(t *testing.T) {
+	defer func(dir string) { powercapDir = dir }(powercapDir)
+	powercapDir = writePowercap(t, map[string][2]string{"intel-rapl:0": {"package-0", "100"}})
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-energy", "-warmup", "0", "-runs", "2", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if e := result.Energy; e == nil || !slices.Equal(e.Domains, []string{"package-0"}) || len(e.Joules) != 2 {
+		t.Errorf("energy %+v, expected both runs' of package-0", e)
+	}
+
+	if code := run([]string{"-energy", "-parallel", "2", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -parallel 2, expected 2", code)
+	}
+	powercapDir = t.TempDir()
+	if code := run([]string{"-energy", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d without RAPL, expected 1", code)
+	}
+}
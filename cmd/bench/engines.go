@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"wasmbench/stats"
+)
+
+// engineSignificance is the p-value under which an engine comparison is
+// significant
+const engineSignificance = 0.05
+
+// EngineComparison is a module run under two runtimes in the same session,
+// on the same agent, at the same params, scale and repetition: its median
+// under Runtime over its median under Baseline, the runtime it ran under
+// first, with the Mann-Whitney U test of the two results' runs
+type EngineComparison struct {
+	Task        string                 `json:"task"`
+	Module      string                 `json:"module"` // File name
+	Agent       string                 `json:"agent,omitempty"`
+	Params      map[string]json.Number `json:"params,omitempty"`
+	Scale       string                 `json:"scale,omitempty"`
+	Repetition  int                    `json:"repetition,omitempty"`
+	Runtime     string                 `json:"runtime"`
+	Baseline    string                 `json:"baseline"`
+	Ratio       float64                `json:"ratio"`
+	FuelRatio   float64                `json:"fuel_ratio,omitempty"` // Of the median fuel, when both runtimes meter it
+	P           float64                `json:"p"`
+	Significant bool                   `json:"significant"` // P is below engineSignificance
+}
+
+// parseEngines splits a -runtime list, e.g. wazero,wasmtime, checking each
+func parseEngines(list string) ([]string, error) {
+	engines := strings.Split(list, ",")
+	for i, engine := range engines {
+		if _, ok := runtimes[engine]; !ok {
+			return nil, fmt.Errorf("unknown -runtime %q (wasmtime needs -tags wasmtime, chrome -tags chromedp)", engine)
+		}
+		if slices.Contains(engines[:i], engine) {
+			return nil, fmt.Errorf("-runtime %s is listed twice", engine)
+		}
+	}
+	return engines, nil
+}
+
+// enginePasses returns each pass once per engine, in the engines' order
+func enginePasses(passes []pass, engines []string) []pass {
+	var expanded []pass
+	for _, p := range passes {
+		for _, engine := range engines {
+			p.opts.runtime = engine
+			expanded = append(expanded, p)
+		}
+	}
+	return expanded
+}
+
+// compareEngines compares each module that ran under more than one runtime
+// with itself under the first of them, in session order. Failed results and
+// native and JavaScript baselines are left out.
+func compareEngines(results []Result) []EngineComparison {
+	type key struct {
+		task, module, agent, params, scale string
+		repetition                         int
+	}
+	baselines := map[key]*Result{}
+	var comparisons []EngineComparison
+	for i := range results {
+		r := &results[i]
+		if r.Error != "" || r.Runtime == "native" || r.Language == "js" || len(r.SamplesMs) == 0 {
+			continue
+		}
+		params, _ := json.Marshal(r.Params)
+		k := key{r.Task, r.Module, r.Agent, string(params), r.Scale, r.Repetition}
+		baseline, ok := baselines[k]
+		if !ok {
+			baselines[k] = r
+			continue
+		}
+		if baseline.Runtime == r.Runtime || baseline.Stats.Median == 0 {
+			continue
+		}
+		c := EngineComparison{Task: r.Task, Module: filepath.Base(r.Module), Agent: r.Agent, Params: r.Params, Scale: r.Scale, Repetition: r.Repetition,
+			Runtime: r.Runtime, Baseline: baseline.Runtime, Ratio: r.Stats.Median / baseline.Stats.Median,
+			P: stats.MannWhitney(baseline.SamplesMs, r.SamplesMs)}
+		if len(r.Fuel) > 0 && len(baseline.Fuel) > 0 {
+			c.FuelRatio = medianFuel(r.Fuel) / medianFuel(baseline.Fuel)
+		}
+		c.Significant = c.P < engineSignificance
+		comparisons = append(comparisons, c)
+	}
+	return comparisons
+}
+
+// medianFuel returns the median of fuel counts
+func medianFuel(fuel []uint64) float64 {
+	values := make([]float64, len(fuel))
+	for i, f := range fuel {
+		values[i] = float64(f)
+	}
+	return stats.Median(values)
+}
+
+// writeEngines prints the comparisons as a table
+func writeEngines(w io.Writer, comparisons []EngineComparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "task\tmodule\tscale\truntime\tbaseline\tratio\tp\t\n")
+	for _, c := range comparisons {
+		note := ""
+		if !c.Significant {
+			note = " (n.s.)"
+		}
+		module := c.Module
+		if c.Agent != "" {
+			module = c.Agent + "/" + module
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.3f×%s\t%.2g\t\n", c.Task, module, c.Scale, c.Runtime, c.Baseline, c.Ratio, note, c.P)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunEngines(t *testing.T) {
+	// A second engine, which is wazero under another name
+	runtimes["wazero2"] = runtimes["wazero"]
+	t.Cleanup(func() { delete(runtimes, "wazero2") })
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	sessionPath := filepath.Join(t.TempDir(), "session.json")
+	var stdout, stderr bytes.Buffer
+	args := []string{"-runtime", "wazero,wazero2", "-warmup", "0", "-runs", "4", "-json", sessionPath, path}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(sessionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if len(session.Results) != 2 || session.Results[0].Runtime != "wazero" || session.Results[1].Runtime != "wazero2" {
+		t.Fatalf("results %+v, expected the module under wazero then wazero2", session.Results)
+	}
+	if len(session.Engines) != 1 {
+		t.Fatalf("comparisons %+v, expected wazero2 against wazero", session.Engines)
+	}
+	if c := session.Engines[0]; c.Module != "matrix_mul-o2.wasm" || c.Runtime != "wazero2" || c.Baseline != "wazero" || c.Ratio <= 0 || c.P <= 0 {
+		t.Errorf("comparison %+v, expected wazero2 against wazero", c)
+	}
+	if !strings.Contains(stderr.String(), "baseline") {
+		t.Errorf("stderr %q, expected the comparison table", stderr.String())
+	}
+
+	for _, list := range []string{"wazero,wazero", "wazero,v8"} {
+		if code := run([]string{"-runtime", list, path}, &stdout, &stderr); code != 2 {
+			t.Errorf("exit status %d for -runtime %s, expected 2", code, list)
+		}
+	}
+}
+
+func TestCompareEngines(t *testing.T) {
+	result := func(runtime, scale string, ms ...float64) Result {
+		r := Result{Module: "builds/rust/matrix_mul-o3.wasm", Runtime: runtime, Task: "matrix_mul", Scale: scale, SamplesMs: ms}
+		r.Stats.Median = ms[len(ms)/2]
+		return r
+	}
+	comparisons := compareEngines([]Result{
+		result("wazero", "small", 10, 10, 10, 10, 10, 10, 10, 10),
+		result("wazero", "large", 100, 100, 100),
+		result("wasmtime", "small", 5, 5, 5, 5, 5, 5, 5, 5),
+		{Module: "native", Runtime: "native", Task: "matrix_mul", SamplesMs: []float64{1}},
+		result("wasmtime", "large", 99, 100, 101),
+		{Module: "builds/rust/matrix_mul-o3.wasm", Runtime: "chrome", Task: "matrix_mul", Scale: "large", Error: "no browser"},
+	})
+	if len(comparisons) != 2 {
+		t.Fatalf("comparisons %+v, expected wasmtime against wazero at each scale", comparisons)
+	}
+	if c := comparisons[0]; c.Scale != "small" || c.Ratio != 0.5 || !c.Significant {
+		t.Errorf("small %+v, expected half the time, significantly", c)
+	}
+	if c := comparisons[1]; c.Scale != "large" || c.Ratio != 1 || c.Significant {
+		t.Errorf("large %+v, expected the same time, not significantly", c)
+	}
+}
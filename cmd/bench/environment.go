@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+)
+
+// runtimeModules are the Go modules that implement the runtimes, whose
+// versions a session records for the runtimes built in
+var runtimeModules = map[string]string{
+	"wazero":   "github.com/tetratelabs/wazero",
+	"wasmtime": "github.com/bytecodealliance/wasmtime-go/v48",
+	"chrome":   "github.com/chromedp/chromedp",
+}
+
+// captureHost fills in what the host can tell about itself: its kernel, CPU
+// model and frequency governor, and the versions of the runtimes built into
+// the runner. Anything the host does not expose is left empty.
+func (e *Environment) captureHost() {
+	e.Kernel = kernelVersion()
+	e.CPUModel = cpuModel()
+	e.Governor = cpuGovernor()
+	e.Runtimes = map[string]string{}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for name, path := range runtimeModules {
+			if _, built := runtimes[name]; !built {
+				continue
+			}
+			for _, dep := range info.Deps {
+				if dep.Path == path {
+					e.Runtimes[name] = dep.Version
+				}
+			}
+		}
+	}
+}
+
+// kernelVersion returns the kernel release, like uname -r
+func kernelVersion() string {
+	if data, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	out, err := exec.Command("uname", "-r").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cpuModel returns the CPU's model name
+func cpuModel() string {
+	if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
+		return cpuInfoModel(string(data))
+	}
+	out, err := exec.Command("sysctl", "-n", "machdep.cpu.brand_string").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cpuInfoModel takes the model name from /proc/cpuinfo: "model name" on x86,
+// "Model" on most ARM boards
+func cpuInfoModel(cpuinfo string) string {
+	var model string
+	for line := range strings.Lines(cpuinfo) {
+		key, value, ok := strings.Cut(line, ":")
+		switch key = strings.TrimSpace(key); {
+		case !ok:
+		case key == "model name":
+			return strings.TrimSpace(value)
+		case key == "Model" && model == "":
+			model = strings.TrimSpace(value)
+		}
+	}
+	return model
+}
+
+// cpuGovernor returns the cpufreq scaling governor of the CPUs, such as
+// performance or powersave, the distinct ones comma-separated when they
+// differ. It is empty where the kernel exposes none, as in most VMs.
+func cpuGovernor() string {
+	paths, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor")
+	var governors []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if governor := strings.TrimSpace(string(data)); err == nil && !slices.Contains(governors, governor) {
+			governors = append(governors, governor)
+		}
+	}
+	slices.Sort(governors)
+	return strings.Join(governors, ",")
+}
+
+// recordToolchain adds the toolchain that built r's module, by language, to
+// the toolchains the session's modules were built with
+func (e *Environment) recordToolchain(r *Result) {
+	language := r.Language
+	if language == "" {
+		language = filepath.Base(filepath.Dir(r.Module))
+	}
+	if r.Toolchain != "" {
+		e.Toolchains[language] = r.Toolchain
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCPUInfoModel(t *testing.T) {
+	for _, c := range []struct{ cpuinfo, expected string }{
+		{"processor\t: 0\nvendor_id\t: GenuineIntel\nmodel\t\t: 85\nmodel name\t: Intel(R) Xeon(R) Gold 6248 CPU @ 2.50GHz\n\nprocessor\t: 1\n", "Intel(R) Xeon(R) Gold 6248 CPU @ 2.50GHz"},
+		{"processor\t: 0\nBogoMIPS\t: 108.00\nCPU part\t: 0xd08\n\nHardware\t: BCM2835\nModel\t\t: Raspberry Pi 4 Model B Rev 1.4\n", "Raspberry Pi 4 Model B Rev 1.4"},
+		{"processor\t: 0\n", ""},
+	} {
+		if model := cpuInfoModel(c.cpuinfo); model != c.expected {
+			t.Errorf("cpuInfoModel = %q, expected %q", model, c.expected)
+		}
+	}
+}
+
+func TestRunRecordsEnvironment(t *testing.T) {
+	builds := t.TempDir()
+	metrics := `{"tinygo": {"language": "tinygo", "toolchain": "tinygo version 0.39.0 linux/amd64"}}`
+	if err := os.WriteFile(filepath.Join(builds, "metrics.json"), []byte(metrics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(builds, "tinygo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	module := filepath.Join(builds, "tinygo", "matrix_mul-o2.wasm")
+	if err := os.WriteFile(module, fakeTask, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(t.TempDir(), "session.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-warmup", "0", "-runs", "1", "-json", jsonPath, module}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	env := session.Environment
+	if env.Toolchains["tinygo"] != "tinygo version 0.39.0 linux/amd64" {
+		t.Errorf("toolchains %v, expected the module's TinyGo", env.Toolchains)
+	}
+	if env.Runtimes["wazero"] == "" {
+		t.Errorf("runtimes %v, expected wazero's version", env.Runtimes)
+	}
+	if runtime.GOOS == "linux" && (env.Kernel == "" || env.CPUModel == "") {
+		t.Errorf("kernel %q and CPU model %q, expected this host's", env.Kernel, env.CPUModel)
+	}
+}
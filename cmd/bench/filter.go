@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// filter narrows a session to the benchmarks whose task, category, scale,
+// runtime and language match its globs, path.Match patterns such as json_*
+// or m[ae]*, or a comma-separated list of them, "" matching anything. Only a
+// -plan has more than one task per module, scale and runtime to pick from, so
+// without one only the category and language globs are set.
+type filter struct {
+	task, category, size, runtime, language string
+}
+
+// check rejects malformed patterns before anything runs
+func (f filter) check() error {
+	for _, pattern := range []string{f.task, f.category, f.size, f.runtime, f.language} {
+		for _, glob := range strings.Split(pattern, ",") {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("filter %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// match reports whether value matches pattern or any glob of its list, ""
+// matching anything
+func match(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, glob := range strings.Split(pattern, ",") {
+		if ok, _ := path.Match(glob, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the passes that match, each with its matching modules.
+// Without modules the passes run tasks natively, as -profile does, and are
+// kept when their own task, scale and runtime match.
+func (f filter) apply(passes []pass, modules bool) []pass {
+	var kept []pass
+	for _, p := range passes {
+		if !match(f.size, p.opts.scale) || !match(f.runtime, p.opts.runtime) {
+			continue
+		}
+		if !modules {
+			if f.matchTask(p.opts.task) {
+				kept = append(kept, p)
+			}
+			continue
+		}
+		var matching []string
+		for _, module := range p.modules {
+			task := p.opts.task
+			if task == "" {
+				task = taskFromFileName(module)
+			}
+			if f.matchTask(task) && match(f.language, filepath.Base(filepath.Dir(module))) {
+				matching = append(matching, module)
+			}
+		}
+		if len(matching) > 0 {
+			p.modules = matching
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// matchTask reports whether task and its category match
+func (f filter) matchTask(task string) bool {
+	return match(f.task, task) && match(f.category, tasks[task].category)
+}
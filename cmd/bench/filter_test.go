@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterApply(t *testing.T) {
+	modules := []string{"builds/tinygo/matrix_mul-o2.wasm", "builds/rust/matrix_mul-o3.wasm", "builds/tinygo/mandelbrot-o2.wasm", "builds/rust/json_parse-o3.wasm"}
+	var passes []pass
+	for _, step := range []struct{ task, scale, runtime string }{
+		{"matrix_mul", "small", "wazero"}, {"matrix_mul", "large", "wasmtime"}, {"mandelbrot", "medium", "wazero"}, {"json_parse", "small", "wazero"},
+	} {
+		p := pass{opts: options{task: step.task, scale: step.scale, runtime: step.runtime}}
+		for _, module := range modules {
+			if taskFromFileName(module) == step.task {
+				p.modules = append(p.modules, module)
+			}
+		}
+		passes = append(passes, p)
+	}
+
+	for _, test := range []struct {
+		name     string
+		only     filter
+		expected []string
+	}{
+		{"task glob", filter{task: "m*"}, []string{
+			"matrix_mul small wazero builds/tinygo/matrix_mul-o2.wasm builds/rust/matrix_mul-o3.wasm",
+			"matrix_mul large wasmtime builds/tinygo/matrix_mul-o2.wasm builds/rust/matrix_mul-o3.wasm",
+			"mandelbrot medium wazero builds/tinygo/mandelbrot-o2.wasm",
+		}},
+		{"category", filter{category: "memory"}, []string{
+			"matrix_mul small wazero builds/tinygo/matrix_mul-o2.wasm builds/rust/matrix_mul-o3.wasm",
+			"matrix_mul large wasmtime builds/tinygo/matrix_mul-o2.wasm builds/rust/matrix_mul-o3.wasm",
+		}},
+		{"size and runtime", filter{size: "[sm]*", runtime: "wazero"}, []string{
+			"matrix_mul small wazero builds/tinygo/matrix_mul-o2.wasm builds/rust/matrix_mul-o3.wasm",
+			"mandelbrot medium wazero builds/tinygo/mandelbrot-o2.wasm",
+			"json_parse small wazero builds/rust/json_parse-o3.wasm",
+		}},
+		// A pass left without modules is dropped
+		{"language", filter{language: "rust", runtime: "wazero"}, []string{
+			"matrix_mul small wazero builds/rust/matrix_mul-o3.wasm",
+			"json_parse small wazero builds/rust/json_parse-o3.wasm",
+		}},
+		{"nothing", filter{task: "matrix_mul", category: "allocation"}, nil},
+	} {
+		var got []string
+		for _, p := range test.only.apply(passes, true) {
+			got = append(got, strings.Join(append([]string{p.opts.task, p.opts.scale, p.opts.runtime}, p.modules...), " "))
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: passes %q, expected %q", test.name, got, test.expected)
+		}
+	}
+
+	if err := (filter{size: "[small"}).check(); err == nil {
+		t.Error("a malformed glob passed the check")
+	}
+}
+
+func TestRunPlanFilter(t *testing.T) {
+	module := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	plan := filepath.Join(t.TempDir(), "plan.yaml")
+	err := os.WriteFile(plan, []byte(`
+environment: {warmup_runs: 0, measure_runs: 2}
+tasks:
+  matrix_mul:
+    scales:
+      small: {dimension: 4}
+      medium: {dimension: 5}
+      large: {dimension: 6}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-plan", plan, "-task", "matrix_*", "-size", "*l*", "-runtime", "wazero", module}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var scales []string
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		scales = append(scales, result.Scale)
+	}
+	if !reflect.DeepEqual(scales, []string{"small", "large"}) {
+		t.Errorf("scales %v, expected small and large", scales)
+	}
+
+	if code := run([]string{"-plan", plan, "-category", "compute", module}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d with nothing matching, expected 1", code)
+	}
+	if code := run([]string{"-size", "small", module}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -size but no plan, expected 2", code)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"wasmbench/common"
+)
+
+// fuzzCaseTimeout bounds each -fuzz run: past it the runner raises the
+// module's cancellation flag, so params a task accepts but takes long over
+// end with StatusCancelled
+const fuzzCaseTimeout = time.Second
+
+// fuzzMemoryPages is the budget -fuzz sets through set_memory_budget, 64MiB
+// above the memory the benchmark's params left the module with. A module
+// that takes one must reject the params whose runs would need more rather
+// than grow its memory past it.
+const fuzzMemoryPages = 1024
+
+// Fuzz is the outcome of a module's -fuzz cases: randomized and boundary
+// params structs, each validated and then run. Every case must end without
+// a trap or a panic, run_task_v2 must reject the params validate_params
+// rejects with the same status and accept the ones it accepts, and linear
+// memory must stay within the budget. After the cases the instance must
+// still hash the benchmark's params as it did before them.
+type Fuzz struct {
+	Seed      uint64 `json:"seed"`
+	Cases     int    `json:"cases"`
+	Accepted  int    `json:"accepted"`  // Validated and run to the end
+	Rejected  int    `json:"rejected"`  // By validate_params and run_task_v2 alike
+	Cancelled int    `json:"cancelled"` // Validated, then stopped past fuzzCaseTimeout
+	PeakBytes uint64 `json:"peak_bytes"`
+	// Of the memory budget the module took, 0 without set_memory_budget
+	BudgetBytes uint64 `json:"budget_bytes,omitempty"`
+}
+
+// fuzzExports are the exports -fuzz checks against each other
+var fuzzExports = []string{"validate_params", "run_task_v2"}
+
+// fuzz loads the module with the benchmark's params, then runs opts.fuzz
+// cases of params derived from them through validate_params and
+// run_task_v2 in the same instance, failing at the first case that breaks
+// the ABI's contract
+func (r *Result) fuzz(ctx context.Context, wasm []byte, opts options) error {
+	m, ptr, err := r.load(ctx, wasm, opts)
+	if err != nil {
+		return err
+	}
+	defer m.close(ctx)
+	for _, name := range fuzzExports {
+		if !m.exports(name) {
+			return fmt.Errorf("-fuzz needs the %s export", name)
+		}
+	}
+	spec := tasks[r.Task]
+	f := &Fuzz{Seed: opts.fuzzSeed}
+	r.Fuzz = f
+	if m.exports("set_memory_budget") {
+		pages := uint32(m.memorySize()/common.WasmPageSize) + fuzzMemoryPages
+		if _, err := m.call(ctx, "set_memory_budget", pages); err != nil {
+			return err
+		}
+		f.BudgetBytes = uint64(pages) * common.WasmPageSize
+	}
+
+	// The buffers are allocated once, large enough for an encoded params
+	// buffer, so the cases leave the module's allocator alone
+	size := max(int(spec.size), common.ParamsHeaderSize+int(common.PayloadSize(spec.fields)))
+	base, ok := m.readMemory(ptr, uint32(spec.size))
+	if !ok {
+		return errors.New("params buffer outside memory")
+	}
+	base = append([]byte(nil), base...)
+	resultPtr, err := m.write(ctx, make([]byte, common.TaskResultSize))
+	if err != nil {
+		return err
+	}
+	casePtr, err := m.write(ctx, make([]byte, size))
+	if err != nil {
+		return err
+	}
+
+	status, hash, err := m.runTaskV2(ctx, ptr, resultPtr)
+	if err != nil {
+		return err
+	}
+	if status != common.StatusOK {
+		return fmt.Errorf("run_task_v2 failed (status %d): %s", status, m.lastError(ctx))
+	}
+	r.Hash = hash
+
+	rng := rand.New(rand.NewPCG(opts.fuzzSeed, 0))
+	r.progress.setPhase(r.Task, "fuzz", opts.fuzz)
+	for i := range opts.fuzz {
+		// The first case is the null params pointer
+		params, description := []byte(nil), "null params pointer"
+		if i > 0 {
+			params, description = fuzzParams(spec, base, rng)
+		}
+		start := time.Now()
+		if err := f.check(ctx, m, casePtr, resultPtr, params, size); err != nil {
+			return fmt.Errorf("fuzz case %d of seed %d (%s): %w", i+1, opts.fuzzSeed, description, err)
+		}
+		r.progress.run(float64(time.Since(start)) / float64(time.Millisecond))
+	}
+
+	// State a rejected or cancelled run left behind shows in the next good one
+	status, after, err := m.runTaskV2(ctx, ptr, resultPtr)
+	if err != nil {
+		return fmt.Errorf("after the fuzz cases: %w", err)
+	}
+	if status != common.StatusOK || after != hash {
+		return fmt.Errorf("after the fuzz cases, the benchmark's params ran with status %d and hashed %d, before them %d", status, after, hash)
+	}
+	return nil
+}
+
+// check runs one case: params written at ptr, padded with zeros to size
+// bytes, or ptr 0 when params is nil
+func (f *Fuzz) check(ctx context.Context, m *module, ptr, resultPtr uint32, params []byte, size int) error {
+	f.Cases++
+	if params == nil {
+		ptr = 0
+	} else if !m.writeMemory(ptr, append(params, make([]byte, size-len(params))...)) {
+		return errors.New("params buffer outside memory")
+	}
+
+	valid, err := m.call(ctx, "validate_params", ptr)
+	if err != nil {
+		return err
+	}
+	validCode, err := m.errorCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	caseCtx, cancel := context.WithTimeout(ctx, fuzzCaseTimeout)
+	stop := m.watch(caseCtx)
+	status, _, err := m.runTaskV2(ctx, ptr, resultPtr)
+	stop()
+	timedOut := caseCtx.Err() != nil
+	cancel()
+	if err != nil {
+		return err
+	}
+	code, err := m.errorCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.PeakBytes = max(f.PeakBytes, m.memorySize())
+	if f.BudgetBytes > 0 && m.memorySize() > f.BudgetBytes {
+		return fmt.Errorf("linear memory grew to %d bytes, past the %d-byte budget", m.memorySize(), f.BudgetBytes)
+	}
+
+	switch {
+	case status == common.StatusPanicked:
+		return fmt.Errorf("run_task_v2 panicked: %s", m.lastError(ctx))
+	case valid != common.StatusOK:
+		if status != valid || code != validCode {
+			return fmt.Errorf("validate_params rejected the params with status %d (error code %d), run_task_v2 returned status %d (error code %d)",
+				valid, validCode, status, code)
+		}
+		f.Rejected++
+	case status == common.StatusCancelled && timedOut:
+		f.Cancelled++
+	case status != common.StatusOK:
+		return fmt.Errorf("validate_params accepted the params, run_task_v2 failed with status %d: %s", status, m.lastError(ctx))
+	default:
+		f.Accepted++
+	}
+	return nil
+}
+
+// runTaskV2 runs the task once on the params at ptr, returning the status
+// and hash it writes to the result block at resultPtr
+func (m *module) runTaskV2(ctx context.Context, ptr, resultPtr uint32) (status, hash uint32, err error) {
+	status, err = m.call(ctx, "run_task_v2", ptr, resultPtr)
+	if err != nil {
+		return 0, 0, err
+	}
+	result, ok := m.readMemory(resultPtr, common.TaskResultSize)
+	if !ok {
+		return 0, 0, errors.New("result buffer outside memory")
+	}
+	return status, binary.LittleEndian.Uint32(result[4:]), nil
+}
+
+// fuzzParams returns the params of a case and a description of them for the
+// error that names a failing case. Most cases are the benchmark's params
+// with a few fields at boundary values; the rest set every field so, encode
+// the params with a corrupt header, or are random bytes.
+func fuzzParams(spec taskSpec, base []byte, rng *rand.Rand) ([]byte, string) {
+	params := append([]byte(nil), base...)
+	switch kind := rng.IntN(10); {
+	case kind < 6:
+		for range 1 + rng.IntN(3) {
+			field := spec.fields[rng.IntN(len(spec.fields))]
+			setBoundary(params, field, base, rng)
+		}
+		return params, describeParams(spec, params)
+	case kind < 8:
+		for _, field := range spec.fields {
+			setBoundary(params, field, base, rng)
+		}
+		return params, describeParams(spec, params)
+	case kind < 9:
+		return fuzzEncoded(spec, params, rng)
+	default:
+		for i := range params {
+			params[i] = byte(rng.Uint32())
+		}
+		return params, fmt.Sprintf("random bytes % x", params)
+	}
+}
+
+// fuzzEncoded encodes params in the buffer form, with a header whose version
+// or length is likely wrong
+func fuzzEncoded(spec taskSpec, params []byte, rng *rand.Rand) ([]byte, string) {
+	for range rng.IntN(3) {
+		setBoundary(params, spec.fields[rng.IntN(len(spec.fields))], params, rng)
+	}
+	payload := common.PayloadSize(spec.fields)
+	version := []uint32{0, common.ParamsVersion, common.ParamsVersion, common.ParamsVersion + 1, math.MaxUint32}[rng.IntN(5)]
+	length := []uint32{0, payload - 4, payload, payload, payload + 1, math.MaxUint32, rng.Uint32N(payload + 8)}[rng.IntN(7)]
+
+	buf := make([]byte, common.ParamsHeaderSize, common.ParamsHeaderSize+payload)
+	binary.LittleEndian.PutUint32(buf, common.ParamsMagic)
+	binary.LittleEndian.PutUint32(buf[4:], version)
+	binary.LittleEndian.PutUint32(buf[8:], length)
+	for _, field := range spec.fields {
+		buf = append(buf, params[field.Offset:field.Offset+fieldSize(field)]...)
+	}
+	return buf, fmt.Sprintf("encoded, version %d, length %d of %d: %s", version, length, payload, describeParams(spec, params))
+}
+
+// setBoundary sets field of params to a value at an edge of its type, near
+// its value in base, or random
+func setBoundary(params []byte, field common.ParamField, base []byte, rng *rand.Rand) {
+	b := params[field.Offset:]
+	switch field.Type {
+	case common.FieldF64:
+		values := []float64{0, math.Copysign(0, -1), 1, -1, 0.5, -0.5, 2, 1e-10, 1e-300, math.SmallestNonzeroFloat64,
+			1e10, 1e300, math.MaxFloat64, -math.MaxFloat64, math.Inf(1), math.Inf(-1), math.NaN(), rng.Float64()*8 - 4}
+		binary.LittleEndian.PutUint64(b, math.Float64bits(values[rng.IntN(len(values))]))
+	case common.FieldU64:
+		current := binary.LittleEndian.Uint64(base[field.Offset:])
+		values := []uint64{0, 1, 2, current - 1, current + 1, current * 2, 1<<32 - 1, 1 << 32, 1<<53 + 1,
+			math.MaxInt64, math.MaxUint64, rng.Uint64(), rng.Uint64N(1 << 16)}
+		binary.LittleEndian.PutUint64(b, values[rng.IntN(len(values))])
+	default:
+		current := binary.LittleEndian.Uint32(base[field.Offset:])
+		values := []uint32{0, 1, 2, 3, 7, 255, 256, 1023, 1024, 4096, 65535, 65536, 1 << 24, current - 1, current + 1,
+			current * 2, math.MaxInt32, 1 << 31, math.MaxUint32 - 1, math.MaxUint32, rng.Uint32(), rng.Uint32N(1024)}
+		binary.LittleEndian.PutUint32(b, values[rng.IntN(len(values))])
+	}
+}
+
+// fieldSize returns the bytes of a params field
+func fieldSize(field common.ParamField) uintptr {
+	if field.Type == common.FieldU32 {
+		return 4
+	}
+	return 8
+}
+
+// describeParams lists the fields of a raw params struct as name=value, in
+// the struct's order; unlike paramValues, it keeps NaN and the infinities
+func describeParams(spec taskSpec, params []byte) string {
+	parts := make([]string, len(spec.fields))
+	for i, field := range spec.fields {
+		b := params[field.Offset:]
+		var value string
+		switch field.Type {
+		case common.FieldF64:
+			value = strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 'g', -1, 64)
+		case common.FieldU64:
+			value = strconv.FormatUint(binary.LittleEndian.Uint64(b), 10)
+		default:
+			value = strconv.FormatUint(uint64(binary.LittleEndian.Uint32(b)), 10)
+		}
+		parts[i] = field.Name + "=" + value
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fuzzTask is matrix_mul in miniature with the params checks -fuzz compares:
+// a bump allocator, and a validate_params and run_task_v2 that both reject a
+// dimension of 0 with status 1 and one over 100 with status 2, and otherwise
+// hash the dimension times 3
+var fuzzTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> (), (i32) -> i32, (i32, i32) -> i32
+	0x01, 0x10, 0x03, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x02, 0x7f, 0x7f, 0x01, 0x7f,
+	// Functions: init, alloc, run_task, validate_params, run_task_v2
+	0x03, 0x06, 0x05, 0x00, 0x01, 0x01, 0x01, 0x02,
+	// Memory: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Globals: mutable i32 2048, the next free byte
+	0x06, 0x07, 0x01, 0x7f, 0x01, 0x41, 0x80, 0x10, 0x0b,
+	// Exports: memory, init, alloc, run_task, validate_params, run_task_v2
+	0x07, 0x44, 0x06,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0f, 'v', 'a', 'l', 'i', 'd', 'a', 't', 'e', '_', 'p', 'a', 'r', 'a', 'm', 's', 0x00, 0x03,
+	0x0b, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', '_', 'v', '2', 0x00, 0x04,
+	// Code
+	0x0a, 0x6e, 0x05,
+	0x02, 0x00, 0x0b, // init: nop
+	// alloc: 256 bytes from the global
+	0x0c, 0x00, 0x23, 0x00, 0x23, 0x00, 0x41, 0x80, 0x02, 0x6a, 0x24, 0x00, 0x0b,
+	// run_task: dimension * 3
+	0x0a, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0x03, 0x6c, 0x0b,
+	// validate_params: if dimension == 0 { 1 } else { dimension > 100 ? 2 : 0 }
+	0x1c, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x45, 0x04, 0x7f, 0x41, 0x01, 0x05,
+	0x41, 0x02, 0x41, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0xe4, 0x00, 0x4b, 0x1b, 0x0b, 0x0b,
+	// run_task_v2: the same status and dimension * 3 to the result block
+	0x34, 0x01, 0x01, 0x7f, 0x20, 0x01, 0x20, 0x00, 0x28, 0x02, 0x00, 0x45, 0x04, 0x7f, 0x41, 0x01, 0x05,
+	0x41, 0x02, 0x41, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0xe4, 0x00, 0x4b, 0x1b, 0x0b,
+	0x22, 0x02, 0x36, 0x02, 0x00, 0x20, 0x01, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0x03, 0x6c, 0x36, 0x02, 0x04,
+	0x20, 0x02, 0x0b,
+}
+
+func TestRunFuzz(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-fuzz", "5", path}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "-fuzz needs the validate_params export") {
+		t.Errorf("exit status %d for a module without validate_params: %s", code, stderr.String())
+	}
+
+	path = writeModule(t, "matrix_mul-o2.wasm", fuzzTask)
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-fuzz", "200", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	f := result.Fuzz
+	if f == nil || f.Seed != 1 || f.Cases != 200 || f.Accepted+f.Rejected != 200 || f.Accepted == 0 || f.Rejected == 0 {
+		t.Fatalf("fuzz %+v, expected 200 cases of seed 1, accepted and rejected", f)
+	}
+	if result.Hash != 15 || f.PeakBytes != 65536 || len(result.SamplesMs) != 0 {
+		t.Errorf("hash %d, peak %d bytes and %d samples, expected the params' hash, one page and no timing", result.Hash, f.PeakBytes, len(result.SamplesMs))
+	}
+
+	// A run_task_v2 that accepts dimensions up to 1000, which validate_params rejects
+	buggy := slices.Clone(fuzzTask)
+	i := bytes.LastIndex(buggy, []byte{0x41, 0xe4, 0x00})
+	copy(buggy[i:], []byte{0x41, 0xe8, 0x07})
+	path = writeModule(t, "matrix_mul-o2.wasm", buggy)
+	stderr.Reset()
+	if code := run([]string{"-fuzz", "200", "-fuzz-seed", "7", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d for a run_task_v2 validate_params does not predict: %s", code, stderr.String())
+	}
+	for _, want := range []string{"of seed 7 (", "dimension=", "validate_params rejected the params with status 2 (error code 0), run_task_v2 returned status 0"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("error should say %q: %s", want, stderr.String())
+		}
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-fuzz", "5", "-determinism", "2", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("-fuzz with -determinism = %d, expected a usage error", code)
+	}
+}
+
+func TestFuzzParams(t *testing.T) {
+	spec := tasks["mandelbrot"]
+	rng := rand.New(rand.NewPCG(1, 0))
+	kinds := map[string]int{}
+	for range 500 {
+		params, description := fuzzParams(spec, spec.defaults, rng)
+		switch {
+		case strings.HasPrefix(description, "random bytes"):
+			kinds["random"]++
+		case strings.HasPrefix(description, "encoded"):
+			kinds["encoded"]++
+			if !strings.Contains(description, "max_iter=") {
+				t.Fatalf("description %q should list the fields", description)
+			}
+		default:
+			kinds["raw"]++
+			if len(params) != int(spec.size) || description != describeParams(spec, params) {
+				t.Fatalf("raw case of %d bytes described as %q", len(params), description)
+			}
+		}
+	}
+	if kinds["raw"] < 300 || kinds["encoded"] == 0 || kinds["random"] == 0 {
+		t.Errorf("case kinds %v, expected mostly raw params with some encoded and random", kinds)
+	}
+}
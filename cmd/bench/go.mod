@@ -0,0 +1,42 @@
+module wasmbench/bench
+
+go 1.25.0
+
+// Pure-Go benchmark runner: runs the task modules under wazero, or under
+// wasmtime-go (cgo) when built with -tags wasmtime, or in headless Chrome
+// through chromedp with -tags chromedp; -tags sqlite adds the go-sqlite3 (cgo)
+// history store
+// Params layouts come from the TinyGo task packages, cmd/abilint checks each
+// module against the ABI before it loads, and yaml.v3 reads -plan
+require (
+	github.com/bytecodealliance/wasmtime-go/v48 v48.0.0
+	github.com/chromedp/cdproto v0.0.0-20250724212937-08a3db8b4327
+	github.com/chromedp/chromedp v0.14.2
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/tetratelabs/wazero v1.12.0
+	gopkg.in/yaml.v3 v3.0.1
+	wasmbench/abilint v0.0.0
+	json_parse_wasm v0.0.0
+	mandelbrot_wasm v0.0.0
+	matrix_mul_wasm v0.0.0
+	wasmbench/common v0.0.0
+	wasmbench/stats v0.0.0
+)
+
+require (
+	github.com/chromedp/sysutil v1.1.0 // indirect
+	github.com/go-json-experiment/json v0.0.0-20250725192818-e39067aee2d2 // indirect
+	github.com/gobwas/httphead v0.1.0 // indirect
+	github.com/gobwas/pool v0.2.1 // indirect
+	github.com/gobwas/ws v1.4.0 // indirect
+	golang.org/x/sys v0.44.0 // indirect
+)
+
+replace (
+	json_parse_wasm => ../../tasks/json_parse/tinygo
+	mandelbrot_wasm => ../../tasks/mandelbrot/tinygo
+	matrix_mul_wasm => ../../tasks/matrix_mul/tinygo
+	wasmbench/abilint => ../abilint
+	wasmbench/common => ../../tasks/common
+	wasmbench/stats => ../stats
+)
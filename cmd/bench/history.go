@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// history is a store that keeps every session's results, keyed by task,
+// params, toolchain and commit, for queries across sessions
+type history interface {
+	record(s *Session) error
+	Close() error
+}
+
+// openHistory opens the history store at path, creating it when missing. It
+// is nil unless built with -tags sqlite.
+var openHistory func(path string) (history, error)
+
+// gitCommit returns the commit checked out in the working directory, "" when
+// it is not in a git work tree
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// toolchains reads the toolchain versions the build scripts record in
+// <builds>/metrics.json, keyed by language
+type toolchains map[string]map[string]string
+
+// toolchain returns the version of the toolchain that built the result's
+// module: the runner's Go for the native baseline, otherwise the one recorded
+// in the metrics.json of the builds directory holding builds/<language>/
+func (t toolchains) toolchain(r *Result) string {
+	if r.Runtime == "native" {
+		return runtime.Version()
+	}
+	// A JavaScript baseline has its engine's version
+	if r.Language == "js" {
+		return r.Toolchain
+	}
+	language := r.Language
+	if language == "" {
+		language = filepath.Base(filepath.Dir(r.Module))
+	}
+	builds := filepath.Dir(filepath.Dir(r.Module))
+	versions, ok := t[builds]
+	if !ok {
+		versions = map[string]string{}
+		var metrics map[string]json.RawMessage
+		if data, err := os.ReadFile(filepath.Join(builds, "metrics.json")); err == nil && json.Unmarshal(data, &metrics) == nil {
+			for name, raw := range metrics {
+				var entry struct {
+					Toolchain string `json:"toolchain"`
+				}
+				if json.Unmarshal(raw, &entry) == nil {
+					versions[name] = entry.Toolchain
+				}
+			}
+		}
+		t[builds] = versions
+	}
+	return versions[language]
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestToolchains(t *testing.T) {
+	builds := t.TempDir()
+	metrics := `{"timestamp": "2026-01-02T03:04:05Z", "tinygo": {"language": "tinygo", "toolchain": "tinygo version 0.39.0 linux/amd64"}, "rust": {"toolchain": "rustc 1.90.0"}}`
+	if err := os.WriteFile(filepath.Join(builds, "metrics.json"), []byte(metrics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := toolchains{}
+	for _, c := range []struct {
+		result   Result
+		expected string
+	}{
+		{Result{Module: filepath.Join(builds, "tinygo", "matrix_mul-o2.wasm"), Runtime: "wazero", Language: "tinygo"}, "tinygo version 0.39.0 linux/amd64"},
+		{Result{Module: filepath.Join(builds, "rust", "matrix_mul-o3.wasm"), Runtime: "wazero"}, "rustc 1.90.0"},
+		{Result{Module: filepath.Join(t.TempDir(), "tinygo", "a.wasm"), Runtime: "wazero"}, ""},
+		{Result{Module: "native", Runtime: "native", Language: "go"}, runtime.Version()},
+	} {
+		if toolchain := versions.toolchain(&c.result); toolchain != c.expected {
+			t.Errorf("toolchain of %s = %q, expected %q", c.result.Module, toolchain, c.expected)
+		}
+	}
+}
+
+func TestRunHistoryNeedsSQLite(t *testing.T) {
+	if openHistory != nil {
+		t.Skip("built with -tags sqlite")
+	}
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-history", filepath.Join(t.TempDir(), "history.db"), path}, &stdout, &stderr); code != 2 {
+		t.Fatalf("exit status %d, expected 2", code)
+	}
+	if !strings.Contains(stderr.String(), "-tags sqlite") {
+		t.Errorf("stderr %q does not say how to enable -history", stderr.String())
+	}
+}
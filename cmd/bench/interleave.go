@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"runtime"
+)
+
+// benchInterleaved benchmarks the modules at paths, of one pass, with their
+// measured runs interleaved, A, B, A, B, rather than each module's runs in a
+// block, so a drift in the host's speed (heat, a background job) falls on
+// every module alike instead of on whichever ran last. Each module is loaded
+// and warmed up in turn, and then every round runs each module still in the
+// rotation once, in path order; one that fails drops out. RunOrder records
+// where each measured run fell among the pass's. A module's -timeout counts
+// from its load, so it covers the other modules' runs in between too.
+func benchInterleaved(ctx context.Context, paths []string, opts options) []Result {
+	results := make([]Result, len(paths))
+	contexts := make([]context.Context, len(paths))
+	rotation := make([]*measurement, len(paths))
+	end := func(i int, err error) {
+		results[i].setError(contexts[i], opts, err)
+		results[i].progress.end()
+	}
+	for i, path := range paths {
+		results[i] = newResult(path, opts)
+		var cancel context.CancelFunc
+		contexts[i], cancel = withTimeout(ctx, opts.timeout)
+		defer cancel()
+		m, err := results[i].prepare(contexts[i], opts)
+		if err != nil || m == nil {
+			end(i, err)
+			continue
+		}
+		defer m.close()
+		rotation[i] = m
+	}
+
+	if opts.strict {
+		runtime.GC()
+	}
+	order := 0
+	for range opts.runs {
+		for i, m := range rotation {
+			if m == nil {
+				continue
+			}
+			position := order
+			order++
+			if err := m.run(); err != nil {
+				m.close()
+				rotation[i] = nil
+				end(i, err)
+				continue
+			}
+			results[i].RunOrder = append(results[i].RunOrder, position)
+		}
+	}
+	for i, m := range rotation {
+		if m == nil {
+			continue
+		}
+		err := m.finish()
+		if err == nil {
+			err = results[i].ColdStart.compare(&results[i])
+		}
+		m.close()
+		end(i, err)
+	}
+	return results
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRunInterleave(t *testing.T) {
+	a := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	// Its second run hashes differently, so it drops out of the rotation
+	b := writeModule(t, "matrix_mul-oz.wasm", counterTask)
+	c := writeModule(t, "matrix_mul-o3.wasm", fakeTask)
+	session := filepath.Join(t.TempDir(), "session.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-interleave", "-warmup", "1", "-runs", "3", "-json", session, a, b, c}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1 for the failing module: %s", code, stderr.String())
+	}
+	var results []Result
+	for line := range strings.Lines(stdout.String()) {
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	if len(results) != 3 || results[0].Module != a || results[1].Module != b || results[2].Module != c {
+		t.Fatalf("results %+v, expected one per module in order", results)
+	}
+	// Round one runs a, b and c; b fails in round two, after which a and c alternate
+	for i, expected := range [][]int{{0, 3, 6}, {1}, {2, 5, 7}} {
+		if !slices.Equal(results[i].RunOrder, expected) {
+			t.Errorf("%s: run order %v, expected %v", filepath.Base(results[i].Module), results[i].RunOrder, expected)
+		}
+	}
+	if results[1].Error == "" || results[0].Error != "" || len(results[2].SamplesMs) != 3 || results[2].Stats.N == 0 {
+		t.Errorf("errors %q, %q, with %d runs of c, expected only b to fail", results[0].Error, results[1].Error, len(results[2].SamplesMs))
+	}
+
+	data, err := os.ReadFile(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Environment.Interleaved {
+		t.Error("session environment does not record -interleave")
+	}
+
+	for _, args := range [][]string{{"-parallel", "2"}, {"-determinism", "2"}, {"-fuzz", "3"}} {
+		if code := run(append([]string{"-interleave"}, append(args, a)...), &stdout, &stderr); code != 2 {
+			t.Errorf("-interleave with %v: exit status %d, expected 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package config loads a benchmark plan: which tasks to run at which param
+// scales, how many warm-up and measured runs and repetitions, and under which
+// runtimes, so an experiment is versioned data rather than shell flags.
+//
+// A plan is YAML, or JSON, which YAML reads as well. configs/bench.yaml and
+// configs/bench-quick.yaml are plans: their experiment, environment and
+// tasks sections are read, and the sections for the other tools are ignored.
+// An optional runner section narrows and extends them:
+//
+//	runner:
+//	  tasks: [matrix_mul]      # Default: every task with scales
+//	  scales: [small, medium]  # Default: every scale of each task
+//	  runtimes: [wazero, wasmtime]
+//	  native: true             # Also run each step natively
+//
+// An agents section lists the hosts bench -agents runs the plan on, each a
+// bench -agent, with the tasks and runtimes assigned to it:
+//
+//	agents:
+//	  - name: graviton
+//	    url: http://10.0.0.5:9470
+//	    tasks: [matrix_mul, json_*]  # Globs, default: every task
+//	    runtimes: [wazero]           # Globs, default: every runtime
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plan is a benchmark plan
+type Plan struct {
+	Experiment struct {
+		Name        string `yaml:"name"`
+		Version     string `yaml:"version"`
+		Description string `yaml:"description"`
+	} `yaml:"experiment"`
+	Environment struct {
+		WarmupRuns    int     `yaml:"warmup_runs"`
+		WarmupCV      float64 `yaml:"warmup_cv"`       // Warm up past warmup_runs until the runs are this steady
+		MaxWarmupRuns int     `yaml:"max_warmup_runs"` // Cap on the warm-up with warmup_cv
+		MeasureRuns   int     `yaml:"measure_runs"`
+		Repetitions   int     `yaml:"repetitions"` // Times the whole plan runs, at least 1
+		Timeout       int     `yaml:"timeout"`     // Seconds each module's benchmark may take, 0 for no limit
+	} `yaml:"environment"`
+	Tasks  []Task `yaml:"-"` // In the plan's order, read by UnmarshalYAML
+	Runner struct {
+		Tasks    []string `yaml:"tasks"`
+		Scales   []string `yaml:"scales"`
+		Runtimes []string `yaml:"runtimes"`
+		Native   bool     `yaml:"native"`
+	} `yaml:"runner"`
+	Agents []Agent `yaml:"agents"`
+}
+
+// Agent is a host of the agents section, the steps of the plan it runs
+// picked by its globs
+type Agent struct {
+	Name     string   `yaml:"name"`
+	URL      string   `yaml:"url"` // Of its bench -agent, e.g. http://10.0.0.5:9470
+	Tasks    []string `yaml:"tasks"`
+	Runtimes []string `yaml:"runtimes"`
+}
+
+// Task is a task's entry in the plan
+type Task struct {
+	Name   string
+	Scales []Scale // In the plan's order
+}
+
+// Scale is a named set of params of a task, written over its defaults
+type Scale struct {
+	Name   string
+	Params map[string]any
+}
+
+// Step is one pass of the runner: every module of a task at one scale under
+// one runtime
+type Step struct {
+	Task       string
+	Scale      string
+	Params     string // JSON object
+	Runtime    string
+	Repetition int // From 1
+}
+
+// Load reads and checks the plan at path
+func Load(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return plan, nil
+}
+
+// Parse reads and checks a plan
+func Parse(data []byte) (*Plan, error) {
+	var plan Plan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, err
+	}
+	if err := plan.check(); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (p *Plan) check() error {
+	env := p.Environment
+	if env.WarmupRuns < 0 || env.WarmupCV < 0 || env.MaxWarmupRuns < 0 || env.MeasureRuns < 0 || env.Repetitions < 0 || env.Timeout < 0 {
+		return errors.New("environment: run counts and the timeout must not be negative")
+	}
+	for _, name := range p.Runner.Tasks {
+		if !slices.ContainsFunc(p.Tasks, func(t Task) bool { return t.Name == name }) {
+			return fmt.Errorf("runner: task %q has no scales in tasks", name)
+		}
+	}
+	for _, name := range p.Runner.Scales {
+		if !slices.ContainsFunc(p.Tasks, func(t Task) bool { return t.scale(name) != nil }) {
+			return fmt.Errorf("runner: no task has scale %q", name)
+		}
+	}
+	for i, agent := range p.Agents {
+		if agent.Name == "" || strings.ContainsAny(agent.Name, "/, ") {
+			return fmt.Errorf("agents: agent %d needs a name without slashes, commas or spaces", i+1)
+		}
+		if slices.ContainsFunc(p.Agents[:i], func(a Agent) bool { return a.Name == agent.Name }) {
+			return fmt.Errorf("agents: %s is listed twice", agent.Name)
+		}
+		if u, err := url.Parse(agent.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("agents: %s: url %q is not an http or https URL", agent.Name, agent.URL)
+		}
+		for _, glob := range slices.Concat(agent.Tasks, agent.Runtimes) {
+			if _, err := path.Match(glob, ""); err != nil || strings.Contains(glob, ",") {
+				return fmt.Errorf("agents: %s: bad glob %q", agent.Name, glob)
+			}
+		}
+	}
+	return nil
+}
+
+func (t Task) scale(name string) *Scale {
+	for i := range t.Scales {
+		if t.Scales[i].Name == name {
+			return &t.Scales[i]
+		}
+	}
+	return nil
+}
+
+// Steps expands the plan into the runner's passes: for each repetition, each
+// selected task in plan order, each of its selected scales, each runtime.
+// Runtimes default to wazero.
+func (p *Plan) Steps() ([]Step, error) {
+	runtimes := p.Runner.Runtimes
+	if len(runtimes) == 0 {
+		runtimes = []string{"wazero"}
+	}
+	var steps []Step
+	for repetition := 1; repetition <= max(p.Environment.Repetitions, 1); repetition++ {
+		for _, task := range p.Tasks {
+			if len(p.Runner.Tasks) > 0 && !slices.Contains(p.Runner.Tasks, task.Name) {
+				continue
+			}
+			for _, scale := range task.Scales {
+				if len(p.Runner.Scales) > 0 && !slices.Contains(p.Runner.Scales, scale.Name) {
+					continue
+				}
+				params, err := json.Marshal(scale.Params)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", task.Name, scale.Name, err)
+				}
+				for _, runtime := range runtimes {
+					steps = append(steps, Step{task.Name, scale.Name, string(params), runtime, repetition})
+				}
+			}
+		}
+	}
+	return steps, nil
+}
+
+// UnmarshalYAML reads the tasks mapping in document order. Entries without
+// scales, such as a task's schema notes, are left out.
+func (p *Plan) UnmarshalYAML(node *yaml.Node) error {
+	type plain Plan // Without this method
+	if err := node.Decode((*plain)(p)); err != nil {
+		return err
+	}
+	var raw struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	p.Tasks = nil
+	return eachEntry(&raw.Tasks, func(name string, value *yaml.Node) error {
+		var entry struct {
+			Scales yaml.Node `yaml:"scales"`
+		}
+		if err := value.Decode(&entry); err != nil {
+			return fmt.Errorf("tasks: %s: %w", name, err)
+		}
+		task := Task{Name: name}
+		err := eachEntry(&entry.Scales, func(scale string, value *yaml.Node) error {
+			params := map[string]any{}
+			if err := value.Decode(&params); err != nil {
+				return fmt.Errorf("tasks: %s: scale %s: %w", name, scale, err)
+			}
+			task.Scales = append(task.Scales, Scale{scale, params})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(task.Scales) > 0 {
+			p.Tasks = append(p.Tasks, task)
+		}
+		return nil
+	})
+}
+
+// eachEntry calls f with each key and value of a mapping node, in order. A
+// missing node has no entries.
+func eachEntry(node *yaml.Node, f func(key string, value *yaml.Node) error) error {
+	if node.Kind == 0 {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := f(node.Content[i].Value, node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const planYAML = `
+experiment:
+  name: "scaling"
+environment:
+  warmup_runs: 2
+  measure_runs: 10
+  repetitions: 2
+tasks:
+  matrix_mul:
+    scales:
+      small:
+        dimension: 64
+      large:
+        dimension: 256
+  json_parse:
+    scales:
+      small:
+        record_count: 500
+    schema:
+      fields: ["id"]
+runner:
+  scales: [small]
+  runtimes: [wazero, wasmtime]
+agents:
+  - name: graviton
+    url: http://10.0.0.5:9470
+    tasks: [matrix_mul]
+languages:
+  rust:
+    enabled: true
+`
+
+func TestSteps(t *testing.T) {
+	plan, err := Parse([]byte(planYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Experiment.Name != "scaling" || plan.Environment.WarmupRuns != 2 || plan.Environment.MeasureRuns != 10 {
+		t.Errorf("plan %+v, expected the experiment and environment sections", plan)
+	}
+	if len(plan.Tasks) != 2 || plan.Tasks[0].Name != "matrix_mul" || plan.Tasks[0].Scales[1].Name != "large" {
+		t.Errorf("tasks %+v, expected matrix_mul then json_parse in plan order", plan.Tasks)
+	}
+	if len(plan.Agents) != 1 || plan.Agents[0].Name != "graviton" || plan.Agents[0].Tasks[0] != "matrix_mul" || plan.Agents[0].Runtimes != nil {
+		t.Errorf("agents %+v, expected graviton assigned matrix_mul", plan.Agents)
+	}
+
+	steps, err := plan.Steps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range steps {
+		got = append(got, strings.Join([]string{s.Task, s.Scale, s.Params, s.Runtime, strconv.Itoa(s.Repetition)}, " "))
+	}
+	expected := []string{
+		`matrix_mul small {"dimension":64} wazero 1`,
+		`matrix_mul small {"dimension":64} wasmtime 1`,
+		`json_parse small {"record_count":500} wazero 1`,
+		`json_parse small {"record_count":500} wasmtime 1`,
+		`matrix_mul small {"dimension":64} wazero 2`,
+		`matrix_mul small {"dimension":64} wasmtime 2`,
+		`json_parse small {"record_count":500} wazero 2`,
+		`json_parse small {"record_count":500} wasmtime 2`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("steps\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	plan, err := Parse([]byte(`{"tasks": {"mandelbrot": {"scales": {"tiny": {"width": 8, "height": 8, "scale_factor": 2.5}}}},
+		"runner": {"native": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := plan.Steps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 1 || steps[0].Params != `{"height":8,"scale_factor":2.5,"width":8}` || steps[0].Runtime != "wazero" || steps[0].Repetition != 1 {
+		t.Errorf("steps %+v, expected one wazero step of the tiny scale", steps)
+	}
+	if !plan.Runner.Native {
+		t.Error("runner.native not read")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, c := range []struct{ plan, error string }{
+		{"environment: {measure_runs: -1}", "must not be negative"},
+		{"environment: {timeout: -5}", "must not be negative"},
+		{"tasks: {matrix_mul: {scales: {small: {dimension: 8}}}}\nrunner: {tasks: [mandelbrot]}", `task "mandelbrot"`},
+		{"tasks: {matrix_mul: {scales: {small: {dimension: 8}}}}\nrunner: {scales: [huge]}", `scale "huge"`},
+		{"tasks: [matrix_mul]", "expected a mapping"},
+		{"agents: [{url: 'http://arm:9470'}]", "needs a name"},
+		{"agents: [{name: arm, url: 'http://a:9470'}, {name: arm, url: 'http://b:9470'}]", "arm is listed twice"},
+		{"agents: [{name: arm, url: 'arm:9470'}]", "not an http or https URL"},
+		{"agents: [{name: arm, url: 'http://arm:9470', tasks: ['[']}]", `bad glob "["`},
+	} {
+		if _, err := Parse([]byte(c.plan)); err == nil || !strings.Contains(err.Error(), c.error) {
+			t.Errorf("%q: error %v, expected one containing %q", c.plan, err, c.error)
+		}
+	}
+}
+
+func TestRepoConfigsArePlans(t *testing.T) {
+	for _, name := range []string{"bench.yaml", "bench-quick.yaml"} {
+		plan, err := Load(filepath.Join("..", "..", "..", "..", "configs", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Tasks) != 3 || plan.Environment.MeasureRuns == 0 {
+			t.Errorf("%s: %d tasks and %d measured runs, expected the three tasks and a run count", name, len(plan.Tasks), plan.Environment.MeasureRuns)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"wasmbench/common"
+)
+
+// jsEngine runs a task's JavaScript implementation, tasks/<task>/js/<task>.js,
+// through the bench object of jsDriver
+type jsEngine interface {
+	// call calls the driver's method with args, which are marshalled to
+	// JSON, and decodes its result into out
+	call(ctx context.Context, out any, method string, args ...any) error
+	close() error
+}
+
+// jsEngines start an engine with the driver and load task into it from the
+// tasks directory dir. Keyed by -js; chrome registers itself behind its
+// build tag.
+var jsEngines = map[string]func(ctx context.Context, dir, task string, log io.Writer) (jsEngine, error){
+	"node": startNode,
+}
+
+// jsDriver is the runner's side of an engine, a plain script for Node and the
+// browser alike. bench.load imports a task module, which defines its task
+// through tasks/common/js/common.js; each run is timed with
+// performance.now() in the engine, so the round trip to the runner stays out
+// of the samples. The outcomes are {status, code, hash, message} objects.
+const jsDriver = `
+const bench = {
+    task: null,
+    params: null,
+
+    async load(url) {
+        try {
+            this.task = (await import(url)).default;
+            return '';
+        } catch (e) {
+            return String(e);
+        }
+    },
+
+    version() {
+        return typeof process === 'undefined' ? navigator.userAgent : 'node ' + process.version;
+    },
+
+    selfTest() {
+        return this.task.selfTest();
+    },
+
+    setParams(params) {
+        this.params = params;
+        return this.task.validate(params);
+    },
+
+    run() {
+        const start = performance.now();
+        const outcome = this.task.run(this.params);
+        outcome.ms = performance.now() - start;
+        return outcome;
+    }
+};
+`
+
+// nodeMain serves the driver over stdin and stdout in JSON lines, a
+// {method, args} call in and a {result} or {error} out. The task's console
+// output goes to stderr, out of the way of the replies.
+const nodeMain = jsDriver + `
+console.log = console.info = console.warn = console.debug = console.error;
+const { createInterface } = await import('node:readline');
+for await (const line of createInterface({ input: process.stdin })) {
+    const { method, args } = JSON.parse(line);
+    let reply;
+    try {
+        reply = { result: (await bench[method](...args)) ?? null };
+    } catch (e) {
+        reply = { error: String(e) };
+    }
+    process.stdout.write(JSON.stringify(reply) + '\n');
+}
+`
+
+// jsOutcome is a task's answer to selfTest, setParams and run
+type jsOutcome struct {
+	Status  uint32  `json:"status"`
+	Code    uint32  `json:"code"`
+	Hash    uint32  `json:"hash"`
+	Message string  `json:"message"`
+	Ms      float64 `json:"ms"` // Of a run, in the engine
+}
+
+// jsTimer gives measure the engine's time of the last run
+type jsTimer struct {
+	ms float64
+}
+
+func (t *jsTimer) lastRunMs() float64 {
+	return t.ms
+}
+
+// jsTaskFile returns where the JavaScript implementation of task is under dir
+func jsTaskFile(dir, task string) string {
+	return filepath.Join(dir, task, "js", task+".js")
+}
+
+// benchJS runs task's JavaScript implementation under the engine of opts.js
+// with the params and run counts of the wasm modules, the baseline -js
+// reports each module against. The engine runs in a process of its own, so
+// -perf has nothing to count; past opts.timeout it is stopped.
+func benchJS(ctx context.Context, task string, opts options) Result {
+	result := Result{Module: "js", Runtime: opts.js, Task: task, Language: "js", Scale: opts.scale, Repetition: opts.repetition,
+		WarmupRuns: opts.warmupRuns, SamplesMs: []float64{}, progress: opts.progress.begin("js", opts.js, opts.scale),
+		stream: opts.stream}
+	ctx, cancel := withTimeout(ctx, opts.timeout)
+	defer cancel()
+	result.setError(ctx, opts, result.benchJS(ctx, opts))
+	result.progress.end()
+	return result
+}
+
+func (r *Result) benchJS(ctx context.Context, opts options) error {
+	spec, ok := tasks[r.Task]
+	if !ok {
+		return fmt.Errorf("unknown task %q", r.Task)
+	}
+	if _, err := os.Stat(jsTaskFile(opts.jsDir, r.Task)); err != nil {
+		return fmt.Errorf("task %s has no JavaScript implementation: %w", r.Task, err)
+	}
+	start, ok := jsEngines[opts.js]
+	if !ok {
+		return fmt.Errorf("unknown JavaScript engine %q", opts.js)
+	}
+	params, err := buildParams(spec, opts.params)
+	if err != nil {
+		return err
+	}
+	r.Params = paramValues(spec, params)
+	r.Verification = opts.references.match(r.Task, params)
+
+	engine, err := start(ctx, opts.jsDir, r.Task, opts.log)
+	if err != nil {
+		return err
+	}
+	defer engine.close()
+	// The engine's version stands in for the toolchain of a module
+	if err := engine.call(ctx, &r.Toolchain, "version"); err != nil {
+		return err
+	}
+	var outcome jsOutcome
+	if err := engine.call(ctx, &outcome, "selfTest"); err != nil {
+		return err
+	} else if outcome.Status != common.StatusOK {
+		return fmt.Errorf("self test failed (status %d): %s", outcome.Status, outcome.Message)
+	}
+	if err := engine.call(ctx, &outcome, "setParams", r.Params); err != nil {
+		return err
+	} else if outcome.Status != common.StatusOK {
+		return diagnose("invalid parameters", outcome.Status, outcome.Code, outcome.Message, r.Params)
+	}
+
+	timer := &jsTimer{}
+	runTask := func() (uint32, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		var outcome jsOutcome
+		if err := engine.call(ctx, &outcome, "run"); err != nil {
+			return 0, err
+		}
+		if outcome.Status != common.StatusOK {
+			return 0, diagnose("run_task failed", outcome.Status, outcome.Code, outcome.Message, r.Params)
+		}
+		timer.ms = outcome.Ms
+		return outcome.Hash, nil
+	}
+	if err := r.warmUp(opts, runTask); err != nil {
+		return err
+	}
+	if opts.energy {
+		r.Energy = &EnergyUsage{}
+	}
+	return r.measure(opts.runs, nil, timer, runTask)
+}
+
+// compareJS sets JSRatio from the JavaScript baseline of r's task. The
+// baseline ran the same params, so a different hash means one of the two is
+// wrong.
+func (r *Result) compareJS(js *Result) {
+	if r.Error != "" || js.Error != "" {
+		return
+	}
+	if r.Hash != js.Hash {
+		r.Error = fmt.Sprintf("hash %d differs from JavaScript's %d", r.Hash, js.Hash)
+		return
+	}
+	if js.Stats.Median != 0 {
+		r.JSRatio = r.Stats.Median / js.Stats.Median
+	}
+}
+
+// nodeEngine is a Node process of its own running nodeMain
+type nodeEngine struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	encoder *json.Encoder
+	decoder *json.Decoder
+}
+
+// startNode starts node on nodeMain and loads task into it. The process is
+// killed when ctx is done; its stderr, the task's console output with it,
+// goes to log.
+func startNode(ctx context.Context, dir, task string, log io.Writer) (jsEngine, error) {
+	path, err := filepath.Abs(jsTaskFile(dir, task))
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "node", "--input-type=module", "-e", nodeMain)
+	cmd.Stderr = log
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	n := &nodeEngine{cmd: cmd, stdin: stdin, encoder: json.NewEncoder(stdin), decoder: json.NewDecoder(stdout)}
+
+	var loadErr string
+	if err := n.call(ctx, &loadErr, "load", (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()); err != nil {
+		n.close()
+		return nil, err
+	}
+	if loadErr != "" {
+		n.close()
+		return nil, errors.New(loadErr)
+	}
+	return n, nil
+}
+
+func (n *nodeEngine) call(ctx context.Context, out any, method string, args ...any) error {
+	if args == nil {
+		args = []any{}
+	}
+	if err := n.encoder.Encode(map[string]any{"method": method, "args": args}); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := n.decoder.Decode(&reply); err != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s: node exited: %w", method, err)
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("%s: %s", method, reply.Error)
+	}
+	return json.Unmarshal(reply.Result, out)
+}
+
+// close ends the driver's input, which ends the process
+func (n *nodeEngine) close() error {
+	n.stdin.Close()
+	return n.cmd.Wait()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"wasmbench/stats"
+)
+
+const tasksDir = "../../tasks"
+
+// needNode skips tests of the node engine on hosts without Node
+func needNode(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+}
+
+func TestJSBaselineMatchesNative(t *testing.T) {
+	needNode(t)
+	opts := options{js: "node", jsDir: tasksDir, warmupRuns: 1, runs: 2, log: io.Discard}
+	for _, c := range []struct{ task, params string }{
+		{"matrix_mul", `{"dimension": 16, "seed_high": 7}`},
+		{"mandelbrot", `{"width": 32, "height": 24, "profile": 1}`},
+		{"json_parse", `{"record_count": 200, "verification": 1}`},
+		{"json_parse", `{"scale": 1, "profile": 1, "verification": 2}`},
+	} {
+		opts.params = c.params
+		js := benchJS(context.Background(), c.task, opts)
+		native := benchNative(context.Background(), c.task, opts)
+		if js.Error != "" || native.Error != "" {
+			t.Fatalf("%s %s: JavaScript error %q, native error %q", c.task, c.params, js.Error, native.Error)
+		}
+		if js.Hash != native.Hash || len(js.SamplesMs) != 2 {
+			t.Errorf("%s %s: JavaScript hash %d in %d runs, expected native Go's %d in 2", c.task, c.params, js.Hash, len(js.SamplesMs), native.Hash)
+		}
+		if js.Runtime != "node" || js.Language != "js" || !strings.HasPrefix(js.Toolchain, "node v") {
+			t.Errorf("baseline %s %s under %q, expected js under node", js.Language, js.Toolchain, js.Runtime)
+		}
+	}
+}
+
+func TestJSBaselineRejects(t *testing.T) {
+	needNode(t)
+	opts := options{js: "node", jsDir: tasksDir, runs: 1, log: io.Discard}
+	for _, c := range []struct{ task, params, want string }{
+		{"matrix_mul", `{"dimension": 0}`, "invalid parameters (status 1 invalid_params, error code 3 zero_dimension): dimension must be non-zero; set the field to at least 1"},
+		{"mandelbrot", `{"width": 20000}`, "(status 2 overflow, error code 4 too_large): width or height exceeds the maximum image dimension; lower the field"},
+		{"matrix_mul", `{"profile": 2}`, "the JavaScript baseline does not implement the compute and memory profiles"},
+		{"json_parse", `{"hash_algorithm": 1}`, "does not implement xxHash32"},
+	} {
+		opts.params = c.params
+		if result := benchJS(context.Background(), c.task, opts); !strings.Contains(result.Error, c.want) {
+			t.Errorf("%s %s: error %q, expected %q", c.task, c.params, result.Error, c.want)
+		}
+	}
+
+	opts.jsDir = t.TempDir()
+	if result := benchJS(context.Background(), "matrix_mul", opts); !strings.Contains(result.Error, "no JavaScript implementation") {
+		t.Errorf("error %q, expected the missing implementation", result.Error)
+	}
+}
+
+func TestRunJSBaseline(t *testing.T) {
+	needNode(t)
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	// fakeTask's hash is not a real matrix product, so it cannot match JavaScript's
+	if code := run([]string{"-js", "node", "-js-tasks", tasksDir, "-warmup", "0", "-runs", "2", "-params", `{"dimension": 4}`, path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1: %s", code, stderr.String())
+	}
+	decoder := json.NewDecoder(&stdout)
+	var js, result Result
+	if err := decoder.Decode(&js); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if js.Module != "js" || js.Task != "matrix_mul" || js.Error != "" || len(js.SamplesMs) != 2 {
+		t.Errorf("baseline %+v, expected 2 JavaScript matrix_mul runs", js)
+	}
+	if !strings.Contains(result.Error, "differs from JavaScript") {
+		t.Errorf("error %q, expected a hash mismatch with JavaScript", result.Error)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-js", "spidermonkey", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("unknown -js engine = %d, expected a usage error", code)
+	}
+	if code := run([]string{"-js", "node", "-fuzz", "5", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("-js with -fuzz = %d, expected a usage error", code)
+	}
+}
+
+func TestCompareJS(t *testing.T) {
+	result := Result{Hash: 7, Stats: stats.Summary{Median: 1}}
+	result.compareJS(&Result{Hash: 7, Stats: stats.Summary{Median: 4}})
+	if result.JSRatio != 0.25 || result.Error != "" {
+		t.Errorf("ratio %v, error %q, expected 0.25", result.JSRatio, result.Error)
+	}
+
+	wrong := Result{Hash: 7, Stats: stats.Summary{Median: 1}}
+	wrong.compareJS(&Result{Hash: 8, Stats: stats.Summary{Median: 4}})
+	if wrong.JSRatio != 0 || wrong.Error != "hash 7 differs from JavaScript's 8" {
+		t.Errorf("ratio %v, error %q, expected a hash mismatch", wrong.JSRatio, wrong.Error)
+	}
+}
@@ -0,0 +1,783 @@
+// Command bench runs the benchmark task modules under wazero, a pure-Go
+// WebAssembly runtime, so the suite can be driven from Go without a browser
+// or Node. For each module it writes the task's params into linear memory,
+// calls init and self_test, then times the warm-up and measured run_task
+// repetitions, and prints one JSON result per module to stdout. -json and
+// -csv also export the whole session, with the host it ran on, and -history
+// (built with -tags sqlite) adds it to a SQLite database of every session.
+// With -warmup-cv, the warm-up lasts until the run times are steady rather
+// than for a fixed count. With -timeout, a module still running when its
+// time is up is stopped and its result fails with timed_out set, and the
+// session goes on to the next module.
+//
+// Modules run one at a time by default. -parallel n runs up to n modules, of
+// any -plan steps, at once for a fast exploratory sweep; their times are then
+// inflated by the competition for cores and only compare within the session.
+// -strict is the measurement mode for numbers to publish: one module at a
+// time on a single thread, pinned to one CPU on Linux, with a collection
+// before each module. The CPU is -cpu, or else the highest CPU the kernel
+// isolates from the scheduler (isolcpus), or else the highest the process may
+// use; -nice -20 raises the thread to the highest priority. The session's
+// environment records the mode, the CPU and the niceness.
+//
+// -interleave alternates the measured runs of the modules of each params
+// point and runtime, A, B, A, B, instead of running all of A's and then all
+// of B's, so thermal drift and background load bias no module in particular.
+// The modules are loaded and warmed up first, and each result's run_order
+// records where its runs fell.
+//
+// -plan runs a benchmark plan instead of a single set of params: each task
+// at each of its scales, under each runtime, the plan's repetitions times,
+// with its warm-up and measured run counts and its timeout unless -warmup,
+// -runs or -timeout is given.
+// configs/bench.yaml and configs/bench-quick.yaml are plans. -task, -size
+// and -runtime then take globs that pick the plan's tasks, scales and
+// runtimes to run, so one combination re-runs without editing the plan, and
+// -category and -language, with or without a plan, pick the tasks of a
+// category (compute, memory or allocation) and the modules of a language.
+//
+// -sweep runs the modules whose task has the swept params at each of a range
+// of sizes instead, then fits each module's median time against the size,
+// the product of the swept fields: a power law for its exponent, and the best
+// of n, n log n, n² and n³. It prints the fits and the sizes where two
+// modules of a task trade places to stderr, and -json records them.
+//
+// -verify checks the hash of every measured run against the reference vector
+// with the same params, and fails a module whose runs miss it; its times stay
+// in the result, marked by the error. Runs of params no vector has are
+// reported unverified.
+//
+// -tolerance bounds, such as ulps=64,abs=1e-4, let a float task's module
+// pass -verify with the wrong hash: once its runs are timed, one more run
+// keeps its output, and if every element is within the bounds of the Go
+// implementation's output for the same params, the module passes with a note
+// on stderr. Float rounding that differs between compilers and SIMD paths
+// changes the hash of a correct matrix product; the comparison tells such a
+// result from a wrong one, and -json records it with the verification.
+//
+// -profile dir runs the task of -task natively, or each task and scale of
+// -plan once, as the -native baseline does but under the CPU profiler, and
+// writes a CPU and a heap profile per task and scale to dir for go tool pprof.
+// It runs no modules.
+//
+// -perf also counts the instructions, cycles, branch misses and cache misses
+// of each measured run through Linux's perf_event_open, user space only, and
+// reports them beside its wall time with the instructions per cycle, for
+// comparing runtimes by the code they run rather than by its time alone.
+// Under chrome, whose modules run in the browser's processes, there is
+// nothing to count.
+//
+// -energy also reads the RAPL energy counters of the processor packages,
+// through Linux's powercap sysfs, around each measured run, and reports its
+// joules and the mean power, to compare the energy a task takes across
+// languages and runtimes besides its time. The counters cover the whole
+// processor, so -energy runs serially and wants an otherwise idle host.
+//
+// A module built with cmd/build -tags allocstats, which says so in its
+// get_task_info, counts the allocations, bytes and GC cycles of the timed
+// section of each run itself. Its result gets them per measured run from
+// get_memory_stats, with the runs that collected and the median time they
+// took over the runs that did not, the cost of the GC to the task.
+//
+// -stream writes a JSON line per measured run to stdout as soon as it
+// completes, and each result line after its runs, each marked by its record
+// field, so tools consume a session as it goes and a crash loses no finished
+// run.
+//
+// -checkpoint file appends every result to file as it is reported, synced to
+// disk, and skips the modules and native baselines the file already has a
+// result of with the same options, reporting that result instead, marked
+// resumed. A session stopped by a crash or a reboot then resumes where it
+// stopped when run again with the same flags, rather than redoing hours of
+// finished tasks and params. Failed results run again.
+//
+// -progress shows what is running on stderr: on a terminal a live table of
+// the benchmarks in progress, with each one's phase, run, and the running
+// mean and CV of its times, under the session's count and ETA; elsewhere a
+// line per finished benchmark.
+//
+// -metrics addr serves the session's progress, the count of samples and the
+// latest statistics of every module in the Prometheus text format at
+// http://addr/metrics while it runs, to follow a long campaign on a remote
+// host.
+//
+// -agent addr serves instead of benchmarking: it runs the sessions a
+// coordinator sends to http://addr/run, one at a time, on its own modules,
+// and streams their runs and results back. It listens on loopback unless
+// addr names a host, and takes only jobs carrying the token both sides read
+// from WASMBENCH_AGENT_TOKEN, with flags that name no file of its host.
+// -agents glob makes bench the coordinator of a -plan: it sends the plan and
+// its measurement flags to the plan's agents whose names match, each with the
+// tasks and runtimes the plan assigns it, and gathers their results into one
+// session, each result labelled with its agent and the session with each
+// agent's environment, so one plan compares x86 with ARM and Linux with
+// macOS.
+//
+// -determinism n checks instead of timing: each module is loaded n times
+// into fresh instances and run twice in each, and fails if any hash differs
+// from the first. With -native, the native runs are checked the same way,
+// from a fresh init each time, and must give the modules' hash.
+//
+// -fuzz n checks instead of timing too: each module is loaded with the
+// benchmark's params and then given n params structs derived from them, with
+// fields at boundary values (0, the type's maximum, NaN, a size past 4GiB),
+// encoded with corrupt headers, or random bytes, each validated with
+// validate_params and then run with run_task_v2 under a one-second
+// cancellation deadline. A module fails at a trap, a panic, a run whose status
+// is not the one validate_params gave, or linear memory grown past the
+// 64MiB budget it is given with set_memory_budget; the error names the case
+// and the seed, -fuzz-seed, that repeats it.
+//
+// -tasks dir loads a third-party task: dir holds its manifest, task.json, in
+// the form of a configs/tasks.json entry with the task's category and
+// reference vectors, and its modules, beside it or in a directory per
+// language. The task then runs, filters, plans, sweeps and verifies as the
+// built-in ones do, but has no native implementation.
+//
+// -cold n also times n fresh instances of each module before its warm-up:
+// compiling and instantiating it, its setup up to the params being written
+// and validated, and its first run_task, each apart from the steady-state
+// runs, for the startup cost of every task under every runtime. The first
+// runs must hash as the steady-state ones do.
+//
+// -overhead first times the runner's own calls under each runtime of the
+// session, on an empty module whose run_task returns at once: a run_task
+// call, which every measured time includes, and the writing of each task's
+// params. Every result then reports the call overhead of its runtime and its
+// median without it, so tasks of a few microseconds compare across runtimes.
+//
+// -runtime takes a list, e.g. wazero,wasmtime, to run every module under
+// each runtime in turn, and compares each module under every later runtime
+// with itself under the first: the ratio of the medians and a Mann-Whitney U
+// test of the runs, printed to stderr and recorded in the session. Plans with
+// several runtimes are compared the same way.
+//
+// -migrate brings the session files it is given, written by -json, and the
+// -history database up to the schema this bench writes, rewriting them in
+// place. Each session records its schema_version and the database its
+// user_version; -history migrates the database it opens anyway, and
+// cmd/report and cmd/benchdiff refuse sessions newer than they know.
+//
+// Built with -tags wasmtime, -runtime wasmtime runs them under wasmtime-go
+// instead with fuel metering, and each result also reports the fuel of every
+// measured run: a count of executed work that, unlike wall time, does not
+// vary with host load. Built with -tags chromedp, -runtime chrome runs each
+// module in a generated harness page of its own headless Chrome, the
+// environment the suite targets, timing its runs there with
+// performance.now().
+//
+// Usage:
+//
+//	bench [flags] [module.wasm ...]
+//
+// With no modules, every module under builds/tinygo and builds/rust, and of
+// the -tasks directories, is run; WASI command builds (*-wasi.wasm) have no
+// run_task and are left out. With -manifest, every module a cmd/build
+// manifest lists as built is run instead. Results of modules listed in the
+// manifest.json beside them report their build variant and flags. The task
+// comes from get_task_info, or else the file name (mandelbrot-o2.wasm).
+// With -native, each task also runs natively from the Go package its TinyGo
+// modules are built from, and every module reports its median as a ratio of
+// the native one. With -js node, each task also runs its pure-JavaScript
+// implementation, tasks/<task>/js/<task>.js, in a Node process with the same
+// params and run counts, timed there with performance.now(), and every module
+// reports its median as a ratio of the JavaScript one, js_ratio: whether the
+// wasm build beats plain JavaScript on the same engine at all. Built with
+// -tags chromedp, -js chrome runs it in headless Chrome instead. Both
+// baselines must hash as the modules do. The exit status is 1 if any module
+// failed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"wasmbench/common"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts options
+	flags.StringVar(&opts.runtime, "runtime", "wazero", "runtime: wazero, wasmtime (needs -tags wasmtime) to also report fuel, or chrome (needs -tags chromedp) for headless Chrome, or a list such as wazero,wasmtime to run every module under each and compare them; with -plan, globs of the plan's runtimes to run")
+	flags.StringVar(&opts.task, "task", "", "task of every module (default: from the module); with -plan, a glob of the plan's tasks to run, e.g. 'json_*'")
+	var only filter
+	flags.StringVar(&only.category, "category", "", "run only the tasks of categories matching this glob: compute (mandelbrot), memory (matrix_mul) or allocation (json_parse)")
+	flags.StringVar(&only.size, "size", "", "with -plan, run only the scales matching this glob, e.g. small or 'm*'")
+	flags.StringVar(&only.language, "language", "", "run only the modules whose builds/<language> directory matches this glob, e.g. rust")
+	flags.StringVar(&opts.params, "params", "", `JSON params overriding the task defaults, e.g. {"dimension": 128}`)
+	flags.IntVar(&opts.warmupRuns, "warmup", 5, "discarded runs before the measured ones (the minimum with -warmup-cv)")
+	flags.Float64Var(&opts.warmupCV, "warmup-cv", 0, "keep warming up until the coefficient of variation of the last 10 warm-up runs is below this, e.g. 0.02")
+	flags.IntVar(&opts.maxWarmupRuns, "max-warmup", 200, "most warm-up runs with -warmup-cv")
+	flags.IntVar(&opts.runs, "runs", 20, "measured runs")
+	flags.BoolVar(&opts.native, "native", false, "also run each task's Go implementation natively and report every module's native_ratio")
+	flags.StringVar(&opts.js, "js", "", "also run each task's JavaScript implementation under this engine, node or chrome (needs -tags chromedp), and report every module's js_ratio")
+	flags.StringVar(&opts.jsDir, "js-tasks", "tasks", "directory of the <task>/js/<task>.js implementations -js runs")
+	var taskDirs []string
+	flags.Func("tasks", "also load the third-party task in this directory, from its task.json, and run its modules when none are named; repeatable", func(dir string) error {
+		taskDirs = append(taskDirs, dir)
+		return nil
+	})
+	buildsDir := flags.String("builds", "builds", "directory searched when no modules are given")
+	manifestPath := flags.String("manifest", "", "run every module built into this cmd/build manifest.json instead of searching -builds")
+	jsonPath := flags.String("json", "", "also write the session (results and host environment) as a JSON document to this file")
+	csvPath := flags.String("csv", "", "also write the session as CSV, one row per measured run, to this file")
+	historyPath := flags.String("history", "", "also record the session in this SQLite history database (needs -tags sqlite)")
+	commit := flags.String("commit", "", "commit the modules were built from, recorded in the session (default: the checked out commit)")
+	flags.IntVar(&opts.determinism, "determinism", 0, "instead of timing, load each module this many times into fresh instances, run it twice in each and fail if any hash differs")
+	measureCalls := flags.Bool("overhead", false, "first time the runner's call into an empty module and its params writes under each runtime, and report every median also without the call overhead")
+	flags.IntVar(&opts.fuzz, "fuzz", 0, "instead of timing, run this many randomized and boundary params structs through each module's validate_params and run_task_v2 and fail it at a trap, a panic, a status validate_params did not predict or memory past the budget")
+	flags.Uint64Var(&opts.fuzzSeed, "fuzz-seed", 1, "seed of the -fuzz cases, to reproduce a failing case")
+	flags.IntVar(&opts.coldStarts, "cold", 0, "also time this many fresh instances of each module from compiling it to the end of its first run_task, apart from the steady-state runs")
+	flags.DurationVar(&opts.timeout, "timeout", 0, "fail a module, native runs included, whose benchmark takes longer than this, e.g. 10m (default: no limit)")
+	parallel := flags.Int("parallel", 1, "benchmark this many modules at once, for fast exploratory sweeps; times then only compare within the session")
+	flags.BoolVar(&opts.interleave, "interleave", false, "alternate the measured runs of each pass's modules, A, B, A, B, instead of running each module's in a block, so drift in the host's speed falls on all of them alike")
+	flags.BoolVar(&opts.strict, "strict", false, "measurement mode: one module at a time, on a thread pinned to one CPU (Linux), with a GC before each module")
+	cpu := flags.Int("cpu", -1, "with -strict, pin to this CPU (default: the highest isolated CPU, or else the highest the process may use)")
+	nice := flags.Int("nice", 0, "with -strict, the niceness of the measuring thread, e.g. -20 for the highest priority (Linux, negative needs root or CAP_SYS_NICE)")
+	sweepSpec := flags.String("sweep", "", "run every module at each of these sizes of a params field and fit its time to the size, e.g. dimension=64..512 (doubling), record_count=100,1000,10000 or width+height=128..1024")
+	verifyDir := flags.String("verify", "", "check every measured run's hash against the reference vectors in this directory, e.g. ../../data/reference_hashes, failing modules that miss")
+	toleranceSpec := flags.String("tolerance", "", "with -verify, pass a float task's runs that miss the reference hash if their output is within this tolerance of native Go's, e.g. ulps=64,abs=1e-4")
+	profileDir := flags.String("profile", "", "instead of benchmarking modules, run the -task, or each task and scale of the -plan, natively under the CPU and heap profilers and write pprof files to this directory")
+	flags.BoolVar(&opts.perf, "perf", false, "also count the instructions, cycles, branch misses and cache misses of every measured run (Linux, not under chrome)")
+	flags.BoolVar(&opts.energy, "energy", false, "also read the processor packages' RAPL energy counters around every measured run and report joules (Linux powercap, usually as root)")
+	streaming := flags.Bool("stream", false, "write a JSON line per measured run to stdout as it completes, with each result after its runs, marked by a record field of run or result")
+	showProgress := flags.Bool("progress", false, "show a live table of the running benchmarks with their run, running mean and CV, and the session's ETA on stderr (a line per benchmark when stderr is not a terminal)")
+	metricsAddr := flags.String("metrics", "", "serve the session's progress and latest results as Prometheus metrics at http://<addr>/metrics while it runs, e.g. :9464")
+	checkpointPath := flags.String("checkpoint", "", "append every result to this file as it is reported, and resume from the results it already has instead of running them again")
+	planPath := flags.String("plan", "", "run the tasks, scales, runtimes and run counts of this YAML or JSON plan, e.g. configs/bench.yaml")
+	agentAddr := flags.String("agent", "", "serve as an agent at this address, e.g. :9470 on loopback or 0.0.0.0:9470 on every interface, running the sessions a bench -agents coordinator sends it with the token in "+agentTokenVariable)
+	agentGlob := flags.String("agents", "", "with -plan, run the plan on its agents whose names match this glob, e.g. '*', instead of here, and gather their results")
+	migrate := flags.Bool("migrate", false, "instead of benchmarking, upgrade the -json session files named as arguments, and the -history database, to the current schema in place")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if *migrate {
+		for name := range set {
+			if name != "migrate" && name != "history" {
+				fmt.Fprintf(stderr, "bench: -migrate upgrades session files and the -history database; -%s does not apply\n", name)
+				return 2
+			}
+		}
+		if *historyPath != "" && openHistory == nil {
+			fmt.Fprintln(stderr, "bench: -history needs -tags sqlite")
+			return 2
+		}
+		return migrateFiles(flags.Args(), *historyPath, stderr)
+	}
+	agentToken := os.Getenv(agentTokenVariable)
+	if *agentAddr != "" {
+		for name := range set {
+			if name != "agent" && name != "builds" && name != "tasks" {
+				fmt.Fprintf(stderr, "bench: -agent runs the sessions a coordinator sends it, with their flags; -%s does not apply\n", name)
+				return 2
+			}
+		}
+	}
+	if *agentGlob != "" {
+		if *planPath == "" {
+			fmt.Fprintln(stderr, "bench: -agents runs a -plan on the agents it lists")
+			return 2
+		}
+		for name := range set {
+			if !slices.Contains(agentFlags, name) && !slices.Contains(coordinatorFlags, name) {
+				fmt.Fprintf(stderr, "bench: -agents gathers the results of other hosts; -%s does not apply\n", name)
+				return 2
+			}
+		}
+		for _, module := range flags.Args() {
+			if !filepath.IsLocal(module) {
+				fmt.Fprintf(stderr, "bench: -agents names modules by their path under each agent's -builds; %s is not one\n", module)
+				return 2
+			}
+		}
+	}
+	if (*agentAddr != "" || *agentGlob != "") && agentToken == "" {
+		fmt.Fprintf(stderr, "bench: -agent and -agents need the token they share in %s\n", agentTokenVariable)
+		return 2
+	}
+	var pluginModules []string
+	for _, dir := range taskDirs {
+		modules, err := loadPlugin(dir)
+		if err != nil {
+			fmt.Fprintln(stderr, "bench: -tasks:", err)
+			return 2
+		}
+		pluginModules = append(pluginModules, modules...)
+	}
+	if *agentAddr != "" {
+		err := serveAgent(*agentAddr, &agent{builds: *buildsDir, plugins: pluginModules, token: agentToken, log: stderr}, stderr)
+		fmt.Fprintln(stderr, "bench: -agent:", err)
+		return 1
+	}
+	if *planPath != "" {
+		if set["params"] {
+			fmt.Fprintln(stderr, "bench: -plan sets the params; -params does not apply")
+			return 2
+		}
+		// The plan's tasks and runtimes are picked from by glob
+		only.task = opts.task
+		if set["runtime"] {
+			only.runtime = opts.runtime
+		}
+	} else if only.size != "" {
+		fmt.Fprintln(stderr, "bench: -size picks the scales of a -plan")
+		return 2
+	}
+	if err := only.check(); err != nil {
+		fmt.Fprintln(stderr, "bench:", err)
+		return 2
+	}
+	if *profileDir != "" && (*sweepSpec != "" || set["determinism"] || set["manifest"] || flags.NArg() > 0) {
+		fmt.Fprintln(stderr, "bench: -profile runs the tasks natively; -sweep, -determinism, -manifest and modules do not apply")
+		return 2
+	}
+	if *profileDir != "" && *checkpointPath != "" {
+		fmt.Fprintln(stderr, "bench: -profile writes profiles, not results to resume; -checkpoint does not apply")
+		return 2
+	}
+	if *profileDir != "" && *measureCalls {
+		fmt.Fprintln(stderr, "bench: -profile runs no modules; -overhead does not apply")
+		return 2
+	}
+	if *sweepSpec != "" && (*planPath != "" || set["determinism"]) {
+		fmt.Fprintln(stderr, "bench: -sweep sets the sizes to time; -plan and -determinism do not apply")
+		return 2
+	}
+	var sw sweep
+	if *sweepSpec != "" {
+		var err error
+		if sw, err = parseSweep(*sweepSpec); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			return 2
+		}
+	}
+	if opts.determinism < 0 {
+		fmt.Fprintln(stderr, "bench: -determinism must be at least 0")
+		return 2
+	}
+	if opts.coldStarts < 0 || (opts.coldStarts > 0 && set["determinism"]) {
+		fmt.Fprintln(stderr, "bench: -cold must be at least 0, and -determinism times nothing")
+		return 2
+	}
+	if opts.fuzz < 0 || (opts.fuzz > 0 && (set["determinism"] || set["cold"] || *sweepSpec != "" || *profileDir != "" || opts.native)) {
+		fmt.Fprintln(stderr, "bench: -fuzz must be at least 0, and -determinism, -cold, -sweep, -profile and -native do not apply")
+		return 2
+	}
+	if opts.js != "" {
+		if _, ok := jsEngines[opts.js]; !ok {
+			fmt.Fprintf(stderr, "bench: unknown -js engine %q: node, or chrome with -tags chromedp\n", opts.js)
+			return 2
+		}
+		if set["determinism"] || opts.fuzz > 0 || *profileDir != "" {
+			fmt.Fprintln(stderr, "bench: -js times the JavaScript baselines; -determinism, -fuzz and -profile do not apply")
+			return 2
+		}
+	}
+	if opts.warmupRuns < 0 || opts.runs < 1 {
+		fmt.Fprintln(stderr, "bench: -warmup must be at least 0 and -runs at least 1")
+		return 2
+	}
+	if *parallel < 1 || (opts.strict && *parallel > 1) {
+		fmt.Fprintln(stderr, "bench: -parallel must be at least 1, and -strict runs serially")
+		return 2
+	}
+	if opts.interleave && (*parallel > 1 || opts.determinism > 0 || opts.fuzz > 0 || *profileDir != "") {
+		fmt.Fprintln(stderr, "bench: -interleave alternates the timed runs of a serial session; -parallel, -determinism, -fuzz and -profile do not apply")
+		return 2
+	}
+	if *toleranceSpec != "" {
+		tolerance, err := common.ParseTolerance(*toleranceSpec)
+		if err != nil || *verifyDir == "" {
+			fmt.Fprintln(stderr, "bench: -tolerance compares the output of runs -verify finds off the reference hash, within bounds such as ulps=64,abs=1e-4")
+			return 2
+		}
+		opts.tolerance = &tolerance
+	}
+	if !opts.strict && (*cpu != -1 || *nice != 0) || *cpu < -1 || *nice < -20 || *nice > 19 {
+		fmt.Fprintln(stderr, "bench: -cpu and -nice set the -strict thread's CPU and its niceness, from -20 to 19")
+		return 2
+	}
+	if opts.timeout < 0 {
+		fmt.Fprintln(stderr, "bench: -timeout must not be negative")
+		return 2
+	}
+	if opts.warmupCV < 0 || (opts.warmupCV > 0 && opts.maxWarmupRuns < opts.warmupRuns) {
+		fmt.Fprintln(stderr, "bench: -warmup-cv must be at least 0 and -max-warmup at least -warmup")
+		return 2
+	}
+	var engines []string
+	if *planPath == "" {
+		var err error
+		if engines, err = parseEngines(opts.runtime); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			return 2
+		}
+		opts.runtime = engines[0]
+	}
+	if opts.coldStarts > 0 && (slices.Contains(engines, "chrome") || opts.runtime == "chrome") {
+		fmt.Fprintln(stderr, "bench: -cold times instantiation in this process; chrome would time starting the browser")
+		return 2
+	}
+	if opts.perf {
+		if slices.Contains(engines, "chrome") || opts.runtime == "chrome" {
+			fmt.Fprintln(stderr, "bench: -perf counts this process's runs; chrome runs modules in its own")
+			return 2
+		}
+		// Fail before any benchmark on a host without the counters, which
+		// for -agents is each agent's
+		if *agentGlob == "" {
+			counters, err := openPerf()
+			if err != nil {
+				fmt.Fprintln(stderr, "bench: -perf:", err)
+				return 1
+			}
+			counters.close()
+		}
+	}
+	if opts.energy {
+		if *parallel > 1 {
+			fmt.Fprintln(stderr, "bench: -energy reads the whole processor's counters, so it needs -parallel 1")
+			return 2
+		}
+		if _, err := openEnergy(powercapDir); err != nil && *agentGlob == "" {
+			fmt.Fprintln(stderr, "bench: -energy:", err)
+			return 1
+		}
+	}
+	opts.log = stderr
+	if *verifyDir != "" && *agentGlob == "" {
+		var err error
+		if opts.references, err = loadReferences(*verifyDir); err != nil {
+			fmt.Fprintln(stderr, "bench: -verify:", err)
+			return 1
+		}
+	}
+
+	var store history
+	if *historyPath != "" {
+		if openHistory == nil {
+			fmt.Fprintln(stderr, "bench: -history needs -tags sqlite")
+			return 2
+		}
+		var err error
+		if store, err = openHistory(*historyPath); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			return 1
+		}
+		defer store.Close()
+	}
+	if *commit == "" {
+		*commit = gitCommit()
+	}
+
+	if *agentGlob != "" {
+		agents, plan, err := planAgents(*planPath, *agentGlob, set)
+		if err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			return 2
+		}
+		job := agentJob{Plan: string(plan), Flags: map[string]string{}, Modules: flags.Args()}
+		flags.Visit(func(f *flag.Flag) {
+			if slices.Contains(agentFlags, f.Name) {
+				job.Flags[f.Name] = f.Value.String()
+			}
+		})
+		var stream *runStream
+		if *streaming {
+			stream = newRunStream(stdout)
+		}
+		session := newSession(*commit)
+		status := coordinate(context.Background(), agents, job, agentToken, session, stdout, stderr, stream)
+		return max(status, session.finish(*jsonPath, *csvPath, stderr))
+	}
+
+	modules := flags.Args()
+	switch {
+	case *profileDir != "":
+		// Profiling runs no modules
+	case *manifestPath != "" && len(modules) > 0:
+		fmt.Fprintln(stderr, "bench: -manifest names the modules; give either")
+		return 2
+	case *manifestPath != "":
+		var err error
+		if modules, err = manifestModules(*manifestPath); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			return 1
+		}
+	case len(modules) == 0:
+		modules = append(findModules(*buildsDir), pluginModules...)
+		if len(modules) == 0 {
+			fmt.Fprintf(stderr, "bench: no modules under %s; build them first or name them\n", *buildsDir)
+			return 1
+		}
+	}
+	passes := []pass{{opts, modules}}
+	var err error
+	switch {
+	case *profileDir != "":
+		if passes, err = profilePasses(*planPath, opts, set); err == nil {
+			err = os.MkdirAll(*profileDir, 0o755)
+		}
+	case *planPath != "":
+		passes, err = planPasses(*planPath, modules, opts, set)
+	case *sweepSpec != "":
+		passes, err = sw.passes(modules, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "bench:", err)
+		return 2
+	}
+	if len(engines) > 1 && *profileDir == "" {
+		passes = enginePasses(passes, engines)
+	}
+	if only != (filter{}) {
+		if passes = only.apply(passes, *profileDir == ""); len(passes) == 0 {
+			fmt.Fprintln(stderr, "bench: nothing matches -task, -category, -size, -runtime and -language")
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	encoder := json.NewEncoder(stdout)
+	session := newSession(*commit)
+	session.Environment.Parallel = *parallel
+	session.Environment.Interleaved = opts.interleave
+	if opts.strict {
+		pin, unpin, err := pinThread(*cpu, *nice)
+		if err != nil {
+			fmt.Fprintln(stderr, "bench: -strict:", err)
+			return 1
+		}
+		defer unpin()
+		session.Environment.Strict = true
+		if pin.cpu >= 0 {
+			session.Environment.PinnedCPU = &pin.cpu
+		}
+		session.Environment.IsolatedCPU = pin.isolated
+		session.Environment.Nice = *nice
+	}
+	// A -profile pass profiles a task rather than running modules
+	planned := len(passes)
+	if *profileDir == "" {
+		planned = 0
+		for _, p := range passes {
+			planned += len(p.modules)
+		}
+	}
+	var saved *checkpoint
+	if *checkpointPath != "" {
+		if saved, err = openCheckpoint(*checkpointPath); err != nil {
+			fmt.Fprintln(stderr, "bench: -checkpoint:", err)
+			return 1
+		}
+		defer saved.close()
+		if len(saved.done) > 0 {
+			fmt.Fprintf(stderr, "bench: resuming from %s, which has %d results\n", *checkpointPath, len(saved.done))
+		}
+		for i := range passes {
+			passes[i].opts.checkpoint = saved
+		}
+	}
+	var stream *runStream
+	if *streaming {
+		stream = newRunStream(stdout)
+		for i := range passes {
+			passes[i].opts.stream = stream
+		}
+	}
+	var ui *progressUI
+	if *showProgress {
+		ui = newProgress(stderr, planned)
+		defer ui.close()
+		stderr = ui
+		for i := range passes {
+			passes[i].opts.progress, passes[i].opts.log = ui, ui
+		}
+	}
+	var exporter *metrics
+	if *metricsAddr != "" {
+		exporter = newMetrics(planned)
+		addr, stop, err := serveMetrics(*metricsAddr, exporter)
+		if err != nil {
+			fmt.Fprintln(stderr, "bench: -metrics:", err)
+			return 1
+		}
+		defer stop()
+		fmt.Fprintf(stderr, "bench: metrics at http://%s/metrics\n", addr)
+	}
+	// The overhead of each runtime, before any module runs
+	overheads := map[string]*CallOverhead{}
+	if *measureCalls {
+		for _, p := range passes {
+			if _, ok := overheads[p.opts.runtime]; ok {
+				continue
+			}
+			o, err := measureOverhead(ctx, p.opts.runtime, stderr)
+			if err != nil {
+				fmt.Fprintf(stderr, "bench: -overhead: %s: %v\n", p.opts.runtime, err)
+				return 1
+			}
+			overheads[p.opts.runtime] = o
+			if o != nil {
+				o.write(stderr)
+				session.Overhead = append(session.Overhead, *o)
+			}
+		}
+	}
+	versions := toolchains{}
+	manifests := builds{}
+	status := 0
+	// benchmark is false for a native or JavaScript baseline, which -metrics
+	// and -progress do not count as one of the session's benchmarks. A result
+	// is in the checkpoint before it is printed, so whatever was printed
+	// resumes.
+	report := func(result Result, job checkpointJob, benchmark bool) bool {
+		if err := saved.record(job, result); err != nil {
+			fmt.Fprintln(stderr, "bench: -checkpoint:", err)
+			status = 1
+			return false
+		}
+		result.Toolchain = versions.toolchain(&result)
+		manifests.label(&result)
+		session.Environment.recordToolchain(&result)
+		session.Results = append(session.Results, result)
+		if exporter != nil {
+			exporter.observe(result, benchmark)
+		}
+		if ui != nil {
+			ui.finish(result, benchmark)
+		}
+		if result.Error != "" {
+			fmt.Fprintf(stderr, "bench: %s: %s\n", result.Module, result.Error)
+			status = 1
+		} else if v := result.Verification; v != nil && v.Vector == "" {
+			fmt.Fprintf(stderr, "bench: %s: no reference vector has these params, so its runs are unverified\n", result.Module)
+		} else if v != nil && v.Tolerance.within() {
+			fmt.Fprintf(stderr, "bench: %s: %d of %d runs did not hash reference vector %s's %d, but the output is within %s of native Go's (at most %d ULPs off)\n",
+				result.Module, v.Mismatches, v.Runs, v.Vector, v.ExpectedHash, v.Tolerance.Tolerance, v.Tolerance.MaxULPs)
+		}
+		var err error
+		if stream != nil {
+			err = stream.result(result)
+		} else {
+			err = encoder.Encode(result)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			status = 1
+			return false
+		}
+		return true
+	}
+
+	if *profileDir != "" {
+		for _, p := range passes {
+			if !report(profileNative(ctx, p.opts.task, p.opts, *profileDir), checkpointJob{}, true) {
+				return status
+			}
+		}
+	}
+
+	// Native and JavaScript baselines of the current pass by task, each run
+	// and printed before its first module. They run here in turn whatever
+	// -parallel, since native tasks share their package's state.
+	var baselines, jsBaselines map[string]*Result
+	current := -1
+	for i, result := range benchPasses(ctx, passes, *parallel) {
+		p := passes[i]
+		if i != current {
+			baselines, jsBaselines, current = map[string]*Result{}, map[string]*Result{}, i
+		}
+		// Baselines are run for the tasks of modules that ran
+		ran := result.Error == ""
+		if p.opts.native && ran {
+			baseline, ok := baselines[result.Task]
+			if !ok {
+				job := nativeJob(result.Task, p.opts)
+				native, ok := saved.completed(job)
+				if !ok {
+					native = benchNative(ctx, result.Task, p.opts)
+				}
+				baseline = &native
+				baselines[result.Task] = baseline
+				if !report(native, job, false) {
+					return status
+				}
+			}
+			if !result.Resumed {
+				result.compareNative(baseline)
+			}
+		}
+		if p.opts.js != "" && ran {
+			baseline, ok := jsBaselines[result.Task]
+			if !ok {
+				job := jsJob(result.Task, p.opts)
+				js, ok := saved.completed(job)
+				if !ok {
+					js = benchJS(ctx, result.Task, p.opts)
+				}
+				baseline = &js
+				jsBaselines[result.Task] = baseline
+				if !report(js, job, false) {
+					return status
+				}
+			}
+			if !result.Resumed {
+				result.compareJS(baseline)
+			}
+		}
+		if o := overheads[result.Runtime]; o != nil && len(result.SamplesMs) > 0 && !result.Resumed {
+			result.correct(o)
+		}
+		if !report(result, jobOf(result.Module, p.opts), true) {
+			return status
+		}
+	}
+
+	if *sweepSpec != "" {
+		session.Scaling = sw.fit(session.Results)
+		if err := session.Scaling.write(stderr); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			status = 1
+		}
+	}
+
+	status = max(status, session.finish(*jsonPath, *csvPath, stderr))
+	if store != nil {
+		if err := store.record(session); err != nil {
+			fmt.Fprintln(stderr, "bench: history:", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// findModules lists the TinyGo and Rust builds under dir, leaving out the
+// WASI command builds
+func findModules(dir string) []string {
+	var modules []string
+	for _, language := range []string{"tinygo", "rust"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, language, "*.wasm"))
+		for _, path := range matches {
+			if !strings.HasSuffix(path, "-wasi.wasm") {
+				modules = append(modules, path)
+			}
+		}
+	}
+	return modules
+}
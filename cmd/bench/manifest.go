@@ -0,0 +1,128 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// buildManifest is the part of a cmd/build manifest.json the runner reads
+type buildManifest struct {
+	Toolchain string          `json:"toolchain"`
+	Artifacts []buildArtifact `json:"artifacts"`
+}
+
+// buildArtifact is one module of a manifest
+type buildArtifact struct {
+	File    string            `json:"file"`
+	Variant string            `json:"variant"`
+	Flags   map[string]string `json:"flags"`
+	SHA256  string            `json:"sha256"`
+	Error   string            `json:"error"`
+}
+
+// loadManifest reads the manifest at path
+func loadManifest(path string) (buildManifest, error) {
+	var m buildManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// manifestModules lists the artifacts of the manifest at path that built
+func manifestModules(path string) ([]string, error) {
+	m, err := loadManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	var modules []string
+	for _, a := range m.Artifacts {
+		if a.Error == "" {
+			modules = append(modules, filepath.Join(filepath.Dir(path), a.File))
+		}
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("%s lists no built modules", path)
+	}
+	return modules, nil
+}
+
+// verifyArtifact refuses a module that does not match the manifest.json
+// beside it: one the manifest does not list, whose last build failed, or whose
+// contents changed since it was recorded, so a stale binary is never
+// benchmarked under a new build's name. Modules without a manifest pass.
+func verifyArtifact(path string, wasm []byte) error {
+	manifest := filepath.Join(filepath.Dir(path), "manifest.json")
+	m, err := loadManifest(manifest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	i := slices.IndexFunc(m.Artifacts, func(a buildArtifact) bool { return a.File == name })
+	switch {
+	case i < 0:
+		return fmt.Errorf("not listed in %s; build it with cmd/build or record it with build -index", manifest)
+	case m.Artifacts[i].Error != "":
+		return fmt.Errorf("its last build failed, according to %s", manifest)
+	}
+	sum := sha256.Sum256(wasm)
+	if hex.EncodeToString(sum[:]) != m.Artifacts[i].SHA256 {
+		return fmt.Errorf("SHA-256 differs from %s, so it is not the build recorded there; rebuild it or record it with build -index", manifest)
+	}
+	return nil
+}
+
+// builds caches the manifest.json beside each module, keyed by directory;
+// the zero manifest where there is none
+type builds map[string]buildManifest
+
+// label adds the build variant and flags of the result's module, and its
+// toolchain if metrics.json had none, from the manifest cmd/build wrote
+// beside it. Modules without one are left as they are.
+func (b builds) label(r *Result) {
+	if r.Runtime == "native" || r.Language == "js" {
+		return
+	}
+	dir := filepath.Dir(r.Module)
+	m, ok := b[dir]
+	if !ok {
+		m, _ = loadManifest(filepath.Join(dir, "manifest.json"))
+		b[dir] = m
+	}
+	for _, a := range m.Artifacts {
+		if a.File == filepath.Base(r.Module) {
+			r.Build, r.BuildFlags = a.Variant, a.Flags
+			if r.Toolchain == "" {
+				r.Toolchain = m.Toolchain
+			}
+			return
+		}
+	}
+}
+
+// config formats the result's build flags as name=value pairs in name order,
+// the configuration its module was built in whatever the file is called; ""
+// for a module without flags in a manifest
+func (r Result) config() string {
+	parts := make([]string, 0, len(r.BuildFlags))
+	for _, name := range slices.Sorted(maps.Keys(r.BuildFlags)) {
+		parts = append(parts, name+"="+r.BuildFlags[name])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunManifest(t *testing.T) {
+	dir := t.TempDir()
+	sum := sha256.Sum256(fakeTask)
+	manifest := fmt.Sprintf(`{"toolchain": "tinygo version 0.39.0 linux/amd64", "artifacts": [
+		{"file": "matrix_mul-o2.wasm", "variant": "o2", "flags": {"opt": "2", "gc": "conservative"}, "sha256": "%[1]x"},
+		{"file": "matrix_mul-oz-gcleaking.wasm", "variant": "oz-gcleaking", "flags": {"opt": "z", "gc": "leaking"}, "sha256": "%[1]x"},
+		{"file": "matrix_mul-os.wasm", "variant": "os", "flags": {"opt": "s", "gc": "conservative"}, "error": "exit status 1"},
+		{"file": "matrix_mul-o1.wasm", "variant": "o1", "flags": {"opt": "1", "gc": "conservative"}, "sha256": "%[1]x"}]}`, sum)
+	path := filepath.Join(dir, "manifest.json")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"matrix_mul-o2.wasm", "matrix_mul-oz-gcleaking.wasm", "matrix_mul-os.wasm", "matrix_mul-o3.wasm"} {
+		if err := os.WriteFile(filepath.Join(dir, name), fakeTask, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Rebuilt since the manifest was written
+	if err := os.WriteFile(filepath.Join(dir, "matrix_mul-o1.wasm"), counterTask, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	// The stale matrix_mul-o1.wasm fails the run
+	if code := run([]string{"-warmup", "0", "-runs", "1", "-manifest", path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1: %s", code, stderr.String())
+	}
+	var builds []string
+	for line := range strings.Lines(stdout.String()) {
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Error != "" {
+			continue
+		}
+		if result.Toolchain != "tinygo version 0.39.0 linux/amd64" || result.BuildFlags["opt"] == "" {
+			t.Errorf("%s: toolchain %q and flags %v, expected the manifest's", result.Module, result.Toolchain, result.BuildFlags)
+		}
+		builds = append(builds, result.Build)
+	}
+	// The failed build is not run, and the stale one is refused
+	if strings.Join(builds, " ") != "o2 oz-gcleaking" {
+		t.Errorf("ran builds %v, expected o2 and oz-gcleaking", builds)
+	}
+
+	if code := run([]string{"-manifest", path, filepath.Join(dir, "matrix_mul-o2.wasm")}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -manifest and modules, expected 2", code)
+	}
+
+	// Modules that do not match the manifest are refused
+	for _, c := range []struct{ name, refusal string }{
+		{"matrix_mul-o1.wasm", "SHA-256 differs"},
+		{"matrix_mul-os.wasm", "last build failed"},
+		{"matrix_mul-o3.wasm", "not listed"},
+	} {
+		stdout.Reset()
+		stderr.Reset()
+		if code := run([]string{"-warmup", "0", "-runs", "1", filepath.Join(dir, c.name)}, &stdout, &stderr); code != 1 {
+			t.Errorf("%s: exit status %d, expected 1", c.name, code)
+		}
+		if !strings.Contains(stderr.String(), c.refusal) {
+			t.Errorf("%s: stderr %q, expected %q", c.name, stderr.String(), c.refusal)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// metrics is a session's progress and latest results in the Prometheus text
+// exposition format, served with -metrics so a campaign of many hours on a
+// remote host can be followed by scraping it. Results are observed as they
+// are reported; nothing is measured while a module runs.
+type metrics struct {
+	mu      sync.Mutex
+	started time.Time
+	planned int // Benchmarks of the session, native baselines aside
+	done    int
+	failed  int
+	series  map[seriesKey]*series
+}
+
+// seriesKey labels the results of one module at one scale
+type seriesKey struct {
+	task, module, runtime, scale string
+}
+
+// series is the results so far of a seriesKey
+type series struct {
+	results  int
+	failures int
+	samples  int
+	stats    stats.Summary // Of the latest result with samples
+}
+
+func newMetrics(planned int) *metrics {
+	return &metrics{started: time.Now(), planned: planned, series: map[seriesKey]*series{}}
+}
+
+// observe adds a reported result; benchmark is false for a native baseline,
+// which the planned count leaves out
+func (m *metrics) observe(result Result, benchmark bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if benchmark {
+		m.done++
+		if result.Error != "" {
+			m.failed++
+		}
+	}
+	key := seriesKey{result.Task, result.Module, result.Runtime, result.Scale}
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{}
+		m.series[key] = s
+	}
+	s.results++
+	if result.Error != "" {
+		s.failures++
+	}
+	s.samples += len(result.SamplesMs)
+	if len(result.SamplesMs) > 0 {
+		s.stats = result.Stats
+	}
+}
+
+// ServeHTTP writes the metrics
+func (m *metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.writeTo(w)
+}
+
+// writeTo writes the metrics in the text exposition format, each series in
+// label order so scrapes compare line by line
+func (m *metrics) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	metric("wasmbench_session_start_time_seconds", "gauge", "Unix time the session started.")
+	fmt.Fprintf(w, "wasmbench_session_start_time_seconds %d\n", m.started.Unix())
+	metric("wasmbench_benchmarks_planned", "gauge", "Benchmarks the session runs, native baselines aside.")
+	fmt.Fprintf(w, "wasmbench_benchmarks_planned %d\n", m.planned)
+	metric("wasmbench_benchmarks_done", "counter", "Benchmarks finished, failed ones included.")
+	fmt.Fprintf(w, "wasmbench_benchmarks_done %d\n", m.done)
+	metric("wasmbench_benchmarks_failed", "counter", "Benchmarks that failed.")
+	fmt.Fprintf(w, "wasmbench_benchmarks_failed %d\n", m.failed)
+
+	keys := make([]seriesKey, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		return cmp.Or(cmp.Compare(a.task, b.task), cmp.Compare(a.module, b.module), cmp.Compare(a.runtime, b.runtime), cmp.Compare(a.scale, b.scale))
+	})
+	for _, family := range []struct {
+		name, kind, help string
+		value            func(s *series) float64
+	}{
+		{"wasmbench_results_total", "counter", "Results reported, one per repetition.", func(s *series) float64 { return float64(s.results) }},
+		{"wasmbench_failures_total", "counter", "Results that failed.", func(s *series) float64 { return float64(s.failures) }},
+		{"wasmbench_samples_total", "counter", "Measured runs timed.", func(s *series) float64 { return float64(s.samples) }},
+		{"wasmbench_median_ms", "gauge", "Median run time of the latest result, outliers removed.", func(s *series) float64 { return s.stats.Median }},
+		{"wasmbench_mean_ms", "gauge", "Mean run time of the latest result, outliers removed.", func(s *series) float64 { return s.stats.Mean }},
+		{"wasmbench_min_ms", "gauge", "Fastest run of the latest result.", func(s *series) float64 { return s.stats.Min }},
+		{"wasmbench_max_ms", "gauge", "Slowest run of the latest result, outliers removed.", func(s *series) float64 { return s.stats.Max }},
+		{"wasmbench_cv", "gauge", "Coefficient of variation of the latest result's runs.", func(s *series) float64 { return s.stats.CV }},
+	} {
+		metric(family.name, family.kind, family.help)
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s{task=%s,module=%s,runtime=%s,scale=%s} %s\n", family.name,
+				label(key.task), label(key.module), label(key.runtime), label(key.scale),
+				strconv.FormatFloat(family.value(m.series[key]), 'g', -1, 64))
+		}
+	}
+}
+
+// label quotes a label value, escaping as the exposition format does
+func label(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// serveMetrics serves m at /metrics on addr until close, returning the
+// address it listens on
+func serveMetrics(addr string, m *metrics) (listening string, close func() error, err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go server.Serve(listener)
+	return listener.Addr().String(), server.Close, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wasmbench/stats"
+)
+
+func TestMetricsServe(t *testing.T) {
+	m := newMetrics(3)
+	m.observe(Result{Module: "native", Runtime: "native", Task: "matrix_mul", SamplesMs: []float64{1}, Stats: stats.Summary{Median: 1}}, false)
+	m.observe(Result{Module: "a.wasm", Runtime: "wazero", Task: "matrix_mul", Scale: "small", SamplesMs: []float64{2, 3}, Stats: stats.Summary{Median: 2.5, CV: 0.1}}, true)
+	m.observe(Result{Module: "a.wasm", Runtime: "wazero", Task: "matrix_mul", Scale: "small", SamplesMs: []float64{4, 4}, Stats: stats.Summary{Median: 4}}, true)
+	m.observe(Result{Module: `b"\.wasm`, Runtime: "wazero", Task: "mandelbrot", SamplesMs: []float64{}, Error: "self test failed"}, true)
+
+	server := httptest.NewServer(m)
+	defer server.Close()
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := response.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type %q, expected the text exposition format", ct)
+	}
+	for _, line := range []string{
+		"# TYPE wasmbench_benchmarks_done counter",
+		"wasmbench_benchmarks_planned 3",
+		// The native baseline is not one of the planned benchmarks
+		"wasmbench_benchmarks_done 3",
+		"wasmbench_benchmarks_failed 1",
+		`wasmbench_results_total{task="matrix_mul",module="a.wasm",runtime="wazero",scale="small"} 2`,
+		`wasmbench_samples_total{task="matrix_mul",module="a.wasm",runtime="wazero",scale="small"} 4`,
+		// The latest result's statistics
+		`wasmbench_median_ms{task="matrix_mul",module="a.wasm",runtime="wazero",scale="small"} 4`,
+		`wasmbench_median_ms{task="matrix_mul",module="native",runtime="native",scale=""} 1`,
+		`wasmbench_failures_total{task="mandelbrot",module="b\"\\.wasm",runtime="wazero",scale=""} 1`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("no line %s in\n%s", line, body)
+		}
+	}
+}
+
+func TestRunMetrics(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-metrics", "127.0.0.1:0", "-warmup", "0", "-runs", "2", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "bench: metrics at http://127.0.0.1:") {
+		t.Errorf("stderr %q, expected the metrics address", stderr.String())
+	}
+	if code := run([]string{"-metrics", "127.0.0.1:-1", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d with a bad -metrics address, expected 1", code)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// sessionVersion is the version of the session format -json writes, its
+// schema_version. Bump it with a step in sessionMigrations whenever a field
+// is renamed or removed or changes meaning; a new field needs neither.
+// cmd/report and cmd/benchdiff refuse sessions newer than the version they
+// know.
+const sessionVersion = 1
+
+// sessionMigrations bring a session document at version i to version i+1.
+// Version 0 is a session from before versioning.
+var sessionMigrations = []func(session map[string]any) error{
+	// 1: environment.parallel, 1 for a serial session, came with -parallel,
+	// and every session before it ran serially
+	func(session map[string]any) error {
+		environment, ok := session["environment"].(map[string]any)
+		if !ok {
+			return errors.New("no environment object")
+		}
+		if _, ok := environment["parallel"]; !ok {
+			environment["parallel"] = 1
+		}
+		return nil
+	},
+}
+
+// migrateSession upgrades the session document data to sessionVersion and
+// returns it with the version it was at. A field the Session type does not
+// have fails it rather than being dropped.
+func migrateSession(data []byte) (*Session, int, error) {
+	var document map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&document); err != nil {
+		return nil, 0, err
+	}
+	version := 0
+	if v, ok := document["schema_version"]; ok {
+		n, ok := v.(json.Number)
+		version64, err := n.Int64()
+		if !ok || err != nil || version64 < 0 {
+			return nil, 0, fmt.Errorf("schema_version %v is not a version", v)
+		}
+		version = int(version64)
+	}
+	if version > sessionVersion {
+		return nil, version, fmt.Errorf("schema version %d is newer than this bench's %d", version, sessionVersion)
+	}
+	for i, migrate := range sessionMigrations[version:] {
+		if err := migrate(document); err != nil {
+			return nil, version, fmt.Errorf("migrating to schema version %d: %w", version+i+1, err)
+		}
+	}
+	document["schema_version"] = sessionVersion
+
+	upgraded, err := json.Marshal(document)
+	if err != nil {
+		return nil, version, err
+	}
+	var s Session
+	decoder = json.NewDecoder(bytes.NewReader(upgraded))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&s); err != nil {
+		return nil, version, err
+	}
+	return &s, version, nil
+}
+
+// migrateFiles is -migrate: it brings the history database at historyPath, if
+// any, and each session file at paths up to the current schema, rewriting
+// the files in place, and returns the exit status
+func migrateFiles(paths []string, historyPath string, stderr io.Writer) int {
+	status := 0
+	if historyPath != "" {
+		// Opening the database migrates it
+		store, err := openHistory(historyPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "bench: history:", err)
+			status = 1
+		} else {
+			store.Close()
+			fmt.Fprintf(stderr, "bench: %s: history at the current schema\n", historyPath)
+		}
+	}
+	for _, path := range paths {
+		if err := migrateFile(path, stderr); err != nil {
+			fmt.Fprintf(stderr, "bench: %s: %v\n", path, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// migrateFile upgrades the session file at path, replacing it only once the
+// upgraded session is written in full
+func migrateFile(path string, stderr io.Writer) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	s, version, err := migrateSession(data)
+	if err != nil {
+		return err
+	}
+	if version == sessionVersion {
+		fmt.Fprintf(stderr, "bench: %s: already at schema version %d\n", path, version)
+		return nil
+	}
+	if err := writeFile(path+".tmp", s.writeJSON); err != nil {
+		os.Remove(path + ".tmp")
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "bench: %s: schema version %d to %d\n", path, version, sessionVersion)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// legacySession is a session from before versioning and before -parallel
+const legacySession = `{
+  "started": "2025-01-02T03:04:05Z",
+  "environment": {"go_version": "go1.24.0", "os": "linux", "arch": "amd64", "cpus": 8},
+  "results": [{"module": "builds/tinygo/matrix_mul-o2.wasm", "runtime": "wazero", "task": "matrix_mul",
+    "warmup_runs": 5, "hash": 15, "samples_ms": [1.5, 1.25], "stats": {"n": 2, "median": 1.375}}]
+}`
+
+func TestMigrateSession(t *testing.T) {
+	s, version, err := migrateSession([]byte(legacySession))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 || s.SchemaVersion != sessionVersion || s.Environment.Parallel != 1 {
+		t.Errorf("from version %d to %d with parallel %d, expected from 0 to %d with 1", version, s.SchemaVersion, s.Environment.Parallel, sessionVersion)
+	}
+	if len(s.Results) != 1 || s.Results[0].Hash != 15 || s.Results[0].Stats.Median != 1.375 {
+		t.Errorf("results %+v, expected the session's result unchanged", s.Results)
+	}
+
+	for _, c := range []struct{ session, want string }{
+		{`{"schema_version": 99, "environment": {}}`, "schema version 99 is newer than this bench's 1"},
+		{`{"schema_version": "one"}`, "is not a version"},
+		{`{"environment": {}, "comments": "by hand"}`, `unknown field "comments"`},
+		{`{"results": []}`, "migrating to schema version 1: no environment object"},
+	} {
+		if _, _, err := migrateSession([]byte(c.session)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: %v, expected %q", c.session, err, c.want)
+		}
+	}
+}
+
+func TestRunMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(path, []byte(legacySession), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-migrate", path}, &stdout, &stderr); code != 0 || !strings.Contains(stderr.String(), "schema version 0 to 1") {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"schema_version": 1`) || !strings.Contains(string(data), `"parallel": 1`) {
+		t.Errorf("migrated session:\n%s", data)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"-migrate", path}, &stdout, &stderr); code != 0 || !strings.Contains(stderr.String(), "already at schema version 1") {
+		t.Errorf("exit status %d migrating a current session: %s", code, stderr.String())
+	}
+	if code := run([]string{"-migrate", "-runs", "3", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("-migrate with -runs = %d, expected a usage error", code)
+	}
+	if code := run([]string{"-migrate", filepath.Join(t.TempDir(), "missing.json")}, &stdout, &stderr); code != 1 {
+		t.Errorf("-migrate of a missing file = %d, expected 1", code)
+	}
+}
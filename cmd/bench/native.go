@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// nativeTask is a task's Go implementation, compiled into the runner from the
+// same package the TinyGo modules are built from
+type nativeTask struct {
+	init     func(seed uint32)
+	selfTest func() uint32
+	runTask  func(paramsPtr, resultPtr uintptr) uint32 // run_task_v2
+}
+
+// nativeResult receives run_task_v2's result. It is a global because the
+// task writes it through a uintptr: a local could move with the goroutine's
+// stack when a run grows it, leaving the write in the old stack.
+var nativeResult common.TaskResult
+
+// nativeMu serializes the runs of the task packages, whose state a native
+// baseline shares with -tolerance's reference outputs while -parallel runs
+// modules alongside the baselines
+var nativeMu sync.Mutex
+
+// benchNative runs task natively with the params and run counts of the wasm
+// modules, the baseline -native reports each module against. Native runs
+// share the task package's state, which init resets, and each pass (each
+// -plan step) runs a task natively at most once. Past opts.timeout, the
+// watchdog raises the task's cancellation flag, which only stops tasks that
+// poll it.
+func benchNative(ctx context.Context, task string, opts options) Result {
+	result := Result{Module: "native", Runtime: "native", Task: task, Language: "go", Scale: opts.scale, Repetition: opts.repetition,
+		WarmupRuns: opts.warmupRuns, SamplesMs: []float64{}, progress: opts.progress.begin("native", "native", opts.scale),
+		stream: opts.stream}
+	ctx, cancel := withTimeout(ctx, opts.timeout)
+	defer cancel()
+	stop := context.AfterFunc(ctx, common.RequestCancel)
+	defer stop()
+	result.setError(ctx, opts, result.benchNative(ctx, opts))
+	result.progress.end()
+	return result
+}
+
+func (r *Result) benchNative(ctx context.Context, opts options) error {
+	spec, ok := tasks[r.Task]
+	if !ok {
+		return fmt.Errorf("unknown task %q", r.Task)
+	}
+	if spec.native.runTask == nil {
+		return fmt.Errorf("task %s has no native implementation", r.Task)
+	}
+	params, err := buildParams(spec, opts.params)
+	if err != nil {
+		return err
+	}
+	r.Params = paramValues(spec, params)
+	r.Verification = opts.references.match(r.Task, params)
+
+	nativeMu.Lock()
+	defer nativeMu.Unlock()
+	native := spec.native
+	native.init(initSeed)
+	if status := native.selfTest(); status != common.StatusOK {
+		return fmt.Errorf("self test failed (status %d): %s", status, common.LastError())
+	}
+
+	// params is heap memory the closure keeps alive across every run
+	runTask := func() (uint32, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		status := native.runTask(uintptr(unsafe.Pointer(&params[0])), uintptr(unsafe.Pointer(&nativeResult)))
+		if status != common.StatusOK {
+			return 0, diagnose("run_task failed", status, common.ErrorCode(), common.LastError(), r.Params)
+		}
+		return nativeResult.Hash, nil
+	}
+	// A native task cannot be instantiated afresh, so each -determinism round
+	// starts from init instead
+	if opts.determinism > 0 {
+		for i := 1; i <= opts.determinism; i++ {
+			if i > 1 {
+				native.init(initSeed)
+			}
+			if err := r.compareRuns(i, determinismRuns, runTask); err != nil {
+				return err
+			}
+		}
+		r.Instantiations = opts.determinism
+		return nil
+	}
+	if err := r.warmUp(opts, runTask); err != nil {
+		return err
+	}
+	if opts.perf {
+		r.Perf = &PerfCounts{}
+	}
+	if opts.energy {
+		r.Energy = &EnergyUsage{}
+	}
+	return r.measure(opts.runs, nil, nil, runTask)
+}
+
+// compareNative sets NativeRatio from the baseline of r's task. The baseline
+// ran the same params, so a different hash means one of the two is wrong.
+func (r *Result) compareNative(native *Result) {
+	if r.Error != "" || native.Error != "" {
+		return
+	}
+	if r.Hash != native.Hash {
+		r.Error = fmt.Sprintf("hash %d differs from native Go's %d", r.Hash, native.Hash)
+		return
+	}
+	if native.Stats.Median != 0 {
+		r.NativeRatio = r.Stats.Median / native.Stats.Median
+	}
+}
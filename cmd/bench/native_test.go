@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"wasmbench/stats"
+)
+
+func TestRunNativeBaseline(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	// fakeTask's hash is not a real matrix product, so it cannot match native Go
+	if code := run([]string{"-native", "-warmup", "0", "-runs", "2", "-params", `{"dimension": 4}`, path}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1: %s", code, stderr.String())
+	}
+
+	decoder := json.NewDecoder(&stdout)
+	var native, result Result
+	if err := decoder.Decode(&native); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if native.Runtime != "native" || native.Task != "matrix_mul" || native.Error != "" || len(native.SamplesMs) != 2 {
+		t.Errorf("baseline %+v, expected 2 native matrix_mul runs", native)
+	}
+	if native.Params["dimension"] != "4" {
+		t.Errorf("baseline params %v, expected the modules' dimension 4", native.Params)
+	}
+	if !strings.Contains(result.Error, "differs from native") {
+		t.Errorf("error %q, expected a hash mismatch with native Go", result.Error)
+	}
+}
+
+func TestCompareNative(t *testing.T) {
+	result := Result{Hash: 7, Stats: stats.Summary{Median: 3}}
+	result.compareNative(&Result{Hash: 7, Stats: stats.Summary{Median: 2}})
+	if result.NativeRatio != 1.5 || result.Error != "" {
+		t.Errorf("ratio %v, error %q, expected 1.5", result.NativeRatio, result.Error)
+	}
+
+	failed := Result{Hash: 7, Stats: stats.Summary{Median: 3}}
+	failed.compareNative(&Result{Error: "self test failed"})
+	if failed.NativeRatio != 0 || failed.Error != "" {
+		t.Errorf("a failed baseline should leave the result alone, got ratio %v, error %q", failed.NativeRatio, failed.Error)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// overheadModule is the empty task -overhead times the runner against: one
+// page of memory, init does nothing, alloc always returns 1024, and run_task
+// returns 1 at once, so a run is only the call into the module and back
+var overheadModule = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> (), (i32) -> i32
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// Functions: init, alloc, run_task
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	// Memory: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Exports: memory, init, alloc, run_task
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	// Code
+	0x0a, 0x0f, 0x03,
+	0x02, 0x00, 0x0b, // init: nop
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b, // alloc: i32.const 1024
+	0x04, 0x00, 0x41, 0x01, 0x0b, // run_task: i32.const 1
+}
+
+// overheadBatches of overheadCalls calls each are timed per measurement,
+// after one more batch to warm up; a call is too short to time alone
+const (
+	overheadBatches = 20
+	overheadCalls   = 1000
+)
+
+// CallOverhead is what the runner spends around the task code under a
+// runtime, with -overhead, timed on overheadModule: the call of each measured
+// run, which every run_task time includes, and the writing of each task's
+// params, which happens once per module before its runs
+type CallOverhead struct {
+	Runtime string             `json:"runtime"`
+	CallNs  float64            `json:"call_ns"`  // Median of one run_task call, host to wasm and back
+	CallCV  float64            `json:"call_cv"`  // Of the batches' per-call times
+	WriteNs map[string]float64 `json:"write_ns"` // Median of writing each task's params through alloc, by task
+}
+
+// measureOverhead times the calls of the runner under runtime. It returns nil
+// for a runtime that times run_task inside itself, as chrome does, where the
+// host's calls are not in the times.
+func measureOverhead(ctx context.Context, runtime string, log io.Writer) (*CallOverhead, error) {
+	inst, err := runtimes[runtime](ctx, overheadModule, log)
+	if err != nil {
+		return nil, err
+	}
+	defer inst.close(ctx)
+	if _, remote := inst.(runTimer); remote {
+		return nil, nil
+	}
+	m := &module{instance: inst}
+	ptr, err := m.write(ctx, make([]byte, 4))
+	if err != nil {
+		return nil, err
+	}
+	o := &CallOverhead{Runtime: runtime, WriteNs: map[string]float64{}}
+	times, err := perCall(func() error {
+		_, err := m.runTask(ctx, ptr)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	o.CallNs, o.CallCV = stats.Median(times), stats.CV(times)
+	for _, task := range slices.Sorted(maps.Keys(tasks)) {
+		params := make([]byte, tasks[task].size)
+		times, err := perCall(func() error {
+			_, err := m.write(ctx, params)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		o.WriteNs[task] = stats.Median(times)
+	}
+	return o, nil
+}
+
+// perCall returns the time per call of each batch of call, in ns
+func perCall(call func() error) ([]float64, error) {
+	times := make([]float64, 0, overheadBatches)
+	for batch := range overheadBatches + 1 {
+		start := time.Now()
+		for range overheadCalls {
+			if err := call(); err != nil {
+				return nil, err
+			}
+		}
+		if batch > 0 {
+			times = append(times, float64(time.Since(start))/overheadCalls)
+		}
+	}
+	return times, nil
+}
+
+// correct reports the call overhead of each of r's runs, and its median
+// without it
+func (r *Result) correct(o *CallOverhead) {
+	r.CallOverheadMs = o.CallNs / 1e6
+	r.CorrectedMedian = max(r.Stats.Median-r.CallOverheadMs, 0)
+}
+
+// write prints the overhead on one line
+func (o *CallOverhead) write(w io.Writer) {
+	fmt.Fprintf(w, "bench: %s overhead: %.0f ns per run_task call (CV %.3f), params written in", o.Runtime, o.CallNs, o.CallCV)
+	for i, task := range slices.Sorted(maps.Keys(o.WriteNs)) {
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprintf(w, " %.0f ns (%s)", o.WriteNs[task], task)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunOverhead(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	sessionPath := filepath.Join(t.TempDir(), "session.json")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-overhead", "-warmup", "0", "-runs", "3", "-json", sessionPath, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "wazero overhead:") {
+		t.Errorf("stderr %q, expected wazero's overhead", stderr.String())
+	}
+	data, err := os.ReadFile(sessionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if len(session.Overhead) != 1 {
+		t.Fatalf("overhead %+v, expected wazero's", session.Overhead)
+	}
+	o := session.Overhead[0]
+	if o.Runtime != "wazero" || o.CallNs <= 0 || o.WriteNs["matrix_mul"] <= 0 || len(o.WriteNs) != len(tasks) {
+		t.Errorf("overhead %+v, expected a call time and a params write time per task", o)
+	}
+	r := session.Results[0]
+	if r.CallOverheadMs != o.CallNs/1e6 || r.CorrectedMedian != max(r.Stats.Median-r.CallOverheadMs, 0) {
+		t.Errorf("result overhead %g ms and corrected median %g, expected wazero's call and the median less it", r.CallOverheadMs, r.CorrectedMedian)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unsafe"
+
+	"json_parse_wasm/jsonparse"
+	"mandelbrot_wasm/mandelbrot"
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// taskSpec describes how to write one task's params struct. The Rust modules
+// read the same raw layout as the TinyGo ones, so the TinyGo field tables
+// serve both.
+type taskSpec struct {
+	category string // What the task stresses, for -category
+	fields   []common.ParamField
+	size     uintptr
+	defaults []byte             // Raw params of a run without -params
+	native   nativeTask         // Zero for a third-party task, which has none
+	limits   map[string]float64 // Highest value of params fields, by name
+	vectors  []referenceVector  // A third-party task's own, besides -verify's
+	// floatOutput is set for a task whose output is float32s, which
+	// -tolerance compares when its hash misses
+	floatOutput bool
+}
+
+// Defaults are each package's DefaultParams, which configs/tasks.json carries
+// to the other harnesses. -tasks adds third-party tasks.
+var tasks = map[string]taskSpec{
+	"mandelbrot": {
+		category: "compute",
+		fields:   mandelbrot.ParamFields(),
+		size:     unsafe.Sizeof(mandelbrot.MandelbrotParams{}),
+		defaults: raw(&mandelbrot.DefaultParams),
+		native:   nativeTask{mandelbrot.Init, mandelbrot.SelfTest, mandelbrot.RunTaskV2},
+	},
+	"matrix_mul": {
+		category: "memory",
+		fields:   matrixmul.ParamFields(),
+		size:     unsafe.Sizeof(matrixmul.MatrixMulParams{}),
+		defaults: raw(&matrixmul.DefaultParams),
+		native:   nativeTask{matrixmul.Init, matrixmul.SelfTest, matrixmul.RunTaskV2},
+
+		floatOutput: true,
+	},
+	"json_parse": {
+		category: "allocation",
+		fields:   jsonparse.ParamFields(),
+		size:     unsafe.Sizeof(jsonparse.JsonParseParams{}),
+		defaults: raw(&jsonparse.DefaultParams),
+		native:   nativeTask{jsonparse.Init, jsonparse.SelfTest, jsonparse.RunTaskV2},
+	},
+}
+
+// buildParams returns the raw params struct of spec with its defaults
+// overridden by the JSON object overrides ("" keeps the defaults). Fields are
+// stored in host byte order, which matches wasm's little-endian memory on the
+// hosts wazero runs on. Params over the spec's limits are rejected.
+func buildParams(spec taskSpec, overrides string) ([]byte, error) {
+	// Backed by uint64s so the f64 and u64 fields are aligned
+	words := make([]uint64, (spec.size+7)/8)
+	dst := unsafe.Pointer(&words[0])
+	params := unsafe.Slice((*byte)(dst), spec.size)
+	copy(params, spec.defaults)
+	if overrides != "" {
+		if status, message := cli.ParamsFromJSON([]byte(overrides), spec.fields, dst); status != common.StatusOK {
+			return nil, errors.New(message)
+		}
+	}
+	if len(spec.limits) > 0 {
+		values := paramValues(spec, params)
+		for _, field := range spec.fields {
+			limit, ok := spec.limits[field.Name]
+			if value, _ := values[field.Name].Float64(); ok && value > limit {
+				return nil, fmt.Errorf("params field %s is %s, over the task's limit of %g", field.Name, values[field.Name], limit)
+			}
+		}
+	}
+	return params, nil
+}
+
+// raw returns the bytes of the params struct p
+func raw[T any](p *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(p)), unsafe.Sizeof(*p))
+}
+
+// paramValues decodes a raw params struct back into its named fields, the
+// form results report the params in
+func paramValues(spec taskSpec, params []byte) map[string]json.Number {
+	values := make(map[string]json.Number, len(spec.fields))
+	for _, field := range spec.fields {
+		p := unsafe.Pointer(&params[field.Offset])
+		switch field.Type {
+		case common.FieldF64:
+			values[field.Name] = json.Number(strconv.FormatFloat(*(*float64)(p), 'g', -1, 64))
+		case common.FieldU64:
+			values[field.Name] = json.Number(strconv.FormatUint(*(*uint64)(p), 10))
+		default:
+			values[field.Name] = json.Number(strconv.FormatUint(uint64(*(*uint32)(p)), 10))
+		}
+	}
+	return values
+}
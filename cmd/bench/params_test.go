@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildParams(t *testing.T) {
+	spec := tasks["mandelbrot"]
+	params, err := buildParams(spec, `{"width": 8, "scale_factor": 0.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uintptr(len(params)) != spec.size {
+		t.Fatalf("params are %d bytes, expected %d", len(params), spec.size)
+	}
+
+	values := paramValues(spec, params)
+	expected := map[string]json.Number{"width": "8", "height": "64", "max_iter": "100", "scale_factor": "0.5", "center_real": "-0.743643887037"}
+	for name, want := range expected {
+		if values[name] != want {
+			t.Errorf("%s = %s, expected %s", name, values[name], want)
+		}
+	}
+
+	if _, err := buildParams(spec, `{"dimension": 8}`); err == nil {
+		t.Error("a field of another task should be rejected")
+	}
+	if _, err := buildParams(tasks["matrix_mul"], `{"dimension": -1}`); err == nil {
+		t.Error("a negative u32 should be rejected")
+	}
+}
+
+func TestTaskDefaultsAreValid(t *testing.T) {
+	for name, spec := range tasks {
+		if _, err := buildParams(spec, ""); err != nil {
+			t.Errorf("%s defaults: %v", name, err)
+		}
+	}
+}
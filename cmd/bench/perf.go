@@ -0,0 +1,37 @@
+package main
+
+// PerfCounts are hardware counters of each measured run, with -perf: the
+// user-space events of the benchmark's thread from the start of run_task to
+// its return, read through perf_event_open. Unlike wall time, instructions
+// do not vary with host load, and cycles per instruction, branch misses and
+// cache misses tell a runtime's slow code from its slow memory.
+type PerfCounts struct {
+	Instructions []uint64 `json:"instructions"`
+	Cycles       []uint64 `json:"cycles"`
+	BranchMisses []uint64 `json:"branch_misses"`
+	CacheMisses  []uint64 `json:"cache_misses"`
+	IPC          float64  `json:"ipc"`              // Instructions per cycle over every measured run
+	Scaled       bool     `json:"scaled,omitempty"` // The kernel multiplexed the counters, so some runs' counts are estimates
+}
+
+// perfSample is one run's counts, in perfEvents order
+type perfSample struct {
+	counts [4]uint64
+	scaled bool // Counted for only part of the run, and scaled up to all of it
+}
+
+// record adds a run's counts
+func (p *PerfCounts) record(s perfSample) {
+	p.Instructions = append(p.Instructions, s.counts[0])
+	p.Cycles = append(p.Cycles, s.counts[1])
+	p.BranchMisses = append(p.BranchMisses, s.counts[2])
+	p.CacheMisses = append(p.CacheMisses, s.counts[3])
+	p.Scaled = p.Scaled || s.scaled
+	var instructions, cycles uint64
+	for i := range p.Cycles {
+		instructions, cycles = instructions+p.Instructions[i], cycles+p.Cycles[i]
+	}
+	if cycles > 0 {
+		p.IPC = float64(instructions) / float64(cycles)
+	}
+}
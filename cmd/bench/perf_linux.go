@@ -0,0 +1,157 @@
+//go:build linux
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// perfEventAttr is struct perf_event_attr up to sample_max_stack
+// (PERF_ATTR_SIZE_VER5)
+type perfEventAttr struct {
+	typ              uint32
+	size             uint32
+	config           uint64
+	samplePeriod     uint64
+	sampleType       uint64
+	readFormat       uint64
+	flags            uint64 // The bitfield from disabled on
+	wakeupEvents     uint32
+	bpType           uint32
+	config1          uint64
+	config2          uint64
+	branchSampleType uint64
+	sampleRegsUser   uint64
+	sampleStackUser  uint32
+	clockID          int32
+	sampleRegsIntr   uint64
+	auxWatermark     uint32
+	sampleMaxStack   uint16
+	_                uint16
+}
+
+// From linux/perf_event.h
+const (
+	perfTypeHardware = 0
+
+	perfCountHWCPUCycles    = 0
+	perfCountHWInstructions = 1
+	perfCountHWCacheMisses  = 3
+	perfCountHWBranchMisses = 5
+
+	perfFormatTotalTimeEnabled = 1 << 0
+	perfFormatTotalTimeRunning = 1 << 1
+	perfFormatGroup            = 1 << 3
+
+	perfAttrDisabled      = 1 << 0
+	perfAttrExcludeKernel = 1 << 5
+	perfAttrExcludeHV     = 1 << 6
+
+	perfFlagFDCloexec = 1 << 3
+
+	perfEventIOCEnable  = 0x2400
+	perfEventIOCDisable = 0x2401
+	perfEventIOCReset   = 0x2403
+	perfIOCFlagGroup    = 1
+)
+
+// perfEvents are the counted events in PerfCounts order; the first leads
+// the group
+var perfEvents = [...]uint64{perfCountHWInstructions, perfCountHWCPUCycles, perfCountHWBranchMisses, perfCountHWCacheMisses}
+
+// perfGroup counts perfEvents on the calling thread, which it locks to the
+// goroutine until close, as one group so every event covers the same span
+type perfGroup struct {
+	fds [len(perfEvents)]int
+}
+
+// openPerf opens the counters, stopped. Counting user space only keeps them
+// within reach of unprivileged processes at the default perf_event_paranoid
+// of 2.
+func openPerf() (*perfGroup, error) {
+	runtime.LockOSThread()
+	g := &perfGroup{}
+	for i := range g.fds {
+		g.fds[i] = -1
+	}
+	for i, event := range perfEvents {
+		attr := perfEventAttr{
+			typ:        perfTypeHardware,
+			config:     event,
+			readFormat: perfFormatGroup | perfFormatTotalTimeEnabled | perfFormatTotalTimeRunning,
+			flags:      perfAttrDisabled | perfAttrExcludeKernel | perfAttrExcludeHV,
+		}
+		attr.size = uint32(unsafe.Sizeof(attr))
+		leader := -1
+		if i > 0 {
+			leader = g.fds[0]
+		}
+		// This thread, on any CPU
+		fd, _, errno := syscall.Syscall6(syscall.SYS_PERF_EVENT_OPEN, uintptr(unsafe.Pointer(&attr)), 0, ^uintptr(0), uintptr(leader), perfFlagFDCloexec, 0)
+		if errno != 0 {
+			g.close()
+			if errors.Is(errno, syscall.EACCES) || errors.Is(errno, syscall.EPERM) {
+				return nil, fmt.Errorf("perf_event_open: %w; lower /proc/sys/kernel/perf_event_paranoid to 2 or below", errno)
+			}
+			return nil, fmt.Errorf("perf_event_open: %w; the CPU or virtual machine may not expose hardware counters", errno)
+		}
+		g.fds[i] = int(fd)
+	}
+	return g, nil
+}
+
+// start zeroes the counters and starts them
+func (g *perfGroup) start() error {
+	for _, request := range []uintptr{perfEventIOCReset, perfEventIOCEnable} {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(g.fds[0]), request, perfIOCFlagGroup); errno != 0 {
+			return fmt.Errorf("perf ioctl: %w", errno)
+		}
+	}
+	return nil
+}
+
+// stop stops the counters and reads them. A group the kernel multiplexed
+// with other events ran for only part of the time it was enabled, so its
+// counts are scaled up to the whole span.
+func (g *perfGroup) stop() (perfSample, error) {
+	var s perfSample
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(g.fds[0]), perfEventIOCDisable, perfIOCFlagGroup); errno != 0 {
+		return s, fmt.Errorf("perf ioctl: %w", errno)
+	}
+	// nr, time_enabled, time_running, then each event's value
+	var buf [8 * (3 + len(perfEvents))]byte
+	n, err := syscall.Read(g.fds[0], buf[:])
+	if err != nil {
+		return s, fmt.Errorf("reading the perf counters: %w", err)
+	}
+	if n != len(buf) || binary.NativeEndian.Uint64(buf[:]) != uint64(len(perfEvents)) {
+		return s, fmt.Errorf("reading the perf counters: %d bytes, expected %d", n, len(buf))
+	}
+	enabled, running := binary.NativeEndian.Uint64(buf[8:]), binary.NativeEndian.Uint64(buf[16:])
+	if running == 0 && enabled > 0 {
+		return s, errors.New("the perf counters never got a hardware counter to run on")
+	}
+	for i := range s.counts {
+		s.counts[i] = binary.NativeEndian.Uint64(buf[24+8*i:])
+		if running < enabled {
+			s.counts[i] = uint64(float64(s.counts[i]) * float64(enabled) / float64(running))
+		}
+	}
+	s.scaled = running < enabled
+	return s, nil
+}
+
+// close releases the counters and unlocks the thread
+func (g *perfGroup) close() {
+	for i := len(g.fds) - 1; i >= 0; i-- {
+		if g.fds[i] >= 0 {
+			syscall.Close(g.fds[i])
+		}
+	}
+	runtime.UnlockOSThread()
+}
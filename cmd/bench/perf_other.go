@@ -0,0 +1,16 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// perfGroup counts hardware events, which is only implemented on Linux
+type perfGroup struct{}
+
+func openPerf() (*perfGroup, error) {
+	return nil, errors.New("hardware counters need Linux's perf_event_open")
+}
+
+func (g *perfGroup) start() error              { return nil }
+func (g *perfGroup) stop() (perfSample, error) { return perfSample{}, nil }
+func (g *perfGroup) close()                    {}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestPerfCountsRecord(t *testing.T) {
+	var p PerfCounts
+	p.record(perfSample{counts: [4]uint64{300, 100, 2, 5}})
+	p.record(perfSample{counts: [4]uint64{500, 300, 4, 1}, scaled: true})
+	if len(p.Instructions) != 2 || p.Cycles[1] != 300 || p.BranchMisses[0] != 2 || p.CacheMisses[1] != 1 {
+		t.Errorf("counts %+v, expected both runs'", p)
+	}
+	// Instructions per cycle of all the runs together, not a mean of ratios
+	if p.IPC != 2 || !p.Scaled {
+		t.Errorf("ipc %g, scaled %v, expected 2 and scaled", p.IPC, p.Scaled)
+	}
+}
+
+func TestRunPerf(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	args := []string{"-perf", "-warmup", "0", "-runs", "3", path}
+	counters, err := openPerf()
+	if err != nil {
+		// The counters are not there to count, so -perf refuses to start
+		if code := run(args, &stdout, &stderr); code != 1 {
+			t.Errorf("exit status %d without hardware counters, expected 1", code)
+		}
+		t.Skip("no hardware counters:", err)
+	}
+	counters.close()
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		p := result.Perf
+		if p == nil || len(p.Instructions) != 3 || len(p.CacheMisses) != 3 || p.Instructions[0] == 0 || p.IPC <= 0 {
+			t.Errorf("%s counts %+v, expected 3 runs'", result.Module, p)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package main
+
+// threadPin is where pinThread bound the measuring thread, with -strict
+type threadPin struct {
+	cpu      int  // -1 where binding is not implemented
+	isolated bool // cpu is one of the kernel's isolated CPUs
+}
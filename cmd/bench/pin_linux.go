@@ -0,0 +1,174 @@
+//go:build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// isolatedCPUs lists the CPUs the kernel keeps the scheduler off, from the
+// isolcpus boot parameter
+const isolatedCPUs = "/sys/devices/system/cpu/isolated"
+
+// pinThread locks the calling goroutine to its OS thread and binds the thread
+// to a single CPU: cpu, or with cpu -1 the highest isolated CPU, or else the
+// highest one the process may use, since CPU 0 usually takes the most
+// interrupts. A nice other than 0 becomes the thread's niceness, and below 0
+// raises its priority, which takes CAP_SYS_NICE. Processes the thread starts,
+// a Node or Chrome engine, inherit both. unpin gives the thread back its CPUs
+// and niceness and unlocks it.
+func pinThread(cpu, nice int) (pin threadPin, unpin func(), err error) {
+	runtime.LockOSThread()
+	fail := func(err error) (threadPin, func(), error) {
+		runtime.UnlockOSThread()
+		return threadPin{cpu: -1}, nil, err
+	}
+	var allowed cpuSet
+	if err := allowed.get(); err != nil {
+		return fail(err)
+	}
+	isolated, err := readIsolatedCPUs()
+	if err != nil {
+		return fail(err)
+	}
+
+	pin = threadPin{cpu: cpu}
+	switch {
+	case cpu >= len(allowed)*64:
+		return fail(fmt.Errorf("CPU %d is past the %d CPUs sched_setaffinity takes here", cpu, len(allowed)*64))
+	case cpu >= 0:
+		err = pinCPU(cpu)
+	case len(isolated) > 0:
+		// A cpuset can still keep the process off the isolated CPUs
+		pin.cpu = isolated[len(isolated)-1]
+		if err = pinCPU(pin.cpu); err != nil {
+			pin.cpu, err = allowed.last(), pinCPU(allowed.last())
+		}
+	default:
+		pin.cpu, err = allowed.last(), pinCPU(allowed.last())
+	}
+	if err != nil {
+		return fail(fmt.Errorf("CPU %d: %w", pin.cpu, err))
+	}
+	for _, c := range isolated {
+		pin.isolated = pin.isolated || c == pin.cpu
+	}
+
+	tid := syscall.Gettid()
+	// getpriority returns 20 - nice, so it never looks like an error
+	previous, err := syscall.Getpriority(syscall.PRIO_PROCESS, tid)
+	if err != nil {
+		allowed.set()
+		return fail(fmt.Errorf("getpriority: %w", err))
+	}
+	previous = 20 - previous
+	if nice != 0 {
+		if err := syscall.Setpriority(syscall.PRIO_PROCESS, tid, nice); err != nil {
+			allowed.set()
+			if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) {
+				err = fmt.Errorf("%w; a negative nice needs root or CAP_SYS_NICE", err)
+			}
+			return fail(fmt.Errorf("setpriority %d: %w", nice, err))
+		}
+	}
+	return pin, func() {
+		// A thread left pinned or reniced stays locked, and exits with the
+		// goroutine rather than going back to the scheduler. Lowering the
+		// niceness back may itself need CAP_SYS_NICE.
+		restored := allowed.set() == nil
+		if nice != 0 {
+			restored = restored && syscall.Setpriority(syscall.PRIO_PROCESS, tid, previous) == nil
+		}
+		if restored {
+			runtime.UnlockOSThread()
+		}
+	}, nil
+}
+
+// pinCPU binds the calling thread to cpu alone
+func pinCPU(cpu int) error {
+	var pinned cpuSet
+	pinned[cpu/64] = 1 << (cpu % 64)
+	return pinned.set()
+}
+
+// readIsolatedCPUs lists the kernel's isolated CPUs in increasing order, none
+// on a kernel without the file
+func readIsolatedCPUs() ([]int, error) {
+	data, err := os.ReadFile(isolatedCPUs)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cpus, err := parseCPUList(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", isolatedCPUs, err)
+	}
+	return cpus, nil
+}
+
+// parseCPUList parses a kernel CPU list, such as 2-3,6, in the order given
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	if list == "" {
+		return cpus, nil
+	}
+	for part := range strings.SplitSeq(list, ",") {
+		low, high, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(low)
+		if err != nil {
+			return nil, fmt.Errorf("CPU list %q: %w", list, err)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(high); err != nil {
+				return nil, fmt.Errorf("CPU list %q: %w", list, err)
+			}
+		}
+		if first < 0 || last < first {
+			return nil, fmt.Errorf("CPU list %q: range %s is empty", list, part)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// cpuSet is a cpu_set_t of the calling thread's affinity, up to 1024 CPUs
+type cpuSet [16]uint64
+
+func (s *cpuSet) get() error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(*s), uintptr(unsafe.Pointer(s)))
+	if errno != 0 {
+		return fmt.Errorf("sched_getaffinity: %w", errno)
+	}
+	return nil
+}
+
+func (s *cpuSet) set() error {
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(*s), uintptr(unsafe.Pointer(s)))
+	if errno != 0 {
+		return fmt.Errorf("sched_setaffinity: %w", errno)
+	}
+	return nil
+}
+
+// last returns the highest CPU in the set, 0 if it is empty
+func (s *cpuSet) last() int {
+	for i := len(s)*64 - 1; i >= 0; i-- {
+		if s[i/64]&(1<<(i%64)) != 0 {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"math/bits"
+	"os"
+	"runtime"
+	"slices"
+	"syscall"
+	"testing"
+)
+
+func TestPinThread(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var before cpuSet
+	if err := before.get(); err != nil {
+		t.Fatal(err)
+	}
+	isolated, err := readIsolatedCPUs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pin, unpin, err := pinThread(-1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pinned cpuSet
+	if err := pinned.get(); err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, word := range pinned {
+		count += bits.OnesCount64(word)
+	}
+	cpu := pin.cpu
+	if count != 1 || pinned[cpu/64]&(1<<(cpu%64)) == 0 || (cpu != before.last() && !pin.isolated) {
+		t.Errorf("pinned to %v on CPU %d, expected only an isolated CPU or the last allowed CPU %d", pinned, cpu, before.last())
+	}
+	if pin.isolated != slices.Contains(isolated, cpu) {
+		t.Errorf("CPU %d isolated %v, expected it among %v", cpu, pin.isolated, isolated)
+	}
+
+	unpin()
+	var after cpuSet
+	if err := after.get(); err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("affinity %v after unpinning, expected %v", after, before)
+	}
+}
+
+func TestPinThreadNice(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("restoring the niceness afterwards needs root")
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	var before cpuSet
+	if err := before.get(); err != nil {
+		t.Fatal(err)
+	}
+	first := 0
+	for first < before.last() && before[first/64]&(1<<(first%64)) == 0 {
+		first++
+	}
+
+	pin, unpin, err := pinThread(first, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priority, err := syscall.Getpriority(syscall.PRIO_PROCESS, syscall.Gettid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin.cpu != first || 20-priority != 5 {
+		t.Errorf("CPU %d at nice %d, expected CPU %d at 5", pin.cpu, 20-priority, first)
+	}
+	unpin()
+	if priority, err = syscall.Getpriority(syscall.PRIO_PROCESS, syscall.Gettid()); err != nil || 20-priority != 0 {
+		t.Errorf("nice %d after unpinning (%v), expected 0", 20-priority, err)
+	}
+
+	if _, _, err := pinThread(4096, 0); err == nil {
+		t.Error("pinned to CPU 4096")
+	}
+}
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("2-3,6,8-8")
+	if err != nil || !slices.Equal(cpus, []int{2, 3, 6, 8}) {
+		t.Errorf("CPUs %v (%v), expected 2, 3, 6 and 8", cpus, err)
+	}
+	if cpus, err := parseCPUList(""); err != nil || len(cpus) != 0 {
+		t.Errorf("CPUs %v (%v) of an empty list", cpus, err)
+	}
+	for _, list := range []string{"3-1", "a", "1-b", "-1"} {
+		if _, err := parseCPUList(list); err == nil {
+			t.Errorf("%q parsed", list)
+		}
+	}
+}
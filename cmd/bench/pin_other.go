@@ -0,0 +1,19 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"runtime"
+)
+
+// pinThread locks the calling goroutine to its OS thread. Binding the thread
+// to a CPU and changing its niceness are only implemented on Linux, so the
+// pin's cpu is -1, and a cpu or nice asked for fails.
+func pinThread(cpu, nice int) (pin threadPin, unpin func(), err error) {
+	if cpu >= 0 || nice != 0 {
+		return threadPin{cpu: -1}, nil, errors.New("-cpu and -nice need Linux's sched_setaffinity and setpriority")
+	}
+	runtime.LockOSThread()
+	return threadPin{cpu: -1}, runtime.UnlockOSThread, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"wasmbench/bench/internal/config"
+)
+
+// pass is a set of modules benchmarked with the same options: the whole run
+// without -plan, and each step of the plan with it
+type pass struct {
+	opts    options
+	modules []string
+}
+
+// planPasses loads the plan at path and returns a pass per step, over the
+// modules whose file name names the step's task. The plan's run counts
+// and timeout replace opts' unless the flags named in set were given, and its
+// native setting adds to -native.
+func planPasses(path string, modules []string, opts options, set map[string]bool) ([]pass, error) {
+	plan, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := plan.Steps()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	env := plan.Environment
+	if env.WarmupRuns > 0 && !set["warmup"] {
+		opts.warmupRuns = env.WarmupRuns
+	}
+	if env.WarmupCV > 0 && !set["warmup-cv"] {
+		opts.warmupCV = env.WarmupCV
+	}
+	if env.MaxWarmupRuns > 0 && !set["max-warmup"] {
+		opts.maxWarmupRuns = env.MaxWarmupRuns
+	}
+	if env.MeasureRuns > 0 && !set["runs"] {
+		opts.runs = env.MeasureRuns
+	}
+	if env.Timeout > 0 && !set["timeout"] {
+		opts.timeout = time.Duration(env.Timeout) * time.Second
+	}
+	opts.native = opts.native || plan.Runner.Native
+
+	var passes []pass
+	for _, step := range steps {
+		if _, ok := runtimes[step.Runtime]; !ok {
+			return nil, fmt.Errorf("%s: unknown runtime %q (wasmtime needs -tags wasmtime, chrome -tags chromedp)", path, step.Runtime)
+		}
+		if _, ok := tasks[step.Task]; !ok {
+			return nil, fmt.Errorf("%s: unknown task %q", path, step.Task)
+		}
+		p := pass{opts: opts}
+		p.opts.task, p.opts.params, p.opts.runtime = step.Task, step.Params, step.Runtime
+		p.opts.scale, p.opts.repetition = step.Scale, step.Repetition
+		for _, module := range modules {
+			if taskFromFileName(module) == step.Task {
+				p.modules = append(p.modules, module)
+			}
+		}
+		passes = append(passes, p)
+	}
+	return passes, nil
+}
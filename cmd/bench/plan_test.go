@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"wasmbench/bench/internal/config"
+)
+
+func TestRunPlan(t *testing.T) {
+	module := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	other := writeModule(t, "mandelbrot-o2.wasm", fakeTask)
+	plan := filepath.Join(t.TempDir(), "plan.yaml")
+	err := os.WriteFile(plan, []byte(`
+environment: {warmup_runs: 0, measure_runs: 3, repetitions: 2}
+tasks:
+  matrix_mul:
+    scales:
+      small: {dimension: 4}
+      large: {dimension: 6}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-plan", plan, "-warmup", "0", module, other}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var got []string
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		dimension, _ := result.Params["dimension"].Int64()
+		if len(result.SamplesMs) != 3 || result.Hash != 3*uint32(dimension) {
+			t.Errorf("result %+v, expected the plan's 3 runs and its dimension", result)
+		}
+		got = append(got, filepath.Base(result.Module)+" "+result.Scale+" "+string(result.Params["dimension"])+" "+strconv.Itoa(result.Repetition))
+	}
+	expected := "matrix_mul-o2.wasm small 4 1,matrix_mul-o2.wasm large 6 1,matrix_mul-o2.wasm small 4 2,matrix_mul-o2.wasm large 6 2"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("results %v, expected %s", got, expected)
+	}
+
+	if code := run([]string{"-plan", plan, "-params", `{"dimension": 8}`, module}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -plan and -params, expected 2", code)
+	}
+}
+
+// Every scale of the repo's plans is valid params of its task
+func TestRepoPlanParams(t *testing.T) {
+	for _, name := range []string{"bench.yaml", "bench-quick.yaml"} {
+		plan, err := config.Load(filepath.Join("..", "..", "configs", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		steps, err := plan.Steps()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, step := range steps {
+			if _, err := buildParams(tasks[step.Task], step.Params); err != nil {
+				t.Errorf("%s: %s %s: %v", name, step.Task, step.Scale, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"wasmbench/common"
+)
+
+// pluginManifestName is the manifest of a third-party task directory
+const pluginManifestName = "task.json"
+
+// pluginManifest is the task.json of a third-party task: a task of its own,
+// benchmarked like the built-in ones, described as configs/tasks.json
+// describes those. Its modules sit beside it or in a directory per language.
+type pluginManifest struct {
+	Task       string                 `json:"task"`
+	Category   string                 `json:"category"`
+	ParamsSize uintptr                `json:"params_size"` // Of the params struct, 0 for just past the last field
+	Params     []pluginParam          `json:"params"`
+	Defaults   map[string]json.Number `json:"defaults"`
+	// Highest value of a params field, as max_<field>
+	Limits           map[string]float64 `json:"limits"`
+	ReferenceVectors []referenceVector  `json:"reference_vectors"`
+}
+
+// pluginParam is a field of the params struct
+type pluginParam struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"` // u32, u64 or f64
+	Offset uintptr `json:"offset"`
+	Doc    string  `json:"doc"`
+}
+
+// pluginTaskName is what a task may be called: the part of a module's file
+// name before the first "-", which names its task
+var pluginTaskName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// loadPlugin reads the manifest of the task directory dir, adds its task to
+// tasks, and returns the modules in dir and its subdirectories, WASI command
+// builds left out
+func loadPlugin(dir string) ([]string, error) {
+	path := filepath.Join(dir, pluginManifestName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest pluginManifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	spec, err := manifest.spec()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, ok := tasks[manifest.Task]; ok {
+		return nil, fmt.Errorf("%s: task %s is already defined", path, manifest.Task)
+	}
+	tasks[manifest.Task] = spec
+
+	var modules []string
+	for _, pattern := range []string{"*.wasm", filepath.Join("*", "*.wasm")} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, module := range matches {
+			if !strings.HasSuffix(module, "-wasi.wasm") {
+				modules = append(modules, module)
+			}
+		}
+	}
+	return modules, nil
+}
+
+// spec checks the manifest and returns its task's spec
+func (m pluginManifest) spec() (taskSpec, error) {
+	if !pluginTaskName.MatchString(m.Task) {
+		return taskSpec{}, fmt.Errorf("task %q must be lower case letters, digits and underscores", m.Task)
+	}
+	if len(m.Params) == 0 {
+		return taskSpec{}, fmt.Errorf("task %s has no params", m.Task)
+	}
+	spec := taskSpec{category: m.Category, size: m.ParamsSize, limits: map[string]float64{}}
+	params := slices.SortedFunc(slices.Values(m.Params), func(a, b pluginParam) int { return cmp.Compare(a.Offset, b.Offset) })
+	var end uintptr
+	for _, p := range params {
+		size := uintptr(4)
+		switch p.Type {
+		case common.FieldU32:
+		case common.FieldU64, common.FieldF64:
+			size = 8
+		default:
+			return taskSpec{}, fmt.Errorf("params field %s has type %q, not u32, u64 or f64", p.Name, p.Type)
+		}
+		if p.Name == "" || p.Offset%size != 0 || p.Offset < end {
+			return taskSpec{}, fmt.Errorf("params field %q at offset %d is unnamed, unaligned or overlaps the one before", p.Name, p.Offset)
+		}
+		if slices.ContainsFunc(spec.fields, func(f common.ParamField) bool { return f.Name == p.Name }) {
+			return taskSpec{}, fmt.Errorf("params field %s is defined twice", p.Name)
+		}
+		end = p.Offset + size
+		spec.fields = append(spec.fields, common.ParamField{Name: p.Name, Type: p.Type, Offset: p.Offset})
+	}
+	if spec.size == 0 {
+		spec.size = end
+	} else if spec.size < end {
+		return taskSpec{}, fmt.Errorf("params_size %d is short of the fields' %d bytes", spec.size, end)
+	}
+
+	for name, limit := range m.Limits {
+		field, ok := strings.CutPrefix(name, "max_")
+		if !ok || !slices.ContainsFunc(spec.fields, func(f common.ParamField) bool { return f.Name == field }) {
+			return taskSpec{}, fmt.Errorf("limit %s is not max_<params field>", name)
+		}
+		spec.limits[field] = limit
+	}
+	spec.defaults = make([]byte, spec.size)
+	if len(m.Defaults) > 0 {
+		defaults, err := json.Marshal(m.Defaults)
+		if err != nil {
+			return taskSpec{}, err
+		}
+		if spec.defaults, err = buildParams(spec, string(defaults)); err != nil {
+			return taskSpec{}, fmt.Errorf("defaults: %w", err)
+		}
+	}
+	for _, v := range m.ReferenceVectors {
+		if _, err := buildParams(spec, string(v.Params)); err != nil && v.ExpectedStatus == 0 {
+			return taskSpec{}, fmt.Errorf("reference vector %s: %w", v.Name, err)
+		}
+	}
+	spec.vectors = m.ReferenceVectors
+	return spec, nil
+}
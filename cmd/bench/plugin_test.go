@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tripleManifest describes fakeTask as a third-party task: its hash is 3
+// times count, the first u32 of its params
+const tripleManifest = `{
+  "task": "triple",
+  "category": "compute",
+  "params": [
+    {"name": "count", "type": "u32", "offset": 0, "doc": "Number to triple"},
+    {"name": "seed", "type": "u64", "offset": 8}
+  ],
+  "defaults": {"count": 7},
+  "limits": {"max_count": 100},
+  "reference_vectors": [
+    {"name": "ten", "params": {"count": 10}, "expected_hash": 30},
+    {"name": "default", "params": {}, "expected_hash": 21}
+  ]
+}`
+
+// writePlugin writes a task directory of manifest with fakeTask as its
+// module, and removes the task when the test ends
+func writePlugin(t *testing.T, manifest string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, pluginManifestName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "tinygo"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tinygo", "triple-o2.wasm"), fakeTask, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { delete(tasks, "triple") })
+	return dir
+}
+
+func TestRunPlugin(t *testing.T) {
+	dir := writePlugin(t, tripleManifest)
+	var stdout, stderr bytes.Buffer
+	args := []string{"-tasks", dir, "-builds", t.TempDir(), "-verify", t.TempDir(), "-language", "tinygo", "-warmup", "0", "-runs", "2"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Task != "triple" || result.Hash != 21 || result.Params["count"] != "7" || result.Params["seed"] != "0" {
+		t.Errorf("task %s hash %d params %v, expected triple's defaults hashed to 21", result.Task, result.Hash, result.Params)
+	}
+	if v := result.Verification; v == nil || v.Vector != "default" || v.Mismatches != 0 {
+		t.Errorf("verification %+v, expected the manifest's default vector", v)
+	}
+
+	// The limits reject params, and there is no native triple
+	delete(tasks, "triple")
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"-tasks", dir, "-warmup", "0", "-runs", "1", "-params", `{"count": 101}`, filepath.Join(dir, "tinygo", "triple-o2.wasm")}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d with count over its limit, expected 1", code)
+	}
+	if !strings.Contains(stderr.String(), "over the task's limit of 100") {
+		t.Errorf("stderr %q, expected the limit", stderr.String())
+	}
+	delete(tasks, "triple")
+	stdout.Reset()
+	if code := run([]string{"-tasks", dir, "-native", "-warmup", "0", "-runs", "1", filepath.Join(dir, "tinygo", "triple-o2.wasm")}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d with -native, expected 1", code)
+	}
+	if !strings.Contains(stdout.String(), "no native implementation") {
+		t.Errorf("output %s, expected a native baseline without an implementation", stdout.String())
+	}
+}
+
+func TestLoadPluginInvalid(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"built-in task":    `{"task": "matrix_mul", "params": [{"name": "n", "type": "u32", "offset": 0}]}`,
+		"dash in the name": `{"task": "my-task", "params": [{"name": "n", "type": "u32", "offset": 0}]}`,
+		"unaligned field":  `{"task": "triple", "params": [{"name": "n", "type": "u64", "offset": 4}]}`,
+		"overlap":          `{"task": "triple", "params": [{"name": "n", "type": "u64", "offset": 0}, {"name": "m", "type": "u32", "offset": 4}]}`,
+		"short size":       `{"task": "triple", "params_size": 4, "params": [{"name": "n", "type": "f64", "offset": 0}]}`,
+		"unknown type":     `{"task": "triple", "params": [{"name": "n", "type": "i8", "offset": 0}]}`,
+		"unknown limit":    `{"task": "triple", "params": [{"name": "n", "type": "u32", "offset": 0}], "limits": {"max_m": 1}}`,
+		"default too high": `{"task": "triple", "params": [{"name": "n", "type": "u32", "offset": 0}], "defaults": {"n": 5}, "limits": {"max_n": 1}}`,
+		"unknown field":    `{"task": "triple", "params": [{"name": "n", "type": "u32", "offset": 0}], "scales": {}}`,
+	} {
+		if _, err := loadPlugin(writePlugin(t, manifest)); err == nil {
+			t.Errorf("%s: loaded, expected an error", name)
+		}
+		delete(tasks, "triple")
+	}
+}
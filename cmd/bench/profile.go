@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+)
+
+// profilePasses returns the passes -profile runs, without modules: the -task
+// alone, or with a plan each of its tasks once per scale
+func profilePasses(planPath string, opts options, set map[string]bool) ([]pass, error) {
+	if planPath == "" {
+		if opts.task == "" {
+			return nil, errors.New("-profile needs -task or -plan")
+		}
+		return []pass{{opts: opts}}, nil
+	}
+	steps, err := planPasses(planPath, nil, opts, set)
+	if err != nil {
+		return nil, err
+	}
+	var passes []pass
+	seen := map[string]bool{}
+	for _, p := range steps {
+		key := p.opts.task + "-" + p.opts.scale
+		if !seen[key] {
+			seen[key] = true
+			passes = append(passes, p)
+		}
+	}
+	return passes, nil
+}
+
+// profileNative runs task natively as benchNative does, under the CPU
+// profiler, and writes <task>-<scale>.cpu.pprof and <task>-<scale>.heap.pprof
+// (<task>.*.pprof without -plan) to dir, so a hotspot shows up in the task's
+// own code before any runtime is blamed for it. The heap profile is taken
+// after a collection at the end; its allocation counts are the process's
+// since it started, so a tier's own are its difference from the tier profiled
+// before it (go tool pprof -base).
+func profileNative(ctx context.Context, task string, opts options, dir string) Result {
+	name := task
+	if opts.scale != "" {
+		name += "-" + opts.scale
+	}
+	cpuPath := filepath.Join(dir, name+".cpu.pprof")
+	heapPath := filepath.Join(dir, name+".heap.pprof")
+
+	failed := func(err error) Result {
+		return Result{Module: "native", Runtime: "native", Task: task, Language: "go", Scale: opts.scale,
+			SamplesMs: []float64{}, Error: fmt.Sprintf("profile: %v", err)}
+	}
+	cpu, err := os.Create(cpuPath)
+	if err != nil {
+		return failed(err)
+	}
+	defer cpu.Close()
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		return failed(err)
+	}
+	result := benchNative(ctx, task, opts)
+	pprof.StopCPUProfile()
+
+	runtime.GC()
+	err = writeFile(heapPath, func(w io.Writer) error { return pprof.Lookup("heap").WriteTo(w, 0) })
+	if err == nil {
+		err = cpu.Close()
+	}
+	if err != nil && result.Error == "" {
+		result.Error = fmt.Sprintf("profile: %v", err)
+	}
+	result.Profiles = []string{cpuPath, heapPath}
+	return result
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRunProfile(t *testing.T) {
+	dir := t.TempDir()
+	plan := filepath.Join(dir, "plan.yaml")
+	err := os.WriteFile(plan, []byte(`
+environment: {warmup_runs: 0, measure_runs: 2, repetitions: 2}
+tasks:
+  matrix_mul:
+    scales:
+      small: {dimension: 4}
+      large: {dimension: 6}
+  json_parse:
+    scales:
+      small: {record_count: 10}
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := filepath.Join(dir, "profiles")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-profile", profiles, "-plan", plan, "-task", "matrix_mul"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	// One native run per scale, whatever the repetitions, of the -task only
+	var scales []string
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var result Result
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Runtime != "native" || result.Task != "matrix_mul" || len(result.SamplesMs) != 2 || len(result.Profiles) != 2 {
+			t.Errorf("result %+v, expected 2 profiled native matrix_mul runs", result)
+		}
+		scales = append(scales, result.Scale)
+	}
+	if !slices.Equal(scales, []string{"small", "large"}) {
+		t.Errorf("profiled scales %v, expected small and large", scales)
+	}
+	for _, name := range []string{"matrix_mul-small.cpu.pprof", "matrix_mul-small.heap.pprof", "matrix_mul-large.cpu.pprof", "matrix_mul-large.heap.pprof"} {
+		// pprof files are gzipped protocol buffers
+		if data, err := os.ReadFile(filepath.Join(profiles, name)); err != nil || !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+			t.Errorf("%s is not a profile: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(profiles, "json_parse-small.cpu.pprof")); err == nil {
+		t.Error("json_parse was profiled, though -task picked matrix_mul")
+	}
+
+	if code := run([]string{"-profile", profiles}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d without a task to profile, expected 2", code)
+	}
+	if code := run([]string{"-profile", profiles, "-task", "matrix_mul", "matrix_mul-o2.wasm"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with a module to profile, expected 2", code)
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// progressInterval is how often the -progress table is redrawn
+const progressInterval = 250 * time.Millisecond
+
+// progressUI is the -progress display on stderr. On a terminal it is a live
+// table of the benchmarks running, each with its phase, run, and the running
+// mean and CV of the phase's times, under a line with the session's count
+// and ETA. It redraws in place, and the session's other stderr output goes
+// through Write to print above it. Anywhere else it prints a line per
+// finished benchmark instead.
+type progressUI struct {
+	mu      sync.Mutex
+	out     io.Writer
+	tty     bool
+	started time.Time
+	planned int // Benchmarks of the session, native baselines aside
+	done    int
+	failed  int
+	rows    []*moduleProgress
+	drawn   int // Lines of the table on the terminal
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// moduleProgress is a benchmark's row. Its methods do nothing on a nil row,
+// so the runner calls them whether or not -progress is on.
+type moduleProgress struct {
+	ui      *progressUI
+	module  string
+	runtime string
+	scale   string
+	task    string
+	phase   string
+	runs    int       // Of the phase, 0 when open-ended
+	times   []float64 // Of the phase so far, in ms
+}
+
+// newProgress starts the display on out for a session of planned benchmarks
+func newProgress(out io.Writer, planned int) *progressUI {
+	p := &progressUI{out: out, tty: isTerminal(out), started: time.Now(), planned: planned, stop: make(chan struct{})}
+	if p.tty {
+		p.stopped.Go(func() {
+			ticker := time.NewTicker(progressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					p.mu.Lock()
+					p.redraw()
+					p.mu.Unlock()
+				case <-p.stop:
+					return
+				}
+			}
+		})
+	}
+	return p
+}
+
+// isTerminal reports whether w is a character device, which a terminal is
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Write prints p above the table
+func (p *progressUI) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.out.Write(data)
+	p.redraw()
+	return n, err
+}
+
+// close stops redrawing and takes the table off the terminal
+func (p *progressUI) close() {
+	if !p.tty {
+		return
+	}
+	close(p.stop)
+	p.stopped.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+}
+
+// begin adds a row for the benchmark of module
+func (p *progressUI) begin(module, runtime, scale string) *moduleProgress {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := &moduleProgress{ui: p, module: module, runtime: runtime, scale: scale, phase: "load"}
+	p.rows = append(p.rows, m)
+	return m
+}
+
+// setPhase starts a phase of runs of task, 0 when open-ended
+func (m *moduleProgress) setPhase(task, phase string, runs int) {
+	if m == nil {
+		return
+	}
+	m.ui.mu.Lock()
+	defer m.ui.mu.Unlock()
+	m.task, m.phase, m.runs, m.times = task, phase, runs, nil
+}
+
+// run records a run of the phase that took ms
+func (m *moduleProgress) run(ms float64) {
+	if m == nil {
+		return
+	}
+	m.ui.mu.Lock()
+	defer m.ui.mu.Unlock()
+	m.times = append(m.times, ms)
+}
+
+// end removes the row of a finished benchmark
+func (m *moduleProgress) end() {
+	if m == nil {
+		return
+	}
+	p := m.ui
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rows = slices.DeleteFunc(p.rows, func(row *moduleProgress) bool { return row == m })
+}
+
+// finish counts a reported result, which is one of the planned benchmarks
+// unless it is a native baseline, and off a terminal prints its line
+func (p *progressUI) finish(result Result, benchmark bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !benchmark {
+		return
+	}
+	p.done++
+	if result.Error != "" {
+		p.failed++
+	}
+	if p.tty {
+		return
+	}
+	label := result.Runtime
+	if result.Scale != "" {
+		label += " " + result.Scale
+	}
+	outcome := fmt.Sprintf("median %.4g ms (CV %.3f)", result.Stats.Median, result.Stats.CV)
+	if result.Error != "" {
+		outcome = "failed"
+	}
+	fmt.Fprintf(p.out, "bench: [%d/%d] %s %s: %s%s\n", p.done, p.planned, result.Module, label, outcome, p.eta())
+}
+
+// eta estimates the time left from the pace so far, counting the finished
+// share of each running benchmark's runs
+func (p *progressUI) eta() string {
+	finished := float64(p.done)
+	for _, row := range p.rows {
+		if row.phase == "measure" && row.runs > 0 {
+			finished += float64(len(row.times)) / float64(row.runs)
+		}
+	}
+	if finished == 0 || p.done >= p.planned {
+		return ""
+	}
+	elapsed := time.Since(p.started)
+	left := time.Duration(float64(elapsed) * (float64(p.planned) - finished) / finished)
+	return ", ETA " + left.Round(time.Second).String()
+}
+
+// clear takes the table off the terminal, leaving the cursor where it began
+func (p *progressUI) clear() {
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\x1b[%dA\x1b[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+// redraw replaces the table on the terminal with the current one
+func (p *progressUI) redraw() {
+	if !p.tty {
+		return
+	}
+	var table bytes.Buffer
+	fmt.Fprintf(&table, "bench: %d/%d done", p.done, p.planned)
+	if p.failed > 0 {
+		fmt.Fprintf(&table, ", %d failed", p.failed)
+	}
+	fmt.Fprintf(&table, ", %s elapsed%s\n", time.Since(p.started).Round(time.Second), p.eta())
+	if len(p.rows) > 0 {
+		w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "MODULE\tTASK\tRUNTIME\tSCALE\tPHASE\tRUN\tMEAN MS\tCV")
+		for _, row := range p.rows {
+			run, mean, cv := "", "", ""
+			if row.phase != "load" {
+				run = fmt.Sprint(len(row.times))
+				if row.runs > 0 {
+					run += fmt.Sprintf("/%d", row.runs)
+				}
+			}
+			if len(row.times) > 0 {
+				mean = fmt.Sprintf("%.4g", stats.Mean(row.times))
+			}
+			if len(row.times) > 1 {
+				cv = fmt.Sprintf("%.3f", stats.CV(row.times))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", shorten(row.module, 40), row.task, row.runtime, row.scale, row.phase, run, mean, cv)
+		}
+		w.Flush()
+	}
+	p.clear()
+	p.out.Write(table.Bytes())
+	p.drawn = bytes.Count(table.Bytes(), []byte("\n"))
+}
+
+// shorten keeps the end of a path, its file name, to width characters
+func shorten(path string, width int) string {
+	if len(path) <= width {
+		return path
+	}
+	return "..." + path[len(path)-(width-3):]
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunProgress(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-progress", "-warmup", "0", "-runs", "2", path, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	// Off a terminal, a line per benchmark
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "bench: [1/2] "+path+" wazero: median ") || !strings.Contains(lines[0], ", ETA ") ||
+		!strings.HasPrefix(lines[1], "bench: [2/2] ") || strings.Contains(lines[1], "ETA") {
+		t.Errorf("stderr:\n%s\nexpected a line for each module", stderr.String())
+	}
+}
+
+func TestProgressTable(t *testing.T) {
+	var out bytes.Buffer
+	p := &progressUI{out: &out, tty: true, started: time.Now(), planned: 4, done: 1}
+	m := p.begin("builds/tinygo/matrix_mul-o2.wasm", "wazero", "small")
+	m.setPhase("matrix_mul", "measure", 20)
+	m.run(2)
+	m.run(4)
+	p.begin("builds/rust/a-module-with-a-rather-long-file-name-o3.wasm", "wazero", "")
+	p.mu.Lock()
+	p.redraw()
+	p.mu.Unlock()
+
+	table := out.String()
+	for _, text := range []string{
+		"bench: 1/4 done, 0s elapsed, ETA 0s\n",
+		"MODULE",
+		"matrix_mul  wazero   small  measure  2/20  3        0.471\n",
+		// Long paths keep their end
+		"...-with-a-rather-long-file-name-o3.wasm  ",
+		"load",
+	} {
+		if !strings.Contains(table, text) {
+			t.Errorf("table\n%s\nhas no %q", table, text)
+		}
+	}
+	if p.drawn != 4 {
+		t.Errorf("%d lines drawn, expected 4", p.drawn)
+	}
+
+	// Other output goes above the table, which is then drawn again
+	out.Reset()
+	p.Write([]byte("bench: a.wasm: self test failed\n"))
+	if !strings.HasPrefix(out.String(), "\x1b[4A\x1b[Jbench: a.wasm: self test failed\nbench: 1/4 done") {
+		t.Errorf("write %q, expected the table cleared, the line, and the table", out.String())
+	}
+}
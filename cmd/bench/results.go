@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// Result is one module's benchmark: the JSON line printed for it, and an
+// entry of the session's results
+type Result struct {
+	Module          string                 `json:"module"`
+	Runtime         string                 `json:"runtime"`
+	Agent           string                 `json:"agent,omitempty"` // Of the plan that ran it, with -agents
+	Task            string                 `json:"task,omitempty"`
+	Language        string                 `json:"language,omitempty"`
+	Variant         string                 `json:"variant,omitempty"`
+	Toolchain       string                 `json:"toolchain,omitempty"`   // Version of the compiler that built the module
+	Build           string                 `json:"build,omitempty"`       // Variant of a cmd/build artifact, e.g. oz-gcleaking
+	BuildFlags      map[string]string      `json:"build_flags,omitempty"` // The artifact's tinygo flags, by name
+	Size            int64                  `json:"size,omitempty"`        // Of the module file, in bytes
+	ABIVersion      uint32                 `json:"abi_version,omitempty"`
+	Params          map[string]json.Number `json:"params,omitempty"`
+	Scale           string                 `json:"scale,omitempty"`      // Of the -plan step
+	Repetition      int                    `json:"repetition,omitempty"` // Of the -plan step, from 1
+	WarmupRuns      int                    `json:"warmup_runs"`
+	WarmupCV        float64                `json:"warmup_cv,omitempty"`      // Of the last warm-up runs, with -warmup-cv
+	Unsteady        bool                   `json:"unsteady,omitempty"`       // The -warmup-cv warm-up hit -max-warmup first
+	Instantiations  int                    `json:"instantiations,omitempty"` // Fresh instances whose hashes all matched, with -determinism
+	Hash            uint32                 `json:"hash"`
+	SamplesMs       []float64              `json:"samples_ms"`                 // Wall time of each measured run_task, from performance.now() under chrome and for -js
+	RunOrder        []int                  `json:"run_order,omitempty"`        // Position of each measured run among its pass's, with -interleave
+	Fuel            []uint64               `json:"fuel,omitempty"`             // Fuel each measured run_task consumed, on runtimes that meter it
+	Memory          *MemoryUsage           `json:"memory,omitempty"`           // Of the module's linear memory, not for native runs
+	Allocations     *Allocations           `json:"allocations,omitempty"`      // Of each measured run, for an instrumented allocator build
+	Perf            *PerfCounts            `json:"perf,omitempty"`             // Hardware counts of the measured runs, with -perf
+	Energy          *EnergyUsage           `json:"energy,omitempty"`           // Of the processor packages during the measured runs, with -energy
+	ColdStart       *ColdStart             `json:"cold_start,omitempty"`       // Startup cost of fresh instances, with -cold
+	Fuzz            *Fuzz                  `json:"fuzz,omitempty"`             // Of the randomized params cases, with -fuzz
+	Stats           stats.Summary          `json:"stats"`                      // Of SamplesMs, in ms
+	NativeRatio     float64                `json:"native_ratio,omitempty"`     // Median over the native Go baseline's median, with -native
+	JSRatio         float64                `json:"js_ratio,omitempty"`         // Median over the JavaScript baseline's median, with -js
+	CallOverheadMs  float64                `json:"call_overhead_ms,omitempty"` // Of each run's call into the module under its runtime, with -overhead
+	CorrectedMedian float64                `json:"corrected_median,omitempty"` // Median less CallOverheadMs, with -overhead
+	TimedOut        bool                   `json:"timed_out,omitempty"`        // Stopped by -timeout, with Error saying so
+	Verification    *Verification          `json:"verification,omitempty"`     // Of the runs' hashes, with -verify
+	Profiles        []string               `json:"profiles,omitempty"`         // pprof files of the native runs, with -profile
+	Resumed         bool                   `json:"resumed,omitempty"`          // From the -checkpoint of an earlier run of the session
+	Error           string                 `json:"error,omitempty"`
+
+	progress *moduleProgress // Row of the -progress display, nil without it
+	stream   *runStream      // Where each run goes with -stream, nil without it
+}
+
+// MemoryUsage is a module's linear memory across its runs. Linear memory
+// only grows, so the size after a run is its peak during the run.
+type MemoryUsage struct {
+	InitialBytes   uint64 `json:"initial_bytes"`    // After init and writing the params, before any run
+	PeakBytes      uint64 `json:"peak_bytes"`       // After the last measured run
+	MinGrowthBytes uint64 `json:"min_growth_bytes"` // Least any measured run grew it
+	MaxGrowthBytes uint64 `json:"max_growth_bytes"` // Most any measured run grew it
+}
+
+// record adds measured run i, which took memory from before to after bytes
+func (m *MemoryUsage) record(i int, before, after uint64) {
+	growth := after - before
+	if i == 0 || growth < m.MinGrowthBytes {
+		m.MinGrowthBytes = growth
+	}
+	m.MaxGrowthBytes = max(m.MaxGrowthBytes, growth)
+	m.PeakBytes = max(m.PeakBytes, after)
+}
+
+// Session is every result of one bench invocation, with the host it ran on
+type Session struct {
+	SchemaVersion int                `json:"schema_version"` // sessionVersion, 0 for a session from before versioning
+	Started       time.Time          `json:"started"`
+	Environment   Environment        `json:"environment"`
+	Results       []Result           `json:"results"`
+	Scaling       *Scaling           `json:"scaling,omitempty"`  // Of a -sweep
+	Engines       []EngineComparison `json:"engines,omitempty"`  // Of modules run under several runtimes
+	Overhead      []CallOverhead     `json:"overhead,omitempty"` // Of each runtime, with -overhead
+	Agents        []AgentSession     `json:"agents,omitempty"`   // Each agent's host, with -agents; Environment is then the coordinator's
+}
+
+// Environment describes the host, the runner build and what the modules were
+// built with, so results from different sessions can be told apart
+type Environment struct {
+	GoVersion   string            `json:"go_version"` // Of the runner, and of the native baselines
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	Kernel      string            `json:"kernel,omitempty"`
+	CPUModel    string            `json:"cpu_model,omitempty"`
+	CPUs        int               `json:"cpus"`
+	Governor    string            `json:"governor,omitempty"` // cpufreq scaling governor, where exposed
+	Hostname    string            `json:"hostname,omitempty"`
+	Commit      string            `json:"commit,omitempty"`     // Of the task code the modules were built from
+	Toolchains  map[string]string `json:"toolchains,omitempty"` // Versions that built the modules, by language
+	Runtimes    map[string]string `json:"runtimes,omitempty"`   // Versions of the runtimes built into the runner
+	Parallel    int               `json:"parallel"`             // Modules benchmarked at once, 1 for serial
+	Strict      bool              `json:"strict,omitempty"`     // Measurement mode, one module at a time on a pinned thread
+	PinnedCPU   *int              `json:"pinned_cpu,omitempty"`
+	Interleaved bool              `json:"interleaved,omitempty"`  // The measured runs of each pass's modules alternated, with -interleave
+	IsolatedCPU bool              `json:"isolated_cpu,omitempty"` // PinnedCPU is one the kernel keeps other tasks off, with isolcpus
+	Nice        int               `json:"nice,omitempty"`         // Of the pinned thread, with -nice
+}
+
+// newSession starts a session on the current host, of modules built from
+// commit. The toolchains are added as the results come in.
+func newSession(commit string) *Session {
+	hostname, _ := os.Hostname()
+	s := &Session{
+		SchemaVersion: sessionVersion,
+		Started:       time.Now().UTC(),
+		Environment: Environment{
+			GoVersion:  runtime.Version(),
+			OS:         runtime.GOOS,
+			Arch:       runtime.GOARCH,
+			CPUs:       runtime.NumCPU(),
+			Hostname:   hostname,
+			Commit:     commit,
+			Toolchains: map[string]string{},
+			Parallel:   1,
+		},
+		Results: []Result{},
+	}
+	s.Environment.captureHost()
+	return s
+}
+
+// finish compares the engines of the session's modules, printing the
+// comparisons, and writes the session to the -json and -csv files given,
+// returning 1 if any of it failed
+func (s *Session) finish(jsonPath, csvPath string, stderr io.Writer) int {
+	status := 0
+	if s.Engines = compareEngines(s.Results); len(s.Engines) > 0 {
+		if err := writeEngines(stderr, s.Engines); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			status = 1
+		}
+	}
+	for _, export := range []struct {
+		path  string
+		write func(io.Writer) error
+	}{{jsonPath, s.writeJSON}, {csvPath, s.writeCSV}} {
+		if export.path == "" {
+			continue
+		}
+		if err := writeFile(export.path, export.write); err != nil {
+			fmt.Fprintln(stderr, "bench:", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// writeJSON writes the session as one indented JSON document
+func (s *Session) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// writeCSV writes the session in long form, one row per measured run, with a
+// column for every params field of any task (empty where a task lacks it).
+// build_flags is the module's configuration as name=value pairs, so builds
+// group by their flags whatever their files are called.
+// A module that failed before its runs gets a single row with its error.
+func (s *Session) writeCSV(w io.Writer) error {
+	var paramNames []string
+	for _, result := range s.Results {
+		for name := range result.Params {
+			if !slices.Contains(paramNames, name) {
+				paramNames = append(paramNames, name)
+			}
+		}
+	}
+	slices.Sort(paramNames)
+
+	out := csv.NewWriter(w)
+	header := append([]string{"module", "runtime", "task", "language", "variant", "build", "build_flags"}, paramNames...)
+	out.Write(append(header, "run", "time_ms", "fuel", "instructions", "cycles", "branch_misses", "cache_misses", "energy_j", "mallocs", "alloc_bytes", "gcs", "hash", "error"))
+	for _, result := range s.Results {
+		row := []string{result.Module, result.Runtime, result.Task, result.Language, result.Variant, result.Build, result.config()}
+		for _, name := range paramNames {
+			row = append(row, result.Params[name].String())
+		}
+		hash := strconv.FormatUint(uint64(result.Hash), 10)
+		if len(result.SamplesMs) == 0 {
+			out.Write(append(row, "", "", "", "", "", "", "", "", "", "", "", "", result.Error))
+			continue
+		}
+		for run, ms := range result.SamplesMs {
+			line := append(slices.Clip(row), strconv.Itoa(run), strconv.FormatFloat(ms, 'g', -1, 64), count(result.Fuel, run))
+			var perf PerfCounts
+			if result.Perf != nil {
+				perf = *result.Perf
+			}
+			line = append(line, count(perf.Instructions, run), count(perf.Cycles, run), count(perf.BranchMisses, run), count(perf.CacheMisses, run))
+			joules := ""
+			if result.Energy != nil && run < len(result.Energy.Joules) {
+				joules = strconv.FormatFloat(result.Energy.Joules[run], 'g', -1, 64)
+			}
+			line = append(line, joules)
+			var allocations Allocations
+			if result.Allocations != nil {
+				allocations = *result.Allocations
+			}
+			line = append(line, count(allocations.Mallocs, run), count(allocations.Bytes, run), count(allocations.GCs, run))
+			out.Write(append(line, hash, result.Error))
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// count formats counts[run], or "" for a run without one
+func count(counts []uint64, run int) string {
+	if run >= len(counts) {
+		return ""
+	}
+	return strconv.FormatUint(counts[run], 10)
+}
+
+// writeFile writes a session export to path
+func writeFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
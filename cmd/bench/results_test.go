@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSessionWriteCSV(t *testing.T) {
+	session := &Session{Results: []Result{
+		{Module: "a.wasm", Runtime: "wasmtime", Task: "matrix_mul", Build: "oz-gcleaking", BuildFlags: map[string]string{"opt": "z", "gc": "leaking"}, Params: map[string]json.Number{"dimension": "8", "seed": "1"},
+			Hash: 9, SamplesMs: []float64{1.5, 2}, Fuel: []uint64{100, 100}, Allocations: &Allocations{Mallocs: []uint64{3, 4}, Bytes: []uint64{96, 128}, GCs: []uint64{0, 1}}},
+		{Module: "native", Runtime: "native", Task: "matrix_mul", Params: map[string]json.Number{"dimension": "8", "seed": "1"},
+			Hash: 9, SamplesMs: []float64{0.5}, Perf: &PerfCounts{Instructions: []uint64{400}, Cycles: []uint64{200}, BranchMisses: []uint64{3}, CacheMisses: []uint64{7}},
+			Energy: &EnergyUsage{Joules: []float64{0.25}}},
+		{Module: "b.wasm", Runtime: "wazero", Task: "mandelbrot", Params: map[string]json.Number{"width": "4"}, Error: "self test failed"},
+	}}
+	var out bytes.Buffer
+	if err := session.writeCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"module", "runtime", "task", "language", "variant", "build", "build_flags", "dimension", "seed", "width", "run", "time_ms", "fuel", "instructions", "cycles", "branch_misses", "cache_misses", "energy_j", "mallocs", "alloc_bytes", "gcs", "hash", "error"},
+		{"a.wasm", "wasmtime", "matrix_mul", "", "", "oz-gcleaking", "gc=leaking opt=z", "8", "1", "", "0", "1.5", "100", "", "", "", "", "", "3", "96", "0", "9", ""},
+		{"a.wasm", "wasmtime", "matrix_mul", "", "", "oz-gcleaking", "gc=leaking opt=z", "8", "1", "", "1", "2", "100", "", "", "", "", "", "4", "128", "1", "9", ""},
+		{"native", "native", "matrix_mul", "", "", "", "", "8", "1", "", "0", "0.5", "", "400", "200", "3", "7", "0.25", "", "", "", "9", ""},
+		{"b.wasm", "wazero", "mandelbrot", "", "", "", "", "", "", "4", "", "", "", "", "", "", "", "", "", "", "", "", "self test failed"},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("%d rows, expected %d:\n%v", len(rows), len(expected), rows)
+	}
+	for i := range expected {
+		if strings.Join(rows[i], ",") != strings.Join(expected[i], ",") {
+			t.Errorf("row %d = %v, expected %v", i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestRunExportsSession(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	dir := t.TempDir()
+	jsonPath, csvPath := filepath.Join(dir, "session.json"), filepath.Join(dir, "session.csv")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-warmup", "0", "-runs", "2", "-json", jsonPath, "-csv", csvPath, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.Environment.GoVersion != runtime.Version() || session.Environment.CPUs == 0 || session.Started.IsZero() {
+		t.Errorf("environment %+v started %v, expected this host", session.Environment, session.Started)
+	}
+	if len(session.Results) != 1 || len(session.Results[0].SamplesMs) != 2 {
+		t.Errorf("results %+v, expected the module's 2 runs", session.Results)
+	}
+
+	data, err = os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 {
+		t.Errorf("%d CSV lines, expected a header and 2 runs:\n%s", lines, data)
+	}
+}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"wasmbench/abilint/lint"
+	"wasmbench/common"
+	"wasmbench/stats"
+)
+
+// initSeed is passed to init, the browser harness's default random seed
+const initSeed = 12345
+
+// options configure every module's benchmark
+type options struct {
+	runtime       string  // Key of runtimes
+	task          string  // Task of every module, "" to infer it per module
+	params        string  // JSON params overriding the task defaults
+	warmupRuns    int     // Fixed, or the minimum with warmupCV
+	warmupCV      float64 // Steady-state CV that ends the warm-up, 0 for a fixed count
+	maxWarmupRuns int     // Cap on the warm-up with warmupCV
+	runs          int
+	native        bool          // Also run each task natively and report the ratio
+	js            string        // Engine to also run each task's JavaScript implementation under, "" for none
+	jsDir         string        // Of the tasks/<task>/js/<task>.js implementations
+	log           io.Writer     // env.log messages and WASI output
+	scale         string        // Plan scale of params, "" without -plan
+	repetition    int           // Of the plan, from 1; 0 without -plan
+	determinism   int           // Fresh instantiations to compare hashes across, 0 to benchmark
+	timeout       time.Duration // Of each module's whole benchmark, 0 for none
+	strict        bool          // Measurement mode: serial, on a pinned thread
+	references    references    // Vectors every run's hash is checked against, nil without -verify
+	perf          bool          // Count hardware events of every measured run
+	energy        bool          // Read the RAPL energy of every measured run
+	progress      *progressUI   // -progress display, nil without it
+	stream        *runStream    // -stream output, nil without it
+	checkpoint    *checkpoint   // Results of earlier runs of the session, nil without -checkpoint
+	coldStarts    int           // Fresh instantiations to time to their first run, 0 for none
+	fuzz          int           // Randomized params cases to check instead of timing, 0 to benchmark
+	fuzzSeed      uint64        // Of the -fuzz cases
+	interleave    bool          // Alternate the measured runs of a pass's modules
+
+	// tolerance bounds how far a float task's output may be from native
+	// Go's when its hash misses the reference's, nil without -tolerance
+	tolerance *common.Tolerance
+}
+
+// taskInfo is the part of the get_task_info JSON the runner reads
+type taskInfo struct {
+	Task       string `json:"task"`
+	Language   string `json:"language"`
+	Variant    string `json:"variant"`
+	ABIVersion uint32 `json:"abi_version"`
+	AllocStats bool   `json:"alloc_stats"` // An instrumented allocator build
+}
+
+// benchModule runs the module at path and returns its result, with Error set
+// when the module could not be loaded or a run failed, and TimedOut when it
+// ran past opts.timeout
+func benchModule(ctx context.Context, path string, opts options) Result {
+	result := newResult(path, opts)
+	ctx, cancel := withTimeout(ctx, opts.timeout)
+	defer cancel()
+	result.setError(ctx, opts, result.bench(ctx, opts))
+	result.progress.end()
+	return result
+}
+
+// newResult starts the result of the module at path, with its row of the
+// -progress display
+func newResult(path string, opts options) Result {
+	return Result{Module: path, Runtime: opts.runtime, Scale: opts.scale, Repetition: opts.repetition, WarmupRuns: opts.warmupRuns, SamplesMs: []float64{},
+		progress: opts.progress.begin(path, opts.runtime, opts.scale), stream: opts.stream}
+}
+
+// withTimeout returns ctx with a deadline timeout from now, or ctx itself
+// when timeout is 0
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// setError records err, if any, as r's error. A run cut short by the deadline
+// of ctx fails however the runtime reported it, so its error is the timeout.
+func (r *Result) setError(ctx context.Context, opts options, err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		r.TimedOut = true
+		err = fmt.Errorf("timed out after %s", opts.timeout)
+	}
+	r.Error = err.Error()
+}
+
+func (r *Result) bench(ctx context.Context, opts options) error {
+	m, err := r.prepare(ctx, opts)
+	if err != nil || m == nil {
+		return err
+	}
+	defer m.close()
+	for range opts.runs {
+		if err := m.run(); err != nil {
+			return err
+		}
+	}
+	if err := m.finish(); err != nil {
+		return err
+	}
+	return r.ColdStart.compare(r)
+}
+
+// prepare reads, loads and warms up the module, returning its measurement
+// for the caller to take the measured runs of and close. -determinism and
+// -fuzz check the module instead, and return no measurement.
+func (r *Result) prepare(ctx context.Context, opts options) (*measurement, error) {
+	wasm, err := os.ReadFile(r.Module)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyArtifact(r.Module, wasm); err != nil {
+		return nil, err
+	}
+	r.Size = int64(len(wasm))
+	if opts.determinism > 0 {
+		return nil, r.checkDeterminism(ctx, wasm, opts)
+	}
+	if opts.fuzz > 0 {
+		return nil, r.fuzz(ctx, wasm, opts)
+	}
+	if opts.coldStarts > 0 {
+		if err := r.measureColdStarts(ctx, wasm, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	m, ptr, err := r.load(ctx, wasm, opts)
+	if err != nil {
+		return nil, err
+	}
+	stopWatch := m.watch(ctx)
+	closeModule := func() {
+		stopWatch()
+		m.close(ctx)
+	}
+	r.Memory = &MemoryUsage{InitialBytes: m.memorySize()}
+	// A runTimer's module runs in another process, out of the counters' reach
+	timer, remote := m.instance.(runTimer)
+	if opts.perf && !remote {
+		r.Perf = &PerfCounts{}
+	}
+	if opts.energy {
+		r.Energy = &EnergyUsage{}
+	}
+	runTask := func() (uint32, error) {
+		// A cancelled run leaves the instance usable, so stop between runs too
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return m.runTask(ctx, ptr)
+	}
+	if err := r.warmUp(opts, runTask); err != nil {
+		closeModule()
+		return nil, err
+	}
+	measuring, err := r.startMeasuring(opts.runs, m.instance, timer, runTask)
+	if err != nil {
+		closeModule()
+		return nil, err
+	}
+	measuring.done = closeModule
+	if opts.tolerance != nil && tasks[r.Task].floatOutput {
+		measuring.tolerate = func() error { return r.checkTolerance(ctx, m, ptr, opts) }
+	}
+	return measuring, nil
+}
+
+// load instantiates the module, fills in r's task and params from it, runs
+// init and self_test, and writes and validates the params, returning the
+// module and its params pointer. The caller closes the module.
+func (r *Result) load(ctx context.Context, wasm []byte, opts options) (m *module, paramsPtr uint32, err error) {
+	// A module off the ABI would only fail once a runtime called it, less clearly
+	if err := lint.Lint(wasm); err != nil {
+		return nil, 0, fmt.Errorf("ABI check: %w", err)
+	}
+	start := time.Now()
+	inst, err := runtimes[r.Runtime](ctx, wasm, opts.log)
+	if err != nil {
+		return nil, 0, err
+	}
+	m = &module{instance: inst, instantiated: time.Since(start)}
+	defer func() {
+		if err != nil {
+			inst.close(ctx)
+		}
+	}()
+
+	if info, ok, err := m.taskInfo(ctx); err != nil {
+		return nil, 0, err
+	} else if ok {
+		r.Task, r.Language, r.Variant, r.ABIVersion = info.Task, info.Language, info.Variant, info.ABIVersion
+		if info.AllocStats && m.exports("get_memory_stats") {
+			r.Allocations = &Allocations{read: func() (uint64, uint64, uint64, error) { return m.runAllocations(ctx) }}
+		}
+	}
+	if opts.task != "" {
+		r.Task = opts.task
+	}
+	if r.Task == "" {
+		r.Task = taskFromFileName(r.Module)
+	}
+	spec, ok := tasks[r.Task]
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown task %q; set -task", r.Task)
+	}
+
+	params, err := buildParams(spec, opts.params)
+	if err != nil {
+		return nil, 0, err
+	}
+	r.Params = paramValues(spec, params)
+	m.params = r.Params
+	if r.Verification == nil {
+		r.Verification = opts.references.match(r.Task, params)
+	}
+
+	if _, err := m.call(ctx, "init", initSeed); err != nil {
+		return nil, 0, err
+	}
+
+	// Known-answer vectors catch a broken artifact before any time is spent benchmarking it
+	if m.exports("self_test") {
+		if status, err := m.call(ctx, "self_test"); err != nil {
+			return nil, 0, err
+		} else if status != 0 {
+			return nil, 0, fmt.Errorf("module self test failed (status %d): %s", status, m.lastError(ctx))
+		}
+	}
+
+	ptr, err := m.write(ctx, params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Reject bad parameters with the module's reason before any run
+	if m.exports("validate_params") {
+		if status, err := m.call(ctx, "validate_params", ptr); err != nil {
+			return nil, 0, err
+		} else if status != 0 {
+			code, err := m.errorCode(ctx)
+			if err != nil {
+				return nil, 0, err
+			}
+			return nil, 0, diagnose("invalid parameters", status, code, m.lastError(ctx), r.Params)
+		}
+	}
+
+	return m, ptr, nil
+}
+
+// measure times runs calls of runTask, recording each one's wall time, the
+// fuel it consumed when inst is a fuelMeter, with r.Memory the growth of
+// inst's linear memory, with r.Allocations the module's own count of its
+// allocations, with r.Perf its hardware counts and with r.Energy the
+// processor's energy, then summarizes them. The times of timer, when it is
+// not nil, replace the wall times. inst is nil for native and JavaScript runs.
+func (r *Result) measure(runs int, inst instance, timer runTimer, runTask func() (uint32, error)) error {
+	m, err := r.startMeasuring(runs, inst, timer, runTask)
+	if err != nil {
+		return err
+	}
+	defer m.close()
+	for range runs {
+		if err := m.run(); err != nil {
+			return err
+		}
+	}
+	return m.finish()
+}
+
+// measurement is a benchmark past its warm-up, taking its measured runs one
+// at a time, so -interleave can alternate the runs of several modules
+type measurement struct {
+	r        *Result
+	inst     instance
+	timer    runTimer
+	runTask  func() (uint32, error)
+	meter    fuelMeter
+	counters *perfGroup
+	rapl     *energyMeter
+	done     func() // Releases what the runs needed, nil for nothing
+	// tolerate is -tolerance's check of a float task's output after runs
+	// that missed the reference hash, nil without it
+	tolerate func() error
+}
+
+// startMeasuring opens the counters of r's measured runs, which measure
+// describes, for runs of them
+func (r *Result) startMeasuring(runs int, inst instance, timer runTimer, runTask func() (uint32, error)) (*measurement, error) {
+	m := &measurement{r: r, inst: inst, timer: timer, runTask: runTask}
+	m.meter, _ = inst.(fuelMeter)
+	r.progress.setPhase(r.Task, "measure", runs)
+	if r.Perf != nil {
+		var err error
+		if m.counters, err = openPerf(); err != nil {
+			return nil, err
+		}
+	}
+	if r.Energy != nil {
+		var err error
+		if m.rapl, err = openEnergy(powercapDir); err != nil {
+			m.close()
+			return nil, err
+		}
+		r.Energy.Domains = m.rapl.names()
+	}
+	return m, nil
+}
+
+// run times one measured run
+func (m *measurement) run() error {
+	r, inst := m.r, m.inst
+	i := len(r.SamplesMs)
+	var memoryBefore uint64
+	if r.Memory != nil {
+		memoryBefore = inst.memorySize()
+	}
+	var fuelBefore uint64
+	if m.meter != nil {
+		var err error
+		if fuelBefore, err = m.meter.fuel(); err != nil {
+			return err
+		}
+	}
+	var energyBefore []uint64
+	if m.rapl != nil {
+		var err error
+		if energyBefore, err = m.rapl.read(); err != nil {
+			return err
+		}
+	}
+	if m.counters != nil {
+		if err := m.counters.start(); err != nil {
+			return err
+		}
+	}
+	start := time.Now()
+	hash, err := m.runTask()
+	elapsed := time.Since(start)
+	if err != nil {
+		return err
+	}
+	if m.counters != nil {
+		sample, err := m.counters.stop()
+		if err != nil {
+			return err
+		}
+		r.Perf.record(sample)
+	}
+	if m.rapl != nil {
+		energyAfter, err := m.rapl.read()
+		if err != nil {
+			return err
+		}
+		r.Energy.record(m.rapl.joules(energyBefore, energyAfter), float64(elapsed)/float64(time.Millisecond))
+	}
+	if m.meter != nil {
+		fuelAfter, err := m.meter.fuel()
+		if err != nil {
+			return err
+		}
+		r.Fuel = append(r.Fuel, fuelAfter-fuelBefore)
+	}
+	if r.Memory != nil {
+		r.Memory.record(i, memoryBefore, inst.memorySize())
+	}
+	if r.Allocations != nil {
+		if err := r.Allocations.record(); err != nil {
+			return err
+		}
+	}
+	r.verify(hash)
+	// Every repetition runs the same params, so the hash must not change
+	if i > 0 && hash != r.Hash {
+		return fmt.Errorf("run %d hashed %d, earlier runs %d", i, hash, r.Hash)
+	}
+	r.Hash = hash
+	ms := float64(elapsed) / float64(time.Millisecond)
+	if m.timer != nil {
+		ms = m.timer.lastRunMs()
+	}
+	r.SamplesMs = append(r.SamplesMs, ms)
+	r.progress.run(ms)
+	return r.stream.run(r)
+}
+
+// finish summarizes the measured runs
+func (m *measurement) finish() error {
+	m.r.summarize()
+	if m.tolerate != nil {
+		if err := m.tolerate(); err != nil {
+			return err
+		}
+	}
+	// The times stay in the result, marked by its error
+	return m.r.verificationError()
+}
+
+// close releases the counters and whatever else the runs needed
+func (m *measurement) close() {
+	if m.counters != nil {
+		m.counters.close()
+		m.counters = nil
+	}
+	if m.done != nil {
+		m.done()
+		m.done = nil
+	}
+}
+
+// summarize fills Stats from the samples, leaving out the outlying runs so
+// one slow run (a GC pause, a descheduled thread) does not skew them
+func (r *Result) summarize() {
+	r.Stats = stats.Summarize(r.SamplesMs)
+	r.Allocations.summarize(r.SamplesMs)
+}
+
+// taskFromFileName takes the task from a build's file name, such as
+// mandelbrot-o2.wasm or matrix_mul-o3-simd.wasm
+func taskFromFileName(path string) string {
+	name, _, _ := strings.Cut(filepath.Base(path), "-")
+	return strings.TrimSuffix(name, ".wasm")
+}
+
+// errGoJS refuses standard Go modules, recognized by their gojs runtime.wasmExit import
+var errGoJS = errors.New("standard Go (GOOS=js) modules need wasm_exec.js; run them in the browser harness")
+
+// instance is a task module instantiated by one of the runtimes, with the
+// host imports the browser loader provides: the env functions of TinyGo
+// builds, WASI, and the gojs runtime clock of TinyGo's wasm target. Only
+// _initialize runs at instantiation; like the browser harness, the runner
+// never calls _start. Params and results are i32s.
+type instance interface {
+	// exports reports whether the module exports the function name
+	exports(name string) bool
+	// call calls the exported function name and returns its first result,
+	// or 0 for a function without results
+	call(ctx context.Context, name string, params ...uint32) (uint32, error)
+	readMemory(ptr, length uint32) ([]byte, bool)
+	writeMemory(ptr uint32, data []byte) bool
+	// memorySize returns the size of linear memory in bytes, 0 without one
+	memorySize() uint64
+	// close releases the instance's runtime
+	close(ctx context.Context) error
+}
+
+// runTimer is implemented by instances that time run_task inside the runtime,
+// where the host's clock would also count the call's transport; lastRunMs
+// returns the time of the last run_task call
+type runTimer interface {
+	lastRunMs() float64
+}
+
+// fuelMeter is implemented by instances that count the work they execute;
+// fuel returns the total consumed so far
+type fuelMeter interface {
+	fuel() (uint64, error)
+}
+
+// runtimes instantiate a module in a fresh runtime, so no state leaks from
+// one module into the next. Keyed by -runtime; optional ones register
+// themselves behind a build tag.
+var runtimes = map[string]func(ctx context.Context, wasm []byte, log io.Writer) (instance, error){
+	"wazero": instantiateWazero,
+}
+
+// module is an instantiated task module
+type module struct {
+	instance
+	instantiated time.Duration          // Compiling and instantiating it
+	params       map[string]json.Number // The params load wrote, which diagnose names
+}
+
+// write copies data into a buffer from the module's alloc and returns its address
+func (m *module) write(ctx context.Context, data []byte) (uint32, error) {
+	ptr, err := m.call(ctx, "alloc", uint32(len(data)))
+	if err != nil {
+		return 0, err
+	}
+	if ptr == 0 || !m.writeMemory(ptr, data) {
+		return 0, fmt.Errorf("alloc(%d) returned an unusable buffer at %#x", len(data), ptr)
+	}
+	return ptr, nil
+}
+
+// runTask runs the task once. A zero hash is only a failure if the module
+// recorded an error, since a legitimate hash can also be 0. run_task returns
+// no status, so a rejection's is the one its error code maps to.
+func (m *module) runTask(ctx context.Context, paramsPtr uint32) (uint32, error) {
+	hash, err := m.call(ctx, "run_task", paramsPtr)
+	if err != nil {
+		return 0, err
+	}
+	if hash == 0 {
+		if message := m.lastError(ctx); message != "" {
+			code, err := m.errorCode(ctx)
+			if err != nil {
+				return 0, err
+			}
+			if code != common.ErrNone {
+				return 0, diagnose("run_task failed", common.ErrorStatus(code), code, message, m.params)
+			}
+			return 0, fmt.Errorf("run_task failed: %s", message)
+		}
+	}
+	return hash, nil
+}
+
+// errorCode reads get_error_code, 0 when the module does not export it
+func (m *module) errorCode(ctx context.Context) (uint32, error) {
+	if !m.exports("get_error_code") {
+		return 0, nil
+	}
+	return m.call(ctx, "get_error_code")
+}
+
+// watch is the -timeout watchdog. When ctx is done it raises the module's
+// get_cancel_ptr flag, so a task that polls it ends the current run with
+// StatusCancelled on runtimes that cannot interrupt a call themselves. Under
+// wazero the context closes the module as well. The returned stop ends the
+// watchdog, and must be called before the module is closed.
+func (m *module) watch(ctx context.Context) (stop func()) {
+	if ctx.Done() == nil || !m.exports("get_cancel_ptr") {
+		return func() {}
+	}
+	flag, err := m.call(ctx, "get_cancel_ptr")
+	if err != nil {
+		return func() {}
+	}
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			m.writeMemory(flag, []byte{1, 0, 0, 0})
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// lastError reads the get_last_error_ptr message, "" if none was recorded or
+// the module does not export it
+func (m *module) lastError(ctx context.Context) string {
+	if !m.exports("get_last_error_ptr") || !m.exports("get_last_error_len") {
+		return ""
+	}
+	ptr, err := m.call(ctx, "get_last_error_ptr")
+	if err != nil {
+		return ""
+	}
+	length, err := m.call(ctx, "get_last_error_len")
+	if err != nil {
+		return ""
+	}
+	message, _ := m.readMemory(ptr, length)
+	return string(message)
+}
+
+// taskInfo decodes the get_task_info metadata; ok is false when the module
+// does not export it, as the Rust modules do not
+func (m *module) taskInfo(ctx context.Context) (info taskInfo, ok bool, err error) {
+	if !m.exports("get_task_info") {
+		return info, false, nil
+	}
+	ptr, err := m.call(ctx, "get_task_info")
+	if err != nil {
+		return info, false, err
+	}
+	// {u32 len, JSON}
+	prefix, ok := m.readMemory(ptr, 4)
+	if !ok {
+		return info, false, errors.New("get_task_info points outside memory")
+	}
+	data, ok := m.readMemory(ptr+4, binary.LittleEndian.Uint32(prefix))
+	if !ok {
+		return info, false, errors.New("get_task_info points outside memory")
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, false, fmt.Errorf("get_task_info: %w", err)
+	}
+	return info, true, nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTask is a minimal task module: one page of memory, init does nothing,
+// alloc always returns 1024, and run_task hashes the first u32 of the params
+// (the matrix_mul dimension) as 3 times its value
+var fakeTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	// Types: (i32) -> (), (i32) -> i32
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	// Functions: init, alloc, run_task
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	// Memory: 1 page
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	// Exports: memory, init, alloc, run_task
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	// Code
+	0x0a, 0x15, 0x03,
+	0x02, 0x00, 0x0b, // init: nop
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b, // alloc: i32.const 1024
+	0x0a, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0x03, 0x6c, 0x0b, // run_task: i32.load(ptr) * 3
+}
+
+// writeModule writes wasm to a file named name in a temporary directory
+func writeModule(t *testing.T, name string, wasm []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, wasm, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunBenchmarksModule(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-warmup", "1", "-runs", "3", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a JSON result: %v\n%s", err, stdout.String())
+	}
+	// The task comes from the file name, since the module has no get_task_info
+	if result.Task != "matrix_mul" || result.Params["dimension"] != "5" || result.Params["seed"] != "12345" {
+		t.Errorf("task %q with params %v, expected matrix_mul with dimension 5 and seed 12345", result.Task, result.Params)
+	}
+	if result.Hash != 15 {
+		t.Errorf("hash = %d, expected 15", result.Hash)
+	}
+	if result.Size != int64(len(fakeTask)) {
+		t.Errorf("size %d, expected the module's %d bytes", result.Size, len(fakeTask))
+	}
+	if result.Runtime != "wazero" || result.Fuel != nil {
+		t.Errorf("runtime %q reported fuel %v, expected wazero without fuel", result.Runtime, result.Fuel)
+	}
+	if len(result.SamplesMs) != 3 || result.WarmupRuns != 1 {
+		t.Errorf("%d measured and %d warm-up runs, expected 3 and 1", len(result.SamplesMs), result.WarmupRuns)
+	}
+	if s := result.Stats; s.Min > s.Median || s.Median > s.Max || s.Mean < s.Min || s.N+s.Outliers != 3 {
+		t.Errorf("inconsistent stats: %+v", s)
+	}
+	if m := result.Memory; m == nil || m.InitialBytes != 65536 || m.PeakBytes != 65536 || m.MaxGrowthBytes != 0 {
+		t.Errorf("memory %+v, expected fakeTask's single page throughout", m)
+	}
+}
+
+// growTask is fakeTask with a run_task that grows memory by a page and
+// returns 7
+var growTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0a, 0x14, 0x03,
+	0x02, 0x00, 0x0b,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x09, 0x00, 0x41, 0x01, 0x40, 0x00, 0x1a, 0x41, 0x07, 0x0b, // run_task: drop(memory.grow(1)); 7
+}
+
+func TestRunReportsMemoryGrowth(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", growTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-warmup", "2", "-runs", "3", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	expected := MemoryUsage{InitialBytes: 65536, PeakBytes: 6 * 65536, MinGrowthBytes: 65536, MaxGrowthBytes: 65536}
+	if result.Memory == nil || *result.Memory != expected {
+		t.Errorf("memory %+v, expected %+v", result.Memory, expected)
+	}
+}
+
+// spinTask is fakeTask with a run_task that never returns
+var spinTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0a, 0x13, 0x03,
+	0x02, 0x00, 0x0b,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x08, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x00, 0x0b, // run_task: loop br 0 end; unreachable
+}
+
+func TestRunTimesOutAndGoesOn(t *testing.T) {
+	spin := writeModule(t, "matrix_mul-o2.wasm", spinTask)
+	fake := writeModule(t, "matrix_mul-o3.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-timeout", "100ms", "-warmup", "0", "-runs", "1", spin, fake}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d, expected 1", code)
+	}
+	decoder := json.NewDecoder(&stdout)
+	var spun, faked Result
+	if err := decoder.Decode(&spun); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode(&faked); err != nil {
+		t.Fatalf("the session should go on after a timeout: %v", err)
+	}
+	if !spun.TimedOut || spun.Error != "timed out after 100ms" {
+		t.Errorf("spinning module timed_out %v with error %q, expected a timeout", spun.TimedOut, spun.Error)
+	}
+	if faked.TimedOut || faked.Error != "" || len(faked.SamplesMs) != 1 {
+		t.Errorf("next module timed_out %v with error %q and %d runs, expected it to run", faked.TimedOut, faked.Error, len(faked.SamplesMs))
+	}
+}
+
+func TestSummarizeLeavesOutOutliers(t *testing.T) {
+	result := Result{SamplesMs: []float64{2, 2.2, 2.1, 9, 2.3, 2}}
+	result.summarize()
+	if s := result.Stats; s.Outliers != 1 || s.Max != 2.3 || s.Min != 2 {
+		t.Errorf("%d outliers, min %v, max %v, expected the 9ms run left out", s.Outliers, s.Min, s.Max)
+	}
+}
+
+func TestRunReportsFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		wasm   []byte
+		args   []string
+		reason string
+	}{
+		{"unknown task", "fractal-o2.wasm", fakeTask, nil, "unknown task"},
+		{"bad params", "matrix_mul-o2.wasm", fakeTask, []string{"-params", `{"width": 8}`}, "unknown params field"},
+		{"not wasm", "matrix_mul-o2.wasm", []byte("not wasm"), nil, "ABI check: not a version 1 wasm module"},
+		{"no run_task", "matrix_mul-o2.wasm", fakeTask[:8], nil, "ABI check: missing export init"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeModule(t, tt.file, tt.wasm)
+			var stdout, stderr bytes.Buffer
+			if code := run(append(tt.args, path), &stdout, &stderr); code != 1 {
+				t.Fatalf("exit status %d, expected 1", code)
+			}
+			var result Result
+			if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+				t.Fatalf("a failed module should still print its result: %v", err)
+			}
+			if !strings.Contains(result.Error, tt.reason) {
+				t.Errorf("error %q does not mention %q", result.Error, tt.reason)
+			}
+		})
+	}
+}
+
+func TestRunRejectsUnknownRuntime(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-runtime", "v8", path}, &stdout, &stderr); code != 2 {
+		t.Fatalf("exit status %d, expected 2", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown -runtime") {
+		t.Errorf("stderr %q does not name the bad runtime", stderr.String())
+	}
+}
+
+func TestFindModules(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"tinygo/mandelbrot-o2.wasm", "tinygo/mandelbrot-o2-wasi.wasm", "rust/matrix_mul-o3.wasm", "go/json_parse.wasm"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	modules := findModules(dir)
+	expected := []string{filepath.Join(dir, "tinygo/mandelbrot-o2.wasm"), filepath.Join(dir, "rust/matrix_mul-o3.wasm")}
+	if strings.Join(modules, ",") != strings.Join(expected, ",") {
+		t.Errorf("findModules = %v, expected %v", modules, expected)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// job is one module of one pass
+type job struct {
+	pass   int // Index into the passes
+	module string
+}
+
+// benchPasses benchmarks the modules of every pass and yields each result
+// with its pass index, in pass and then module order. With one worker, each
+// module runs on the calling goroutine once the previous result was handled;
+// with opts.strict, after a collection, so no garbage of the last module is
+// swept during the next one's runs. With more, up to workers modules of any
+// passes run at once, each on its own goroutine: fast for exploring, but the
+// modules compete for cores, caches and memory bandwidth, so the times only
+// compare within a session. A module that opts.checkpoint has a result for
+// is not run, and that result is yielded in its place. With one worker and
+// opts.interleave, each pass's modules alternate their measured runs.
+func benchPasses(ctx context.Context, passes []pass, workers int) iter.Seq2[int, Result] {
+	var jobs []job
+	for i, p := range passes {
+		for _, module := range p.modules {
+			jobs = append(jobs, job{i, module})
+		}
+	}
+	if workers <= 1 && slices.ContainsFunc(passes, func(p pass) bool { return p.opts.interleave }) {
+		return interleavePasses(ctx, passes)
+	}
+	if workers <= 1 {
+		return func(yield func(int, Result) bool) {
+			for _, j := range jobs {
+				opts := passes[j.pass].opts
+				result, ok := opts.checkpoint.completed(jobOf(j.module, opts))
+				if !ok {
+					if opts.strict {
+						runtime.GC()
+					}
+					result = benchModule(ctx, j.module, opts)
+				}
+				if !yield(j.pass, result) {
+					return
+				}
+			}
+		}
+	}
+
+	return func(yield func(int, Result) bool) {
+		results := make([]chan Result, len(jobs))
+		for i := range results {
+			results[i] = make(chan Result, 1)
+		}
+		next := make(chan int)
+		var wg sync.WaitGroup
+		// Cancelled first when the caller stops early, which ends the
+		// running modules between runs, then waited for
+		ctx, cancel := context.WithCancel(ctx)
+		defer wg.Wait()
+		defer cancel()
+		for range min(workers, len(jobs)) {
+			wg.Go(func() {
+				for i := range next {
+					opts := passes[jobs[i].pass].opts
+					result, ok := opts.checkpoint.completed(jobOf(jobs[i].module, opts))
+					if !ok {
+						result = benchModule(ctx, jobs[i].module, opts)
+					}
+					results[i] <- result
+				}
+			})
+		}
+		wg.Go(func() {
+			defer close(next)
+			for i := range jobs {
+				select {
+				case next <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+
+		for i, j := range jobs {
+			if !yield(j.pass, <-results[i]) {
+				return
+			}
+		}
+	}
+}
+
+// interleavePasses benchmarks each pass's modules with benchInterleaved and
+// yields the results in pass and then module order, as benchPasses does. The
+// modules opts.checkpoint has a result for stay out of the rotation.
+func interleavePasses(ctx context.Context, passes []pass) iter.Seq2[int, Result] {
+	return func(yield func(int, Result) bool) {
+		for i, p := range passes {
+			results := make([]Result, len(p.modules))
+			resumed := make([]bool, len(p.modules))
+			var pending []string
+			for j, module := range p.modules {
+				if results[j], resumed[j] = p.opts.checkpoint.completed(jobOf(module, p.opts)); !resumed[j] {
+					pending = append(pending, module)
+				}
+			}
+			measured := benchInterleaved(ctx, pending, p.opts)
+			for j := range p.modules {
+				if !resumed[j] {
+					results[j], measured = measured[0], measured[1:]
+				}
+				if !yield(i, results[j]) {
+					return
+				}
+			}
+		}
+	}
+}
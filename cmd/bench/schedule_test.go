@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBenchPassesKeepsOrder(t *testing.T) {
+	var passes []pass
+	for dimension := 1; dimension <= 4; dimension++ {
+		opts := options{runtime: "wazero", params: fmt.Sprintf(`{"dimension": %d}`, dimension), runs: 2}
+		passes = append(passes, pass{opts, []string{
+			writeModule(t, "matrix_mul-o2.wasm", fakeTask),
+			writeModule(t, "matrix_mul-o3.wasm", fakeTask),
+		}})
+	}
+	for _, workers := range []int{1, 3} {
+		var got []string
+		for i, result := range benchPasses(context.Background(), passes, workers) {
+			if result.Error != "" {
+				t.Fatal(result.Error)
+			}
+			got = append(got, fmt.Sprintf("%d %s %d", i, filepath.Base(result.Module), result.Hash))
+		}
+		var expected []string
+		for i := range passes {
+			for _, name := range []string{"matrix_mul-o2.wasm", "matrix_mul-o3.wasm"} {
+				expected = append(expected, fmt.Sprintf("%d %s %d", i, name, 3*(i+1)))
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("%d workers: results %v, expected %v", workers, got, expected)
+		}
+	}
+}
+
+func TestRunParallelAndStrict(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	for _, args := range [][]string{{"-parallel", "2"}, {"-strict"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(append(args, "-warmup", "0", "-runs", "2", path, path), &stdout, &stderr); code != 0 {
+			t.Fatalf("%v: exit status %d: %s", args, code, stderr.String())
+		}
+		if n := strings.Count(stdout.String(), "\n"); n != 2 {
+			t.Errorf("%v: %d results, expected 2", args, n)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-strict", "-parallel", "4", path}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -strict and -parallel 4, expected 2", code)
+	}
+	for _, args := range [][]string{{"-nice", "-5"}, {"-cpu", "0"}, {"-strict", "-nice", "20"}, {"-strict", "-cpu", "-2"}} {
+		if code := run(append(args, path), &stdout, &stderr); code != 2 {
+			t.Errorf("exit status %d with %v, expected 2", code, args)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	openHistory = openSQLiteHistory
+}
+
+// historySchema creates the history tables of an empty database: a row per
+// session, per module result, per tinygo flag of the result's build and per
+// measured run. results repeats the session's commit so that task, params,
+// toolchain and commit index together; params is the result's params as a
+// JSON object with sorted keys, for json_extract, and sessions.environment
+// the whole environment, CPU model and toolchains included, the same way.
+const historySchema = `
+CREATE TABLE IF NOT EXISTS sessions (
+	id          INTEGER PRIMARY KEY,
+	started     TEXT NOT NULL,
+	commit_id   TEXT NOT NULL,
+	go_version  TEXT NOT NULL,
+	os          TEXT NOT NULL,
+	arch        TEXT NOT NULL,
+	cpus        INTEGER NOT NULL,
+	hostname    TEXT NOT NULL,
+	environment TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	id           INTEGER PRIMARY KEY,
+	session_id   INTEGER NOT NULL REFERENCES sessions(id),
+	task         TEXT NOT NULL,
+	params       TEXT NOT NULL,
+	scale        TEXT NOT NULL,
+	repetition   INTEGER NOT NULL,
+	toolchain    TEXT NOT NULL,
+	commit_id    TEXT NOT NULL,
+	module       TEXT NOT NULL,
+	runtime      TEXT NOT NULL,
+	language     TEXT NOT NULL,
+	variant      TEXT NOT NULL,
+	hash         INTEGER NOT NULL,
+	runs         INTEGER NOT NULL,
+	outliers     INTEGER NOT NULL,
+	min_ms       REAL NOT NULL,
+	median_ms    REAL NOT NULL,
+	mean_ms      REAL NOT NULL,
+	max_ms       REAL NOT NULL,
+	stddev_ms    REAL NOT NULL,
+	cv           REAL NOT NULL,
+	native_ratio REAL,
+	peak_memory  INTEGER, -- Bytes of linear memory, NULL for native runs
+	error        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_key ON results (task, params, toolchain, commit_id);
+CREATE TABLE IF NOT EXISTS build_flags (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	flag      TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (result_id, flag)
+);
+CREATE TABLE IF NOT EXISTS runs (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	run       INTEGER NOT NULL,
+	time_ms   REAL NOT NULL,
+	fuel      INTEGER,
+	PRIMARY KEY (result_id, run)
+);
+`
+
+// sqliteHistory is a history store in a local SQLite database
+type sqliteHistory struct {
+	db *sql.DB
+}
+
+// historyVersion is the version of historySchema, which a database keeps as
+// its user_version. Bump it with a step in historyMigrations whenever the
+// schema changes.
+const historyVersion = 5
+
+// historyMigrations bring a database at version i+1 to version i+2. Version 1
+// is the first schema; a database from before versioning has user_version 0
+// and may be at any version from 1, so every step checks before it adds.
+var historyMigrations = []func(tx *sql.Tx) error{
+	// 2: the -plan step of each result
+	func(tx *sql.Tx) error {
+		if err := addColumn(tx, "results", "scale", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		return addColumn(tx, "results", "repetition", "INTEGER NOT NULL DEFAULT 0")
+	},
+	// 3: peak linear memory
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "results", "peak_memory", "INTEGER")
+	},
+	// 4: the whole environment of each session
+	func(tx *sql.Tx) error {
+		return addColumn(tx, "sessions", "environment", "TEXT NOT NULL DEFAULT '{}'")
+	},
+	// 5: the build flags of each result
+	func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS build_flags (
+	result_id INTEGER NOT NULL REFERENCES results(id),
+	flag      TEXT NOT NULL,
+	value     TEXT NOT NULL,
+	PRIMARY KEY (result_id, flag)
+)`)
+		return err
+	},
+}
+
+// openSQLiteHistory opens the database at path, creating it at historyVersion
+// or migrating it there from an older version
+func openSQLiteHistory(path string) (history, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	if err := migrateHistory(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &sqliteHistory{db: db}, nil
+}
+
+// migrateHistory creates the schema in an empty database and brings an older
+// one up to historyVersion, in one transaction
+func migrateHistory(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version, tables int
+	if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'sessions'`).Scan(&tables); err != nil {
+		return err
+	}
+	switch {
+	case version > historyVersion:
+		return fmt.Errorf("history schema version %d is newer than this bench's %d", version, historyVersion)
+	case tables == 0:
+		if _, err := tx.Exec(historySchema); err != nil {
+			return err
+		}
+	default:
+		for _, migrate := range historyMigrations[max(version, 1)-1:] {
+			if err := migrate(tx); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, historyVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addColumn adds column to table unless it already has it
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&exists); err != nil {
+		return err
+	}
+	if exists > 0 {
+		return nil
+	}
+	_, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// record inserts the session and its results in one transaction
+func (h *sqliteHistory) record(s *Session) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	env := s.Environment
+	environment, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	inserted, err := tx.Exec(`INSERT INTO sessions (started, commit_id, go_version, os, arch, cpus, hostname, environment) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.Started.Format(time.RFC3339Nano), env.Commit, env.GoVersion, env.OS, env.Arch, env.CPUs, env.Hostname, string(environment))
+	if err != nil {
+		return err
+	}
+	sessionID, err := inserted.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	insertResult, err := tx.Prepare(`INSERT INTO results (session_id, task, params, scale, repetition, toolchain, commit_id, module, runtime, language, variant,
+		hash, runs, outliers, min_ms, median_ms, mean_ms, max_ms, stddev_ms, cv, native_ratio, peak_memory, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	insertRun, err := tx.Prepare(`INSERT INTO runs (result_id, run, time_ms, fuel) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	insertFlag, err := tx.Prepare(`INSERT INTO build_flags (result_id, flag, value) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	for _, r := range s.Results {
+		params := []byte("{}")
+		if len(r.Params) > 0 {
+			// Maps marshal with sorted keys, so equal params are equal strings
+			if params, err = json.Marshal(r.Params); err != nil {
+				return err
+			}
+		}
+		var nativeRatio any
+		if r.NativeRatio != 0 {
+			nativeRatio = r.NativeRatio
+		}
+		var peakMemory any
+		if r.Memory != nil {
+			peakMemory = int64(r.Memory.PeakBytes)
+		}
+		st := r.Stats
+		inserted, err := insertResult.Exec(sessionID, r.Task, string(params), r.Scale, r.Repetition, r.Toolchain, env.Commit, r.Module, r.Runtime, r.Language, r.Variant,
+			r.Hash, st.N, st.Outliers, st.Min, st.Median, st.Mean, st.Max, st.StdDev, st.CV, nativeRatio, peakMemory, r.Error)
+		if err != nil {
+			return err
+		}
+		resultID, err := inserted.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for flag, value := range r.BuildFlags {
+			if _, err := insertFlag.Exec(resultID, flag, value); err != nil {
+				return err
+			}
+		}
+		for run, ms := range r.SamplesMs {
+			var fuel any
+			if run < len(r.Fuel) {
+				fuel = int64(r.Fuel[run])
+			}
+			if _, err := insertRun.Exec(resultID, run, ms, fuel); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (h *sqliteHistory) Close() error {
+	return h.db.Close()
+}
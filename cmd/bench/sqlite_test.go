@@ -0,0 +1,144 @@
+//go:build sqlite
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRecordsHistory(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	sum := sha256.Sum256(fakeTask)
+	manifest := fmt.Sprintf(`{"artifacts": [{"file": "matrix_mul-o2.wasm", "flags": {"opt": "2", "gc": "conservative"}, "sha256": "%x"}]}`, sum)
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "manifest.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	db := filepath.Join(t.TempDir(), "history.db")
+	for _, commit := range []string{"aaa", "bbb"} {
+		var stdout, stderr bytes.Buffer
+		args := []string{"-history", db, "-commit", commit, "-warmup", "0", "-runs", "3", "-params", `{"dimension": 5}`, path}
+		if code := run(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("exit status %d: %s", code, stderr.String())
+		}
+	}
+
+	conn, err := sql.Open("sqlite3", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The same task and params across both commits
+	rows, err := conn.Query(`SELECT r.commit_id, r.hash, COUNT(runs.run) FROM results r JOIN runs ON runs.result_id = r.id
+		WHERE r.task = 'matrix_mul' AND json_extract(r.params, '$.dimension') = 5 GROUP BY r.id ORDER BY r.id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var commits []string
+	for rows.Next() {
+		var commit string
+		var hash uint32
+		var runs int
+		if err := rows.Scan(&commit, &hash, &runs); err != nil {
+			t.Fatal(err)
+		}
+		if hash != 15 || runs != 3 {
+			t.Errorf("commit %s: hash %d over %d runs, expected 15 over 3", commit, hash, runs)
+		}
+		commits = append(commits, commit)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0] != "aaa" || commits[1] != "bbb" {
+		t.Errorf("results of commits %v, expected one of each session", commits)
+	}
+
+	var sessions int
+	var wazero string
+	if err := conn.QueryRow(`SELECT COUNT(*), MIN(json_extract(environment, '$.runtimes.wazero')) FROM sessions`).Scan(&sessions, &wazero); err != nil {
+		t.Fatal(err)
+	}
+	if sessions != 2 || wazero == "" {
+		t.Errorf("%d sessions recording wazero %q, expected 2 with its version", sessions, wazero)
+	}
+
+	// Each result's build flags, for grouping by configuration
+	var flags int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM build_flags f JOIN results r ON r.id = f.result_id
+		WHERE f.flag = 'gc' AND f.value = 'conservative' AND r.task = 'matrix_mul'`).Scan(&flags); err != nil {
+		t.Fatal(err)
+	}
+	if flags != 2 {
+		t.Errorf("%d results built with -gc=conservative, expected both", flags)
+	}
+}
+
+// firstHistorySchema is the history schema before versioning, without the
+// plan, memory, environment and build flag columns later versions add
+const firstHistorySchema = `
+CREATE TABLE sessions (id INTEGER PRIMARY KEY, started TEXT NOT NULL, commit_id TEXT NOT NULL, go_version TEXT NOT NULL,
+	os TEXT NOT NULL, arch TEXT NOT NULL, cpus INTEGER NOT NULL, hostname TEXT NOT NULL);
+CREATE TABLE results (id INTEGER PRIMARY KEY, session_id INTEGER NOT NULL REFERENCES sessions(id), task TEXT NOT NULL,
+	params TEXT NOT NULL, toolchain TEXT NOT NULL, commit_id TEXT NOT NULL, module TEXT NOT NULL, runtime TEXT NOT NULL,
+	language TEXT NOT NULL, variant TEXT NOT NULL, hash INTEGER NOT NULL, runs INTEGER NOT NULL, outliers INTEGER NOT NULL,
+	min_ms REAL NOT NULL, median_ms REAL NOT NULL, mean_ms REAL NOT NULL, max_ms REAL NOT NULL, stddev_ms REAL NOT NULL,
+	cv REAL NOT NULL, native_ratio REAL, error TEXT NOT NULL);
+CREATE INDEX results_key ON results (task, params, toolchain, commit_id);
+CREATE TABLE runs (result_id INTEGER NOT NULL REFERENCES results(id), run INTEGER NOT NULL, time_ms REAL NOT NULL,
+	fuel INTEGER, PRIMARY KEY (result_id, run));
+INSERT INTO sessions VALUES (1, '2025-01-02T03:04:05Z', 'old', 'go1.24.0', 'linux', 'amd64', 8, 'host');
+INSERT INTO results VALUES (1, 1, 'matrix_mul', '{"dimension":5}', 'tinygo', 'old', 'matrix_mul-o2.wasm', 'wazero', 'tinygo',
+	'o2', 15, 3, 0, 1, 1, 1, 1, 0, 0, NULL, '');
+`
+
+func TestHistoryMigration(t *testing.T) {
+	db := filepath.Join(t.TempDir(), "history.db")
+	conn, err := sql.Open("sqlite3", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Exec(firstHistorySchema); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-migrate", "-history", db}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	if code := run([]string{"-history", db, "-warmup", "0", "-runs", "3", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("recording into the migrated history: exit status %d: %s", code, stderr.String())
+	}
+
+	var version, results int
+	var environment string
+	if err := conn.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM results WHERE scale = '' AND repetition = 0`).Scan(&results); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.QueryRow(`SELECT environment FROM sessions WHERE commit_id = 'old'`).Scan(&environment); err != nil {
+		t.Fatal(err)
+	}
+	if version != historyVersion || results != 2 || environment != "{}" {
+		t.Errorf("version %d with %d results, the old one's environment %q; expected %d with both results and {}", version, results, environment, historyVersion)
+	}
+
+	if _, err := conn.Exec(`PRAGMA user_version = 99`); err != nil {
+		t.Fatal(err)
+	}
+	if code := run([]string{"-migrate", "-history", db}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "newer than this bench's") {
+		t.Errorf("exit status %d for a newer database: %s", code, stderr.String())
+	}
+}
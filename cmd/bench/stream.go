@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// runStream is stdout with -stream: a JSON line per measured run as soon as
+// it completes, then each result as usual, marked by its record field, so a
+// consumer follows the session as it goes and a session that dies midway
+// leaves every run it finished. Writes from parallel modules are serialized.
+type runStream struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// runRecord is a -stream line of one measured run
+type runRecord struct {
+	Record     string    `json:"record"` // "run"
+	Time       time.Time `json:"time"`   // When the run completed
+	Module     string    `json:"module"`
+	Runtime    string    `json:"runtime"`
+	Agent      string    `json:"agent,omitempty"`
+	Task       string    `json:"task,omitempty"`
+	Scale      string    `json:"scale,omitempty"`
+	Repetition int       `json:"repetition,omitempty"`
+	Run        int       `json:"run"` // From 0, as in the CSV export
+	TimeMs     float64   `json:"time_ms"`
+	Hash       uint32    `json:"hash"`
+	// Each with the flag or runtime that measures it
+	Fuel         *uint64  `json:"fuel,omitempty"`
+	Instructions *uint64  `json:"instructions,omitempty"`
+	Cycles       *uint64  `json:"cycles,omitempty"`
+	BranchMisses *uint64  `json:"branch_misses,omitempty"`
+	CacheMisses  *uint64  `json:"cache_misses,omitempty"`
+	Joules       *float64 `json:"joules,omitempty"`
+	Mallocs      *uint64  `json:"mallocs,omitempty"`
+	AllocBytes   *uint64  `json:"alloc_bytes,omitempty"`
+	GCs          *uint64  `json:"gcs,omitempty"`
+}
+
+// resultRecord is a -stream line of a result
+type resultRecord struct {
+	Record string `json:"record"` // "result"
+	Result
+}
+
+func newRunStream(w io.Writer) *runStream {
+	return &runStream{encoder: json.NewEncoder(w)}
+}
+
+// run writes the latest of r's measured runs
+func (s *runStream) run(r *Result) error {
+	if s == nil {
+		return nil
+	}
+	i := len(r.SamplesMs) - 1
+	record := runRecord{Record: "run", Time: time.Now().UTC(), Module: r.Module, Runtime: r.Runtime, Task: r.Task, Scale: r.Scale,
+		Repetition: r.Repetition, Run: i, TimeMs: r.SamplesMs[i], Hash: r.Hash}
+	if i < len(r.Fuel) {
+		record.Fuel = &r.Fuel[i]
+	}
+	if p := r.Perf; p != nil && i < len(p.Instructions) {
+		record.Instructions, record.Cycles = &p.Instructions[i], &p.Cycles[i]
+		record.BranchMisses, record.CacheMisses = &p.BranchMisses[i], &p.CacheMisses[i]
+	}
+	if e := r.Energy; e != nil && i < len(e.Joules) {
+		record.Joules = &e.Joules[i]
+	}
+	if a := r.Allocations; a != nil && i < len(a.Bytes) {
+		record.Mallocs, record.AllocBytes, record.GCs = &a.Mallocs[i], &a.Bytes[i], &a.GCs[i]
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(record)
+}
+
+// forward writes a run another bench streamed, an agent's with -agents
+func (s *runStream) forward(record runRecord) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(record)
+}
+
+// result writes a finished result
+func (s *runStream) result(r Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder.Encode(resultRecord{"result", r})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRunStream(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-stream", "-warmup", "2", "-runs", "3", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	// A line per measured run, the warm-up aside, then the result
+	var records []string
+	lines := bufio.NewScanner(&stdout)
+	for lines.Scan() {
+		var line struct {
+			runRecord
+			Stats *json.RawMessage `json:"stats"`
+		}
+		if err := json.Unmarshal(lines.Bytes(), &line); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, line.Record)
+		switch {
+		case line.Record == "run" && (line.Module != path || line.Task != "matrix_mul" || line.Run != len(records)-1 || line.Hash != 15 || line.Time.IsZero()):
+			t.Errorf("run record %s", lines.Bytes())
+		case line.Record == "result" && line.Stats == nil:
+			t.Errorf("result record %s, expected the result", lines.Bytes())
+		}
+	}
+	if len(records) != 4 || records[0] != "run" || records[2] != "run" || records[3] != "result" {
+		t.Errorf("records %v, expected 3 runs and the result", records)
+	}
+}
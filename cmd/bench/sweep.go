@@ -0,0 +1,313 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"wasmbench/common"
+)
+
+// sweep is a -sweep: params fields set together to each of a range of sizes
+type sweep struct {
+	fields []string // width+height sweeps the pixels of a square image
+	values []uint64 // Ascending
+}
+
+// parseSweep parses fields=values, where fields are params fields joined by
+// "+" and values either list the sizes (64,128,256) or double from one size
+// up to another (64..512)
+func parseSweep(s string) (sweep, error) {
+	var sw sweep
+	names, values, ok := strings.Cut(s, "=")
+	if !ok || names == "" {
+		return sw, errors.New("-sweep is field=from..to or field=v1,v2,..., e.g. dimension=64..512")
+	}
+	sw.fields = strings.Split(names, "+")
+	if from, to, ok := strings.Cut(values, ".."); ok {
+		low, err := strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			return sw, fmt.Errorf("-sweep: %w", err)
+		}
+		high, err := strconv.ParseUint(to, 10, 64)
+		if err != nil {
+			return sw, fmt.Errorf("-sweep: %w", err)
+		}
+		for v := low; v > 0 && v <= high; v *= 2 {
+			sw.values = append(sw.values, v)
+		}
+	} else {
+		for _, value := range strings.Split(values, ",") {
+			v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return sw, fmt.Errorf("-sweep: %w", err)
+			}
+			sw.values = append(sw.values, v)
+		}
+		slices.Sort(sw.values)
+		sw.values = slices.Compact(sw.values)
+	}
+	if len(sw.values) < 2 || sw.values[0] == 0 {
+		return sw, errors.New("-sweep needs at least two distinct sizes above 0")
+	}
+	return sw, nil
+}
+
+// passes returns a pass per size, over the modules whose task has every
+// swept field. The sizes override the same fields of opts' params.
+func (sw sweep) passes(modules []string, opts options) ([]pass, error) {
+	var matching []string
+	for _, module := range modules {
+		task := opts.task
+		if task == "" {
+			task = taskFromFileName(module)
+		}
+		if spec, ok := tasks[task]; ok && sw.sweeps(spec) {
+			matching = append(matching, module)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("-sweep: no module's task has the params %s", strings.Join(sw.fields, " and "))
+	}
+
+	params := map[string]json.Number{}
+	if opts.params != "" {
+		decoder := json.NewDecoder(strings.NewReader(opts.params))
+		decoder.UseNumber()
+		if err := decoder.Decode(&params); err != nil {
+			return nil, fmt.Errorf("-params: %w", err)
+		}
+	}
+	var passes []pass
+	for _, v := range sw.values {
+		for _, name := range sw.fields {
+			params[name] = json.Number(strconv.FormatUint(v, 10))
+		}
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		p := pass{opts: opts, modules: matching}
+		p.opts.params = string(data)
+		passes = append(passes, p)
+	}
+	return passes, nil
+}
+
+// sweeps reports whether spec's task has every swept field
+func (sw sweep) sweeps(spec taskSpec) bool {
+	for _, name := range sw.fields {
+		if !slices.ContainsFunc(spec.fields, func(f common.ParamField) bool { return f.Name == name }) {
+			return false
+		}
+	}
+	return true
+}
+
+// size is the problem size a result ran at, the product of the swept fields,
+// or 0 if the result lacks one
+func (sw sweep) size(r Result) float64 {
+	n := 1.0
+	for _, name := range sw.fields {
+		v, err := r.Params[name].Float64()
+		if err != nil || v <= 0 {
+			return 0
+		}
+		n *= v
+	}
+	return n
+}
+
+// Scaling is the complexity fit of a -sweep session: how each module's
+// median time grows with the problem size, and where modules trade places
+type Scaling struct {
+	Fields     []string     `json:"fields"` // Swept params, the size being their product
+	Fits       []ScalingFit `json:"fits"`
+	Crossovers []Crossover  `json:"crossovers,omitempty"`
+}
+
+// ScalingFit is one module under one runtime fitted over the sizes it ran at.
+// The power law median ≈ coefficient × size^exponent is a least-squares line
+// in log-log space; model is whichever of the complexity classes fits best.
+type ScalingFit struct {
+	Task        string  `json:"task"`
+	Module      string  `json:"module"` // File name
+	Runtime     string  `json:"runtime"`
+	Language    string  `json:"language,omitempty"`
+	Points      int     `json:"points"`
+	MinSize     float64 `json:"min_size"`
+	MaxSize     float64 `json:"max_size"`
+	Exponent    float64 `json:"exponent"`
+	Coefficient float64 `json:"coefficient_ms"`
+	R2          float64 `json:"r2"` // Of the power law, in log-log space
+	Model       string  `json:"model"`
+}
+
+// label names the fit's module and runtime
+func (f ScalingFit) label() string {
+	return f.Module + " (" + f.Runtime + ")"
+}
+
+// Crossover is a size at which two modules of a task are equally fast
+// according to their fits: Below is faster at smaller sizes, Above at larger.
+// Extrapolated crossovers lie outside the swept sizes, within crossoverReach.
+type Crossover struct {
+	Task         string  `json:"task"`
+	Size         float64 `json:"size"`
+	Below        string  `json:"below"`
+	Above        string  `json:"above"`
+	Extrapolated bool    `json:"extrapolated,omitempty"`
+}
+
+// crossoverReach is how far past the swept sizes, as a factor, crossovers
+// are still reported
+const crossoverReach = 10
+
+// complexityModels are the candidate growth classes of a task's time
+var complexityModels = []struct {
+	name string
+	f    func(n float64) float64
+}{
+	{"n", func(n float64) float64 { return n }},
+	{"n log n", func(n float64) float64 { return n * math.Log2(max(n, 2)) }},
+	{"n²", func(n float64) float64 { return n * n }},
+	{"n³", func(n float64) float64 { return n * n * n }},
+}
+
+// fit fits the median times of the session's successful results, grouped by
+// module and runtime, against their sizes, and finds the crossovers between
+// the modules of each task. Groups that ran at fewer than two sizes are left
+// out.
+func (sw sweep) fit(results []Result) *Scaling {
+	type point struct{ size, ms float64 }
+	type group struct {
+		fit    ScalingFit
+		points []point
+	}
+	var groups []*group
+	byKey := map[string]*group{}
+	for _, r := range results {
+		size := sw.size(r)
+		if r.Error != "" || size == 0 || r.Stats.Median <= 0 {
+			continue
+		}
+		key := r.Task + "\x00" + r.Module + "\x00" + r.Runtime
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{fit: ScalingFit{Task: r.Task, Module: filepath.Base(r.Module), Runtime: r.Runtime, Language: r.Language}}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.points = append(g.points, point{size, r.Stats.Median})
+	}
+
+	s := &Scaling{Fields: sw.fields, Fits: []ScalingFit{}}
+	for _, g := range groups {
+		x := make([]float64, len(g.points))
+		y := make([]float64, len(g.points))
+		for i, p := range g.points {
+			x[i], y[i] = math.Log(p.size), math.Log(p.ms)
+		}
+		if slices.Min(x) == slices.Max(x) {
+			continue
+		}
+		f := g.fit
+		f.Points = len(g.points)
+		f.MinSize, f.MaxSize = math.Exp(slices.Min(x)), math.Exp(slices.Max(x))
+		var intercept float64
+		f.Exponent, intercept, f.R2 = linearFit(x, y)
+		f.Coefficient = math.Exp(intercept)
+		// Each model's coefficient is the geometric mean ratio of the times to
+		// the model, which minimizes the squared log residuals
+		best := math.Inf(1)
+		for _, model := range complexityModels {
+			var logC, residual float64
+			for i, p := range g.points {
+				logC += y[i] - math.Log(model.f(p.size))
+			}
+			logC /= float64(len(g.points))
+			for i, p := range g.points {
+				d := y[i] - logC - math.Log(model.f(p.size))
+				residual += d * d
+			}
+			if residual < best {
+				best, f.Model = residual, model.name
+			}
+		}
+		s.Fits = append(s.Fits, f)
+	}
+
+	for i, a := range s.Fits {
+		for _, b := range s.Fits[i+1:] {
+			if a.Task != b.Task || a.Exponent == b.Exponent {
+				continue
+			}
+			// a·n^p = b·n^q at n = (b/a)^(1/(p−q))
+			size := math.Pow(b.Coefficient/a.Coefficient, 1/(a.Exponent-b.Exponent))
+			low, high := min(a.MinSize, b.MinSize), max(a.MaxSize, b.MaxSize)
+			if math.IsNaN(size) || size < low/crossoverReach || size > high*crossoverReach {
+				continue
+			}
+			c := Crossover{Task: a.Task, Size: size, Below: a.label(), Above: b.label(), Extrapolated: size < low || size > high}
+			if a.Exponent < b.Exponent {
+				c.Below, c.Above = b.label(), a.label()
+			}
+			s.Crossovers = append(s.Crossovers, c)
+		}
+	}
+	slices.SortStableFunc(s.Crossovers, func(a, b Crossover) int {
+		return cmp.Or(cmp.Compare(a.Task, b.Task), cmp.Compare(a.Size, b.Size))
+	})
+	return s
+}
+
+// linearFit is the least-squares line y = slope·x + intercept, with its
+// coefficient of determination
+func linearFit(x, y []float64) (slope, intercept, r2 float64) {
+	n := float64(len(x))
+	var sx, sy, sxx, sxy, syy float64
+	for i := range x {
+		sx += x[i]
+		sy += y[i]
+		sxx += x[i] * x[i]
+		sxy += x[i] * y[i]
+		syy += y[i] * y[i]
+	}
+	vx, vy, cov := sxx-sx*sx/n, syy-sy*sy/n, sxy-sx*sy/n
+	slope = cov / vx
+	intercept = (sy - slope*sx) / n
+	r2 = 1
+	if vy > 0 {
+		r2 = cov * cov / (vx * vy)
+	}
+	return slope, intercept, r2
+}
+
+// write prints the fits and crossovers as a table
+func (s *Scaling) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "task\tmodule\truntime\tsizes\texponent\tr²\tbest fit\t\n")
+	for _, f := range s.Fits {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.2f\t%.3f\t%s\t\n", f.Task, f.Module, f.Runtime, f.Points, f.Exponent, f.R2, f.Model)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	size := strings.Join(s.Fields, "×")
+	for _, c := range s.Crossovers {
+		note := ""
+		if c.Extrapolated {
+			note = ", extrapolated"
+		}
+		fmt.Fprintf(w, "%s: %s is faster below %s %.4g, %s above it%s\n", c.Task, c.Below, size, c.Size, c.Above, note)
+	}
+	return nil
+}
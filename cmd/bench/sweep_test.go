@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseSweep(t *testing.T) {
+	for _, c := range []struct {
+		spec   string
+		fields []string
+		values []uint64
+	}{
+		{"dimension=64..512", []string{"dimension"}, []uint64{64, 128, 256, 512}},
+		{"dimension=64..500", []string{"dimension"}, []uint64{64, 128, 256}},
+		{"record_count=1000, 100,10000,100", []string{"record_count"}, []uint64{100, 1000, 10000}},
+		{"width+height=128..256", []string{"width", "height"}, []uint64{128, 256}},
+	} {
+		sw, err := parseSweep(c.spec)
+		if err != nil {
+			t.Errorf("%s: %v", c.spec, err)
+			continue
+		}
+		if !slices.Equal(sw.fields, c.fields) || !slices.Equal(sw.values, c.values) {
+			t.Errorf("%s: fields %v at %v, expected %v at %v", c.spec, sw.fields, sw.values, c.fields, c.values)
+		}
+	}
+	for _, spec := range []string{"dimension", "=1,2", "dimension=64", "dimension=64..100", "dimension=0..8", "dimension=a,b"} {
+		if _, err := parseSweep(spec); err == nil {
+			t.Errorf("%s: accepted", spec)
+		}
+	}
+}
+
+func TestSweepFit(t *testing.T) {
+	sw := sweep{fields: []string{"dimension"}, values: []uint64{50, 100, 200, 400}}
+	var results []Result
+	for _, v := range sw.values {
+		n := float64(v)
+		params := map[string]json.Number{"dimension": json.Number(strconv.FormatUint(v, 10))}
+		// The cubic module is faster below 100, the quadratic one above
+		for _, m := range []struct {
+			module string
+			ms     float64
+		}{{"cubic.wasm", 1e-6 * n * n * n}, {"quadratic.wasm", 1e-4 * n * n}} {
+			r := Result{Module: "builds/" + m.module, Runtime: "wazero", Task: "matrix_mul", Params: params}
+			r.Stats.Median = m.ms
+			results = append(results, r)
+		}
+	}
+	results = append(results, Result{Module: "failed.wasm", Runtime: "wazero", Task: "matrix_mul", Error: "trap"})
+
+	s := sw.fit(results)
+	if len(s.Fits) != 2 {
+		t.Fatalf("%d fits, expected the two modules'", len(s.Fits))
+	}
+	for i, expected := range []struct {
+		exponent float64
+		model    string
+	}{{3, "n³"}, {2, "n²"}} {
+		f := s.Fits[i]
+		if math.Abs(f.Exponent-expected.exponent) > 1e-9 || math.Abs(f.R2-1) > 1e-9 || f.Model != expected.model || f.Points != 4 {
+			t.Errorf("%s: exponent %v (r² %v, %s over %d sizes), expected %v (%s)", f.Module, f.Exponent, f.R2, f.Model, f.Points, expected.exponent, expected.model)
+		}
+	}
+	if len(s.Crossovers) != 1 {
+		t.Fatalf("crossovers %+v, expected one", s.Crossovers)
+	}
+	if c := s.Crossovers[0]; math.Abs(c.Size-100) > 1e-6 || c.Below != "cubic.wasm (wazero)" || c.Above != "quadratic.wasm (wazero)" || c.Extrapolated {
+		t.Errorf("crossover %+v, expected cubic.wasm faster below 100", c)
+	}
+
+	var out bytes.Buffer
+	if err := s.write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "cubic.wasm (wazero) is faster below dimension 100") {
+		t.Errorf("table does not name the crossover:\n%s", out.String())
+	}
+}
+
+func TestRunSweep(t *testing.T) {
+	dir := t.TempDir()
+	matrix := filepath.Join(dir, "matrix_mul-o2.wasm")
+	mandelbrot := filepath.Join(dir, "mandelbrot-o2.wasm")
+	for _, path := range []string{matrix, mandelbrot} {
+		if err := os.WriteFile(path, fakeTask, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jsonPath := filepath.Join(dir, "session.json")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"-warmup", "0", "-runs", "3", "-sweep", "dimension=4..16", "-params", `{"seed": 7}`, "-json", jsonPath, matrix, mandelbrot}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	var dimensions []string
+	for line := range strings.Lines(stdout.String()) {
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.Params["seed"] != "7" {
+			t.Errorf("params %v lost -params' seed", result.Params)
+		}
+		dimensions = append(dimensions, string(result.Params["dimension"]))
+	}
+	// The mandelbrot module has no dimension, so only matrix_mul is swept
+	if !slices.Equal(dimensions, []string{"4", "8", "16"}) {
+		t.Errorf("ran at dimensions %v, expected 4, 8 and 16", dimensions)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.Scaling == nil || !slices.Equal(session.Scaling.Fields, []string{"dimension"}) {
+		t.Errorf("session scaling %+v, expected the dimension sweep's fits", session.Scaling)
+	}
+	if !strings.Contains(stderr.String(), "exponent") {
+		t.Errorf("stderr %q has no fit table", stderr.String())
+	}
+
+	for _, args := range [][]string{{"-sweep", "dimension=64"}, {"-sweep", "dimension=64..128", "-determinism", "2"}, {"-sweep", "max_iter=10..20", matrix}} {
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("bench %v: exit status %d, expected 2", args, code)
+		}
+	}
+}
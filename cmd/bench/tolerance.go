@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// ToleranceCheck is -tolerance's second look at a float task's result that
+// missed the reference hash: the output of one more run, compared element by
+// element with the Go implementation's. Float rounding that differs between
+// compilers changes the hash of a correct result; the comparison tells it
+// from a wrong one.
+type ToleranceCheck struct {
+	Tolerance string  `json:"tolerance"` // As ParseTolerance reads it
+	Elements  int     `json:"elements"`  // Of the Go implementation's output
+	Beyond    int     `json:"beyond"`    // Elements outside the tolerance
+	MaxULPs   uint32  `json:"max_ulps"`
+	MaxAbs    float64 `json:"max_abs"`
+	Error     string  `json:"error,omitempty"` // Why the outputs were not compared
+	first     string  // The first element beyond, for the result's error
+}
+
+// within reports whether the outputs were compared and every element was
+// within the tolerance
+func (c *ToleranceCheck) within() bool {
+	return c != nil && c.Error == "" && c.Beyond == 0
+}
+
+// checkTolerance compares the output of a run of m with native Go's when r's
+// runs missed the reference hash, leaving the verification's Tolerance for
+// verificationError to judge. The run is untimed and after the measured ones.
+func (r *Result) checkTolerance(ctx context.Context, m *module, paramsPtr uint32, opts options) error {
+	v := r.Verification
+	if v == nil || v.Mismatches == 0 {
+		return nil
+	}
+	check := &ToleranceCheck{Tolerance: opts.tolerance.String()}
+	v.Tolerance = check
+	if !m.exports("set_output") || !m.exports("get_output") {
+		check.Error = "the module does not export set_output and get_output"
+		return nil
+	}
+	got, err := m.output(ctx, paramsPtr)
+	if err != nil {
+		return err
+	}
+	params, err := buildParams(tasks[r.Task], opts.params)
+	if err != nil {
+		return err
+	}
+	want, err := nativeOutput(r.Task, params)
+	if err != nil {
+		return err
+	}
+
+	gotValues, wantValues := common.Float32sLE(got), common.Float32sLE(want)
+	c := opts.tolerance.CompareFloat32s(gotValues, wantValues)
+	check.Elements, check.Beyond, check.MaxULPs = c.Elements, c.Beyond, c.MaxULPs
+	// JSON has no infinity; a NaN or infinite element is beyond any tolerance anyway
+	check.MaxAbs = min(c.MaxAbs, math.MaxFloat64)
+	switch {
+	case c.Within():
+	case c.First >= len(gotValues) || c.First >= len(wantValues):
+		check.first = fmt.Sprintf("the output has %d elements, expected %d", len(gotValues), len(wantValues))
+	default:
+		check.first = fmt.Sprintf("the first is element %d, %v, expected %v", c.First, c.FirstGot, c.FirstWant)
+	}
+	return nil
+}
+
+// output runs the task once more with set_output on, and returns a copy of
+// the run's get_output values
+func (m *module) output(ctx context.Context, paramsPtr uint32) ([]byte, error) {
+	if _, err := m.call(ctx, "set_output", 1); err != nil {
+		return nil, err
+	}
+	if _, err := m.runTask(ctx, paramsPtr); err != nil {
+		return nil, err
+	}
+	// {u32 ptr, u32 len, u32 element size}
+	ptr, err := m.call(ctx, "get_output")
+	if err != nil {
+		return nil, err
+	}
+	block, ok := m.readMemory(ptr, 12)
+	if !ok {
+		return nil, errors.New("get_output points outside memory")
+	}
+	data, ok := m.readMemory(binary.LittleEndian.Uint32(block), binary.LittleEndian.Uint32(block[4:]))
+	if !ok {
+		return nil, errors.New("get_output's values are outside memory")
+	}
+	data = bytes.Clone(data)
+	if _, err := m.call(ctx, "set_output", 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// nativeOutput runs task's Go implementation once with the raw params and
+// returns a copy of the run's output
+func nativeOutput(task string, params []byte) ([]byte, error) {
+	native := tasks[task].native
+	if native.runTask == nil {
+		return nil, fmt.Errorf("task %s has no native implementation to compare with", task)
+	}
+	nativeMu.Lock()
+	defer nativeMu.Unlock()
+	native.init(initSeed)
+	common.EnableOutput(true)
+	defer common.EnableOutput(false)
+	if status := native.runTask(uintptr(unsafe.Pointer(&params[0])), uintptr(unsafe.Pointer(&nativeResult))); status != common.StatusOK {
+		return nil, fmt.Errorf("the Go implementation failed (status %d): %s", status, common.LastError())
+	}
+	data, _ := common.RunOutput()
+	return bytes.Clone(data), nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"wasmbench/common"
+)
+
+// outputTask is a matrix_mul build in miniature whose product rounds
+// differently: fakeTask's exports, with run_task always hashing 7, plus a
+// set_output that does nothing and a get_output block at 2048 pointing at
+// values, fewer than 32 of them, at 3072
+func outputTask(values []float32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return slices.Concat([]byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		// Types: (i32) -> (), (i32) -> i32, () -> i32
+		0x01, 0x0e, 0x03, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f, 0x60, 0x00, 0x01, 0x7f,
+		// Functions: init, alloc, run_task, set_output, get_output
+		0x03, 0x06, 0x05, 0x00, 0x01, 0x01, 0x00, 0x02,
+		// Memory: 1 page
+		0x05, 0x03, 0x01, 0x00, 0x01,
+		// Exports: memory, init, alloc, run_task, set_output, get_output
+		0x07, 0x3e, 0x06,
+		0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+		0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+		0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+		0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+		0x0a, 's', 'e', 't', '_', 'o', 'u', 't', 'p', 'u', 't', 0x00, 0x03,
+		0x0a, 'g', 'e', 't', '_', 'o', 'u', 't', 'p', 'u', 't', 0x00, 0x04,
+		// Code
+		0x0a, 0x18, 0x05,
+		0x02, 0x00, 0x0b, // init: nop
+		0x05, 0x00, 0x41, 0x80, 0x08, 0x0b, // alloc: i32.const 1024
+		0x04, 0x00, 0x41, 0x07, 0x0b, // run_task: i32.const 7
+		0x02, 0x00, 0x0b, // set_output: nop
+		0x05, 0x00, 0x41, 0x80, 0x10, 0x0b, // get_output: i32.const 2048
+		// Data: the output block at 2048, the values at 3072
+		0x0b, byte(1 + 18 + 6 + len(data)), 0x02,
+		0x00, 0x41, 0x80, 0x10, 0x0b, 0x0c,
+		// {u32 ptr, u32 len, u32 element size}
+		0x00, 0x0c, 0x00, 0x00, byte(len(data)), 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00,
+		0x00, 0x41, 0x80, 0x18, 0x0b, byte(len(data)),
+	}, data)
+}
+
+func TestRunTolerance(t *testing.T) {
+	refs := t.TempDir()
+	vectors := `[{"name": "two", "params": {"dimension": 2}, "expected_hash": 99}]`
+	if err := os.WriteFile(filepath.Join(refs, "matrix_mul.json"), []byte(vectors), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	params, err := buildParams(tasks["matrix_mul"], `{"dimension": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := nativeOutput("matrix_mul", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := common.Float32sLE(output)
+	if len(product) != 4 {
+		t.Fatalf("The Go implementation's 2×2 product has %d elements", len(product))
+	}
+	rounded := slices.Clone(product)
+	rounded[1] = math.Nextafter32(rounded[1], float32(math.Inf(1)))
+	wrong := slices.Clone(product)
+	wrong[2]++
+
+	for _, c := range []struct {
+		name      string
+		wasm      []byte
+		tolerance string
+		status    int
+		check     *ToleranceCheck
+		stderr    string
+	}{
+		{"identical", outputTask(product), "ulps=0", 0, &ToleranceCheck{Tolerance: "ulps=0,abs=0", Elements: 4}, "within ulps=0,abs=0 of native Go's"},
+		{"rounded", outputTask(rounded), "ulps=1", 0, &ToleranceCheck{Tolerance: "ulps=1,abs=0", Elements: 4, MaxULPs: 1}, "at most 1 ULPs off"},
+		{"rounded exactly", outputTask(rounded), "", 1, nil, "3 of 3 runs did not hash 99, reference vector two's"},
+		{"wrong", outputTask(wrong), "ulps=64,abs=1e-4", 1, &ToleranceCheck{Tolerance: "ulps=64,abs=0.0001", Elements: 4, Beyond: 1},
+			"1 of 4 output elements are beyond ulps=64,abs=0.0001 of native Go's, the first is element 2"},
+		{"short", outputTask(product[:3]), "ulps=64", 1, &ToleranceCheck{Tolerance: "ulps=64,abs=0", Elements: 4, Beyond: 1},
+			"the output has 3 elements, expected 4"},
+		{"no output", fakeTask, "ulps=64", 1, &ToleranceCheck{Tolerance: "ulps=64,abs=0", Error: "the module does not export set_output and get_output"},
+			"-tolerance could not compare the output"},
+	} {
+		path := writeModule(t, "matrix_mul-o2.wasm", c.wasm)
+		args := []string{"-warmup", "0", "-runs", "3", "-verify", refs, "-params", `{"dimension": 2}`}
+		if c.tolerance != "" {
+			args = append(args, "-tolerance", c.tolerance)
+		}
+		var stdout, stderr bytes.Buffer
+		if code := run(append(args, path), &stdout, &stderr); code != c.status {
+			t.Errorf("%s: exit status %d, expected %d: %s", c.name, code, c.status, stderr.String())
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		v := result.Verification
+		if v == nil || v.Mismatches != 3 {
+			t.Fatalf("%s: verification %+v, expected 3 runs missing", c.name, v)
+		}
+		got := v.Tolerance
+		if got != nil {
+			// The largest difference is checked apart from the fields that are exact
+			got = &ToleranceCheck{Tolerance: got.Tolerance, Elements: got.Elements, Beyond: got.Beyond, MaxULPs: got.MaxULPs, Error: got.Error}
+			if c.name == "wrong" {
+				got.MaxULPs = 0
+			}
+		}
+		if (got == nil) != (c.check == nil) || got != nil && *got != *c.check {
+			t.Errorf("%s: tolerance check %+v, expected %+v", c.name, got, c.check)
+		}
+		if !strings.Contains(stderr.String(), c.stderr) {
+			t.Errorf("%s: stderr %q, expected %q", c.name, stderr.String(), c.stderr)
+		}
+	}
+}
+
+func TestRunToleranceUsage(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+	for _, args := range [][]string{
+		{"-tolerance", "ulps=4"},
+		{"-verify", t.TempDir(), "-tolerance", "ulps=x"},
+		{"-verify", t.TempDir(), "-tolerance", "rel=0.1"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := run(append(args, path), &stdout, &stderr); code != 2 || !strings.Contains(stderr.String(), "-tolerance") {
+			t.Errorf("%v: exit status %d, expected 2 with a usage error: %s", args, code, stderr.String())
+		}
+	}
+}
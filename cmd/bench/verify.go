@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Verification is a -verify result's check of its runs against the reference
+// vectors cmd/genrefs writes
+type Verification struct {
+	Vector       string `json:"vector,omitempty"` // The vector with the run's params; without one the runs are unverified
+	ExpectedHash uint32 `json:"expected_hash,omitempty"`
+	Runs         int    `json:"runs"`       // Checked, measured or -determinism runs
+	Mismatches   int    `json:"mismatches"` // Runs that did not hash ExpectedHash
+	// -tolerance's comparison of the output, for a float task's runs that
+	// missed ExpectedHash
+	Tolerance *ToleranceCheck `json:"tolerance,omitempty"`
+}
+
+// referenceVector is the part of a data/reference_hashes vector -verify reads
+type referenceVector struct {
+	Name           string          `json:"name"`
+	Params         json.RawMessage `json:"params"`
+	ExpectedHash   uint32          `json:"expected_hash"`
+	ExpectedStatus uint32          `json:"expected_status"`
+}
+
+// references are the reference vectors the tasks succeed on, by task, each
+// with its raw params struct
+type references map[string][]reference
+
+type reference struct {
+	referenceVector
+	params []byte
+}
+
+// loadReferences reads <task>.json in dir for every task, and adds the
+// vectors of third-party tasks' manifests. A task without any vectors has its
+// runs unverified.
+func loadReferences(dir string) (references, error) {
+	refs := references{}
+	for task, spec := range tasks {
+		path := filepath.Join(dir, task+".json")
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		var vectors []referenceVector
+		if err == nil {
+			if err := json.Unmarshal(data, &vectors); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		// Checked when the manifest was loaded
+		vectors = append(vectors, spec.vectors...)
+		for _, v := range vectors {
+			if v.ExpectedStatus != 0 {
+				continue
+			}
+			params, err := buildParams(spec, string(v.Params))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, v.Name, err)
+			}
+			refs[task] = append(refs[task], reference{v, params})
+		}
+	}
+	return refs, nil
+}
+
+// match returns the verification of a run of task with the raw params: the
+// first vector whose params, defaults filled in, are the same struct. It is
+// nil without -verify.
+func (refs references) match(task string, params []byte) *Verification {
+	if refs == nil {
+		return nil
+	}
+	for _, ref := range refs[task] {
+		if bytes.Equal(ref.params, params) {
+			return &Verification{Vector: ref.Name, ExpectedHash: ref.ExpectedHash}
+		}
+	}
+	return &Verification{}
+}
+
+// verify counts a run that hashed hash against r's reference vector
+func (r *Result) verify(hash uint32) {
+	if v := r.Verification; v != nil && v.Vector != "" {
+		v.Runs++
+		if hash != v.ExpectedHash {
+			v.Mismatches++
+		}
+	}
+}
+
+// verificationError fails r if any run it verified missed the reference
+// hash, unless -tolerance found its output within the tolerance
+func (r *Result) verificationError() error {
+	v := r.Verification
+	if v == nil || v.Mismatches == 0 || v.Tolerance.within() {
+		return nil
+	}
+	message := fmt.Sprintf("%d of %d runs did not hash %d, reference vector %s's", v.Mismatches, v.Runs, v.ExpectedHash, v.Vector)
+	switch c := v.Tolerance; {
+	case c == nil:
+	case c.Error != "":
+		message += "; -tolerance could not compare the output: " + c.Error
+	default:
+		message += fmt.Sprintf("; %d of %d output elements are beyond %s of native Go's, %s", c.Beyond, c.Elements, c.Tolerance, c.first)
+	}
+	return errors.New(message)
+}
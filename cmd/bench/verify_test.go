@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunVerify(t *testing.T) {
+	refs := t.TempDir()
+	// fakeTask hashes 3 times the dimension; the seed defaults to 12345
+	vectors := `[
+		{"name": "five", "params": {"dimension": 5, "seed": 12345}, "expected_hash": 15},
+		{"name": "six", "params": {"dimension": 6}, "expected_hash": 99},
+		{"name": "rejected", "params": {"dimension": 7}, "expected_hash": 0, "expected_status": 1}
+	]`
+	if err := os.WriteFile(filepath.Join(refs, "matrix_mul.json"), []byte(vectors), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	for _, c := range []struct {
+		args         []string
+		status       int
+		vector       string
+		runs, misses int
+		stderr       string
+	}{
+		{[]string{"-params", `{"dimension": 5}`}, 0, "five", 3, 0, ""},
+		{[]string{"-params", `{"dimension": 6}`}, 1, "six", 3, 3, "3 of 3 runs did not hash 99, reference vector six's"},
+		{[]string{"-params", `{"dimension": 7}`}, 0, "", 0, 0, "unverified"},
+		{[]string{"-params", `{"dimension": 6}`, "-determinism", "2"}, 1, "six", 2, 2, "2 of 2 runs did not hash 99"},
+	} {
+		var stdout, stderr bytes.Buffer
+		args := append([]string{"-warmup", "0", "-runs", "3", "-verify", refs}, append(c.args, path)...)
+		if code := run(args, &stdout, &stderr); code != c.status {
+			t.Errorf("%v: exit status %d, expected %d: %s", c.args, code, c.status, stderr.String())
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		v := result.Verification
+		if v == nil || v.Vector != c.vector || v.Runs != c.runs || v.Mismatches != c.misses {
+			t.Errorf("%v: verification %+v, expected vector %q with %d of %d runs missing", c.args, v, c.vector, c.misses, c.runs)
+		}
+		if !strings.Contains(stderr.String(), c.stderr) {
+			t.Errorf("%v: stderr %q, expected %q", c.args, stderr.String(), c.stderr)
+		}
+		// A mismatch fails the module but keeps its times
+		if c.misses > 0 && c.runs == 3 && result.Stats.N != 3 {
+			t.Errorf("%v: stats %+v, expected the 3 runs", c.args, result.Stats)
+		}
+	}
+}
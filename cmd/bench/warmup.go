@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"wasmbench/stats"
+)
+
+// warmupWindow is the number of latest warm-up runs whose coefficient of
+// variation decides, with -warmup-cv, that the runs have reached a steady state
+const warmupWindow = 10
+
+// warmUp runs the task before the measured runs: opts.warmupRuns times, or
+// with opts.warmupCV at least that many and then until the CV of the last
+// warmupWindow run times drops below it, up to opts.maxWarmupRuns. The first
+// runs pay for compilation, page faults and cold caches; a run count that is
+// enough for one module and size is not for another. r.WarmupRuns records the
+// runs made, and r.Unsteady a warm-up that hit the cap first.
+func (r *Result) warmUp(opts options, runTask func() (uint32, error)) error {
+	var times []float64
+	runs := opts.warmupRuns
+	if opts.warmupCV > 0 {
+		runs = 0 // Until steady
+	}
+	r.progress.setPhase(r.Task, "warm-up", runs)
+	for run := 0; ; run++ {
+		if run >= opts.warmupRuns {
+			if opts.warmupCV == 0 {
+				break
+			}
+			if len(times) >= warmupWindow {
+				r.WarmupCV = stats.CV(times[len(times)-warmupWindow:])
+				if r.WarmupCV < opts.warmupCV {
+					break
+				}
+			}
+			if run >= opts.maxWarmupRuns {
+				r.Unsteady = true
+				fmt.Fprintf(opts.log, "bench: %s: no steady state after %d warm-up runs (CV %.3f)\n", r.Module, run, r.WarmupCV)
+				break
+			}
+		}
+		start := time.Now()
+		if _, err := runTask(); err != nil {
+			return err
+		}
+		times = append(times, float64(time.Since(start))/float64(time.Millisecond))
+		r.progress.run(times[len(times)-1])
+	}
+	r.WarmupRuns = len(times)
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// sleeper returns a run_task that sleeps for each of durations in turn,
+// repeating the last one
+func sleeper(durations ...time.Duration) func() (uint32, error) {
+	run := 0
+	return func() (uint32, error) {
+		time.Sleep(durations[min(run, len(durations)-1)])
+		run++
+		return 0, nil
+	}
+}
+
+func TestWarmUpFixed(t *testing.T) {
+	var r Result
+	if err := r.warmUp(options{warmupRuns: 3, log: io.Discard}, sleeper(0)); err != nil {
+		t.Fatal(err)
+	}
+	if r.WarmupRuns != 3 || r.WarmupCV != 0 {
+		t.Errorf("%d warm-up runs at CV %v, expected 3 without a CV", r.WarmupRuns, r.WarmupCV)
+	}
+}
+
+func TestWarmUpUntilSteady(t *testing.T) {
+	slow, fast := 30*time.Millisecond, time.Millisecond
+	var r Result
+	opts := options{warmupCV: 0.5, maxWarmupRuns: 100, log: io.Discard}
+	if err := r.warmUp(opts, sleeper(slow, slow, slow, fast)); err != nil {
+		t.Fatal(err)
+	}
+	// The window is only steady once the slow runs have left it
+	if r.WarmupRuns < 3+warmupWindow || r.Unsteady || r.WarmupCV >= 0.5 {
+		t.Errorf("%d warm-up runs at CV %v (unsteady %v), expected the slow runs to be left behind", r.WarmupRuns, r.WarmupCV, r.Unsteady)
+	}
+}
+
+func TestWarmUpCap(t *testing.T) {
+	var durations []time.Duration
+	for i := 0; i < 40; i++ {
+		durations = append(durations, time.Duration(1+i%2*19)*time.Millisecond)
+	}
+	var r Result
+	opts := options{warmupRuns: 2, warmupCV: 0.1, maxWarmupRuns: 20, log: io.Discard}
+	if err := r.warmUp(opts, sleeper(durations...)); err != nil {
+		t.Fatal(err)
+	}
+	if r.WarmupRuns != 20 || !r.Unsteady {
+		t.Errorf("%d warm-up runs (unsteady %v), expected to stop unsteady at the cap of 20", r.WarmupRuns, r.Unsteady)
+	}
+}
@@ -0,0 +1,190 @@
+//go:build wasmtime
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go/v48"
+)
+
+func init() {
+	runtimes["wasmtime"] = instantiateWasmtime
+}
+
+// wasmtimeFuel is the fuel each store starts with, more than any run consumes,
+// so fuel only meters the runs and never stops them
+const wasmtimeFuel = math.MaxInt64
+
+// wasmtimeInstance is a module instantiated in its own fuel-metered wasmtime store
+type wasmtimeInstance struct {
+	store    *wasmtime.Store
+	instance *wasmtime.Instance
+}
+
+// instantiateWasmtime compiles and instantiates a task module under
+// wasmtime-go with fuel metering. WASI output is not captured.
+func instantiateWasmtime(ctx context.Context, wasm []byte, log io.Writer) (instance, error) {
+	config := wasmtime.NewConfig()
+	config.SetConsumeFuel(true)
+	engine := wasmtime.NewEngineWithConfig(config)
+	compiled, err := wasmtime.NewModule(engine, wasm)
+	if err != nil {
+		return nil, err
+	}
+	for _, imported := range compiled.Imports() {
+		if name := imported.Name(); imported.Module() == "gojs" && name != nil && *name == "runtime.wasmExit" {
+			return nil, errGoJS
+		}
+	}
+
+	store := wasmtime.NewStore(engine)
+	store.SetWasi(wasmtime.NewWasiConfig())
+	if err := store.SetFuel(wasmtimeFuel); err != nil {
+		store.Close()
+		return nil, err
+	}
+	linker := wasmtime.NewLinker(engine)
+	if err := defineWasmtimeHost(linker, log); err != nil {
+		store.Close()
+		return nil, err
+	}
+	instance, err := linker.Instantiate(store, compiled)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	w := &wasmtimeInstance{store, instance}
+	if w.exports("_initialize") {
+		if _, err := w.call(ctx, "_initialize"); err != nil {
+			store.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// defineWasmtimeHost provides the host modules task modules import, the same
+// functions the wazero runtime provides
+func defineWasmtimeHost(linker *wasmtime.Linker, log io.Writer) error {
+	if err := linker.DefineWasi(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	// Host clock for run_task_timed, and TinyGo's runtime ticks
+	nowMs := func() float64 {
+		return float64(time.Since(start)) / float64(time.Millisecond)
+	}
+	// Progress of long runs; the runner has no display and no deadline
+	reportProgress := func(permille int32) {}
+	// The runner supplies no host data, so runs with the host generator trap
+	nextRandom := func() (int32, *wasmtime.Trap) {
+		return 0, wasmtime.NewTrap("env.next_random: the runner supplies no host random data")
+	}
+	// Leveled debug log of modules built with -tags debuglog
+	logMessage := func(caller *wasmtime.Caller, ptr, length int32) {
+		memory := caller.GetExport("memory")
+		if memory == nil || memory.Memory() == nil {
+			return
+		}
+		data := memory.Memory().UnsafeData(caller)
+		if end := uint64(uint32(ptr)) + uint64(uint32(length)); end <= uint64(len(data)) {
+			fmt.Fprintf(log, "%s\n", data[uint32(ptr):end])
+		}
+	}
+
+	for _, host := range []struct {
+		module, name string
+		fn           any
+	}{
+		{"env", "now_ms", nowMs},
+		{"env", "report_progress", reportProgress},
+		{"env", "next_random", nextRandom},
+		{"env", "log", logMessage},
+		{"gojs", "runtime.ticks", nowMs},
+		{"gojs", "runtime.sleepTicks", func(ms float64) {}},
+	} {
+		if err := linker.FuncWrap(host.module, host.name, host.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wasmtimeInstance) exports(name string) bool {
+	return w.instance.GetFunc(w.store, name) != nil
+}
+
+func (w *wasmtimeInstance) call(ctx context.Context, name string, params ...uint32) (uint32, error) {
+	fn := w.instance.GetFunc(w.store, name)
+	if fn == nil {
+		return 0, fmt.Errorf("missing export %s", name)
+	}
+	args := make([]any, len(params))
+	for i, param := range params {
+		args[i] = int32(param)
+	}
+	result, err := fn.Call(w.store, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	switch result := result.(type) {
+	case nil:
+		return 0, nil
+	case int32:
+		return uint32(result), nil
+	default:
+		return 0, fmt.Errorf("%s returned %T, expected an i32", name, result)
+	}
+}
+
+// memory returns the module's exported linear memory. The slice is only valid
+// until the module next runs, which may grow the memory.
+func (w *wasmtimeInstance) memory() []byte {
+	export := w.instance.GetExport(w.store, "memory")
+	if export == nil || export.Memory() == nil {
+		return nil
+	}
+	return export.Memory().UnsafeData(w.store)
+}
+
+func (w *wasmtimeInstance) memorySize() uint64 {
+	return uint64(len(w.memory()))
+}
+
+func (w *wasmtimeInstance) readMemory(ptr, length uint32) ([]byte, bool) {
+	memory := w.memory()
+	if uint64(ptr)+uint64(length) > uint64(len(memory)) {
+		return nil, false
+	}
+	return append([]byte(nil), memory[ptr:ptr+length]...), true
+}
+
+func (w *wasmtimeInstance) writeMemory(ptr uint32, data []byte) bool {
+	memory := w.memory()
+	if uint64(ptr)+uint64(len(data)) > uint64(len(memory)) {
+		return false
+	}
+	copy(memory[ptr:], data)
+	return true
+}
+
+// fuel returns the fuel consumed since instantiation. Fuel counts executed
+// wasm operators, so it is the same on every host for the same run.
+func (w *wasmtimeInstance) fuel() (uint64, error) {
+	remaining, err := w.store.GetFuel()
+	if err != nil {
+		return 0, err
+	}
+	return wasmtimeFuel - remaining, nil
+}
+
+func (w *wasmtimeInstance) close(ctx context.Context) error {
+	w.store.Close()
+	return nil
+}
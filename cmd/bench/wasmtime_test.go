@@ -0,0 +1,30 @@
+//go:build wasmtime
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWasmtimeReportsFuel(t *testing.T) {
+	path := writeModule(t, "matrix_mul-o2.wasm", fakeTask)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-runtime", "wasmtime", "-warmup", "1", "-runs", "3", "-params", `{"dimension": 5}`, path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+
+	var result Result
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a JSON result: %v\n%s", err, stdout.String())
+	}
+	if result.Hash != 15 {
+		t.Errorf("hash = %d, expected 15", result.Hash)
+	}
+	// The same run executes the same operators, so its fuel never varies
+	if len(result.Fuel) != 3 || result.Fuel[0] == 0 || result.Fuel[1] != result.Fuel[0] || result.Fuel[2] != result.Fuel[0] {
+		t.Errorf("fuel = %v, expected 3 equal nonzero runs", result.Fuel)
+	}
+}
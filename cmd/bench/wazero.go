@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// wazeroInstance is a module instantiated in its own wazero runtime
+type wazeroInstance struct {
+	runtime wazero.Runtime
+	module  api.Module
+}
+
+// instantiateWazero compiles and instantiates a task module under wazero, the
+// default runtime. When ctx has a deadline, the module is closed once it
+// passes, ending any call in progress; without one, the compiled code skips
+// the checks that needs.
+func instantiateWazero(ctx context.Context, wasm []byte, log io.Writer) (instance, error) {
+	_, deadline := ctx.Deadline()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(deadline))
+	compiled, err := runtime.CompileModule(ctx, wasm)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	for _, fn := range compiled.ImportedFunctions() {
+		if moduleName, name, _ := fn.Import(); moduleName == "gojs" && name == "runtime.wasmExit" {
+			runtime.Close(ctx)
+			return nil, errGoJS
+		}
+	}
+
+	if err := instantiateWazeroHost(ctx, runtime, log); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	config := wazero.NewModuleConfig().
+		WithStartFunctions("_initialize").
+		WithStdout(log).
+		WithStderr(log).
+		WithSysNanotime().
+		WithSysWalltime()
+	module, err := runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	return &wazeroInstance{runtime, module}, nil
+}
+
+// instantiateWazeroHost provides the host modules task modules import
+func instantiateWazeroHost(ctx context.Context, runtime wazero.Runtime, log io.Writer) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	// Host clock for run_task_timed, and TinyGo's runtime ticks
+	nowMs := func() float64 {
+		return float64(time.Since(start)) / float64(time.Millisecond)
+	}
+	// Progress of long runs; the runner has no display, and its -timeout
+	// watchdog writes the cancellation flag itself
+	reportProgress := func(permille uint32) {}
+	// The runner supplies no host data, so runs with the host generator trap
+	nextRandom := func() uint32 {
+		panic("env.next_random: the runner supplies no host random data")
+	}
+	// Leveled debug log of modules built with -tags debuglog
+	logMessage := func(ctx context.Context, m api.Module, ptr, length uint32) {
+		if message, ok := m.Memory().Read(ptr, length); ok {
+			fmt.Fprintf(log, "%s: %s\n", m.Name(), message)
+		}
+	}
+
+	_, err := runtime.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(nowMs).Export("now_ms").
+		NewFunctionBuilder().WithFunc(reportProgress).Export("report_progress").
+		NewFunctionBuilder().WithFunc(nextRandom).Export("next_random").
+		NewFunctionBuilder().WithFunc(logMessage).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = runtime.NewHostModuleBuilder("gojs").
+		NewFunctionBuilder().WithFunc(nowMs).Export("runtime.ticks").
+		NewFunctionBuilder().WithFunc(func(ms float64) {}).Export("runtime.sleepTicks").
+		Instantiate(ctx)
+	return err
+}
+
+func (w *wazeroInstance) exports(name string) bool {
+	return w.module.ExportedFunction(name) != nil
+}
+
+func (w *wazeroInstance) call(ctx context.Context, name string, params ...uint32) (uint32, error) {
+	fn := w.module.ExportedFunction(name)
+	if fn == nil {
+		return 0, fmt.Errorf("missing export %s", name)
+	}
+	args := make([]uint64, len(params))
+	for i, param := range params {
+		args[i] = api.EncodeU32(param)
+	}
+	results, err := fn.Call(ctx, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return api.DecodeU32(results[0]), nil
+}
+
+func (w *wazeroInstance) readMemory(ptr, length uint32) ([]byte, bool) {
+	if w.module.Memory() == nil {
+		return nil, false
+	}
+	return w.module.Memory().Read(ptr, length)
+}
+
+func (w *wazeroInstance) writeMemory(ptr uint32, data []byte) bool {
+	return w.module.Memory() != nil && w.module.Memory().Write(ptr, data)
+}
+
+func (w *wazeroInstance) memorySize() uint64 {
+	if w.module.Memory() == nil {
+		return 0
+	}
+	return uint64(w.module.Memory().Size())
+}
+
+func (w *wazeroInstance) close(ctx context.Context) error {
+	return w.runtime.Close(ctx)
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"wasmbench/stats"
+)
+
+// options configure the comparison
+type options struct {
+	threshold  float64 // Percent
+	confidence float64
+	resamples  int
+}
+
+// sessionVersion is the newest bench session schema benchdiff reads; a newer
+// session may have renamed or dropped what it reads
+const sessionVersion = 1
+
+// session is the part of a bench -json session benchdiff reads
+type session struct {
+	SchemaVersion int         `json:"schema_version"` // 0 before bench versioned its sessions
+	Environment   environment `json:"environment"`
+	Results       []result    `json:"results"`
+}
+
+// environment is what of a session's host and builds makes timings comparable
+type environment struct {
+	CPUModel   string            `json:"cpu_model"`
+	CPUs       int               `json:"cpus"`
+	Governor   string            `json:"governor"`
+	Kernel     string            `json:"kernel"`
+	GoVersion  string            `json:"go_version"`
+	Toolchains map[string]string `json:"toolchains"`
+	Runtimes   map[string]string `json:"runtimes"`
+}
+
+// changes lists how head's environment differs from base's, one "name: base
+// -> head" line each. Sessions that predate a field do not report it.
+func (base environment) changes(head environment) []string {
+	var lines []string
+	add := func(name, b, h string) {
+		if b != "" && h != "" && b != h {
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", name, b, h))
+		}
+	}
+	add("cpu", base.CPUModel, head.CPUModel)
+	if base.CPUs != 0 && head.CPUs != 0 {
+		add("cpus", strconv.Itoa(base.CPUs), strconv.Itoa(head.CPUs))
+	}
+	add("governor", base.Governor, head.Governor)
+	add("kernel", base.Kernel, head.Kernel)
+	add("go", base.GoVersion, head.GoVersion)
+	for _, versions := range []struct {
+		kind       string
+		base, head map[string]string
+	}{{"toolchain", base.Toolchains, head.Toolchains}, {"runtime", base.Runtimes, head.Runtimes}} {
+		for _, name := range slices.Sorted(maps.Keys(versions.head)) {
+			add(versions.kind+" "+name, versions.base[name], versions.head[name])
+		}
+	}
+	return lines
+}
+
+// result is one module's entry of a session
+type result struct {
+	Module     string                 `json:"module"`
+	Runtime    string                 `json:"runtime"`
+	Agent      string                 `json:"agent"` // Of a bench -agents session
+	Task       string                 `json:"task"`
+	Params     map[string]json.Number `json:"params"`
+	Repetition int                    `json:"repetition"` // Of a bench -plan step
+	Hash       uint32                 `json:"hash"`
+	SamplesMs  []float64              `json:"samples_ms"`
+	Error      string                 `json:"error"`
+}
+
+// loadSession reads a session file
+func loadSession(path string) (session, error) {
+	var s session
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.SchemaVersion > sessionVersion {
+		return s, fmt.Errorf("%s: session schema version %d is newer than the %d cmd/benchdiff reads; update it", path, s.SchemaVersion, sessionVersion)
+	}
+	return s, nil
+}
+
+// key matches a result with its counterpart in the other session. Modules
+// match by file name, so sessions from different checkouts compare,
+// repetitions of a plan match the same repetition, and an agent's results
+// match the same agent's.
+func (r result) key() string {
+	return strings.Join([]string{r.Task, filepath.Base(r.Module), r.Runtime, r.Agent, r.params(), strconv.Itoa(r.Repetition)}, "\x00")
+}
+
+// name is the module's file name, and the agent that ran it, if any
+func (r result) name() string {
+	if r.Agent != "" {
+		return filepath.Base(r.Module) + " on " + r.Agent
+	}
+	return filepath.Base(r.Module)
+}
+
+// params formats the params as name=value pairs in name order
+func (r result) params() string {
+	var parts []string
+	for _, name := range slices.Sorted(maps.Keys(r.Params)) {
+		parts = append(parts, name+"="+r.Params[name].String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Verdicts of a pair
+const (
+	unchanged  = "~"
+	faster     = "faster"
+	slower     = "slower" // Significant, but within the threshold
+	regressed  = "REGRESSED"
+	hashChange = "HASH CHANGED"
+	failed     = "FAILED"
+	fixed      = "fixed" // Failed in the baseline only
+)
+
+// pair is a result of the baseline and its counterpart in the candidate
+type pair struct {
+	base, head result
+	baseMs     float64 // Medians
+	headMs     float64
+	delta      float64 // Percent change of the median
+	low, high  float64 // Confidence interval of delta
+	pValue     float64 // Of the Mann-Whitney U test of the runs
+	verdict    string
+}
+
+// diff is the comparison of two sessions
+type diff struct {
+	pairs              []pair // In the candidate's order
+	onlyBase, onlyHead []result
+}
+
+// compare pairs the sessions' results and judges each pair
+func compare(base, head session, opts options) diff {
+	var d diff
+	baseByKey := map[string]result{}
+	for _, r := range base.Results {
+		baseByKey[r.key()] = r
+	}
+	matched := map[string]bool{}
+	for _, h := range head.Results {
+		b, ok := baseByKey[h.key()]
+		if !ok {
+			d.onlyHead = append(d.onlyHead, h)
+			continue
+		}
+		matched[h.key()] = true
+		d.pairs = append(d.pairs, judge(b, h, opts))
+	}
+	for _, b := range base.Results {
+		if !matched[b.key()] {
+			d.onlyBase = append(d.onlyBase, b)
+		}
+	}
+	return d
+}
+
+// judge compares the medians of a pair and gives its verdict
+func judge(base, head result, opts options) pair {
+	p := pair{base: base, head: head}
+	switch {
+	case head.Error != "" || len(head.SamplesMs) == 0:
+		p.verdict = failed
+		return p
+	case base.Error != "" || len(base.SamplesMs) == 0:
+		p.verdict = fixed
+		return p
+	}
+	p.baseMs, p.headMs = stats.Median(base.SamplesMs), stats.Median(head.SamplesMs)
+	p.delta = percentChange(p.baseMs, p.headMs)
+	p.low, p.high = stats.Bootstrap(base.SamplesMs, head.SamplesMs, medianChange, opts.resamples, opts.confidence, bootstrapSeed)
+	p.pValue = stats.MannWhitney(base.SamplesMs, head.SamplesMs)
+	// A change must move the median past the interval and the runs as a
+	// whole past the U test's significance level
+	significant := p.pValue < 1-opts.confidence
+	switch {
+	case base.Hash != head.Hash:
+		p.verdict = hashChange
+	case significant && p.low > 0 && p.delta > opts.threshold:
+		p.verdict = regressed
+	case significant && p.low > 0:
+		p.verdict = slower
+	case significant && p.high < 0:
+		p.verdict = faster
+	default:
+		p.verdict = unchanged
+	}
+	return p
+}
+
+// failed reports whether the candidate regressed, changed a hash or failed
+func (d diff) failed() bool {
+	return slices.ContainsFunc(d.pairs, func(p pair) bool {
+		return p.verdict == regressed || p.verdict == hashChange || p.verdict == failed
+	})
+}
+
+// write prints the pairs, each task's geometric mean change and the results
+// that have no counterpart
+func (d diff) write(w io.Writer, opts options) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "task\tmodule\truntime\tparams\tbase ms\thead ms\tdelta\t%g%% CI\tp\t\n", opts.confidence*100)
+	var tasks []string
+	ratios := map[string][]float64{}
+	for _, p := range d.pairs {
+		h := p.head
+		if p.baseMs == 0 || p.headMs == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\t\t\t\t\t%s\n", h.Task, h.name(), h.Runtime, h.params(), p.verdict)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.4g\t%.4g\t%+.1f%%\t[%+.1f%%, %+.1f%%]\t%.2g\t%s\n",
+			h.Task, h.name(), h.Runtime, h.params(), p.baseMs, p.headMs, p.delta, p.low, p.high, p.pValue, p.verdict)
+		if !slices.Contains(tasks, h.Task) {
+			tasks = append(tasks, h.Task)
+		}
+		ratios[h.Task] = append(ratios[h.Task], p.headMs/p.baseMs)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(tasks) > 0 {
+		fmt.Fprintln(w)
+		for _, task := range tasks {
+			fmt.Fprintf(tw, "%s\t%+.1f%%\tgeometric mean of %d\n", task, (geomean(ratios[task])-1)*100, len(ratios[task]))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, only := range []struct {
+		label   string
+		results []result
+	}{{"only in base", d.onlyBase}, {"only in head", d.onlyHead}} {
+		for _, r := range only.results {
+			fmt.Fprintf(w, "%s: %s %s %s %s\n", only.label, r.Task, r.name(), r.Runtime, r.params())
+		}
+	}
+	return nil
+}
+
+// percentChange is head's change from base, in percent of base
+func percentChange(base, head float64) float64 {
+	return (head/base - 1) * 100
+}
+
+// bootstrapSeed seeds the resampling, so the same sessions always give the
+// same intervals
+const bootstrapSeed = 0x62656e6368646966
+
+// medianChange is the percent change of the median from base to head, the
+// statistic the interval is of
+func medianChange(base, head []float64) float64 {
+	return percentChange(stats.Median(base), stats.Median(head))
+}
+
+func geomean(ratios []float64) float64 {
+	var sum float64
+	for _, ratio := range ratios {
+		sum += math.Log(ratio)
+	}
+	return math.Exp(sum / float64(len(ratios)))
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var defaults = options{threshold: 5, confidence: 0.95, resamples: 2000}
+
+// samples returns n runs spread ±1% around ms
+func samples(ms float64, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = ms * (0.99 + 0.02*float64(i)/float64(n-1))
+	}
+	return s
+}
+
+func matrixMul(module string, dimension string, hash uint32, ms float64) result {
+	return result{Module: module, Runtime: "wazero", Task: "matrix_mul", Params: map[string]json.Number{"dimension": json.Number(dimension)},
+		Hash: hash, SamplesMs: samples(ms, 20)}
+}
+
+func TestJudge(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		base, head result
+		verdict    string
+	}{
+		{"same", matrixMul("a.wasm", "64", 1, 10), matrixMul("a.wasm", "64", 1, 10), unchanged},
+		{"faster", matrixMul("a.wasm", "64", 1, 10), matrixMul("a.wasm", "64", 1, 8), faster},
+		{"slower within threshold", matrixMul("a.wasm", "64", 1, 10), matrixMul("a.wasm", "64", 1, 10.3), slower},
+		{"regressed", matrixMul("a.wasm", "64", 1, 10), matrixMul("a.wasm", "64", 1, 12), regressed},
+		{"hash changed", matrixMul("a.wasm", "64", 1, 10), matrixMul("a.wasm", "64", 2, 10), hashChange},
+		{"failed", matrixMul("a.wasm", "64", 1, 10), result{Module: "a.wasm", Error: "trap"}, failed},
+		{"fixed", result{Module: "a.wasm", Error: "trap"}, matrixMul("a.wasm", "64", 1, 10), fixed},
+	} {
+		if p := judge(c.base, c.head, defaults); p.verdict != c.verdict {
+			t.Errorf("%s: verdict %q (delta %.1f%% in [%.1f%%, %.1f%%]), expected %q", c.name, p.verdict, p.delta, p.low, p.high, c.verdict)
+		}
+	}
+}
+
+func TestBootstrapInterval(t *testing.T) {
+	base, head := result{SamplesMs: samples(10, 30)}, result{SamplesMs: samples(11, 30)}
+	p := judge(base, head, defaults)
+	if !(p.low < 10 && 10 < p.high) || p.high-p.low > 2 {
+		t.Errorf("interval [%.2f%%, %.2f%%], expected a narrow one around +10%%", p.low, p.high)
+	}
+	if again := judge(base, head, defaults); again.low != p.low || again.high != p.high {
+		t.Error("the interval is not deterministic")
+	}
+}
+
+func TestCompareMatchesByFileName(t *testing.T) {
+	base := session{Results: []result{
+		matrixMul("/old/builds/tinygo/a.wasm", "64", 1, 10),
+		matrixMul("/old/builds/tinygo/a.wasm", "128", 1, 80),
+		matrixMul("/old/builds/tinygo/gone.wasm", "64", 1, 10),
+	}}
+	head := session{Results: []result{
+		matrixMul("builds/tinygo/a.wasm", "128", 1, 72),
+		matrixMul("builds/tinygo/a.wasm", "64", 1, 10),
+		matrixMul("builds/tinygo/new.wasm", "64", 1, 10),
+	}}
+	d := compare(base, head, defaults)
+	if len(d.pairs) != 2 || len(d.onlyBase) != 1 || len(d.onlyHead) != 1 {
+		t.Fatalf("%d pairs, %d only in base and %d only in head, expected 2, 1 and 1", len(d.pairs), len(d.onlyBase), len(d.onlyHead))
+	}
+	if d.pairs[0].verdict != faster || d.pairs[1].verdict != unchanged {
+		t.Errorf("verdicts %q and %q, expected faster and unchanged", d.pairs[0].verdict, d.pairs[1].verdict)
+	}
+	if d.failed() {
+		t.Error("a faster candidate failed")
+	}
+
+	var out strings.Builder
+	if err := d.write(&out, defaults); err != nil {
+		t.Fatal(err)
+	}
+	// The geometric mean of 0.9 and 1.0
+	for _, want := range []string{"-10.0%", "matrix_mul  -5.1%  geometric mean of 2", "only in base: matrix_mul gone.wasm", "only in head: matrix_mul new.wasm"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCompareMatchesByAgent(t *testing.T) {
+	onAgent := func(agent string, ms float64) result {
+		r := matrixMul("builds/tinygo/a.wasm", "64", 1, ms)
+		r.Agent = agent
+		return r
+	}
+	base := session{Results: []result{onAgent("x86", 10), onAgent("arm", 20)}}
+	head := session{Results: []result{onAgent("arm", 20), onAgent("x86", 12)}}
+	d := compare(base, head, defaults)
+	if len(d.pairs) != 2 || d.pairs[0].verdict != unchanged || d.pairs[1].verdict != regressed {
+		t.Fatalf("%+v, expected arm unchanged and x86 regressed", d.pairs)
+	}
+	var out strings.Builder
+	if err := d.write(&out, defaults); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "a.wasm on x86") {
+		t.Errorf("output does not name the agent:\n%s", out.String())
+	}
+}
+
+func TestEnvironmentChanges(t *testing.T) {
+	base := environment{
+		CPUModel: "Example CPU", CPUs: 8, Governor: "performance",
+		Toolchains: map[string]string{"tinygo": "tinygo version 0.38.0", "rust": "rustc 1.90.0"},
+		Runtimes:   map[string]string{"wazero": "v1.12.0"},
+	}
+	if changes := base.changes(base); len(changes) != 0 {
+		t.Errorf("same environment changed %q", changes)
+	}
+	head := base
+	head.Governor = "powersave"
+	head.Toolchains = map[string]string{"tinygo": "tinygo version 0.39.0", "rust": "rustc 1.90.0"}
+	head.Runtimes = nil
+	expected := []string{"governor: performance -> powersave", "toolchain tinygo: tinygo version 0.38.0 -> tinygo version 0.39.0"}
+	if changes := base.changes(head); !slices.Equal(changes, expected) {
+		t.Errorf("changes %q, expected %q", changes, expected)
+	}
+	if changes := (environment{}).changes(head); len(changes) != 0 {
+		t.Errorf("a session without an environment changed %q", changes)
+	}
+}
+
+func TestRunExitStatus(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, s session) string {
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	base := write("base.json", session{Results: []result{matrixMul("a.wasm", "64", 1, 10)}})
+	head := write("head.json", session{Results: []result{matrixMul("a.wasm", "64", 1, 12)}})
+
+	for _, c := range []struct {
+		args   []string
+		status int
+	}{
+		{[]string{base, base}, 0},
+		{[]string{base, head}, 1},
+		{[]string{"-threshold", "25", base, head}, 0},
+		{[]string{base}, 2},
+		{[]string{"-confidence", "1", base, head}, 2},
+	} {
+		var stdout, stderr strings.Builder
+		if status := run(c.args, &stdout, &stderr); status != c.status {
+			t.Errorf("benchdiff %v: exit status %d, expected %d\n%s%s", c.args, status, c.status, stdout.String(), stderr.String())
+		}
+	}
+}
+
+func TestLoadSessionRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(path, []byte(`{"schema_version": 2, "results": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSession(path); err == nil || !strings.Contains(err.Error(), "schema version 2") {
+		t.Errorf("loadSession of a version 2 session: %v, expected it refused", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Command benchdiff compares two sessions written by bench -json, a baseline
+// and a candidate, to check whether a change to the task code made them
+// faster or slower. Results are matched by module file name, runtime, task
+// and params, and each pair's difference of medians is reported as a
+// percentage of the baseline with a bootstrap confidence interval and the
+// p-value of a Mann-Whitney U test of the runs, followed by each task's
+// geometric mean change.
+//
+// Usage:
+//
+//	benchdiff [-threshold 5] [-confidence 0.95] base.json head.json
+//
+// A pair changed significantly when the whole interval lies on one side of
+// zero and the U test's p-value is below 1 - confidence, and regressed when
+// the candidate is also slower by more than -threshold percent, so noise
+// alone does not fail it. The exit status is 1 if any pair regressed, changed
+// its hash or failed only in the candidate, and 2 on bad usage. Differences
+// between the sessions' CPUs, frequency governors, kernels, toolchains and
+// runtimes are noted on stderr, since they can explain a change the code did
+// not make.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("benchdiff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts options
+	flags.Float64Var(&opts.threshold, "threshold", 5, "slowdown in percent past which a significant change is a regression")
+	flags.Float64Var(&opts.confidence, "confidence", 0.95, "confidence level of the intervals")
+	flags.IntVar(&opts.resamples, "resamples", 2000, "bootstrap resamples per interval")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "benchdiff: name a baseline and a candidate session file written by bench -json")
+		return 2
+	}
+	if opts.threshold < 0 || opts.confidence <= 0 || opts.confidence >= 1 || opts.resamples < 1 {
+		fmt.Fprintln(stderr, "benchdiff: -threshold must be at least 0, -confidence between 0 and 1 and -resamples at least 1")
+		return 2
+	}
+
+	var sessions [2]session
+	for i, path := range flags.Args() {
+		s, err := loadSession(path)
+		if err != nil {
+			fmt.Fprintln(stderr, "benchdiff:", err)
+			return 1
+		}
+		sessions[i] = s
+	}
+
+	for _, change := range sessions[0].Environment.changes(sessions[1].Environment) {
+		fmt.Fprintln(stderr, "benchdiff: environment changed,", change)
+	}
+	d := compare(sessions[0], sessions[1], opts)
+	if err := d.write(stdout, opts); err != nil {
+		fmt.Fprintln(stderr, "benchdiff:", err)
+		return 1
+	}
+	if d.failed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,3 @@
+module wasmbench/build
+
+go 1.25.0
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// index records artifacts already in the builds directory, built by the
+// scripts rather than by this command, with args as their build arguments.
+// The tinygo flags of the matrix among args become their flags, so the
+// scripts' builds are labeled with their configuration as cmd/build's are.
+// With no files named, every .wasm file there is recorded. The toolchain is
+// the one metrics.json records for the directory's language. An artifact
+// whose checksum the manifest already has keeps its entry, args and flags
+// included.
+func (b builder) index(files []string, args []string) (Manifest, error) {
+	previous, err := b.loadManifest()
+	if err != nil {
+		return Manifest{}, err
+	}
+	m := Manifest{Toolchain: previous.Toolchain, Target: previous.Target, Artifacts: []Artifact{}}
+	if toolchain := b.metricsToolchain(); toolchain != "" {
+		m.Toolchain = toolchain
+	}
+	if len(files) == 0 {
+		matches, _ := filepath.Glob(filepath.Join(b.out, "*.wasm"))
+		for _, path := range matches {
+			files = append(files, filepath.Base(path))
+		}
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(b.out, filepath.Base(file)))
+		if err != nil {
+			return Manifest{}, err
+		}
+		sum := sha256.Sum256(data)
+		a := Artifact{File: filepath.Base(file), Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:]), Args: args, Flags: flagsOf(args)}
+		if i := slices.IndexFunc(previous.Artifacts, func(p Artifact) bool { return p.File == a.File && p.SHA256 == a.SHA256 }); i >= 0 {
+			a = previous.Artifacts[i]
+		} else {
+			name := strings.TrimSuffix(a.File, ".wasm")
+			a.Task, a.Variant, _ = strings.Cut(name, "-")
+		}
+		m.Artifacts = append(m.Artifacts, a)
+	}
+	return m, nil
+}
+
+// metricsToolchain returns the toolchain version the build scripts recorded
+// in <builds>/metrics.json for the language of the builds directory, "" if
+// there is none
+func (b builder) metricsToolchain() string {
+	var metrics map[string]json.RawMessage
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(b.out), "metrics.json"))
+	if err != nil || json.Unmarshal(data, &metrics) != nil {
+		return ""
+	}
+	var entry struct {
+		Toolchain string `json:"toolchain"`
+	}
+	if json.Unmarshal(metrics[filepath.Base(b.out)], &entry) != nil {
+		return ""
+	}
+	return entry.Toolchain
+}
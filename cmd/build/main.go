@@ -0,0 +1,160 @@
+// Command build compiles every TinyGo task across a matrix of tinygo flags,
+// -opt, -gc, -scheduler, -panic and -tags, so the cost of each flag shows up
+// in the benchmarks rather than being argued about. Each flag takes a
+// comma-separated list of values, and every task is built with every
+// combination into <task>-<variant>.wasm. The variant names the optimization
+// level and each value that differs from scripts/build_tinygo.sh's flags
+// (matrix_mul-oz-gcleaking-panicprint.wasm), so the default build keeps its
+// usual name. The artifacts are tinygo's output as is, without wasm-opt, so
+// the flags alone make the difference.
+//
+// -tags allocstats builds the instrumented allocator variant instead, and
+// -tags none,allocstats both. It counts the allocations, bytes and GC cycles
+// of every measured run and reports them through get_memory_stats, and
+// cmd/bench records them per run. The counting stays outside the timed
+// section, so its times still compare with the plain build's.
+//
+// The builds directory also gets manifest.json: the toolchain, and each
+// artifact's task, variant, flags, size, checksum, build time or error.
+// cmd/bench labels its results from it, and its -manifest runs every artifact
+// that built. The runner refuses a module that the manifest beside it does
+// not list, or whose checksum differs from the recorded one, so a stale binary
+// is never benchmarked under a newer build's name. -index records artifacts
+// built by the scripts instead, which call it after their builds, with the
+// tinygo flags of -args as their flags.
+//
+// Usage:
+//
+//	build [flags] [task ...]
+//	build -index [-args "flags"] [-out dir] [file.wasm ...]
+//
+// With no tasks named, every task with a TinyGo package is built. With -n,
+// the commands are printed instead. The exit status is 1 if any build failed.
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var b builder
+	flags.StringVar(&b.tinygo, "tinygo", "tinygo", "tinygo command")
+	flags.StringVar(&b.tasks, "tasks", "../../tasks", "directory of the <task>/tinygo packages")
+	flags.StringVar(&b.out, "out", "../../builds/tinygo", "directory of the artifacts and manifest.json")
+	flags.StringVar(&b.target, "target", "wasm", "tinygo -target")
+	values := make([]*string, len(dimensions))
+	defaults := map[string]string{"opt": "2,z", "gc": "conservative,leaking"}
+	for i, d := range dimensions {
+		value := cmp.Or(defaults[d.flag], d.def)
+		values[i] = flags.String(d.flag, value, fmt.Sprintf("comma-separated tinygo -%s values, of %s", d.flag, strings.Join(d.valid, ", ")))
+	}
+	jobs := flags.Int("j", runtime.NumCPU(), "builds to run at once")
+	dryRun := flags.Bool("n", false, "print the build commands instead of running them")
+	index := flags.Bool("index", false, "instead of building, record the named .wasm files in -out (default all), built by the scripts, in manifest.json")
+	indexArgs := flags.String("args", "", "with -index, the build arguments the files were built with")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *jobs < 1 {
+		fmt.Fprintln(stderr, "build: -j must be at least 1")
+		return 2
+	}
+	var err error
+
+	if *index {
+		if b.out, err = filepath.Abs(b.out); err != nil {
+			fmt.Fprintln(stderr, "build:", err)
+			return 1
+		}
+		m, err := b.index(flags.Args(), strings.Fields(*indexArgs))
+		if err == nil {
+			err = b.writeManifest(m)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "build:", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s: %d artifact(s) recorded\n", filepath.Join(b.out, manifestFile), len(m.Artifacts))
+		return 0
+	}
+
+	axes := make([][]string, len(dimensions))
+	for i, d := range dimensions {
+		for _, v := range strings.Split(*values[i], ",") {
+			v = strings.TrimSpace(v)
+			if !slices.Contains(d.valid, v) {
+				fmt.Fprintf(stderr, "build: -%s %q is not one of %s\n", d.flag, v, strings.Join(d.valid, ", "))
+				return 2
+			}
+			if !slices.Contains(axes[i], v) {
+				axes[i] = append(axes[i], v)
+			}
+		}
+	}
+	tasks := flags.Args()
+	if len(tasks) == 0 {
+		if tasks = findTasks(b.tasks); len(tasks) == 0 {
+			fmt.Fprintf(stderr, "build: no <task>/tinygo packages under %s\n", b.tasks)
+			return 1
+		}
+	}
+	for _, task := range tasks {
+		if _, err := os.Stat(filepath.Join(b.tasks, task, "tinygo", "go.mod")); err != nil {
+			fmt.Fprintf(stderr, "build: %s has no TinyGo package under %s\n", task, b.tasks)
+			return 2
+		}
+	}
+	if b.out, err = filepath.Abs(b.out); err != nil {
+		fmt.Fprintln(stderr, "build:", err)
+		return 1
+	}
+	artifacts := b.plan(tasks, combinations(axes))
+
+	if *dryRun {
+		for _, a := range artifacts {
+			cmd := b.command(a)
+			fmt.Fprintf(stdout, "cd %s && %s\n", cmd.Dir, strings.Join(cmd.Args, " "))
+		}
+		return 0
+	}
+	m := Manifest{Target: b.target, Artifacts: artifacts}
+	if m.Toolchain, err = b.toolchain(); err != nil {
+		fmt.Fprintln(stderr, "build:", err)
+		return 1
+	}
+	if err := os.MkdirAll(b.out, 0o755); err != nil {
+		fmt.Fprintln(stderr, "build:", err)
+		return 1
+	}
+	b.build(artifacts, *jobs)
+
+	status := 0
+	for _, a := range artifacts {
+		if a.Error != "" {
+			fmt.Fprintf(stderr, "build: %s: %s\n", a.File, a.Error)
+			status = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %d bytes in %.0f ms\n", a.File, a.Size, a.BuildMs)
+	}
+	if err := b.writeManifest(m); err != nil {
+		fmt.Fprintln(stderr, "build:", err)
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dimension is one TinyGo flag of the build matrix
+type dimension struct {
+	flag   string   // tinygo's, without the dash, and the key of the manifest's flags
+	prefix string   // Of the artifact name suffix
+	def    string   // Left out of the artifact name, as scripts/build_tinygo.sh does
+	valid  []string // Values tinygo accepts
+}
+
+// dimensions are the axes of the matrix in artifact name order. The defaults
+// are the flags of scripts/build_tinygo.sh, so the default build of a task
+// keeps its name (matrix_mul-o2.wasm) and only the other variants get longer
+// ones (matrix_mul-oz-gcleaking.wasm). -tags allocstats builds the
+// instrumented allocator variant (matrix_mul-o2-allocstats.wasm), which counts
+// the allocations and GC cycles of each measured run for get_memory_stats.
+var dimensions = []dimension{
+	{"opt", "o", "", []string{"0", "1", "2", "s", "z"}},
+	{"gc", "gc", "conservative", []string{"none", "leaking", "conservative", "precise"}},
+	{"scheduler", "sched", "none", []string{"none", "tasks", "asyncify"}},
+	{"panic", "panic", "trap", []string{"trap", "print"}},
+	{"tags", "", "none", []string{"none", "allocstats"}},
+}
+
+// combination is one value of every dimension, in dimensions order
+type combination []string
+
+// combinations is the cartesian product of the values of each dimension
+func combinations(values [][]string) []combination {
+	combos := []combination{{}}
+	for _, axis := range values {
+		var next []combination
+		for _, c := range combos {
+			for _, v := range axis {
+				next = append(next, append(slices.Clip(c), v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// variant names the combination: every value that is not its dimension's
+// default, behind the dimension's prefix, joined by dashes
+func (c combination) variant() string {
+	var parts []string
+	for i, d := range dimensions {
+		if c[i] != d.def {
+			parts = append(parts, d.prefix+c[i])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// flags are the combination's tinygo flags. The tags value none is no build
+// tag, and passes no flag.
+func (c combination) flags() []string {
+	var args []string
+	for i, d := range dimensions {
+		if d.flag == "tags" && c[i] == "none" {
+			continue
+		}
+		args = append(args, "-"+d.flag+"="+c[i])
+	}
+	return args
+}
+
+// flagsOf picks the values of the matrix's dimensions out of tinygo build
+// args, given as -flag=value or -flag value, nil if args set none of them, as
+// cargo's do not. Dimensions args leave out are left out, since only the
+// scripts know which of tinygo's defaults they relied on.
+func flagsOf(args []string) map[string]string {
+	var flags map[string]string
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || !slices.ContainsFunc(dimensions, func(d dimension) bool { return d.flag == name }) {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				continue
+			}
+			value = args[i+1]
+		}
+		if flags == nil {
+			flags = map[string]string{}
+		}
+		flags[name] = value
+	}
+	return flags
+}
+
+// Manifest describes the artifacts of a builds directory, so the runner can
+// label every result with the flags its module was built with
+type Manifest struct {
+	Toolchain string     `json:"toolchain"`
+	Target    string     `json:"target,omitempty"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is one task built with one combination of flags
+type Artifact struct {
+	File    string            `json:"file"` // In the manifest's directory
+	Task    string            `json:"task"`
+	Variant string            `json:"variant"`
+	Flags   map[string]string `json:"flags,omitempty"`
+	Args    []string          `json:"args"` // Of tinygo build, but for -o
+	Size    int64             `json:"size,omitempty"`
+	SHA256  string            `json:"sha256,omitempty"`
+	BuildMs float64           `json:"build_ms,omitempty"`
+	Error   string            `json:"error,omitempty"` // The build failed, with tinygo's output
+}
+
+// manifestFile is the manifest's name in the builds directory
+const manifestFile = "manifest.json"
+
+// builder runs tinygo over the task packages
+type builder struct {
+	tinygo string // Command
+	tasks  string // Directory of <task>/tinygo
+	out    string // Absolute directory of the artifacts and the manifest
+	target string
+}
+
+// plan lists the artifacts of every task in every combination, unbuilt
+func (b builder) plan(tasks []string, combos []combination) []Artifact {
+	var artifacts []Artifact
+	for _, task := range tasks {
+		for _, c := range combos {
+			a := Artifact{Task: task, Variant: c.variant(), Flags: map[string]string{}}
+			a.File = task + "-" + a.Variant + ".wasm"
+			for i, d := range dimensions {
+				a.Flags[d.flag] = c[i]
+			}
+			a.Args = append([]string{"-target=" + b.target}, c.flags()...)
+			a.Args = append(a.Args, "-no-debug")
+			artifacts = append(artifacts, a)
+		}
+	}
+	return artifacts
+}
+
+// command returns the tinygo command building a in its task's package
+func (b builder) command(a Artifact) *exec.Cmd {
+	args := append([]string{"build"}, a.Args...)
+	cmd := exec.Command(b.tinygo, append(args, "-o", filepath.Join(b.out, a.File), ".")...)
+	cmd.Dir = filepath.Join(b.tasks, a.Task, "tinygo")
+	return cmd
+}
+
+// build runs each artifact's command, up to jobs at once, and records its
+// size, checksum and build time or its error
+func (b builder) build(artifacts []Artifact, jobs int) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, jobs)
+	for i := range artifacts {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			a := &artifacts[i]
+			cmd := b.command(*a)
+			start := time.Now()
+			output, err := cmd.CombinedOutput()
+			a.BuildMs = float64(time.Since(start).Microseconds()) / 1000
+			if err != nil {
+				a.Error = strings.TrimSpace(fmt.Sprintf("%v\n%s", err, output))
+				return
+			}
+			data, err := os.ReadFile(filepath.Join(b.out, a.File))
+			if err != nil {
+				a.Error = err.Error()
+				return
+			}
+			sum := sha256.Sum256(data)
+			a.Size, a.SHA256 = int64(len(data)), hex.EncodeToString(sum[:])
+		})
+	}
+	wg.Wait()
+}
+
+// toolchain returns tinygo's version line
+func (b builder) toolchain() (string, error) {
+	out, err := exec.Command(b.tinygo, "version").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s version: %w", b.tinygo, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// writeManifest writes the manifest of the builds directory, keeping the
+// artifacts of an earlier manifest that were not rebuilt and still exist
+func (b builder) writeManifest(m Manifest) error {
+	previous, err := b.loadManifest()
+	if err != nil {
+		return err
+	}
+	for _, a := range previous.Artifacts {
+		if slices.ContainsFunc(m.Artifacts, func(built Artifact) bool { return built.File == a.File }) {
+			continue
+		}
+		// Artifacts deleted since are dropped, failed builds kept
+		if _, err := os.Stat(filepath.Join(b.out, a.File)); err == nil || a.Error != "" {
+			m.Artifacts = append(m.Artifacts, a)
+		}
+	}
+	slices.SortFunc(m.Artifacts, func(a, b Artifact) int { return cmp.Compare(a.File, b.File) })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(b.out, manifestFile), append(data, '\n'), 0o644)
+}
+
+// loadManifest reads the builds directory's manifest, the zero manifest if
+// there is none yet
+func (b builder) loadManifest() (Manifest, error) {
+	var m Manifest
+	path := filepath.Join(b.out, manifestFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// findTasks lists the tasks with a TinyGo package under dir
+func findTasks(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*", "tinygo", "go.mod"))
+	tasks := make([]string, len(matches))
+	for i, path := range matches {
+		tasks[i] = filepath.Base(filepath.Dir(filepath.Dir(path)))
+	}
+	return tasks
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVariants(t *testing.T) {
+	combos := combinations([][]string{{"2", "z"}, {"conservative"}, {"none"}, {"trap", "print"}, {"none", "allocstats"}})
+	var variants []string
+	for _, c := range combos {
+		variants = append(variants, c.variant())
+	}
+	expected := []string{"o2", "o2-allocstats", "o2-panicprint", "o2-panicprint-allocstats", "oz", "oz-allocstats", "oz-panicprint", "oz-panicprint-allocstats"}
+	if !slices.Equal(variants, expected) {
+		t.Errorf("variants %v, expected %v", variants, expected)
+	}
+	if flags := combos[6].flags(); !slices.Equal(flags, []string{"-opt=z", "-gc=conservative", "-scheduler=none", "-panic=print"}) {
+		t.Errorf("flags %v of oz-panicprint", flags)
+	}
+	if flags := combos[7].flags(); !slices.Equal(flags, []string{"-opt=z", "-gc=conservative", "-scheduler=none", "-panic=print", "-tags=allocstats"}) {
+		t.Errorf("flags %v of oz-panicprint-allocstats", flags)
+	}
+}
+
+// fakeTinyGo is a tinygo stand-in that reports a version, fails -gc=leaking
+// builds and otherwise writes its arguments as the artifact
+const fakeTinyGo = `#!/bin/sh
+if [ "$1" = version ]; then echo "tinygo version 0.39.0 linux/amd64"; exit 0; fi
+case "$*" in *-gc=leaking*) echo "leaking is not supported" >&2; exit 1;; esac
+while [ "$#" -gt 0 ]; do
+	if [ "$1" = -o ]; then out="$2"; fi
+	shift
+done
+pwd > "$out"
+`
+
+func TestRunWritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	tinygo := filepath.Join(dir, "tinygo")
+	if err := os.WriteFile(tinygo, []byte(fakeTinyGo), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tasks := filepath.Join(dir, "tasks")
+	for _, task := range []string{"matrix_mul", "mandelbrot"} {
+		if err := os.MkdirAll(filepath.Join(tasks, task, "tinygo"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tasks, task, "tinygo", "go.mod"), []byte("module "+task+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(dir, "builds", "tinygo")
+	base := []string{"-tinygo", tinygo, "-tasks", tasks, "-out", out}
+
+	var stdout, stderr strings.Builder
+	if code := run(append(base, "-gc", "conservative", "-scheduler", "none,asyncify"), &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	// A second run adds to the manifest, and its failed build is recorded
+	if code := run(append(base, "-opt", "s", "-gc", "leaking", "matrix_mul"), &stdout, &stderr); code != 1 {
+		t.Fatalf("exit status %d with a failed build, expected 1", code)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, manifestFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Toolchain != "tinygo version 0.39.0 linux/amd64" || m.Target != "wasm" {
+		t.Errorf("toolchain %q for target %q", m.Toolchain, m.Target)
+	}
+	var files []string
+	for _, a := range m.Artifacts {
+		files = append(files, a.File)
+	}
+	expected := []string{
+		"mandelbrot-o2-schedasyncify.wasm", "mandelbrot-o2.wasm", "mandelbrot-oz-schedasyncify.wasm", "mandelbrot-oz.wasm",
+		"matrix_mul-o2-schedasyncify.wasm", "matrix_mul-o2.wasm", "matrix_mul-os-gcleaking.wasm", "matrix_mul-oz-schedasyncify.wasm", "matrix_mul-oz.wasm",
+	}
+	if !slices.Equal(files, expected) {
+		t.Fatalf("artifacts %v, expected %v", files, expected)
+	}
+	built := m.Artifacts[5]
+	if built.Variant != "o2" || built.Flags["scheduler"] != "none" || built.Size == 0 || len(built.SHA256) != 64 || built.Error != "" {
+		t.Errorf("artifact %+v, expected the default build's", built)
+	}
+	if data, err := os.ReadFile(filepath.Join(out, built.File)); err != nil || strings.TrimSpace(string(data)) != filepath.Join(tasks, "matrix_mul", "tinygo") {
+		t.Errorf("%s built in %q, expected the task's package", built.File, data)
+	}
+	if failed := m.Artifacts[6]; !strings.Contains(failed.Error, "leaking is not supported") {
+		t.Errorf("failed build's error %q lacks tinygo's output", failed.Error)
+	}
+
+	if code := run(append(base, "-opt", "3"), &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -opt 3, expected 2", code)
+	}
+}
+
+func TestRunIndex(t *testing.T) {
+	builds := t.TempDir()
+	out := filepath.Join(builds, "rust")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(builds, "metrics.json"), []byte(`{"rust": {"toolchain": "rustc 1.90.0"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(out, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func() Manifest {
+		data, err := os.ReadFile(filepath.Join(out, manifestFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	index := func(args ...string) {
+		t.Helper()
+		var stdout, stderr strings.Builder
+		if code := run(append([]string{"-index", "-out", out}, args...), &stdout, &stderr); code != 0 {
+			t.Fatalf("exit status %d: %s", code, stderr.String())
+		}
+	}
+	write("matrix_mul-o3.wasm", "first")
+	write("mandelbrot-o3.wasm", "first")
+	index("-args", "--release -C opt-level=3")
+
+	m := load()
+	if m.Toolchain != "rustc 1.90.0" || len(m.Artifacts) != 2 {
+		t.Fatalf("manifest %+v, expected both modules built by rustc 1.90.0", m)
+	}
+	if a := m.Artifacts[1]; a.File != "matrix_mul-o3.wasm" || a.Task != "matrix_mul" || a.Variant != "o3" || a.Size != 5 || !slices.Equal(a.Args, []string{"--release", "-C", "opt-level=3"}) {
+		t.Errorf("artifact %+v", a)
+	}
+	if a := m.Artifacts[0]; a.Flags != nil {
+		t.Errorf("cargo's args gave flags %v", a.Flags)
+	}
+
+	// Only the rebuilt module takes the new arguments, and deleted ones go
+	write("matrix_mul-o3.wasm", "second")
+	if err := os.Remove(filepath.Join(out, "mandelbrot-o3.wasm")); err != nil {
+		t.Fatal(err)
+	}
+	write("json_parse-o3.wasm", "first")
+	index("-args", "--release")
+	m = load()
+	var summary []string
+	for _, a := range m.Artifacts {
+		summary = append(summary, a.File+" "+strings.Join(a.Args, " "))
+	}
+	expected := []string{"json_parse-o3.wasm --release", "matrix_mul-o3.wasm --release"}
+	if !slices.Equal(summary, expected) {
+		t.Errorf("artifacts %q, expected %q", summary, expected)
+	}
+	index("-args", "-C opt-level=s", "matrix_mul-o3.wasm")
+	if a := load().Artifacts[0]; strings.Join(a.Args, " ") != "--release" {
+		t.Errorf("unchanged json_parse-o3.wasm now has args %q", a.Args)
+	}
+}
+
+func TestFlagsOf(t *testing.T) {
+	flags := flagsOf(strings.Fields("-opt=2 -panic=trap -no-debug -scheduler=none -gc leaking -tags"))
+	expected := map[string]string{"opt": "2", "panic": "trap", "scheduler": "none", "gc": "leaking"}
+	if !maps.Equal(flags, expected) {
+		t.Errorf("flags %v, expected %v", flags, expected)
+	}
+}
@@ -0,0 +1,3 @@
+module wasmbench/genlayout
+
+go 1.25.0
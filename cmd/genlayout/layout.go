@@ -0,0 +1,183 @@
+// Layout file reading and assertion generation: reads the packages' source
+// with go/parser only, for their name and the structs' field names
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// layoutVersion is the layout file format genlayout reads
+const layoutVersion = 1
+
+// outputName is the assertion file written in each package
+const outputName = "layout_gen.go"
+
+// Layouts is configs/layouts.json
+type Layouts struct {
+	LayoutVersion int      `json:"layout_version"`
+	Structs       []Struct `json:"structs"`
+}
+
+// Struct is the layout of one shared struct
+type Struct struct {
+	Name   string  `json:"name"`
+	Dir    string  `json:"dir"`  // Of its Go package, slash-separated from the root
+	Size   uint64  `json:"size"` // In wasm32 linear memory
+	Fields []Field `json:"fields"`
+}
+
+// Field is one field of a struct, in declaration order
+type Field struct {
+	Name   string `json:"name"` // snake_case, as the Rust struct names it
+	Offset uint64 `json:"offset"`
+	Size   uint64 `json:"size"`
+}
+
+// readLayouts reads the layout file at path and checks that each struct's
+// fields are in order and inside it
+func readLayouts(path string) (Layouts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Layouts{}, err
+	}
+	var layouts Layouts
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&layouts); err != nil {
+		return Layouts{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if layouts.LayoutVersion != layoutVersion {
+		return Layouts{}, fmt.Errorf("%s: layout_version %d, expected %d", path, layouts.LayoutVersion, layoutVersion)
+	}
+	for _, s := range layouts.Structs {
+		end := uint64(0)
+		for _, f := range s.Fields {
+			if f.Offset < end || f.Offset+f.Size > s.Size {
+				return Layouts{}, fmt.Errorf("%s: %s.%s at %d, %d bytes, overlaps the field before it or ends past the struct's %d bytes",
+					path, s.Name, f.Name, f.Offset, f.Size, s.Size)
+			}
+			end = f.Offset + f.Size
+		}
+	}
+	return layouts, nil
+}
+
+// generate returns the assertion file of each package dir with a struct in
+// layouts, keyed by the dir
+func generate(root string, layouts Layouts) (map[string][]byte, error) {
+	byDir := map[string][]Struct{}
+	var dirs []string
+	for _, s := range layouts.Structs {
+		if byDir[s.Dir] == nil {
+			dirs = append(dirs, s.Dir)
+		}
+		byDir[s.Dir] = append(byDir[s.Dir], s)
+	}
+
+	files := map[string][]byte{}
+	for _, dir := range dirs {
+		name, structs, err := readPackage(filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, err
+		}
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "// Code generated by cmd/genlayout from configs/layouts.json; DO NOT EDIT.\n\n")
+		// The 32-bit ports but wasm align u64 and f64 at 4, not at 8 as wasm32
+		// and the 64-bit hosts do
+		fmt.Fprintf(&b, "//go:build !(386 || arm || mips || mipsle)\n\npackage %s\n\nimport \"unsafe\"\n", name)
+		for _, s := range byDir[dir] {
+			goFields, ok := structs[s.Name]
+			if !ok {
+				return nil, fmt.Errorf("%s: no struct %s", dir, s.Name)
+			}
+			if err := writeAssertions(&b, s, goFields); err != nil {
+				return nil, fmt.Errorf("%s: %w", dir, err)
+			}
+		}
+		src, err := format.Source(b.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		files[dir] = src
+	}
+	return files, nil
+}
+
+// writeAssertions writes the function whose index expressions check s's
+// layout: x[n - want] is in bounds only when n is want, and underflows
+// uintptr when it is less
+func writeAssertions(b *bytes.Buffer, s Struct, goFields []string) error {
+	names := map[string]string{} // Go field names by their snake_case match
+	for _, name := range goFields {
+		names[strings.ToLower(name)] = name
+	}
+	fmt.Fprintf(b, "\n// %s must have the layout configs/layouts.json gives it, the one the\n// hosts read and write it by\n", s.Name)
+	fmt.Fprintf(b, "func _() {\n\tvar x [1]struct{}\n\t_ = x[unsafe.Sizeof(%s{})-%d]\n", s.Name, s.Size)
+	for _, f := range s.Fields {
+		key := strings.ReplaceAll(f.Name, "_", "")
+		goName, ok := names[key]
+		if !ok {
+			return fmt.Errorf("%s has no field %s", s.Name, f.Name)
+		}
+		delete(names, key)
+		fmt.Fprintf(b, "\t_ = x[unsafe.Offsetof(%s{}.%s)-%d]\n", s.Name, goName, f.Offset)
+		fmt.Fprintf(b, "\t_ = x[unsafe.Sizeof(%s{}.%s)-%d]\n", s.Name, goName, f.Size)
+	}
+	for _, name := range goFields {
+		if _, ok := names[strings.ToLower(name)]; ok {
+			return fmt.Errorf("%s.%s is not in the layout file; add it there first", s.Name, name)
+		}
+	}
+	fmt.Fprintf(b, "}\n")
+	return nil
+}
+
+// readPackage returns the name of the package in dir and the field names of
+// its structs, generated and test files left out
+func readPackage(dir string) (string, map[string][]string, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	name, structs := "", map[string][]string{}
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == outputName {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		name = file.Name.Name
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				var fields []string
+				for _, field := range st.Fields.List {
+					for _, ident := range field.Names {
+						fields = append(fields, ident.Name)
+					}
+				}
+				structs[spec.Name.Name] = fields
+			}
+			return false
+		})
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("%s: no Go files", dir)
+	}
+	return name, structs, nil
+}
@@ -0,0 +1,78 @@
+// Command genlayout writes compile-time layout assertions for every struct
+// the host and the modules share through linear memory, from
+// configs/layouts.json. The file gives each params, limits and result
+// struct's wasm32 size and each field's offset and size, under the field's
+// snake_case name, which the Rust structs use too. For each package with a
+// struct in the file, genlayout writes layout_gen.go, whose unsafe.Sizeof
+// and unsafe.Offsetof expressions fail to compile once the struct's layout
+// differs from the file's: an index out of bounds where the struct's size or
+// offset is larger, a constant that overflows uintptr where it is smaller.
+// A field of the struct the file does not list, or one the file
+// lists that the struct does not have, fails the generator, so an added
+// field cannot go unchecked. The Rust crates' tests check their structs
+// against the same file.
+//
+// Usage:
+//
+//	genlayout [-layouts file] [-root dir] [-check]
+//
+// With -check, nothing is written and the exit status is 1 if an assertion
+// file is out of date. A struct's layout is part of the ABI: change the file
+// with it, on purpose, then regenerate the assertions.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genlayout", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	layouts := flags.String("layouts", "../../configs/layouts.json", "layout file")
+	root := flags.String("root", "../..", "directory the layout file's package dirs are relative to")
+	check := flags.Bool("check", false, "report out-of-date assertion files instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	file, err := readLayouts(*layouts)
+	if err != nil {
+		fmt.Fprintln(stderr, "genlayout:", err)
+		return 1
+	}
+	files, err := generate(*root, file)
+	if err != nil {
+		fmt.Fprintln(stderr, "genlayout:", err)
+		return 1
+	}
+
+	status := 0
+	for _, dir := range slices.Sorted(maps.Keys(files)) {
+		path := filepath.Join(*root, dir, outputName)
+		if *check {
+			if current, err := os.ReadFile(path); err != nil || !bytes.Equal(current, files[dir]) {
+				fmt.Fprintf(stderr, "genlayout: %s is out of date\n", path)
+				status = 1
+			}
+			continue
+		}
+		if err := os.WriteFile(path, files[dir], 0o644); err != nil {
+			fmt.Fprintln(stderr, "genlayout:", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s: written\n", path)
+	}
+	return status
+}
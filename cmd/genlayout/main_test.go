@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssertionsAreCurrent(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-check"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit status %d: %s(run genlayout to rewrite them)", code, stderr.String())
+	}
+}
+
+const toyPackage = `package toy
+
+type ToyParams struct {
+	Count    uint32
+	Ratio    float64
+	SeedHigh uint32
+}
+`
+
+// toyLayouts is ToyParams's layout in wasm32, with a field changed by edit
+func toyLayouts(edit func(*Struct)) Layouts {
+	s := Struct{Name: "ToyParams", Dir: "toy", Size: 24, Fields: []Field{
+		{Name: "count", Offset: 0, Size: 4},
+		{Name: "ratio", Offset: 8, Size: 8},
+		{Name: "seed_high", Offset: 16, Size: 4},
+	}}
+	if edit != nil {
+		edit(&s)
+	}
+	return Layouts{LayoutVersion: layoutVersion, Structs: []Struct{s}}
+}
+
+// typeCheck type-checks the toy package with its assertion file, laid out
+// as gc lays it out on amd64, which agrees with wasm32 on these fields and,
+// unlike types.StdSizes, pads a struct to its alignment
+func typeCheck(t *testing.T, assertions []byte) error {
+	t.Helper()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for name, src := range map[string]string{"toy.go": toyPackage, outputName: string(assertions)} {
+		file, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	config := types.Config{Importer: importer.Default(), Sizes: types.SizesFor("gc", "amd64")}
+	_, err := config.Check("toy", fset, files, nil)
+	return err
+}
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "toy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "toy", "toy.go"), []byte(toyPackage), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := generate(root, toyLayouts(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(files["toy"], []byte("_ = x[unsafe.Offsetof(ToyParams{}.SeedHigh)-16]")) {
+		t.Errorf("No assertion of SeedHigh's offset:\n%s", files["toy"])
+	}
+	if err := typeCheck(t, files["toy"]); err != nil {
+		t.Errorf("The assertions of the struct's own layout: %v", err)
+	}
+
+	// A layout the struct does not have fails to compile
+	for name, edit := range map[string]func(*Struct){
+		"a field further on": func(s *Struct) { s.Fields[1].Offset = 12 },
+		"a field further in": func(s *Struct) { s.Fields[1].Offset = 4 },
+		"a wider field":      func(s *Struct) { s.Fields[2].Size = 8 },
+		"a smaller struct":   func(s *Struct) { s.Size = 20 },
+	} {
+		files, err := generate(root, toyLayouts(edit))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := typeCheck(t, files["toy"]); err == nil {
+			t.Errorf("%s: the assertions compile", name)
+		}
+	}
+
+	// Fields on one side only fail the generator
+	for want, edit := range map[string]func(*Struct){
+		"ToyParams has no field seed_low":              func(s *Struct) { s.Fields[2].Name = "seed_low" },
+		"ToyParams.SeedHigh is not in the layout file": func(s *Struct) { s.Fields = s.Fields[:2] },
+		"toy: no struct Params":                        func(s *Struct) { s.Name = "Params" },
+	} {
+		if _, err := generate(root, toyLayouts(edit)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%v, expected %q", err, want)
+		}
+	}
+}
+
+func TestReadLayouts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "layouts.json")
+	for contents, want := range map[string]string{
+		`{"layout_version": 1, "structs": [{"name": "P", "dir": "p", "size": 8, "fields": [{"name": "a", "offset": 0, "size": 4}]}]}`: "",
+		`{"layout_version": 2, "structs": []}`:             "layout_version 2, expected 1",
+		`{"layout_version": 1, "structs": [], "note": ""}`: `unknown field "note"`,
+		`{"layout_version": 1, "structs": [{"name": "P", "dir": "p", "size": 8, "fields": [{"name": "a", "offset": 0, "size": 4}, {"name": "b", "offset": 2, "size": 4}]}]}`: "P.b at 2, 4 bytes, overlaps",
+		`{"layout_version": 1, "structs": [{"name": "P", "dir": "p", "size": 8, "fields": [{"name": "a", "offset": 6, "size": 4}]}]}`:                                        "ends past the struct's 8 bytes",
+	} {
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := readLayouts(path)
+		if want == "" && err != nil || want != "" && (err == nil || !strings.Contains(err.Error(), want)) {
+			t.Errorf("%s: %v, expected %q", contents, err, want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+module wasmbench/gennode
+
+go 1.25.0
+
+// Node.js harness generator: reads the task manifest, configs/tasks.json
@@ -0,0 +1,67 @@
+// Command gennode writes harness/node/bench.js, a ready-to-run Node.js
+// harness for the task modules, so Node joins the runtime matrix without
+// hand-maintained JS glue. The script embeds the part of configs/tasks.json,
+// the task manifest cmd/gentasks writes from the task packages, that it
+// needs: the ABI version and, for each task, the params struct layout (every
+// field's name, type and offset, the struct size) and the default params
+// cmd/bench runs. Around it, the script loads a module with the host
+// imports the other runtimes provide, marshals the params into linear memory
+// by that layout, calls init, self_test and validate_params, times the
+// warm-up and measured run_task calls with process.hrtime, and prints one
+// JSON result per module in cmd/bench's format, with "runtime": "node".
+//
+// Usage:
+//
+//	gennode [-tasks file] [-out file] [-check]
+//
+// With -check, nothing is written and the exit status is 1 if the script is
+// out of date. Regenerate it whenever the task manifest changes.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gennode", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	tasksPath := flags.String("tasks", "../../configs/tasks.json", "task manifest, written by cmd/gentasks")
+	out := flags.String("out", "../../harness/node/bench.js", "script to write")
+	check := flags.Bool("check", false, "report an out-of-date script instead of writing it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	m, err := loadManifest(*tasksPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "gennode:", err)
+		return 1
+	}
+	data, err := generate(m)
+	if err != nil {
+		fmt.Fprintln(stderr, "gennode:", err)
+		return 1
+	}
+	if *check {
+		if current, err := os.ReadFile(*out); err != nil || !bytes.Equal(current, data) {
+			fmt.Fprintf(stderr, "gennode: %s is out of date\n", *out)
+			return 1
+		}
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(stderr, "gennode:", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: %d tasks\n", *out, len(m.Tasks))
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeTask is a minimal task module: one page of memory, init does nothing,
+// alloc always returns 1024, and run_task hashes the first u32 of the params
+// (the matrix_mul dimension) as 3 times its value
+var fakeTask = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x0a, 0x02, 0x60, 0x01, 0x7f, 0x00, 0x60, 0x01, 0x7f, 0x01, 0x7f,
+	0x03, 0x04, 0x03, 0x00, 0x01, 0x01,
+	0x05, 0x03, 0x01, 0x00, 0x01,
+	0x07, 0x24, 0x04,
+	0x06, 'm', 'e', 'm', 'o', 'r', 'y', 0x02, 0x00,
+	0x04, 'i', 'n', 'i', 't', 0x00, 0x00,
+	0x05, 'a', 'l', 'l', 'o', 'c', 0x00, 0x01,
+	0x08, 'r', 'u', 'n', '_', 't', 'a', 's', 'k', 0x00, 0x02,
+	0x0a, 0x15, 0x03,
+	0x02, 0x00, 0x0b,
+	0x05, 0x00, 0x41, 0x80, 0x08, 0x0b,
+	0x0a, 0x00, 0x20, 0x00, 0x28, 0x02, 0x00, 0x41, 0x03, 0x6c, 0x0b,
+}
+
+func TestScriptIsCurrent(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-check"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit status %d: %s(run gennode to rewrite it)", code, stderr.String())
+	}
+}
+
+func TestScriptRunsModule(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "bench.js")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-out", script}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	module := filepath.Join(dir, "matrix_mul-o2.wasm")
+	if err := os.WriteFile(module, fakeTask, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(node, script, "--warmup", "1", "--runs", "3", "--params", `{"dimension": 5}`, module).Output()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	var result struct {
+		Task      string             `json:"task"`
+		Runtime   string             `json:"runtime"`
+		Params    map[string]float64 `json:"params"`
+		Hash      uint32             `json:"hash"`
+		SamplesMs []float64          `json:"samples_ms"`
+		Stats     struct{ N int }    `json:"stats"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("output is not a JSON result: %v\n%s", err, out)
+	}
+	// The task comes from the file name, since the module has no get_task_info
+	if result.Task != "matrix_mul" || result.Runtime != "node" || result.Params["dimension"] != 5 || result.Params["seed"] != 12345 {
+		t.Errorf("%s task %q with params %v, expected node running matrix_mul with dimension 5 and seed 12345", result.Runtime, result.Task, result.Params)
+	}
+	if result.Hash != 15 || len(result.SamplesMs) != 3 || result.Stats.N == 0 {
+		t.Errorf("hash %d with %d runs and stats of %d, expected 15 with 3", result.Hash, len(result.SamplesMs), result.Stats.N)
+	}
+
+	out, err = exec.Command(node, script, "--params", `{"width": 8}`, module).Output()
+	if exit, ok := err.(*exec.ExitError); !ok || exit.ExitCode() != 1 || !strings.Contains(string(out), "unknown params field width") {
+		t.Errorf("error %v with output %s, expected an unknown field to fail the module", err, out)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// taskManifest is a task's entry in the script's manifest, the part of its
+// configs/tasks.json entry the script reads
+type taskManifest struct {
+	ParamsSize uintptr                `json:"params_size"`
+	Params     []paramField           `json:"params"`   // In declaration order
+	Defaults   map[string]json.Number `json:"defaults"` // Params of a run without --params
+}
+
+// paramField is a params field as get_task_info reports it
+type paramField struct {
+	Name   string  `json:"name"`
+	Type   string  `json:"type"`
+	Offset uintptr `json:"offset"`
+}
+
+// manifest is the script's manifest
+type manifest struct {
+	ABIVersion int                     `json:"abi_version"`
+	Tasks      map[string]taskManifest `json:"tasks"`
+}
+
+// loadManifest reads the script's manifest from the task manifest at path,
+// which cmd/gentasks writes
+func loadManifest(path string) (manifest, error) {
+	var m manifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(m.Tasks) == 0 {
+		return m, fmt.Errorf("%s lists no tasks", path)
+	}
+	return m, nil
+}
+
+//go:embed bench.js.tmpl
+var scriptTemplate string
+
+var script = template.Must(template.New("bench.js").Parse(scriptTemplate))
+
+// generate returns the script with the manifest m
+func generate(m manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := script.Execute(&out, struct{ Manifest string }{string(data)}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
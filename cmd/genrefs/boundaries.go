@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// boundaryCategory is the category of the vectors boundaries derives
+const boundaryCategory = "boundary"
+
+// boundaryBudget bounds the work of a boundary vector that runs, as the
+// product of the task's work fields, so a limit too costly to run at, like
+// matrix_mul's 2000×2000 product, does not slow every check of the files
+const boundaryBudget = 1 << 20
+
+// boundaries derives the task's boundary vectors from its limits rather than
+// a hand-curated list, so every task gets the same coverage of them. They
+// start from DefaultParams. For each limit of the task's own on a single
+// field there is the field at 0, at 1, at the limit and one past it; at the
+// limit, the other fields with a limit of their own are 1, and the vector is
+// left out if its work exceeds boundaryBudget. A limit on a product of
+// fields is exceeded with its first field at that field's limit, where the
+// other fields' limits allow it. Each option field is one past the shared
+// limit, and a seed is 0 and the largest u32.
+func (t task) boundaries() []vectorSpec {
+	base := t.defaults()
+	var specs []vectorSpec
+	add := func(name, description string, params map[string]uint64) {
+		spec := vectorSpec{Name: "boundary_" + name, Description: description, Category: boundaryCategory, Params: map[string]json.Number{}}
+		for field, value := range base {
+			spec.Params[field] = value
+		}
+		for field, value := range params {
+			spec.Params[field] = json.Number(strconv.FormatUint(value, 10))
+		}
+		specs = append(specs, spec)
+	}
+	limits := map[string]uint64{} // Of the fields with a limit of their own
+	for _, bound := range t.schema.Bounds {
+		if len(bound.Params) == 1 {
+			limits[bound.Params[0]] = bound.Max
+		}
+	}
+
+	for _, bound := range t.schema.Bounds {
+		field, limit := bound.Params[0], bound.Limit+" "+strconv.FormatUint(bound.Max, 10)
+		if len(bound.Params) > 1 {
+			// The first field at its limit, the last just past the product's
+			// and the ones between at 1
+			first, last := limits[field], bound.Params[len(bound.Params)-1]
+			if first == 0 {
+				continue
+			}
+			over := map[string]uint64{field: first, last: bound.Max/first + 1}
+			for _, other := range bound.Params[1 : len(bound.Params)-1] {
+				over[other] = 1
+			}
+			if max, ok := limits[last]; ok && over[last] > max {
+				continue
+			}
+			add(strings.Join(bound.Params, "_")+"_over", strings.Join(bound.Params, "*")+" past "+limit, over)
+			continue
+		}
+
+		add(field+"_zero", field+" 0", map[string]uint64{field: 0})
+		add(field+"_one", field+" 1", map[string]uint64{field: 1})
+		atLimit := map[string]uint64{field: bound.Max}
+		for other := range limits {
+			if other != field {
+				atLimit[other] = 1
+			}
+		}
+		if t.work(base, atLimit) <= boundaryBudget {
+			add(field+"_max", field+" at "+limit, atLimit)
+		}
+		add(field+"_over", field+" one past "+limit, map[string]uint64{field: bound.Max + 1})
+	}
+
+	for _, bound := range t.schema.OptionBounds() {
+		field := bound.Params[0]
+		add(field+"_over", field+" one past "+bound.Limit+" "+strconv.FormatUint(bound.Max, 10), map[string]uint64{field: bound.Max + 1})
+	}
+	for _, field := range t.schema.Fields {
+		if field.Name == "seed" && field.Type == common.FieldU32 {
+			add("seed_zero", "seed 0", map[string]uint64{"seed": 0})
+			add("seed_max", "seed at the largest u32", map[string]uint64{"seed": math.MaxUint32})
+		}
+	}
+	return specs
+}
+
+// work is the product of the task's work fields, with params over base
+func (t task) work(base map[string]json.Number, params map[string]uint64) uint64 {
+	work := uint64(1)
+	for _, field := range t.workFields {
+		value, ok := params[field]
+		if !ok {
+			value, _ = strconv.ParseUint(base[field].String(), 10, 64)
+		}
+		if value != 0 && work > math.MaxUint64/value {
+			return math.MaxUint64
+		}
+		work *= value
+	}
+	return work
+}
+
+// defaults returns the nonzero fields of the task's DefaultParams
+func (t task) defaults() map[string]json.Number {
+	values := map[string]json.Number{}
+	for _, field := range t.schema.Fields {
+		ptr := unsafe.Add(t.defaultParams, field.Offset)
+		switch field.Type {
+		case common.FieldF64:
+			if f := *(*float64)(ptr); f != 0 {
+				values[field.Name] = json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+			}
+		case common.FieldU64:
+			if u := *(*uint64)(ptr); u != 0 {
+				values[field.Name] = json.Number(strconv.FormatUint(u, 10))
+			}
+		default:
+			if u := *(*uint32)(ptr); u != 0 {
+				values[field.Name] = json.Number(strconv.FormatUint(uint64(u), 10))
+			}
+		}
+	}
+	return values
+}
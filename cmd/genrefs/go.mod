@@ -0,0 +1,18 @@
+module wasmbench/genrefs
+
+go 1.25.0
+
+// Reference hash generator: runs the task packages natively
+require (
+	json_parse_wasm v0.0.0
+	mandelbrot_wasm v0.0.0
+	matrix_mul_wasm v0.0.0
+	wasmbench/common v0.0.0
+)
+
+replace (
+	json_parse_wasm => ../../tasks/json_parse/tinygo
+	mandelbrot_wasm => ../../tasks/mandelbrot/tinygo
+	matrix_mul_wasm => ../../tasks/matrix_mul/tinygo
+	wasmbench/common => ../../tasks/common
+)
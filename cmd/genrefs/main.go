@@ -0,0 +1,116 @@
+// Command genrefs writes the reference hashes in data/reference_hashes from
+// the parameter matrix in configs/reference_vectors.json. Every vector runs
+// through the task's Go implementation natively, compiled in from the package
+// its TinyGo modules are built from. Vectors that succeed record their hash
+// and the checkpoint hashes of the stages before it, and the ones the task
+// rejects record its status and error code. The config's vectors are
+// followed by each task's boundary vectors, derived from the limits of its
+// schema: each limited field at 0, at 1, at and past its limit. Every file
+// follows the versioned schema of package refschema, which the
+// cross-implementation tests of both languages read, and genrefs checks what
+// it writes against it. Each task's Go package embeds a copy of its file,
+// testdata/reference_hashes.json, so its tests find the vectors wherever
+// they run; genrefs writes the copies with the files.
+//
+// Usage:
+//
+//	genrefs [flags] [task ...]
+//
+// With no tasks named, every task in the config is written. With -check,
+// nothing is written and the exit status is 1 if any file or copy is out of date,
+// listing each vector whose hash, stages, status or params drifted from the file,
+// or breaks the schema, listing what is wrong with each vector.
+// Each task package runs genrefs for its own task from a go:generate
+// directive, so go generate beside a changed implementation rewrites its file.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("genrefs", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "../../configs/reference_vectors.json", "parameter matrix of every task")
+	outDir := flags.String("out", "../../data/reference_hashes", "directory of the <task>.json files")
+	embedDir := flags.String("embed", "../../tasks", "directory of the task packages that embed a copy of each file, none if empty")
+	check := flags.Bool("check", false, "report out-of-date files instead of writing them")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "genrefs:", err)
+		return 1
+	}
+	names := flags.Args()
+	if len(names) == 0 {
+		for name := range config {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+	}
+
+	status := 0
+	for _, name := range names {
+		specs, ok := config[name]
+		if !ok {
+			fmt.Fprintf(stderr, "genrefs: %s is not in %s\n", name, *configPath)
+			return 1
+		}
+		data, count, err := generate(name, specs)
+		if err != nil {
+			fmt.Fprintf(stderr, "genrefs: %s: %v\n", name, err)
+			return 1
+		}
+
+		paths := []string{filepath.Join(*outDir, name+".json")}
+		if *embedDir != "" {
+			paths = append(paths, filepath.Join(*embedDir, embeddedCopy(name)))
+		}
+		for _, path := range paths {
+			if *check {
+				current, err := os.ReadFile(path)
+				if err != nil {
+					fmt.Fprintln(stderr, "genrefs:", err)
+					status = 1
+					continue
+				}
+				if err := tasks[name].schema.Validate(current); err != nil {
+					fmt.Fprintf(stderr, "genrefs: %s: %v\n", path, err)
+					status = 1
+				}
+				if !bytes.Equal(current, data) {
+					fmt.Fprintf(stderr, "genrefs: %s is out of date; run go generate in the task's package\n", path)
+					for _, line := range drift(current, data) {
+						fmt.Fprintln(stderr, "\t"+line)
+					}
+					status = 1
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				fmt.Fprintln(stderr, "genrefs:", err)
+				return 1
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				fmt.Fprintln(stderr, "genrefs:", err)
+				return 1
+			}
+			fmt.Fprintf(stdout, "%s: %d vectors\n", path, count)
+		}
+	}
+	return status
+}
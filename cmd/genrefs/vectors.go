@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unsafe"
+
+	"json_parse_wasm/jsonparse"
+	"mandelbrot_wasm/mandelbrot"
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+	"wasmbench/common/refschema"
+)
+
+// task is the schema of a task's file, with its params fields and
+// checkpoint stages, the size of its params struct, its DefaultParams, its
+// Go implementation, the fields whose product is a run's work, as its
+// self-calibration counts it, and its package's directory under tasks, which
+// embeds a copy of the file
+type task struct {
+	schema        refschema.Schema
+	size          uintptr
+	defaultParams unsafe.Pointer
+	run           func(paramsPtr, resultPtr uintptr) uint32 // run_task_v2
+	workFields    []string
+	pkg           string
+}
+
+var tasks = map[string]task{
+	"mandelbrot": {mandelbrot.ReferenceSchema(), unsafe.Sizeof(mandelbrot.MandelbrotParams{}), unsafe.Pointer(&mandelbrot.DefaultParams),
+		mandelbrot.RunTaskV2, []string{"width", "height", "max_iter"}, "mandelbrot/tinygo/mandelbrot"},
+	"matrix_mul": {matrixmul.ReferenceSchema(), unsafe.Sizeof(matrixmul.MatrixMulParams{}), unsafe.Pointer(&matrixmul.DefaultParams),
+		matrixmul.RunTaskV2, []string{"dimension", "dimension", "dimension"}, "matrix_mul/tinygo/matrixmul"},
+	"json_parse": {jsonparse.ReferenceSchema(), unsafe.Sizeof(jsonparse.JsonParseParams{}), unsafe.Pointer(&jsonparse.DefaultParams),
+		jsonparse.RunTaskV2, []string{"record_count"}, "json_parse/tinygo/jsonparse"},
+}
+
+// embeddedCopy is where under the tasks directory the package of task embeds
+// its reference file, for its tests to read wherever they run
+func embeddedCopy(name string) string {
+	return filepath.Join(tasks[name].pkg, "testdata", "reference_hashes.json")
+}
+
+// vectorSpec is one entry of a task's list in the config. Without axes it is
+// a single vector; with axes it is a grid of one vector per combination of
+// one point from each axis, merged over params and named
+// <name>_<index on axis 0>_<index on axis 1>... Descriptions are templates
+// over the vector's params, e.g. "records={{.record_count}}".
+type vectorSpec struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Category    string                     `json:"category"`
+	Params      map[string]json.Number     `json:"params"`
+	Axes        [][]map[string]json.Number `json:"axes"`
+}
+
+// referenceVector is an entry of data/reference_hashes/<task>.json
+type referenceVector struct {
+	Name              string       `json:"name"`
+	Description       string       `json:"description"`
+	Params            vectorParams `json:"params"`
+	ExpectedHash      uint32       `json:"expected_hash"`
+	ExpectedStatus    uint32       `json:"expected_status,omitempty"`     // Status of a rejected run
+	ExpectedErrorCode uint32       `json:"expected_error_code,omitempty"` // Shared error code of a rejected run
+	ExpectedStages    stageHashes  `json:"expected_stages,omitempty"`     // Of a run that succeeds
+	Category          string       `json:"category"`
+}
+
+// stageHashes are a vector's expected_stages: the checkpoint hash of each
+// stage before the result, whose hash is expected_hash, by stage name
+type stageHashes []stageHash
+
+type stageHash struct {
+	name string
+	hash uint32
+}
+
+// MarshalJSON writes the hashes as an object in stage order, the order the
+// run reaches them
+func (s stageHashes) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, stage := range s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Quote(stage.name) + ":" + strconv.FormatUint(uint64(stage.hash), 10))
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// UnmarshalJSON reads the hashes back in the order the file lists them
+func (s *stageHashes) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return errors.New("expected_stages is not an object of stage hashes")
+	}
+	*s = nil
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var hash uint32
+		if err := decoder.Decode(&hash); err != nil {
+			return err
+		}
+		*s = append(*s, stageHash{token.(string), hash})
+	}
+	return nil
+}
+
+// loadConfig reads the parameter matrix, a JSON object of each task's vector list
+func loadConfig(path string) (map[string][]vectorSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config map[string][]vectorSpec
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// generate runs every vector of specs, then the task's boundary vectors,
+// through the named task and returns the reference file's contents and its
+// number of vectors. A file that would
+// break the task's schema, e.g. with a category the schema does not list, is
+// an error rather than written.
+func generate(name string, specs []vectorSpec) ([]byte, int, error) {
+	t, ok := tasks[name]
+	if !ok {
+		return nil, 0, errors.New("no such task")
+	}
+
+	var vectors []referenceVector
+	seen := map[string]bool{}
+	for _, spec := range append(slices.Clip(specs), t.boundaries()...) {
+		for _, single := range spec.expand() {
+			if seen[single.Name] {
+				return nil, 0, fmt.Errorf("duplicate vector %s", single.Name)
+			}
+			seen[single.Name] = true
+			vector, err := t.reference(single)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", single.Name, err)
+			}
+			vectors = append(vectors, vector)
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(vectors); err != nil {
+		return nil, 0, err
+	}
+	// Without the encoder's trailing newline, like the files the Rust generators wrote
+	data := bytes.TrimSuffix(out.Bytes(), []byte("\n"))
+	if err := t.schema.Validate(data); err != nil {
+		return nil, 0, err
+	}
+	return data, len(vectors), nil
+}
+
+// expand returns the single vectors of spec: spec itself, or its grid
+func (spec vectorSpec) expand() []vectorSpec {
+	if len(spec.Axes) == 0 {
+		return []vectorSpec{spec}
+	}
+	var vectors []vectorSpec
+	index := make([]int, len(spec.Axes))
+	for {
+		single := vectorSpec{Name: spec.Name, Description: spec.Description, Category: spec.Category, Params: map[string]json.Number{}}
+		for name, value := range spec.Params {
+			single.Params[name] = value
+		}
+		for axis, i := range index {
+			single.Name += "_" + strconv.Itoa(i)
+			for name, value := range spec.Axes[axis][i] {
+				single.Params[name] = value
+			}
+		}
+		vectors = append(vectors, single)
+
+		// Advance the last axis fastest, as nested loops over the axes would
+		axis := len(index) - 1
+		for ; axis >= 0; axis-- {
+			if index[axis]++; index[axis] < len(spec.Axes[axis]) {
+				break
+			}
+			index[axis] = 0
+		}
+		if axis < 0 {
+			return vectors
+		}
+	}
+}
+
+// runResult receives run_task_v2's result. It is a global because the task
+// writes it through a uintptr: a local could move with the goroutine's stack
+// when a run grows it, leaving the write in the old stack.
+var runResult common.TaskResult
+
+// reference runs a single vector and records its outcome
+func (t task) reference(spec vectorSpec) (referenceVector, error) {
+	params := vectorParams{fields: t.schema.Fields, values: spec.Params}
+	data, err := json.Marshal(spec.Params)
+	if err != nil {
+		return referenceVector{}, err
+	}
+	// Backed by uint64s so the f64 and u64 fields are aligned
+	words := make([]uint64, (t.size+7)/8)
+	if status, message := cli.ParamsFromJSON(data, t.schema.Fields, unsafe.Pointer(&words[0])); status != common.StatusOK {
+		return referenceVector{}, errors.New(message)
+	}
+	description, err := params.describe(spec.Description)
+	if err != nil {
+		return referenceVector{}, err
+	}
+
+	common.EnableCheckpoints(true)
+	defer common.EnableCheckpoints(false)
+	status := t.run(uintptr(unsafe.Pointer(&words[0])), uintptr(unsafe.Pointer(&runResult)))
+	runtime.KeepAlive(words)
+	vector := referenceVector{Name: spec.Name, Description: description, Params: params, Category: spec.Category}
+	if status == common.StatusOK {
+		vector.ExpectedHash = runResult.Hash
+		// The last stage is the result itself
+		for stage, name := range t.schema.Stages[:len(t.schema.Stages)-1] {
+			if hash, ok := common.CheckpointHash(uint32(stage)); ok {
+				vector.ExpectedStages = append(vector.ExpectedStages, stageHash{name, hash})
+			}
+		}
+	} else {
+		vector.ExpectedStatus, vector.ExpectedErrorCode = status, common.ErrorCode()
+	}
+	return vector, nil
+}
+
+// vectorParams are a vector's params, written in the order of the task's
+// params struct, with f64 fields always carrying a fraction or exponent as
+// serde_json writes them
+type vectorParams struct {
+	fields []common.ParamField
+	values map[string]json.Number
+}
+
+func (p vectorParams) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for _, field := range p.fields {
+		value, ok := p.values[field.Name]
+		if !ok {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Quote(field.Name) + ":")
+		if field.Type == common.FieldF64 {
+			f, err := value.Float64()
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(formatFloat(f))
+		} else {
+			n, err := strconv.ParseUint(value.String(), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(strconv.FormatUint(n, 10))
+		}
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// templateValue is a param as a description template sees it: a number that
+// prints without a fraction when it has none, and takes printf verbs like %.3f
+type templateValue float64
+
+func (v templateValue) String() string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 64)
+}
+
+// describe expands the description template over the params
+func (p vectorParams) describe(description string) (string, error) {
+	tmpl, err := template.New("description").Option("missingkey=error").Parse(description)
+	if err != nil {
+		return "", err
+	}
+	values := make(map[string]templateValue, len(p.values))
+	for name, value := range p.values {
+		f, err := value.Float64()
+		if err != nil {
+			return "", err
+		}
+		values[name] = templateValue(f)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, values); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// formatFloat writes f the way serde_json does: the shortest digits that
+// round-trip, in plain notation with at least one fractional digit while at
+// most 16 digits precede the decimal point or 4 zeros follow it, and in
+// exponent notation (1e-10, 1.5e300) past that
+func formatFloat(f float64) string {
+	sign := ""
+	if f < 0 || (f == 0 && 1/f < 0) {
+		sign, f = "-", -f
+	}
+	// d.ddde±XX, so the digits are the mantissa without its point
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	exp, _ := strconv.Atoi(exponent)
+	point := exp + 1 // Digits before the decimal point
+
+	switch {
+	case len(digits) <= point && point <= 16:
+		return sign + digits + strings.Repeat("0", point-len(digits)) + ".0"
+	case 0 < point && point <= 16:
+		return sign + digits[:point] + "." + digits[point:]
+	case -5 < point && point <= 0:
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	case len(digits) == 1:
+		return sign + digits + "e" + strconv.Itoa(exp)
+	default:
+		return sign + digits[:1] + "." + digits[1:] + "e" + strconv.Itoa(exp)
+	}
+}
+
+// drift lists how the reference file current differs from generated, one
+// line per vector that changed, disappeared or is new, so -check says which
+// outputs moved rather than only that the file did
+func drift(current, generated []byte) []string {
+	type entry struct {
+		name string
+		raw  json.RawMessage
+	}
+	parse := func(data []byte) ([]entry, error) {
+		var raws []json.RawMessage
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, err
+		}
+		entries := make([]entry, len(raws))
+		for i, raw := range raws {
+			var v struct{ Name string }
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return nil, err
+			}
+			entries[i] = entry{v.Name, raw}
+		}
+		return entries, nil
+	}
+	old, err := parse(current)
+	if err != nil {
+		return []string{"not a list of vectors: " + err.Error()}
+	}
+	now, _ := parse(generated)
+
+	var lines []string
+	for _, n := range now {
+		i := slices.IndexFunc(old, func(o entry) bool { return o.name == n.name })
+		if i < 0 {
+			lines = append(lines, n.name+": missing")
+			continue
+		}
+		if line := vectorDrift(old[i].raw, n.raw); line != "" {
+			lines = append(lines, n.name+": "+line)
+		}
+	}
+	for _, o := range old {
+		if !slices.ContainsFunc(now, func(n entry) bool { return n.name == o.name }) {
+			lines = append(lines, o.name+": no longer in the config")
+		}
+	}
+	return lines
+}
+
+// vectorDrift describes how a vector's generated entry differs from its
+// committed one, "" when they are the same
+func vectorDrift(old, now json.RawMessage) string {
+	var a, b map[string]json.RawMessage
+	if json.Unmarshal(old, &a) != nil || json.Unmarshal(now, &b) != nil {
+		return "changed"
+	}
+	var changes []string
+	for _, key := range []string{"expected_hash", "expected_status", "expected_error_code", "expected_stages", "params", "description", "category"} {
+		before, after := compact(a[key]), compact(b[key])
+		if before != after {
+			changes = append(changes, fmt.Sprintf("%s %s, now %s", key, cmp.Or(before, "none"), cmp.Or(after, "none")))
+		}
+	}
+	return strings.Join(changes, "; ")
+}
+
+// compact returns a JSON value without its whitespace
+func compact(raw json.RawMessage) string {
+	var b bytes.Buffer
+	if json.Compact(&b, raw) != nil {
+		return string(raw)
+	}
+	return b.String()
+}
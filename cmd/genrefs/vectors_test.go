@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0.0"},
+		{math.Copysign(0, -1), "-0.0"},
+		{4, "4.0"},
+		{-0.75, "-0.75"},
+		{0.01, "0.01"},
+		{0.0001, "0.0001"},
+		{0.00001, "0.00001"},
+		{0.000001, "1e-6"},
+		{1e-10, "1e-10"},
+		{1e-308, "1e-308"},
+		{-0.7269095996951777, "-0.7269095996951777"},
+		{1e15, "1000000000000000.0"},
+		{1e16, "1e16"},
+		{1.5e300, "1.5e300"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.value); got != tt.expected {
+			t.Errorf("formatFloat(%v) = %s, expected %s", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	specs := []vectorSpec{
+		{
+			Name:        "grid",
+			Description: "{{.dimension}}x{{.dimension}}, seed={{.seed}}",
+			Category:    "small_matrices",
+			Params:      map[string]json.Number{"seed": "7"},
+			Axes:        [][]map[string]json.Number{{{"dimension": "1"}, {"dimension": "2"}}, {{"seed": "1"}, {"seed": "2"}, {"seed": "3"}}},
+		},
+		{Name: "too_large", Description: "Rejected", Category: "errors", Params: map[string]json.Number{"dimension": "2001", "seed": "1"}},
+	}
+	data, count, err := generate("matrix_mul", specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vectors []struct {
+		Name              string            `json:"name"`
+		Description       string            `json:"description"`
+		Params            map[string]uint32 `json:"params"`
+		ExpectedHash      uint32            `json:"expected_hash"`
+		ExpectedStatus    uint32            `json:"expected_status"`
+		ExpectedErrorCode uint32            `json:"expected_error_code"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	boundaries := len(tasks["matrix_mul"].boundaries())
+	if count != 7+boundaries || len(vectors) != count {
+		t.Fatalf("%d vectors, expected 6 from the grid, 1 rejected and %d boundary vectors", len(vectors), boundaries)
+	}
+	// The last axis advances fastest, and axis points override params
+	if grid := vectors[1]; grid.Name != "grid_0_1" || grid.Description != "1x1, seed=2" || grid.Params["seed"] != 2 || grid.ExpectedHash == 0 {
+		t.Errorf("second grid vector %+v, expected grid_0_1 with dimension 1 and seed 2", grid)
+	}
+	if rejected := vectors[6]; rejected.ExpectedHash != 0 || rejected.ExpectedStatus == 0 || rejected.ExpectedErrorCode == 0 {
+		t.Errorf("rejected vector %+v, expected a status and error code without a hash", rejected)
+	}
+	if !bytes.Contains(data, []byte(`"params": {
+      "dimension": 1,
+      "seed": 2
+    }`)) {
+		t.Errorf("params should be written in struct order:\n%s", data)
+	}
+
+	if _, _, err := generate("matrix_mul", append(specs, specs[1])); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Errorf("error %v, expected a duplicate vector name to be rejected", err)
+	}
+	unknown := vectorSpec{Name: "one", Category: "large_matrices", Params: map[string]json.Number{"dimension": "1"}}
+	if _, _, err := generate("matrix_mul", []vectorSpec{unknown}); err == nil || !strings.Contains(err.Error(), `one: category: "large_matrices" is not a category of matrix_mul`) {
+		t.Errorf("error %v, expected the category the schema does not list to be named", err)
+	}
+}
+
+func TestBoundaries(t *testing.T) {
+	names := func(task string) []string {
+		var names []string
+		for _, spec := range tasks[task].boundaries() {
+			names = append(names, strings.TrimPrefix(spec.Name, "boundary_"))
+		}
+		return names
+	}
+	options := []string{"scale_over", "profile_over", "warmup_iterations_over", "verification_over", "allocator_over", "hash_algorithm_over", "generator_over"}
+
+	// 2000³ multiply-adds are over the budget, so the dimension at its limit is left out
+	want := slices.Concat([]string{"dimension_zero", "dimension_one", "dimension_over"}, options, []string{"seed_zero", "seed_max"})
+	if got := names("matrix_mul"); !slices.Equal(got, want) {
+		t.Errorf("matrix_mul boundaries %v, expected %v", got, want)
+	}
+	// Both sides at max_image_dimension make max_total_pixels, so it cannot be exceeded alone
+	want = slices.Concat([]string{"width_zero", "width_one", "width_max", "width_over", "height_zero", "height_one", "height_max", "height_over"}, options)
+	if got := names("mandelbrot"); !slices.Equal(got, want) {
+		t.Errorf("mandelbrot boundaries %v, expected %v", got, want)
+	}
+
+	for _, spec := range tasks["mandelbrot"].boundaries() {
+		if spec.Name != "boundary_width_max" {
+			continue
+		}
+		// At its limit the other dimension is 1, and the rest are DefaultParams'
+		if spec.Params["width"] != "10000" || spec.Params["height"] != "1" || spec.Params["max_iter"] != "100" || spec.Params["scale_factor"] != "3" {
+			t.Errorf("boundary_width_max params %v", spec.Params)
+		}
+	}
+}
+
+func TestReferenceFilesAreCurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs every reference vector")
+	}
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-check"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit status %d: %s(run go generate in the task's package to rewrite them)", code, stderr.String())
+	}
+}
+
+func TestRunWritesFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "vectors.json")
+	if err := os.WriteFile(config, []byte(`{"json_parse": [{"name": "one", "description": "", "category": "runner", "params": {"record_count": 1, "seed": 1}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	embed := filepath.Join(dir, "tasks")
+	if code := run([]string{"-config", config, "-out", dir, "-embed", embed}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	if code := run([]string{"-config", config, "-out", dir, "-embed", embed, "-check"}, &stdout, &stderr); code != 0 {
+		t.Errorf("a file just written should be current: %s", stderr.String())
+	}
+
+	// The package's copy is the file, and a stale copy fails -check too
+	copyPath := filepath.Join(embed, "json_parse", "tinygo", "jsonparse", "testdata", "reference_hashes.json")
+	copied, err := os.ReadFile(copyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(copyPath, append(copied, ' '), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"-config", config, "-out", dir, "-embed", embed, "-check"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), copyPath) {
+		t.Errorf("exit status %d for a stale copy, expected 1 naming it: %s", code, stderr.String())
+	}
+
+	// A committed hash the implementation no longer produces is named
+	path := filepath.Join(dir, "json_parse.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []referenceVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	stale := bytes.Replace(data, []byte(strconv.FormatUint(uint64(vectors[0].ExpectedHash), 10)), []byte("7"), 1)
+	if err := os.WriteFile(path, stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"-config", config, "-out", dir, "-embed", "", "-check"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d for a stale file, expected 1", code)
+	}
+	if want := fmt.Sprintf("one: expected_hash 7, now %d", vectors[0].ExpectedHash); !strings.Contains(stderr.String(), want) {
+		t.Errorf("-check said %q, expected it to name the vector: %s", stderr.String(), want)
+	}
+
+	// A file older than the schema is named stale, and what it lacks listed
+	old := regexp.MustCompile(`,\s*"expected_stages": \{[^}]*\}`).ReplaceAll(data, nil)
+	if err := os.WriteFile(path, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"-config", config, "-out", dir, "-embed", "", "-check"}, &stdout, &stderr); code != 1 ||
+		!strings.Contains(stderr.String(), "one: expected_stages: missing") || !strings.Contains(stderr.String(), "the file is stale") {
+		t.Errorf("exit status %d for a file without expected_stages, expected 1 naming it stale: %s", code, stderr.String())
+	}
+	if code := run([]string{"-config", config, "-out", dir, "-embed", "", "mandelbrot"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit status %d for a task missing from the config, expected 1", code)
+	}
+}
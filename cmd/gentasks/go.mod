@@ -0,0 +1,6 @@
+module wasmbench/gentasks
+
+go 1.25.0
+
+// Task manifest generator: reads the task packages' source, so it needs
+// none of them as a dependency
@@ -0,0 +1,99 @@
+// Command gentasks writes configs/tasks.json, the task manifest, from the Go
+// source of the task packages, so the harnesses and tools share one
+// definition of each task instead of keeping their own. Each package is read
+// with go/ast and type-checked with go/types, and the manifest records, with
+// the ABI version:
+//
+//   - the params struct: its wasm32 size, and each field's name as
+//     ParamFields gives it, type, offset and comment
+//   - DefaultParams, the params of a run that sets none
+//   - TaskLimits, the largest accepted value of each bounded param
+//   - the variant and checkpoint stages of get_task_info
+//   - every //go:export function of the TinyGo build, with its wasm signature
+//
+// A ParamFields entry whose name, type or order disagrees with the struct
+// fails the generator, so the hand-written table cannot drift either.
+// cmd/gennode builds harness/node/bench.js from the manifest, the browser
+// harness reads its params layouts from it, and cmd/bench and cmd/genrefs
+// compile in the same packages.
+//
+// Usage:
+//
+//	gentasks [-tasks dir] [-out file] [-check]
+//
+// With -check, nothing is written and the exit status is 1 if the manifest is
+// out of date. Regenerate it whenever a task package's params, defaults,
+// limits or exports change.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gentasks", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	tasksDir := flags.String("tasks", "../../tasks", "directory of the <task>/tinygo modules")
+	out := flags.String("out", "../../configs/tasks.json", "manifest to write")
+	check := flags.Bool("check", false, "report an out-of-date manifest instead of writing it")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	data, err := generate(*tasksDir)
+	if err != nil {
+		fmt.Fprintln(stderr, "gentasks:", err)
+		return 1
+	}
+	if *check {
+		if current, err := os.ReadFile(*out); err != nil || !bytes.Equal(current, data) {
+			fmt.Fprintf(stderr, "gentasks: %s is out of date\n", *out)
+			return 1
+		}
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(stderr, "gentasks:", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: written\n", *out)
+	return 0
+}
+
+// generate returns the manifest of every task with a TinyGo module under dir
+func generate(dir string) ([]byte, error) {
+	// go/build resolves the packages' imports by module only from absolute paths
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := filepath.Glob(filepath.Join(dir, "*", "tinygo", "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no <task>/tinygo modules under %s", dir)
+	}
+	m := Manifest{Tasks: map[string]Task{}, importer: newModuleImporter()}
+	for _, module := range modules {
+		if err := m.add(filepath.Dir(module)); err != nil {
+			return nil, err
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestManifestIsCurrent(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-check"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit status %d: %s(run gentasks to rewrite it)", code, stderr.String())
+	}
+}
+
+// toyTask is a task package with every declaration the generator reads
+const toyTask = `package toy
+
+import (
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+type ToyParams struct {
+	Count uint32  // Elements per run
+	Ratio float64 // Fraction kept
+	Seed  uint64
+}
+
+func ParamFields() []common.ParamField {
+	var p ToyParams
+	return []common.ParamField{
+		{Name: "count", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Count)},
+		{Name: "ratio", Type: common.FieldF64, Offset: unsafe.Offsetof(p.Ratio)},
+		{Name: "seed", Type: common.FieldU64, Offset: unsafe.Offsetof(p.Seed)},
+	}
+}
+
+var DefaultParams = ToyParams{Count: 16, Ratio: 0.5}
+
+const MaxCount uint32 = 1 << 20
+
+type Limits struct {
+	WordCount uint32
+	MaxScale  uint32
+	MaxCount  uint32
+}
+
+var TaskLimits = Limits{WordCount: 2, MaxScale: common.ScaleLarge, MaxCount: MaxCount}
+
+var stages = []string{"input", "output"}
+
+var info = common.EncodeTaskInfo(common.TaskInfo{
+	Task:       "toy",
+	Variant:    "plain",
+	ParamsSize: unsafe.Sizeof(ToyParams{}),
+	Params:     ParamFields(),
+	Stages:     stages,
+})
+`
+
+const toyExports = `//go:build tinygo
+
+package main
+
+//go:export init64
+func initWasm64(seed uint64) {}
+
+//go:export run_task_v2
+func runTaskV2(paramsPtr, resultPtr uintptr) uint32 { return 0 }
+`
+
+// writeToyTask writes the toy task's module under tasks and returns its
+// package file
+func writeToyTask(t *testing.T, tasks string) string {
+	t.Helper()
+	common, err := filepath.Abs("../../tasks/common")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tasks, "toy", "tinygo")
+	if err := os.MkdirAll(filepath.Join(dir, "toy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod":          "module toy_wasm\n\ngo 1.25.0\n\nrequire wasmbench/common v0.0.0\n\nreplace wasmbench/common => " + common + "\n",
+		"exports_wasm.go": toyExports,
+		"toy/toy.go":      toyTask,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "toy", "toy.go")
+}
+
+func TestRunReadsTaskSource(t *testing.T) {
+	dir := t.TempDir()
+	tasks := filepath.Join(dir, "tasks")
+	source := writeToyTask(t, tasks)
+	out := filepath.Join(dir, "tasks.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-tasks", tasks, "-out", out}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	toy, ok := m.Tasks["toy"]
+	if m.ABIVersion == 0 || !ok {
+		t.Fatalf("manifest %s, expected the toy task and the ABI version", data)
+	}
+	// u64 and f64 fields are 8-byte aligned in wasm32 memory
+	params := []Param{
+		{"count", "u32", 0, "Elements per run"},
+		{"ratio", "f64", 8, "Fraction kept"},
+		{"seed", "u64", 16, ""},
+	}
+	if toy.Package != "toy_wasm/toy" || toy.ParamsType != "ToyParams" || toy.ParamsSize != 24 || !reflect.DeepEqual(toy.Params, params) {
+		t.Errorf("params %+v of %s, size %d", toy.Params, toy.ParamsType, toy.ParamsSize)
+	}
+	if toy.Defaults["count"] != "16" || toy.Defaults["ratio"] != "0.5" || len(toy.Defaults) != 2 {
+		t.Errorf("defaults %v, expected count 16 and ratio 0.5", toy.Defaults)
+	}
+	if !reflect.DeepEqual(toy.Limits, map[string]uint64{"max_scale": 4, "max_count": 1 << 20}) {
+		t.Errorf("limits %v", toy.Limits)
+	}
+	exports := []Export{
+		{"init64", []string{"i64"}, []string{}},
+		{"run_task_v2", []string{"i32", "i32"}, []string{"i32"}},
+	}
+	if toy.Variant != "plain" || !reflect.DeepEqual(toy.Stages, []string{"input", "output"}) || !reflect.DeepEqual(toy.Exports, exports) {
+		t.Errorf("variant %q, stages %v, exports %+v", toy.Variant, toy.Stages, toy.Exports)
+	}
+
+	// A ParamFields entry that disagrees with the struct fails the generator
+	wrong := strings.Replace(toyTask, `Type: common.FieldF64`, `Type: common.FieldU32`, 1)
+	if err := os.WriteFile(source, []byte(wrong), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"-tasks", tasks, "-out", out}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "ParamFields gives Ratio type u32") {
+		t.Errorf("exit status %d with a mistyped ParamFields entry: %s", code, stderr.String())
+	}
+}
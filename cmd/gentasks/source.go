@@ -0,0 +1,502 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Manifest is configs/tasks.json: every task as its Go source defines it
+type Manifest struct {
+	ABIVersion int64           `json:"abi_version"`
+	Tasks      map[string]Task `json:"tasks"`
+
+	importer *moduleImporter // Of the tasks added
+}
+
+// Task is a task's entry in the manifest
+type Task struct {
+	Package    string                 `json:"package"` // Of its TinyGo module
+	Variant    string                 `json:"variant"`
+	ParamsType string                 `json:"params_type"`
+	ParamsSize int64                  `json:"params_size"` // In wasm32 linear memory
+	Params     []Param                `json:"params"`      // In declaration order
+	Defaults   map[string]json.Number `json:"defaults"`    // DefaultParams, but for its zero fields
+	Limits     map[string]uint64      `json:"limits"`      // TaskLimits, but for its word count
+	Stages     []string               `json:"stages"`      // Of get_checkpoints
+	Exports    []Export               `json:"exports"`     // Of the TinyGo build, by name
+}
+
+// Param is one params struct field, named as ParamFields names it
+type Param struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"` // u32, u64 or f64
+	Offset int64  `json:"offset"`
+	Doc    string `json:"doc,omitempty"` // The field's comment
+}
+
+// Export is a //go:export function with its wasm signature
+type Export struct {
+	Name    string   `json:"name"`
+	Params  []string `json:"params"`
+	Results []string `json:"results"`
+}
+
+// wasm32 is the layout of TinyGo's wasm targets: 4-byte pointers, 8-byte
+// aligned u64 and f64 fields
+var wasm32 = &types.StdSizes{WordSize: 4, MaxAlign: 8}
+
+// fieldTypes are the params field types, by Go type
+var fieldTypes = map[string]string{"uint32": "u32", "uint64": "u64", "float64": "f64"}
+
+// wasmTypes are the wasm value types of the export signatures, by Go type
+var wasmTypes = map[string]string{"uint32": "i32", "uintptr": "i32", "uint64": "i64", "float64": "f64"}
+
+// source is a type-checked task package
+type source struct {
+	fset  *token.FileSet
+	files []*ast.File
+	pkg   *types.Package
+	info  *types.Info
+}
+
+// add reads the task whose TinyGo module is in dir into m: the one package
+// below it, type-checked with its imports read from source, and the module's
+// exports_wasm.go
+func (m *Manifest) add(dir string) error {
+	module, err := modulePath(dir)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var packages []string
+	for _, e := range entries {
+		if e.IsDir() {
+			packages = append(packages, e.Name())
+		}
+	}
+	if len(packages) != 1 {
+		return fmt.Errorf("%s: %d packages, expected the task's one", dir, len(packages))
+	}
+	src, err := check(filepath.Join(dir, packages[0]), module+"/"+packages[0], m.importer)
+	if err != nil {
+		return err
+	}
+	name, task, err := src.task()
+	if err == nil {
+		task.Exports, err = exports(filepath.Join(dir, "exports_wasm.go"))
+	}
+	var abi int64
+	if err == nil {
+		abi, err = src.abiVersion()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", src.pkg.Path(), err)
+	}
+	if _, ok := m.Tasks[name]; ok {
+		return fmt.Errorf("%s: task %s is already in %s", src.pkg.Path(), name, m.Tasks[name].Package)
+	}
+	m.ABIVersion, m.Tasks[name] = abi, task
+	return nil
+}
+
+// abiVersion returns common.ABIVersion, as the task package imports it
+func (src *source) abiVersion() (int64, error) {
+	for _, imported := range src.pkg.Imports() {
+		if c, ok := imported.Scope().Lookup("ABIVersion").(*types.Const); ok && imported.Path() == "wasmbench/common" {
+			if v, exact := constant.Int64Val(c.Val()); exact {
+				return v, nil
+			}
+		}
+	}
+	return 0, errors.New("no wasmbench/common.ABIVersion")
+}
+
+// modulePath returns the module path of the go.mod in dir
+func modulePath(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.TrimSpace(path), nil
+		}
+	}
+	return "", fmt.Errorf("%s/go.mod has no module line", dir)
+}
+
+// check parses and type-checks the host build of the package in dir
+func check(dir, path string, imp *moduleImporter) (*source, error) {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	src := &source{
+		fset: imp.fset,
+		info: &types.Info{Types: map[ast.Expr]types.TypeAndValue{}},
+	}
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(src.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		src.files = append(src.files, file)
+	}
+	config := types.Config{Importer: imp}
+	if src.pkg, err = config.Check(path, src.fset, src.files, src.info); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// moduleImporter type-checks imports from source. go/build takes a module
+// path without a dot, such as wasmbench/common, for a missing standard
+// package, so the go command finds the directory of every import outside the
+// standard library instead.
+type moduleImporter struct {
+	fset     *token.FileSet
+	std      types.ImporterFrom
+	packages map[string]*types.Package
+}
+
+func newModuleImporter() *moduleImporter {
+	fset := token.NewFileSet()
+	return &moduleImporter{fset, importer.ForCompiler(fset, "source", nil).(types.ImporterFrom), map[string]*types.Package{}}
+}
+
+func (imp *moduleImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *moduleImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg, ok := imp.packages[path]; ok {
+		return pkg, nil
+	}
+	if path == "unsafe" || !strings.Contains(path, "/") && !strings.Contains(path, ".") {
+		return imp.std.ImportFrom(path, dir, mode)
+	}
+	cmd := exec.Command("go", "list", "-f", "{{.Standard}} {{.Dir}}", path)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list %s: %w", path, err)
+	}
+	standard, pkgDir, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	if standard == "true" {
+		return imp.std.ImportFrom(path, dir, mode)
+	}
+	src, err := check(pkgDir, path, imp)
+	if err != nil {
+		return nil, err
+	}
+	imp.packages[path] = src.pkg
+	return src.pkg, nil
+}
+
+// task reads the task's entry from its declarations: the TaskInfo literal
+// names the task, its variant, params type and stages, ParamFields the params
+// and DefaultParams and TaskLimits, or taskLimits where it is unexported,
+// the rest
+func (src *source) task() (string, Task, error) {
+	var task Task
+	info, err := src.literal(nil, "TaskInfo")
+	if err != nil {
+		return "", task, err
+	}
+	name := constant.StringVal(src.constant(info["Task"]))
+	task.Package = src.pkg.Path()
+	task.Variant = constant.StringVal(src.constant(info["Variant"]))
+	sizeof, ok := info["ParamsSize"].(*ast.CallExpr)
+	if !ok || len(sizeof.Args) != 1 {
+		return "", task, errors.New("TaskInfo.ParamsSize is not unsafe.Sizeof of the params struct")
+	}
+	paramsType, ok := src.info.Types[sizeof.Args[0]].Type.(*types.Named)
+	if !ok {
+		return "", task, errors.New("TaskInfo.ParamsSize is not unsafe.Sizeof of the params struct")
+	}
+	task.ParamsType = paramsType.Obj().Name()
+	for _, stage := range src.elements(info["Stages"]) {
+		task.Stages = append(task.Stages, constant.StringVal(src.constant(stage)))
+	}
+
+	if task.ParamsSize, task.Params, err = src.params(paramsType); err != nil {
+		return "", task, err
+	}
+	byField := map[string]Param{}
+	fields := paramsType.Underlying().(*types.Struct)
+	for i := range fields.NumFields() {
+		byField[fields.Field(i).Name()] = task.Params[i]
+	}
+	defaults, err := src.literal(src.value("DefaultParams"), task.ParamsType)
+	if err != nil {
+		return "", task, err
+	}
+	task.Defaults = map[string]json.Number{}
+	for field, value := range defaults {
+		param := byField[field]
+		v := src.constant(value)
+		if param.Type == "f64" {
+			f, _ := constant.Float64Val(constant.ToFloat(v))
+			task.Defaults[param.Name] = json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		} else {
+			u, _ := constant.Uint64Val(constant.ToInt(v))
+			task.Defaults[param.Name] = json.Number(strconv.FormatUint(u, 10))
+		}
+	}
+	limits, err := src.literal(cmp.Or(src.value("TaskLimits"), src.value("taskLimits")), "Limits")
+	if err != nil {
+		return "", task, err
+	}
+	task.Limits = map[string]uint64{}
+	for field, value := range limits {
+		if field != "WordCount" {
+			task.Limits[snakeCase(field)], _ = constant.Uint64Val(src.constant(value))
+		}
+	}
+	return name, task, nil
+}
+
+// params lays out the params struct for wasm32 and names each field as the
+// ParamFields literal does, refusing a literal that disagrees with the struct
+func (src *source) params(paramsType *types.Named) (int64, []Param, error) {
+	fields := paramsType.Underlying().(*types.Struct)
+	vars := make([]*types.Var, fields.NumFields())
+	for i := range vars {
+		vars[i] = fields.Field(i)
+	}
+	offsets := wasm32.Offsetsof(vars)
+	comments := src.fieldComments(paramsType.Obj().Name())
+
+	fn := src.function("ParamFields")
+	if fn == nil {
+		return 0, nil, errors.New("no ParamFields function")
+	}
+	var entries []ast.Expr
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.CompositeLit); ok && entries == nil && len(lit.Elts) > 0 {
+			if _, ok := lit.Elts[0].(*ast.CompositeLit); ok {
+				entries = lit.Elts
+			}
+		}
+		return true
+	})
+	if len(entries) != len(vars) {
+		return 0, nil, fmt.Errorf("ParamFields describes %d fields, %s has %d", len(entries), paramsType.Obj().Name(), len(vars))
+	}
+	params := make([]Param, len(vars))
+	for i, entry := range entries {
+		values, err := src.literal(entry, "ParamField")
+		if err != nil {
+			return 0, nil, err
+		}
+		offsetof, ok := values["Offset"].(*ast.CallExpr)
+		var field string
+		if ok && len(offsetof.Args) == 1 {
+			if sel, ok := offsetof.Args[0].(*ast.SelectorExpr); ok {
+				field = sel.Sel.Name
+			}
+		}
+		if field != vars[i].Name() {
+			return 0, nil, fmt.Errorf("ParamFields entry %d is not at the offset of %s, field %d", i, vars[i].Name(), i)
+		}
+		kind := fieldTypes[vars[i].Type().String()]
+		if kind == "" {
+			return 0, nil, fmt.Errorf("%s.%s has type %s, not one of the params field types", paramsType.Obj().Name(), field, vars[i].Type())
+		}
+		if stated := constant.StringVal(src.constant(values["Type"])); stated != kind {
+			return 0, nil, fmt.Errorf("ParamFields gives %s type %s, the struct field is %s", field, stated, kind)
+		}
+		params[i] = Param{
+			Name:   constant.StringVal(src.constant(values["Name"])),
+			Type:   kind,
+			Offset: offsets[i],
+			Doc:    comments[field],
+		}
+	}
+	return wasm32.Sizeof(fields), params, nil
+}
+
+// value returns the value of the package-level variable name, or nil
+func (src *source) value(name string) ast.Expr {
+	for _, file := range src.files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, ident := range vs.Names {
+					if ident.Name == name && i < len(vs.Values) {
+						return vs.Values[i]
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// function returns the declaration of the package-level function name, or nil
+func (src *source) function(name string) *ast.FuncDecl {
+	for _, file := range src.files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
+// fieldComments returns the comment of each field of the struct type name
+func (src *source) fieldComments(name string) map[string]string {
+	comments := map[string]string{}
+	for _, file := range src.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok || spec.Name.Name != name {
+				return true
+			}
+			for _, field := range spec.Type.(*ast.StructType).Fields.List {
+				text := strings.TrimSpace(field.Comment.Text() + field.Doc.Text())
+				for _, ident := range field.Names {
+					comments[ident.Name] = strings.Join(strings.Fields(text), " ")
+				}
+			}
+			return false
+		})
+	}
+	return comments
+}
+
+// literal returns the keyed fields of the composite literal of type typeName
+// within expr, or anywhere in the package if expr is nil, the first one found
+func (src *source) literal(expr ast.Expr, typeName string) (map[string]ast.Expr, error) {
+	var lit *ast.CompositeLit
+	find := func(n ast.Node) bool {
+		if c, ok := n.(*ast.CompositeLit); ok && lit == nil {
+			if named, ok := src.info.Types[c].Type.(*types.Named); ok && named.Obj().Name() == typeName {
+				lit = c
+			}
+		}
+		return lit == nil
+	}
+	if expr != nil {
+		ast.Inspect(expr, find)
+	} else {
+		for _, file := range src.files {
+			ast.Inspect(file, find)
+		}
+	}
+	if lit == nil {
+		return nil, fmt.Errorf("no %s literal", typeName)
+	}
+	values := map[string]ast.Expr{}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return nil, fmt.Errorf("%s literal has unkeyed fields", typeName)
+		}
+		values[kv.Key.(*ast.Ident).Name] = kv.Value
+	}
+	return values, nil
+}
+
+// elements returns the elements of the slice literal expr, or of the one
+// assigned to the package-level variable it names
+func (src *source) elements(expr ast.Expr) []ast.Expr {
+	if ident, ok := expr.(*ast.Ident); ok {
+		expr = src.value(ident.Name)
+	}
+	if lit, ok := expr.(*ast.CompositeLit); ok {
+		return lit.Elts
+	}
+	return nil
+}
+
+// constant returns the value of the constant expression expr; unknown for
+// anything else, which the constant package reads as zero
+func (src *source) constant(expr ast.Expr) constant.Value {
+	if tv, ok := src.info.Types[expr]; ok && tv.Value != nil {
+		return tv.Value
+	}
+	return constant.MakeUnknown()
+}
+
+// exports returns the //go:export functions of the file at path with their
+// wasm signatures, sorted by name
+func exports(path string) ([]Export, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var list []Export
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Doc == nil {
+			continue
+		}
+		for _, comment := range fn.Doc.List {
+			name, ok := strings.CutPrefix(comment.Text, "//go:export ")
+			if !ok {
+				continue
+			}
+			export := Export{Name: strings.TrimSpace(name), Params: []string{}, Results: []string{}}
+			for _, group := range []struct {
+				fields *ast.FieldList
+				types  *[]string
+			}{{fn.Type.Params, &export.Params}, {fn.Type.Results, &export.Results}} {
+				if group.fields == nil {
+					continue
+				}
+				for _, field := range group.fields.List {
+					ident, _ := field.Type.(*ast.Ident)
+					if ident == nil || wasmTypes[ident.Name] == "" {
+						return nil, fmt.Errorf("%s: %s has a %s parameter or result, which has no wasm type here", path, export.Name, types.ExprString(field.Type))
+					}
+					for range max(len(field.Names), 1) {
+						*group.types = append(*group.types, wasmTypes[ident.Name])
+					}
+				}
+			}
+			list = append(list, export)
+		}
+	}
+	slices.SortFunc(list, func(a, b Export) int { return cmp.Compare(a.Name, b.Name) })
+	return list, nil
+}
+
+// snakeCase converts a Go field name to the manifest's key
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
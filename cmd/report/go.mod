@@ -0,0 +1,8 @@
+module wasmbench/report
+
+go 1.25.0
+
+// Session report; the significance tests come from wasmbench/stats
+require wasmbench/stats v0.0.0
+
+replace wasmbench/stats => ../stats
@@ -0,0 +1,100 @@
+// Command report turns session files written by bench -json into a single
+// self-contained HTML report: for each task, a bar chart of every module's
+// median run time at each params point, a scaling curve of the medians
+// across the points when the sessions cover several, a table of the fastest
+// TinyGo build against the fastest Rust build, a table of each module that
+// ran under several runtimes against its fastest runtime, and a table of each
+// pair of cmd/build artifacts whose tinygo flags differ in one flag, the
+// impact of that flag. Each ratio of the tables has a 95% bootstrap
+// confidence interval and the p-value of a Mann-Whitney U test of the runs,
+// and is marked not significant unless both say the two differ. The charts
+// are inline SVG, so the report needs no scripts, network or plotting
+// toolchain.
+//
+// -format markdown writes a short Markdown summary instead, to paste into a
+// discussion or release notes: the sessions, the best and worst TinyGo / Rust
+// ratio, and per task a table of every build's median, spread and binary size
+// at each point, with the language and build flag comparisons. It goes to
+// stdout unless -o names a file.
+//
+// Usage:
+//
+//	report [-o report.html] session.json ...
+//	report -format markdown [-o summary.md] session.json ...
+//
+// Sessions are merged, so runs of the same modules at different -params
+// (say, one session per matrix dimension) make up a scaling curve.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "file to write (default report.html, or stdout for markdown)")
+	format := flags.String("format", "html", "html for the full report, markdown for a summary")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var render func(report, io.Writer) error
+	switch *format {
+	case "html":
+		render = report.render
+		if *output == "" {
+			*output = "report.html"
+		}
+	case "markdown":
+		render = report.renderMarkdown
+	default:
+		fmt.Fprintf(stderr, "report: -format %q is not html or markdown\n", *format)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "report: name at least one session file written by bench -json")
+		return 2
+	}
+
+	var sessions []session
+	for _, path := range flags.Args() {
+		s, err := loadSession(path)
+		if err != nil {
+			fmt.Fprintln(stderr, "report:", err)
+			return 1
+		}
+		sessions = append(sessions, s)
+	}
+
+	r := buildReport(sessions, time.Now().UTC())
+	if *output == "" {
+		if err := render(r, stdout); err != nil {
+			fmt.Fprintln(stderr, "report:", err)
+			return 1
+		}
+		return 0
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintln(stderr, "report:", err)
+		return 1
+	}
+	err = render(r, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "report:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	_ "embed"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// extreme is a TinyGo vs Rust comparison picked out of every task's, for the
+// summary's best and worst ratio
+type extreme struct {
+	Task string
+	ratioRow
+}
+
+// extremes returns the lowest and the highest TinyGo / Rust ratio over the
+// tasks, both nil without any comparison and the same with only one
+func extremes(tasks []taskReport) (best, worst *extreme) {
+	for _, task := range tasks {
+		for _, row := range task.Ratios {
+			if best == nil || row.Ratio < best.Ratio {
+				best = &extreme{task.Name, row}
+			}
+			if worst == nil || row.Ratio > worst.Ratio {
+				worst = &extreme{task.Name, row}
+			}
+		}
+	}
+	return best, worst
+}
+
+// Results are the point's results, fastest first
+func (p pointReport) Results() []result {
+	return p.results
+}
+
+//go:embed report.md.tmpl
+var markdownTemplate string
+
+var summary = template.Must(template.New("summary").Funcs(template.FuncMap{
+	"ms":       formatMs,
+	"size":     formatSize,
+	"versions": formatVersions,
+	"cell":     markdownCell,
+	"base":     filepath.Base,
+	"language": func(r result) string { return r.language() },
+	"div":      func(a, b float64) float64 { return a / b },
+	"percent":  func(f float64) float64 { return 100 * f },
+}).Parse(markdownTemplate))
+
+// renderMarkdown writes the report as a short Markdown summary, to paste into
+// an issue, a discussion or release notes: the sessions, the best and worst
+// TinyGo / Rust ratio, and per task a table of every build at each point with
+// its binary size, and the language and build flag comparisons
+func (r report) renderMarkdown(w io.Writer) error {
+	return summary.Execute(w, r)
+}
+
+// formatSize formats a module's size in bytes, "-" when unknown
+func formatSize(n int64) string {
+	if n <= 0 {
+		return "-"
+	}
+	return formatBytes(uint64(n))
+}
+
+// markdownCell escapes s for a table cell, where a | would end it
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
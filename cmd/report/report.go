@@ -0,0 +1,420 @@
+package main
+
+import (
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sessionVersion is the newest bench session schema the report reads; a
+// newer session may have renamed or dropped what it reads
+const sessionVersion = 1
+
+// session is the part of a bench -json session the report reads
+type session struct {
+	SchemaVersion int       `json:"schema_version"` // 0 before bench versioned its sessions
+	Started       time.Time `json:"started"`
+	Environment   struct {
+		GoVersion  string            `json:"go_version"`
+		OS         string            `json:"os"`
+		Arch       string            `json:"arch"`
+		CPUModel   string            `json:"cpu_model"`
+		CPUs       int               `json:"cpus"`
+		Governor   string            `json:"governor"`
+		Hostname   string            `json:"hostname"`
+		Commit     string            `json:"commit"`
+		Toolchains map[string]string `json:"toolchains"`
+		Runtimes   map[string]string `json:"runtimes"`
+	} `json:"environment"`
+	Results []result `json:"results"`
+}
+
+// result is one module's entry of a session
+type result struct {
+	Module     string                 `json:"module"`
+	Runtime    string                 `json:"runtime"`
+	Agent      string                 `json:"agent"` // Of a bench -agents session
+	Task       string                 `json:"task"`
+	Language   string                 `json:"language"`
+	Variant    string                 `json:"variant"`
+	BuildFlags map[string]string      `json:"build_flags"` // The artifact's tinygo flags, by name
+	Params     map[string]json.Number `json:"params"`
+	Size       int64                  `json:"size"` // Of the module file, 0 in sessions before bench recorded it
+	Stats      struct {
+		N      int     `json:"n"`
+		Min    float64 `json:"min"`
+		Max    float64 `json:"max"`
+		Median float64 `json:"median"`
+		CV     float64 `json:"cv"`
+	} `json:"stats"`
+	SamplesMs []float64 `json:"samples_ms"`
+	Memory    struct {
+		PeakBytes uint64 `json:"peak_bytes"`
+	} `json:"memory"`
+	Error string `json:"error"`
+}
+
+// scaleParams are the params whose product is a task's problem size, the x
+// axis of its scaling curve
+var scaleParams = map[string][]string{
+	"mandelbrot": {"width", "height"},
+	"matrix_mul": {"dimension"},
+	"json_parse": {"record_count"},
+}
+
+// loadSession reads a session file
+func loadSession(path string) (session, error) {
+	var s session
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.SchemaVersion > sessionVersion {
+		return s, fmt.Errorf("%s: session schema version %d is newer than the %d cmd/report reads; update it", path, s.SchemaVersion, sessionVersion)
+	}
+	return s, nil
+}
+
+// language returns the result's source language: from get_task_info, else
+// from the builds/<language>/ directory the module sits in
+func (r result) language() string {
+	if r.Language != "" {
+		return r.Language
+	}
+	if r.Runtime == "native" {
+		return "go"
+	}
+	return filepath.Base(filepath.Dir(r.Module))
+}
+
+// label names the result's build in charts: file name, runtime unless
+// wazero, and the agent that ran it, if any
+func (r result) label() string {
+	label := filepath.Base(r.Module)
+	if r.Runtime != "" && r.Runtime != "wazero" {
+		label += " (" + r.Runtime + ")"
+	}
+	if r.Agent != "" {
+		label += " on " + r.Agent
+	}
+	return label
+}
+
+// point identifies the result's params, and scale is its problem size: the
+// product of the task's scale params, 0 for a task without any
+func (r result) point() (key string, scale float64, label string) {
+	names := make([]string, 0, len(r.Params))
+	for name := range r.Params {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + r.Params[name].String()
+	}
+
+	scale = 1
+	var dimensions []string
+	for _, name := range scaleParams[r.Task] {
+		value, err := strconv.ParseFloat(r.Params[name].String(), 64)
+		if err != nil {
+			scale = 0
+			break
+		}
+		scale *= value
+		dimensions = append(dimensions, r.Params[name].String())
+	}
+	if len(dimensions) == 0 {
+		scale = 0
+	}
+	label = strings.Join(dimensions, "x")
+	if label == "" {
+		label = strings.Join(parts, " ")
+	}
+	return strings.Join(parts, " "), scale, label
+}
+
+// report is the model the HTML template renders
+type report struct {
+	Generated time.Time
+	Sessions  []session
+	Tasks     []taskReport
+	Failures  []result
+	// Lowest and highest TinyGo / Rust ratio of any task, nil without one
+	Best, Worst *extreme
+}
+
+// taskReport is one task's section
+type taskReport struct {
+	Name     string
+	Points   []pointReport // By increasing scale
+	Scaling  template.HTML // Line chart across the points, "" for a single point
+	Ratios   []ratioRow
+	Runtimes []runtimeRow
+	Flags    []flagRow
+}
+
+// pointReport is one params point of a task
+type pointReport struct {
+	Label   string
+	Params  string
+	scale   float64
+	results []result
+	Chart   template.HTML
+}
+
+// ratioRow compares the fastest TinyGo and Rust builds at a point
+type ratioRow struct {
+	Point        string
+	TinyGo, Rust result
+	Ratio        float64 // TinyGo median over Rust median
+	MemoryRatio  float64 // TinyGo peak linear memory over Rust's, 0 when either is unknown
+	Significance significance
+}
+
+// runtimeRow compares a module under a runtime with the same module under
+// the runtime that ran it fastest at a point
+type runtimeRow struct {
+	Point        string
+	Module       string
+	Runtime      result
+	Fastest      result
+	Ratio        float64 // Runtime median over the fastest runtime's median
+	Significance significance
+}
+
+// flagRow compares two builds of a point whose tinygo flags differ in one
+// flag alone, under the same runtime, so the row is that flag's impact
+type flagRow struct {
+	Point        string
+	Flag         string
+	From, To     result  // From has the value first by name, so rows of a flag read alike at every point
+	Ratio        float64 // To median over From median
+	Significance significance
+}
+
+// buildReport groups the sessions' results by task and params point
+func buildReport(sessions []session, generated time.Time) report {
+	r := report{Generated: generated, Sessions: sessions}
+	tasks := map[string]map[string]*pointReport{}
+	for _, s := range sessions {
+		for _, res := range s.Results {
+			if res.Error != "" {
+				r.Failures = append(r.Failures, res)
+				continue
+			}
+			key, scale, label := res.point()
+			if tasks[res.Task] == nil {
+				tasks[res.Task] = map[string]*pointReport{}
+			}
+			point := tasks[res.Task][key]
+			if point == nil {
+				point = &pointReport{Label: label, Params: key, scale: scale}
+				tasks[res.Task][key] = point
+			}
+			point.results = append(point.results, res)
+		}
+	}
+
+	for _, name := range sortedKeys(tasks) {
+		task := taskReport{Name: name}
+		for _, key := range sortedKeys(tasks[name]) {
+			task.Points = append(task.Points, *tasks[name][key])
+		}
+		slices.SortStableFunc(task.Points, func(a, b pointReport) int {
+			return cmp.Compare(a.scale, b.scale)
+		})
+		for i := range task.Points {
+			point := &task.Points[i]
+			slices.SortStableFunc(point.results, func(a, b result) int {
+				return cmp.Compare(a.Stats.Median, b.Stats.Median)
+			})
+			point.Chart = barChart(point.results)
+			if row, ok := compareLanguages(point); ok {
+				task.Ratios = append(task.Ratios, row)
+			}
+			task.Runtimes = append(task.Runtimes, compareRuntimes(point)...)
+			task.Flags = append(task.Flags, compareFlags(point)...)
+		}
+		task.Scaling = scalingChart(task.Points)
+		r.Tasks = append(r.Tasks, task)
+	}
+	r.Best, r.Worst = extremes(r.Tasks)
+	return r
+}
+
+// compareLanguages pairs the fastest TinyGo and Rust builds of a point, whose
+// results are sorted by median
+func compareLanguages(point *pointReport) (ratioRow, bool) {
+	row := ratioRow{Point: point.Label}
+	var haveTinyGo, haveRust bool
+	for _, res := range point.results {
+		switch res.language() {
+		case "tinygo":
+			if !haveTinyGo {
+				row.TinyGo, haveTinyGo = res, true
+			}
+		case "rust":
+			if !haveRust {
+				row.Rust, haveRust = res, true
+			}
+		}
+	}
+	if !haveTinyGo || !haveRust || row.Rust.Stats.Median == 0 {
+		return row, false
+	}
+	row.Ratio = row.TinyGo.Stats.Median / row.Rust.Stats.Median
+	if row.TinyGo.Memory.PeakBytes != 0 && row.Rust.Memory.PeakBytes != 0 {
+		row.MemoryRatio = float64(row.TinyGo.Memory.PeakBytes) / float64(row.Rust.Memory.PeakBytes)
+	}
+	row.Significance = compareRuns(row.TinyGo.SamplesMs, row.Rust.SamplesMs)
+	return row, true
+}
+
+// compareRuntimes compares each module that ran under several runtimes at a
+// point with its fastest runtime, in the point's order by median
+func compareRuntimes(point *pointReport) []runtimeRow {
+	fastest := map[string]result{}
+	var rows []runtimeRow
+	for _, res := range point.results {
+		first, ok := fastest[res.Module]
+		if !ok {
+			fastest[res.Module] = res
+			continue
+		}
+		if res.Runtime == first.Runtime || first.Stats.Median == 0 {
+			continue
+		}
+		rows = append(rows, runtimeRow{
+			Point:        point.Label,
+			Module:       res.Module,
+			Runtime:      res,
+			Fastest:      first,
+			Ratio:        res.Stats.Median / first.Stats.Median,
+			Significance: compareRuns(res.SamplesMs, first.SamplesMs),
+		})
+	}
+	return rows
+}
+
+// compareFlags pairs the builds of a point that differ in a single tinygo
+// flag and ran under the same runtime, by flag and then by value
+func compareFlags(point *pointReport) []flagRow {
+	var rows []flagRow
+	for i, a := range point.results {
+		for _, b := range point.results[i+1:] {
+			flag, ok := differingFlag(a, b)
+			if !ok || a.Runtime != b.Runtime {
+				continue
+			}
+			from, to := a, b
+			if from.BuildFlags[flag] > to.BuildFlags[flag] {
+				from, to = to, from
+			}
+			if from.Stats.Median == 0 {
+				continue
+			}
+			rows = append(rows, flagRow{
+				Point:        point.Label,
+				Flag:         flag,
+				From:         from,
+				To:           to,
+				Ratio:        to.Stats.Median / from.Stats.Median,
+				Significance: compareRuns(to.SamplesMs, from.SamplesMs),
+			})
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b flagRow) int {
+		return cmp.Or(cmp.Compare(a.Flag, b.Flag), cmp.Compare(a.From.BuildFlags[a.Flag], b.From.BuildFlags[b.Flag]),
+			cmp.Compare(a.To.BuildFlags[a.Flag], b.To.BuildFlags[b.Flag]))
+	})
+	return rows
+}
+
+// differingFlag returns the one flag whose value differs between the builds
+// of a and b, false unless both have the same flags and exactly one differs
+func differingFlag(a, b result) (string, bool) {
+	if len(a.BuildFlags) == 0 || len(a.BuildFlags) != len(b.BuildFlags) {
+		return "", false
+	}
+	var differs []string
+	for name, value := range a.BuildFlags {
+		other, ok := b.BuildFlags[name]
+		if !ok {
+			return "", false
+		}
+		if other != value {
+			differs = append(differs, name)
+		}
+	}
+	if len(differs) != 1 {
+		return "", false
+	}
+	return differs[0], true
+}
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var page = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms":       formatMs,
+	"bytes":    formatBytes,
+	"versions": formatVersions,
+}).Parse(reportTemplate))
+
+// formatVersions lists toolchain or runtime versions by name, "-" if none
+// were recorded
+func formatVersions(versions map[string]string) string {
+	if len(versions) == 0 {
+		return "-"
+	}
+	list := make([]string, 0, len(versions))
+	for _, name := range slices.Sorted(maps.Keys(versions)) {
+		list = append(list, name+": "+versions[name])
+	}
+	return strings.Join(list, "; ")
+}
+
+// render writes the report as a single HTML document
+func (r report) render(w io.Writer) error {
+	return page.Execute(w, r)
+}
+
+// formatMs formats a duration in ms to 3 significant digits
+func formatMs(ms float64) string {
+	if ms == 0 || math.Abs(ms) >= 100 {
+		return strconv.FormatFloat(ms, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(ms, 'g', 3, 64)
+}
+
+// formatBytes formats a size in KiB, or MiB from 1 MiB
+func formatBytes(n uint64) string {
+	if n >= 1<<20 {
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + " MiB"
+	}
+	return strconv.FormatFloat(float64(n)/(1<<10), 'f', 0, 64) + " KiB"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
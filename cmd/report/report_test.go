@@ -0,0 +1,173 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionJSON runs two matrix_mul builds of each language at two dimensions;
+// the Rust o3 build is the fastest Rust build at both. At dimension 64 it also
+// runs under wasmtime, barely slower, and the runs are recorded, as they are
+// for a leaking-GC TinyGo build that differs from o2 in -gc alone.
+const sessionJSON = `{
+  "started": "2026-01-02T03:04:05Z",
+  "environment": {"go_version": "go1.25.0", "os": "linux", "arch": "amd64", "cpus": 8, "hostname": "bench<host>",
+    "cpu_model": "Example CPU @ 3.00GHz", "governor": "performance",
+    "toolchains": {"tinygo": "tinygo version 0.39.0 linux/amd64", "rust": "rustc 1.90.0"}, "runtimes": {"wazero": "v1.12.0"}},
+  "results": [
+    {"module": "builds/tinygo/matrix_mul-o2.wasm", "runtime": "wazero", "task": "matrix_mul", "language": "tinygo",
+     "build_flags": {"opt": "2", "gc": "conservative", "scheduler": "none"},
+     "params": {"dimension": 64, "seed": 1}, "size": 20480, "stats": {"n": 8, "min": 3.8, "max": 4.2, "median": 4, "cv": 0.03}, "memory": {"peak_bytes": 2097152},
+     "samples_ms": [3.9, 4, 4.1, 4, 3.8, 4.2, 4, 4.1]},
+    {"module": "builds/tinygo/matrix_mul-o2-gcleaking.wasm", "runtime": "wazero", "task": "matrix_mul", "language": "tinygo",
+     "build_flags": {"opt": "2", "gc": "leaking", "scheduler": "none"},
+     "params": {"dimension": 64, "seed": 1}, "stats": {"n": 8, "min": 4.8, "max": 5.2, "median": 5, "cv": 0.03},
+     "samples_ms": [5, 5.1, 4.9, 5, 5.2, 4.8, 5, 5.1]},
+    {"module": "builds/rust/matrix_mul-o3.wasm", "runtime": "wazero", "task": "matrix_mul",
+     "params": {"dimension": 64, "seed": 1}, "stats": {"n": 8, "min": 1.8, "max": 2.2, "median": 2, "cv": 0.06}, "memory": {"peak_bytes": 1048576},
+     "samples_ms": [2, 1.9, 2.1, 2, 2.2, 1.8, 2, 2]},
+    {"module": "builds/rust/matrix_mul-o3.wasm", "runtime": "wasmtime", "task": "matrix_mul",
+     "params": {"dimension": 64, "seed": 1}, "stats": {"n": 8, "min": 1.9, "max": 2.3, "median": 2.1, "cv": 0.06},
+     "samples_ms": [2.1, 1.9, 2.2, 2, 2.3, 1.9, 2.1, 2.2]},
+    {"module": "builds/rust/matrix_mul-os.wasm", "runtime": "wazero", "task": "matrix_mul",
+     "params": {"dimension": 64, "seed": 1}, "stats": {"n": 5, "min": 5, "max": 7, "median": 6, "cv": 0.1}},
+    {"module": "builds/tinygo/matrix_mul-o2.wasm", "runtime": "wazero", "task": "matrix_mul", "language": "tinygo",
+     "params": {"dimension": 128, "seed": 1}, "stats": {"n": 5, "min": 30, "max": 34, "median": 32, "cv": 0.1}},
+    {"module": "builds/rust/matrix_mul-o3.wasm", "runtime": "wazero", "task": "matrix_mul",
+     "params": {"dimension": 128, "seed": 1}, "stats": {"n": 5, "min": 15, "max": 17, "median": 16, "cv": 0.1}},
+    {"module": "builds/tinygo/mandelbrot-o2.wasm", "runtime": "wazero", "task": "mandelbrot", "error": "self test failed <vector>"}
+  ]
+}`
+
+func TestBuildReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(path, []byte(sessionJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := loadSession(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := buildReport([]session{s}, time.Now())
+	if len(r.Tasks) != 1 || len(r.Failures) != 1 {
+		t.Fatalf("%d tasks and %d failures, expected matrix_mul and the mandelbrot failure", len(r.Tasks), len(r.Failures))
+	}
+	task := r.Tasks[0]
+	if len(task.Points) != 2 || task.Points[0].Label != "64" || task.Points[1].Label != "128" {
+		t.Fatalf("points %+v, expected dimensions 64 then 128", task.Points)
+	}
+	if len(task.Ratios) != 2 {
+		t.Fatalf("%d ratio rows, expected one per dimension", len(task.Ratios))
+	}
+	if row := task.Ratios[0]; row.Rust.Module != "builds/rust/matrix_mul-o3.wasm" || row.Ratio != 2 {
+		t.Errorf("dimension 64 compares %s at ratio %v, expected the fastest Rust build at 2", row.Rust.Module, row.Ratio)
+	}
+	if sig := task.Ratios[0].Significance; !sig.Significant || sig.Low > 2 || sig.High < 2 {
+		t.Errorf("dimension 64 ratio %+v, expected a significant interval around 2", sig)
+	}
+	if task.Ratios[1].Significance.Tested {
+		t.Error("dimension 128 has no runs recorded, so its ratio cannot be tested")
+	}
+	if len(task.Runtimes) != 1 {
+		t.Fatalf("%d runtime rows, expected wasmtime against wazero at dimension 64", len(task.Runtimes))
+	}
+	if row := task.Runtimes[0]; row.Runtime.Runtime != "wasmtime" || row.Fastest.Runtime != "wazero" || row.Significance.Significant {
+		t.Errorf("runtime row %s against %s, significant %v, expected wasmtime against wazero, not significant",
+			row.Runtime.Runtime, row.Fastest.Runtime, row.Significance.Significant)
+	}
+	if len(task.Flags) != 1 {
+		t.Fatalf("%d flag rows, expected -gc at dimension 64", len(task.Flags))
+	}
+	if row := task.Flags[0]; row.Flag != "gc" || row.From.BuildFlags["gc"] != "conservative" || row.Ratio != 1.25 || !row.Significance.Significant {
+		t.Errorf("flag row -%s from %s at ratio %v, expected a significant 1.25 from conservative to leaking",
+			row.Flag, row.From.BuildFlags[row.Flag], row.Ratio)
+	}
+	if task.Scaling == "" || !strings.Contains(string(task.Scaling), "<polyline") {
+		t.Error("two dimensions should draw a scaling curve")
+	}
+}
+
+func TestLoadSessionVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, c := range []struct {
+		version string
+		ok      bool
+	}{
+		{"", true}, // Before bench versioned its sessions
+		{`"schema_version": 1,`, true},
+		{`"schema_version": 2,`, false},
+	} {
+		path := filepath.Join(dir, "session.json")
+		if err := os.WriteFile(path, []byte("{"+c.version+`"results": []}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadSession(path); (err == nil) != c.ok {
+			t.Errorf("loadSession of {%s ...}: %v", c.version, err)
+		}
+	}
+}
+
+func TestRunWritesReport(t *testing.T) {
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "session.json"), filepath.Join(dir, "report.html")
+	if err := os.WriteFile(input, []byte(sessionJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr strings.Builder
+	if code := run([]string{"-o", output, input}, io.Discard, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{"<h2>matrix_mul</h2>", "TinyGo vs Rust", "2.00×", "1.05× (n.s.)", "2.0 MiB / 1.0 MiB (2.00×)", "<svg", "self test failed &lt;vector&gt;", "bench&lt;host&gt;", "Example CPU @ 3.00GHz", "rust: rustc 1.90.0; tinygo: tinygo version 0.39.0 linux/amd64", "wazero: v1.12.0", "<td>-gc</td>", "1.25×"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+
+	if code := run(nil, io.Discard, &stderr); code != 2 {
+		t.Errorf("exit status %d without sessions, expected 2", code)
+	}
+}
+
+func TestRunWritesMarkdown(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(input, []byte(sessionJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr strings.Builder
+	if code := run([]string{"-format", "markdown", input}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	summary := stdout.String()
+	for _, want := range []string{
+		"## matrix_mul",
+		"**Best TinyGo / Rust:** matrix_mul at 64, 2.00× (matrix_mul-o2.wasm against matrix_mul-o3.wasm)",
+		"| 64 | matrix_mul-o2.wasm | tinygo | wazero | 4 ms | 3.0% | 20 KiB | 2.00× |",
+		"| 64 | matrix_mul-o3.wasm | rust | wasmtime | 2.1 ms | 6.0% | - | 1.05× |",
+		"| 128 | matrix_mul-o2.wasm | matrix_mul-o3.wasm | 2.00× | - | - |",
+		"| 64 | -gc | wazero | conservative (matrix_mul-o2.wasm) | leaking (matrix_mul-o2-gcleaking.wasm) | 1.25× |",
+		"- mandelbrot-o2.wasm (mandelbrot): self test failed",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "<svg") || strings.Contains(summary, "Worst") {
+		t.Errorf("summary has charts or a worst ratio equal to the best:\n%s", summary)
+	}
+
+	if code := run([]string{"-format", "pdf", input}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit status %d with -format pdf, expected 2", code)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "wasmbench/stats"
+
+// confidence is the level of the report's intervals; a difference is
+// significant at 1 - confidence
+const confidence = 0.95
+
+// resamples is the bootstrap resamples per interval
+const resamples = 2000
+
+// bootstrapSeed seeds the resampling, so the same sessions always give the
+// same report
+const bootstrapSeed = 0x7265706f7274
+
+// significance is how surely the runs of two results differ, as benchdiff
+// judges a change: the bootstrap interval of the ratio of their medians, and
+// a Mann-Whitney U test of the runs
+type significance struct {
+	Low, High   float64 // Confidence interval of the ratio
+	P           float64
+	Significant bool // The interval excludes 1 and P is below 1 - confidence
+	Tested      bool // Both results have at least 2 runs
+}
+
+// compareRuns tests the ratio of a's median over b's
+func compareRuns(a, b []float64) significance {
+	if len(a) < 2 || len(b) < 2 {
+		return significance{}
+	}
+	s := significance{Tested: true, P: stats.MannWhitney(a, b)}
+	s.Low, s.High = stats.Bootstrap(a, b, medianRatio, resamples, confidence, bootstrapSeed)
+	s.Significant = s.P < 1-confidence && (s.Low > 1 || s.High < 1)
+	return s
+}
+
+// medianRatio is the ratio of a's median over b's, the statistic the
+// interval is of
+func medianRatio(a, b []float64) float64 {
+	return stats.Median(a) / stats.Median(b)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Bar colors by language, and the line colors of the scaling curves
+var (
+	languageColors = map[string]string{"tinygo": "#00add8", "rust": "#dea584", "go": "#7f8c8d", "js": "#f0db4f"}
+	lineColors     = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#17becf"}
+)
+
+// Bar chart geometry, in px
+const (
+	barLabelWidth = 240
+	barAreaWidth  = 360
+	barRowHeight  = 24
+)
+
+// barChart draws a horizontal bar of each result's median, with a whisker
+// from its fastest to its slowest kept run
+func barChart(results []result) template.HTML {
+	var longest float64
+	for _, res := range results {
+		longest = max(longest, res.Stats.Max, res.Stats.Median)
+	}
+	if longest == 0 {
+		longest = 1
+	}
+	x := func(ms float64) float64 { return barLabelWidth + ms/longest*barAreaWidth }
+
+	var b strings.Builder
+	height := len(results)*barRowHeight + 8
+	fmt.Fprintf(&b, `<svg class="bars" width="%d" height="%d" role="img">`, barLabelWidth+barAreaWidth+80, height)
+	for i, res := range results {
+		y := i*barRowHeight + 4
+		color := languageColors[res.language()]
+		if color == "" {
+			color = "#999"
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, barLabelWidth-8, y+15, template.HTMLEscapeString(res.label()))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"><title>%s: median %s ms, CV %.1f%%</title></rect>`,
+			barLabelWidth, y+2, x(res.Stats.Median)-barLabelWidth, barRowHeight-6, color,
+			template.HTMLEscapeString(res.label()), formatMs(res.Stats.Median), res.Stats.CV*100)
+		mid := y + barRowHeight/2 - 1
+		fmt.Fprintf(&b, `<line x1="%.1f" x2="%.1f" y1="%d" y2="%d" class="whisker"/>`, x(res.Stats.Min), x(res.Stats.Max), mid, mid)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s ms</text>`, x(max(res.Stats.Median, res.Stats.Max))+6, y+15, formatMs(res.Stats.Median))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// Line chart geometry, in px
+const (
+	plotLeft   = 70
+	plotTop    = 16
+	plotWidth  = 420
+	plotHeight = 240
+	legendLeft = plotLeft + plotWidth + 24
+)
+
+// scalingChart draws each build's median against the problem size on log-log
+// axes, where a straight line of slope k means time grows as size^k. It is
+// empty unless the task was run at two or more sizes.
+func scalingChart(points []pointReport) template.HTML {
+	type sample struct{ scale, ms float64 }
+	series := map[string][]sample{}
+	var scales []float64
+	var labels []string
+	minMs, maxMs := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		if point.scale <= 0 {
+			continue
+		}
+		if !slices.Contains(scales, point.scale) {
+			scales = append(scales, point.scale)
+			labels = append(labels, point.Label)
+		}
+		for _, res := range point.results {
+			if res.Stats.Median <= 0 {
+				continue
+			}
+			series[res.label()] = append(series[res.label()], sample{point.scale, res.Stats.Median})
+			minMs, maxMs = min(minMs, res.Stats.Median), max(maxMs, res.Stats.Median)
+		}
+	}
+	if len(scales) < 2 || len(series) == 0 {
+		return ""
+	}
+
+	// Points are sorted by scale, so scales is too
+	logX := func(scale float64) float64 {
+		return plotLeft + (math.Log(scale)-math.Log(scales[0]))/(math.Log(scales[len(scales)-1])-math.Log(scales[0]))*plotWidth
+	}
+	logY := func(ms float64) float64 {
+		if maxMs == minMs {
+			return plotTop + plotHeight/2
+		}
+		return plotTop + plotHeight - (math.Log(ms)-math.Log(minMs))/(math.Log(maxMs)-math.Log(minMs))*plotHeight
+	}
+
+	var b strings.Builder
+	names := sortedKeys(series)
+	height := max(plotTop+plotHeight+40, len(names)*20+plotTop)
+	fmt.Fprintf(&b, `<svg class="scaling" width="%d" height="%d" role="img">`, legendLeft+260, height)
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" class="plot"/>`, plotLeft, plotTop, plotWidth, plotHeight)
+	for i, scale := range scales {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, logX(scale), plotTop+plotHeight+18, template.HTMLEscapeString(labels[i]))
+	}
+	for _, ms := range []float64{minMs, math.Sqrt(minMs * maxMs), maxMs} {
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%s ms</text>`, plotLeft-6, logY(ms)+4, formatMs(ms))
+	}
+	for i, name := range names {
+		color := lineColors[i%len(lineColors)]
+		var path []string
+		for _, s := range series[name] {
+			path = append(path, fmt.Sprintf("%.1f,%.1f", logX(s.scale), logY(s.ms)))
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s: %s ms</title></circle>`,
+				logX(s.scale), logY(s.ms), color, template.HTMLEscapeString(name), formatMs(s.ms))
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" stroke="%s"/>`, strings.Join(path, " "), color)
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/><text x="%d" y="%d">%s</text>`,
+			legendLeft, plotTop+i*20, color, legendLeft+18, plotTop+i*20+11, template.HTMLEscapeString(name))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
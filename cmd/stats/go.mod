@@ -0,0 +1,7 @@
+module wasmbench/stats
+
+go 1.25.0
+
+// Statistics of repeated timings shared by cmd/bench, cmd/benchdiff and
+// cmd/report
+// No external dependencies - pure standard library
@@ -0,0 +1,212 @@
+// Package stats summarizes the repeated timings of a benchmark and tests
+// whether two sets of them differ, for cmd/bench, cmd/benchdiff and
+// cmd/report. Every function takes the samples in any order, leaves them
+// unmodified, and returns 0 rather than NaN when there are too few samples,
+// so the results always encode as JSON.
+package stats
+
+import (
+	"cmp"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// OutlierFences is the multiple of the interquartile range past the
+// quartiles beyond which a sample is an outlier (Tukey's fences)
+const OutlierFences = 1.5
+
+// TrimFraction is the share of samples Summary's trimmed mean drops from each end
+const TrimFraction = 0.1
+
+// Summary describes repeated runs after outlier removal
+type Summary struct {
+	N           int     `json:"n"`        // Samples summarized
+	Outliers    int     `json:"outliers"` // Samples removed before summarizing
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Mean        float64 `json:"mean"`
+	Median      float64 `json:"median"`
+	TrimmedMean float64 `json:"trimmed_mean"` // Mean without the TrimFraction lowest and highest samples
+	StdDev      float64 `json:"stddev"`
+	CV          float64 `json:"cv"` // StdDev over Mean
+}
+
+// Summarize removes the outliers from samples and describes the rest
+func Summarize(samples []float64) Summary {
+	kept, removed := RemoveOutliers(samples)
+	if len(kept) == 0 {
+		return Summary{}
+	}
+	sorted := slices.Sorted(slices.Values(kept))
+	return Summary{
+		N:           len(kept),
+		Outliers:    removed,
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Mean:        Mean(kept),
+		Median:      Median(kept),
+		TrimmedMean: TrimmedMean(kept, TrimFraction),
+		StdDev:      StdDev(kept),
+		CV:          CV(kept),
+	}
+}
+
+// Mean returns the arithmetic mean
+func Mean(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var total float64
+	for _, sample := range samples {
+		total += sample
+	}
+	return total / float64(len(samples))
+}
+
+// Median returns the middle sample, or the mean of the two middle ones
+func Median(samples []float64) float64 {
+	return Quantile(samples, 0.5)
+}
+
+// Quantile returns the q-quantile (0 <= q <= 1), interpolating linearly
+// between the samples around it as R's default type 7 and NumPy do
+func Quantile(samples []float64, q float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(samples))
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (position-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// TrimmedMean returns the mean without the floor(fraction * n) lowest and
+// highest samples; fraction is below 0.5
+func TrimmedMean(samples []float64, fraction float64) float64 {
+	sorted := slices.Sorted(slices.Values(samples))
+	trim := int(fraction * float64(len(sorted)))
+	return Mean(sorted[trim : len(sorted)-trim])
+}
+
+// StdDev returns the sample standard deviation, with Bessel's correction
+func StdDev(samples []float64) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+	mean := Mean(samples)
+	var squares float64
+	for _, sample := range samples {
+		squares += (sample - mean) * (sample - mean)
+	}
+	return math.Sqrt(squares / float64(len(samples)-1))
+}
+
+// CV returns the coefficient of variation, the standard deviation relative
+// to the mean, or 0 when the mean is 0
+func CV(samples []float64) float64 {
+	mean := Mean(samples)
+	if mean == 0 {
+		return 0
+	}
+	return StdDev(samples) / mean
+}
+
+// RemoveOutliers returns the samples within OutlierFences interquartile
+// ranges of the quartiles, in their original order, and how many it removed.
+// With fewer than 4 samples the quartiles mean little, and none are removed.
+func RemoveOutliers(samples []float64) (kept []float64, removed int) {
+	if len(samples) < 4 {
+		return slices.Clone(samples), 0
+	}
+	q1, q3 := Quantile(samples, 0.25), Quantile(samples, 0.75)
+	low, high := q1-OutlierFences*(q3-q1), q3+OutlierFences*(q3-q1)
+	for _, sample := range samples {
+		if sample < low || sample > high {
+			removed++
+			continue
+		}
+		kept = append(kept, sample)
+	}
+	return kept, removed
+}
+
+// MannWhitney returns the two-sided p-value of the Mann-Whitney U test of
+// whether a sample of b tends to be larger or smaller than one of a. Unlike a
+// t-test it assumes no distribution of the samples, and run times, with their
+// long right tails (GC pauses, preemption), are anything but normal. The
+// normal approximation with tie and continuity corrections is close from
+// about 8 samples a side. It returns 1 when either has fewer than 2 samples
+// or every sample is tied.
+func MannWhitney(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+	type sample struct {
+		value float64
+		fromA bool
+	}
+	all := make([]sample, 0, len(a)+len(b))
+	for _, value := range a {
+		all = append(all, sample{value, true})
+	}
+	for _, value := range b {
+		all = append(all, sample{value, false})
+	}
+	slices.SortFunc(all, func(x, y sample) int { return cmp.Compare(x.value, y.value) })
+
+	// Tied samples share the mean of their ranks
+	var rankSumA, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for _, s := range all[i:j] {
+			if s.fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n1, n2 := float64(len(a)), float64(len(b))
+	n := n1 + n2
+	u := rankSumA - n1*(n1+1)/2
+	variance := n1 * n2 / 12 * (n + 1 - ties/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := max(math.Abs(u-n1*n2/2)-0.5, 0) / math.Sqrt(variance)
+	return math.Erfc(z / math.Sqrt2)
+}
+
+// Bootstrap returns the percentile bootstrap interval, at confidence, of
+// statistic over resamples of a and b drawn with replacement. The draws are
+// seeded by seed and the sample counts, so the same samples always give the
+// same interval. It returns 0, 0 when either has no samples.
+func Bootstrap(a, b []float64, statistic func(a, b []float64) float64, resamples int, confidence float64, seed uint64) (low, high float64) {
+	if len(a) == 0 || len(b) == 0 || resamples <= 0 {
+		return 0, 0
+	}
+	rng := rand.New(rand.NewPCG(seed, uint64(len(a))<<32|uint64(len(b))))
+	values := make([]float64, resamples)
+	aResample, bResample := make([]float64, len(a)), make([]float64, len(b))
+	for i := range values {
+		for j := range aResample {
+			aResample[j] = a[rng.IntN(len(a))]
+		}
+		for j := range bResample {
+			bResample[j] = b[rng.IntN(len(b))]
+		}
+		values[i] = statistic(aResample, bResample)
+	}
+	tail := (1 - confidence) / 2
+	return Quantile(values, tail), Quantile(values, 1-tail)
+}
@@ -0,0 +1,132 @@
+package stats
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// near reports whether a and b agree to 1e-9
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDescriptiveStatistics(t *testing.T) {
+	samples := []float64{4, 1, 3, 2, 5, 100}
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"mean", Mean(samples), 115.0 / 6},
+		{"median", Median(samples), 3.5},
+		{"odd median", Median([]float64{3, 1, 2}), 2},
+		{"quartile 1", Quantile(samples, 0.25), 2.25},
+		{"quartile 3", Quantile(samples, 0.75), 4.75},
+		{"maximum", Quantile(samples, 1), 100},
+		{"trimmed mean", TrimmedMean(samples, 0.2), 3.5},
+		{"untrimmed mean", TrimmedMean(samples, 0), 115.0 / 6},
+		{"stddev", StdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}), math.Sqrt(32.0 / 7)},
+		{"cv", CV([]float64{1, 3}), math.Sqrt2 / 2},
+		{"single sample stddev", StdDev([]float64{5}), 0},
+		{"empty mean", Mean(nil), 0},
+		{"empty median", Median(nil), 0},
+		{"zero-mean cv", CV([]float64{0, 0}), 0},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.expected) {
+			t.Errorf("%s = %v, expected %v", tt.name, tt.got, tt.expected)
+		}
+	}
+	if !slices.Equal(samples, []float64{4, 1, 3, 2, 5, 100}) {
+		t.Errorf("samples were reordered: %v", samples)
+	}
+}
+
+func TestRemoveOutliers(t *testing.T) {
+	// Quartiles 2.25 and 4.75 put the fences at -1.5 and 8.5
+	kept, removed := RemoveOutliers([]float64{4, 1, 3, 2, 5, 100})
+	if !slices.Equal(kept, []float64{4, 1, 3, 2, 5}) || removed != 1 {
+		t.Errorf("kept %v, removed %d, expected the 100 alone removed", kept, removed)
+	}
+
+	kept, removed = RemoveOutliers([]float64{1, 1000, 2})
+	if len(kept) != 3 || removed != 0 {
+		t.Errorf("kept %v, removed %d, expected no removal from 3 samples", kept, removed)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]float64{10, 12, 11, 13, 50, 12, 11, 10, 12, 11})
+	if summary.N != 9 || summary.Outliers != 1 {
+		t.Fatalf("%d samples and %d outliers, expected the 50 removed", summary.N, summary.Outliers)
+	}
+	if summary.Min != 10 || summary.Max != 13 || summary.Median != 11 {
+		t.Errorf("min %v, max %v, median %v, expected 10, 13 and 11", summary.Min, summary.Max, summary.Median)
+	}
+	if !near(summary.Mean, 102.0/9) || summary.CV <= 0 || summary.CV != summary.StdDev/summary.Mean {
+		t.Errorf("mean %v, cv %v, expected 102/9 and stddev over mean", summary.Mean, summary.CV)
+	}
+
+	if empty := Summarize(nil); empty != (Summary{}) {
+		t.Errorf("Summarize(nil) = %+v, expected the zero Summary", empty)
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	low := []float64{1, 2, 3, 4, 5, 6, 7, 8}
+	high := []float64{11, 12, 13, 14, 15, 16, 17, 18}
+	// |U - 32| less 0.5 over sqrt(8*8*17/12): z 3.31
+	if p := MannWhitney(low, high); math.Abs(p-0.000939) > 1e-6 {
+		t.Errorf("p %v for samples that never overlap, expected 0.000939", p)
+	}
+	if p := MannWhitney(low, low); p != 1 {
+		t.Errorf("p %v for the same samples, expected 1", p)
+	}
+	if p := MannWhitney([]float64{5, 5, 5}, []float64{5, 5}); p != 1 {
+		t.Errorf("p %v for tied samples, expected 1", p)
+	}
+	if p := MannWhitney([]float64{1}, high); p != 1 {
+		t.Errorf("p %v for a single sample, expected 1", p)
+	}
+}
+
+func TestMannWhitneyMatchesSciPy(t *testing.T) {
+	var low, high, odd, even []float64
+	for i := 1; i <= 10; i++ {
+		low, high = append(low, float64(i)), append(high, float64(i+10))
+	}
+	for i := 1; i <= 16; i += 2 {
+		odd, even = append(odd, float64(i)), append(even, float64(i+1))
+	}
+	for _, c := range []struct {
+		name     string
+		a, b     []float64
+		expected float64 // scipy.stats.mannwhitneyu, asymptotic
+	}{
+		{"apart", low, high, 0.000183},
+		{"interleaved", odd, even, 0.713},
+	} {
+		if p := MannWhitney(c.a, c.b); math.Abs(p-c.expected) > 0.001 {
+			t.Errorf("%s: p %.6f, expected %g", c.name, p, c.expected)
+		}
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	var a, b []float64
+	for i := range 30 {
+		a, b = append(a, 10+float64(i%5)/10), append(b, 20+float64(i%5)/10)
+	}
+	ratio := func(a, b []float64) float64 { return Median(b) / Median(a) }
+	low, high := Bootstrap(a, b, ratio, 2000, 0.95, 1)
+	if !(low < 1.98 && 1.98 < high) || high-low > 0.1 {
+		t.Errorf("interval [%.3f, %.3f], expected a narrow one around 1.98", low, high)
+	}
+	if again, _ := Bootstrap(a, b, ratio, 2000, 0.95, 1); again != low {
+		t.Error("Bootstrap is not deterministic")
+	}
+	if low, high := Bootstrap(nil, b, ratio, 2000, 0.95, 1); low != 0 || high != 0 {
+		t.Errorf("interval [%v, %v] without samples, expected [0, 0]", low, high)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// maxListed bounds the differences a differential run prints one by one
+const maxListed = 10
+
+// difference is a vector whose module run parts from the native one
+type difference struct {
+	vector vector
+	reason string
+}
+
+// loadVectors reads every vector of task from the reference files in dir
+func loadVectors(dir, task string) ([]vector, error) {
+	path := filepath.Join(dir, task+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var vectors []vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vectors, nil
+}
+
+// differential runs every vector through the module and natively, with the
+// same params, and returns the vectors whose runs differ. The error is for a
+// module that fails to run at all, not one whose result differs.
+func differential(ctx context.Context, m *module, info taskInfo, t task, vectors []vector) ([]difference, error) {
+	var differences []difference
+	for _, v := range vectors {
+		params, err := t.params(v.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		native := t.trace(params)
+		module, err := m.trace(ctx, common.Memory(unsafe.Pointer(&params[0]), int(t.size)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		if reason := differs(info.Stages, t, native, module); reason != "" {
+			differences = append(differences, difference{v, reason})
+		}
+	}
+	return differences, nil
+}
+
+// differs describes how the module's run parts from the native one, on one
+// line: the first stage whose hashes differ, else the results, else the first
+// output element. It returns "" when the runs agree.
+func differs(stages []string, t task, native, module trace) string {
+	var reasons []string
+	if native.status != module.status || native.hash != module.hash {
+		reasons = append(reasons, fmt.Sprintf("native %s, module %s", outcome(native), outcome(module)))
+	}
+	for i, name := range stages {
+		if a, b := stageHash(native, uint32(i)), stageHash(module, uint32(i)); a != b {
+			reasons = append(reasons, fmt.Sprintf("first diverging stage %s: native %s, module %s", name, a, b))
+			break
+		}
+	}
+	if module.output != nil {
+		if line := compareOutputs(t, native, module); line != "" {
+			for _, part := range strings.Split(strings.TrimSuffix(line, "\n"), "\n") {
+				reasons = append(reasons, strings.TrimSpace(part))
+			}
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// reportDifferences prints the differences of a differential run over n
+// vectors, and returns whether there were any
+func reportDifferences(w io.Writer, info taskInfo, n int, differences []difference) bool {
+	for _, d := range differences[:min(len(differences), maxListed)] {
+		fmt.Fprintf(w, "%s/%s: %s\n", info.Task, d.vector.Name, d.reason)
+	}
+	if len(differences) > maxListed {
+		fmt.Fprintf(w, "... and %d more\n", len(differences)-maxListed)
+	}
+	if len(differences) == 0 {
+		fmt.Fprintf(w, "%s: all %d vectors agree\n", info.Task, n)
+		return false
+	}
+	fmt.Fprintf(w, "%s: %d of %d vectors differ\n", info.Task, len(differences), n)
+	return true
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// modulesEnv names a directory of TinyGo modules to test instead of
+// builds/tinygo
+const modulesEnv = "WASMBENCH_TINYGO_MODULES"
+
+// TestDifferential runs every reference vector through each TinyGo module
+// built and through the Go it is built from, and fails on any vector whose
+// runs differ
+func TestDifferential(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs every reference vector through each module")
+	}
+	dir := os.Getenv(modulesEnv)
+	if dir == "" {
+		dir = "../../builds/tinygo"
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.wasm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skipf("no modules in %s; build them with scripts/build_tinygo.sh, or set %s", dir, modulesEnv)
+	}
+
+	ctx := context.Background()
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			wasm, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := instantiate(ctx, wasm, os.Stderr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.close(ctx)
+			info, err := m.taskInfo(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			task, ok := tasks[info.Task]
+			if !ok {
+				t.Fatalf("No Go implementation of task %q", info.Task)
+			}
+			vectors, err := loadVectors(refsDir, info.Task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			differences, err := differential(ctx, m, info, task, vectors)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if reportDifferences(&out, info, len(vectors), differences) {
+				t.Error(out.String())
+			}
+		})
+	}
+}
+
+func TestDiffers(t *testing.T) {
+	_, native := nativeTrace(t, "matrix_mul", "small_8x8")
+	stages := []string{"input", "product", "output"}
+	if got := differs(stages, tasks["matrix_mul"], native, native); got != "" {
+		t.Errorf("Identical runs differ: %s", got)
+	}
+
+	// A module whose product is off in its last element
+	module := native
+	module.output = slices.Clone(native.output)
+	module.output[len(module.output)-1]++
+	module.stages[1]++
+	module.stages[2]++
+	module.hash++
+	got := differs(stages, tasks["matrix_mul"], native, module)
+	for _, want := range []string{"first diverging stage product", "first diverging element: 63 of 64 (1 differ); native "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("differs = %q, expected %q in it", got, want)
+		}
+	}
+	if strings.Contains(got, "\n") {
+		t.Errorf("differs = %q, expected one line", got)
+	}
+
+	differences := []difference{{vector{Name: "a"}, "x"}}
+	info := taskInfo{Task: "matrix_mul"}
+	var out bytes.Buffer
+	if !reportDifferences(&out, info, 12, differences) || out.String() != "matrix_mul/a: x\nmatrix_mul: 1 of 12 vectors differ\n" {
+		t.Errorf("reportDifferences printed %q", out.String())
+	}
+	out.Reset()
+	if reportDifferences(&out, info, 12, nil) || out.String() != "matrix_mul: all 12 vectors agree\n" {
+		t.Errorf("reportDifferences printed %q", out.String())
+	}
+}
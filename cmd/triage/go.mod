@@ -0,0 +1,22 @@
+module wasmbench/triage
+
+go 1.25.0
+
+// Hash-mismatch triage: runs a reference vector through a task module under
+// wazero and through the task's Go implementation natively
+require (
+	github.com/tetratelabs/wazero v1.12.0
+	json_parse_wasm v0.0.0
+	mandelbrot_wasm v0.0.0
+	matrix_mul_wasm v0.0.0
+	wasmbench/common v0.0.0
+)
+
+require golang.org/x/sys v0.44.0 // indirect
+
+replace (
+	json_parse_wasm => ../../tasks/json_parse/tinygo
+	mandelbrot_wasm => ../../tasks/mandelbrot/tinygo
+	matrix_mul_wasm => ../../tasks/matrix_mul/tinygo
+	wasmbench/common => ../../tasks/common
+)
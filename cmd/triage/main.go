@@ -0,0 +1,117 @@
+// Command triage finds where a task module's result for a reference vector
+// parts from the task's Go implementation. It runs the vector once through
+// the module under wazero and once through the Go package the TinyGo modules
+// are built from, natively, both with stage checkpoints and the full output
+// enabled. It prints each implementation's hash beside the reference hash,
+// the stage hashes side by side, the first stage whose hashes differ, and the
+// first output element that differs, with both values and how many of the
+// elements differ in all.
+//
+// Usage:
+//
+//	triage [flags] module.wasm [vector]
+//
+// The vector is named as in data/reference_hashes/<task>.json, the task being
+// the module's, from get_task_info. The module needs the set_checkpoints and
+// get_checkpoints exports, and set_output and get_output to compare outputs;
+// the Rust modules have neither. The exit status is 1 if the two runs differ.
+//
+// Without a vector, triage runs a differential test of the module: every
+// vector of the task's reference file, through the module and natively with
+// the same params. It prints a line for each vector whose runs differ, with
+// the first stage whose hashes differ or the first element, and how many of
+// the vectors differ; the exit status is 1 if any does. A vector the two agree
+// on passes even where both miss its reference hash, which go test in the task
+// packages checks; the test here is of the TinyGo build, its code generation
+// and the wasm ABI, against the Go it is built from.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("triage", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	refsDir := flags.String("refs", "../../data/reference_hashes", "directory of the <task>.json reference files")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 && flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "usage: triage [flags] module.wasm [vector]")
+		return 2
+	}
+	path, name := flags.Arg(0), flags.Arg(1)
+
+	ctx := context.Background()
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, "triage:", err)
+		return 1
+	}
+	m, err := instantiate(ctx, wasm, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %s: %v\n", path, err)
+		return 1
+	}
+	defer m.close(ctx)
+	info, err := m.taskInfo(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %s: %v\n", path, err)
+		return 1
+	}
+	t, ok := tasks[info.Task]
+	if !ok {
+		fmt.Fprintf(stderr, "triage: %s: no Go implementation of task %q\n", path, info.Task)
+		return 1
+	}
+	if name == "" {
+		vectors, err := loadVectors(*refsDir, info.Task)
+		if err != nil {
+			fmt.Fprintln(stderr, "triage:", err)
+			return 1
+		}
+		differences, err := differential(ctx, m, info, t, vectors)
+		if err != nil {
+			fmt.Fprintf(stderr, "triage: %s: %v\n", path, err)
+			return 1
+		}
+		if reportDifferences(stdout, info, len(vectors), differences) {
+			return 1
+		}
+		return 0
+	}
+	vector, err := loadVector(*refsDir, info.Task, name)
+	if err != nil {
+		fmt.Fprintln(stderr, "triage:", err)
+		return 1
+	}
+	params, err := t.params(vector.Params)
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %s: %v\n", name, err)
+		return 1
+	}
+
+	native := t.trace(params)
+	module, err := m.trace(ctx, common.Memory(unsafe.Pointer(&params[0]), int(t.size)))
+	if err != nil {
+		fmt.Fprintf(stderr, "triage: %s: %v\n", path, err)
+		return 1
+	}
+	if report(stdout, info, vector, t, native, module) {
+		return 1
+	}
+	return 0
+}
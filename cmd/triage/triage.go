@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+
+	"json_parse_wasm/jsonparse"
+	"mandelbrot_wasm/mandelbrot"
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// initSeed is passed to init on both sides, as cmd/bench passes it
+const initSeed = 12345
+
+// task is a task's Go implementation, the layout of its params struct, and
+// how to show an element of its get_output
+type task struct {
+	fields  []common.ParamField
+	size    uintptr
+	init    func(seed uint32)
+	run     func(paramsPtr, resultPtr uintptr) uint32 // run_task_v2
+	element func(b []byte) string
+}
+
+var tasks = map[string]task{
+	"mandelbrot": {mandelbrot.ParamFields(), unsafe.Sizeof(mandelbrot.MandelbrotParams{}), mandelbrot.Init, mandelbrot.RunTaskV2, formatCount},
+	"matrix_mul": {matrixmul.ParamFields(), unsafe.Sizeof(matrixmul.MatrixMulParams{}), matrixmul.Init, matrixmul.RunTaskV2, formatFloat32},
+	"json_parse": {jsonparse.ParamFields(), unsafe.Sizeof(jsonparse.JsonParseParams{}), jsonparse.Init, jsonparse.RunTaskV2, formatRecord},
+}
+
+// vector is an entry of data/reference_hashes/<task>.json
+type vector struct {
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description"`
+	Params            map[string]json.Number `json:"params"`
+	ExpectedHash      uint32                 `json:"expected_hash"`
+	ExpectedStatus    uint32                 `json:"expected_status"`
+	ExpectedErrorCode uint32                 `json:"expected_error_code"`
+}
+
+// loadVector reads the named vector of task from the reference files in dir
+func loadVector(dir, task, name string) (vector, error) {
+	vectors, err := loadVectors(dir, task)
+	if err != nil {
+		return vector{}, err
+	}
+	i := slices.IndexFunc(vectors, func(v vector) bool { return v.Name == name })
+	if i < 0 {
+		return vector{}, fmt.Errorf("%s has no vector %s", filepath.Join(dir, task+".json"), name)
+	}
+	return vectors[i], nil
+}
+
+// params encodes values in the task's params struct. The words back it so
+// the f64 and u64 fields are aligned for the native run; the module is given
+// the same bytes, its wasm32 layout being the same.
+func (t task) params(values map[string]json.Number) ([]uint64, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]uint64, (t.size+7)/8)
+	if status, message := cli.ParamsFromJSON(data, t.fields, unsafe.Pointer(&words[0])); status != common.StatusOK {
+		return nil, errors.New(message)
+	}
+	return words, nil
+}
+
+// trace is what a run of the vector left behind
+type trace struct {
+	status      uint32
+	hash        uint32
+	message     string // The error of a failed run
+	recorded    uint32 // Bitmask of the stages the run reached
+	stages      [common.MaxCheckpoints]uint32
+	output      []byte // nil when the implementation does not keep it
+	elementSize uint32
+}
+
+// nativeResult receives run_task_v2's result. It is a global because the
+// task writes it through a uintptr: a local could move with the goroutine's
+// stack when a run grows it, leaving the write in the old stack.
+var nativeResult common.TaskResult
+
+// trace runs the task natively with checkpoints and the output kept
+func (t task) trace(params []uint64) trace {
+	common.EnableCheckpoints(true)
+	common.EnableOutput(true)
+	defer common.EnableCheckpoints(false)
+	defer common.EnableOutput(false)
+
+	t.init(initSeed)
+	status := t.run(uintptr(unsafe.Pointer(&params[0])), uintptr(unsafe.Pointer(&nativeResult)))
+	runtime.KeepAlive(params)
+	tr := trace{status: status, hash: nativeResult.Hash}
+	if status != common.StatusOK {
+		tr.message = common.LastError()
+	}
+	for stage := range uint32(common.MaxCheckpoints) {
+		if hash, ok := common.CheckpointHash(stage); ok {
+			tr.recorded |= 1 << stage
+			tr.stages[stage] = hash
+		}
+	}
+	output, elementSize := common.RunOutput()
+	tr.output, tr.elementSize = slices.Clone(output), elementSize
+	if tr.output == nil {
+		tr.output = []byte{}
+	}
+	return tr
+}
+
+// report prints the module's run of v beside the native one, and returns
+// whether they differ
+func report(w io.Writer, info taskInfo, v vector, t task, native, module trace) bool {
+	fmt.Fprintf(w, "%s/%s: %s\n", info.Task, v.Name, v.Description)
+	if v.ExpectedStatus != common.StatusOK {
+		fmt.Fprintf(w, "reference: rejected with status %d, error code %d\n", v.ExpectedStatus, v.ExpectedErrorCode)
+	} else {
+		fmt.Fprintf(w, "reference: hash %d\n", v.ExpectedHash)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tnative\tmodule")
+	fmt.Fprintf(tw, "hash\t%s\t%s\n", outcome(native), outcome(module))
+	diverged := ""
+	for i, name := range info.Stages {
+		stage := uint32(i)
+		a, b := stageHash(native, stage), stageHash(module, stage)
+		if a == b {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, a, b)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\tdiffers\n", name, a, b)
+		if diverged == "" {
+			diverged = name
+		}
+	}
+	tw.Flush()
+
+	differ := native.status != module.status || native.hash != module.hash
+	if diverged != "" {
+		fmt.Fprintf(w, "first diverging stage: %s\n", diverged)
+		differ = true
+	} else if len(info.Stages) > 0 {
+		fmt.Fprintln(w, "every stage agrees")
+	}
+	if module.output == nil {
+		fmt.Fprintln(w, "outputs not compared: the module does not export get_output")
+		return differ
+	}
+	if line := compareOutputs(t, native, module); line != "" {
+		fmt.Fprint(w, line)
+		return true
+	}
+	fmt.Fprintf(w, "outputs agree: %d elements\n", elements(native))
+	return differ
+}
+
+// outcome describes a run's result: its hash, or the status it failed with
+func outcome(tr trace) string {
+	if tr.status != common.StatusOK {
+		return fmt.Sprintf("status %d: %s", tr.status, tr.message)
+	}
+	return fmt.Sprint(tr.hash)
+}
+
+// stageHash shows the hash a run recorded for stage, "-" if it did not reach it
+func stageHash(tr trace, stage uint32) string {
+	if tr.recorded&(1<<stage) == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%08x", tr.stages[stage])
+}
+
+// elements returns the number of whole elements in a run's output
+func elements(tr trace) int {
+	if tr.elementSize == 0 {
+		return 0
+	}
+	return len(tr.output) / int(tr.elementSize)
+}
+
+// compareOutputs describes where the module's output parts from the native
+// one: the first element that differs with both values, and how many do,
+// or how their lengths differ. It returns "" when they are the same.
+func compareOutputs(t task, native, module trace) string {
+	if native.elementSize != module.elementSize {
+		return fmt.Sprintf("output elements differ in size: native %d bytes, module %d\n", native.elementSize, module.elementSize)
+	}
+	size := int(native.elementSize)
+	if size == 0 || bytes.Equal(native.output, module.output) {
+		return ""
+	}
+
+	var b strings.Builder
+	n := min(elements(native), elements(module))
+	first, count := -1, 0
+	for i := range n {
+		if !bytes.Equal(native.output[i*size:(i+1)*size], module.output[i*size:(i+1)*size]) {
+			if first < 0 {
+				first = i
+			}
+			count++
+		}
+	}
+	if first >= 0 {
+		fmt.Fprintf(&b, "first diverging element: %d of %d (%d differ)\n", first, n, count)
+		fmt.Fprintf(&b, "  native  %s\n", t.element(native.output[first*size:]))
+		fmt.Fprintf(&b, "  module  %s\n", t.element(module.output[first*size:]))
+	}
+	if elements(native) != elements(module) {
+		fmt.Fprintf(&b, "output lengths differ: native %d elements, module %d\n", elements(native), elements(module))
+	}
+	return b.String()
+}
+
+// formatFloat32 shows a matrix_mul value and its bits, which tell a last-place
+// rounding difference from a wrong value
+func formatFloat32(b []byte) string {
+	bits := common.ReadUint32LE(b)
+	return fmt.Sprintf("%v (%#08x)", math.Float32frombits(bits), bits)
+}
+
+// formatCount shows a mandelbrot pixel's iteration count
+func formatCount(b []byte) string {
+	return fmt.Sprintf("%d iterations", common.ReadUint32LE(b))
+}
+
+// formatRecord shows a json_parse record: {id, value, flag, name hash}
+func formatRecord(b []byte) string {
+	return fmt.Sprintf("id %d, value %d, flag %d, name hash %08x",
+		common.ReadUint32LE(b), common.ReadInt32LE(b[4:]), common.ReadUint32LE(b[8:]), common.ReadUint32LE(b[12:]))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const refsDir = "../../data/reference_hashes"
+
+// nativeTrace runs the named reference vector of task natively
+func nativeTrace(t *testing.T, task, name string) (vector, trace) {
+	t.Helper()
+	v, err := loadVector(refsDir, task, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := tasks[task].params(v.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v, tasks[task].trace(params)
+}
+
+func TestNativeTrace(t *testing.T) {
+	v, tr := nativeTrace(t, "matrix_mul", "small_8x8")
+	if tr.status != 0 || tr.hash != v.ExpectedHash {
+		t.Errorf("Native run = %d (status %d), expected the reference hash %d", tr.hash, tr.status, v.ExpectedHash)
+	}
+	// input, product and output
+	if tr.recorded != 0b111 {
+		t.Errorf("Recorded stages %03b, expected all three", tr.recorded)
+	}
+	if tr.elementSize != 4 || elements(tr) != 64 {
+		t.Errorf("Output of %d bytes (element size %d), expected the 8x8 product", len(tr.output), tr.elementSize)
+	}
+
+	if _, err := loadVector(refsDir, "matrix_mul", "no_such_vector"); err == nil {
+		t.Error("Loading a vector the file does not have should fail")
+	}
+}
+
+func TestReport(t *testing.T) {
+	v, native := nativeTrace(t, "mandelbrot", "systematic_2_1_2_1")
+	info := taskInfo{Task: "mandelbrot", Language: "tinygo", Stages: []string{"input", "iterations", "output"}}
+
+	var out bytes.Buffer
+	if report(&out, info, v, tasks["mandelbrot"], native, native) {
+		t.Errorf("Identical runs should not differ:\n%s", out.String())
+	}
+	for _, want := range []string{"every stage agrees", "outputs agree: 100 elements"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Report should say %q:\n%s", want, out.String())
+		}
+	}
+
+	// A module whose iterations go wrong from pixel 7 on, at two pixels
+	module := native
+	module.output = slices.Clone(native.output)
+	module.output[7*4]++
+	module.output[50*4]++
+	module.stages[1]++
+	module.stages[2]++
+	module.hash++
+	out.Reset()
+	if !report(&out, info, v, tasks["mandelbrot"], native, module) {
+		t.Errorf("Diverging runs should differ:\n%s", out.String())
+	}
+	for _, want := range []string{
+		"first diverging stage: iterations",
+		"first diverging element: 7 of 100 (2 differ)",
+		"  native  " + formatCount(native.output[7*4:]),
+		"  module  " + formatCount(module.output[7*4:]),
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Report should say %q:\n%s", want, out.String())
+		}
+	}
+
+	// A module without get_output is compared by its stages alone
+	module.output = nil
+	out.Reset()
+	report(&out, info, v, tasks["mandelbrot"], native, module)
+	if !strings.Contains(out.String(), "outputs not compared") {
+		t.Errorf("Report should say the outputs were not compared:\n%s", out.String())
+	}
+
+	// A run cut short keeps fewer elements
+	module.output = native.output[:90*4]
+	if got := compareOutputs(tasks["mandelbrot"], native, module); got != "output lengths differ: native 100 elements, module 90\n" {
+		t.Errorf("compareOutputs = %q", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if status := run(nil, &stdout, &stderr); status != 2 {
+		t.Errorf("run without a module = %d, expected 2", status)
+	}
+
+	// An empty module has none of the exports
+	path := filepath.Join(t.TempDir(), "empty.wasm")
+	if err := os.WriteFile(path, []byte("\x00asm\x01\x00\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if status := run([]string{path, "small_8x8"}, &stdout, &stderr); status != 1 || !strings.Contains(stderr.String(), "missing export init") {
+		t.Errorf("run on an empty module = %d: %s", status, stderr.String())
+	}
+	stderr.Reset()
+	if status := run([]string{path}, &stdout, &stderr); status != 1 || !strings.Contains(stderr.String(), "missing export init") {
+		t.Errorf("A differential run on an empty module = %d: %s", status, stderr.String())
+	}
+}
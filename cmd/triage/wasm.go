@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"wasmbench/common"
+)
+
+// Exports the module's run needs; set_output and get_output are used when present
+var requiredExports = []string{"init", "alloc", "run_task_v2", "set_checkpoints", "get_checkpoints", "get_task_info"}
+
+// taskInfo is the part of get_task_info triage reads
+type taskInfo struct {
+	Task     string   `json:"task"`
+	Language string   `json:"language"`
+	Stages   []string `json:"stages"`
+}
+
+// module is a task module instantiated in its own wazero runtime
+type module struct {
+	runtime wazero.Runtime
+	api.Module
+}
+
+// instantiate compiles and instantiates a task module under wazero, with the
+// host functions cmd/bench gives it
+func instantiate(ctx context.Context, wasm []byte, log io.Writer) (*module, error) {
+	runtime := wazero.NewRuntime(ctx)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	start := time.Now()
+	nowMs := func() float64 {
+		return float64(time.Since(start)) / float64(time.Millisecond)
+	}
+	nextRandom := func() uint32 {
+		panic("env.next_random: triage supplies no host random data")
+	}
+	logMessage := func(ctx context.Context, m api.Module, ptr, length uint32) {
+		if message, ok := m.Memory().Read(ptr, length); ok {
+			fmt.Fprintf(log, "%s: %s\n", m.Name(), message)
+		}
+	}
+	_, err := runtime.NewHostModuleBuilder("env").
+		NewFunctionBuilder().WithFunc(nowMs).Export("now_ms").
+		NewFunctionBuilder().WithFunc(func(permille uint32) {}).Export("report_progress").
+		NewFunctionBuilder().WithFunc(nextRandom).Export("next_random").
+		NewFunctionBuilder().WithFunc(logMessage).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+
+	config := wazero.NewModuleConfig().
+		WithStartFunctions("_initialize").
+		WithStdout(log).
+		WithStderr(log).
+		WithSysNanotime().
+		WithSysWalltime()
+	instance, err := runtime.InstantiateWithConfig(ctx, wasm, config)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, err
+	}
+	m := &module{runtime, instance}
+	for _, name := range requiredExports {
+		if !m.exports(name) {
+			m.close(ctx)
+			return nil, fmt.Errorf("missing export %s; only the TinyGo modules hash their stages", name)
+		}
+	}
+	return m, nil
+}
+
+func (m *module) close(ctx context.Context) {
+	m.runtime.Close(ctx)
+}
+
+func (m *module) exports(name string) bool {
+	return m.ExportedFunction(name) != nil
+}
+
+func (m *module) call(ctx context.Context, name string, params ...uint32) (uint32, error) {
+	fn := m.ExportedFunction(name)
+	if fn == nil {
+		return 0, fmt.Errorf("missing export %s", name)
+	}
+	args := make([]uint64, len(params))
+	for i, param := range params {
+		args[i] = api.EncodeU32(param)
+	}
+	results, err := fn.Call(ctx, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return api.DecodeU32(results[0]), nil
+}
+
+// read copies n bytes of linear memory at ptr; name is the export that
+// returned ptr, for the error
+func (m *module) read(name string, ptr, n uint32) ([]byte, error) {
+	data, ok := m.Memory().Read(ptr, n)
+	if !ok {
+		return nil, fmt.Errorf("%s points outside memory", name)
+	}
+	out := make([]byte, n)
+	copy(out, data)
+	return out, nil
+}
+
+// readPtr calls an export returning the address of a block and reads n bytes of it
+func (m *module) readPtr(ctx context.Context, name string, n uint32) ([]byte, error) {
+	ptr, err := m.call(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return m.read(name, ptr, n)
+}
+
+// taskInfo decodes the get_task_info metadata
+func (m *module) taskInfo(ctx context.Context) (taskInfo, error) {
+	var info taskInfo
+	// {u32 len, JSON}
+	ptr, err := m.call(ctx, "get_task_info")
+	if err != nil {
+		return info, err
+	}
+	prefix, err := m.read("get_task_info", ptr, 4)
+	if err != nil {
+		return info, err
+	}
+	data, err := m.read("get_task_info", ptr+4, binary.LittleEndian.Uint32(prefix))
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("get_task_info: %w", err)
+	}
+	return info, nil
+}
+
+// write copies data into a buffer from the module's alloc and returns its address
+func (m *module) write(ctx context.Context, data []byte) (uint32, error) {
+	ptr, err := m.call(ctx, "alloc", uint32(len(data)))
+	if err != nil {
+		return 0, err
+	}
+	if ptr == 0 || !m.Memory().Write(ptr, data) {
+		return 0, fmt.Errorf("alloc(%d) returned an unusable buffer at %#x", len(data), ptr)
+	}
+	return ptr, nil
+}
+
+// trace runs the module once on params with checkpoints and the output kept
+func (m *module) trace(ctx context.Context, params []byte) (trace, error) {
+	if _, err := m.call(ctx, "init", initSeed); err != nil {
+		return trace{}, err
+	}
+	if _, err := m.call(ctx, "set_checkpoints", 1); err != nil {
+		return trace{}, err
+	}
+	keepOutput := m.exports("set_output") && m.exports("get_output")
+	if keepOutput {
+		if _, err := m.call(ctx, "set_output", 1); err != nil {
+			return trace{}, err
+		}
+	}
+
+	paramsPtr, err := m.write(ctx, params)
+	if err != nil {
+		return trace{}, err
+	}
+	resultPtr, err := m.write(ctx, make([]byte, common.TaskResultSize))
+	if err != nil {
+		return trace{}, err
+	}
+	status, err := m.call(ctx, "run_task_v2", paramsPtr, resultPtr)
+	if err != nil {
+		return trace{}, err
+	}
+	result, err := m.read("alloc", resultPtr, common.TaskResultSize)
+	if err != nil {
+		return trace{}, err
+	}
+	tr := trace{status: status, hash: binary.LittleEndian.Uint32(result[4:])}
+	if status != common.StatusOK {
+		tr.message = m.lastError(ctx)
+	}
+
+	// {u32 count, u32 recorded mask, u32 hashes[8]}
+	block, err := m.readPtr(ctx, "get_checkpoints", 8+4*common.MaxCheckpoints)
+	if err != nil {
+		return trace{}, err
+	}
+	tr.recorded = binary.LittleEndian.Uint32(block[4:])
+	for i := range tr.stages {
+		tr.stages[i] = binary.LittleEndian.Uint32(block[8+4*i:])
+	}
+
+	if keepOutput {
+		// {u32 ptr, u32 len, u32 element size}
+		block, err := m.readPtr(ctx, "get_output", 12)
+		if err != nil {
+			return trace{}, err
+		}
+		ptr, length := binary.LittleEndian.Uint32(block), binary.LittleEndian.Uint32(block[4:])
+		tr.elementSize = binary.LittleEndian.Uint32(block[8:])
+		if tr.output, err = m.read("get_output", ptr, length); err != nil {
+			return trace{}, err
+		}
+	}
+	return tr, nil
+}
+
+// lastError reads the get_last_error_ptr message, "" if there is none
+func (m *module) lastError(ctx context.Context) string {
+	if !m.exports("get_last_error_ptr") || !m.exports("get_last_error_len") {
+		return ""
+	}
+	ptr, err := m.call(ctx, "get_last_error_ptr")
+	if err != nil {
+		return ""
+	}
+	length, err := m.call(ctx, "get_last_error_len")
+	if err != nil {
+		return ""
+	}
+	message, _ := m.read("get_last_error_ptr", ptr, length)
+	return string(message)
+}
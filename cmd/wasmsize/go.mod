@@ -0,0 +1,3 @@
+module wasmbench/wasmsize
+
+go 1.25.0
@@ -0,0 +1,187 @@
+// Command wasmsize reports the size of the built task modules, a primary axis
+// of the TinyGo-vs-Rust comparison next to run time. For each module it
+// parses the wasm binary and breaks its file size down into code, data, the
+// name section, DWARF debug sections, other custom sections and the rest, and
+// lists its imports and exports.
+//
+// Usage:
+//
+//	wasmsize [-builds dir] [-v] [-packages n] [-json file] [module.wasm ...]
+//
+// With no modules, every .wasm under the language directories of -builds is
+// read. Modules are grouped by task, from the file name (mandelbrot-o2.wasm),
+// and each one's size is also given as a ratio of its task's smallest build.
+// -v also prints each module's imports and exports, and -json writes the
+// whole report with the size of every section. From a module's name section,
+// its code is also attributed to the packages its functions belong to, and
+// -packages n prints each module's n largest, so a dependency such as fmt or
+// strconv that a change drops shows in the sizes before and after it.
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run is the command body, returning the process exit status
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("wasmsize", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	builds := flags.String("builds", "builds", "directory searched when no modules are given")
+	verbose := flags.Bool("v", false, "also list each module's imports and exports")
+	packages := flags.Int("packages", 0, "also list each module's `n` largest packages by code size")
+	jsonPath := flags.String("json", "", "also write the report, with every section's size, as JSON to this file")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths, _ = filepath.Glob(filepath.Join(*builds, "*", "*.wasm"))
+		if len(paths) == 0 {
+			fmt.Fprintf(stderr, "wasmsize: no modules under %s; build them first or name them\n", *builds)
+			return 1
+		}
+	}
+
+	status := 0
+	var modules []module
+	for _, path := range paths {
+		m, err := readModule(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "wasmsize: %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		modules = append(modules, m)
+	}
+	sortModules(modules)
+
+	if err := writeTable(stdout, modules, *verbose, *packages); err != nil {
+		fmt.Fprintln(stderr, "wasmsize:", err)
+		return 1
+	}
+	if *jsonPath != "" {
+		data, err := json.MarshalIndent(modules, "", "  ")
+		if err == nil {
+			err = os.WriteFile(*jsonPath, append(data, '\n'), 0o644)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "wasmsize:", err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// module is one build's entry of the report
+type module struct {
+	Path      string         `json:"path"`
+	Task      string         `json:"task"`
+	Language  string         `json:"language"` // The builds/<language>/ directory
+	Variant   string         `json:"variant"`  // The file name after the task, like o2 or o3-simd
+	Size      int            `json:"size"`
+	Breakdown map[string]int `json:"breakdown"` // Bytes by category
+	Ratio     float64        `json:"ratio"`     // Size over the task's smallest build
+	binary
+}
+
+// readModule reads and parses the module at path
+func readModule(path string) (module, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return module{}, err
+	}
+	b, err := parseBinary(data)
+	if err != nil {
+		return module{}, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".wasm")
+	task, variant, _ := strings.Cut(name, "-")
+	return module{
+		Path:      path,
+		Task:      task,
+		Language:  filepath.Base(filepath.Dir(path)),
+		Variant:   variant,
+		Size:      len(data),
+		Breakdown: b.breakdown(),
+		binary:    b,
+	}, nil
+}
+
+// sortModules orders the modules by task, then size, and sets their ratios
+func sortModules(modules []module) {
+	slices.SortStableFunc(modules, func(a, b module) int {
+		if c := strings.Compare(a.Task, b.Task); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Size, b.Size)
+	})
+	smallest := 0
+	for i := range modules {
+		if i == 0 || modules[i].Task != modules[i-1].Task {
+			smallest = modules[i].Size
+		}
+		modules[i].Ratio = float64(modules[i].Size) / float64(smallest)
+	}
+}
+
+// writeTable prints a row per module with its breakdown in bytes, with
+// verbose each module's imports and exports, and each module's largest
+// packages up to the given count
+func writeTable(w io.Writer, modules []module, verbose bool, packages int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "task\tlanguage\tvariant\tsize\tratio\t%s\t\n", strings.Join(categories, "\t"))
+	for _, m := range modules {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%.2f×\t", m.Task, m.Language, m.Variant, m.Size, m.Ratio)
+		for _, category := range categories {
+			fmt.Fprintf(tw, "%d\t", m.Breakdown[category])
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if packages > 0 {
+		for _, m := range modules {
+			if len(m.Packages) == 0 {
+				fmt.Fprintf(w, "\n%s: no function names to attribute its code\n", m.Path)
+				continue
+			}
+			fmt.Fprintf(w, "\n%s: code by package\n", m.Path)
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+			for _, p := range m.Packages[:min(packages, len(m.Packages))] {
+				fmt.Fprintf(tw, "  %d\t%.1f%%\t %s\n", p.Size, 100*float64(p.Size)/float64(m.Breakdown[categoryCode]), p.Name)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if verbose {
+		for _, m := range modules {
+			fmt.Fprintf(w, "\n%s: %d imports, %d exports\n", m.Path, len(m.Imports), len(m.Exports))
+			for _, name := range m.Imports {
+				fmt.Fprintln(w, "  import", name)
+			}
+			for _, name := range m.Exports {
+				fmt.Fprintln(w, "  export", name)
+			}
+		}
+	}
+	return nil
+}
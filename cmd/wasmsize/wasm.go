@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// wasmHeader is the magic number and version 1 every module starts with
+var wasmHeader = []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+
+// Names of the standard sections by id
+var sectionNames = []string{"custom", "type", "import", "function", "table", "memory", "global", "export", "start", "element", "code", "data", "datacount", "tag"}
+
+// Names of the import and export kinds by their byte
+var externKinds = []string{"func", "table", "memory", "global", "tag"}
+
+// section is one section of a module. Its size counts the id byte and the
+// size field along with the contents, so a module's sections and its 8 byte
+// header add up to its file size.
+type section struct {
+	Name string `json:"name"` // Custom sections are "custom:<name>", like custom:producers
+	Size int    `json:"size"`
+}
+
+// binary is the parsed layout of a module
+type binary struct {
+	Sections []section     `json:"sections"`           // In file order
+	Imports  []string      `json:"imports"`            // <module>.<name> (<kind>)
+	Exports  []string      `json:"exports"`            // <name> (<kind>)
+	Packages []packageSize `json:"packages,omitempty"` // Largest first, for a module with function names
+}
+
+// packageSize is the code of one package: its function bodies, size fields
+// included, attributed by the names in the module's name section
+type packageSize struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// parseBinary reads the sections of a wasm module, and the names of its
+// imports and exports
+func parseBinary(data []byte) (binary, error) {
+	var b binary
+	if !bytes.HasPrefix(data, wasmHeader) {
+		return b, errors.New("not a version 1 wasm module")
+	}
+	var (
+		funcImports int
+		bodies      []int
+		names       map[uint32]string
+	)
+	r := &reader{data: data, pos: len(wasmHeader)}
+	for r.pos < len(data) {
+		start := r.pos
+		id := r.byte()
+		size := r.u32()
+		end := r.pos + int(size)
+		if r.err != nil || end > len(data) {
+			return b, fmt.Errorf("section at offset %d runs past the end of the module", start)
+		}
+		contents := &reader{data: data[:end], pos: r.pos}
+		name := fmt.Sprintf("unknown(%d)", id)
+		if int(id) < len(sectionNames) {
+			name = sectionNames[id]
+		}
+		switch id {
+		case 0:
+			name = "custom:" + contents.name()
+			if name == "custom:name" {
+				names = contents.functionNames()
+			}
+		case 2:
+			b.Imports, funcImports = contents.imports()
+		case 7:
+			b.Exports = contents.exports()
+		case 10:
+			bodies = contents.bodies()
+		}
+		if contents.err != nil {
+			return b, fmt.Errorf("%s section at offset %d: %w", name, start, contents.err)
+		}
+		b.Sections = append(b.Sections, section{name, end - start})
+		r.pos = end
+	}
+	b.Packages = packageSizes(bodies, funcImports, names)
+	return b, nil
+}
+
+// reader decodes the binary format, keeping the first error
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errTruncated = errors.New("truncated")
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.data) {
+		r.err = errTruncated
+		return 0
+	}
+	r.pos++
+	return r.data[r.pos-1]
+}
+
+// u64 reads an unsigned LEB128 number
+func (r *reader) u64() uint64 {
+	var n uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b := r.byte()
+		n |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n
+		}
+	}
+	if r.err == nil {
+		r.err = errors.New("LEB128 number too long")
+	}
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	n := r.u64()
+	if n > 1<<32-1 && r.err == nil {
+		r.err = errors.New("u32 out of range")
+	}
+	return uint32(n)
+}
+
+func (r *reader) name() string {
+	n := int(r.u32())
+	if r.err != nil {
+		return ""
+	}
+	if n > len(r.data)-r.pos {
+		r.err = errTruncated
+		return ""
+	}
+	r.pos += n
+	return string(r.data[r.pos-n : r.pos])
+}
+
+// limits skips the limits of a table or memory type
+func (r *reader) limits() {
+	if flags := r.byte(); flags&1 != 0 {
+		r.u64()
+	}
+	r.u64()
+}
+
+func kindName(kind byte) string {
+	if int(kind) < len(externKinds) {
+		return externKinds[kind]
+	}
+	return fmt.Sprintf("kind %d", kind)
+}
+
+// imports reads the import section, returning the imports and how many are
+// functions, which come before the module's own in the function index space
+func (r *reader) imports() (imports []string, funcs int) {
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		module, name := r.name(), r.name()
+		kind := r.byte()
+		switch kind {
+		case 0: // Type index
+			r.u32()
+			funcs++
+		case 1: // Element type, limits
+			r.byte()
+			r.limits()
+		case 2:
+			r.limits()
+		case 3: // Value type, mutability
+			r.byte()
+			r.byte()
+		case 4: // Attribute, type index
+			r.byte()
+			r.u32()
+		default:
+			r.err = fmt.Errorf("import %s.%s has unknown kind %d", module, name, kind)
+		}
+		imports = append(imports, fmt.Sprintf("%s.%s (%s)", module, name, kindName(kind)))
+	}
+	return imports, funcs
+}
+
+func (r *reader) exports() []string {
+	var exports []string
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		name := r.name()
+		kind := r.byte()
+		r.u32()
+		exports = append(exports, fmt.Sprintf("%s (%s)", name, kindName(kind)))
+	}
+	return exports
+}
+
+// bodies reads the code section, returning the size of each function body
+// with its size field
+func (r *reader) bodies() []int {
+	var sizes []int
+	for n := r.u32(); n > 0 && r.err == nil; n-- {
+		start := r.pos
+		size := int(r.u32())
+		if r.err == nil && size > len(r.data)-r.pos {
+			r.err = errTruncated
+		}
+		r.pos += size
+		sizes = append(sizes, r.pos-start)
+	}
+	return sizes
+}
+
+// functionNames reads the function names subsection of a name section,
+// skipping the other subsections
+func (r *reader) functionNames() map[uint32]string {
+	names := map[uint32]string{}
+	for r.pos < len(r.data) && r.err == nil {
+		id := r.byte()
+		size := int(r.u32())
+		if r.err != nil || size > len(r.data)-r.pos {
+			r.err = errTruncated
+			break
+		}
+		end := r.pos + size
+		if id == 1 {
+			sub := &reader{data: r.data[:end], pos: r.pos}
+			for n := sub.u32(); n > 0 && sub.err == nil; n-- {
+				index := sub.u32()
+				names[index] = sub.name()
+			}
+			r.err = sub.err
+		}
+		r.pos = end
+	}
+	return names
+}
+
+// packageSizes sums the function bodies by the package of each function's
+// name, largest first; nil without names. The code section only holds the
+// module's own functions, whose indices follow the imported ones.
+func packageSizes(bodies []int, funcImports int, names map[uint32]string) []packageSize {
+	if len(names) == 0 {
+		return nil
+	}
+	sizes := map[string]int{}
+	for i, size := range bodies {
+		name, ok := names[uint32(funcImports+i)]
+		if !ok {
+			sizes["(unnamed)"] += size
+			continue
+		}
+		sizes[packageOf(name)] += size
+	}
+	var packages []packageSize
+	for name, size := range sizes {
+		packages = append(packages, packageSize{name, size})
+	}
+	slices.SortFunc(packages, func(a, b packageSize) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Name, b.Name))
+	})
+	return packages
+}
+
+// packageOf returns the package of a function name. TinyGo writes Go names
+// like wasmbench/common.HashBytes and (*strings.Builder).WriteString, whose
+// package is the path up to the first dot after the last slash. For Rust the
+// crate is the first path component, of a demangled name like
+// <alloc::vec::Vec<T> as core::ops::Drop>::drop or of a mangled one like
+// _ZN4core3fmt5write17h0123456789abcdefE. Names of neither form, the C
+// library's memcpy among them, are grouped as (unqualified).
+func packageOf(name string) string {
+	if crate, _, ok := strings.Cut(strings.TrimLeft(name, "<&"), "::"); ok && !strings.ContainsAny(crate, " ()<>") {
+		return crate
+	}
+	if rest, ok := strings.CutPrefix(name, "_ZN"); ok {
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if n, err := strconv.Atoi(rest[:digits]); err == nil && digits+n <= len(rest) {
+			return rest[digits : digits+n]
+		}
+	}
+	name = strings.TrimLeft(name, "(*")
+	slash := strings.LastIndex(name, "/") + 1
+	if dot := strings.Index(name[slash:], "."); dot > 0 {
+		return name[:slash+dot]
+	}
+	return "(unqualified)"
+}
+
+// Categories of the size breakdown, the columns of the summary table
+const (
+	categoryCode   = "code"
+	categoryData   = "data"
+	categoryNames  = "names"  // The name section
+	categoryDebug  = "debug"  // DWARF .debug_* sections
+	categoryCustom = "custom" // Other custom sections, like producers and target_features
+	categoryOther  = "other"  // The header and the declaration sections
+)
+
+var categories = []string{categoryCode, categoryData, categoryNames, categoryDebug, categoryCustom, categoryOther}
+
+// breakdown sums the sections, and the header, into the categories
+func (b binary) breakdown() map[string]int {
+	sizes := map[string]int{categoryOther: len(wasmHeader)}
+	for _, s := range b.Sections {
+		switch {
+		case s.Name == "code":
+			sizes[categoryCode] += s.Size
+		case s.Name == "data" || s.Name == "datacount":
+			sizes[categoryData] += s.Size
+		case s.Name == "custom:name":
+			sizes[categoryNames] += s.Size
+		case strings.HasPrefix(s.Name, "custom:.debug_"):
+			sizes[categoryDebug] += s.Size
+		case strings.HasPrefix(s.Name, "custom:"):
+			sizes[categoryCustom] += s.Size
+		default:
+			sizes[categoryOther] += s.Size
+		}
+	}
+	return sizes
+}
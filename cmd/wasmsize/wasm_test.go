@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sec encodes a section; contents are under 128 bytes, so the size is one byte
+func sec(id byte, contents ...byte) []byte {
+	return append([]byte{id, byte(len(contents))}, contents...)
+}
+
+// str encodes a name
+func str(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func cat(parts ...[]byte) []byte {
+	return slices.Concat(parts...)
+}
+
+// testModule imports env.log and a memory, exports run_task, and has a data
+// segment and name and producers custom sections
+var testModule = cat(
+	wasmHeader,
+	sec(1, 0x01, 0x60, 0x01, 0x7f, 0x01, 0x7f), // Type (i32) -> i32
+	sec(2, cat([]byte{0x02},
+		str("env"), str("log"), []byte{0x00, 0x00},
+		str("env"), str("memory"), []byte{0x02, 0x01, 0x01, 0x10})...), // Memory 1..16 pages
+	sec(3, 0x01, 0x00),
+	sec(7, cat([]byte{0x01}, str("run_task"), []byte{0x00, 0x01})...),
+	sec(10, 0x01, 0x04, 0x00, 0x20, 0x00, 0x0b), // local.get 0
+	sec(11, 0x01, 0x00, 0x41, 0x00, 0x0b, 0x03, 'a', 'b', 'c'),
+	sec(0, cat(str("name"), []byte{0x00, 0x02, 0x01, 'm'})...),
+	sec(0, cat(str("producers"), []byte{0x00})...),
+)
+
+func TestParseBinary(t *testing.T) {
+	b, err := parseBinary(testModule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	total := len(wasmHeader)
+	for _, s := range b.Sections {
+		names = append(names, s.Name)
+		total += s.Size
+	}
+	if strings.Join(names, ",") != "type,import,function,export,code,data,custom:name,custom:producers" {
+		t.Errorf("sections %v", names)
+	}
+	if total != len(testModule) {
+		t.Errorf("sections and header add up to %d bytes, expected %d", total, len(testModule))
+	}
+	if strings.Join(b.Imports, ",") != "env.log (func),env.memory (memory)" || strings.Join(b.Exports, ",") != "run_task (func)" {
+		t.Errorf("imports %v and exports %v", b.Imports, b.Exports)
+	}
+
+	sizes := b.breakdown()
+	expected := map[string]int{"code": 8, "data": 11, "names": 11, "debug": 0, "custom": 13, "other": len(testModule) - 8 - 11 - 11 - 13}
+	for _, category := range categories {
+		if sizes[category] != expected[category] {
+			t.Errorf("%s: %d bytes, expected %d", category, sizes[category], expected[category])
+		}
+	}
+}
+
+func TestParseBinaryRejects(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"not wasm":          []byte("\x7fELF\x02\x01\x01\x00"),
+		"section past end":  cat(wasmHeader, []byte{0x01, 0x10, 0x00}),
+		"truncated imports": cat(wasmHeader, sec(2, 0x01, 0x03, 'e', 'n')),
+	} {
+		if _, err := parseBinary(data); err == nil {
+			t.Errorf("%s: parsed", name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	builds := t.TempDir()
+	small := cat(wasmHeader, sec(10, 0x00))
+	for path, data := range map[string][]byte{
+		"tinygo/matrix_mul-o2.wasm": testModule,
+		"rust/matrix_mul-o3.wasm":   small,
+		"rust/mandelbrot-o3.wasm":   small,
+	} {
+		path = filepath.Join(builds, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jsonPath := filepath.Join(t.TempDir(), "sizes.json")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-builds", builds, "-v", "-packages", "3", "-json", jsonPath}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit status %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "export run_task (func)") {
+		t.Errorf("-v output does not list the exports:\n%s", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "matrix_mul-o2.wasm: no function names to attribute its code") {
+		t.Errorf("-packages output does not note the module without function names:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var modules []module
+	if err := json.Unmarshal(data, &modules); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range modules {
+		got = append(got, m.Task+" "+m.Language+" "+m.Variant)
+	}
+	if strings.Join(got, ",") != "mandelbrot rust o3,matrix_mul rust o3,matrix_mul tinygo o2" {
+		t.Errorf("modules %v, expected them by task then size", got)
+	}
+	if last := modules[2]; last.Ratio != float64(len(testModule))/float64(len(small)) || len(last.Sections) != 8 {
+		t.Errorf("TinyGo module %+v, expected its ratio to the Rust build and its 8 sections", last)
+	}
+}
+
+func TestPackages(t *testing.T) {
+	// Functions 1 to 4 follow the imported env.log: bodies of 3, 5, 4 and 3
+	// bytes with their size field, all but the last named
+	functionNames := cat([]byte{0x03},
+		[]byte{0x01}, str("(*strings.Builder).WriteString"),
+		[]byte{0x02}, str("strconv.AppendFloat"),
+		[]byte{0x03}, str("strings.Index"))
+	module := cat(
+		wasmHeader,
+		sec(1, 0x01, 0x60, 0x00, 0x00),
+		sec(2, cat([]byte{0x01}, str("env"), str("log"), []byte{0x00, 0x00})...),
+		sec(3, 0x04, 0x00, 0x00, 0x00, 0x00),
+		sec(10, 0x04,
+			0x02, 0x00, 0x0b,
+			0x04, 0x00, 0x01, 0x01, 0x0b,
+			0x03, 0x00, 0x01, 0x0b,
+			0x02, 0x00, 0x0b),
+		sec(0, cat(str("name"), []byte{0x00, 0x02, 0x01, 'm', 0x01, byte(len(functionNames))}, functionNames)...),
+	)
+	b, err := parseBinary(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range b.Packages {
+		got = append(got, fmt.Sprintf("%s %d", p.Name, p.Size))
+	}
+	if strings.Join(got, ",") != "strings 7,strconv 5,(unnamed) 3" {
+		t.Errorf("packages %v", got)
+	}
+
+	if b, _ := parseBinary(testModule); b.Packages != nil {
+		t.Errorf("A module without function names has packages %v", b.Packages)
+	}
+}
+
+func TestPackageOf(t *testing.T) {
+	for name, want := range map[string]string{
+		"runtime.alloc":                                 "runtime",
+		"wasmbench/common.HashBytes":                    "wasmbench/common",
+		"(*strings.Builder).WriteString":                "strings",
+		"(wasmbench/common.Tolerance).String":           "wasmbench/common",
+		"main.main$1":                                   "main",
+		"core::fmt::write::h0123456789abcdef":           "core",
+		"<alloc::vec::Vec<T> as core::ops::Drop>::drop": "alloc",
+		"_ZN4core3fmt5write17h0123456789abcdefE":        "core",
+		"_ZN99short":                                    "(unqualified)",
+		"memcpy":                                        "(unqualified)",
+	} {
+		if got := packageOf(name); got != want {
+			t.Errorf("packageOf(%q) = %q, expected %q", name, got, want)
+		}
+	}
+}
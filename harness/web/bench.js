@@ -28,18 +28,44 @@ const FNV_HASH_CONSTANTS = {
     PRIME: 16777619
 };
 
-const MANDELBROT_CONSTANTS = {
-    BUFFER_SIZE: 40,
-    CENTER_REAL: -0.743643887037,
-    CENTER_IMAG: 0.131825904205,
-    SCALE_FACTOR: 3.0,
-    DEFAULT_MAX_ITER: 100
+// Verification hash algorithm ids, selected by verification.hash_algorithm in the config
+const HASH_ALGORITHMS = {
+    fnv1a: 0,
+    xxhash32: 1
 };
 
-const PARAM_BUFFER_SIZES = {
-    JSON: 8, // 2 * u32
-    MATRIX: 8, // 2 * u32
-    MANDELBROT: 40
+// Random data generator ids of the params Generator field; the host generator
+// draws from env.next_random, fed by the randomValues config option
+const GENERATORS = {
+    lcg: 0,
+    host: 2
+};
+
+// Encoded params buffer header, accepted by modules reporting ABI version 2 or later
+const PARAMS_ENCODING = {
+    MAGIC: 0x50424d57, // "WMBP" in memory
+    VERSION: 1,
+    HEADER_SIZE: 12, // u32 magic, u32 version, u32 payload length
+    MIN_ABI_VERSION: 2
+};
+
+// ABI versions this harness can drive; newer modules are refused before run_task
+const SUPPORTED_ABI_VERSIONS = {
+    MIN: 1,
+    MAX: 2
+};
+
+// Optimization suffix ending of simd128 builds (scripts/build_tinygo.sh --simd)
+const SIMD_SUFFIX = '-simd';
+
+// run_task_timed result: u32 status, u32 hash, f64 elapsed milliseconds
+const TIMED_RESULT_SIZE = 16;
+
+// Byte size of each params field type of the task manifest
+const PARAM_FIELD_SIZES = {
+    u32: 4,
+    u64: 8,
+    f64: 8
 };
 
 export class BenchmarkRunner {
@@ -54,6 +80,11 @@ export class BenchmarkRunner {
         // Initialize random number generator (will be configured from config)
         this.randomSeed = MEASUREMENT_CONSTANTS.DEFAULT_RANDOM_SEED;
         this.random = this._xorshift32(this.randomSeed);
+        this.hashAlgorithm = HASH_ALGORITHMS.fnv1a;
+        this.generator = GENERATORS.lcg;
+
+        // Params layouts and defaults of every task, from configs/tasks.json
+        this.taskManifest = null;
     }
 
     /**
@@ -106,6 +137,11 @@ export class BenchmarkRunner {
         // Apply configuration to instance
         this._applyConfig(config);
 
+        // Params are written by the layouts the task packages define, so the harness keeps no copy of them
+        if (!this.taskManifest) {
+            this.taskManifest = await this.configLoader.loadTaskManifest();
+        }
+
         window.logResult('Initializing benchmark runner', 'success');
         window.logResult(
             `Config loaded: ${config.taskNames?.length || 0} tasks, ${config.enabledLanguages?.length || 0} languages`
@@ -150,6 +186,19 @@ export class BenchmarkRunner {
             this.random = this._xorshift32(this.randomSeed);
         }
 
+        // Select the verification hash; modules without the field always use FNV-1a
+        if (config.verification && config.verification.hashAlgorithm) {
+            const algorithm = HASH_ALGORITHMS[config.verification.hashAlgorithm];
+            if (algorithm === undefined) {
+                throw new Error(`Unknown verification hash algorithm: ${config.verification.hashAlgorithm}`);
+            }
+            this.hashAlgorithm = algorithm;
+        }
+
+        // Replay host-supplied data (e.g. a captured dataset) instead of the LCG
+        this.loader.setRandomSource(config.randomValues ?? null);
+        this.generator = config.randomValues?.length ? GENERATORS.host : GENERATORS.lcg;
+
         // Store config reference for easy access
         this.config = config;
     }
@@ -340,7 +389,12 @@ export class BenchmarkRunner {
             throw new Error(`No optimization suffix defined for ${language} in task ${taskName}`);
         }
 
-        const wasmPath = `/builds/${language}/${taskNameSnakeCase}-${optSuffix}.wasm`;
+        // A simd128 module fails to compile without SIMD support, so fall back to the scalar build
+        let wasmPath = `/builds/${language}/${taskNameSnakeCase}-${optSuffix}.wasm`;
+        if (optSuffix.endsWith(SIMD_SUFFIX) && !this.loader.hostHasSimd()) {
+            wasmPath = `/builds/${language}/${taskNameSnakeCase}-${optSuffix.slice(0, -SIMD_SUFFIX.length)}.wasm`;
+            window.logResult(`No simd128 support, running the scalar ${language} build`, 'warning');
+        }
 
         window.benchmarkState.currentTask = taskName;
         window.benchmarkState.currentLang = language;
@@ -356,14 +410,78 @@ export class BenchmarkRunner {
             // Load the WASM module
             const instance = await this.loader.loadModule(wasmPath, moduleId);
 
+            // Handshake: refuse modules whose ABI this harness does not implement instead of misreading them
+            const abiVersion = this.loader.readAbiVersion(instance);
+            if (abiVersion < SUPPORTED_ABI_VERSIONS.MIN || abiVersion > SUPPORTED_ABI_VERSIONS.MAX) {
+                throw new Error(
+                    `Module ${moduleId} implements ABI v${abiVersion}; ` +
+                        `this harness supports v${SUPPORTED_ABI_VERSIONS.MIN}-v${SUPPORTED_ABI_VERSIONS.MAX}`
+                );
+            }
+
+            const taskInfo = this.loader.readTaskInfo(instance);
+            if (taskInfo) {
+                window.logResult(
+                    `Module: ${taskInfo.task} (${taskInfo.language}, ${taskInfo.variant}, ABI v${abiVersion})`
+                );
+            }
+
+            // Encoded params are decoded field by field, so only raw structs depend on the module's layout
+            const encodeParams = abiVersion >= PARAMS_ENCODING.MIN_ABI_VERSION;
+            if (!encodeParams) {
+                // Refuse to run if the module's params layout differs from the one written below
+                this._checkParamsLayout(instance, taskNameSnakeCase);
+            }
+
             // Initialize with seed
             instance.exports.init(this.randomSeed);
 
+            // Known-answer vectors catch a broken artifact before any time is spent benchmarking it
+            const selfTest = this.loader.runSelfTest(instance);
+            if (selfTest && !selfTest.passed) {
+                throw new Error(`Module self test failed (status ${selfTest.status}): ${selfTest.reason}`);
+            }
+
+            // Stage checkpoint hashes pinpoint where a cross-language hash diverges
+            this.loader.setCheckpoints(instance, Boolean(config.checkpoints));
+
+            // Cap module memory so oversized params fail validation instead of trapping on memory.grow
+            this.loader.setMemoryBudget(instance, (config.memoryBudgetMb ?? 0) * 1024 * 1024);
+
+            // Parallel variants only go wide when both the page and the module support threads
+            const threads = this.loader.negotiateThreads(instance, config.threads ?? 1);
+            // Label results from simd128 builds; scalar fallbacks report false
+            const simd = this.loader.readHasSimd(instance);
+
             // Generate input data based on task and scale
             const inputData = this._generateInputData(taskName, scale, config);
 
             // Write input data to WASM memory
-            const dataPtr = this.loader.writeDataToMemory(instance, inputData);
+            const paramsData = encodeParams ? this._encodeParams(inputData, taskNameSnakeCase) : inputData;
+            const dataPtr = this.loader.writeDataToMemory(instance, paramsData);
+
+            // Reject bad parameters with the module's reason before any run
+            if (
+                typeof instance.exports.validate_params === 'function' &&
+                instance.exports.validate_params(dataPtr) !== 0
+            ) {
+                const code = this.loader.readErrorCode(instance);
+                const reason = this.loader.readLastError(instance);
+                throw new Error(`Invalid parameters${code ? ` [${code}]` : ''}: ${reason}`);
+            }
+
+            // Pre-reserve the working set so repetitions never grow memory mid-run
+            if (
+                config.reserveMemory &&
+                typeof instance.exports.reserve_memory === 'function' &&
+                instance.exports.reserve_memory(dataPtr) !== 0
+            ) {
+                throw new Error(`Memory reservation failed: ${this.loader.readLastError(instance)}`);
+            }
+
+            // Buffer for run_task_timed's {status, hash, elapsed_ms} result, if exported
+            const resultPtr =
+                typeof instance.exports.run_task_timed === 'function' ? instance.exports.alloc(TIMED_RESULT_SIZE) : 0;
 
             // Warmup runs (discard results)
             window.logResult(`Warmup runs: ${config.warmupRuns}`);
@@ -371,6 +489,8 @@ export class BenchmarkRunner {
                 if (this.cancelled) return;
 
                 window.benchmarkState.currentRun = i + 1;
+                this.loader.runDeadline = config.timeout ? performance.now() + config.timeout : null;
+                this.loader.rewindRandomSource();
                 instance.exports.run_task(dataPtr);
 
                 // Garbage collection hint between warmup runs
@@ -385,14 +505,19 @@ export class BenchmarkRunner {
                 if (this.cancelled) return;
 
                 window.benchmarkState.currentRun = config.warmupRuns + i + 1;
+                this.loader.runDeadline = config.timeout ? performance.now() + config.timeout : null;
+                // Every run draws the same host data from the start
+                this.loader.rewindRandomSource();
 
-                const result = await this._measureSingleRun(instance, dataPtr, {
+                const result = await this._measureSingleRun(instance, dataPtr, resultPtr, {
                     task: taskName,
                     language: language,
                     scale: scale,
                     run: i + 1,
                     repetition: repetition,
                     moduleId: moduleId,
+                    threads: threads,
+                    simd: simd,
                     inputData: inputData,
                     inputDataHash: this._computeInputDataHash(inputData)
                 });
@@ -404,6 +529,10 @@ export class BenchmarkRunner {
                 const totalCompleted = window.benchmarkState.successfulRuns + window.benchmarkState.failedRuns;
                 window.benchmarkState.progress = (totalCompleted / window.benchmarkState.totalRuns) * 100;
             }
+
+            // Release the buffers pinned by alloc
+            this.loader.freeDataFromMemory(instance, dataPtr);
+            this.loader.freeDataFromMemory(instance, resultPtr);
         } catch (error) {
             window.benchmarkState.failedRuns++;
             const errorLogMsg =
@@ -423,6 +552,7 @@ export class BenchmarkRunner {
                 success: false
             });
         } finally {
+            this.loader.runDeadline = null;
             // Clean up any loaded WASM module for this task
             this.loader.unloadModule(moduleId);
         }
@@ -432,7 +562,7 @@ export class BenchmarkRunner {
      * Measure a single benchmark run
      * @private
      */
-    async _measureSingleRun(instance, dataPtr, metadata) {
+    async _measureSingleRun(instance, dataPtr, resultPtr, metadata) {
         // Force garbage collection before measurement
         if (typeof window.gc === 'function') {
             window.gc();
@@ -450,11 +580,38 @@ export class BenchmarkRunner {
               }
             : null;
 
+        // Sample module runtime counters outside the timed region
+        const moduleStatsBefore = this.loader.readMemoryStats(instance);
+
         // Measure execution time
         const timeBefore = performance.now();
-        const hash = instance.exports.run_task(dataPtr);
+        let hash = resultPtr ? instance.exports.run_task_timed(dataPtr, resultPtr) : instance.exports.run_task(dataPtr);
         const timeAfter = performance.now();
 
+        const moduleStatsAfter = moduleStatsBefore && this.loader.readMemoryStats(instance);
+        const moduleResult = this.loader.readResult(instance);
+        const stageHashes = this.loader.readCheckpoints(instance);
+
+        // run_task_timed returns the status; the hash and in-module duration are in the result buffer
+        let moduleExecutionTime = null;
+        if (resultPtr) {
+            const view = new DataView(instance.exports.memory.buffer);
+            hash = view.getUint32(resultPtr + 4, true);
+            moduleExecutionTime = view.getFloat64(resultPtr + 8, true);
+        }
+
+        // A zero hash is only a failure if the module recorded an error
+        if (hash === 0) {
+            const panicMessage = this.loader.readPanicMessage(instance);
+            if (panicMessage) {
+                throw new Error(`run_task panicked: ${panicMessage}`);
+            }
+            const lastError = this.loader.readLastError(instance);
+            if (lastError) {
+                throw new Error(`run_task failed: ${lastError}`);
+            }
+        }
+
         // Capture final memory state
         const memAfter = performance.memory
             ? {
@@ -480,10 +637,21 @@ export class BenchmarkRunner {
         const result = {
             ...metadataWithoutInputData,
             executionTime: executionTime,
+            moduleExecutionTime: moduleExecutionTime, // Measured inside the module, null if unsupported
             memoryUsageMb: memoryDelta,
             memoryUsed: memoryDeltaBytes, // Memory usage in bytes
             wasmMemoryBytes: wasmMemStats ? wasmMemStats.bytes : 0,
+            // Module runtime counters for this run, null if get_memory_stats is not exported
+            moduleHeapInUse: moduleStatsAfter ? moduleStatsAfter.heapInUse : null,
+            moduleAllocBytes: moduleStatsAfter ? moduleStatsAfter.totalAlloc - moduleStatsBefore.totalAlloc : null,
+            moduleAllocCount: moduleStatsAfter ? moduleStatsAfter.mallocs - moduleStatsBefore.mallocs : null,
+            moduleGcCycles: moduleStatsAfter ? moduleStatsAfter.numGC - moduleStatsBefore.numGC : null,
+            // Work done by this run from the get_result_ptr block, null if not exported
+            elementsProcessed: moduleResult ? moduleResult.elementsProcessed : null,
+            bytesTouched: moduleResult ? moduleResult.bytesTouched : null,
             resultHash: hash >>> 0, // Ensure unsigned 32-bit
+            // Per-stage hashes when config.checkpoints is set, null otherwise
+            stageHashes: stageHashes,
             timestamp: Date.now(),
             jsHeapBefore: memBefore ? memBefore.used : 0,
             jsHeapAfter: memAfter ? memAfter.used : 0,
@@ -495,6 +663,131 @@ export class BenchmarkRunner {
         return result;
     }
 
+    /**
+     * Compare the module's params_fingerprint() export with the expected layout.
+     * Modules built before the export existed are not checked.
+     * @private
+     * @param {WebAssembly.Instance} instance - Loaded task module
+     * @param {string} taskName - Task name in snake_case
+     */
+    _checkParamsLayout(instance, taskName) {
+        const layout = this._paramsLayout(taskName);
+        if (typeof instance.exports.params_fingerprint !== 'function') {
+            return;
+        }
+
+        const expected = this._computeLayoutFingerprint(layout);
+        const actual = instance.exports.params_fingerprint() >>> 0;
+        if (actual !== expected) {
+            throw new Error(
+                `Params layout mismatch for ${taskName}: module fingerprint ${actual}, harness expects ${expected}`
+            );
+        }
+    }
+
+    /**
+     * Repack a raw params struct as an encoded params buffer: a magic, version
+     * and payload length header followed by the fields in declaration order,
+     * little-endian and without padding
+     * @private
+     * @param {Uint8Array} rawParams - Params struct as generated for the task
+     * @param {string} taskName - Task name in snake_case
+     * @returns {Uint8Array} Encoded params buffer
+     */
+    _encodeParams(rawParams, taskName) {
+        const fields = this._paramsLayout(taskName).slice(0, -1);
+        const payloadLength = fields.reduce((total, [, size]) => total + size, 0);
+        const encoded = new Uint8Array(PARAMS_ENCODING.HEADER_SIZE + payloadLength);
+
+        const header = new DataView(encoded.buffer);
+        header.setUint32(0, PARAMS_ENCODING.MAGIC, true);
+        header.setUint32(4, PARAMS_ENCODING.VERSION, true);
+        header.setUint32(8, payloadLength, true);
+
+        // Fields are already little-endian in the raw struct, so copy their bytes
+        let position = PARAMS_ENCODING.HEADER_SIZE;
+        for (const [offset, size] of fields) {
+            encoded.set(rawParams.subarray(offset, offset + size), position);
+            position += size;
+        }
+
+        return encoded;
+    }
+
+    /**
+     * Look up a task in the task manifest
+     * @private
+     * @param {string} taskName - Task name in snake_case
+     * @returns {Object} The task's manifest entry
+     */
+    _manifestTask(taskName) {
+        const task = this.taskManifest?.tasks[taskName];
+        if (!task) {
+            throw new Error(`Task ${taskName} is not in the task manifest; regenerate configs/tasks.json with cmd/gentasks`);
+        }
+        return task;
+    }
+
+    /**
+     * Params struct layout of a task as [offset, size] pairs in field order,
+     * followed by the struct size, the form params_fingerprint() hashes
+     * @private
+     * @param {string} taskName - Task name in snake_case
+     * @returns {Array} Layout of the task's params struct
+     */
+    _paramsLayout(taskName) {
+        const task = this._manifestTask(taskName);
+        return [...task.params.map(field => [field.offset, PARAM_FIELD_SIZES[field.type]]), task.params_size];
+    }
+
+    /**
+     * Write a task's params struct by the manifest's layout: the given values
+     * by field name, the task's default params for the rest and zero for any
+     * field without a default
+     * @private
+     * @param {string} taskName - Task name in snake_case
+     * @param {Object} values - Field values by snake_case field name
+     * @returns {Uint8Array} Raw params struct
+     */
+    _writeParams(taskName, values) {
+        const task = this._manifestTask(taskName);
+        const params = new ArrayBuffer(task.params_size);
+        const view = new DataView(params);
+
+        for (const field of task.params) {
+            const value = values[field.name] ?? task.defaults[field.name] ?? 0;
+            if (field.type === 'f64') {
+                view.setFloat64(field.offset, value, true);
+            } else if (field.type === 'u64') {
+                view.setBigUint64(field.offset, BigInt(value), true);
+            } else {
+                view.setUint32(field.offset, value, true);
+            }
+        }
+
+        return new Uint8Array(params);
+    }
+
+    /**
+     * Compute the FNV-1a hash of a params layout, hashing each offset, size and
+     * the struct size as little-endian u32 words
+     * @private
+     * @param {Array} layout - [offset, size] pairs followed by the struct size
+     * @returns {number} 32-bit layout fingerprint
+     */
+    _computeLayoutFingerprint(layout) {
+        let hash = FNV_HASH_CONSTANTS.OFFSET_BASIS;
+
+        for (const word of layout.flat()) {
+            for (let shift = 0; shift < 32; shift += 8) {
+                hash ^= (word >>> shift) & 0xff;
+                hash = Math.imul(hash, FNV_HASH_CONSTANTS.PRIME) >>> 0;
+            }
+        }
+
+        return hash;
+    }
+
     /**
      * Compute FNV-1a hash of input data for compact storage
      * @private
@@ -597,24 +890,18 @@ export class BenchmarkRunner {
             throw new Error('Mandelbrot width and height must be positive integers');
         }
 
-        const maxIter = scaleConfig.maxIter || MANDELBROT_CONSTANTS.DEFAULT_MAX_ITER;
-        if (maxIter <= 0) {
+        if (scaleConfig.maxIter !== undefined && scaleConfig.maxIter <= 0) {
             throw new Error('Mandelbrot maxIter must be a positive integer');
         }
 
-        const params = new ArrayBuffer(MANDELBROT_CONSTANTS.BUFFER_SIZE);
-        const view = new DataView(params);
-
-        // MandelbrotParams struct layout matching Rust: Width, Height, MaxIter, CenterReal, CenterImag, ScaleFactor
-        view.setUint32(0, scaleConfig.width, true); // Width: uint32
-        view.setUint32(4, scaleConfig.height, true); // Height: uint32
-        view.setUint32(8, maxIter, true); // MaxIter: uint32
-        view.setUint32(12, 0, true); // Padding for 8-byte alignment
-        view.setFloat64(16, MANDELBROT_CONSTANTS.CENTER_REAL, true); // CenterReal: float64
-        view.setFloat64(24, MANDELBROT_CONSTANTS.CENTER_IMAG, true); // CenterImag: float64
-        view.setFloat64(32, MANDELBROT_CONSTANTS.SCALE_FACTOR, true); // ScaleFactor: float64
-
-        return new Uint8Array(params);
+        // The view and, without maxIter, the iteration limit are the task's defaults;
+        // the other fields stay zero: custom dimensions, default profile, hash verification
+        return this._writeParams('mandelbrot', {
+            width: scaleConfig.width,
+            height: scaleConfig.height,
+            max_iter: scaleConfig.maxIter,
+            hash_algorithm: this.hashAlgorithm
+        });
     }
 
     /**
@@ -651,15 +938,13 @@ export class BenchmarkRunner {
         }
 
         try {
-            // Create binary parameter structure for WASM module
-            // The JSON task expects: [recordCount: u32, seed: u32]
-            const params = new ArrayBuffer(PARAM_BUFFER_SIZES.JSON);
-            const view = new DataView(params);
-
-            view.setUint32(0, recordCount, true); // recordCount
-            view.setUint32(4, this.randomSeed || MEASUREMENT_CONSTANTS.DEFAULT_RANDOM_SEED, true); // seed
-
-            return new Uint8Array(params);
+            // Warm-up runs are driven by the harness, so warmup_iterations stays zero with the other unset fields
+            return this._writeParams('json_parse', {
+                record_count: recordCount,
+                seed: this.randomSeed || MEASUREMENT_CONSTANTS.DEFAULT_RANDOM_SEED,
+                hash_algorithm: this.hashAlgorithm,
+                generator: this.generator // 0 = LCG, the generator of the reference hashes; 2 = host
+            });
         } catch (error) {
             throw new Error(`Failed to create JSON parameters: ${error.message}`);
         }
@@ -702,15 +987,13 @@ export class BenchmarkRunner {
             throw new Error(`Matrix dimension cannot exceed ${BENCHMARK_LIMITS.MAX_MATRIX_DIMENSION} for safety`);
         }
 
-        // Create binary parameter structure for WASM module
-        // The matrix task expects: MatrixMulParams { dimension: u32, seed: u32 }
-        const params = new ArrayBuffer(PARAM_BUFFER_SIZES.MATRIX);
-        const view = new DataView(params);
-
-        view.setUint32(0, dimension, true); // dimension: u32
-        view.setUint32(4, this.randomSeed || MEASUREMENT_CONSTANTS.DEFAULT_RANDOM_SEED, true); // seed: u32
-
-        return new Uint8Array(params);
+        // Warm-up runs are driven by the harness, so warmup_iterations stays zero with the other unset fields
+        return this._writeParams('matrix_mul', {
+            dimension,
+            seed: this.randomSeed || MEASUREMENT_CONSTANTS.DEFAULT_RANDOM_SEED,
+            hash_algorithm: this.hashAlgorithm,
+            generator: this.generator // 0 = LCG, the generator of the reference hashes; 2 = host
+        });
     }
 
     /**
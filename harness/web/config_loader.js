@@ -96,6 +96,20 @@ export class ConfigLoader {
         }
     }
 
+    /**
+     * Load the task manifest cmd/gentasks writes from the task packages: each
+     * task's params layout, default params, limits and exports
+     * @param {string} manifestPath - Path to the manifest
+     * @returns {Promise<Object>} Parsed manifest, with abi_version and tasks
+     */
+    async loadTaskManifest(manifestPath = '/configs/tasks.json') {
+        const response = await fetch(manifestPath);
+        if (!response.ok) {
+            throw new Error(this._createFetchErrorMessage(manifestPath, response.status, response.statusText));
+        }
+        return response.json();
+    }
+
     /**
      * Create detailed HTTP fetch error message
      * @param {string} path - File path that failed
@@ -14,6 +14,38 @@ export class WasmLoader {
         this.WASM_PAGE_SIZE = 65536;
         this.MAX_MODULE_ID_LENGTH = 100;
         this.MAX_DATA_SIZE = 100 * 1024 * 1024; // 100MB safety limit
+        this.GO_WASM_EXEC_PATH = '/builds/go/wasm_exec.js';
+        // Names of the shared parameter error codes, indexed by get_error_code
+        // (the error_codes list of data/error_codes.json)
+        this.PARAM_ERROR_NAMES = [
+            'none',
+            'null_params',
+            'bad_encoding',
+            'zero_dimension',
+            'too_large',
+            'non_finite',
+            'non_positive',
+            'unknown_scale',
+            'unknown_profile',
+            'unknown_verification',
+            'unknown_allocator',
+            'unknown_hash_algorithm',
+            'unknown_generator'
+        ];
+
+        // Values env.next_random returns to runs with the host generator, in
+        // order from randomIndex; null when the host supplies no data
+        this.randomValues = null;
+        this.randomIndex = 0;
+
+        // Latest env.report_progress call, {moduleId, permille, timestamp}; a stale
+        // timestamp during a run points at a hang
+        this.lastProgress = null;
+        // Optional (moduleId, permille) => void listener for progress displays
+        this.onProgress = null;
+        // performance.now() deadline of the current run; a progress report past it
+        // raises the module's cancellation flag instead of letting the run hang on
+        this.runDeadline = null;
     }
 
     /**
@@ -84,6 +116,17 @@ export class WasmLoader {
             const wasmBytes = await response.arrayBuffer();
             window.logResult(`Fetched ${wasmBytes.byteLength} bytes for ${moduleId}`);
 
+            const module = await WebAssembly.compile(wasmBytes);
+            if (this._isGoJsModule(module)) {
+                const instance = await this._instantiateGoJs(module, moduleId);
+                this._validateModuleExports(instance, moduleId);
+                window.logResult(`Successfully loaded ${moduleId} (standard Go)`, 'success');
+                return instance;
+            }
+
+            // Set once instantiated; env.log reads messages from its memory
+            let moduleInstance = null;
+
             // Instantiate the WASM module with imports for both Rust and TinyGo
             const imports = {
                 env: {
@@ -93,6 +136,33 @@ export class WasmLoader {
                     },
                     trace: (ptr, len) => {
                         console.log(`WASM trace: ptr=${ptr}, len=${len}`);
+                    },
+                    // Host clock for run_task_timed (TinyGo)
+                    now_ms: () => performance.now(),
+                    // Coarse progress of long TinyGo runs, in permille
+                    report_progress: permille => {
+                        this.lastProgress = { moduleId, permille, timestamp: performance.now() };
+                        this.onProgress?.(moduleId, permille);
+                        if (this.runDeadline !== null && this.lastProgress.timestamp > this.runDeadline) {
+                            this.requestCancel(moduleInstance);
+                        }
+                    },
+                    // Data of TinyGo runs with the host generator (Generator 2)
+                    next_random: () => this.nextRandom(),
+                    // Leveled debug log of TinyGo modules built with -tags debuglog
+                    log: (ptr, len) => {
+                        if (!moduleInstance) {
+                            return;
+                        }
+                        const message = new TextDecoder().decode(
+                            new Uint8Array(moduleInstance.exports.memory.buffer, ptr, len)
+                        );
+                        const type = message.startsWith('[error]')
+                            ? 'error'
+                            : message.startsWith('[warn]')
+                              ? 'warning'
+                              : 'log';
+                        window.logResult(`${moduleId}: ${message}`, type);
                     }
                 },
                 // WASI imports for TinyGo compatibility
@@ -189,7 +259,8 @@ export class WasmLoader {
                 }
             };
 
-            const { instance } = await WebAssembly.instantiate(wasmBytes, imports);
+            const instance = await WebAssembly.instantiate(module, imports);
+            moduleInstance = instance;
 
             // Validate required exports
             this._validateModuleExports(instance, moduleId);
@@ -202,6 +273,46 @@ export class WasmLoader {
         }
     }
 
+    /**
+     * Whether module was built by the standard Go compiler (GOOS=js), whose
+     * runtime imports gojs.runtime.wasmExit; TinyGo's wasm target does not
+     * @private
+     */
+    _isGoJsModule(module) {
+        return WebAssembly.Module.imports(module).some(
+            entry => entry.module === 'gojs' && entry.name === 'runtime.wasmExit'
+        );
+    }
+
+    /**
+     * Run a standard Go module under wasm_exec.js. Its main publishes the
+     * task exports on a global named by argv[1] and blocks, so go.run never
+     * settles; the exports are wrapped with the module memory to look like
+     * a TinyGo instance.
+     * @private
+     */
+    async _instantiateGoJs(module, moduleId) {
+        if (typeof globalThis.Go !== 'function') {
+            await import(/* @vite-ignore */ this.GO_WASM_EXEC_PATH);
+        }
+
+        const go = new globalThis.Go();
+        const exportName = `wasmbench_${moduleId.replace(/\W/g, '_')}`;
+        go.argv = ['wasmbench', exportName];
+
+        const instance = await WebAssembly.instantiate(module, go.importObject);
+        go.run(instance).catch(error => {
+            window.logResult(`${moduleId}: Go runtime exited: ${error.message}`, 'error');
+        });
+
+        const exports = globalThis[exportName];
+        delete globalThis[exportName];
+        if (!exports) {
+            throw new Error(`Module ${moduleId}: Go main did not publish its exports`);
+        }
+        return { exports: { ...exports, memory: instance.exports.mem } };
+    }
+
     /**
      * Validate that the module exports the required functions
      * @private
@@ -324,7 +435,13 @@ export class WasmLoader {
         }
 
         try {
-            const ptr = instance.exports.alloc(data.length);
+            // The data overwrites the whole buffer, so a module that can skip
+            // zeroing it (TinyGo's alloc_uninitialized) is asked to
+            const { alloc_uninitialized: allocUninitialized } = instance.exports;
+            const ptr =
+                typeof allocUninitialized === 'function'
+                    ? allocUninitialized(data.length)
+                    : instance.exports.alloc(data.length);
             if (ptr === 0) {
                 throw new Error('writeDataToMemory: allocation failed - returned null pointer');
             }
@@ -343,6 +460,18 @@ export class WasmLoader {
         }
     }
 
+    /**
+     * Release a buffer returned by alloc. TinyGo modules export the release
+     * function as dealloc; modules without it are left to their own allocator.
+     * @param {WebAssembly.Instance} instance
+     * @param {number} ptr
+     */
+    freeDataFromMemory(instance, ptr) {
+        if (ptr !== 0 && typeof instance.exports.dealloc === 'function') {
+            instance.exports.dealloc(ptr);
+        }
+    }
+
     /**
      * Read data from WASM memory
      * @param {WebAssembly.Instance} instance
@@ -355,6 +484,358 @@ export class WasmLoader {
         return memView.slice(ptr, ptr + length);
     }
 
+    /**
+     * Read the ABI version a module implements: its abi_version export, the
+     * version in its task info, or 1 for modules that publish neither
+     * (the original run_task interface, as the Rust modules implement it)
+     * @param {WebAssembly.Instance} instance
+     * @returns {number}
+     */
+    readAbiVersion(instance) {
+        if (typeof instance.exports.abi_version === 'function') {
+            return instance.exports.abi_version();
+        }
+        const taskInfo = this.readTaskInfo(instance);
+        return taskInfo?.abi_version ?? 1;
+    }
+
+    /**
+     * Read the metadata published by a task's get_task_info export
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} Task name, language, variant, ABI version and params schema, or null if not exported
+     */
+    readTaskInfo(instance) {
+        if (typeof instance.exports.get_task_info !== 'function') {
+            return null;
+        }
+
+        const ptr = instance.exports.get_task_info();
+        const length = new DataView(instance.exports.memory.buffer).getUint32(ptr, true);
+        return JSON.parse(new TextDecoder().decode(this.readDataFromMemory(instance, ptr + 4, length)));
+    }
+
+    /**
+     * Sample the runtime memory statistics published by a task's get_memory_stats export
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} Heap in use plus cumulative allocation and GC counters, or null if not exported
+     */
+    readMemoryStats(instance) {
+        if (typeof instance.exports.get_memory_stats !== 'function') {
+            return null;
+        }
+
+        const ptr = instance.exports.get_memory_stats();
+        const view = new DataView(instance.exports.memory.buffer);
+        const [heapInUse, totalAlloc, mallocs, numGC] = [0, 8, 16, 24].map(offset =>
+            Number(view.getBigUint64(ptr + offset, true))
+        );
+        return { heapInUse, totalAlloc, mallocs, numGC };
+    }
+
+    /**
+     * Ask a task's current run to stop by raising the flag at get_cancel_ptr. The
+     * run fails with status 5 at its next loop boundary and the instance stays usable.
+     * @param {WebAssembly.Instance} instance
+     * @returns {boolean} Whether the module supports cancellation
+     */
+    requestCancel(instance) {
+        if (!instance || typeof instance.exports.get_cancel_ptr !== 'function') {
+            return false;
+        }
+        new DataView(instance.exports.memory.buffer).setUint32(instance.exports.get_cancel_ptr(), 1, true);
+        return true;
+    }
+
+    /**
+     * Cap the module's linear memory through set_memory_budget, so params that
+     * would grow memory past the cap are rejected with status 2 before the run
+     * instead of trapping on memory.grow mid-benchmark
+     * @param {WebAssembly.Instance} instance
+     * @param {number} bytes - Budget in bytes, rounded down to whole pages; 0 removes it
+     * @returns {number|null} Pages the module may now grow to, or null if not supported
+     */
+    setMemoryBudget(instance, bytes) {
+        const { set_memory_budget: setBudget, get_max_memory_pages: getMaxPages } = instance?.exports ?? {};
+        if (typeof setBudget !== 'function' || typeof getMaxPages !== 'function') {
+            return null;
+        }
+        setBudget(Math.floor(bytes / this.WASM_PAGE_SIZE));
+        return getMaxPages();
+    }
+
+    /**
+     * Run the module's self_test export: a few tiny known-answer vectors
+     * embedded in the module, checked against the reference hashes
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} {passed, status, reason} of the test, or null if not exported
+     */
+    runSelfTest(instance) {
+        if (typeof instance.exports.self_test !== 'function') {
+            return null;
+        }
+        const status = instance.exports.self_test();
+        return {
+            passed: status === 0,
+            status,
+            reason: status === 0 ? null : this.readLastError(instance)
+        };
+    }
+
+    /**
+     * Set the values env.next_random returns to runs with the host generator
+     * (params Generator 2), e.g. a captured dataset. Values are handed out in
+     * order and wrap around when a run draws more than were given.
+     * @param {ArrayLike<number>|null} values - u32 values, or null to remove the source
+     */
+    setRandomSource(values) {
+        this.randomValues = values?.length ? Uint32Array.from(values) : null;
+        this.randomIndex = 0;
+    }
+
+    /**
+     * Restart the host random source, so every run draws the same values
+     */
+    rewindRandomSource() {
+        this.randomIndex = 0;
+    }
+
+    /**
+     * Next value of the host random source, the env.next_random import
+     * @returns {number} u32 value
+     */
+    nextRandom() {
+        if (this.randomValues === null) {
+            throw new Error('env.next_random called without a random source; set randomValues in the config');
+        }
+        const value = this.randomValues[this.randomIndex];
+        this.randomIndex = (this.randomIndex + 1) % this.randomValues.length;
+        return value;
+    }
+
+    /**
+     * Whether the engine supports simd128, probed by validating a minimal
+     * module whose only function uses a SIMD instruction
+     * @returns {boolean}
+     */
+    hostHasSimd() {
+        if (this._hostHasSimd === undefined) {
+            // (func (result v128) i32.const 0 i8x16.splat i8x16.popcnt)
+            const probe = new Uint8Array([
+                0, 97, 115, 109, 1, 0, 0, 0, 1, 5, 1, 96, 0, 1, 123, 3, 2, 1, 0, 10, 10, 1, 8, 0, 65, 0, 253, 15, 253,
+                98, 11
+            ]);
+            this._hostHasSimd = WebAssembly.validate(probe);
+        }
+        return this._hostHasSimd;
+    }
+
+    /**
+     * Whether the module was built for simd128, from its has_simd export
+     * @param {WebAssembly.Instance} instance
+     * @returns {boolean} False for modules without the export
+     */
+    readHasSimd(instance) {
+        const { has_simd: hasSimd } = instance?.exports ?? {};
+        return typeof hasSimd === 'function' && hasSimd() !== 0;
+    }
+
+    /**
+     * Whether this page can run wasm threads: shared memory needs a
+     * cross-origin isolated page (COOP/COEP headers)
+     * @returns {boolean}
+     */
+    hostHasThreads() {
+        return globalThis.crossOriginIsolated === true && typeof SharedArrayBuffer === 'function';
+    }
+
+    /**
+     * Agree on a thread count with the module. Both the page and the module
+     * must support threads; otherwise the module is told to run serially, so
+     * the fallback does not depend on which side lacked them.
+     * @param {WebAssembly.Instance} instance
+     * @param {number} requested - Threads wanted by the config
+     * @returns {number} Threads the module's runs will use (1 if it has no has_threads export)
+     */
+    negotiateThreads(instance, requested) {
+        const { has_threads: hasThreads, set_thread_count: setThreadCount } = instance?.exports ?? {};
+        if (typeof hasThreads !== 'function' || typeof setThreadCount !== 'function') {
+            return 1;
+        }
+        const wanted = this.hostHasThreads() && hasThreads() !== 0 ? Math.max(1, Math.floor(requested)) : 1;
+        return setThreadCount(wanted);
+    }
+
+    /**
+     * Turn stage checkpoint hashing on or off through set_checkpoints. Stage
+     * hashing costs time, so it is meant for diagnosing hash mismatches only.
+     * @param {WebAssembly.Instance} instance
+     * @param {boolean} enabled
+     * @returns {boolean} Whether the module supports checkpoints
+     */
+    setCheckpoints(instance, enabled) {
+        if (!instance || typeof instance.exports.set_checkpoints !== 'function') {
+            return false;
+        }
+        instance.exports.set_checkpoints(enabled ? 1 : 0);
+        return true;
+    }
+
+    /**
+     * Read the stage hashes the last run recorded at get_checkpoints, named by
+     * the stages listed in get_task_info
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} Map of stage name to u32 hash for the recorded stages,
+     *     or null if not exported or nothing was recorded
+     */
+    readCheckpoints(instance) {
+        if (typeof instance.exports.get_checkpoints !== 'function') {
+            return null;
+        }
+
+        const ptr = instance.exports.get_checkpoints();
+        const view = new DataView(instance.exports.memory.buffer);
+        // {u32 count, u32 recorded mask, u32 hashes[8]}
+        const count = view.getUint32(ptr, true);
+        const recorded = view.getUint32(ptr + 4, true);
+        if (recorded === 0) {
+            return null;
+        }
+
+        const stages = this.readTaskInfo(instance)?.stages ?? [];
+        const hashes = {};
+        for (let stage = 0; stage < count; stage++) {
+            if (recorded & (1 << stage)) {
+                hashes[stages[stage] ?? `stage${stage}`] = view.getUint32(ptr + 8 + stage * 4, true);
+            }
+        }
+        return hashes;
+    }
+
+    /**
+     * Read the result block a task publishes through get_result_ptr after every run
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} Status, hashes, in-module duration and work metrics of the last run,
+     *     or null if not exported or no run has completed
+     */
+    readResult(instance) {
+        if (typeof instance.exports.get_result_ptr !== 'function') {
+            return null;
+        }
+
+        const ptr = instance.exports.get_result_ptr();
+        const view = new DataView(instance.exports.memory.buffer);
+        // "WMBR"; zero until the first run
+        if (view.getUint32(ptr, true) !== 0x52424d57) {
+            return null;
+        }
+
+        const hasHash64 = (view.getUint32(ptr + 12, true) & 1) !== 0;
+        return {
+            status: view.getUint32(ptr + 4, true),
+            hash: view.getUint32(ptr + 8, true),
+            hash64: hasHash64 ? view.getBigUint64(ptr + 16, true) : null,
+            elapsedMs: view.getFloat64(ptr + 24, true),
+            elementsProcessed: Number(view.getBigUint64(ptr + 32, true)),
+            bytesTouched: Number(view.getBigUint64(ptr + 40, true))
+        };
+    }
+
+    /**
+     * Read the diagnostic message left by the last failed run_task call
+     * @param {WebAssembly.Instance} instance
+     * @returns {string|null} Error message, or null if none was recorded or not exported
+     */
+    readLastError(instance) {
+        const { get_last_error_ptr: getPtr, get_last_error_len: getLen } = instance.exports;
+        if (typeof getPtr !== 'function' || typeof getLen !== 'function') {
+            return null;
+        }
+
+        const length = getLen();
+        if (length === 0) {
+            return null;
+        }
+        return new TextDecoder().decode(this.readDataFromMemory(instance, getPtr(), length));
+    }
+
+    /**
+     * Read the shared code of the module's last parameter rejection
+     * @param {WebAssembly.Instance} instance
+     * @returns {string|null} Error code name, or null if none was recorded or not exported
+     */
+    readErrorCode(instance) {
+        const { get_error_code: getCode } = instance.exports;
+        if (typeof getCode !== 'function') {
+            return null;
+        }
+
+        const code = getCode();
+        if (code === 0) {
+            return null;
+        }
+        return this.PARAM_ERROR_NAMES[code] ?? `error_${code}`;
+    }
+
+    /**
+     * Read the panic message recorded by a task's last run_task call
+     * @param {WebAssembly.Instance} instance
+     * @returns {string|null} Panic message, or null if none was recorded or the exports are missing
+     */
+    readPanicMessage(instance) {
+        const { get_panic_ptr: getPtr, get_panic_len: getLen } = instance.exports;
+        if (typeof getPtr !== 'function' || typeof getLen !== 'function') {
+            return null;
+        }
+
+        const length = getLen();
+        if (length === 0) {
+            return null;
+        }
+        return new TextDecoder().decode(this.readDataFromMemory(instance, getPtr(), length));
+    }
+
+    /**
+     * Read the parameter limits published by a task's get_limits export
+     * @param {WebAssembly.Instance} instance
+     * @returns {Object|null} Common limits plus the task-specific tail, or null if not exported
+     */
+    readTaskLimits(instance) {
+        if (typeof instance.exports.get_limits !== 'function') {
+            return null;
+        }
+
+        const ptr = instance.exports.get_limits();
+        const view = new DataView(instance.exports.memory.buffer);
+        const wordCount = view.getUint32(ptr, true);
+        const words = [];
+        for (let i = 1; i <= wordCount; i++) {
+            words.push(view.getUint32(ptr + i * 4, true));
+        }
+
+        const [
+            maxAllocationSize,
+            maxWarmupIterations,
+            maxScale,
+            maxProfile,
+            maxVerification,
+            maxAllocator,
+            maxHashAlgorithm,
+            maxGenerator,
+            ...taskLimits
+        ] = words;
+        return {
+            maxAllocationSize,
+            maxWarmupIterations,
+            maxScale,
+            maxProfile,
+            maxVerification,
+            maxAllocator,
+            maxHashAlgorithm,
+            maxGenerator,
+            taskLimits
+        };
+    }
+
     /**
      * Clear all loaded modules
      */
@@ -0,0 +1,151 @@
+#!/bin/bash
+
+# Standard Go WebAssembly Build Script
+# Builds the TinyGo task sources with the mainline Go compiler (GOOS=js GOARCH=wasm),
+# so TinyGo and gc-Go output can be compared for size and speed
+
+# Source common utilities
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+source "${SCRIPT_DIR}/common.sh"
+
+# Configuration
+TASKS_DIR="${PROJECT_ROOT}/tasks"
+GO_BUILDS_DIR="${BUILDS_DIR}/go"
+
+# The harness derives the file name suffix of languages without optimization levels as o2
+OPT_SUFFIX="o2"
+
+# Check if the Go toolchain is available
+check_go_toolchain() {
+    log_info "Checking Go toolchain..."
+
+    if ! command -v go &> /dev/null; then
+        log_error "go not found. Please install Go: https://golang.org/dl/"
+        exit 1
+    fi
+
+    log_success "Go toolchain ready"
+    go version
+}
+
+# Copy the JS runtime glue matching the compiler that built the modules
+copy_wasm_exec() {
+    local goroot
+    goroot="$(go env GOROOT)"
+
+    # Go 1.24 moved wasm_exec.js from misc/wasm to lib/wasm
+    local wasm_exec="${goroot}/lib/wasm/wasm_exec.js"
+    [[ -f "${wasm_exec}" ]] || wasm_exec="${goroot}/misc/wasm/wasm_exec.js"
+
+    if [[ ! -f "${wasm_exec}" ]]; then
+        log_error "wasm_exec.js not found in ${goroot}"
+        return 1
+    fi
+
+    cp "${wasm_exec}" "${GO_BUILDS_DIR}/wasm_exec.js"
+    log_info "Copied $(basename "${wasm_exec}") to ${GO_BUILDS_DIR}"
+}
+
+# Build a single task with the standard Go compiler
+build_go_task() {
+    local task_name="$1"
+    local task_dir="${TASKS_DIR}/${task_name}/tinygo"
+    local output_path="${GO_BUILDS_DIR}/${task_name}-${OPT_SUFFIX}.wasm"
+
+    if [[ ! -d "${task_dir}" ]]; then
+        log_error "Task directory not found: ${task_dir}"
+        return 1
+    fi
+
+    log_info "Building ${task_name}..."
+
+    # -s -w drops the symbol table and DWARF, the gc-Go counterpart of -no-debug
+    if ! (cd "${task_dir}" && GOOS=js GOARCH=wasm go build -trimpath -ldflags="-s -w" -o "${output_path}" .); then
+        log_error "Failed to build ${task_name}"
+        return 1
+    fi
+
+    local wasm_size=$(wc -c < "${output_path}")
+    gzip -c "${output_path}" > "${output_path}.gz"
+    local gzipped_size=$(wc -c < "${output_path}.gz")
+
+    log_success "Built ${task_name}: ${wasm_size} bytes (${gzipped_size} bytes gzipped)"
+    return 0
+}
+
+# Print usage information
+usage() {
+    cat << EOF
+Usage: $0 [OPTIONS] [TASK_NAME]
+
+Build the Go tasks with the standard Go compiler (GOOS=js GOARCH=wasm).
+Modules are written to builds/go together with the wasm_exec.js they run under.
+
+OPTIONS:
+    -h, --help          Show this help message
+
+TASK_NAME:
+    If specified, builds only the specified task (mandelbrot, json_parse, or matrix_mul)
+    If omitted, builds all tasks
+
+EXAMPLES:
+    $0                      # Build all tasks
+    $0 mandelbrot           # Build only mandelbrot task
+EOF
+}
+
+# Parse command line arguments
+parse_args() {
+    while [[ $# -gt 0 ]]; do
+        case $1 in
+            -h|--help)
+                usage
+                exit 0
+                ;;
+            mandelbrot|json_parse|matrix_mul)
+                SINGLE_TASK="$1"
+                shift
+                ;;
+            *)
+                log_error "Unknown argument: $1"
+                usage
+                exit 1
+                ;;
+        esac
+    done
+}
+
+# Main build function
+main() {
+    local tasks=("mandelbrot" "json_parse" "matrix_mul")
+    [[ -n "${SINGLE_TASK}" ]] && tasks=("${SINGLE_TASK}")
+
+    log_info "Starting standard Go WebAssembly build process..."
+    check_go_toolchain
+
+    mkdir -p "${GO_BUILDS_DIR}"
+    copy_wasm_exec || return 1
+
+    local failed_count=0
+    for task in "${tasks[@]}"; do
+        build_go_task "${task}" || failed_count=$((failed_count + 1))
+    done
+
+    local successful_count=$((${#tasks[@]} - failed_count))
+    log_info "Build Summary:"
+    log_success "Successfully built: ${successful_count}/${#tasks[@]} tasks"
+
+    if [[ ${failed_count} -gt 0 ]]; then
+        log_error "Failed builds: ${failed_count} tasks"
+        return 1
+    fi
+    log_success "🎉 All Go tasks built successfully!"
+    return 0
+}
+
+# Handle script arguments
+if [[ "${BASH_SOURCE[0]}" == "${0}" ]]; then
+    SINGLE_TASK=""
+    parse_args "$@"
+    main
+fi
@@ -362,6 +362,16 @@ build_sequential() {
     return "${#failed_tasks[@]}"
 }
 
+# Record the built modules of the tasks in the builds directory's manifest
+record_build_manifest() {
+    local files=()
+    for task in "$@"; do
+        [[ -f "${RUST_OUTPUT_DIR}/${task}-${OPT_SUFFIX}.wasm" ]] && files+=("${task}-${OPT_SUFFIX}.wasm")
+    done
+    [[ ${#files[@]} -gt 0 ]] || return 0
+    record_artifacts "${RUST_OUTPUT_DIR}" "--target ${WASM_TARGET} --${PROFILE} opt-level=${CARGO_PROFILE_RELEASE_OPT_LEVEL:-3} lto=${CARGO_PROFILE_RELEASE_LTO:-fat} codegen-units=${CARGO_PROFILE_RELEASE_CODEGEN_UNITS:-1} panic=${CARGO_PROFILE_RELEASE_PANIC:-abort}" "${files[@]}"
+}
+
 # Aggregate build metrics from temporary files into unified JSON
 aggregate_build_metrics() {
     local timestamp=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
@@ -503,6 +513,7 @@ main() {
 
     # Aggregate metrics
     aggregate_build_metrics
+    record_build_manifest "${tasks[@]}"
 
     # Report results
     local successful_count=$((${#tasks[@]} - failed_count))
@@ -621,6 +632,7 @@ if [[ "${BASH_SOURCE[0]}" == "${0}" ]]; then
 
         if build_rust_task_with_metrics "${SINGLE_TASK}"; then
             aggregate_build_metrics
+            record_build_manifest "${SINGLE_TASK}"
             log_success "🎉 Task ${SINGLE_TASK} built successfully!"
             exit 0
         else
@@ -19,6 +19,11 @@ TINYGO_BUILD_FLAGS=()
 # Build options
 PARALLEL_BUILD=false
 GENERATE_CHECKSUMS=true
+DEBUG_LOG=false
+RECOVER_PANICS=false
+WASI_BUILD=false
+SIMD_BUILD=false
+GC_MODE=""
 BUILD_METRICS_FILE="${BUILDS_DIR}/metrics.json"
 CHECKSUM_FILE="${TINYGO_BUILDS_DIR}/checksums.txt"
 
@@ -64,9 +69,49 @@ read_tinygo_config() {
 
     [[ ${#TINYGO_BUILD_FLAGS[@]} -eq 0 ]] && TINYGO_BUILD_FLAGS=("-opt=2" "-panic=trap" "-no-debug" "-scheduler=none" "-gc=conservative")
 
+    # WASI command modules sit next to the browser builds under their own suffix
+    if [[ "${WASI_BUILD}" == true ]]; then
+        WASM_TARGET="wasip1"
+        OPT_SUFFIX="${OPT_SUFFIX}-wasi"
+    fi
+
+    # GC-mode variants replace the configured -gc flag and get a -gc<mode> suffix
+    if [[ -n "${GC_MODE}" ]]; then
+        local flags=()
+        for flag in "${TINYGO_BUILD_FLAGS[@]}"; do
+            [[ "${flag}" == -gc=* ]] || flags+=("${flag}")
+        done
+        TINYGO_BUILD_FLAGS=("${flags[@]}" "-gc=${GC_MODE}")
+        OPT_SUFFIX="${OPT_SUFFIX}-gc${GC_MODE}"
+    fi
+
+    # SIMD variants build for a derived target with simd128 and the simd128 build tag
+    if [[ "${SIMD_BUILD}" == true ]]; then
+        WASM_TARGET="$(write_simd_target "${WASM_TARGET}")"
+        OPT_SUFFIX="${OPT_SUFFIX}-simd"
+    fi
+
     log_info "TinyGo: target=${WASM_TARGET}, flags=${TINYGO_BUILD_FLAGS[*]}, suffix=${OPT_SUFFIX}"
 }
 
+# Write a TinyGo target file extending the base target with simd128 and
+# print its path. The features replace the base target's, so they repeat
+# TinyGo's defaults for wasm.
+write_simd_target() {
+    local base_target="$1"
+    local target_file="${TINYGO_BUILDS_DIR}/.${base_target}-simd.json"
+
+    mkdir -p "${TINYGO_BUILDS_DIR}"
+    cat > "${target_file}" << EOF
+{
+    "inherits": ["${base_target}"],
+    "features": "+bulk-memory,+mutable-globals,+nontrapping-fptoint,+sign-ext,+simd128",
+    "build-tags": ["simd128"]
+}
+EOF
+    echo "${target_file}"
+}
+
 # Build a single TinyGo task
 build_tinygo_task() {
     local task_name="$1"
@@ -97,6 +142,16 @@ build_tinygo_task() {
         "-target=${WASM_TARGET}"
         "${TINYGO_BUILD_FLAGS[@]}"
     )
+
+    # Debug builds route common.Log through the env.log import
+    if [[ "${DEBUG_LOG}" == true ]]; then
+        build_flags+=("-tags=debuglog")
+    fi
+
+    # -panic=trap aborts before deferred calls run, so recovery needs -panic=print
+    if [[ "${RECOVER_PANICS}" == true ]]; then
+        build_flags=("${build_flags[@]/-panic=trap/-panic=print}")
+    fi
     
     if ! tinygo build "${build_flags[@]}" -o "${output_path}" .; then
         log_error "Failed to build ${task_name}"
@@ -119,7 +174,9 @@ build_tinygo_task() {
     # Optimize with wasm-opt (enable bulk memory operations)
     if command -v wasm-opt &> /dev/null; then
         local temp_wasm="${output_path}.tmp"
-        wasm-opt -Oz --enable-bulk-memory --enable-nontrapping-float-to-int --enable-sign-ext "${output_path}" -o "${temp_wasm}"
+        local opt_features=(--enable-bulk-memory --enable-nontrapping-float-to-int --enable-sign-ext)
+        [[ "${SIMD_BUILD}" == true ]] && opt_features+=(--enable-simd)
+        wasm-opt -Oz "${opt_features[@]}" "${output_path}" -o "${temp_wasm}"
         if mv "${temp_wasm}" "${output_path}" 2>/dev/null; then
             local optimized_size=$(wc -c < "${output_path}")
             log_info "Optimized size: ${optimized_size} bytes"
@@ -317,6 +374,21 @@ build_sequential() {
     return "${#failed_tasks[@]}"
 }
 
+# Record the built modules of the tasks in the builds directory's manifest
+record_build_manifest() {
+    local files=()
+    for task in "$@"; do
+        [[ -f "${TINYGO_BUILDS_DIR}/${task}-${OPT_SUFFIX}.wasm" ]] && files+=("${task}-${OPT_SUFFIX}.wasm")
+    done
+    [[ ${#files[@]} -gt 0 ]] || return 0
+
+    # The flags build_tinygo_task passed
+    local build_flags=("-target=${WASM_TARGET}" "${TINYGO_BUILD_FLAGS[@]}")
+    [[ "${DEBUG_LOG}" == true ]] && build_flags+=("-tags=debuglog")
+    [[ "${RECOVER_PANICS}" == true ]] && build_flags=("${build_flags[@]/-panic=trap/-panic=print}")
+    record_artifacts "${TINYGO_BUILDS_DIR}" "${build_flags[*]}" "${files[@]}"
+}
+
 # Aggregate build metrics from temporary files into unified JSON
 aggregate_build_metrics() {
     local timestamp=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
@@ -458,6 +530,7 @@ main() {
 
     # Aggregate metrics
     aggregate_build_metrics
+    record_build_manifest "${tasks[@]}"
 
     # Report results
     local successful_count=$((${#tasks[@]} - failed_count))
@@ -488,6 +561,11 @@ OPTIONS:
     -s, --sequential    Build tasks sequentially (default)
     -c, --checksums     Generate checksums (default: enabled)
     --no-checksums      Disable checksum generation
+    --debug-log         Enable env.log debug logging (not for benchmark runs)
+    --recover-panics    Build with -panic=print so run_task reports panics via get_panic_ptr
+    --wasi              Build WASI command modules (JSON params on stdin) for wasmtime/wasmer
+    --gc MODE           Build with -gc=MODE (conservative, precise or leaking) as a -gcMODE variant
+    --simd              Build simd128 variants (*-simd.wasm) for runtimes with SIMD support
     -h, --help          Show this help message
 
 TASK_NAME:
@@ -499,6 +577,9 @@ EXAMPLES:
     $0 --parallel           # Build all tasks in parallel
     $0 mandelbrot           # Build only mandelbrot task
     $0 -p --no-checksums    # Parallel build without checksums
+    $0 --wasi mandelbrot    # Build mandelbrot-o2-wasi.wasm for wasmtime
+    $0 --gc leaking         # Build GC-off variants (*-o2-gcleaking.wasm)
+    $0 --simd               # Build SIMD variants (*-o2-simd.wasm)
 EOF
 }
 
@@ -522,6 +603,35 @@ parse_args() {
                 GENERATE_CHECKSUMS=false
                 shift
                 ;;
+            --debug-log)
+                DEBUG_LOG=true
+                shift
+                ;;
+            --recover-panics)
+                RECOVER_PANICS=true
+                shift
+                ;;
+            --wasi)
+                WASI_BUILD=true
+                shift
+                ;;
+            --simd)
+                SIMD_BUILD=true
+                shift
+                ;;
+            --gc)
+                case "$2" in
+                    conservative|precise|leaking)
+                        GC_MODE="$2"
+                        shift 2
+                        ;;
+                    *)
+                        log_error "Unknown GC mode: $2 (expected conservative, precise or leaking)"
+                        usage
+                        exit 1
+                        ;;
+                esac
+                ;;
             -h|--help)
                 usage
                 exit 0
@@ -576,6 +686,7 @@ if [[ "${BASH_SOURCE[0]}" == "${0}" ]]; then
 
         if build_tinygo_task_with_metrics "${SINGLE_TASK}"; then
             aggregate_build_metrics
+            record_build_manifest "${SINGLE_TASK}"
             log_success "🎉 Task ${SINGLE_TASK} built successfully!"
             exit 0
         else
@@ -109,6 +109,25 @@ cleanup() {
 # Set up cleanup trap
 trap cleanup EXIT
 
+# Record built artifacts in the manifest.json of their directory with
+# cmd/build -index, so cmd/bench refuses copies that no longer match them
+# Usage: record_artifacts <dir> <build args> <file.wasm>...
+record_artifacts() {
+    local dir="$1"
+    local args="$2"
+    shift 2
+
+    if ! command -v go &> /dev/null; then
+        log_warning "go not found; ${dir}/manifest.json not updated, so cmd/bench may refuse the new builds"
+        return 0
+    fi
+    if (cd "${PROJECT_ROOT}/cmd/build" && go run . -index -out "${dir}" -args "${args}" "$@"); then
+        log_info "Recorded $# artifact(s) in ${dir}/manifest.json"
+    else
+        log_warning "Failed to update ${dir}/manifest.json, so cmd/bench may refuse the new builds"
+    fi
+}
+
 # Validation-specific functions
 cd_to_root() {
     cd "${PROJECT_ROOT}"
@@ -283,7 +302,8 @@ run_cross_implementation_test() {
     
     # Run cross-implementation test using TinyGo compiler
     # This ensures the same compiler optimizations and behavior as the WASM build
-    # Note: TinyGo test runs all tests in the package (no -run filter support)
+    # Note: TinyGo test runs all tests in the package (no -run filter support);
+    # the tests live in the task package below the module's main package
     log_step "Testing TinyGo implementation against Rust reference hashes..."
     
     local test_output
@@ -291,7 +311,7 @@ run_cross_implementation_test() {
     
     # Capture both stdout and stderr for detailed error reporting
     # TinyGo test syntax: tinygo test [options]
-    if test_output=$(tinygo test 2>&1); then
+    if test_output=$(tinygo test ./... 2>&1); then
         test_exit_code=0
     else
         test_exit_code=$?
@@ -303,34 +323,15 @@ run_cross_implementation_test() {
         add_validation_result "$task" "PASS" "implementations match exactly"
         return 0
     else
-        # Special handling for matrix_mul partial compatibility
-        if [[ "$task" == "matrix_mul" ]]; then
-            # Check if small matrices passed (critical functionality)
-            if echo "$test_output" | grep -q "small_2x2.*MATCH" && \
-               echo "$test_output" | grep -q "small_3x3.*MATCH" && \
-               echo "$test_output" | grep -q "small_4x4.*MATCH"; then
-                
-                # Extract pass/fail statistics
-                local stats=$(echo "$test_output" | grep -E "Passed:.*[0-9]+/[0-9]+")
-                
-                log_warning "⚠️  Partial compatibility detected for $task"
-                log_info "Test results: $stats"
-                log_info "✅ Small matrices (2x2, 3x3, 4x4) match correctly"
-                log_info "❌ Larger matrices show floating-point precision differences"
-                log_info "This is expected due to accumulated rounding errors in FP arithmetic"
-                log_success "✅ Core functionality verified"
-                add_validation_result "$task" "PARTIAL" "small matrices match, larger matrices differ (expected)"
-                return 0
-            fi
-        fi
-        
-        # Genuine test failure
+        # Every task is judged by the same conformance policy, which already
+        # passes float rounding within a task's tolerance
         log_error "❌ Cross-implementation validation failed for $task"
         log_error "Exit code: $test_exit_code"
         
         # Extract failure summary from test output
         if echo "$test_output" | grep -q "CROSS-IMPLEMENTATION VALIDATION FAILED"; then
-            local failure_info=$(echo "$test_output" | grep -A2 "CROSS-IMPLEMENTATION VALIDATION FAILED")
+            # The summary follows the first failing vectors the suite lists
+            local failure_info=$(echo "$test_output" | grep -B11 "CROSS-IMPLEMENTATION VALIDATION FAILED")
             log_error "Test failure details:"
             echo "$failure_info" | while IFS= read -r line; do
                 log_error "  $line"
@@ -229,6 +229,23 @@ app.get('/configs/bench-quick.json', (req, res) => {
     }
 });
 
+// Serve the task manifest (params layouts and defaults, written by cmd/gentasks)
+app.get('/configs/tasks.json', (req, res) => {
+    const manifestPath = path.join(__dirname, '../configs/tasks.json');
+
+    if (fs.existsSync(manifestPath)) {
+        res.setHeader('Content-Type', 'application/json');
+        res.sendFile(manifestPath);
+    } else {
+        writeLog(`ERROR: tasks.json not found at: ${manifestPath}`);
+        res.status(404).json({
+            error: 'Task manifest not found',
+            message: 'tasks.json not found. Run "go run ." in cmd/gentasks to generate it.',
+            path: '/configs/tasks.json'
+        });
+    }
+});
+
 // Serve individual files from harness/web at root level for convenience
 const webAssets = ['bench.js', 'wasm_loader.js', 'config_loader.js'];
 webAssets.forEach(asset => {
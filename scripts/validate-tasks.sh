@@ -26,7 +26,7 @@ readonly SCRIPT_NAME="validate-tasks.sh"
 readonly TASK_INFO=(
     "mandelbrot:FULL:Mandelbrot set visualization with complex number arithmetic"
     "json_parse:FULL:JSON parsing and serialization with nested object handling"
-    "matrix_mul:PARTIAL:Matrix multiplication with floating-point arithmetic precision differences"
+    "matrix_mul:FULL:Matrix multiplication, float rounding compared within a tolerance"
 )
 
 # Available tasks array (for compatibility)
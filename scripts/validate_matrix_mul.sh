@@ -26,8 +26,6 @@ validate_matrix_mul() {
         return 0
     else
         log_error "❌ Matrix Multiplication cross-implementation validation failed"
-        # Note: This task has partial compatibility (small matrices work, larger ones differ)
-        log_warning "⚠️  Partial compatibility detected - small matrices (≤4x4) match exactly"
         return 1
     fi
 }
@@ -0,0 +1,8 @@
+//go:build allocstats
+
+package common
+
+// AllocStats reports an instrumented build (build tag allocstats), which
+// counts the allocations and GC cycles of each run's measured section for
+// get_memory_stats
+const AllocStats = true
@@ -0,0 +1,113 @@
+package common
+
+import "unsafe"
+
+// Scratch allocators selectable through a task's Allocator parameter
+const (
+	AllocatorHeap  uint32 = iota // Ordinary GC-managed allocations (default)
+	AllocatorArena               // Bump allocations from a reusable Arena
+)
+
+// ScratchAllocator returns the allocator a run should take its scratch
+// buffers from. Leaking-GC builds and the reserved memory mode always use the
+// arena: it is reused from run to run, while heap buffers would pile up with
+// every run.
+func ScratchAllocator(requested uint32) uint32 {
+	if LeakingGC || Reserved() {
+		return AllocatorArena
+	}
+	return requested
+}
+
+const (
+	arenaAlign   = 8
+	arenaMinSize = 64 * 1024
+)
+
+// Arena is a bump allocator for pointer-free scratch buffers. Allocations are
+// carved from one backing buffer and released together by Reset, so once the
+// arena has grown to fit a workload, repeating it performs no GC allocations.
+//
+// The backing buffer is not scanned for pointers: only store plain numeric
+// data in arena memory.
+type Arena struct {
+	buf    []byte
+	offset int
+}
+
+// alloc returns size zeroed bytes aligned to arenaAlign. When the backing
+// buffer is exhausted the arena moves to a larger one; earlier allocations
+// keep the old buffer alive until they are dropped.
+func (a *Arena) alloc(size int) []byte {
+	start := (a.offset + arenaAlign - 1) &^ (arenaAlign - 1)
+	end := start + size
+	if end > len(a.buf) {
+		a.buf = make([]byte, max(2*len(a.buf), size, arenaMinSize))
+		start, end = 0, size
+	}
+
+	a.offset = end
+	b := a.buf[start:end:end]
+	clear(b)
+	return b
+}
+
+// Bytes allocates n zeroed bytes
+func (a *Arena) Bytes(n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	return a.alloc(n)
+}
+
+// Uint32s allocates n zeroed uint32 values
+func (a *Arena) Uint32s(n int) []uint32 {
+	if n <= 0 {
+		return nil
+	}
+	b := a.alloc(n * 4)
+	return unsafe.Slice((*uint32)(unsafe.Pointer(&b[0])), n)
+}
+
+// Float32s allocates n zeroed float32 values
+func (a *Arena) Float32s(n int) []float32 {
+	if n <= 0 {
+		return nil
+	}
+	b := a.alloc(n * 4)
+	return unsafe.Slice((*float32)(unsafe.Pointer(&b[0])), n)
+}
+
+// Float64s allocates n zeroed float64 values
+func (a *Arena) Float64s(n int) []float64 {
+	if n <= 0 {
+		return nil
+	}
+	b := a.alloc(n * 8)
+	return unsafe.Slice((*float64)(unsafe.Pointer(&b[0])), n)
+}
+
+// Reserve moves the arena to a backing buffer of at least n bytes now, so a
+// workload that fits never makes it grow later. Earlier allocations are
+// released.
+func (a *Arena) Reserve(n int) {
+	if n > len(a.buf) {
+		a.buf = make([]byte, n)
+	}
+	a.offset = 0
+}
+
+// Reset releases every allocation at once, keeping the backing buffer for reuse
+func (a *Arena) Reset() {
+	a.offset = 0
+}
+
+// Used reports the bytes handed out since the last Reset
+func (a *Arena) Used() int {
+	return a.offset
+}
+
+// Capacity reports the size of the current backing buffer
+func (a *Arena) Capacity() int {
+	return len(a.buf)
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestArenaAllocationsAreZeroedAndAligned(t *testing.T) {
+	var arena Arena
+
+	bytes := arena.Bytes(3)
+	for i := range bytes {
+		bytes[i] = 0xFF
+	}
+
+	values := arena.Uint32s(10)
+	if len(values) != 10 {
+		t.Fatalf("Expected 10 values, got %d", len(values))
+	}
+	if uintptr(unsafe.Pointer(&values[0]))%arenaAlign != 0 {
+		t.Error("Allocations should be aligned")
+	}
+	for i, v := range values {
+		if v != 0 {
+			t.Errorf("Value %d should be zeroed, got %d", i, v)
+		}
+	}
+
+	wide := arena.Float64s(3)
+	if len(wide) != 3 || uintptr(unsafe.Pointer(&wide[0]))%arenaAlign != 0 {
+		t.Error("Float64 allocations should be aligned")
+	}
+
+	if arena.Float32s(0) != nil || arena.Float64s(0) != nil || arena.Bytes(-1) != nil {
+		t.Error("Empty allocations should return nil")
+	}
+}
+
+func TestArenaResetReusesBuffer(t *testing.T) {
+	var arena Arena
+
+	first := arena.Float32s(100)
+	first[0] = 1.5
+	capacity := arena.Capacity()
+
+	arena.Reset()
+	if arena.Used() != 0 {
+		t.Errorf("Reset should rewind the arena, %d bytes still used", arena.Used())
+	}
+
+	second := arena.Float32s(100)
+	if &first[0] != &second[0] {
+		t.Error("Allocations after Reset should reuse the backing buffer")
+	}
+	if second[0] != 0 {
+		t.Error("Reused memory should be zeroed")
+	}
+	if arena.Capacity() != capacity {
+		t.Error("Reset should keep the backing buffer")
+	}
+}
+
+func TestArenaGrowsPastCapacity(t *testing.T) {
+	var arena Arena
+
+	small := arena.Uint32s(4)
+	small[0] = 42
+
+	large := arena.Uint32s(arenaMinSize) // 4× the initial buffer
+	if len(large) != arenaMinSize || arena.Capacity() < arenaMinSize*4 {
+		t.Fatalf("Arena should grow to fit, capacity %d", arena.Capacity())
+	}
+	if small[0] != 42 {
+		t.Error("Growing should not disturb earlier allocations")
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		arena.Reset()
+		arena.Uint32s(arenaMinSize)
+	})
+	if allocs != 0 {
+		t.Errorf("A workload that fits should not allocate, got %.1f allocations", allocs)
+	}
+}
+
+func TestScratchAllocator(t *testing.T) {
+	for _, requested := range []uint32{AllocatorHeap, AllocatorArena} {
+		want := requested
+		if LeakingGC {
+			want = AllocatorArena
+		}
+		if got := ScratchAllocator(requested); got != want {
+			t.Errorf("ScratchAllocator(%d) = %d, want %d", requested, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package common
+
+import "runtime"
+
+// Memory budget. A wasm32 module can grow its linear memory to 4GiB, and a
+// memory.grow the engine refuses traps in the middle of a benchmark. The host
+// sets a budget in 64KiB pages with set_memory_budget, and tasks check a
+// run's working set against it before the run starts, rejecting the params
+// with StatusOverflow instead of trapping.
+
+// WasmPageSize is the size of one page of wasm linear memory
+const WasmPageSize = 65536
+
+// MaxWasmPages is the most pages a wasm32 linear memory can hold (4GiB)
+const MaxWasmPages uint32 = 65536
+
+// Memory64 reports whether this build addresses a 64-bit linear memory.
+// TinyGo only targets wasm32, so every current build is limited to 4GiB and
+// u64 sizes beyond it are rejected by Validator.Addressable.
+const Memory64 = false
+
+// MaxLinearMemoryBytes is the most bytes the module's linear memory can hold
+const MaxLinearMemoryBytes = uint64(MaxWasmPages) * WasmPageSize
+
+// memoryBudgetPages caps the module's memory in pages, 0 for no budget
+var memoryBudgetPages uint32
+
+// SetMemoryBudget caps the module's memory at pages, clamped to MaxWasmPages;
+// 0 removes the budget
+func SetMemoryBudget(pages uint32) {
+	memoryBudgetPages = min(pages, MaxWasmPages)
+}
+
+// MaxMemoryPages returns the pages the module may grow its memory to: the
+// budget when one is set, MaxWasmPages otherwise
+func MaxMemoryPages() uint32 {
+	if memoryBudgetPages == 0 {
+		return MaxWasmPages
+	}
+	return memoryBudgetPages
+}
+
+// FitsMemoryBudget reports whether bytes more can be allocated without the
+// module's memory growing past the budget. Memory the heap already holds but
+// does not use counts as free, so the check errs towards rejecting.
+func FitsMemoryBudget(bytes int) bool {
+	if memoryBudgetPages == 0 {
+		return true
+	}
+	return memoryInUse()+uint64(bytes) <= uint64(memoryBudgetPages)*WasmPageSize
+}
+
+// memoryInUse returns the bytes the runtime holds minus the heap it holds free
+func memoryInUse() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Sys - m.HeapIdle
+}
+
+// CheckMemoryBudget returns StatusOverflow when a run would grow memory past
+// the budget. scratch is the run's arena working set and heap the bytes it
+// allocates on the GC heap whatever the allocator. Reserved runs take their
+// scratch from the arena ReserveArena already sized, so only heap counts.
+func CheckMemoryBudget(scratch, heap int) (uint32, string) {
+	if reserved {
+		scratch = 0
+	}
+	if FitsMemoryBudget(scratch + heap) {
+		return StatusOK, ""
+	}
+	return Reject(ErrTooLarge, "working set exceeds the memory budget set by set_memory_budget")
+}
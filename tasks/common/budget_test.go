@@ -0,0 +1,53 @@
+package common
+
+import "testing"
+
+func TestMemoryBudget(t *testing.T) {
+	defer SetMemoryBudget(0)
+	defer ReleaseReservation()
+
+	SetMemoryBudget(0)
+	if MaxMemoryPages() != MaxWasmPages {
+		t.Errorf("Without a budget the limit should be %d pages, got %d", MaxWasmPages, MaxMemoryPages())
+	}
+	if status, _ := CheckMemoryBudget(1<<30, 0); status != StatusOK {
+		t.Error("Without a budget every working set should fit")
+	}
+
+	SetMemoryBudget(MaxWasmPages + 1)
+	if MaxMemoryPages() != MaxWasmPages {
+		t.Errorf("A budget should be clamped to %d pages, got %d", MaxWasmPages, MaxMemoryPages())
+	}
+
+	// One page cannot hold the module's own memory, let alone a working set
+	SetMemoryBudget(1)
+	if MaxMemoryPages() != 1 {
+		t.Errorf("Expected a 1 page limit, got %d", MaxMemoryPages())
+	}
+	status, message := CheckMemoryBudget(WasmPageSize, 0)
+	if status != StatusOverflow || ErrorCode() != ErrTooLarge || message == "" {
+		t.Errorf("An over-budget working set should overflow, got status %d code %d", status, ErrorCode())
+	}
+	if ptr := Alloc(WasmPageSize); ptr != 0 {
+		Free(ptr)
+		t.Error("Alloc should refuse buffers past the budget")
+	}
+
+	// Reserved runs draw their scratch from memory already sized, so a budget
+	// a few pages above the memory in use still fits a large scratch set
+	var arena Arena
+	ReserveArena(&arena, 64)
+	SetMemoryBudget(uint32(memoryInUse()/WasmPageSize) + 4)
+	if status, _ := CheckMemoryBudget(1<<20, 0); status != StatusOK {
+		t.Error("Reserved working sets should not be checked against the budget")
+	}
+	if status, _ := CheckMemoryBudget(0, 1<<20); status != StatusOverflow {
+		t.Error("Heap bytes of a reserved run should still be checked against the budget")
+	}
+	ReleaseReservation()
+
+	SetMemoryBudget(MaxWasmPages)
+	if status, _ := CheckMemoryBudget(1<<20, 1<<20); status != StatusOK {
+		t.Error("A working set well inside the budget should fit")
+	}
+}
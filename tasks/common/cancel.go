@@ -0,0 +1,34 @@
+package common
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// cancelFlag is the u32 at get_cancel_ptr. The host stores a nonzero value to
+// stop the current run: from env.report_progress, or from another thread when
+// the memory is shared. Tasks poll it at loop boundaries and fail the run with
+// StatusCancelled, leaving the instance usable for the next run.
+var cancelFlag uint32
+
+// CancelPtr returns the address of the cancellation flag in linear memory
+func CancelPtr() uintptr {
+	return uintptr(unsafe.Pointer(&cancelFlag))
+}
+
+// Cancelled reports whether the host has asked the current run to stop. The
+// atomic load keeps the poll from being hoisted out of the loop it guards.
+func Cancelled() bool {
+	return atomic.LoadUint32(&cancelFlag) != 0
+}
+
+// ClearCancel lowers the flag at the start of a run, so a request aimed at
+// one run cannot cancel the next
+func ClearCancel() {
+	atomic.StoreUint32(&cancelFlag, 0)
+}
+
+// RequestCancel raises the flag as a host would, for native callers and tests
+func RequestCancel() {
+	atomic.StoreUint32(&cancelFlag, 1)
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestCancelFlag(t *testing.T) {
+	defer ClearCancel()
+
+	ClearCancel()
+	if Cancelled() {
+		t.Fatal("A cleared flag should not report cancellation")
+	}
+
+	// The host cancels by storing to the exported address
+	PutUint32LE(Memory(unsafe.Pointer(&cancelFlag), 4), 1)
+	if !Cancelled() || CancelPtr() != uintptr(unsafe.Pointer(&cancelFlag)) {
+		t.Error("A store at CancelPtr should cancel the run")
+	}
+
+	// Advance polls the flag whether or not a report is due
+	progress := NewProgress(1)
+	if progress.Advance(1) {
+		t.Error("Advance should report cancellation")
+	}
+	ClearCancel()
+	if !progress.Advance(1) {
+		t.Error("Advance should continue once the flag is cleared")
+	}
+
+	RequestCancel()
+	if !Cancelled() {
+		t.Error("RequestCancel should raise the flag")
+	}
+}
@@ -0,0 +1,90 @@
+package common
+
+import "unsafe"
+
+// MaxCheckpoints bounds the stages a task can hash
+const MaxCheckpoints = 8
+
+// StageInput is the first stage of every task: the hash of the generated input
+const StageInput uint32 = 0
+
+// Checkpoints holds the stage hashes of the last run, published through
+// get_checkpoints: the stage count, a bitmask of the stages the run reached,
+// then one FNV-1a hash per stage in the order get_task_info lists them. When
+// two implementations' final hashes differ, the first stage whose hashes
+// differ is where they diverged.
+type Checkpoints struct {
+	Count    uint32
+	Recorded uint32
+	Hashes   [MaxCheckpoints]uint32
+}
+
+// Stage hashing costs a pass over each stage's data, so it is off unless the
+// host asks for it with set_checkpoints
+var (
+	checkpoints        Checkpoints
+	checkpointsEnabled bool
+)
+
+// EnableCheckpoints turns stage hashing on or off for the following runs
+func EnableCheckpoints(enabled bool) {
+	checkpointsEnabled = enabled
+}
+
+// CheckpointsEnabled reports whether runs should record stage hashes; tasks
+// test it before hashing a stage
+func CheckpointsEnabled() bool {
+	return checkpointsEnabled
+}
+
+// ResetCheckpoints clears the stage hashes at the start of a run of a task
+// with count stages
+func ResetCheckpoints(count uint32) {
+	checkpoints = Checkpoints{Count: min(count, MaxCheckpoints)}
+}
+
+// RecordCheckpoint stores the hash of stage; stages past Count are ignored
+func RecordCheckpoint(stage, hash uint32) {
+	if stage >= checkpoints.Count {
+		return
+	}
+	checkpoints.Hashes[stage] = hash
+	checkpoints.Recorded |= 1 << stage
+}
+
+// CheckpointHash returns the hash recorded for stage by the last run, and
+// whether the run reached it with hashing enabled
+func CheckpointHash(stage uint32) (uint32, bool) {
+	if stage >= checkpoints.Count || checkpoints.Recorded&(1<<stage) == 0 {
+		return 0, false
+	}
+	return checkpoints.Hashes[stage], true
+}
+
+// CheckpointsPtr returns the address of the stage hashes in linear memory
+func CheckpointsPtr() uintptr {
+	return uintptr(unsafe.Pointer(&checkpoints))
+}
+
+// DivergedStage compares the stage hashes of the last run with a reference
+// vector's expected_stages, in the order of the task's stage names, and
+// describes the first expected stage the run missed or hashed differently;
+// "" when it matched them all. The stages before it agree, so the two
+// implementations parted between that stage and the one before.
+func DivergedStage(names []string, expected map[string]uint32) string {
+	for stage, name := range names {
+		want, ok := expected[name]
+		if !ok {
+			continue
+		}
+		got, recorded := CheckpointHash(uint32(stage))
+		switch {
+		case !recorded:
+			return "stage " + name + " was not recorded, expected " + string(AppendUint(nil, uint64(want)))
+		case got != want:
+			return "first diverging stage: " + name + " hashed " + string(AppendUint(nil, uint64(got))) +
+				", expected " + string(AppendUint(nil, uint64(want)))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestCheckpoints(t *testing.T) {
+	defer EnableCheckpoints(false)
+	EnableCheckpoints(true)
+	if !CheckpointsEnabled() {
+		t.Fatal("EnableCheckpoints(true) should enable stage hashing")
+	}
+
+	ResetCheckpoints(3)
+	RecordCheckpoint(StageInput, 0x1111)
+	RecordCheckpoint(2, 0x3333)
+	RecordCheckpoint(3, 0x4444) // Beyond the task's stages
+
+	if hash, ok := CheckpointHash(StageInput); !ok || hash != 0x1111 {
+		t.Errorf("Input stage = %#x, %v", hash, ok)
+	}
+	if _, ok := CheckpointHash(1); ok {
+		t.Error("A stage the run did not reach should not report a hash")
+	}
+	if _, ok := CheckpointHash(3); ok {
+		t.Error("Stages past the count should be ignored")
+	}
+
+	// The host reads {count, recorded mask, hashes...}
+	block := Memory(unsafe.Pointer(&checkpoints), int(unsafe.Sizeof(Checkpoints{})))
+	if CheckpointsPtr() != uintptr(unsafe.Pointer(&checkpoints)) {
+		t.Error("CheckpointsPtr should address the checkpoint block")
+	}
+	if ReadUint32LE(block) != 3 || ReadUint32LE(block[4:]) != 0b101 || ReadUint32LE(block[16:]) != 0x3333 {
+		t.Errorf("Unexpected checkpoint block % x", block[:20])
+	}
+
+	ResetCheckpoints(MaxCheckpoints + 4)
+	if checkpoints.Count != MaxCheckpoints || checkpoints.Recorded != 0 {
+		t.Errorf("Reset should clear the hashes and clamp the count, got %+v", checkpoints)
+	}
+}
+
+func TestDivergedStage(t *testing.T) {
+	defer EnableCheckpoints(false)
+	EnableCheckpoints(true)
+	names := []string{"input", "product", "output"}
+	ResetCheckpoints(uint32(len(names)))
+	RecordCheckpoint(StageInput, 11)
+	RecordCheckpoint(1, 22)
+
+	if got := DivergedStage(names, map[string]uint32{"input": 11, "product": 22}); got != "" {
+		t.Errorf("Matching stages reported %q", got)
+	}
+	if got := DivergedStage(names, map[string]uint32{"input": 11, "product": 23}); got != "first diverging stage: product hashed 22, expected 23" {
+		t.Errorf("A differing product reported %q", got)
+	}
+	if got := DivergedStage(names, map[string]uint32{"input": 10, "product": 23}); got != "first diverging stage: input hashed 11, expected 10" {
+		t.Errorf("Differing stages should report the first, got %q", got)
+	}
+	if got := DivergedStage(names, map[string]uint32{"output": 33}); got != "stage output was not recorded, expected 33" {
+		t.Errorf("An unreached stage reported %q", got)
+	}
+	// Stages the vector does not record, like those of a vector that predates them, are skipped
+	if got := DivergedStage(names, nil); got != "" {
+		t.Errorf("A vector without stages reported %q", got)
+	}
+}
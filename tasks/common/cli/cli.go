@@ -0,0 +1,105 @@
+// Package cli reads a task's params from JSON and runs the task as a WASI
+// command. It lives apart from common because it needs encoding/json and
+// strconv, which the modules built for the browser and wazero never use and
+// TinyGo would otherwise compile into every one of them.
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// ParamsFromJSON fills the struct at dst from a JSON object keyed by the
+// field names of fields (the get_task_info names, e.g. "max_iter"). Missing
+// fields are left untouched, so dst should start zeroed; unknown keys and
+// values that do not fit a field's type are rejected.
+func ParamsFromJSON(data []byte, fields []common.ParamField, dst unsafe.Pointer) (uint32, string) {
+	var values map[string]json.Number
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return common.StatusInvalidParams, "params are not a JSON object of numbers: " + err.Error()
+	}
+
+	for name := range values {
+		if !hasField(fields, name) {
+			return common.StatusInvalidParams, "unknown params field " + name
+		}
+	}
+
+	for _, field := range fields {
+		value, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+
+		target := unsafe.Add(dst, field.Offset)
+		if field.Type == common.FieldF64 {
+			f, err := value.Float64()
+			if err != nil {
+				return common.StatusInvalidParams, "field " + field.Name + " is not a number"
+			}
+			*(*float64)(target) = f
+			continue
+		}
+
+		if field.Type == common.FieldU64 {
+			u, err := strconv.ParseUint(value.String(), 10, 64)
+			if err != nil {
+				return common.StatusInvalidParams, "field " + field.Name + " is not a u64"
+			}
+			*(*uint64)(target) = u
+			continue
+		}
+
+		u, err := strconv.ParseUint(value.String(), 10, 32)
+		if err != nil || u > math.MaxUint32 {
+			return common.StatusInvalidParams, "field " + field.Name + " is not a u32"
+		}
+		*(*uint32)(target) = uint32(u)
+	}
+	return common.StatusOK, ""
+}
+
+func hasField(fields []common.ParamField, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run is the body of a task's WASI command: it reads the params as JSON
+// from stdin, runs them through run (the task's run_task_v2) and prints the
+// hash in decimal to stdout. Failures go to stderr, and the run's status is
+// returned as the process exit code (0 on success).
+func Run[T any](stdin io.Reader, stdout, stderr io.Writer, fields []common.ParamField,
+	run func(params *T, result *common.TaskResult) uint32) int {
+	input, err := io.ReadAll(stdin)
+	if err != nil {
+		io.WriteString(stderr, "error: reading params: "+err.Error()+"\n")
+		return int(common.StatusInvalidParams)
+	}
+
+	var params T
+	if status, message := ParamsFromJSON(input, fields, unsafe.Pointer(&params)); status != common.StatusOK {
+		io.WriteString(stderr, "error: "+message+"\n")
+		return int(status)
+	}
+
+	var result common.TaskResult
+	if status := run(&params, &result); status != common.StatusOK {
+		io.WriteString(stderr, "error: "+common.LastError()+"\n")
+		return int(status)
+	}
+
+	io.WriteString(stdout, strconv.FormatUint(uint64(result.Hash), 10)+"\n")
+	return 0
+}
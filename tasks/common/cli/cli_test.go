@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+type testParams struct {
+	Count  uint32
+	Center float64
+	Seed   uint32
+	Total  uint64
+}
+
+func testFields() []common.ParamField {
+	var p testParams
+	return []common.ParamField{
+		{Name: "count", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Count)},
+		{Name: "center", Type: common.FieldF64, Offset: unsafe.Offsetof(p.Center)},
+		{Name: "seed", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Seed)},
+		{Name: "total", Type: common.FieldU64, Offset: unsafe.Offsetof(p.Total)},
+	}
+}
+
+func TestParamsFromJSON(t *testing.T) {
+	var p testParams
+	status, message := ParamsFromJSON([]byte(`{"count": 7, "center": -0.75}`), testFields(), unsafe.Pointer(&p))
+	if status != common.StatusOK || p != (testParams{Count: 7, Center: -0.75}) {
+		t.Fatalf("Got %+v, status %d (%s)", p, status, message)
+	}
+
+	rejected := []string{
+		`{"count": 1, "unknown": 2}`,
+		`{"count": -1}`,
+		`{"count": 4294967296}`,
+		`{"count": 1.5}`,
+		`[1, 2]`,
+		``,
+	}
+	for _, input := range rejected {
+		var p testParams
+		if status, _ := ParamsFromJSON([]byte(input), testFields(), unsafe.Pointer(&p)); status != common.StatusInvalidParams {
+			t.Errorf("%q: status %d, expected %d", input, status, common.StatusInvalidParams)
+		}
+	}
+
+	if status, message := ParamsFromJSON([]byte(`{"total": 18446744073709551615}`), testFields(), unsafe.Pointer(&p)); status != common.StatusOK || p.Total != 1<<64-1 {
+		t.Errorf("JSON u64 read as %d, status %d (%s)", p.Total, status, message)
+	}
+	if status, _ := ParamsFromJSON([]byte(`{"total": 18446744073709551616}`), testFields(), unsafe.Pointer(&p)); status != common.StatusInvalidParams {
+		t.Errorf("JSON value past u64 should be rejected, got status %d", status)
+	}
+}
+
+func TestRun(t *testing.T) {
+	// Stand-in run_task_v2: hashes count and seed, rejects a zero count
+	runTaskV2 := func(p *testParams, result *common.TaskResult) uint32 {
+		status := common.StatusOK
+		if p.Count == 0 {
+			status = common.StatusInvalidParams
+			common.SetLastError("count must be positive")
+		}
+		*result = common.TaskResult{Status: status, Hash: p.Count * p.Seed}
+		return status
+	}
+
+	var stdout, stderr strings.Builder
+	code := Run[testParams](strings.NewReader(`{"count": 6, "seed": 7}`), &stdout, &stderr, testFields(), runTaskV2)
+	if code != 0 || stdout.String() != "42\n" || stderr.Len() != 0 {
+		t.Errorf("Exit %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+
+	stdout.Reset()
+	code = Run[testParams](strings.NewReader(`{"seed": 7}`), &stdout, &stderr, testFields(), runTaskV2)
+	if code != int(common.StatusInvalidParams) || stdout.Len() != 0 || stderr.String() != "error: count must be positive\n" {
+		t.Errorf("Exit %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+	common.ClearLastError()
+}
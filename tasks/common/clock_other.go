@@ -0,0 +1,14 @@
+//go:build !wasm || wasip1 || !tinygo
+
+package common
+
+import "time"
+
+// clockStart anchors NowMs for native builds such as go test
+var clockStart = time.Now()
+
+// NowMs reports monotonic milliseconds since process start, standing in for
+// the host clock in native, WASI and gc-Go builds
+func NowMs() float64 {
+	return float64(time.Since(clockStart).Nanoseconds()) / 1e6
+}
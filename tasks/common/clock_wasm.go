@@ -0,0 +1,9 @@
+//go:build tinygo && !wasip1
+
+package common
+
+// NowMs reads the host's monotonic clock in milliseconds, imported as
+// env.now_ms (performance.now() in the web harness)
+//
+//go:wasmimport env now_ms
+func NowMs() float64
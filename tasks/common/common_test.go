@@ -0,0 +1,531 @@
+package common
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestHashBytesKnownVectors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint32
+	}{
+		{"", 0x811C9DC5},
+		{"a", 0xE40C292C},
+		{"foobar", 0xBF9CF968},
+	}
+
+	for _, tt := range tests {
+		if got := HashBytes(FNVOffsetBasis, []byte(tt.input)); got != tt.expected {
+			t.Errorf("HashBytes(%q) = %#x, expected %#x", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestHashUint32MatchesLittleEndianBytes(t *testing.T) {
+	values := []uint32{0, 1, 0x12345678, 0xFFFFFFFF}
+	bytes := make([]byte, 4*len(values))
+	for i, value := range values {
+		PutUint32LE(bytes[i*4:], value)
+	}
+
+	want := HashBytes(FNVOffsetBasis, bytes)
+	if got := HashUint32s(FNVOffsetBasis, values); got != want {
+		t.Errorf("HashUint32s = %#x, byte-wise hash = %#x", got, want)
+	}
+
+	hash := FNVOffsetBasis
+	for _, b := range bytes {
+		hash = HashByte(hash, b)
+	}
+	if hash != want {
+		t.Errorf("HashByte chain = %#x, expected %#x", hash, want)
+	}
+}
+
+func TestHash64BytesKnownVectors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint64
+	}{
+		{"", 0xCBF29CE484222325},
+		{"a", 0xAF63DC4C8601EC8C},
+		{"foobar", 0x85944171F73967E8},
+	}
+
+	for _, tt := range tests {
+		if got := Hash64Bytes(FNV64OffsetBasis, []byte(tt.input)); got != tt.expected {
+			t.Errorf("Hash64Bytes(%q) = %#x, expected %#x", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestHash64Uint32MatchesLittleEndianBytes(t *testing.T) {
+	values := []uint32{0, 1, 0x12345678, 0xFFFFFFFF}
+	bytes := make([]byte, 4*len(values))
+	for i, value := range values {
+		PutUint32LE(bytes[i*4:], value)
+	}
+
+	want := Hash64Bytes(FNV64OffsetBasis, bytes)
+	if got := Hash64Uint32s(FNV64OffsetBasis, values); got != want {
+		t.Errorf("Hash64Uint32s = %#x, byte-wise hash = %#x", got, want)
+	}
+
+	hash := FNV64OffsetBasis
+	for _, b := range bytes {
+		hash = Hash64Byte(hash, b)
+	}
+	if hash != want {
+		t.Errorf("Hash64Byte chain = %#x, expected %#x", hash, want)
+	}
+}
+
+func TestWordHashesMatchByteChain(t *testing.T) {
+	data := []byte("word-at-a-time FNV")
+	for n := 0; n <= len(data); n++ {
+		hash, hash64 := FNVOffsetBasis, FNV64OffsetBasis
+		for _, b := range data[:n] {
+			hash, hash64 = HashByte(hash, b), Hash64Byte(hash64, b)
+		}
+		if got := HashBytes(FNVOffsetBasis, data[:n]); got != hash {
+			t.Errorf("HashBytes over %d bytes = %#x, byte chain = %#x", n, got, hash)
+		}
+		if got := Hash64Bytes(FNV64OffsetBasis, data[:n]); got != hash64 {
+			t.Errorf("Hash64Bytes over %d bytes = %#x, byte chain = %#x", n, got, hash64)
+		}
+	}
+
+	for _, value := range []uint64{0, 1, 0x0123456789ABCDEF, 0xFFFFFFFFFFFFFFFF} {
+		var bytes [8]byte
+		PutUint64LE(bytes[:], value)
+		if got, want := HashUint64(FNVOffsetBasis, value), HashBytes(FNVOffsetBasis, bytes[:]); got != want {
+			t.Errorf("HashUint64(%#x) = %#x, byte-wise hash = %#x", value, got, want)
+		}
+		if got, want := Hash64Uint64(FNV64OffsetBasis, value), Hash64Bytes(FNV64OffsetBasis, bytes[:]); got != want {
+			t.Errorf("Hash64Uint64(%#x) = %#x, byte-wise hash = %#x", value, got, want)
+		}
+	}
+}
+
+func TestXXHash32KnownVectors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected uint32
+	}{
+		{"", 0x02CC5D05},
+		{"a", 0x550D7456},
+		{"abc", 0x32D153FF},
+		{"Nobody inspects the spammish repetition", 0xE2293B2F},
+	}
+
+	for _, tt := range tests {
+		if got := XXHash32Bytes(0, []byte(tt.input)); got != tt.expected {
+			t.Errorf("XXHash32Bytes(%q) = %#x, expected %#x", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestXXHash32StreamingMatchesOneShot(t *testing.T) {
+	data := make([]byte, 103)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	want := XXHash32Bytes(0, data)
+
+	// Mixed write sizes cross stripe boundaries at every alignment
+	h := NewXXHash32(0)
+	h.AddByte(data[0])
+	h.AddBytes(data[1:6])
+	h.AddUint32(ReadUint32LE(data[6:]))
+	h.AddBytes(data[10:41])
+	for i := 41; i < 101; i += 4 {
+		h.AddUint32(ReadUint32LE(data[i:]))
+	}
+	h.AddByte(data[101])
+	h.AddByte(data[102])
+	if got := h.Sum32(); got != want {
+		t.Errorf("Streaming hash = %#x, one-shot hash = %#x", got, want)
+	}
+
+	values := []uint32{0, 1, 0x12345678, 0xFFFFFFFF, 42}
+	bytes := make([]byte, 4*len(values))
+	for i, value := range values {
+		PutUint32LE(bytes[i*4:], value)
+	}
+	if got, want := XXHash32Uint32s(0, values), XXHash32Bytes(0, bytes); got != want {
+		t.Errorf("XXHash32Uint32s = %#x, byte-wise hash = %#x", got, want)
+	}
+}
+
+func TestNextLCGSequence(t *testing.T) {
+	state := uint32(0)
+	expected := []uint32{1013904223, 1196435762, 3519870697}
+
+	for i, want := range expected {
+		if got := NextLCG(&state); got != want || state != want {
+			t.Errorf("Step %d: got %d (state %d), expected %d", i, got, state, want)
+		}
+	}
+}
+
+func TestPCG32ReferenceSequence(t *testing.T) {
+	// pcg32-global demo output for seed 42, stream 54
+	p := NewPCG32(42, 54)
+	expected := []uint32{0xA15C02B7, 0x7B47F409, 0xBA1D3330, 0x83D2F293, 0xBFA4784B, 0xCBED606E}
+
+	for i, want := range expected {
+		if got := p.Next(); got != want {
+			t.Errorf("Step %d: got %#x, expected %#x", i, got, want)
+		}
+	}
+}
+
+func TestRandGenerators(t *testing.T) {
+	lcg := NewRand(GeneratorLCG, 7)
+	state := uint32(7)
+	for i := 0; i < 4; i++ {
+		if got, want := lcg.Next(), NextLCG(&state); got != want {
+			t.Errorf("LCG step %d: got %d, expected %d", i, got, want)
+		}
+	}
+
+	pcg := NewRand(GeneratorPCG32, 7)
+	reference := NewPCG32(7, PCGDefaultStream)
+	for i := 0; i < 4; i++ {
+		if got, want := pcg.Next(), reference.Next(); got != want {
+			t.Errorf("PCG32 step %d: got %d, expected %d", i, got, want)
+		}
+	}
+
+	// Unlike the LCG, PCG32's lowest bit does not simply alternate
+	lcg, pcg = NewRand(GeneratorLCG, 7), NewRand(GeneratorPCG32, 7)
+	lcgAlternates, pcgAlternates := true, true
+	previousLCG, previousPCG := lcg.Next()&1, pcg.Next()&1
+	for i := 0; i < 64; i++ {
+		nextLCG, nextPCG := lcg.Next()&1, pcg.Next()&1
+		lcgAlternates = lcgAlternates && nextLCG != previousLCG
+		pcgAlternates = pcgAlternates && nextPCG != previousPCG
+		previousLCG, previousPCG = nextLCG, nextPCG
+	}
+	if !lcgAlternates || pcgAlternates {
+		t.Errorf("Low-bit alternation: LCG %v (expected true), PCG32 %v (expected false)", lcgAlternates, pcgAlternates)
+	}
+}
+
+func TestHostRandom(t *testing.T) {
+	values := []uint32{5, 1, 4}
+	SetHostRandom(func() uint32 {
+		value := values[0]
+		values = values[1:]
+		return value
+	})
+	defer SetHostRandom(nil)
+
+	// The host stream ignores the seed and serves every array in draw order
+	host := NewRand(GeneratorHost, 7)
+	if host.Next() != 5 || host.Stream(1).Next() != 1 || host.Stream(0).Next() != 4 {
+		t.Error("Host generator should return the source's values in order")
+	}
+}
+
+func TestSeed64(t *testing.T) {
+	if got := JoinSeed(0x89ABCDEF, 0x01234567); got != 0x0123456789ABCDEF {
+		t.Errorf("JoinSeed = %#x, expected 0x0123456789ABCDEF", got)
+	}
+
+	// Legacy 32-bit seeds keep their LCG sequence; high words fold into it
+	if LCGSeed(7) != 7 || LCGSeed(JoinSeed(7, 0)) != 7 {
+		t.Error("A seed below 2^32 should reach the LCG unchanged")
+	}
+	if got := LCGSeed(JoinSeed(7, 3)); got != 7^3 {
+		t.Errorf("LCGSeed should fold the high word, got %d", got)
+	}
+
+	wide, legacy := NewRand(GeneratorPCG32, JoinSeed(7, 1)), NewRand(GeneratorPCG32, 7)
+	reference := NewPCG32(JoinSeed(7, 1), PCGDefaultStream)
+	if got := wide.Next(); got != reference.Next() || got == legacy.Next() {
+		t.Error("PCG32 should be seeded with all 64 bits")
+	}
+}
+
+func TestSplitMix64ReferenceSequence(t *testing.T) {
+	s := NewSplitMix64(1234567)
+	expected := []uint64{6457827717110365317, 3203168211198807973, 9817491932198370423, 4593380528125082431}
+
+	for i, want := range expected {
+		if got := s.Next(); got != want {
+			t.Errorf("Step %d: got %d, expected %d", i, got, want)
+		}
+		if got := ExpandSeed(1234567, uint32(i)); got != want {
+			t.Errorf("ExpandSeed(1234567, %d) = %d, expected %d", i, got, want)
+		}
+	}
+}
+
+func TestRandStreams(t *testing.T) {
+	// PCG32 streams do not depend on what was drawn before
+	first := NewRand(GeneratorPCG32, 9)
+	for i := 0; i < 10; i++ {
+		first.Next()
+	}
+	second := NewRand(GeneratorPCG32, 9)
+	a, b := first.Stream(1), second.Stream(1)
+	for i := 0; i < 4; i++ {
+		if a.Next() != b.Next() {
+			t.Fatal("PCG32 stream 1 should not depend on prior draws")
+		}
+	}
+
+	other := second.Stream(2)
+	if second.Stream(1).Next() == other.Next() {
+		t.Error("Distinct PCG32 streams should differ")
+	}
+
+	// The LCG continues its single shared stream
+	lcg := NewRand(GeneratorLCG, 9)
+	if lcg.Stream(1) != &lcg {
+		t.Error("LCG Stream should return the shared generator")
+	}
+}
+
+func TestAlloc(t *testing.T) {
+	if Alloc(0) != 0 {
+		t.Error("Zero-byte allocation should return 0")
+	}
+	if Alloc(MaxAllocationSize+1) != 0 {
+		t.Error("Oversized allocation should return 0")
+	}
+	if Alloc(64) == 0 {
+		t.Error("Small allocation should succeed")
+	}
+}
+
+func TestAllocPinsUntilFree(t *testing.T) {
+	count, bytes := LiveAllocations()
+
+	ptr := Alloc(100)
+	if gotCount, gotBytes := LiveAllocations(); gotCount != count+1 || gotBytes != bytes+100 {
+		t.Errorf("Alloc should pin the buffer: %d buffers/%d bytes, expected %d/%d", gotCount, gotBytes, count+1, bytes+100)
+	}
+
+	if !Free(ptr) {
+		t.Error("Free should release a live allocation")
+	}
+	if gotCount, gotBytes := LiveAllocations(); gotCount != count || gotBytes != bytes {
+		t.Errorf("Free should unpin the buffer: %d buffers/%d bytes, expected %d/%d", gotCount, gotBytes, count, bytes)
+	}
+
+	if Free(ptr) {
+		t.Error("Double free should be ignored")
+	}
+	if Free(0) {
+		t.Error("Freeing the null address should be ignored")
+	}
+}
+
+func TestAllocUninitialized(t *testing.T) {
+	defer func() { recycled = nil }()
+	recycled = nil
+
+	if AllocUninitialized(0) != 0 || AllocUninitialized(MaxAllocationSize+1) != 0 {
+		t.Error("Empty and oversized requests should return 0")
+	}
+
+	// A fresh buffer comes from the runtime, zeroed
+	ptr := AllocUninitialized(100)
+	buf := allocations[ptr]
+	if len(buf) != 100 || buf[0] != 0 {
+		t.Fatalf("Expected a zeroed 100-byte buffer, got %d bytes", len(buf))
+	}
+	buf[0], buf[99] = 7, 9
+	count, bytes := LiveAllocations()
+	if !Free(ptr) || len(recycled) != 1 {
+		t.Fatalf("Free should keep the buffer for reuse, %d kept", len(recycled))
+	}
+	if gotCount, gotBytes := LiveAllocations(); gotCount != count-1 || gotBytes != bytes-100 {
+		t.Errorf("Free should still unpin the buffer: %d buffers/%d bytes", gotCount, gotBytes)
+	}
+
+	// A smaller request reuses it as it was left, without zeroing
+	small := AllocUninitialized(50)
+	if small != ptr || allocations[small][0] != 7 || len(allocations[small]) != 50 || len(recycled) != 0 {
+		t.Errorf("Expected the recycled buffer at %#x unchanged, got %#x", ptr, small)
+	}
+	Free(small)
+	if len(recycled) != 1 || cap(recycled[0]) != 100 || len(recycled[0]) != 100 {
+		t.Fatal("Free should recycle the buffer at its full capacity")
+	}
+	if larger := AllocUninitialized(200); larger == ptr || len(recycled) != 1 {
+		t.Error("A request larger than every recycled buffer should allocate")
+	} else {
+		Free(larger)
+	}
+
+	// The smallest buffer that fits is taken
+	if got := AllocUninitialized(80); got != ptr {
+		t.Errorf("Expected the best-fitting buffer %#x, got %#x", ptr, got)
+	} else {
+		Free(got)
+	}
+
+	// Buffers from Alloc go back to the GC, and only the newest few are kept
+	Free(Alloc(64))
+	if len(recycled) != 2 {
+		t.Errorf("Alloc's buffers should not be recycled, %d kept", len(recycled))
+	}
+	var ptrs []uintptr
+	for range maxRecycled + 2 {
+		ptrs = append(ptrs, AllocUninitialized(1000))
+	}
+	for _, p := range ptrs {
+		Free(p)
+	}
+	if len(recycled) != maxRecycled || uintptr(unsafe.Pointer(&recycled[maxRecycled-1][0])) != ptrs[len(ptrs)-1] {
+		t.Errorf("Free should keep the %d newest buffers, %d kept", maxRecycled, len(recycled))
+	}
+	if len(uninitialized) != 0 {
+		t.Errorf("%d released buffers still marked", len(uninitialized))
+	}
+}
+
+func TestLayoutFingerprint(t *testing.T) {
+	// FNV-1a of the byte sequence 01 00 00 00
+	if got := LayoutFingerprint([]uint32{1}); got != 0xFB69B604 {
+		t.Errorf("LayoutFingerprint([1]) = %#x", got)
+	}
+}
+
+func TestLastError(t *testing.T) {
+	SetLastError("width exceeds limit")
+	if LastError() != "width exceeds limit" || LastErrorLen() != uint32(len("width exceeds limit")) {
+		t.Errorf("Unexpected message %q (len %d)", LastError(), LastErrorLen())
+	}
+
+	ptr := LastErrorPtr()
+	SetLastError(strings.Repeat("x", MaxErrorLength+10))
+	if LastErrorLen() != MaxErrorLength {
+		t.Errorf("Long messages should be truncated to %d bytes, got %d", MaxErrorLength, LastErrorLen())
+	}
+	if LastErrorPtr() != ptr {
+		t.Error("The message buffer should not move between calls")
+	}
+
+	ClearLastError()
+	if LastError() != "" || LastErrorLen() != 0 {
+		t.Error("ClearLastError should empty the message")
+	}
+}
+
+func TestPackResult(t *testing.T) {
+	packed := PackResult(StatusVerificationFailed, 0xDEADBEEF)
+	if packed>>32 != uint64(StatusVerificationFailed) || uint32(packed) != 0xDEADBEEF {
+		t.Errorf("PackResult = %#x, expected status high and hash low", packed)
+	}
+	if PackResult(StatusOK, 0) != 0 {
+		t.Error("An ok run with hash 0 should pack to 0")
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	status := StatusOK
+	run := func(index int) uint32 {
+		defer RecoverPanic(&status)
+		values := []uint32{1, 2, 3}
+		return values[index]
+	}
+
+	if got := run(1); got != 2 || status != StatusOK || PanicMessageLen() != 0 {
+		t.Fatalf("Normal run: got %d, status %d, panic %q", got, status, PanicMessage())
+	}
+
+	if got := run(5); got != 0 || status != StatusPanicked {
+		t.Fatalf("Panicking run: got %d, status %d (expected 0, %d)", got, status, StatusPanicked)
+	}
+	if msg := PanicMessage(); !strings.HasPrefix(msg, "panic: ") || !strings.Contains(msg, "index out of range") {
+		t.Errorf("Unexpected panic message %q", msg)
+	}
+	if LastError() != PanicMessage() {
+		t.Errorf("Last error %q should mirror the panic message", LastError())
+	}
+
+	ClearPanic()
+	if PanicMessageLen() != 0 {
+		t.Error("ClearPanic should empty the buffer")
+	}
+	ClearLastError()
+}
+
+func TestFieldsFingerprint(t *testing.T) {
+	fields := []ParamField{{"a", FieldU32, 0}, {"b", FieldF64, 8}}
+	if got, want := FieldsFingerprint(fields, 16), LayoutFingerprint([]uint32{0, 4, 8, 8, 16}); got != want {
+		t.Errorf("FieldsFingerprint = 0x%08X, want 0x%08X", got, want)
+	}
+}
+
+func TestEncodeTaskInfo(t *testing.T) {
+	blob := EncodeTaskInfo(TaskInfo{
+		Task:       "demo",
+		Language:   "tinygo",
+		Variant:    "naive",
+		ParamsSize: 8,
+		Params:     []ParamField{{"count", FieldU32, 0}, {"seed", FieldU32, 4}},
+		Stages:     []string{"input", "output"},
+	})
+
+	want := `{"task":"demo","language":"tinygo","variant":"naive","abi_version":2,"params_size":8,` +
+		`"params":[{"name":"count","type":"u32","offset":0},{"name":"seed","type":"u32","offset":4}],` +
+		`"stages":["input","output"]}`
+	if AllocStats {
+		want = strings.TrimSuffix(want, "}") + `,"alloc_stats":true}`
+	}
+	length := uint32(blob[0]) | uint32(blob[1])<<8 | uint32(blob[2])<<16 | uint32(blob[3])<<24
+	if int(length) != len(want) || string(blob[4:]) != want {
+		t.Errorf("Unexpected task info (length %d):\n%s", length, blob[4:])
+	}
+}
+
+func TestNowMsIsMonotonic(t *testing.T) {
+	start := NowMs()
+	time.Sleep(time.Millisecond)
+	if elapsed := NowMs() - start; elapsed < 1 {
+		t.Errorf("NowMs advanced %f ms across a 1 ms sleep", elapsed)
+	}
+}
+
+func TestSnapshotMemoryStats(t *testing.T) {
+	before := *SnapshotMemoryStats()
+	buf := make([]byte, 1<<20)
+	buf[0] = 1
+	runtime.GC()
+	after := *SnapshotMemoryStats()
+
+	if after.TotalAlloc-before.TotalAlloc < 1<<20 {
+		t.Errorf("TotalAlloc should grow by the 1MB allocation, grew %d", after.TotalAlloc-before.TotalAlloc)
+	}
+	if after.Mallocs <= before.Mallocs || after.NumGC <= before.NumGC || after.HeapInUse == 0 {
+		t.Errorf("Unexpected memory stats: before %+v, after %+v", before, after)
+	}
+}
+
+func TestAllocCount(t *testing.T) {
+	StartAllocCount()
+	buf := make([]byte, 1<<20)
+	buf[0] = 1
+	runtime.GC()
+	StopAllocCount()
+	stats := *SnapshotMemoryStats()
+
+	if !AllocStats {
+		if stats.RunMallocs != 0 || stats.RunBytes != 0 || stats.RunGCs != 0 {
+			t.Errorf("run counts %+v without the allocstats tag, expected none", stats)
+		}
+		return
+	}
+	if stats.RunBytes < 1<<20 || stats.RunMallocs == 0 || stats.RunGCs == 0 {
+		t.Errorf("run counts %+v, expected the 1MB allocation and a GC cycle", stats)
+	}
+}
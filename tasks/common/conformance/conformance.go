@@ -0,0 +1,339 @@
+// Package conformance runs a task's reference vectors through its Go
+// implementation under one policy, the same for every task, and reports the
+// pass rate of each category:
+//
+//	func TestCrossImplementationHashMatching(t *testing.T) {
+//		conformance.Run(t, conformance.Task{Name: "mandelbrot", ...})
+//	}
+//
+// A vector passes when the run reports its expected status and error code,
+// and, for a run that succeeds, its expected hash with every recorded stage
+// hash matching too. A float task may give a tolerance: a run that misses
+// the hash then passes if its output is within the tolerance of the exact
+// reference output, and is counted apart as tolerated. A file that cannot
+// be read, or that breaks the task's refschema.Schema, fails the test;
+// nothing is skipped.
+package conformance
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/cli"
+	"wasmbench/common/refschema"
+)
+
+// ReferenceHashesEnv names a directory of <task>.json reference files read
+// instead of each task's own copy, e.g. to try a file before go generate
+// copies it into the task packages
+const ReferenceHashesEnv = "WASMBENCH_REFERENCE_HASHES"
+
+// FloatToleranceEnv overrides the tolerance of every float task, written as
+// common.ParseTolerance reads it
+const FloatToleranceEnv = "WASMBENCH_FLOAT_TOLERANCE"
+
+// maxReported bounds the failures reported one by one per task; a broken
+// implementation fails most vectors, and the first few say why
+const maxReported = 10
+
+// Task is what the suite needs of a task package, all of it exported for
+// the native harnesses already
+type Task struct {
+	Name    string
+	Schema  refschema.Schema                          // ReferenceSchema(), with the params fields and stages
+	Size    uintptr                                   // Of the params struct
+	Run     func(paramsPtr, resultPtr uintptr) uint32 // RunTaskV2
+	Vectors []byte                                    // The reference file
+	Source  string                                    // Where Vectors came from, for messages
+
+	// Tolerance, for a float task, bounds how far the output of a run that
+	// misses the hash may be from Reference's, as common.ParseTolerance
+	// reads it; "" compares hashes only
+	Tolerance string
+	// Reference returns the exact output of the params at ptr, the value
+	// every implementation approximates in its own order of operations
+	Reference func(params unsafe.Pointer) []float32
+}
+
+// Vector is an entry of a reference file
+type Vector struct {
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Params            json.RawMessage   `json:"params"`
+	ExpectedHash      uint32            `json:"expected_hash"`
+	ExpectedStatus    uint32            `json:"expected_status"`
+	ExpectedErrorCode uint32            `json:"expected_error_code"`
+	ExpectedStages    map[string]uint32 `json:"expected_stages"`
+	Category          string            `json:"category"`
+}
+
+// Outcome is how a vector's run went
+type Outcome struct {
+	Vector    Vector
+	Hash      uint32
+	Status    uint32
+	ErrorCode uint32
+	Tolerated string // How far off a hash miss within the tolerance was
+	Failure   string // Why the vector failed, "" if it passed
+}
+
+// Passed reports whether the vector passed, tolerated or not
+func (o Outcome) Passed() bool {
+	return o.Failure == ""
+}
+
+// Report is the outcome of every vector of a task, in file order
+type Report struct {
+	Task     string
+	Outcomes []Outcome
+}
+
+// Tally counts the vectors of a category, or of a whole task
+type Tally struct {
+	Category  string
+	Vectors   int
+	Passed    int // Tolerated included
+	Tolerated int
+}
+
+// Rate is the share of vectors passed, in percent
+func (t Tally) Rate() float64 {
+	if t.Vectors == 0 {
+		return 100
+	}
+	return 100 * float64(t.Passed) / float64(t.Vectors)
+}
+
+func (t Tally) String() string {
+	s := fmt.Sprintf("%d/%d passed (%.1f%%)", t.Passed, t.Vectors, t.Rate())
+	if t.Tolerated > 0 {
+		s += fmt.Sprintf(", %d within tolerance", t.Tolerated)
+	}
+	return s
+}
+
+func (t *Tally) add(o Outcome) {
+	t.Vectors++
+	if o.Passed() {
+		t.Passed++
+	}
+	if o.Tolerated != "" {
+		t.Tolerated++
+	}
+}
+
+// Total tallies every vector of the report
+func (r Report) Total() Tally {
+	total := Tally{Category: r.Task}
+	for _, o := range r.Outcomes {
+		total.add(o)
+	}
+	return total
+}
+
+// Categories tallies the vectors of each category, in name order; vectors
+// without one are "uncategorized"
+func (r Report) Categories() []Tally {
+	byName := map[string]*Tally{}
+	for _, o := range r.Outcomes {
+		name := cmp.Or(o.Vector.Category, "uncategorized")
+		if byName[name] == nil {
+			byName[name] = &Tally{Category: name}
+		}
+		byName[name].add(o)
+	}
+	tallies := make([]Tally, 0, len(byName))
+	for _, tally := range byName {
+		tallies = append(tallies, *tally)
+	}
+	slices.SortFunc(tallies, func(a, b Tally) int { return strings.Compare(a.Category, b.Category) })
+	return tallies
+}
+
+// Failures returns the outcomes of the vectors that failed
+func (r Report) Failures() []Outcome {
+	var failed []Outcome
+	for _, o := range r.Outcomes {
+		if !o.Passed() {
+			failed = append(failed, o)
+		}
+	}
+	return failed
+}
+
+// Run checks every task's vectors in a subtest of its own, reports each
+// task's pass rate by category, and fails the subtest of any task with a
+// vector that failed
+func Run(t *testing.T, tasks ...Task) {
+	t.Helper()
+	var total Tally
+	for _, task := range tasks {
+		t.Run(task.Name, func(t *testing.T) {
+			report, err := Check(task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tally := range report.Categories() {
+				t.Logf("%s: %s", tally.Category, tally)
+			}
+			taskTotal := report.Total()
+			total.Vectors += taskTotal.Vectors
+			total.Passed += taskTotal.Passed
+			total.Tolerated += taskTotal.Tolerated
+			for _, o := range report.Outcomes {
+				if o.Tolerated != "" {
+					t.Logf("%s: %s", o.Vector.Name, o.Tolerated)
+				}
+			}
+
+			failures := report.Failures()
+			if len(failures) == 0 {
+				t.Logf("total: %s", taskTotal)
+				return
+			}
+			for _, o := range failures[:min(len(failures), maxReported)] {
+				t.Errorf("%s (%s): %s", o.Vector.Name, o.Vector.Description, o.Failure)
+			}
+			if len(failures) > maxReported {
+				t.Errorf("... and %d more", len(failures)-maxReported)
+			}
+			t.Errorf("CROSS-IMPLEMENTATION VALIDATION FAILED for %s: %s", task.Name, taskTotal)
+		})
+	}
+	if len(tasks) > 1 {
+		t.Logf("%d tasks: %s", len(tasks), total)
+	}
+}
+
+// Check runs every vector of task and reports how each went. The error is
+// for a reference file that cannot be read or breaks the schema, or a
+// tolerance that cannot be read, not a vector that failed.
+func Check(task Task) (Report, error) {
+	vectors, err := task.Load()
+	if err != nil {
+		return Report{}, err
+	}
+	var tolerance common.Tolerance
+	if task.Tolerance != "" {
+		if tolerance, err = Tolerance(task.Tolerance); err != nil {
+			return Report{}, err
+		}
+	}
+	report := Report{Task: task.Name, Outcomes: make([]Outcome, 0, len(vectors))}
+	for _, vector := range vectors {
+		report.Outcomes = append(report.Outcomes, task.check(vector, tolerance))
+	}
+	return report, nil
+}
+
+// Tolerance reads spec, or FloatToleranceEnv when it is set
+func Tolerance(spec string) (common.Tolerance, error) {
+	if env := os.Getenv(FloatToleranceEnv); env != "" {
+		tolerance, err := common.ParseTolerance(env)
+		if err != nil {
+			return common.Tolerance{}, fmt.Errorf("%s: %w", FloatToleranceEnv, err)
+		}
+		return tolerance, nil
+	}
+	return common.ParseTolerance(spec)
+}
+
+// Load reads the task's vectors, from ReferenceHashesEnv's directory when it
+// is set, and fails unless the file follows the task's schema
+func (task Task) Load() ([]Vector, error) {
+	data, source := task.Vectors, task.Source
+	if dir := os.Getenv(ReferenceHashesEnv); dir != "" {
+		source = filepath.Join(dir, task.Name+".json")
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return nil, fmt.Errorf("failed to read test vectors file %s: %w", source, err)
+		}
+	}
+
+	if err := task.Schema.Validate(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	var vectors []Vector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON from %s: %w", source, err)
+	}
+	return vectors, nil
+}
+
+// runResult receives run_task_v2's result. It is a global because the task
+// writes it through a uintptr: a local could move with the goroutine's stack
+// when a run grows it, leaving the write in the old stack.
+var runResult common.TaskResult
+
+// check runs a single vector with checkpoints on
+func (task Task) check(vector Vector, tolerance common.Tolerance) Outcome {
+	outcome := Outcome{Vector: vector}
+	// Backed by uint64s so the f64 and u64 fields are aligned
+	words := make([]uint64, (task.Size+7)/8)
+	params := unsafe.Pointer(&words[0])
+	if status, message := cli.ParamsFromJSON(vector.Params, task.Schema.Fields, params); status != common.StatusOK {
+		outcome.Failure = "the params do not decode: " + message
+		return outcome
+	}
+
+	common.EnableCheckpoints(true)
+	defer common.EnableCheckpoints(false)
+	runResult = common.TaskResult{}
+	outcome.Status = task.Run(uintptr(params), uintptr(unsafe.Pointer(&runResult)))
+	outcome.Hash, outcome.ErrorCode = runResult.Hash, common.ErrorCode()
+	runtime.KeepAlive(words)
+	diverged := common.DivergedStage(task.Schema.Stages, vector.ExpectedStages)
+
+	switch {
+	case outcome.Status != vector.ExpectedStatus || outcome.ErrorCode != vector.ExpectedErrorCode:
+		outcome.Failure = fmt.Sprintf("expected status %d with error code %d, got status %d with error code %d",
+			vector.ExpectedStatus, vector.ExpectedErrorCode, outcome.Status, outcome.ErrorCode)
+	case outcome.Hash == vector.ExpectedHash && diverged != "":
+		outcome.Failure = "the hash matches, but " + diverged
+	case outcome.Hash == vector.ExpectedHash:
+	case task.Reference == nil:
+		outcome.Failure = hashMiss(outcome, diverged)
+	default:
+		// Rejections never get here, so the run had an output to compare
+		c := tolerance.CompareFloat32s(task.output(params), task.Reference(params))
+		runtime.KeepAlive(words)
+		if c.Within() {
+			outcome.Tolerated = fmt.Sprintf("%s, a float rounding difference: all %d elements within %s (max %d ULPs, max difference %g)",
+				hashMiss(outcome, diverged), c.Elements, tolerance, c.MaxULPs, c.MaxAbs)
+		} else {
+			outcome.Failure = fmt.Sprintf("%s, and %d of %d elements are beyond %s, the first at %d: %v, expected %v",
+				hashMiss(outcome, diverged), c.Beyond, c.Elements, tolerance, c.First, c.FirstGot, c.FirstWant)
+		}
+	}
+	return outcome
+}
+
+// hashMiss describes a hash that missed the vector's
+func hashMiss(o Outcome, diverged string) string {
+	message := fmt.Sprintf("expected hash %d, got %d", o.Vector.ExpectedHash, o.Hash)
+	if diverged != "" {
+		message += "; " + diverged
+	}
+	return message
+}
+
+// output runs the params at ptr once more with the output kept, and returns
+// it as float32s
+func (task Task) output(params unsafe.Pointer) []float32 {
+	common.EnableOutput(true)
+	defer common.EnableOutput(false)
+	if status := task.Run(uintptr(params), uintptr(unsafe.Pointer(&runResult))); status != common.StatusOK {
+		return nil
+	}
+	data, _ := common.RunOutput()
+	return common.Float32sLE(data)
+}
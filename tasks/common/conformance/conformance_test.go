@@ -0,0 +1,146 @@
+package conformance
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/refschema"
+)
+
+// fakeRun is a task in miniature: it rejects n = 0, records an input stage
+// of 10n, hashes n, except 7 and 13, which it hashes one too high, and
+// outputs its hash, with 7's rounded to the next float instead
+func fakeRun(paramsPtr, resultPtr uintptr) uint32 {
+	common.ClearLastError()
+	common.ResetCheckpoints(2)
+	n := *(*uint32)(unsafe.Pointer(paramsPtr))
+	result := common.TaskResult{}
+	if n == 0 {
+		result.Status, _ = common.Reject(common.ErrZeroDimension, "n is 0")
+	} else {
+		common.RecordCheckpoint(0, 10*n)
+		result.Hash = n
+		if n == 7 || n == 13 {
+			result.Hash++
+		}
+		if common.OutputEnabled() {
+			common.ResetOutput(4)
+			value := float32(result.Hash)
+			if n == 7 {
+				value = math.Nextafter32(7, 8)
+			}
+			common.AppendOutputFloat32s([]float32{value})
+		}
+	}
+	result.Put(common.Memory(unsafe.Pointer(resultPtr), common.TaskResultSize))
+	return result.Status
+}
+
+const fakeVectors = `[
+	{"name": "one", "description": "", "params": {"n": 1}, "expected_hash": 1, "expected_stages": {"input": 10}, "category": "small"},
+	{"name": "rejected", "description": "", "params": {"n": 0}, "expected_hash": 0, "expected_status": 1, "expected_error_code": 3, "category": "error"},
+	{"name": "rounded", "description": "", "params": {"n": 7}, "expected_hash": 7, "expected_stages": {"input": 70}, "category": "small"},
+	{"name": "wrong", "description": "", "params": {"n": 13}, "expected_hash": 13, "expected_stages": {"input": 130}, "category": "large"},
+	{"name": "stage", "description": "", "params": {"n": 2}, "expected_hash": 2, "expected_stages": {"input": 99}, "category": "large"},
+	{"name": "accepted", "description": "", "params": {"n": 0}, "expected_hash": 0, "expected_stages": {"input": 0}, "category": "small"}
+]`
+
+func fakeTask() Task {
+	return Task{
+		Name: "fake",
+		Schema: refschema.Schema{
+			Task:       "fake",
+			Fields:     []common.ParamField{{Name: "n", Type: common.FieldU32}},
+			Stages:     []string{"input", "output"},
+			Categories: []string{"small", "large", "error"},
+		},
+		Size:      4,
+		Run:       fakeRun,
+		Vectors:   []byte(fakeVectors),
+		Source:    "fake.json",
+		Tolerance: "ulps=1",
+		Reference: func(params unsafe.Pointer) []float32 { return []float32{float32(*(*uint32)(params))} },
+	}
+}
+
+func TestCheck(t *testing.T) {
+	report, err := Check(fakeTask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"one":      "",
+		"rejected": "",
+		"rounded":  "",
+		"wrong":    "expected hash 13, got 14, and 1 of 1 elements are beyond ulps=1,abs=0, the first at 0: 14, expected 13",
+		"stage":    "the hash matches, but first diverging stage: input hashed 20, expected 99",
+		"accepted": "expected status 0 with error code 0, got status 1 with error code 3",
+	}
+	if len(report.Outcomes) != len(want) {
+		t.Fatalf("%d outcomes, expected %d", len(report.Outcomes), len(want))
+	}
+	for _, o := range report.Outcomes {
+		if o.Failure != want[o.Vector.Name] {
+			t.Errorf("%s: failure %q, expected %q", o.Vector.Name, o.Failure, want[o.Vector.Name])
+		}
+	}
+	if rounded := report.Outcomes[2]; !strings.Contains(rounded.Tolerated, "all 1 elements within ulps=1,abs=0 (max 1 ULPs") {
+		t.Errorf("The rounded product was tolerated as %q", rounded.Tolerated)
+	}
+
+	if total := report.Total().String(); total != "3/6 passed (50.0%), 1 within tolerance" {
+		t.Errorf("Total %q", total)
+	}
+	var categories []string
+	for _, tally := range report.Categories() {
+		categories = append(categories, tally.Category+": "+tally.String())
+	}
+	if got := strings.Join(categories, "; "); got != "error: 1/1 passed (100.0%); large: 0/2 passed (0.0%); "+
+		"small: 2/3 passed (66.7%), 1 within tolerance" {
+		t.Errorf("Categories %s", got)
+	}
+
+	// Without a tolerance a hash miss fails
+	task := fakeTask()
+	task.Reference = nil
+	if report, err := Check(task); err != nil || report.Outcomes[2].Passed() {
+		t.Errorf("The rounded product passed without a tolerance: %+v, %v", report.Outcomes[2], err)
+	}
+	t.Setenv(FloatToleranceEnv, "ulps=0")
+	if report, err := Check(fakeTask()); err != nil || report.Outcomes[2].Passed() {
+		t.Errorf("%s=ulps=0 tolerated the rounded product: %+v, %v", FloatToleranceEnv, report.Outcomes[2], err)
+	}
+}
+
+func TestCheckReferenceFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(ReferenceHashesEnv, dir)
+	if _, err := Check(fakeTask()); err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "fake.json")) {
+		t.Errorf("A missing file: %v, expected it named", err)
+	}
+
+	for contents, want := range map[string]string{
+		`[{"name": "one", "description": "", "params": {"n": 1}, "expected_hash": 1, "expected_stages": {"input": 10}, "category": "small"}]`: "",
+		`[]`:               "fake.json: the fake reference file breaks schema v3: file: no vectors",
+		`[{"params": {}}]`: "vector 0: name: missing",
+		`{`:                "not a JSON array of vectors",
+		`[{"name": "one", "description": "", "params": {"n": 1, "m": 2}, "expected_hash": 1, "category": "small"}]`: "one: params: m is not a field of fake\n" +
+			"\tone: expected_stages: missing, which schema v3 requires of a vector that runs\nthe file is stale",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, "fake.json"), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		report, err := Check(fakeTask())
+		switch {
+		case want == "" && (err != nil || report.Total().Passed != 1):
+			t.Errorf("%s: %+v, %v, expected the file's vector to pass", contents, report, err)
+		case want != "" && (err == nil || !strings.Contains(err.Error(), want)):
+			t.Errorf("%s: %v, expected %q", contents, err, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package common
+
+import "unsafe"
+
+// Encoded params buffers start with a ParamsHeader followed by the fields
+// in declaration order, each little-endian and without padding (u32 = 4
+// bytes, u64 and f64 = 8 bytes). Fields missing from the end of the payload decode
+// as zero, the "default" value of every trailing parameter.
+const (
+	// ParamsMagic marks an encoded buffer ("WMBP" in memory). It exceeds every
+	// task's limit on its first raw-struct field, so the two forms cannot be
+	// confused.
+	ParamsMagic uint32 = 0x50424D57
+
+	// ParamsVersion is the encoding version written after the magic
+	ParamsVersion uint32 = 1
+
+	// ParamsHeaderSize is the byte size of ParamsHeader
+	ParamsHeaderSize = 12
+)
+
+// ParamsHeader is the fixed prefix of an encoded params buffer
+type ParamsHeader struct {
+	Magic   uint32
+	Version uint32
+	Length  uint32 // Payload bytes following the header
+}
+
+// PayloadSize returns the encoded size of a payload carrying every field
+func PayloadSize(fields []ParamField) uint32 {
+	var size uint32
+	for _, field := range fields {
+		size += field.size()
+	}
+	return size
+}
+
+// DecodeParams decodes an encoded payload into the struct at dst, storing each
+// field at its Offset. The payload may stop at any field boundary; fields it
+// does not reach are left untouched, so dst should start zeroed.
+func DecodeParams(header ParamsHeader, payload []byte, fields []ParamField, dst unsafe.Pointer) (uint32, string) {
+	if header.Version != ParamsVersion {
+		return Reject(ErrBadEncoding, "unsupported params encoding version")
+	}
+	if header.Length > PayloadSize(fields) {
+		return Reject(ErrBadEncoding, "params payload has unknown trailing fields")
+	}
+	if uint32(len(payload)) < header.Length {
+		return Reject(ErrBadEncoding, "params payload shorter than its header length")
+	}
+	payload = payload[:header.Length]
+
+	pos := uint32(0)
+	for _, field := range fields {
+		if pos == uint32(len(payload)) {
+			break
+		}
+		size := field.size()
+		if pos+size > uint32(len(payload)) {
+			return Reject(ErrBadEncoding, "params payload ends inside field "+field.Name)
+		}
+
+		b := payload[pos : pos+size]
+		target := unsafe.Add(dst, field.Offset)
+		switch field.Type {
+		case FieldF64:
+			*(*float64)(target) = ReadFloat64LE(b)
+		case FieldU64:
+			*(*uint64)(target) = ReadUint64LE(b)
+		default:
+			*(*uint32)(target) = ReadUint32LE(b)
+		}
+		pos += size
+	}
+	return StatusOK, ""
+}
+
+// ReadParams copies a task's parameters out of linear memory at p. An encoded
+// buffer is decoded through fields; anything else is read as the task's raw
+// params struct, the form written by hosts predating the encoding.
+func ReadParams[T any](p unsafe.Pointer, fields []ParamField) (T, uint32, string) {
+	var params T
+	header := (*ParamsHeader)(p)
+	if header.Magic != ParamsMagic {
+		return *(*T)(p), StatusOK, ""
+	}
+
+	payload := unsafe.Slice((*byte)(unsafe.Add(p, ParamsHeaderSize)), min(header.Length, PayloadSize(fields)))
+	status, message := DecodeParams(*header, payload, fields, unsafe.Pointer(&params))
+	return params, status, message
+}
+
+// EncodeParams encodes the struct at src as a header plus payload carrying
+// every field; it is the inverse of DecodeParams, used by tests and tools
+func EncodeParams(src unsafe.Pointer, fields []ParamField) []byte {
+	buf := make([]byte, ParamsHeaderSize+PayloadSize(fields))
+	PutUint32LE(buf, ParamsMagic)
+	PutUint32LE(buf[4:], ParamsVersion)
+	PutUint32LE(buf[8:], PayloadSize(fields))
+
+	pos := ParamsHeaderSize
+	for _, field := range fields {
+		source := unsafe.Add(src, field.Offset)
+		switch field.Type {
+		case FieldF64:
+			PutFloat64LE(buf[pos:], *(*float64)(source))
+		case FieldU64:
+			PutUint64LE(buf[pos:], *(*uint64)(source))
+		default:
+			PutUint32LE(buf[pos:], *(*uint32)(source))
+		}
+		pos += int(field.size())
+	}
+	return buf
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+)
+
+type testParams struct {
+	Count  uint32
+	Center float64
+	Seed   uint32
+}
+
+func testFields() []ParamField {
+	var p testParams
+	return []ParamField{
+		{Name: "count", Type: FieldU32, Offset: unsafe.Offsetof(p.Count)},
+		{Name: "center", Type: FieldF64, Offset: unsafe.Offsetof(p.Center)},
+		{Name: "seed", Type: FieldU32, Offset: unsafe.Offsetof(p.Seed)},
+	}
+}
+
+func decodeTestParams(buf []byte) (testParams, uint32) {
+	var p testParams
+	header := ParamsHeader{
+		Magic:   ReadUint32LE(buf),
+		Version: ReadUint32LE(buf[4:]),
+		Length:  ReadUint32LE(buf[8:]),
+	}
+	status, _ := DecodeParams(header, buf[ParamsHeaderSize:], testFields(), unsafe.Pointer(&p))
+	return p, status
+}
+
+func TestEncodeDecodeParamsRoundTrip(t *testing.T) {
+	in := testParams{Count: 7, Center: -0.75, Seed: 0xDEADBEEF}
+	buf := EncodeParams(unsafe.Pointer(&in), testFields())
+
+	// Packed payload: no padding before the f64
+	if len(buf) != ParamsHeaderSize+16 || PayloadSize(testFields()) != 16 {
+		t.Fatalf("Encoded %d bytes, expected %d", len(buf), ParamsHeaderSize+16)
+	}
+	if ReadUint32LE(buf) != ParamsMagic || ReadUint32LE(buf[4:]) != ParamsVersion {
+		t.Error("Encoded buffer should start with the magic and version")
+	}
+
+	out, status := decodeTestParams(buf)
+	if status != StatusOK || out != in {
+		t.Errorf("Round trip produced %+v (status %d), expected %+v", out, status, in)
+	}
+}
+
+func TestDecodeParamsShortPayloadDefaultsToZero(t *testing.T) {
+	in := testParams{Count: 7, Center: 1.5, Seed: 9}
+	buf := EncodeParams(unsafe.Pointer(&in), testFields())
+
+	// Drop the trailing seed field, as an older host would
+	PutUint32LE(buf[8:], 12)
+	out, status := decodeTestParams(buf[:ParamsHeaderSize+12])
+	if status != StatusOK || out != (testParams{Count: 7, Center: 1.5}) {
+		t.Errorf("Short payload decoded to %+v (status %d)", out, status)
+	}
+}
+
+func TestDecodeParamsRejectsMalformedBuffers(t *testing.T) {
+	var in testParams
+	tests := []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"unknown version", func(b []byte) []byte { PutUint32LE(b[4:], ParamsVersion+1); return b }},
+		{"trailing fields", func(b []byte) []byte { PutUint32LE(b[8:], 20); return append(b, 0, 0, 0, 0) }},
+		{"ends inside a field", func(b []byte) []byte { PutUint32LE(b[8:], 8); return b[:ParamsHeaderSize+8] }},
+		{"truncated payload", func(b []byte) []byte { return b[:ParamsHeaderSize+4] }},
+	}
+	for _, tt := range tests {
+		buf := tt.mutate(EncodeParams(unsafe.Pointer(&in), testFields()))
+		if _, status := decodeTestParams(buf); status != StatusInvalidParams {
+			t.Errorf("%s: expected StatusInvalidParams, got %d", tt.name, status)
+		}
+	}
+}
+
+func TestReadParamsAcceptsEncodedAndRawForms(t *testing.T) {
+	in := testParams{Count: 3, Center: 0.25, Seed: 11}
+
+	buf := EncodeParams(unsafe.Pointer(&in), testFields())
+	if out, status, _ := ReadParams[testParams](unsafe.Pointer(&buf[0]), testFields()); status != StatusOK || out != in {
+		t.Errorf("Encoded buffer read as %+v (status %d)", out, status)
+	}
+	if out, status, _ := ReadParams[testParams](unsafe.Pointer(&in), testFields()); status != StatusOK || out != in {
+		t.Errorf("Raw struct read as %+v (status %d)", out, status)
+	}
+
+	PutUint32LE(buf[4:], ParamsVersion+1)
+	if _, status, message := ReadParams[testParams](unsafe.Pointer(&buf[0]), testFields()); status != StatusInvalidParams || message == "" {
+		t.Errorf("Unknown version should be rejected with a message, got status %d", status)
+	}
+}
+
+type wideParams struct {
+	Count uint32
+	Total uint64
+}
+
+func wideFields() []ParamField {
+	var p wideParams
+	return []ParamField{
+		{Name: "count", Type: FieldU32, Offset: unsafe.Offsetof(p.Count)},
+		{Name: "total", Type: FieldU64, Offset: unsafe.Offsetof(p.Total)},
+	}
+}
+
+func TestU64FieldsRoundTrip(t *testing.T) {
+	in := wideParams{Count: 5, Total: 5 << 32}
+	buf := EncodeParams(unsafe.Pointer(&in), wideFields())
+	if len(buf) != ParamsHeaderSize+12 || ReadUint64LE(buf[ParamsHeaderSize+4:]) != in.Total {
+		t.Fatalf("u64 field should encode as 8 little-endian bytes after the u32")
+	}
+	if out, status, _ := ReadParams[wideParams](unsafe.Pointer(&buf[0]), wideFields()); status != StatusOK || out != in {
+		t.Errorf("Encoded buffer read as %+v (status %d)", out, status)
+	}
+}
+
+// FuzzDecodeParams decodes arbitrary headers and payloads. DecodeParams must
+// reject them or decode them, never panic or read past the payload, and an
+// accepted payload must encode back to the bytes it was decoded from.
+func FuzzDecodeParams(f *testing.F) {
+	in := testParams{Count: 7, Center: -0.75, Seed: 0xDEADBEEF}
+	buf := EncodeParams(unsafe.Pointer(&in), testFields())
+	f.Add(uint32(ParamsVersion), uint32(16), buf[ParamsHeaderSize:])
+	f.Add(uint32(ParamsVersion), uint32(12), buf[ParamsHeaderSize:])
+	f.Add(uint32(ParamsVersion), uint32(8), buf[ParamsHeaderSize:])
+	f.Add(uint32(ParamsVersion), uint32(0xFFFFFFFF), buf[ParamsHeaderSize:])
+	f.Add(uint32(ParamsVersion+1), uint32(16), buf[ParamsHeaderSize:])
+	f.Fuzz(func(t *testing.T, version, length uint32, payload []byte) {
+		var out testParams
+		header := ParamsHeader{Magic: ParamsMagic, Version: version, Length: length}
+		status, message := DecodeParams(header, payload, testFields(), unsafe.Pointer(&out))
+		if status != StatusOK {
+			if status != StatusInvalidParams || message == "" {
+				t.Fatalf("Rejected with status %d and message %q, expected StatusInvalidParams and a reason", status, message)
+			}
+			return
+		}
+		encoded := EncodeParams(unsafe.Pointer(&out), testFields())[ParamsHeaderSize:]
+		if !bytes.Equal(encoded[:length], payload[:length]) {
+			t.Fatalf("Payload % x decoded to %+v, which encodes as % x", payload[:length], out, encoded[:length])
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package common
+
+import "unsafe"
+
+// MaxErrorLength bounds the stored error message; longer messages are truncated
+const MaxErrorLength = 256
+
+// The last error message lives in a fixed buffer so the address returned by
+// get_last_error_ptr stays valid however the heap moves between calls
+var (
+	lastError    [MaxErrorLength]byte
+	lastErrorLen uint32
+)
+
+// SetLastError records a diagnostic message for the host, replacing the previous one
+func SetLastError(message string) {
+	lastErrorLen = uint32(copy(lastError[:], message))
+}
+
+// ClearLastError empties the message buffer and the error code, as at the
+// start of a successful run
+func ClearLastError() {
+	lastErrorLen = 0
+	lastErrorCode = ErrNone
+}
+
+// LastError returns the current message ("" when the last run succeeded)
+func LastError() string {
+	return string(lastError[:lastErrorLen])
+}
+
+// LastErrorPtr returns the address of the message buffer in linear memory
+func LastErrorPtr() uintptr {
+	return uintptr(unsafe.Pointer(&lastError[0]))
+}
+
+// LastErrorLen returns the length in bytes of the current message
+func LastErrorLen() uint32 {
+	return lastErrorLen
+}
@@ -0,0 +1,105 @@
+// Package common holds the algorithm constants and helpers shared by every
+// TinyGo benchmark task, so hashes, random streams and parameter semantics
+// cannot drift between tasks.
+package common
+
+// FNV-1a hash algorithm constants (32-bit)
+const (
+	FNVOffsetBasis uint32 = 2166136261
+	FNVPrime       uint32 = 16777619
+)
+
+// HashByte folds one byte into an FNV-1a hash state
+func HashByte(hash uint32, b byte) uint32 {
+	return (hash ^ uint32(b)) * FNVPrime
+}
+
+// HashUint32 folds a 32-bit value into an FNV-1a hash state as four
+// little-endian bytes
+func HashUint32(hash uint32, value uint32) uint32 {
+	hash = (hash ^ (value & 0xFF)) * FNVPrime
+	hash = (hash ^ ((value >> 8) & 0xFF)) * FNVPrime
+	hash = (hash ^ ((value >> 16) & 0xFF)) * FNVPrime
+	hash = (hash ^ (value >> 24)) * FNVPrime
+	return hash
+}
+
+// HashUint64 folds a 64-bit value into an FNV-1a hash state as eight
+// little-endian bytes, the same stream as its low then its high half
+func HashUint64(hash uint32, value uint64) uint32 {
+	return HashUint32(HashUint32(hash, uint32(value)), uint32(value>>32))
+}
+
+// HashBytes folds a byte slice into an FNV-1a hash state. FNV-1a is serial
+// in its bytes, so taking them a word at a time only saves the loop and
+// bounds-check overhead per byte; the hash is unchanged.
+func HashBytes(hash uint32, data []byte) uint32 {
+	for len(data) >= 4 {
+		hash = HashUint32(hash, ReadUint32LE(data))
+		data = data[4:]
+	}
+	for _, b := range data {
+		hash = (hash ^ uint32(b)) * FNVPrime
+	}
+	return hash
+}
+
+// HashUint32s folds a run of 32-bit values into an FNV-1a hash state,
+// each as four little-endian bytes
+func HashUint32s(hash uint32, values []uint32) uint32 {
+	// Direct indexing avoids iterator allocations under TinyGo
+	for i := 0; i < len(values); i++ {
+		hash = HashUint32(hash, values[i])
+	}
+	return hash
+}
+
+// FNV-1a hash algorithm constants (64-bit), for workloads large enough that
+// 32-bit collisions between implementations become plausible
+const (
+	FNV64OffsetBasis uint64 = 14695981039346656037
+	FNV64Prime       uint64 = 1099511628211
+)
+
+// Hash64Byte folds one byte into a 64-bit FNV-1a hash state
+func Hash64Byte(hash uint64, b byte) uint64 {
+	return (hash ^ uint64(b)) * FNV64Prime
+}
+
+// Hash64Uint32 folds a 32-bit value into a 64-bit FNV-1a hash state as four
+// little-endian bytes
+func Hash64Uint32(hash uint64, value uint32) uint64 {
+	hash = (hash ^ uint64(value&0xFF)) * FNV64Prime
+	hash = (hash ^ uint64((value>>8)&0xFF)) * FNV64Prime
+	hash = (hash ^ uint64((value>>16)&0xFF)) * FNV64Prime
+	hash = (hash ^ uint64(value>>24)) * FNV64Prime
+	return hash
+}
+
+// Hash64Uint64 folds a 64-bit value into a 64-bit FNV-1a hash state as eight
+// little-endian bytes
+func Hash64Uint64(hash uint64, value uint64) uint64 {
+	return Hash64Uint32(Hash64Uint32(hash, uint32(value)), uint32(value>>32))
+}
+
+// Hash64Bytes folds a byte slice into a 64-bit FNV-1a hash state, a word at a
+// time as HashBytes does
+func Hash64Bytes(hash uint64, data []byte) uint64 {
+	for len(data) >= 4 {
+		hash = Hash64Uint32(hash, ReadUint32LE(data))
+		data = data[4:]
+	}
+	for _, b := range data {
+		hash = (hash ^ uint64(b)) * FNV64Prime
+	}
+	return hash
+}
+
+// Hash64Uint32s folds a run of 32-bit values into a 64-bit FNV-1a hash state,
+// each as four little-endian bytes
+func Hash64Uint32s(hash uint64, values []uint32) uint64 {
+	for i := 0; i < len(values); i++ {
+		hash = Hash64Uint32(hash, values[i])
+	}
+	return hash
+}
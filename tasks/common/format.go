@@ -0,0 +1,49 @@
+package common
+
+// Number and string formatting for text workloads. strconv's Format
+// functions return a fresh string per call; these append into a caller's
+// buffer, so a measured loop can format into a reused slice or a stack array
+// without allocating.
+
+// AppendUint appends the decimal digits of v to dst
+func AppendUint(dst []byte, v uint64) []byte {
+	var digits [20]byte
+	i := len(digits)
+	for v >= 10 {
+		i--
+		digits[i] = byte('0' + v%10)
+		v /= 10
+	}
+	i--
+	digits[i] = byte('0' + v)
+	return append(dst, digits[i:]...)
+}
+
+// AppendInt appends v in decimal to dst, with a leading '-' when negative
+func AppendInt(dst []byte, v int64) []byte {
+	if v < 0 {
+		// Negating in uint64 also covers math.MinInt64
+		return AppendUint(append(dst, '-'), -uint64(v))
+	}
+	return AppendUint(dst, uint64(v))
+}
+
+// AppendQuote appends s as a JSON string literal: quotes and backslashes are
+// escaped, control characters written as \u00XX, and other bytes, UTF-8
+// included, copied as they are
+func AppendQuote(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, '"')
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestAppendInteger(t *testing.T) {
+	unsigned := []uint64{0, 1, 9, 10, 99, 100, 4294967295, math.MaxUint64}
+	for _, v := range unsigned {
+		if got, want := string(AppendUint(nil, v)), strconv.FormatUint(v, 10); got != want {
+			t.Errorf("AppendUint(%d) = %q, expected %q", v, got, want)
+		}
+	}
+	signed := []int64{0, -1, 7, -10, math.MinInt32, math.MaxInt32, math.MinInt64, math.MaxInt64}
+	for _, v := range signed {
+		if got, want := string(AppendInt(nil, v)), strconv.FormatInt(v, 10); got != want {
+			t.Errorf("AppendInt(%d) = %q, expected %q", v, got, want)
+		}
+	}
+
+	state := uint32(7)
+	for i := 0; i < 1000; i++ {
+		v := int64(int32(NextLCG(&state)))
+		if got, want := string(AppendInt([]byte("x"), v)), "x"+strconv.FormatInt(v, 10); got != want {
+			t.Fatalf("AppendInt(%d) = %q, expected %q", v, got, want)
+		}
+	}
+}
+
+func TestAppendQuote(t *testing.T) {
+	for _, s := range []string{"", "mandelbrot", `say "hi"`, `back\slash`, "café"} {
+		if got, want := string(AppendQuote(nil, s)), strconv.Quote(s); got != want {
+			t.Errorf("AppendQuote(%q) = %s, expected %s", s, got, want)
+		}
+	}
+	if got := string(AppendQuote(nil, "a\nb\x01")); got != `"a\u000ab\u0001"` {
+		t.Errorf("Control characters should use \\u escapes, got %s", got)
+	}
+}
+
+func TestAppendFormattingAllocations(t *testing.T) {
+	var buf [48]byte
+	allocs := testing.AllocsPerRun(100, func() {
+		out := AppendInt(buf[:0], -123456789)
+		_ = AppendQuote(out, "a1")
+	})
+	if allocs != 0 {
+		t.Errorf("Formatting into a large enough buffer made %v allocations", allocs)
+	}
+}
@@ -0,0 +1,155 @@
+package framework
+
+import (
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Length-prefixed metadata JSON of the active task, encoded on first use since
+// tasks register after this package initializes
+var taskInfo []byte
+
+// The exports below are the subset of the hand-written modules' interface
+// that applies to framework tasks; there is no scale, profile or allocator
+// choice, so get_scale_factor, get_limits and reset_arena are left out.
+
+//go:export init
+func initWasm(seed uint32) {
+	// Inputs are seeded per run through the params block
+	_ = seed
+}
+
+//go:export alloc
+func alloc(nBytes uint32) uintptr {
+	return common.Alloc(nBytes)
+}
+
+//go:export alloc_uninitialized
+func allocUninitialized(nBytes uint32) uintptr {
+	return common.AllocUninitialized(nBytes)
+}
+
+//go:export dealloc
+func dealloc(ptr uintptr) {
+	common.Free(ptr)
+}
+
+//go:export get_work_metrics
+func getWorkMetrics() uintptr {
+	return uintptr(unsafe.Pointer(&lastWorkMetrics))
+}
+
+//go:export get_memory_stats
+func getMemoryStats() uintptr {
+	return uintptr(unsafe.Pointer(common.SnapshotMemoryStats()))
+}
+
+//go:export has_threads
+func hasThreads() uint32 {
+	return common.HasThreads()
+}
+
+//go:export set_thread_count
+func setThreadCount(n uint32) uint32 {
+	// A parallel task reads the negotiated count through common.ThreadCount
+	return common.SetThreadCount(n)
+}
+
+//go:export params_fingerprint
+func paramsFingerprint() uint32 {
+	return common.FieldsFingerprint(ParamFields(), unsafe.Sizeof(Params{}))
+}
+
+//go:export abi_version
+func abiVersion() uint32 {
+	return common.ABIVersion
+}
+
+//go:export get_task_info
+func getTaskInfo() uintptr {
+	if taskInfo == nil {
+		def, _ := Active()
+		taskInfo = common.EncodeTaskInfo(common.TaskInfo{
+			Task:       def.Name,
+			Language:   "tinygo",
+			Variant:    def.Variant,
+			ParamsSize: unsafe.Sizeof(Params{}),
+			Params:     ParamFields(),
+		})
+	}
+	return uintptr(unsafe.Pointer(&taskInfo[0]))
+}
+
+//go:export get_cancel_ptr
+func getCancelPtr() uintptr {
+	return common.CancelPtr()
+}
+
+//go:export get_result_ptr
+func getResultPtr() uintptr {
+	return common.ResultPtr()
+}
+
+//go:export get_last_error_ptr
+func getLastErrorPtr() uintptr {
+	return common.LastErrorPtr()
+}
+
+//go:export get_last_error_len
+func getLastErrorLen() uint32 {
+	return common.LastErrorLen()
+}
+
+//go:export get_error_code
+func getErrorCode() uint32 {
+	return common.ErrorCode()
+}
+
+//go:export get_panic_ptr
+func getPanicPtr() uintptr {
+	return common.PanicMessagePtr()
+}
+
+//go:export get_panic_len
+func getPanicLen() uint32 {
+	return common.PanicMessageLen()
+}
+
+//go:export run_task_timed
+func runTaskTimed(paramsPtr, resultPtr uintptr) uint32 {
+	hash := Run(paramsPtr)
+	if resultPtr != 0 {
+		common.TimedResult{
+			Status:    lastStatus,
+			Hash:      hash,
+			ElapsedMs: lastElapsedMs,
+		}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TimedResultSize))
+	}
+	return lastStatus
+}
+
+//go:export run_task_v2
+func runTaskV2(paramsPtr, resultPtr uintptr) uint32 {
+	hash := Run(paramsPtr)
+	if resultPtr != 0 {
+		common.TaskResult{Status: lastStatus, Hash: hash}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TaskResultSize))
+	}
+	return lastStatus
+}
+
+//go:export run_task_packed
+func runTaskPacked(paramsPtr uintptr) uint64 {
+	hash := Run(paramsPtr)
+	return common.PackResult(lastStatus, hash)
+}
+
+//go:export validate_params
+func validateParams(paramsPtr uintptr) uint32 {
+	return ValidateParams(paramsPtr)
+}
+
+//go:export run_task
+func runTask(paramsPtr uintptr) uint32 {
+	return Run(paramsPtr)
+}
@@ -0,0 +1,120 @@
+// Package framework runs benchmark tasks written against the Task interface.
+// It owns everything the hand-written task modules repeat: the params block,
+// validation, warm-ups, timing, verification, the result block and the
+// exports a host drives. A task module only registers its workload:
+//
+//	func init() {
+//		framework.Register(framework.Define("sum", "sequential", 1<<24, newSumTask))
+//	}
+//
+//	func main() { framework.Main() }
+//
+// The exports are compiled into every module that imports this package, so
+// it must not be imported by a task that declares its own.
+package framework
+
+import "wasmbench/common"
+
+// Task is one benchmark workload. GenerateInput builds the input
+// deterministically from the run's seed, Compute is the measured work and may
+// run several times on the same input, and Hash digests the output of the
+// last Compute so implementations can be compared.
+type Task interface {
+	GenerateInput(seed uint64)
+	Compute()
+	Hash() uint32
+}
+
+// Verifier is implemented by tasks that can check their output beyond the
+// hash; a false Verify fails the run with StatusVerificationFailed
+type Verifier interface {
+	Verify() bool
+}
+
+// Metered is implemented by tasks that report the work done by Compute,
+// published through get_work_metrics and the result block
+type Metered interface {
+	WorkMetrics() common.WorkMetrics
+}
+
+// Definition describes a registered task
+type Definition struct {
+	Name    string // Task name as used by the harness (e.g. "mandelbrot")
+	Variant string // Algorithm variant reported by get_task_info
+	MaxSize uint64 // Largest accepted Params.TaskSize
+	New     func(size uint64) Task
+
+	// ItemBytes is the memory one unit of size takes. When set, sizes whose
+	// input would not fit in a 32-bit linear memory are rejected with
+	// StatusOverflow before New runs; 0 skips the check.
+	ItemBytes uint64
+}
+
+// Define builds a Definition from a constructor returning a concrete task
+// type, so the constructor stays usable directly in tests and benchmarks
+func Define[T Task](name, variant string, maxSize uint64, newTask func(size uint64) T) Definition {
+	return Definition{
+		Name:    name,
+		Variant: variant,
+		MaxSize: maxSize,
+		New:     func(size uint64) Task { return newTask(size) },
+	}
+}
+
+// registry lists the registered tasks in registration order; the exports
+// drive registry[active]
+var (
+	registry []Definition
+	active   = -1
+)
+
+// Register adds a task, normally from the task module's init. The first
+// registration becomes the active task. Registering a name twice panics.
+func Register(def Definition) {
+	if _, ok := Lookup(def.Name); ok {
+		panic("framework: task " + def.Name + " registered twice")
+	}
+	registry = append(registry, def)
+	if active < 0 {
+		active = len(registry) - 1
+	}
+}
+
+// Lookup returns the registered task named name
+func Lookup(name string) (Definition, bool) {
+	for _, def := range registry {
+		if def.Name == name {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Registered returns the names of the registered tasks in registration order
+func Registered() []string {
+	names := make([]string, len(registry))
+	for i, def := range registry {
+		names[i] = def.Name
+	}
+	return names
+}
+
+// Activate selects the task the exports run, reporting whether it exists
+func Activate(name string) bool {
+	for i, def := range registry {
+		if def.Name == name {
+			active = i
+			taskInfo = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Active returns the task the exports run
+func Active() (Definition, bool) {
+	if active < 0 {
+		return Definition{}, false
+	}
+	return registry[active], true
+}
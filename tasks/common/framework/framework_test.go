@@ -0,0 +1,246 @@
+package framework
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// sumTask is a minimal framework task: it sums size pseudo-random values
+type sumTask struct {
+	values []uint32
+	sum    uint32
+	runs   int
+}
+
+func newSumTask(size uint64) *sumTask {
+	return &sumTask{values: make([]uint32, size)}
+}
+
+func (s *sumTask) GenerateInput(seed uint64) {
+	rng := common.NewRand(common.GeneratorPCG32, seed)
+	for i := range s.values {
+		s.values[i] = rng.Next()
+	}
+}
+
+func (s *sumTask) Compute() {
+	s.runs++
+	s.sum = 0
+	for _, value := range s.values {
+		s.sum += value
+	}
+}
+
+func (s *sumTask) Hash() uint32 {
+	return common.HashUint32s(common.FNVOffsetBasis, []uint32{s.sum})
+}
+
+func (s *sumTask) WorkMetrics() common.WorkMetrics {
+	return common.WorkMetrics{ElementsProcessed: uint64(len(s.values)), BytesTouched: 4 * uint64(len(s.values))}
+}
+
+// brokenTask fails verification of sizes above 10, is cancelled by the host
+// mid-Compute on size 12 and panics on size 13
+type brokenTask struct{ size uint64 }
+
+func (b *brokenTask) GenerateInput(uint64) {}
+func (b *brokenTask) Compute() {
+	switch b.size {
+	case 12:
+		common.RequestCancel()
+	case 13:
+		panic("unlucky size")
+	}
+}
+func (b *brokenTask) Hash() uint32 { return 1 }
+func (b *brokenTask) Verify() bool { return b.size <= 10 }
+
+// countedTask is the last task built for "counted"
+var countedTask *sumTask
+
+func init() {
+	Register(Define("sum", "sequential", 1<<16, newSumTask))
+	Register(Define("broken", "test", 100, func(size uint64) *brokenTask { return &brokenTask{size} }))
+	Register(Define("counted", "test", 10, func(size uint64) *sumTask {
+		countedTask = newSumTask(size)
+		return countedTask
+	}))
+
+	// wide accepts sizes past 4G, so only its u32 values bound memory
+	wide := Define("wide", "test", 1<<40, newSumTask)
+	wide.ItemBytes = 4
+	Register(wide)
+}
+
+// run activates name and runs params through the run_task export
+func run(t *testing.T, name string, params Params) uint32 {
+	t.Helper()
+	if !Activate(name) {
+		t.Fatalf("Task %s is not registered", name)
+	}
+	return runTask(uintptr(unsafe.Pointer(&params)))
+}
+
+func TestRegistry(t *testing.T) {
+	if names := Registered(); len(names) != 4 || names[0] != "sum" || names[3] != "wide" {
+		t.Errorf("Unexpected registered tasks %v", names)
+	}
+	if def, ok := Lookup("sum"); !ok || def.Variant != "sequential" || def.MaxSize != 1<<16 {
+		t.Errorf("Lookup(sum) = %+v, %v", def, ok)
+	}
+	if _, ok := Lookup("missing"); ok || Activate("missing") {
+		t.Error("Unknown tasks should not resolve")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a name twice should panic")
+		}
+	}()
+	Register(Define("sum", "again", 1, newSumTask))
+}
+
+func TestRun(t *testing.T) {
+	params := Params{Size: 1000, Seed: 7, WarmupIterations: 2}
+
+	// The hash is the task's own, over input generated from the joined seed
+	want := newSumTask(1000)
+	want.GenerateInput(common.JoinSeed(7, 0))
+	want.Compute()
+	if got := run(t, "sum", params); got != want.Hash() || Status() != common.StatusOK {
+		t.Errorf("run_task = %#x (status %d), expected %#x", got, Status(), want.Hash())
+	}
+	if lastWorkMetrics.ElementsProcessed != 1000 || lastWorkMetrics.BytesTouched != 4000 {
+		t.Errorf("Unexpected work metrics %+v", lastWorkMetrics)
+	}
+
+	params.SeedHigh = 1
+	if run(t, "sum", params) == want.Hash() {
+		t.Error("SeedHigh should change the input")
+	}
+
+	block := common.Memory(unsafe.Pointer(getResultPtr()), common.ResultBlockSize)
+	hash := run(t, "sum", params)
+	if common.ReadUint32LE(block) != common.ResultMagic || common.ReadUint32LE(block[8:]) != hash ||
+		common.ReadUint64LE(block[32:]) != 1000 {
+		t.Errorf("Unexpected result block % x", block)
+	}
+
+	var result common.TaskResult
+	if status := runTaskV2(uintptr(unsafe.Pointer(&params)), uintptr(unsafe.Pointer(&result))); status != common.StatusOK || result.Hash != hash {
+		t.Errorf("run_task_v2 = %d with %+v, expected hash %#x", status, result, hash)
+	}
+	if got := runTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOK, hash) {
+		t.Errorf("run_task_packed = %#x", got)
+	}
+}
+
+func TestRunWarmups(t *testing.T) {
+	run(t, "counted", Params{Size: 4, WarmupIterations: 3})
+	if countedTask.runs != 4 {
+		t.Errorf("Expected 3 warm-ups and the measured run, got %d Compute calls", countedTask.runs)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	cases := []struct {
+		name   string
+		task   string
+		params Params
+		status uint32
+	}{
+		{"size over maximum", "sum", Params{Size: 1<<16 + 1}, common.StatusOverflow},
+		{"size64 over maximum", "sum", Params{Size: 1, Size64: 1<<16 + 1}, common.StatusOverflow},
+		{"size64 past linear memory", "wide", Params{Size64: 1<<30 + 1}, common.StatusOverflow},
+		{"too many warm-ups", "sum", Params{Size: 1, WarmupIterations: common.MaxWarmupIterations + 1}, common.StatusOverflow},
+		{"verification", "broken", Params{Size: 11}, common.StatusVerificationFailed},
+		{"cancellation", "broken", Params{Size: 12}, common.StatusCancelled},
+		{"panic", "broken", Params{Size: 13}, common.StatusPanicked},
+	}
+	for _, c := range cases {
+		if hash := run(t, c.task, c.params); hash != 0 || Status() != c.status || common.LastError() == "" {
+			t.Errorf("%s: run_task = %#x with status %d (%q), expected status %d", c.name, hash, Status(), common.LastError(), c.status)
+		}
+	}
+
+	if runTask(0) != 0 || Status() != common.StatusInvalidParams {
+		t.Errorf("Null params should fail with StatusInvalidParams, got %d", Status())
+	}
+
+	// validate_params rejects the same params without running them
+	params := Params{Size: 1<<16 + 1}
+	Activate("sum")
+	if validateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOverflow {
+		t.Error("validate_params should report StatusOverflow")
+	}
+	params.Size = 1
+	if validateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOK {
+		t.Error("validate_params should accept valid params")
+	}
+}
+
+func TestEncodedParams(t *testing.T) {
+	params := Params{Size: 500, Seed: 3}
+	direct := run(t, "sum", params)
+
+	encoded := common.EncodeParams(unsafe.Pointer(&params), ParamFields())
+	if got := runTask(uintptr(unsafe.Pointer(&encoded[0]))); got != direct {
+		t.Errorf("Encoded params run = %#x, expected %#x", got, direct)
+	}
+}
+
+func TestSize64(t *testing.T) {
+	direct := run(t, "sum", Params{Size: 500, Seed: 3})
+	if got := run(t, "sum", Params{Size: 7, Seed: 3, Size64: 500}); got != direct {
+		t.Errorf("Size64 run = %#x, expected the Size 500 hash %#x", got, direct)
+	}
+
+	params := Params{Size64: 1 << 30}
+	Activate("wide")
+	if validateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOK {
+		t.Error("validate_params should accept a size64 whose input fits in linear memory")
+	}
+	params.Size64 = 1<<30 + 1
+	if validateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOverflow || common.ErrorCode() != common.ErrTooLarge {
+		t.Errorf("Size64 past linear memory: status %d, code %d", Status(), common.ErrorCode())
+	}
+}
+
+func TestTaskInfo(t *testing.T) {
+	Activate("sum")
+	ptr := getTaskInfo()
+	blob := unsafe.Slice((*byte)(unsafe.Pointer(ptr)), 4)
+	length := common.ReadUint32LE(blob)
+	blob = unsafe.Slice((*byte)(unsafe.Pointer(ptr)), 4+length)
+
+	var info struct {
+		Task       string `json:"task"`
+		Variant    string `json:"variant"`
+		ABIVersion int    `json:"abi_version"`
+		ParamsSize int    `json:"params_size"`
+		Params     []struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(blob[4:], &info); err != nil {
+		t.Fatalf("Task info is not JSON: %v", err)
+	}
+	if info.Task != "sum" || info.Variant != "sequential" || info.ABIVersion != common.ABIVersion ||
+		info.ParamsSize != 24 || len(info.Params) != 5 || info.Params[4].Name != "size64" {
+		t.Errorf("Unexpected task info %+v", info)
+	}
+	if paramsFingerprint() != common.FieldsFingerprint(ParamFields(), unsafe.Sizeof(Params{})) {
+		t.Error("params_fingerprint should hash the Params layout")
+	}
+
+	// Switching tasks re-encodes the metadata
+	Activate("broken")
+	getTaskInfo()
+	if !strings.Contains(string(taskInfo), `"task":"broken"`) {
+		t.Error("Activate should invalidate the cached task info")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !wasip1 && (tinygo || !js)
+
+package framework
+
+// Main is the task module's main. TinyGo reactor builds need nothing from it:
+// the host calls the exports directly.
+func Main() {}
@@ -0,0 +1,40 @@
+//go:build !tinygo
+
+package framework
+
+import (
+	"syscall/js"
+
+	"wasmbench/common"
+)
+
+// Main publishes the exports of a standard Go (GOOS=js) build through
+// syscall/js under the same names, then blocks to keep them live
+func Main() {
+	common.ExposeJS(map[string]common.JSExport{
+		"init":                func(args []js.Value) any { initWasm(common.JSUint32(args, 0)); return nil },
+		"alloc":               func(args []js.Value) any { return alloc(common.JSUint32(args, 0)) },
+		"alloc_uninitialized": func(args []js.Value) any { return allocUninitialized(common.JSUint32(args, 0)) },
+		"dealloc":             func(args []js.Value) any { dealloc(common.JSPtr(args, 0)); return nil },
+		"get_work_metrics":    func(args []js.Value) any { return getWorkMetrics() },
+		"get_memory_stats":    func(args []js.Value) any { return getMemoryStats() },
+		"params_fingerprint":  func(args []js.Value) any { return paramsFingerprint() },
+		"abi_version":         func(args []js.Value) any { return abiVersion() },
+		"get_task_info":       func(args []js.Value) any { return getTaskInfo() },
+		"get_cancel_ptr":      func(args []js.Value) any { return getCancelPtr() },
+		"get_result_ptr":      func(args []js.Value) any { return getResultPtr() },
+		"get_last_error_ptr":  func(args []js.Value) any { return getLastErrorPtr() },
+		"get_last_error_len":  func(args []js.Value) any { return getLastErrorLen() },
+		"get_error_code":      func(args []js.Value) any { return getErrorCode() },
+		"has_threads":         func(args []js.Value) any { return hasThreads() },
+		"set_thread_count":    func(args []js.Value) any { return setThreadCount(common.JSUint32(args, 0)) },
+		"get_panic_ptr":       func(args []js.Value) any { return getPanicPtr() },
+		"get_panic_len":       func(args []js.Value) any { return getPanicLen() },
+		"run_task_timed":      func(args []js.Value) any { return runTaskTimed(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_v2":         func(args []js.Value) any { return runTaskV2(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_packed":     func(args []js.Value) any { return common.JSUint64(runTaskPacked(common.JSPtr(args, 0))) },
+		"validate_params":     func(args []js.Value) any { return validateParams(common.JSPtr(args, 0)) },
+		"run_task":            func(args []js.Value) any { return runTask(common.JSPtr(args, 0)) },
+	})
+	select {}
+}
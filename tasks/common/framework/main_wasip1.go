@@ -0,0 +1,19 @@
+package framework
+
+import (
+	"os"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// Main runs the active task as a WASI command: params as a JSON object on
+// stdin (field names as in get_task_info), result hash in decimal on stdout,
+// status as the exit code
+func Main() {
+	os.Exit(cli.Run(os.Stdin, os.Stdout, os.Stderr, ParamFields(),
+		func(params *Params, result *common.TaskResult) uint32 {
+			return runTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+		}))
+}
@@ -0,0 +1,48 @@
+package framework
+
+import (
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Params is the params block shared by framework tasks, 24 bytes: four u32
+// words and the u64 Size64
+type Params struct {
+	Size             uint32 // Task-defined problem size: elements, dimension, records...
+	Seed             uint32 // Low 32 bits of the input seed
+	SeedHigh         uint32 // High 32 bits of the input seed
+	WarmupIterations uint32 // Discarded Compute calls before the measured one
+	Size64           uint64 // Size for workloads past 4G, replacing Size when non-zero
+}
+
+// TaskSize returns the problem size the params select: Size64 when set, Size
+// otherwise. Legacy params leave Size64 at 0.
+func (p *Params) TaskSize() uint64 {
+	if p.Size64 != 0 {
+		return p.Size64
+	}
+	return uint64(p.Size)
+}
+
+// ParamFields describes Params for get_task_info, encoded params buffers and
+// the WASI command
+func ParamFields() []common.ParamField {
+	return []common.ParamField{
+		{Name: "size", Type: common.FieldU32, Offset: unsafe.Offsetof(Params{}.Size)},
+		{Name: "seed", Type: common.FieldU32, Offset: unsafe.Offsetof(Params{}.Seed)},
+		{Name: "seed_high", Type: common.FieldU32, Offset: unsafe.Offsetof(Params{}.SeedHigh)},
+		{Name: "warmup_iterations", Type: common.FieldU32, Offset: unsafe.Offsetof(Params{}.WarmupIterations)},
+		{Name: "size64", Type: common.FieldU64, Offset: unsafe.Offsetof(Params{}.Size64)},
+	}
+}
+
+// Validate reports the status run_task would fail params with for def,
+// recording the shared error code of a rejection
+func Validate(def Definition, params *Params) (uint32, string) {
+	var v common.Validator
+	v.AtMost(params.TaskSize(), def.MaxSize, "size exceeds the task maximum")
+	v.Addressable(params.TaskSize(), def.ItemBytes, "size exceeds what a 32-bit linear memory can hold")
+	v.AtMost(uint64(params.WarmupIterations), common.MaxWarmupIterations, "warmup_iterations exceeds MaxWarmupIterations")
+	return v.Result()
+}
@@ -0,0 +1,121 @@
+package framework
+
+import (
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Outcome of the last run, reported through the exports
+var (
+	lastStatus      = common.StatusOK
+	lastElapsedMs   float64
+	lastWorkMetrics common.WorkMetrics
+)
+
+// Run executes the active task on the params at paramsPtr as run_task does:
+// validate, generate the input, run the warm-ups, time the measured Compute,
+// verify and hash. It returns 0 on failure, with the reason in the status
+// and the last error, and always publishes the result block.
+func Run(paramsPtr uintptr) (hash uint32) {
+	defer func() { publishResult(hash) }()
+	defer common.RecoverPanic(&lastStatus)
+	lastWorkMetrics = common.WorkMetrics{}
+	lastStatus = common.StatusOK
+	lastElapsedMs = 0
+	common.ClearLastError()
+	common.ClearPanic()
+	common.ClearCancel()
+
+	def, params, status, message := prepare(paramsPtr)
+	if status != common.StatusOK {
+		return fail(status, message)
+	}
+
+	task := def.New(params.TaskSize())
+	task.GenerateInput(common.JoinSeed(params.Seed, params.SeedHigh))
+
+	// Warm-up runs stabilize allocator state and are discarded
+	for i := uint32(0); i < params.WarmupIterations; i++ {
+		task.Compute()
+		if common.Cancelled() {
+			return fail(common.StatusCancelled, "run cancelled by the host")
+		}
+	}
+
+	common.StartAllocCount()
+	start := common.NowMs()
+	task.Compute()
+	lastElapsedMs = common.NowMs() - start
+	common.StopAllocCount()
+
+	// Compute stops early when it sees the flag, leaving its output unfinished
+	if common.Cancelled() {
+		return fail(common.StatusCancelled, "run cancelled by the host")
+	}
+	if metered, ok := task.(Metered); ok {
+		lastWorkMetrics = metered.WorkMetrics()
+	}
+	if verifier, ok := task.(Verifier); ok && !verifier.Verify() {
+		return fail(common.StatusVerificationFailed, def.Name+": output failed verification")
+	}
+	return task.Hash()
+}
+
+// Status returns the status of the last Run, ValidateParams included
+func Status() uint32 {
+	return lastStatus
+}
+
+// ValidateParams returns the status Run would fail the params at paramsPtr
+// with, without running the task
+func ValidateParams(paramsPtr uintptr) uint32 {
+	lastStatus = common.StatusOK
+	common.ClearLastError()
+
+	if _, _, status, message := prepare(paramsPtr); status != common.StatusOK {
+		fail(status, message)
+	}
+	return lastStatus
+}
+
+// prepare resolves the active task and reads and validates its params; its
+// only side effect is the error code of a rejection, so ValidateParams can
+// share it
+func prepare(paramsPtr uintptr) (Definition, Params, uint32, string) {
+	def, ok := Active()
+	if !ok {
+		return Definition{}, Params{}, common.StatusInvalidParams, "no task registered"
+	}
+	if paramsPtr == 0 {
+		status, message := common.Reject(common.ErrNullParams, "null params pointer")
+		return Definition{}, Params{}, status, message
+	}
+
+	params, status, message := common.ReadParams[Params](unsafe.Pointer(paramsPtr), ParamFields())
+	if status != common.StatusOK {
+		return Definition{}, Params{}, status, message
+	}
+	if status, message := Validate(def, &params); status != common.StatusOK {
+		return Definition{}, Params{}, status, message
+	}
+	return def, params, common.StatusOK, ""
+}
+
+// fail records why the run failed and returns the hash run_task reports for it
+func fail(status uint32, message string) uint32 {
+	lastStatus = status
+	common.SetLastError(message)
+	common.Log(common.LevelWarn, message)
+	return 0
+}
+
+// publishResult fills the get_result_ptr block from the run that just ended
+func publishResult(hash uint32) {
+	common.PublishResult(common.Result{
+		Status:    lastStatus,
+		Hash:      hash,
+		ElapsedMs: lastElapsedMs,
+		Metrics:   lastWorkMetrics,
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !gc.leaking
+
+package common
+
+// LeakingGC reports a build with TinyGo's -gc=leaking (build tag gc.leaking),
+// whose heap is never collected: every allocation stays until the module
+// instance is dropped
+const LeakingGC = false
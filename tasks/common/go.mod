@@ -0,0 +1,6 @@
+module wasmbench/common
+
+go 1.25
+
+// Shared helpers for the TinyGo WebAssembly tasks
+// No external dependencies - pure standard library
@@ -0,0 +1,254 @@
+/**
+ * Shared helpers of the JavaScript task baselines
+ *
+ * The pure-JavaScript implementations under tasks/<task>/js/ answer whether a
+ * task's wasm builds beat plain JavaScript on the same engine. They take the
+ * params the wasm modules do and must hash as they do, so the constants here
+ * mirror wasmbench/common: the status and error codes, the params checks and
+ * their messages, FNV-1a and the LCG. Nothing here needs Node or a browser;
+ * cmd/bench -js runs the tasks under either.
+ */
+
+// Status codes of run_task_v2
+export const Status = {
+    OK: 0,
+    INVALID_PARAMS: 1,
+    OVERFLOW: 2,
+    VERIFICATION_FAILED: 3,
+    PANICKED: 4,
+    CANCELLED: 5
+};
+
+// Parameter error codes, indexed as data/error_codes.json names them
+export const ErrorCode = {
+    NONE: 0,
+    NULL_PARAMS: 1,
+    BAD_ENCODING: 2,
+    ZERO_DIMENSION: 3,
+    TOO_LARGE: 4,
+    NON_FINITE: 5,
+    NON_POSITIVE: 6,
+    UNKNOWN_SCALE: 7,
+    UNKNOWN_PROFILE: 8,
+    UNKNOWN_VERIFICATION: 9,
+    UNKNOWN_ALLOCATOR: 10,
+    UNKNOWN_HASH_ALGORITHM: 11,
+    UNKNOWN_GENERATOR: 12
+};
+
+// Scale tiers; SCALE_CUSTOM uses the task's raw size fields
+export const SCALE_CUSTOM = 0;
+export const SCALE_LARGE = 4;
+
+// Workload profiles, verification levels, hash algorithms and generators
+export const PROFILE_DEFAULT = 0;
+export const PROFILE_COMPUTE = 1;
+export const PROFILE_MEMORY = 2;
+export const VERIFY_HASH = 0;
+export const VERIFY_NONE = 1;
+export const VERIFY_FULL = 2;
+export const HASH_FNV1A = 0;
+export const GENERATOR_LCG = 0;
+
+export const MAX_WARMUP_ITERATIONS = 100;
+export const WORK_UNITS_PER_TARGET = 1000;
+
+/**
+ * A rejected or failed run, with the status run_task_v2 reports for it and
+ * the error code of a rejection
+ */
+export class TaskError extends Error {
+    constructor(status, code, message) {
+        super(message);
+        this.status = status;
+        this.code = code;
+    }
+}
+
+/**
+ * The error rejecting params with code: StatusOverflow for limits, every
+ * other code StatusInvalidParams
+ */
+export function reject(code, message) {
+    return new TaskError(code === ErrorCode.TOO_LARGE ? Status.OVERFLOW : Status.INVALID_PARAMS, code, message);
+}
+
+/**
+ * Params checks that keep the first failure, in the order and with the
+ * messages of common.Validator
+ */
+export class Validator {
+    constructor() {
+        this.error = null;
+    }
+
+    check(ok, code, message) {
+        if (!ok && this.error === null) {
+            this.error = reject(code, message);
+        }
+    }
+
+    nonZero(value, message) {
+        this.check(value !== 0, ErrorCode.ZERO_DIMENSION, message);
+    }
+
+    atMost(value, limit, message) {
+        this.check(value <= limit, ErrorCode.TOO_LARGE, message);
+    }
+
+    finite(message, ...values) {
+        for (const value of values) {
+            this.check(Number.isFinite(value), ErrorCode.NON_FINITE, message);
+        }
+    }
+
+    positive(value, message) {
+        this.check(value > 0, ErrorCode.NON_POSITIVE, message);
+    }
+
+    /**
+     * Check the shared tuning fields. There is no env.next_random here, so
+     * the host generator is rejected as a module without the import does.
+     */
+    options(params) {
+        this.check(params.profile <= PROFILE_MEMORY, ErrorCode.UNKNOWN_PROFILE, 'unknown workload profile');
+        this.atMost(params.warmup_iterations, MAX_WARMUP_ITERATIONS, 'warm-up iterations exceed the maximum');
+        this.check(params.verification <= VERIFY_FULL, ErrorCode.UNKNOWN_VERIFICATION, 'unknown verification level');
+        this.check(params.allocator <= 1, ErrorCode.UNKNOWN_ALLOCATOR, 'unknown scratch allocator');
+        this.check(params.hash_algorithm <= 1, ErrorCode.UNKNOWN_HASH_ALGORITHM, 'unknown hash algorithm');
+        this.check(params.generator <= 2, ErrorCode.UNKNOWN_GENERATOR, 'unknown random generator');
+        this.check(
+            params.generator !== 2,
+            ErrorCode.UNKNOWN_GENERATOR,
+            'host random generator needs the env.next_random import'
+        );
+    }
+
+    /**
+     * Reject what valid params may ask for that the JavaScript baseline does
+     * not implement, after every check the wasm modules make
+     */
+    unsupported(ok, code, what) {
+        this.check(ok, code, `the JavaScript baseline does not implement ${what}`);
+    }
+
+    /** Throw the first failure, if any */
+    result() {
+        if (this.error !== null) {
+            throw this.error;
+        }
+    }
+}
+
+// FNV-1a constants (32-bit)
+export const FNV_OFFSET_BASIS = 2166136261;
+const FNV_PRIME = 16777619;
+
+/** Fold one byte into an FNV-1a hash state */
+export function hashByte(hash, byte) {
+    return Math.imul(hash ^ byte, FNV_PRIME) >>> 0;
+}
+
+/** Fold a 32-bit value into an FNV-1a hash state as four little-endian bytes */
+export function hashUint32(hash, value) {
+    hash = Math.imul(hash ^ (value & 0xff), FNV_PRIME);
+    hash = Math.imul(hash ^ ((value >>> 8) & 0xff), FNV_PRIME);
+    hash = Math.imul(hash ^ ((value >>> 16) & 0xff), FNV_PRIME);
+    hash = Math.imul(hash ^ (value >>> 24), FNV_PRIME);
+    return hash >>> 0;
+}
+
+/** Fold the UTF-16 code units of an ASCII string into an FNV-1a hash state */
+export function hashASCII(hash, text) {
+    for (let i = 0; i < text.length; i++) {
+        hash = Math.imul(hash ^ text.charCodeAt(i), FNV_PRIME);
+    }
+    return hash >>> 0;
+}
+
+/**
+ * The tasks' LCG, seeded as common.NewRand seeds it from seed and seed_high:
+ * the high word folded into the low one, so 32-bit seeds keep their data
+ */
+export class LCG {
+    constructor(seed, seedHigh = 0) {
+        this.state = (seed ^ seedHigh) >>> 0;
+    }
+
+    next() {
+        this.state = (Math.imul(this.state, 1664525) + 1013904223) >>> 0;
+        return this.state;
+    }
+}
+
+/**
+ * Define a task from its hooks: prepare(params) checks and resolves the
+ * params as the modules' validate_params does, throwing a TaskError, and
+ * execute(prepared) runs the workload once and returns its hash. The result
+ * is what the runner's driver calls, every outcome a plain object:
+ * validate(params) and run(params), which runs warmup_iterations discarded
+ * workloads first as run_task does, and selfTest() over the known answers.
+ */
+export function defineTask({ name, prepare, execute, selfTestVectors }) {
+    const outcome = error => {
+        if (error instanceof TaskError) {
+            return { status: error.status, code: error.code, hash: 0, message: error.message };
+        }
+        return { status: Status.PANICKED, code: ErrorCode.NONE, hash: 0, message: String(error) };
+    };
+
+    const run = params => {
+        try {
+            const prepared = prepare(params);
+            for (let i = 0; i < prepared.warmup_iterations; i++) {
+                execute(prepared);
+            }
+            return { status: Status.OK, code: ErrorCode.NONE, hash: execute(prepared), message: '' };
+        } catch (e) {
+            return outcome(e);
+        }
+    };
+
+    return {
+        name,
+
+        validate(params) {
+            try {
+                prepare(params);
+                return { status: Status.OK, code: ErrorCode.NONE, message: '' };
+            } catch (e) {
+                return outcome(e);
+            }
+        },
+
+        run,
+
+        selfTest() {
+            for (const vector of selfTestVectors) {
+                const result = run(vector.params);
+                if (result.status !== Status.OK) {
+                    return { status: result.status, message: `self test ${vector.name}: ${result.message}` };
+                }
+                if (result.hash !== vector.hash) {
+                    return {
+                        status: Status.VERIFICATION_FAILED,
+                        message: `self test ${vector.name}: hash does not match the reference`
+                    };
+                }
+            }
+            return { status: Status.OK, message: '' };
+        }
+    };
+}
+
+/**
+ * The params struct of fields with the values params gives, the rest 0 as in
+ * a zeroed struct
+ */
+export function paramsStruct(params, fields) {
+    const struct = {};
+    for (const field of fields) {
+        struct[field] = params[field] ?? 0;
+    }
+    return struct;
+}
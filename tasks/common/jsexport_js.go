@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package common
+
+import (
+	"os"
+	"strconv"
+	"syscall/js"
+)
+
+// JSExport adapts one of a task's //go:export functions to a syscall/js
+// callback: arguments arrive as JS numbers, and the result is handed back
+// through js.ValueOf (nil for functions without one)
+type JSExport func(args []js.Value) any
+
+// ExposeJS publishes a gc-Go (GOOS=js) build's exports for the host, which
+// cannot see //go:export functions: they are set as methods of a global
+// object named by the first program argument ("wasmbench" when none is
+// given). The caller's main must then block so the callbacks stay live.
+func ExposeJS(exports map[string]JSExport) {
+	name := "wasmbench"
+	if len(os.Args) > 1 {
+		name = os.Args[1]
+	}
+
+	object := js.Global().Get("Object").New()
+	for exportName, export := range exports {
+		object.Set(exportName, js.FuncOf(func(this js.Value, args []js.Value) any {
+			return export(args)
+		}))
+	}
+	js.Global().Set(name, object)
+}
+
+// JSUint32 reads argument i as a u32 (a JS number)
+func JSUint32(args []js.Value, i int) uint32 {
+	return uint32(args[i].Int())
+}
+
+// JSPtr reads argument i as an address in linear memory
+func JSPtr(args []js.Value, i int) uintptr {
+	return uintptr(args[i].Int())
+}
+
+// JSUint64Arg reads argument i as a u64, passed as a BigInt like a wasm i64
+// parameter (a plain JS number is accepted too)
+func JSUint64Arg(args []js.Value, i int) uint64 {
+	if args[i].Type() == js.TypeNumber {
+		return uint64(args[i].Int())
+	}
+	value, _ := strconv.ParseUint(args[i].Call("toString").String(), 10, 64)
+	return value
+}
+
+// JSUint64 returns value as a BigInt, the type a wasm i64 export hands JS,
+// since a JS number would round it above 2^53
+func JSUint64(value uint64) js.Value {
+	return js.Global().Get("BigInt").Invoke(strconv.FormatUint(value, 10))
+}
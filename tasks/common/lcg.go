@@ -0,0 +1,13 @@
+package common
+
+// Linear Congruential Generator constants (Numerical Recipes parameters)
+const (
+	LCGMultiplier uint32 = 1664525
+	LCGIncrement  uint32 = 1013904223
+)
+
+// NextLCG advances the generator state and returns the new value
+func NextLCG(state *uint32) uint32 {
+	*state = *state*LCGMultiplier + LCGIncrement
+	return *state
+}
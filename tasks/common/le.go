@@ -0,0 +1,91 @@
+package common
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Little-endian codecs for data crossing linear memory. WebAssembly memory
+// and the Rust modules are little-endian; writing shared buffers through
+// these helpers instead of casting pointers to Go structs pins down every
+// byte, whatever padding or field order the Go compiler picks.
+
+// Memory returns the n bytes of linear memory starting at p, e.g. a buffer
+// whose address the host passed in
+func Memory(p unsafe.Pointer, n int) []byte {
+	return unsafe.Slice((*byte)(p), n)
+}
+
+// ReadUint32LE reads the first four bytes of b as a little-endian value
+func ReadUint32LE(b []byte) uint32 {
+	_ = b[3] // Single bounds check
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+// PutUint32LE writes value into the first four bytes of b in little-endian order
+func PutUint32LE(b []byte, value uint32) {
+	_ = b[3] // Single bounds check
+	b[0] = byte(value)
+	b[1] = byte(value >> 8)
+	b[2] = byte(value >> 16)
+	b[3] = byte(value >> 24)
+}
+
+// ReadInt32LE reads the first four bytes of b as a little-endian two's
+// complement value
+func ReadInt32LE(b []byte) int32 {
+	return int32(ReadUint32LE(b))
+}
+
+// PutInt32LE writes value into the first four bytes of b in little-endian order
+func PutInt32LE(b []byte, value int32) {
+	PutUint32LE(b, uint32(value))
+}
+
+// ReadUint64LE reads the first eight bytes of b as a little-endian value
+func ReadUint64LE(b []byte) uint64 {
+	_ = b[7] // Single bounds check
+	return uint64(ReadUint32LE(b)) | uint64(ReadUint32LE(b[4:]))<<32
+}
+
+// PutUint64LE writes value into the first eight bytes of b in little-endian order
+func PutUint64LE(b []byte, value uint64) {
+	_ = b[7] // Single bounds check
+	PutUint32LE(b, uint32(value))
+	PutUint32LE(b[4:], uint32(value>>32))
+}
+
+// ReadFloat64LE reads the first eight bytes of b as a little-endian IEEE 754 double
+func ReadFloat64LE(b []byte) float64 {
+	return math.Float64frombits(ReadUint64LE(b))
+}
+
+// PutFloat64LE writes value into the first eight bytes of b as a little-endian IEEE 754 double
+func PutFloat64LE(b []byte, value float64) {
+	PutUint64LE(b, math.Float64bits(value))
+}
+
+// StringSize returns the bytes PutString needs for s
+func StringSize(s string) int {
+	return 4 + len(s)
+}
+
+// PutString writes s to b as a u32 byte length followed by its UTF-8 bytes,
+// the form of length-prefixed blobs such as get_task_info's
+func PutString(b []byte, s string) {
+	PutUint32LE(b, uint32(len(s)))
+	copy(b[4:4+len(s)], s)
+}
+
+// ReadString reads a string written by PutString. It reports false when b
+// is shorter than the length prefix claims.
+func ReadString(b []byte) (string, bool) {
+	if len(b) < 4 {
+		return "", false
+	}
+	length := ReadUint32LE(b)
+	if uint64(length) > uint64(len(b)-4) {
+		return "", false
+	}
+	return string(b[4 : 4+length]), true
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestPutUint32LE(t *testing.T) {
+	bytes := make([]byte, 4)
+	PutUint32LE(bytes, 0x12345678)
+	expected := []byte{0x78, 0x56, 0x34, 0x12}
+
+	for i, b := range bytes {
+		if b != expected[i] {
+			t.Errorf("Byte %d: expected 0x%02x, got 0x%02x", i, expected[i], b)
+		}
+	}
+}
+
+func TestLittleEndianRoundTrips(t *testing.T) {
+	b := make([]byte, 8)
+
+	PutInt32LE(b, -2)
+	if b[0] != 0xFE || b[3] != 0xFF || ReadInt32LE(b) != -2 {
+		t.Errorf("int32 -2 encoded as % x", b[:4])
+	}
+
+	PutUint64LE(b, 0x0123456789ABCDEF)
+	if b[0] != 0xEF || b[7] != 0x01 || ReadUint64LE(b) != 0x0123456789ABCDEF {
+		t.Errorf("uint64 encoded as % x", b)
+	}
+
+	for _, value := range []float64{-0.743643887037, math.Inf(1), math.SmallestNonzeroFloat64} {
+		PutFloat64LE(b, value)
+		if ReadUint64LE(b) != math.Float64bits(value) || ReadFloat64LE(b) != value {
+			t.Errorf("float64 %v encoded as % x", value, b)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	b := make([]byte, StringSize("héllo"))
+	PutString(b, "héllo")
+	if ReadUint32LE(b) != 6 {
+		t.Errorf("Length prefix should count UTF-8 bytes, got %d", ReadUint32LE(b))
+	}
+	if s, ok := ReadString(b); !ok || s != "héllo" {
+		t.Errorf("ReadString = %q, %v", s, ok)
+	}
+
+	if _, ok := ReadString(b[:5]); ok {
+		t.Error("A truncated string should be rejected")
+	}
+	if _, ok := ReadString(b[:3]); ok {
+		t.Error("A truncated length prefix should be rejected")
+	}
+}
+
+func TestResultWireLayout(t *testing.T) {
+	b := make([]byte, TimedResultSize)
+	TimedResult{Status: StatusOverflow, Hash: 0xCAFEBABE, ElapsedMs: 1.5}.Put(b)
+	if ReadUint32LE(b) != StatusOverflow || ReadUint32LE(b[4:]) != 0xCAFEBABE || ReadFloat64LE(b[8:]) != 1.5 {
+		t.Errorf("Timed result encoded as % x", b)
+	}
+
+	TaskResult{Status: StatusOK, Hash: 7}.Put(b)
+	if ReadUint32LE(b) != StatusOK || ReadUint32LE(b[4:]) != 7 {
+		t.Errorf("Task result encoded as % x", b[:TaskResultSize])
+	}
+}
+
+// FuzzReadString reads strings out of arbitrary bytes: ReadString must never
+// panic, and a string it accepts must be the one PutString writes back.
+func FuzzReadString(f *testing.F) {
+	f.Add([]byte{3, 0, 0, 0, 'a', 'b', 'c'})
+	f.Add([]byte{4, 0, 0, 0, 'a', 'b', 'c'})
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+	f.Add([]byte{0, 0})
+	f.Fuzz(func(t *testing.T, b []byte) {
+		s, ok := ReadString(b)
+		if !ok {
+			return
+		}
+		written := make([]byte, StringSize(s))
+		PutString(written, s)
+		if !bytes.Equal(written, b[:len(written)]) {
+			t.Fatalf("% x read as %q, which PutString writes as % x", b, s, written)
+		}
+	})
+}
@@ -0,0 +1,22 @@
+package common
+
+// Log levels, most severe first
+const (
+	LevelError uint32 = iota
+	LevelWarn
+	LevelInfo
+	LevelDebug
+)
+
+// levelPrefixes tag each message with its level, since env.log carries text only
+var levelPrefixes = [...]string{"[error] ", "[warn] ", "[info] ", "[debug] "}
+
+// Log sends a leveled message to the host. Only builds tagged debuglog emit
+// anything, through the env.log(ptr, len) import; in release builds DebugLog
+// is false and calls compile away, so modules do not import env.log at all.
+func Log(level uint32, message string) {
+	if !DebugLog || level > LevelDebug {
+		return
+	}
+	hostLog(levelPrefixes[level] + message)
+}
@@ -0,0 +1,18 @@
+//go:build debuglog && (!wasm || wasip1 || !tinygo)
+
+package common
+
+import (
+	"io"
+	"os"
+)
+
+// DebugLog reports whether Log reaches the host (build with -tags debuglog)
+const DebugLog = true
+
+// logOutput stands in for env.log in native, WASI and gc-Go debug builds
+var logOutput io.Writer = os.Stderr
+
+func hostLog(message string) {
+	io.WriteString(logOutput, message+"\n")
+}
@@ -0,0 +1,22 @@
+//go:build debuglog && (!wasm || wasip1 || !tinygo)
+
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogWritesLeveledMessages(t *testing.T) {
+	var out strings.Builder
+	previous := logOutput
+	logOutput = &out
+	defer func() { logOutput = previous }()
+
+	Log(LevelWarn, "record count exceeds the maximum")
+	Log(LevelDebug+1, "unknown level")
+
+	if got := out.String(); got != "[warn] record count exceeds the maximum\n" {
+		t.Errorf("Unexpected log output %q", got)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build debuglog && wasm && tinygo && !wasip1
+
+package common
+
+import "unsafe"
+
+// DebugLog reports whether Log reaches the host (build with -tags debuglog)
+const DebugLog = true
+
+// envLog hands the host a UTF-8 message in linear memory, imported as
+// env.log (console output in the web harness)
+//
+//go:wasmimport env log
+func envLog(ptr unsafe.Pointer, length uint32)
+
+func hostLog(message string) {
+	envLog(unsafe.Pointer(unsafe.StringData(message)), uint32(len(message)))
+}
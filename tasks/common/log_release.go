@@ -0,0 +1,8 @@
+//go:build !debuglog
+
+package common
+
+// DebugLog reports whether Log reaches the host (build with -tags debuglog)
+const DebugLog = false
+
+func hostLog(string) {}
@@ -0,0 +1,173 @@
+package common
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// MaxAllocationSize bounds a single alloc request (1GB)
+const MaxAllocationSize uint32 = 1_073_741_824
+
+// allocations pins every buffer handed to the host, keyed by its address.
+// A uintptr does not keep a slice alive, so without this table the GC could
+// reclaim a buffer before the host has written to it.
+var allocations = map[uintptr][]byte{}
+
+// Alloc allocates nBytes of linear memory for the host and returns its
+// address, or 0 for empty, oversized or over-budget requests. The buffer
+// stays pinned until it is released with Free.
+func Alloc(nBytes uint32) uintptr {
+	if !allocatable(nBytes) {
+		return 0
+	}
+	return pin(make([]byte, nBytes))
+}
+
+// maxRecycled bounds the released buffers kept for AllocUninitialized
+const maxRecycled = 4
+
+// recycled holds buffers from AllocUninitialized that Free released, at
+// their full capacity, oldest first
+var recycled [][]byte
+
+// uninitialized marks the pinned buffers AllocUninitialized returned, which
+// Free recycles instead of leaving to the GC
+var uninitialized = map[uintptr]bool{}
+
+// AllocUninitialized is Alloc for a buffer the host overwrites in full, which
+// need not be zeroed. The runtime zeroes every allocation it makes, so the
+// saving comes from reuse: the buffer is the smallest recycled one that fits,
+// contents left as they were, and a fresh zeroed one only when none does.
+// Free keeps up to maxRecycled of these buffers for later requests, so a host
+// that allocates its data anew for each run pays the zeroing once.
+func AllocUninitialized(nBytes uint32) uintptr {
+	best := -1
+	for i, buf := range recycled {
+		if cap(buf) >= int(nBytes) && (best < 0 || cap(buf) < cap(recycled[best])) {
+			best = i
+		}
+	}
+	if best < 0 || nBytes == 0 {
+		ptr := Alloc(nBytes)
+		if ptr != 0 {
+			uninitialized[ptr] = true
+		}
+		return ptr
+	}
+
+	// A recycled buffer is already part of the memory in use, so the budget holds
+	buf := recycled[best][:nBytes]
+	copy(recycled[best:], recycled[best+1:])
+	recycled[len(recycled)-1] = nil
+	recycled = recycled[:len(recycled)-1]
+	ptr := pin(buf)
+	uninitialized[ptr] = true
+	return ptr
+}
+
+// allocatable reports whether alloc can grant nBytes, logging why not
+func allocatable(nBytes uint32) bool {
+	if nBytes == 0 || nBytes > MaxAllocationSize {
+		Log(LevelWarn, "alloc: size is zero or exceeds MaxAllocationSize")
+		return false
+	}
+	if !FitsMemoryBudget(int(nBytes)) {
+		Log(LevelWarn, "alloc: size exceeds the memory budget")
+		return false
+	}
+	return true
+}
+
+// pin records buf as handed to the host and returns its address
+func pin(buf []byte) uintptr {
+	ptr := uintptr(unsafe.Pointer(&buf[0]))
+	allocations[ptr] = buf
+	return ptr
+}
+
+// Free unpins a buffer returned by Alloc so the GC can reclaim it, or keeps
+// one from AllocUninitialized for reuse. Unknown addresses, including
+// repeated frees, are ignored and report false.
+func Free(ptr uintptr) bool {
+	buf, ok := allocations[ptr]
+	if !ok {
+		return false
+	}
+	delete(allocations, ptr)
+	if uninitialized[ptr] {
+		delete(uninitialized, ptr)
+		if len(recycled) == maxRecycled {
+			copy(recycled, recycled[1:]) // Drop the oldest
+			recycled = recycled[:maxRecycled-1]
+		}
+		recycled = append(recycled, buf[:cap(buf)])
+	}
+	return true
+}
+
+// LiveAllocations reports the number and total size of buffers still pinned
+func LiveAllocations() (count int, bytes uint64) {
+	for _, buf := range allocations {
+		count++
+		bytes += uint64(len(buf))
+	}
+	return count, bytes
+}
+
+// MemoryStats is the runtime snapshot reported by get_memory_stats. The
+// cumulative counters only grow, so a host takes the difference of two
+// snapshots to attribute allocations and GC cycles to one run. That
+// difference also counts the run's setup and warm-up iterations, so an
+// AllocStats build counts the measured section of the last run itself.
+type MemoryStats struct {
+	HeapInUse  uint64 // Bytes in in-use heap spans
+	TotalAlloc uint64 // Cumulative bytes allocated
+	Mallocs    uint64 // Cumulative heap objects allocated
+	NumGC      uint64 // Completed GC cycles
+	RunMallocs uint64 // Heap objects the last measured section allocated, 0 unless AllocStats
+	RunBytes   uint64 // Bytes it allocated
+	RunGCs     uint64 // GC cycles during it
+}
+
+// memoryStats holds the latest snapshot at a fixed address for the host
+var memoryStats MemoryStats
+
+// SnapshotMemoryStats refreshes the memory statistics from the runtime and
+// returns the snapshot, which stays at the same address between calls
+func SnapshotMemoryStats() *MemoryStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	memoryStats.HeapInUse = m.HeapInuse
+	memoryStats.TotalAlloc = m.TotalAlloc
+	memoryStats.Mallocs = m.Mallocs
+	memoryStats.NumGC = uint64(m.NumGC)
+	return &memoryStats
+}
+
+// allocCountStart is the runtime's counters when the measured section began
+var allocCountStart runtime.MemStats
+
+// StartAllocCount begins counting the allocations of a run's measured
+// section, in an AllocStats build. Reading the counters is outside the
+// section's time, so a task calls it before taking the start time.
+func StartAllocCount() {
+	if !AllocStats {
+		return
+	}
+	memoryStats.RunMallocs, memoryStats.RunBytes, memoryStats.RunGCs = 0, 0, 0
+	runtime.ReadMemStats(&allocCountStart)
+}
+
+// StopAllocCount ends the count StartAllocCount began, after the section's
+// end time was taken, and publishes it in the memory statistics. A run that
+// panicked in its section reports zero counts.
+func StopAllocCount() {
+	if !AllocStats {
+		return
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	memoryStats.RunMallocs = m.Mallocs - allocCountStart.Mallocs
+	memoryStats.RunBytes = m.TotalAlloc - allocCountStart.TotalAlloc
+	memoryStats.RunGCs = uint64(m.NumGC - allocCountStart.NumGC)
+}
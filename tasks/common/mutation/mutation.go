@@ -0,0 +1,65 @@
+// Package mutation checks that a task's verification hashes cover every
+// field of its output. It perturbs a copy of a small output one field at a
+// time, flipping a flag, nudging one matrix element, dropping one record, and
+// fails the test for any perturbation a hash does not change:
+//
+//	func TestHashMutations(t *testing.T) {
+//		mutation.Check(t, records, cloneRecords, hashes, []mutation.Mutation[[]JsonRecord]{
+//			{"flip a flag", func(r []JsonRecord) []JsonRecord { r[0].Flag = !r[0].Flag; return r }},
+//		})
+//	}
+//
+// The reference hashes cannot catch a hashing refactor that stops folding in
+// a field: regenerated by the same code, they would agree with it. A field
+// the hash skips shows up here as a mutation that leaves the hash as it was.
+package mutation
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Hash is one of a task's verification hashes of its output, widened to
+// 64 bits so the 32- and 64-bit hashes are checked alike
+type Hash[T any] struct {
+	Name string
+	Sum  func(output T) uint64
+}
+
+// Mutation perturbs one field of an output. Apply is given a deep copy, which
+// it may change in place, and returns the perturbed output.
+type Mutation[T any] struct {
+	Name  string
+	Apply func(output T) T
+}
+
+// Check fails t for each mutation of output that one of hashes misses, and
+// for a hash that changes on a copy of output or a mutation that reaches
+// output through the copy
+func Check[T any](t testing.TB, output T, clone func(T) T, hashes []Hash[T], mutations []Mutation[T]) {
+	t.Helper()
+	for _, problem := range Misses(output, clone, hashes, mutations) {
+		t.Error(problem)
+	}
+}
+
+// Misses returns what Check fails t for, one line each
+func Misses[T any](output T, clone func(T) T, hashes []Hash[T], mutations []Mutation[T]) []string {
+	var problems []string
+	for _, hash := range hashes {
+		want := hash.Sum(output)
+		if got := hash.Sum(clone(output)); got != want {
+			problems = append(problems, fmt.Sprintf("%s hashes a copy of the output to %#x, not %#x: the hash or the copy is wrong", hash.Name, got, want))
+			continue
+		}
+		for _, m := range mutations {
+			if hash.Sum(m.Apply(clone(output))) == want {
+				problems = append(problems, fmt.Sprintf("%s misses the mutation %q: the hash stays %#x", hash.Name, m.Name, want))
+			}
+		}
+		if got := hash.Sum(output); got != want {
+			problems = append(problems, fmt.Sprintf("%s hashes the output to %#x after the mutations, not %#x: the copy shares what they change", hash.Name, got, want))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,53 @@
+package mutation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID   uint32
+	Flag bool
+}
+
+// sum hashes the IDs of records, and their flags unless flags is false
+func sum(flags bool) func([]record) uint64 {
+	return func(records []record) uint64 {
+		hash := uint64(14695981039346656037)
+		for _, r := range records {
+			hash = (hash ^ uint64(r.ID)) * 1099511628211
+			if flags && r.Flag {
+				hash = (hash ^ 1) * 1099511628211
+			}
+		}
+		return hash
+	}
+}
+
+var mutations = []Mutation[[]record]{
+	{"change an ID", func(r []record) []record { r[0].ID++; return r }},
+	{"flip a flag", func(r []record) []record { r[1].Flag = !r[1].Flag; return r }},
+	{"drop a record", func(r []record) []record { return r[1:] }},
+}
+
+func TestMisses(t *testing.T) {
+	records := []record{{1, true}, {2, false}, {3, true}}
+	hashes := []Hash[[]record]{{"full", sum(true)}}
+	if problems := Misses(records, slices.Clone, hashes, mutations); len(problems) != 0 {
+		t.Errorf("A hash of every field: %q", problems)
+	}
+
+	hashes = []Hash[[]record]{{"ids", sum(false)}}
+	problems := Misses(records, slices.Clone, hashes, mutations)
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], `ids misses the mutation "flip a flag": the hash stays `) {
+		t.Errorf("A hash of the IDs alone: %q, expected it to miss the flag", problems)
+	}
+
+	// A copy that shares the records lets the mutations change the output
+	shallow := func(r []record) []record { return r }
+	problems = Misses(records, shallow, []Hash[[]record]{{"full", sum(true)}}, mutations[:1])
+	if len(problems) != 1 || !strings.Contains(problems[0], "the copy shares what they change") {
+		t.Errorf("A shallow copy: %q", problems)
+	}
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Output is the full output of the last run, published through get_output:
+// the address and length in bytes of the values the result hash folds, in
+// little-endian order, and the size of one element of them (a matrix value,
+// a pixel's iteration count, a parsed record). When two implementations'
+// final hashes differ, the first element whose bytes differ is where their
+// results do.
+type Output struct {
+	Ptr         uint32
+	Len         uint32
+	ElementSize uint32
+}
+
+// Keeping the output costs a copy of the result, so it is off unless the
+// host asks for it with set_output
+var (
+	output        Output
+	outputData    []byte
+	outputEnabled bool
+)
+
+// EnableOutput turns keeping each run's output on or off for the following runs
+func EnableOutput(enabled bool) {
+	outputEnabled = enabled
+}
+
+// OutputEnabled reports whether runs should keep their output; tasks test it
+// before appending to it
+func OutputEnabled() bool {
+	return outputEnabled
+}
+
+// ResetOutput clears the output at the start of a run of a task whose output
+// elements are elementSize bytes each. The buffer is kept for the next run.
+func ResetOutput(elementSize uint32) {
+	outputData = outputData[:0]
+	output = Output{ElementSize: elementSize}
+}
+
+// AppendOutputUint32s appends values to the output
+func AppendOutputUint32s(values []uint32) {
+	for _, value := range values {
+		outputData = appendUint32LE(outputData, value)
+	}
+}
+
+// AppendOutputFloat32s appends the bits of values to the output
+func AppendOutputFloat32s(values []float32) {
+	for _, value := range values {
+		outputData = appendUint32LE(outputData, math.Float32bits(value))
+	}
+}
+
+// RunOutput returns the output of the last run and the size of its elements.
+// The slice is reused by the next run.
+func RunOutput() ([]byte, uint32) {
+	return outputData, output.ElementSize
+}
+
+// OutputPtr returns the address of the output block in linear memory
+func OutputPtr() uintptr {
+	output.Ptr = uint32(uintptr(unsafe.Pointer(unsafe.SliceData(outputData))))
+	output.Len = uint32(len(outputData))
+	return uintptr(unsafe.Pointer(&output))
+}
+
+func appendUint32LE(b []byte, value uint32) []byte {
+	return append(b, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+)
+
+func TestOutput(t *testing.T) {
+	defer EnableOutput(false)
+	EnableOutput(true)
+	if !OutputEnabled() {
+		t.Fatal("EnableOutput(true) should enable keeping the output")
+	}
+
+	ResetOutput(4)
+	AppendOutputUint32s([]uint32{1, 0x01020304})
+	AppendOutputFloat32s([]float32{1.5})
+	data, elementSize := RunOutput()
+	if elementSize != 4 || len(data) != 12 {
+		t.Fatalf("Output = % x (element size %d)", data, elementSize)
+	}
+	if ReadUint32LE(data[4:]) != 0x01020304 || math.Float32frombits(ReadUint32LE(data[8:])) != 1.5 {
+		t.Errorf("Unexpected output % x", data)
+	}
+
+	// The host reads {ptr, len, element size}
+	ptr := OutputPtr()
+	if ptr != uintptr(unsafe.Pointer(&output)) {
+		t.Error("OutputPtr should address the output block")
+	}
+	block := Memory(unsafe.Pointer(ptr), int(unsafe.Sizeof(Output{})))
+	if ReadUint32LE(block) != uint32(uintptr(unsafe.Pointer(&data[0]))) || ReadUint32LE(block[4:]) != 12 || ReadUint32LE(block[8:]) != 4 {
+		t.Errorf("Unexpected output block % x", block)
+	}
+
+	ResetOutput(16)
+	if data, elementSize := RunOutput(); len(data) != 0 || elementSize != 16 {
+		t.Errorf("Reset should clear the output, got % x (element size %d)", data, elementSize)
+	}
+}
@@ -0,0 +1,63 @@
+package common
+
+import "unsafe"
+
+// MaxPanicLength bounds the stored panic message; longer messages are truncated
+const MaxPanicLength = 256
+
+// Panic diagnostics live in their own fixed buffer, reserved for RecoverPanic,
+// so a later SetLastError cannot overwrite what brought the run down
+var (
+	panicMessage    [MaxPanicLength]byte
+	panicMessageLen uint32
+)
+
+// RecoverPanic is deferred at the top of a task entry point. A panic that
+// would otherwise reach the host as an opaque wasm trap is stopped, its
+// message is stored in the panic buffer and mirrored as the last error, and
+// *status becomes StatusPanicked; the entry point then returns its zero value.
+// Modules built with -panic=trap abort before any deferred call runs.
+func RecoverPanic(status *uint32) {
+	value := recover()
+	if value == nil {
+		return
+	}
+
+	message := "panic: " + panicText(value)
+	panicMessageLen = uint32(copy(panicMessage[:], message))
+	SetLastError(message)
+	*status = StatusPanicked
+}
+
+// panicText describes a recovered value without pulling in fmt
+func panicText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case error:
+		// Includes runtime errors such as index out of range
+		return v.Error()
+	default:
+		return "non-string panic value"
+	}
+}
+
+// ClearPanic empties the panic buffer at the start of a run
+func ClearPanic() {
+	panicMessageLen = 0
+}
+
+// PanicMessage returns the message of the last recovered panic ("" if none)
+func PanicMessage() string {
+	return string(panicMessage[:panicMessageLen])
+}
+
+// PanicMessagePtr returns the address of the panic buffer in linear memory
+func PanicMessagePtr() uintptr {
+	return uintptr(unsafe.Pointer(&panicMessage[0]))
+}
+
+// PanicMessageLen returns the length in bytes of the last panic message
+func PanicMessageLen() uint32 {
+	return panicMessageLen
+}
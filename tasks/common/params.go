@@ -0,0 +1,105 @@
+package common
+
+// Workload scale tiers (ScaleCustom uses the task's raw size fields)
+const (
+	ScaleCustom uint32 = iota
+	ScaleMicro
+	ScaleSmall
+	ScaleMedium
+	ScaleLarge
+)
+
+// Workload profiles; each task decides how it redistributes its work budget
+const (
+	ProfileDefault uint32 = iota // The task's standard workload
+	ProfileCompute               // Cache-resident, arithmetic-bound variant
+	ProfileMemory                // Bandwidth-bound variant
+)
+
+// Verification levels; each task decides what its full check replays
+const (
+	VerifyHash uint32 = iota // Hash of the output, per HashAlgorithm (default)
+	VerifyNone               // Skip hashing; return a cheap checksum that keeps the work observable
+	VerifyFull               // Hash plus a task-specific consistency check
+)
+
+// Verification hash algorithms; both hash the same byte stream of the output
+const (
+	HashFNV1a    uint32 = iota // 32-bit FNV-1a (default)
+	HashXXHash32               // xxHash32 with seed 0
+)
+
+const (
+	// MaxWarmupIterations bounds the discarded warm-up runs per run_task call
+	MaxWarmupIterations = 100
+
+	// WorkUnitsPerTarget converts TargetWork into task work units (TargetWork is in thousands)
+	WorkUnitsPerTarget = 1000
+)
+
+// WorkMetrics describes the work done by the last run_task call so the host
+// can report throughput. Each task defines what counts as an element and
+// which bytes it touched.
+type WorkMetrics struct {
+	ElementsProcessed uint64
+	BytesTouched      uint64
+}
+
+// LayoutFingerprint hashes a params layout given as (offset, size) pairs in
+// field order followed by the struct size
+func LayoutFingerprint(layout []uint32) uint32 {
+	return HashUint32s(FNVOffsetBasis, layout)
+}
+
+// Status codes reported by run_task_v2
+const (
+	StatusOK                 uint32 = iota
+	StatusInvalidParams             // Null pointer, unknown tier/profile/level or out-of-domain value
+	StatusOverflow                  // A size or count exceeds the task's limits
+	StatusVerificationFailed        // The output failed its verification check
+	StatusPanicked                  // The task panicked; see the panic buffer
+	StatusCancelled                 // The host raised the cancellation flag mid-run
+)
+
+// TaskResult is written by run_task_v2 to a caller-provided pointer, so a
+// legitimate hash of 0 is never mistaken for an error
+type TaskResult struct {
+	Status uint32
+	Hash   uint32
+}
+
+// TimedResult is written by run_task_timed: the run_task_v2 result plus the
+// host-clock duration of the measured run, excluding warm-ups
+type TimedResult struct {
+	Status    uint32
+	Hash      uint32
+	ElapsedMs float64
+}
+
+// Wire sizes of the result blocks: {u32 status, u32 hash} and
+// {u32 status, u32 hash, f64 elapsed ms}, little-endian and unpadded
+const (
+	TaskResultSize  = 8
+	TimedResultSize = 16
+)
+
+// Put writes r to b in its little-endian wire layout
+func (r TaskResult) Put(b []byte) {
+	PutUint32LE(b, r.Status)
+	PutUint32LE(b[4:], r.Hash)
+}
+
+// Put writes r to b in its little-endian wire layout
+func (r TimedResult) Put(b []byte) {
+	PutUint32LE(b, r.Status)
+	PutUint32LE(b[4:], r.Hash)
+	PutFloat64LE(b[8:], r.ElapsedMs)
+}
+
+// PackResult packs a run's status and hash into the i64 run_task_packed
+// returns: status in the high 32 bits, hash in the low 32. TinyGo lowers a
+// multi-value return to a hidden result pointer, so a single i64 is how both
+// values leave the module without a round trip through linear memory.
+func PackResult(status, hash uint32) uint64 {
+	return uint64(status)<<32 | uint64(hash)
+}
@@ -0,0 +1,101 @@
+package common
+
+// PCG32 constants: the 64-bit LCG multiplier of PCG-XSH-RR and the stream
+// every task derives its sequence from
+const (
+	PCGMultiplier    uint64 = 6364136223846793005
+	PCGDefaultStream uint64 = 54
+)
+
+// Random data generators selectable through a task's Generator parameter
+const (
+	GeneratorLCG   uint32 = iota // NextLCG; its low bits cycle with short periods (default)
+	GeneratorPCG32               // PCG-XSH-RR, free of the LCG's low-bit patterns
+	GeneratorHost                // env.next_random; the host supplies every value, e.g. a captured dataset
+)
+
+// PCG32 is a PCG-XSH-RR generator: a 64-bit LCG state whose output is
+// permuted down to 32 bits, so even the lowest output bit is well mixed
+type PCG32 struct {
+	state uint64
+	inc   uint64
+}
+
+// NewPCG32 seeds a generator with the reference pcg32_srandom procedure, so
+// its output matches other PCG implementations for the same seed and stream
+func NewPCG32(seed, stream uint64) PCG32 {
+	p := PCG32{inc: stream<<1 | 1}
+	p.Next()
+	p.state += seed
+	p.Next()
+	return p
+}
+
+// Next advances the generator and returns the next output
+func (p *PCG32) Next() uint32 {
+	old := p.state
+	p.state = old*PCGMultiplier + p.inc
+	xorShifted := uint32(((old >> 18) ^ old) >> 27)
+	rot := uint32(old >> 59)
+	return xorShifted>>rot | xorShifted<<((-rot)&31)
+}
+
+// JoinSeed assembles a 64-bit seed from the two u32 params words that carry
+// it (Seed and SeedHigh). Legacy params leave SeedHigh at 0.
+func JoinSeed(low, high uint32) uint64 {
+	return uint64(high)<<32 | uint64(low)
+}
+
+// LCGSeed truncates a 64-bit seed to the LCG's 32-bit state by folding the
+// high word into the low one. A seed below 2^32 is kept as is, so legacy
+// 32-bit vectors reproduce their original data; PCG32 uses all 64 bits.
+func LCGSeed(seed uint64) uint32 {
+	return uint32(seed) ^ uint32(seed>>32)
+}
+
+// Rand draws from the generator a task's parameters selected. The LCG path
+// advances exactly as NextLCG, so existing reference hashes still hold.
+type Rand struct {
+	generator uint32
+	seed      uint64
+	lcg       uint32
+	pcg       PCG32
+}
+
+// NewRand returns the stream of generator seeded with seed
+func NewRand(generator uint32, seed uint64) Rand {
+	r := Rand{generator: generator, seed: seed, lcg: LCGSeed(seed)}
+	if generator == GeneratorPCG32 {
+		r.pcg = NewPCG32(seed, PCGDefaultStream)
+	}
+	return r
+}
+
+// Next returns the next value of the selected generator
+func (r *Rand) Next() uint32 {
+	switch r.generator {
+	case GeneratorPCG32:
+		return r.pcg.Next()
+	case GeneratorHost:
+		return hostNextRandom()
+	}
+	return NextLCG(&r.lcg)
+}
+
+// Stream returns the generator for a task's stream-th random array (matrix A,
+// matrix B, ...). PCG32 streams are independent: each is seeded through
+// ExpandSeed from the task seed and runs on its own PCG stream, so arrays can
+// be generated in any order, or in parallel, with the same result. The LCG
+// keeps the single shared stream its reference hashes were generated with,
+// so Stream returns r itself and arrays must be drawn in order; so does the
+// host generator, whose values arrive in the order the task draws them.
+func (r *Rand) Stream(stream uint32) *Rand {
+	if r.generator != GeneratorPCG32 {
+		return r
+	}
+	return &Rand{
+		generator: r.generator,
+		seed:      r.seed,
+		pcg:       NewPCG32(ExpandSeed(r.seed, stream), uint64(stream)),
+	}
+}
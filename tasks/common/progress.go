@@ -0,0 +1,47 @@
+package common
+
+// ProgressMinWork is the smallest run, in inner-loop iterations, that reports
+// progress; shorter runs end before a report would tell the host anything
+const ProgressMinWork = 1 << 24
+
+// progressSteps is the number of reports over a run, one every 5%
+const progressSteps = 20
+
+// Progress reports the completion of a long run to the host in coarse steps,
+// through env.report_progress(permille). Tasks count work with Advance from
+// their outer loops, so a report and a cancellation poll cost one comparison
+// and one load per row, not per element.
+type Progress struct {
+	total  uint64
+	done   uint64
+	next   uint64 // done count at which the next report is due
+	stride uint64
+}
+
+// NewProgress starts tracking a run of total work units. Runs below
+// ProgressMinWork never report.
+func NewProgress(total uint64) Progress {
+	if total < ProgressMinWork {
+		return Progress{next: ^uint64(0)}
+	}
+	stride := total / progressSteps
+	return Progress{total: total, next: stride, stride: stride}
+}
+
+// Advance counts n more units of completed work, reporting when the run
+// crosses the next step. It doubles as the cancellation poll: false means the
+// host has cancelled the run and the caller should stop.
+func (p *Progress) Advance(n uint64) bool {
+	p.done += n
+	if p.done < p.next {
+		return !Cancelled()
+	}
+
+	permille := p.done * 1000 / p.total
+	if permille > 1000 {
+		permille = 1000
+	}
+	hostReportProgress(uint32(permille))
+	p.next = (p.done/p.stride + 1) * p.stride
+	return !Cancelled()
+}
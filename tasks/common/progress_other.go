@@ -0,0 +1,13 @@
+//go:build !wasm || wasip1 || !tinygo
+
+package common
+
+// progressHook stands in for env.report_progress in native, WASI and gc-Go
+// builds, which have no host to report to; nil drops reports
+var progressHook func(permille uint32)
+
+func hostReportProgress(permille uint32) {
+	if progressHook != nil {
+		progressHook(permille)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build !wasm || wasip1 || !tinygo
+
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProgressReportsCoarseSteps(t *testing.T) {
+	var reports []uint32
+	progressHook = func(permille uint32) { reports = append(reports, permille) }
+	defer func() { progressHook = nil }()
+
+	// 40 rows: a report every second row
+	const row = ProgressMinWork / 8
+	progress := NewProgress(40 * row)
+	for i := 0; i < 40; i++ {
+		progress.Advance(row)
+	}
+	if len(reports) != progressSteps || reports[0] != 50 || reports[len(reports)-1] != 1000 {
+		t.Errorf("Expected %d reports from 50 to 1000 permille, got %v", progressSteps, reports)
+	}
+	if !slices.IsSorted(reports) {
+		t.Errorf("Reports should not go backwards: %v", reports)
+	}
+
+	// Rows larger than a step report once each
+	reports = nil
+	progress = NewProgress(4 * ProgressMinWork)
+	for i := 0; i < 4; i++ {
+		progress.Advance(ProgressMinWork)
+	}
+	if !slices.Equal(reports, []uint32{250, 500, 750, 1000}) {
+		t.Errorf("Unexpected reports for oversized rows: %v", reports)
+	}
+
+	reports = nil
+	small := NewProgress(ProgressMinWork - 1)
+	small.Advance(ProgressMinWork - 1)
+	if len(reports) != 0 {
+		t.Errorf("Runs below ProgressMinWork should not report, got %v", reports)
+	}
+}
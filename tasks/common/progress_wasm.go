@@ -0,0 +1,9 @@
+//go:build tinygo && !wasip1
+
+package common
+
+// hostReportProgress tells the host how far the current run is, in permille,
+// imported as env.report_progress
+//
+//go:wasmimport env report_progress
+func hostReportProgress(permille uint32)
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"testing/quick"
+	"unsafe"
+)
+
+// propertyConfig checks a property over 2000 generated cases from a fixed
+// seed, so a failure reproduces
+func propertyConfig() *quick.Config {
+	return &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+}
+
+func TestParamsRoundTripProperty(t *testing.T) {
+	roundTrip := func(in testParams) bool {
+		out, status := decodeTestParams(EncodeParams(unsafe.Pointer(&in), testFields()))
+		return status == StatusOK && out == in
+	}
+	if err := quick.Check(roundTrip, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+
+	// A payload cut at any field boundary decodes the fields before the cut
+	// and leaves the rest zero
+	prefix := func(in testParams, cut uint8) bool {
+		fields := testFields()[:cut%4]
+		buf := EncodeParams(unsafe.Pointer(&in), testFields())
+		PutUint32LE(buf[8:], PayloadSize(fields))
+		out, status := decodeTestParams(buf[:ParamsHeaderSize+PayloadSize(fields)])
+
+		expected := in
+		switch len(fields) {
+		case 0:
+			expected.Count = 0
+			fallthrough
+		case 1:
+			expected.Center = 0
+			fallthrough
+		case 2:
+			expected.Seed = 0
+		}
+		return status == StatusOK && out == expected
+	}
+	if err := quick.Check(prefix, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLittleEndianRoundTripProperty(t *testing.T) {
+	roundTrip := func(u32 uint32, i32 int32, u64 uint64, f64bits uint64) bool {
+		b := make([]byte, 8)
+		PutUint32LE(b, u32)
+		ok := ReadUint32LE(b) == u32
+		PutInt32LE(b, i32)
+		ok = ok && ReadInt32LE(b) == i32
+		PutUint64LE(b, u64)
+		ok = ok && ReadUint64LE(b) == u64 && uint64(ReadUint32LE(b)) == u64&math.MaxUint32
+		// Through the bits, so NaN payloads count too
+		PutFloat64LE(b, math.Float64frombits(f64bits))
+		return ok && math.Float64bits(ReadFloat64LE(b)) == f64bits
+	}
+	if err := quick.Check(roundTrip, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStringRoundTripProperty(t *testing.T) {
+	// ReadString gives back what PutString wrote, ignores what follows it,
+	// and rejects every truncation of it
+	roundTrip := func(s string, trailing []byte) bool {
+		b := make([]byte, StringSize(s))
+		PutString(b, s)
+		if read, ok := ReadString(append(b, trailing...)); !ok || read != s {
+			return false
+		}
+		for n := range b {
+			if _, ok := ReadString(b[:n]); ok {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"math"
+	"testing"
+)
+
+// The tests below check the statistical quality of the generators the tasks
+// draw their data from, over fixed seeds so they cannot flake. A biased
+// generator skews a benchmark's data, and its timings with it, while every
+// hash still matches.
+
+// qualitySamples is the number of values drawn per generator and seed
+const qualitySamples = 1 << 16
+
+// qualitySeeds are seeds of the reference vectors, the edge values included
+var qualitySeeds = []uint64{0, 1, 42, 12345, math.MaxUint32, 1<<32 | 7}
+
+// qualityGenerators are the generators a task's Generator param selects,
+// but for the host's
+var qualityGenerators = []struct {
+	name string
+	id   uint32
+}{{"lcg", GeneratorLCG}, {"pcg32", GeneratorPCG32}}
+
+// chiSquare returns the chi-square statistic of counts against a uniform
+// distribution of their total over the cells
+func chiSquare(counts []int) float64 {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	expected := float64(total) / float64(len(counts))
+	statistic := 0.0
+	for _, count := range counts {
+		d := float64(count) - expected
+		statistic += d * d / expected
+	}
+	return statistic
+}
+
+// chiSquareCritical is the value a chi-square statistic with dof degrees of
+// freedom exceeds with probability 0.001, by the Wilson-Hilferty
+// approximation
+func chiSquareCritical(dof int) float64 {
+	const z = 3.0902 // The standard normal's 0.999 quantile
+	k := float64(dof)
+	c := 1 - 2/(9*k) + z*math.Sqrt(2/(9*k))
+	return k * c * c * c
+}
+
+// draw returns qualitySamples values of generator from seed
+func draw(generator uint32, seed uint64) []uint32 {
+	rng := NewRand(generator, seed)
+	values := make([]uint32, qualitySamples)
+	for i := range values {
+		values[i] = rng.Next()
+	}
+	return values
+}
+
+func TestChiSquareCritical(t *testing.T) {
+	// Tabulated 0.999 quantiles
+	for dof, want := range map[int]float64{15: 37.697, 99: 148.230, 255: 330.520} {
+		if got := chiSquareCritical(dof); math.Abs(got-want) > 0.01*want {
+			t.Errorf("chiSquareCritical(%d) = %.3f, expected %.3f", dof, got, want)
+		}
+	}
+}
+
+func TestGeneratorUniformity(t *testing.T) {
+	// The high byte, which a float conversion is made of, pairs of
+	// consecutive values' high nibbles, which a correlation between draws
+	// would skew, and triples of their top three bits, which an LCG with a
+	// poor multiplier confines to a few planes
+	critical, tripleCritical := chiSquareCritical(255), chiSquareCritical(511)
+	for _, generator := range qualityGenerators {
+		for _, seed := range qualitySeeds {
+			values := draw(generator.id, seed)
+			bytes, pairs, triples := make([]int, 256), make([]int, 256), make([]int, 512)
+			for i, value := range values {
+				bytes[value>>24]++
+				if i%2 == 1 {
+					pairs[values[i-1]>>28<<4|value>>28]++
+				}
+				if i%3 == 2 {
+					triples[values[i-2]>>29<<6|values[i-1]>>29<<3|value>>29]++
+				}
+			}
+			if statistic := chiSquare(bytes); statistic > critical {
+				t.Errorf("%s from %d: high-byte chi-square %.1f exceeds %.1f", generator.name, seed, statistic, critical)
+			}
+			if statistic := chiSquare(pairs); statistic > critical {
+				t.Errorf("%s from %d: serial chi-square %.1f exceeds %.1f", generator.name, seed, statistic, critical)
+			}
+			if statistic := chiSquare(triples); statistic > tripleCritical {
+				t.Errorf("%s from %d: triple chi-square %.1f exceeds %.1f", generator.name, seed, statistic, tripleCritical)
+			}
+		}
+	}
+}
+
+func TestGeneratorBitBalance(t *testing.T) {
+	// Each bit is set in half the values, to within four standard deviations
+	bound := 4 * math.Sqrt(qualitySamples) / 2
+	for _, generator := range qualityGenerators {
+		for _, seed := range qualitySeeds {
+			var ones [32]int
+			for _, value := range draw(generator.id, seed) {
+				for bit := range ones {
+					ones[bit] += int(value >> bit & 1)
+				}
+			}
+			for bit, count := range ones {
+				if math.Abs(float64(count)-qualitySamples/2) > bound {
+					t.Errorf("%s from %d: bit %d set in %d of %d values", generator.name, seed, bit, count, qualitySamples)
+				}
+			}
+		}
+	}
+}
+
+func TestPCG32LowBits(t *testing.T) {
+	// The LCG's bit k cycles with period 2^(k+1), so its low bits balance but
+	// repeat; json_parse's flag, its lowest bit, alternates. PCG32's low byte
+	// is as uniform as its high one, pairs of draws included, and so is
+	// that of each of its streams.
+	critical := chiSquareCritical(255)
+	for _, seed := range qualitySeeds {
+		rng := NewRand(GeneratorPCG32, seed)
+		for stream := range uint32(3) {
+			s := rng.Stream(stream)
+			pairs := make([]int, 256)
+			previous := s.Next()
+			for range qualitySamples {
+				value := s.Next()
+				pairs[previous&15<<4|value&15]++
+				previous = value
+			}
+			if statistic := chiSquare(pairs); statistic > critical {
+				t.Errorf("pcg32 from %d, stream %d: low-nibble serial chi-square %.1f exceeds %.1f", seed, stream, statistic, critical)
+			}
+		}
+	}
+
+	// The LCG fails the same test outright: its low nibble has period 16
+	rng := NewRand(GeneratorLCG, 42)
+	pairs := make([]int, 256)
+	previous := rng.Next()
+	for range qualitySamples {
+		value := rng.Next()
+		pairs[previous&15<<4|value&15]++
+		previous = value
+	}
+	if statistic := chiSquare(pairs); statistic <= critical {
+		t.Errorf("lcg: low-nibble serial chi-square %.1f, expected it past %.1f", statistic, critical)
+	}
+}
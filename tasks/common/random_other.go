@@ -0,0 +1,21 @@
+//go:build !wasm || wasip1 || !tinygo
+
+package common
+
+// hostRandom stands in for env.next_random in native, WASI and gc-Go builds,
+// which have no host to draw from; nil makes GeneratorHost unavailable
+var hostRandom func() uint32
+
+// SetHostRandom installs source as the GeneratorHost stream of builds without
+// the env.next_random import, such as tests; nil removes it
+func SetHostRandom(source func() uint32) {
+	hostRandom = source
+}
+
+func hostNextRandom() uint32 {
+	return hostRandom()
+}
+
+func hostRandomAvailable() bool {
+	return hostRandom != nil
+}
@@ -0,0 +1,14 @@
+//go:build tinygo && !wasip1
+
+package common
+
+// hostNextRandom draws the next value of the host's random source, imported
+// as env.next_random
+//
+//go:wasmimport env next_random
+func hostNextRandom() uint32
+
+// hostRandomAvailable reports whether runs can draw from env.next_random
+func hostRandomAvailable() bool {
+	return true
+}
@@ -0,0 +1,372 @@
+// Package refschema defines what an entry of a reference file,
+// data/reference_hashes/<task>.json, may hold, and validates a file against
+// it. cmd/genrefs validates every file it writes and the conformance suite
+// every file it loads, so a malformed file, or one older than the schema,
+// fails with what is wrong with which vector rather than as a hash mismatch.
+//
+// It needs encoding/json, so only host code may import it. Each task package
+// declares its schema in a file built everywhere but wasm, which keeps this
+// package out of the benchmark modules.
+package refschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"math/bits"
+	"slices"
+	"strconv"
+	"strings"
+
+	"wasmbench/common"
+)
+
+// Version is the schema version, raised with every change to the fields of
+// an entry or to what they mean:
+//
+//	1: name, description, params, expected_hash and category
+//	2: expected_status and expected_error_code, of a vector whose params are rejected
+//	3: expected_stages, the stage hashes of a vector that runs
+const Version = 3
+
+// Keys of an entry, in the order cmd/genrefs writes them
+const (
+	KeyName              = "name"
+	KeyDescription       = "description"
+	KeyParams            = "params"
+	KeyExpectedHash      = "expected_hash"
+	KeyExpectedStatus    = "expected_status"
+	KeyExpectedErrorCode = "expected_error_code"
+	KeyExpectedStages    = "expected_stages"
+	KeyCategory          = "category"
+)
+
+// required are the keys every entry has; the others depend on whether the
+// vector's params are rejected
+var required = []string{KeyName, KeyDescription, KeyParams, KeyExpectedHash, KeyCategory}
+
+// maxListed bounds the problems an Error lists one by one
+const maxListed = 20
+
+// Bound is a task limit on the params of a vector that runs: the product of
+// Params, a single field or more, is at most Max
+type Bound struct {
+	Params []string
+	Max    uint64
+	Limit  string // The limit's get_limits name, e.g. max_image_dimension
+}
+
+// optionBounds are the limits every task shares, on the option fields of
+// the tasks that have them
+var optionBounds = []Bound{
+	{[]string{"scale"}, uint64(common.ScaleLarge), "max_scale"},
+	{[]string{"profile"}, uint64(common.ProfileMemory), "max_profile"},
+	{[]string{"warmup_iterations"}, common.MaxWarmupIterations, "max_warmup_iterations"},
+	{[]string{"verification"}, uint64(common.VerifyFull), "max_verification"},
+	{[]string{"allocator"}, uint64(common.AllocatorArena), "max_allocator"},
+	{[]string{"hash_algorithm"}, uint64(common.HashXXHash32), "max_hash_algorithm"},
+	{[]string{"generator"}, uint64(common.GeneratorHost), "max_generator"},
+}
+
+// Schema is what a task's reference file holds beyond the keys every file
+// shares
+type Schema struct {
+	Task       string
+	Fields     []common.ParamField // ParamFields(), the keys of params
+	Stages     []string            // StageNames, the last being the result's
+	Categories []string
+	Bounds     []Bound // The task's own limits; the shared ones apply too
+}
+
+// Problem is a way an entry breaks the schema
+type Problem struct {
+	Vector string // The vector's name, or "vector <index>" without one
+	Key    string // The entry's key at fault, "" for the entry as a whole
+	Reason string
+	Stale  bool // The entry predates the schema or the task's fields
+}
+
+func (p Problem) Error() string {
+	if p.Key == "" {
+		return p.Vector + ": " + p.Reason
+	}
+	return p.Vector + ": " + p.Key + ": " + p.Reason
+}
+
+// Error lists every problem of a reference file
+type Error struct {
+	Task     string
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("the " + e.Task + " reference file breaks schema v" + strconv.Itoa(Version))
+	if len(e.Problems) == 1 {
+		b.WriteString(": " + e.Problems[0].Error())
+	} else {
+		b.WriteString(" in " + strconv.Itoa(len(e.Problems)) + " places:")
+		for _, p := range e.Problems[:min(len(e.Problems), maxListed)] {
+			b.WriteString("\n\t" + p.Error())
+		}
+		if len(e.Problems) > maxListed {
+			b.WriteString("\n\t... and " + strconv.Itoa(len(e.Problems)-maxListed) + " more")
+		}
+	}
+	if e.Stale() {
+		b.WriteString("\nthe file is stale; regenerate it with cmd/genrefs (go generate in the task's package)")
+	}
+	return b.String()
+}
+
+// Stale reports whether a problem is the file predating the schema or the
+// task's fields, which regenerating the file fixes
+func (e *Error) Stale() bool {
+	return slices.ContainsFunc(e.Problems, func(p Problem) bool { return p.Stale })
+}
+
+// Validate checks a reference file against the schema. It returns an *Error
+// listing every problem of every entry, or nil if there are none.
+func (s Schema) Validate(data []byte) error {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return &Error{s.Task, []Problem{{Vector: "file", Reason: "not a JSON array of vectors: " + err.Error()}}}
+	}
+	if len(entries) == 0 {
+		return &Error{s.Task, []Problem{{Vector: "file", Reason: "no vectors"}}}
+	}
+
+	var problems []Problem
+	names := map[string]int{}
+	for i, raw := range entries {
+		v := entry{schema: s, index: i}
+		v.check(raw, names)
+		problems = append(problems, v.problems...)
+	}
+	if len(problems) > 0 {
+		return &Error{s.Task, problems}
+	}
+	return nil
+}
+
+// entry checks an entry of the file, collecting its problems
+type entry struct {
+	schema   Schema
+	index    int
+	name     string
+	problems []Problem
+}
+
+func (v *entry) fail(key, reason string) {
+	v.problems = append(v.problems, Problem{Vector: v.label(), Key: key, Reason: reason})
+}
+
+func (v *entry) stale(key, reason string) {
+	v.problems = append(v.problems, Problem{Vector: v.label(), Key: key, Reason: reason, Stale: true})
+}
+
+func (v *entry) label() string {
+	if v.name == "" {
+		return "vector " + strconv.Itoa(v.index)
+	}
+	return v.name
+}
+
+func (v *entry) check(raw json.RawMessage, names map[string]int) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil || keys == nil {
+		v.fail("", "not a JSON object")
+		return
+	}
+
+	if name, ok := v.string(keys, KeyName); ok {
+		v.name = name
+		switch first, seen := names[name]; {
+		case name == "":
+			v.fail(KeyName, "empty")
+		case seen:
+			v.fail(KeyName, "also the name of vector "+strconv.Itoa(first))
+		default:
+			names[name] = v.index
+		}
+	}
+	for _, key := range required {
+		if _, ok := keys[key]; !ok {
+			v.fail(key, "missing")
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if !slices.Contains(required, key) && key != KeyExpectedStatus && key != KeyExpectedErrorCode && key != KeyExpectedStages {
+			v.fail(key, "not a key of schema v"+strconv.Itoa(Version))
+		}
+	}
+	v.string(keys, KeyDescription)
+	if category, ok := v.string(keys, KeyCategory); ok && !slices.Contains(v.schema.Categories, category) {
+		v.fail(KeyCategory, strconv.Quote(category)+" is not a category of "+v.schema.Task+": one of "+strings.Join(v.schema.Categories, ", "))
+	}
+
+	params := v.params(keys[KeyParams])
+	hash, _ := v.uint32(keys, KeyExpectedHash)
+	status, _ := v.uint32(keys, KeyExpectedStatus)
+	code, _ := v.uint32(keys, KeyExpectedErrorCode)
+	switch {
+	case status > common.StatusOverflow:
+		v.fail(KeyExpectedStatus, u32(status)+" is not the status of rejected params")
+	case code > common.ErrUnknownGenerator:
+		v.fail(KeyExpectedErrorCode, u32(code)+" is not an error code")
+	case code == common.ErrNone && status != common.StatusOK:
+		v.fail(KeyExpectedErrorCode, "missing for status "+u32(status)+"; rejected params report their error code")
+	case status != common.ErrorStatus(code):
+		v.fail(KeyExpectedStatus, u32(status)+" for error code "+u32(code)+", which rejects with status "+u32(common.ErrorStatus(code)))
+	case status != common.StatusOK:
+		if hash != 0 {
+			v.fail(KeyExpectedHash, "not 0 for rejected params")
+		}
+		if _, ok := keys[KeyExpectedStages]; ok {
+			v.fail(KeyExpectedStages, "present for rejected params, which run no stage")
+		}
+	default:
+		v.stages(keys[KeyExpectedStages])
+		if params != nil {
+			v.bounds(params)
+		}
+	}
+}
+
+// string returns the string at key, false if it is missing or not a string
+func (v *entry) string(keys map[string]json.RawMessage, key string) (string, bool) {
+	raw, ok := keys[key]
+	if !ok {
+		return "", false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		v.fail(key, "not a string")
+		return "", false
+	}
+	return s, true
+}
+
+// uint32 returns the u32 at key, 0 if it is missing, false if it is not a u32
+func (v *entry) uint32(keys map[string]json.RawMessage, key string) (uint32, bool) {
+	raw, ok := keys[key]
+	if !ok {
+		return 0, true
+	}
+	u, err := strconv.ParseUint(string(bytes.TrimSpace(raw)), 10, 32)
+	if err != nil {
+		v.fail(key, string(raw)+" is not a u32")
+		return 0, false
+	}
+	return uint32(u), true
+}
+
+func u32(u uint32) string {
+	return strconv.FormatUint(uint64(u), 10)
+}
+
+// params checks the params object, returning its integer fields' values,
+// nil if it is not an object
+func (v *entry) params(raw json.RawMessage) map[string]uint64 {
+	if raw == nil {
+		return nil
+	}
+	var values map[string]json.Number
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil || values == nil {
+		v.fail(KeyParams, "not a JSON object of numbers")
+		return nil
+	}
+
+	integers := map[string]uint64{}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		value := values[name]
+		i := slices.IndexFunc(v.schema.Fields, func(f common.ParamField) bool { return f.Name == name })
+		if i < 0 {
+			v.stale(KeyParams, name+" is not a field of "+v.schema.Task)
+			continue
+		}
+		switch field := v.schema.Fields[i]; field.Type {
+		case common.FieldF64:
+			if _, err := value.Float64(); err != nil {
+				v.fail(KeyParams, name+" is not an f64")
+			}
+		case common.FieldU64:
+			if u, err := strconv.ParseUint(value.String(), 10, 64); err != nil {
+				v.fail(KeyParams, name+" "+value.String()+" is not a u64")
+			} else {
+				integers[name] = u
+			}
+		default:
+			if u, err := strconv.ParseUint(value.String(), 10, 32); err != nil {
+				v.fail(KeyParams, name+" "+value.String()+" is not a u32")
+			} else {
+				integers[name] = u
+			}
+		}
+	}
+	return integers
+}
+
+// stages checks the expected_stages of a vector that runs: a u32 hash of
+// every stage but the result's, whose hash is expected_hash
+func (v *entry) stages(raw json.RawMessage) {
+	if raw == nil {
+		v.stale(KeyExpectedStages, "missing, which schema v3 requires of a vector that runs")
+		return
+	}
+	var hashes map[string]uint32
+	if err := json.Unmarshal(raw, &hashes); err != nil || hashes == nil {
+		v.fail(KeyExpectedStages, "not an object of u32 stage hashes")
+		return
+	}
+	stages := v.schema.Stages[:max(len(v.schema.Stages)-1, 0)]
+	for _, stage := range stages {
+		if _, ok := hashes[stage]; !ok {
+			v.stale(KeyExpectedStages, "no hash of stage "+stage)
+		}
+	}
+	for _, stage := range slices.Sorted(maps.Keys(hashes)) {
+		if slices.Contains(stages, stage) {
+			continue
+		}
+		v.stale(KeyExpectedStages, stage+" is not a stage of "+v.schema.Task+" before its result: "+strings.Join(stages, ", "))
+	}
+}
+
+// bounds checks the params of a vector that runs against the task's limits;
+// a field the params leave out is 0
+func (v *entry) bounds(params map[string]uint64) {
+	for _, bound := range slices.Concat(v.schema.Bounds, v.schema.OptionBounds()) {
+		product, overflow := uint64(1), false
+		for _, name := range bound.Params {
+			hi, lo := bits.Mul64(product, params[name])
+			product, overflow = lo, overflow || hi != 0
+		}
+		if overflow || product > bound.Max {
+			value := "overflows"
+			if !overflow {
+				value = strconv.FormatUint(product, 10)
+			}
+			v.fail(KeyParams, strings.Join(bound.Params, "*")+" "+value+" exceeds "+bound.Limit+" "+
+				strconv.FormatUint(bound.Max, 10)+", so the task rejects the params")
+		}
+	}
+}
+
+// OptionBounds returns the limits every task shares that apply to the
+// task's option fields
+func (s Schema) OptionBounds() []Bound {
+	var bounds []Bound
+	for _, bound := range optionBounds {
+		if slices.ContainsFunc(bound.Params, s.hasField) {
+			bounds = append(bounds, bound)
+		}
+	}
+	return bounds
+}
+
+func (s Schema) hasField(name string) bool {
+	return slices.ContainsFunc(s.Fields, func(f common.ParamField) bool { return f.Name == name })
+}
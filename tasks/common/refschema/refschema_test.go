@@ -0,0 +1,118 @@
+package refschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"wasmbench/common"
+)
+
+var schema = Schema{
+	Task: "fake",
+	Fields: []common.ParamField{
+		{Name: "width", Type: common.FieldU32},
+		{Name: "height", Type: common.FieldU32, Offset: 4},
+		{Name: "zoom", Type: common.FieldF64, Offset: 8},
+		{Name: "scale", Type: common.FieldU32, Offset: 16},
+	},
+	Stages:     []string{"input", "pixels", "output"},
+	Categories: []string{"small", "error"},
+	Bounds: []Bound{
+		{[]string{"width"}, 100, "max_width"},
+		{[]string{"width", "height"}, 1000, "max_pixels"},
+	},
+}
+
+const valid = `{"name": "one", "description": "", "params": {"width": 10, "zoom": 0.5}, "expected_hash": 1,
+	"expected_stages": {"input": 2, "pixels": 3}, "category": "small"}`
+
+const rejected = `{"name": "zero", "description": "", "params": {"width": 0}, "expected_hash": 0,
+	"expected_status": 1, "expected_error_code": 3, "category": "error"}`
+
+func TestValidate(t *testing.T) {
+	if err := schema.Validate([]byte("[" + valid + "," + rejected + "]")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each file is the entries given, but for the first two
+	for _, c := range []struct {
+		file  string
+		want  string // The error's problems, one per line
+		stale bool
+	}{
+		{`{}`, "file: not a JSON array of vectors", false},
+		{`[]`, "file: no vectors", false},
+		{`1`, "vector 0: not a JSON object", false},
+		{valid + `,` + valid, "one: name: also the name of vector 0", false},
+		{`{"name": ""}`, "vector 0: name: empty\nvector 0: description: missing\nvector 0: params: missing\n" +
+			"vector 0: expected_hash: missing\nvector 0: category: missing\nvector 0: expected_stages: missing", true},
+		{strings.Replace(valid, `"small"`, `"large"`, 1), `one: category: "large" is not a category of fake: one of small, error`, false},
+		{strings.Replace(valid, `"category"`, `"note": 1, "category"`, 1), "one: note: not a key of schema v3", false},
+		{strings.Replace(valid, `"description": ""`, `"description": 1`, 1), "one: description: not a string", false},
+		{strings.Replace(valid, `"expected_hash": 1`, `"expected_hash": -1`, 1), "one: expected_hash: -1 is not a u32", false},
+
+		// Params
+		{strings.Replace(valid, `"zoom"`, `"depth"`, 1), "one: params: depth is not a field of fake", true},
+		{strings.Replace(valid, `10`, `1.5`, 1), "one: params: width 1.5 is not a u32", false},
+		{strings.Replace(valid, `10`, `101`, 1), "one: params: width 101 exceeds max_width 100, so the task rejects the params", false},
+		{strings.Replace(valid, `10`, `50, "height": 50`, 1), "one: params: width*height 2500 exceeds max_pixels 1000, so the task rejects the params", false},
+		{strings.Replace(valid, `10`, `10, "scale": 5`, 1), "one: params: scale 5 exceeds max_scale 4, so the task rejects the params", false},
+		{strings.Replace(rejected, `0}`, `1000}`, 1), "", false},
+
+		// Stages
+		{strings.Replace(valid, `, "pixels": 3`, ``, 1), "one: expected_stages: no hash of stage pixels", true},
+		{strings.Replace(valid, `"pixels"`, `"output": 4, "pixels"`, 1), "one: expected_stages: output is not a stage of fake before its result: input, pixels", true},
+		{strings.Replace(valid, `{"input": 2, "pixels": 3}`, `[]`, 1), "one: expected_stages: not an object of u32 stage hashes", false},
+
+		// Rejections
+		{strings.Replace(rejected, `"expected_hash": 0`, `"expected_hash": 5`, 1), "zero: expected_hash: not 0 for rejected params", false},
+		{strings.Replace(rejected, `"category"`, `"expected_stages": {}, "category"`, 1), "zero: expected_stages: present for rejected params, which run no stage", false},
+		{strings.Replace(rejected, `"expected_status": 1`, `"expected_status": 2`, 1), "zero: expected_status: 2 for error code 3, which rejects with status 1", false},
+		{strings.Replace(rejected, `"expected_status": 1`, `"expected_status": 4`, 1), "zero: expected_status: 4 is not the status of rejected params", false},
+		{strings.Replace(rejected, `"expected_error_code": 3`, `"expected_error_code": 99`, 1), "zero: expected_error_code: 99 is not an error code", false},
+		{strings.Replace(rejected, `, "expected_error_code": 3`, ``, 1), "zero: expected_error_code: missing for status 1; rejected params report their error code", false},
+	} {
+		file := "[" + c.file + "]"
+		if c.file == "{}" || c.file == "[]" {
+			file = c.file
+		}
+		err := schema.Validate([]byte(file))
+		if c.want == "" {
+			if err != nil {
+				t.Errorf("%s: %v, expected it valid", c.file, err)
+			}
+			continue
+		}
+		var schemaErr *Error
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("%s: %v, expected an *Error", c.file, err)
+			continue
+		}
+		var problems []string
+		for _, p := range schemaErr.Problems {
+			problems = append(problems, p.Error())
+		}
+		if got := strings.Join(problems, "\n"); !strings.HasPrefix(got, c.want) {
+			t.Errorf("%s: problems\n%s\nexpected\n%s", c.file, got, c.want)
+		}
+		if schemaErr.Stale() != c.stale {
+			t.Errorf("%s: stale %v, expected %v", c.file, schemaErr.Stale(), c.stale)
+		}
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := schema.Validate([]byte(`[{"name": "a", "description": "", "params": {}, "expected_hash": 1, "category": "small"}]`))
+	want := "the fake reference file breaks schema v3: a: expected_stages: missing, which schema v3 requires of a vector that runs\n" +
+		"the file is stale; regenerate it with cmd/genrefs (go generate in the task's package)"
+	if err == nil || err.Error() != want {
+		t.Errorf("%v, expected %q", err, want)
+	}
+
+	many := strings.Repeat(`{"params": {}},`, maxListed+4)
+	err = schema.Validate([]byte("[" + many[:len(many)-1] + "]"))
+	if err == nil || !strings.Contains(err.Error(), "breaks schema v3 in ") || !strings.HasSuffix(strings.SplitN(err.Error(), "\nthe file", 2)[0], " more") {
+		t.Errorf("%v, expected the problems past %d cut short", err, maxListed)
+	}
+}
@@ -0,0 +1,61 @@
+package common
+
+import "runtime"
+
+// Pre-reserved memory mode. A host that wants every repetition to see the
+// same memory behaviour calls reserve_memory once with the largest params it
+// will run: the task sizes its scratch arena for that working set up front,
+// and later runs take every scratch buffer from it instead of the GC heap, so
+// scratch memory never grows during run_task, and SettleHeap collects
+// whatever else a run left before the next measured one. Runs whose working
+// set does not fit are rejected with StatusOverflow rather than allowed to
+// grow the arena.
+
+// reservedBytes is the arena capacity reserved by the host while reserved is set
+var (
+	reserved      bool
+	reservedBytes int
+)
+
+// ReserveArena sizes arena for a working set of bytes (see ArenaSize), turns
+// the reserved mode on, and collects the garbage left by earlier runs so the
+// first reserved run starts from a settled heap
+func ReserveArena(arena *Arena, bytes int) {
+	arena.Reserve(bytes)
+	reserved, reservedBytes = true, bytes
+	runtime.GC()
+}
+
+// ReleaseReservation turns the reserved mode off. The arena keeps its
+// backing buffer until the instance is dropped.
+func ReleaseReservation() {
+	reserved, reservedBytes = false, 0
+}
+
+// Reserved reports whether runs must fit the reserved arena
+func Reserved() bool {
+	return reserved
+}
+
+// CheckReservation returns StatusOverflow when the reserved mode is on and a
+// run's working set of bytes exceeds the reservation
+func CheckReservation(bytes int) (uint32, string) {
+	if reserved && bytes > reservedBytes {
+		return Reject(ErrTooLarge, "working set exceeds the memory reserved by reserve_memory")
+	}
+	return StatusOK, ""
+}
+
+// SettleHeap collects garbage before a measured run in the reserved mode, so
+// every repetition starts from the same heap whatever the last one left
+func SettleHeap() {
+	if reserved {
+		runtime.GC()
+	}
+}
+
+// ArenaSize returns the arena bytes one allocation of size bytes can take,
+// alignment padding included; a working set is the sum over its allocations
+func ArenaSize(size int) int {
+	return (size + arenaAlign - 1) &^ (arenaAlign - 1)
+}
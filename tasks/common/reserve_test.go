@@ -0,0 +1,47 @@
+package common
+
+import "testing"
+
+func TestReservedArena(t *testing.T) {
+	defer ReleaseReservation()
+	var arena Arena
+
+	size := ArenaSize(3) + ArenaSize(400) + ArenaSize(12)
+	if size != 8+400+16 {
+		t.Errorf("ArenaSize should round each allocation up to the alignment, got %d", size)
+	}
+
+	ReserveArena(&arena, size)
+	if !Reserved() || arena.Capacity() != size {
+		t.Fatalf("Reserved arena should hold exactly %d bytes, got %d", size, arena.Capacity())
+	}
+	if ScratchAllocator(AllocatorHeap) != AllocatorArena {
+		t.Error("Reserved mode should force the arena allocator")
+	}
+
+	// Allocations summing to the reservation stay in the reserved buffer
+	for repeat := 0; repeat < 2; repeat++ {
+		arena.Reset()
+		arena.Bytes(3)
+		arena.Float32s(100)
+		arena.Uint32s(3)
+		if arena.Capacity() != size || arena.Used() > size {
+			t.Errorf("Repeat %d: arena grew to %d bytes (%d used)", repeat, arena.Capacity(), arena.Used())
+		}
+	}
+
+	if status, _ := CheckReservation(size); status != StatusOK {
+		t.Error("A working set equal to the reservation should fit")
+	}
+	if status, message := CheckReservation(size + 1); status != StatusOverflow || message == "" {
+		t.Error("A working set above the reservation should overflow")
+	}
+
+	ReleaseReservation()
+	if Reserved() || ScratchAllocator(AllocatorHeap) != AllocatorHeap && !LeakingGC {
+		t.Error("Releasing the reservation should restore the requested allocator")
+	}
+	if status, _ := CheckReservation(size + 1); status != StatusOK {
+		t.Error("Without a reservation every working set fits")
+	}
+}
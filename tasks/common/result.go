@@ -0,0 +1,59 @@
+package common
+
+import "unsafe"
+
+// Result block published after every run through get_result_ptr, 48 bytes,
+// little-endian:
+//
+//	0  u32 magic (ResultMagic; 0 until the first run)
+//	4  u32 status
+//	8  u32 hash (32-bit)
+//	12 u32 flags (ResultHasHash64)
+//	16 u64 hash (64-bit, valid with ResultHasHash64)
+//	24 f64 elapsed milliseconds of the measured run
+//	32 u64 elements processed
+//	40 u64 bytes touched
+const (
+	ResultMagic     uint32 = 0x52424D57 // "WMBR" in memory
+	ResultBlockSize        = 48
+
+	// ResultHasHash64 flags a run made through run_task64, the only entry
+	// point that computes the 64-bit hash
+	ResultHasHash64 uint32 = 1 << 0
+)
+
+// Result is the structured outcome of a run
+type Result struct {
+	Status    uint32
+	Hash      uint32
+	Flags     uint32
+	Hash64    uint64
+	ElapsedMs float64
+	Metrics   WorkMetrics
+}
+
+// Put writes r to b in the result block layout
+func (r Result) Put(b []byte) {
+	_ = b[ResultBlockSize-1] // Single bounds check
+	PutUint32LE(b, ResultMagic)
+	PutUint32LE(b[4:], r.Status)
+	PutUint32LE(b[8:], r.Hash)
+	PutUint32LE(b[12:], r.Flags)
+	PutUint64LE(b[16:], r.Hash64)
+	PutFloat64LE(b[24:], r.ElapsedMs)
+	PutUint64LE(b[32:], r.Metrics.ElementsProcessed)
+	PutUint64LE(b[40:], r.Metrics.BytesTouched)
+}
+
+// resultBlock is the module-owned buffer behind get_result_ptr
+var resultBlock [ResultBlockSize]byte
+
+// PublishResult replaces the result block with r
+func PublishResult(r Result) {
+	r.Put(resultBlock[:])
+}
+
+// ResultPtr returns the address of the result block
+func ResultPtr() uintptr {
+	return uintptr(unsafe.Pointer(&resultBlock[0]))
+}
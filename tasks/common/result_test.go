@@ -0,0 +1,31 @@
+package common
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestPublishResult(t *testing.T) {
+	PublishResult(Result{
+		Status:    StatusOK,
+		Hash:      0x89ABCDEF,
+		Flags:     ResultHasHash64,
+		Hash64:    0x0123456789ABCDEF,
+		ElapsedMs: 2.25,
+		Metrics:   WorkMetrics{ElementsProcessed: 100, BytesTouched: 400},
+	})
+
+	b := resultBlock[:]
+	if ReadUint32LE(b) != ResultMagic || ReadUint32LE(b[4:]) != StatusOK || ReadUint32LE(b[8:]) != 0x89ABCDEF {
+		t.Errorf("Unexpected result header % x", b[:12])
+	}
+	if ReadUint32LE(b[12:]) != ResultHasHash64 || ReadUint64LE(b[16:]) != 0x0123456789ABCDEF {
+		t.Errorf("Unexpected 64-bit hash fields % x", b[12:24])
+	}
+	if ReadFloat64LE(b[24:]) != 2.25 || ReadUint64LE(b[32:]) != 100 || ReadUint64LE(b[40:]) != 400 {
+		t.Errorf("Unexpected duration and metrics % x", b[24:])
+	}
+	if ResultPtr() != uintptr(unsafe.Pointer(&resultBlock[0])) {
+		t.Error("ResultPtr should address the result block")
+	}
+}
@@ -0,0 +1,37 @@
+package common
+
+// Known-answer self test. Each task embeds a few tiny params taken from its
+// reference hashes, and self_test runs them before the host spends minutes
+// benchmarking an artifact: a miscompiled, mis-built or stale module fails in
+// milliseconds instead of after a full run. The vectors use the default
+// hash, generator and allocator, so they check the paths every run takes.
+
+// KnownAnswer is one self_test vector: params and the hash a run must return
+type KnownAnswer[T any] struct {
+	Name   string // Reference vector name
+	Params T
+	Hash   uint32
+}
+
+// SelfTest runs each vector through run (the task's run_task_v2) and returns
+// StatusOK when every hash matches. The first failure stops the test and
+// returns the run's status when the vector was rejected, or
+// StatusVerificationFailed on a hash mismatch, with a last error naming the
+// vector. Like any run it replaces the last run's status and result.
+func SelfTest[T any](vectors []KnownAnswer[T], run func(params *T, result *TaskResult) uint32) uint32 {
+	for i := range vectors {
+		// Runs take a pointer; copy so the embedded vector stays intact
+		params := vectors[i].Params
+		var result TaskResult
+		if status := run(&params, &result); status != StatusOK {
+			SetLastError("self test " + vectors[i].Name + ": " + LastError())
+			return status
+		}
+		if result.Hash != vectors[i].Hash {
+			SetLastError("self test " + vectors[i].Name + ": hash does not match the reference")
+			return StatusVerificationFailed
+		}
+	}
+	ClearLastError()
+	return StatusOK
+}
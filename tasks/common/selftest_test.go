@@ -0,0 +1,50 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	// A run hashing its param, rejecting 0 as a zero dimension
+	run := func(params *uint32, result *TaskResult) uint32 {
+		ClearLastError()
+		if *params == 0 {
+			status, message := Reject(ErrZeroDimension, "size must be non-zero")
+			SetLastError(message)
+			return status
+		}
+		*result = TaskResult{Status: StatusOK, Hash: *params * 3}
+		return StatusOK
+	}
+
+	vectors := []KnownAnswer[uint32]{{"one", 1, 3}, {"two", 2, 6}}
+	if status := SelfTest(vectors, run); status != StatusOK {
+		t.Fatalf("SelfTest = %d (%s), expected StatusOK", status, LastError())
+	}
+	if LastError() != "" {
+		t.Errorf("passing SelfTest left last error %q", LastError())
+	}
+	if vectors[0].Params != 1 {
+		t.Errorf("SelfTest changed the embedded params")
+	}
+
+	mismatch := append(vectors, KnownAnswer[uint32]{"bad", 4, 13})
+	if status := SelfTest(mismatch, run); status != StatusVerificationFailed {
+		t.Errorf("mismatched hash: SelfTest = %d, expected StatusVerificationFailed", status)
+	}
+	if !strings.Contains(LastError(), "bad") {
+		t.Errorf("mismatch error %q does not name the vector", LastError())
+	}
+
+	rejected := []KnownAnswer[uint32]{{"zero", 0, 0}, {"one", 1, 3}}
+	if status := SelfTest(rejected, run); status != StatusInvalidParams {
+		t.Errorf("rejected vector: SelfTest = %d, expected StatusInvalidParams", status)
+	}
+	if got := LastError(); !strings.Contains(got, "zero") || !strings.Contains(got, "non-zero") {
+		t.Errorf("rejection error %q does not name the vector and the reason", got)
+	}
+	if ErrorCode() != ErrZeroDimension {
+		t.Errorf("rejection error code = %d, expected ErrZeroDimension", ErrorCode())
+	}
+}
@@ -0,0 +1,15 @@
+package common
+
+// SIMD variants. Wasm validates a module as a whole, so an artifact holding a
+// single simd128 instruction fails to compile on a runtime without SIMD: the
+// scalar fallback is a separate build, chosen by the host. Inside a SIMD
+// build, tasks with a vector code path select it at init from SIMDBuild, and
+// has_simd reports the choice so results can be labelled.
+
+// HasSIMD reports SIMDBuild as the u32 flag has_simd returns
+func HasSIMD() uint32 {
+	if SIMDBuild {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,7 @@
+//go:build simd128
+
+package common
+
+// SIMDBuild reports a build for a target with the simd128 feature (build tag
+// simd128, set by scripts/build_tinygo.sh --simd)
+const SIMDBuild = true
@@ -0,0 +1,128 @@
+// Package snapshot compares the serialized outputs of a task's stages with
+// golden files under the package's testdata/snapshots, byte for byte. A
+// result hash says only that a run went wrong; a snapshot of each stage's
+// output says which stage did, and shows the line where its output drifted:
+//
+//	func TestSnapshots(t *testing.T) {
+//		snapshot.Match(t, "records.json", []byte(serializeToJson(records)))
+//	}
+//
+// Run the tests with WASMBENCH_UPDATE_SNAPSHOTS=1 to write the golden files
+// from the current outputs, after a change meant to alter them, and review
+// the files' diff before committing it.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// UpdateEnv, set to anything, makes Match write the golden files instead
+const UpdateEnv = "WASMBENCH_UPDATE_SNAPSHOTS"
+
+// Dir is where the golden files are, relative to the package under test
+const Dir = "testdata/snapshots"
+
+// Match fails t unless got is the golden file name holds, or writes the file
+// when UpdateEnv is set
+func Match(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join(Dir, name)
+	if os.Getenv(UpdateEnv) != "" {
+		if err := update(path, got); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("snapshot %s written", path)
+		return
+	}
+	if err := compare(path, got); err != nil {
+		t.Error(err)
+	}
+}
+
+// update writes got to the golden file at path
+func update(path string, got []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, got, 0o644)
+}
+
+// compare returns nil if the golden file at path holds got, and otherwise
+// where the two first differ
+func compare(path string, got []byte) error {
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no snapshot %s; run the tests with %s=1 to write it", path, UpdateEnv)
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(got, want) {
+		return nil
+	}
+
+	offset := 0
+	for offset < len(got) && offset < len(want) && got[offset] == want[offset] {
+		offset++
+	}
+	line := bytes.Count(want[:offset], []byte("\n")) + 1
+	return fmt.Errorf("snapshot %s differs at byte %d, line %d (%d bytes, expected %d):\n  got:  %q\n  want: %q\n"+
+		"run the tests with %s=1 to accept the new output",
+		path, offset, line, len(got), len(want), lineAt(got, offset), lineAt(want, offset), UpdateEnv)
+}
+
+// lineAt returns the line of data holding byte offset, or what is left of
+// it, without its newline
+func lineAt(data []byte, offset int) []byte {
+	offset = min(offset, len(data))
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := bytes.IndexByte(data[offset:], '\n')
+	if end < 0 {
+		return data[start:]
+	}
+	return data[start : offset+end]
+}
+
+// Uint32Grid renders values as text, width to a line and separated by
+// spaces, the form of a snapshot of an array of counts or ids
+func Uint32Grid(values []uint32, width int) []byte {
+	return grid(len(values), width, func(b []byte, i int) []byte {
+		return strconv.AppendUint(b, uint64(values[i]), 10)
+	})
+}
+
+// Float32Grid renders values as Uint32Grid does, each in the fewest digits
+// that read back as its exact bits
+func Float32Grid(values []float32, width int) []byte {
+	return grid(len(values), width, func(b []byte, i int) []byte {
+		if math.IsNaN(float64(values[i])) {
+			// NaNs differ only in their payload
+			return fmt.Appendf(b, "NaN(%#x)", math.Float32bits(values[i]))
+		}
+		return strconv.AppendFloat(b, float64(values[i]), 'g', -1, 32)
+	})
+}
+
+func grid(n, width int, appendValue func([]byte, int) []byte) []byte {
+	var b []byte
+	for i := 0; i < n; i++ {
+		if i > 0 && i%width == 0 {
+			b = append(b, '\n')
+		} else if i > 0 {
+			b = append(b, ' ')
+		}
+		b = appendValue(b, i)
+	}
+	if n > 0 {
+		b = append(b, '\n')
+	}
+	return b
+}
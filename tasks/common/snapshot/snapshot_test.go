@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots", "out.txt")
+	if err := compare(path, []byte("x")); err == nil || !strings.Contains(err.Error(), UpdateEnv+"=1 to write it") {
+		t.Errorf("Missing snapshot: %v, expected a hint to write it", err)
+	}
+
+	if err := update(path, []byte("1 2 3\n4 5 6\n7 8 9\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := compare(path, []byte("1 2 3\n4 5 6\n7 8 9\n")); err != nil {
+		t.Errorf("Identical output: %v", err)
+	}
+
+	err := compare(path, []byte("1 2 3\n4 50 6\n7 8 9\n"))
+	if err == nil {
+		t.Fatal("Changed output should differ from the snapshot")
+	}
+	for _, want := range []string{"differs at byte 9, line 2 (19 bytes, expected 18)", `got:  "4 50 6"`, `want: "4 5 6"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error %q, expected it to say %q", err, want)
+		}
+	}
+
+	// Output cut short is reported where it ends
+	err = compare(path, []byte("1 2 3\n"))
+	if err == nil || !strings.Contains(err.Error(), "differs at byte 6, line 2") || !strings.Contains(err.Error(), `got:  ""`) {
+		t.Errorf("Truncated output: %v", err)
+	}
+}
+
+func TestGrids(t *testing.T) {
+	if got := string(Uint32Grid([]uint32{1, 22, 333, 4294967295, 5}, 2)); got != "1 22\n333 4294967295\n5\n" {
+		t.Errorf("Uint32Grid wrote %q", got)
+	}
+	if got := string(Uint32Grid(nil, 4)); got != "" {
+		t.Errorf("Empty grid wrote %q", got)
+	}
+
+	values := []float32{0.1, float32(math.Copysign(0, -1)), 1e-45, float32(math.Inf(1)), math.Float32frombits(0x7FC00001)}
+	if got := string(Float32Grid(values, 5)); got != "0.1 -0 1e-45 +Inf NaN(0x7fc00001)\n" {
+		t.Errorf("Float32Grid wrote %q", got)
+	}
+}
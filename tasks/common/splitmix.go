@@ -0,0 +1,38 @@
+package common
+
+// SplitMix64 constants: the golden-ratio increment and the two mixing multipliers
+const (
+	SplitMixGamma uint64 = 0x9E3779B97F4A7C15
+	splitMixMul1  uint64 = 0xBF58476D1CE4E5B9
+	splitMixMul2  uint64 = 0x94D049BB133111EB
+)
+
+// SplitMix64 is the seed-expansion generator: a counter advanced by
+// SplitMixGamma whose value is finalized through a bijective mix
+type SplitMix64 struct {
+	state uint64
+}
+
+// NewSplitMix64 returns a SplitMix64 generator seeded with seed
+func NewSplitMix64(seed uint64) SplitMix64 {
+	return SplitMix64{state: seed}
+}
+
+// Next advances the generator and returns the next output
+func (s *SplitMix64) Next() uint64 {
+	s.state += SplitMixGamma
+	return splitMix(s.state)
+}
+
+func splitMix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * splitMixMul1
+	z = (z ^ (z >> 27)) * splitMixMul2
+	return z ^ (z >> 31)
+}
+
+// ExpandSeed derives the seed of independent random stream `stream` from a
+// task's seed. It is output number stream of NewSplitMix64(seed), computed
+// directly, so streams can be derived in any order.
+func ExpandSeed(seed uint64, stream uint32) uint64 {
+	return splitMix(seed + (uint64(stream)+1)*SplitMixGamma)
+}
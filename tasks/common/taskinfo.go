@@ -0,0 +1,92 @@
+package common
+
+// ABIVersion identifies the export set and params conventions of the task
+// modules; it changes whenever a host would need updating to keep working.
+// Version 2 accepts encoded params buffers (see DecodeParams).
+const ABIVersion = 2
+
+// Params field types reported by get_task_info
+const (
+	FieldU32 = "u32"
+	FieldU64 = "u64"
+	FieldF64 = "f64"
+)
+
+// ParamField describes one params struct field
+type ParamField struct {
+	Name   string // snake_case field name
+	Type   string // FieldU32, FieldU64 or FieldF64
+	Offset uintptr
+}
+
+// size returns the byte size of the field's type
+func (f ParamField) size() uint32 {
+	if f.Type == FieldU64 || f.Type == FieldF64 {
+		return 8
+	}
+	return 4
+}
+
+// FieldsFingerprint hashes a params layout described by fields in
+// declaration order, matching LayoutFingerprint over the (offset, size) pairs
+func FieldsFingerprint(fields []ParamField, structSize uintptr) uint32 {
+	layout := make([]uint32, 0, 2*len(fields)+1)
+	for _, field := range fields {
+		layout = append(layout, uint32(field.Offset), field.size())
+	}
+	return LayoutFingerprint(append(layout, uint32(structSize)))
+}
+
+// TaskInfo is the metadata a module publishes through get_task_info
+type TaskInfo struct {
+	Task       string // Task name as used by the harness (e.g. "mandelbrot")
+	Language   string // Implementation language
+	Variant    string // Algorithm variant
+	ParamsSize uintptr
+	Params     []ParamField
+	Stages     []string // Names of the get_checkpoints stages, StageInput first
+}
+
+// EncodeTaskInfo serializes info as a JSON object prefixed by its byte
+// length as a little-endian u32, so a host can decode it from one pointer
+func EncodeTaskInfo(info TaskInfo) []byte {
+	b := []byte(`{"task":`)
+	b = AppendQuote(b, info.Task)
+	b = append(b, `,"language":`...)
+	b = AppendQuote(b, info.Language)
+	b = append(b, `,"variant":`...)
+	b = AppendQuote(b, info.Variant)
+	b = append(b, `,"abi_version":`...)
+	b = AppendUint(b, ABIVersion)
+	b = append(b, `,"params_size":`...)
+	b = AppendUint(b, uint64(info.ParamsSize))
+	b = append(b, `,"params":[`...)
+	for i, field := range info.Params {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"name":`...)
+		b = AppendQuote(b, field.Name)
+		b = append(b, `,"type":`...)
+		b = AppendQuote(b, field.Type)
+		b = append(b, `,"offset":`...)
+		b = AppendUint(b, uint64(field.Offset))
+		b = append(b, '}')
+	}
+	b = append(b, `],"stages":[`...)
+	for i, stage := range info.Stages {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = AppendQuote(b, stage)
+	}
+	b = append(b, ']')
+	if AllocStats {
+		b = append(b, `,"alloc_stats":true`...)
+	}
+	b = append(b, '}')
+
+	blob := make([]byte, StringSize(string(b)))
+	PutString(blob, string(b))
+	return blob
+}
@@ -0,0 +1,42 @@
+package common
+
+// Thread negotiation. A parallel task variant needs both a host that can run
+// wasm threads (a browser page with COOP/COEP headers, for shared memory) and
+// a module built for them. The host asks has_threads, then requests a thread
+// count with set_thread_count, and the module answers with the count its runs
+// will actually use. Anything it cannot honour falls back to 1, so a run's
+// hash never depends on which side lacked threads.
+
+// ThreadsSupported reports whether this build can run work on more than one
+// thread. TinyGo emits no shared memory or atomics for wasm, and the modules
+// are built with -scheduler=none, so every current build is serial.
+const ThreadsSupported = false
+
+// MaxThreads bounds a requested thread count
+const MaxThreads uint32 = 64
+
+// threadCount is the number of threads runs use, always 1 in serial builds
+var threadCount uint32 = 1
+
+// SetThreadCount requests n threads for later runs and returns the count they
+// will use: n clamped to MaxThreads, or 1 for a serial build or n = 0
+func SetThreadCount(n uint32) uint32 {
+	threadCount = 1
+	if ThreadsSupported && n > 1 {
+		threadCount = min(n, MaxThreads)
+	}
+	return threadCount
+}
+
+// ThreadCount returns the number of threads runs use
+func ThreadCount() uint32 {
+	return threadCount
+}
+
+// HasThreads reports ThreadsSupported as the u32 flag has_threads returns
+func HasThreads() uint32 {
+	if ThreadsSupported {
+		return 1
+	}
+	return 0
+}
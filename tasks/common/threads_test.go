@@ -0,0 +1,20 @@
+package common
+
+import "testing"
+
+func TestThreadNegotiation(t *testing.T) {
+	defer SetThreadCount(1)
+
+	if (HasThreads() == 1) != ThreadsSupported {
+		t.Errorf("HasThreads = %d disagrees with ThreadsSupported = %v", HasThreads(), ThreadsSupported)
+	}
+	for _, requested := range []uint32{0, 1, 2, 8, MaxThreads + 1} {
+		want := uint32(1)
+		if ThreadsSupported && requested > 1 {
+			want = min(requested, MaxThreads)
+		}
+		if got := SetThreadCount(requested); got != want || ThreadCount() != want {
+			t.Errorf("SetThreadCount(%d) = %d (ThreadCount %d), expected %d", requested, got, ThreadCount(), want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package common
+
+import "math"
+
+// Tolerance bounds how far an element of a float32 output may be from the
+// reference implementation's and still count as the same result: within
+// ULPs units in the last place, or within Abs of it, whichever admits more.
+// Abs covers results near zero, where float rounding of a long sum can be
+// many ULPs of a tiny value. The zero Tolerance admits only identical bits.
+type Tolerance struct {
+	ULPs uint32
+	Abs  float64
+}
+
+// ULPDistance returns how many float32 values lie between a and b, 0 for
+// equal values (+0 and -0 included) and math.MaxUint32 when either is NaN
+func ULPDistance(a, b float32) uint32 {
+	if a != a || b != b {
+		return math.MaxUint32
+	}
+	// Map the bits onto a line where adjacent floats are adjacent integers
+	ordered := func(f float32) int64 {
+		bits := int64(math.Float32bits(f))
+		if bits&(1<<31) != 0 {
+			return -(bits &^ (1 << 31))
+		}
+		return bits
+	}
+	distance := ordered(a) - ordered(b)
+	if distance < 0 {
+		distance = -distance
+	}
+	return uint32(min(distance, math.MaxUint32))
+}
+
+// FloatComparison is the element-wise comparison of a float32 output with
+// the reference's under a Tolerance
+type FloatComparison struct {
+	Elements  int     // Compared, those of the reference
+	Beyond    int     // Elements outside the tolerance
+	MaxULPs   uint32  // Largest distance of any element, in ULPs
+	MaxAbs    float64 // Largest absolute difference of any element
+	First     int     // Index of the first element beyond, -1 for none
+	FirstGot  float32 // The output's value there
+	FirstWant float32 // The reference's
+}
+
+// Within reports whether every element was within the tolerance
+func (c FloatComparison) Within() bool {
+	return c.Beyond == 0
+}
+
+// CompareFloat32s compares got with want element by element under t. NaNs
+// match only NaNs. An output of another length is beyond the tolerance from
+// the first element one of them lacks.
+func (t Tolerance) CompareFloat32s(got, want []float32) FloatComparison {
+	c := FloatComparison{Elements: len(want), First: -1}
+	for i := range max(len(got), len(want)) {
+		if i >= len(got) || i >= len(want) {
+			c.Beyond += max(len(got), len(want)) - i
+			if c.First < 0 {
+				c.First = i
+			}
+			break
+		}
+		a, b := got[i], want[i]
+		ulps := ULPDistance(a, b)
+		if a != a && b != b {
+			ulps = 0
+		}
+		abs := math.Abs(float64(a) - float64(b))
+		if ulps == 0 {
+			abs = 0
+		}
+		c.MaxULPs = max(c.MaxULPs, ulps)
+		if abs == abs {
+			c.MaxAbs = max(c.MaxAbs, abs)
+		}
+		if ulps > t.ULPs && !(abs <= t.Abs) {
+			c.Beyond++
+			if c.First < 0 {
+				c.First, c.FirstGot, c.FirstWant = i, a, b
+			}
+		}
+	}
+	return c
+}
+
+// Float32sLE reads the little-endian float32s of an output block
+func Float32sLE(data []byte) []float32 {
+	values := make([]float32, len(data)/4)
+	for i := range values {
+		values[i] = math.Float32frombits(ReadUint32LE(data[i*4:]))
+	}
+	return values
+}
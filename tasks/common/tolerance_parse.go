@@ -0,0 +1,42 @@
+//go:build !wasm
+
+package common
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseTolerance reads a tolerance written as comma-separated bounds, such
+// as "ulps=4,abs=1e-6"; a bound not given is 0. Only host tools parse
+// tolerances, so this file stays out of the wasm builds, and strconv with it.
+func ParseTolerance(spec string) (Tolerance, error) {
+	var t Tolerance
+	for _, bound := range strings.Split(spec, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(bound), "=")
+		switch name {
+		case "ulps":
+			ulps, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return Tolerance{}, errors.New("ulps=" + value + " is not a whole number of ULPs")
+			}
+			t.ULPs = uint32(ulps)
+		case "abs":
+			abs, err := strconv.ParseFloat(value, 64)
+			if err != nil || abs < 0 || math.IsInf(abs, 0) {
+				return Tolerance{}, errors.New("abs=" + value + " is not a finite difference of at least 0")
+			}
+			t.Abs = abs
+		default:
+			return Tolerance{}, errors.New("unknown bound " + strconv.Quote(bound) + ", expected ulps=n or abs=x")
+		}
+	}
+	return t, nil
+}
+
+// String writes t as ParseTolerance reads it
+func (t Tolerance) String() string {
+	return "ulps=" + strconv.FormatUint(uint64(t.ULPs), 10) + ",abs=" + strconv.FormatFloat(t.Abs, 'g', -1, 64)
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseTolerance(t *testing.T) {
+	tol, err := ParseTolerance("ulps=4, abs=1e-6")
+	if err != nil || tol != (Tolerance{ULPs: 4, Abs: 1e-6}) {
+		t.Fatalf("ParseTolerance = %+v, %v", tol, err)
+	}
+	if again, err := ParseTolerance(tol.String()); err != nil || again != tol {
+		t.Errorf("%s read back as %+v, %v", tol, again, err)
+	}
+	if tol, err := ParseTolerance("abs=0.5"); err != nil || tol != (Tolerance{Abs: 0.5}) {
+		t.Errorf("abs alone read as %+v, %v", tol, err)
+	}
+	for _, spec := range []string{"", "ulps=-1", "ulps=1.5", "abs=-1", "abs=inf", "rel=0.1", "ulps"} {
+		if _, err := ParseTolerance(spec); err == nil {
+			t.Errorf("%q should not parse", spec)
+		}
+	}
+}
+
+func TestULPDistance(t *testing.T) {
+	one := float32(1)
+	next := math.Nextafter32(one, 2)
+	tests := []struct {
+		a, b float32
+		want uint32
+	}{
+		{one, one, 0},
+		{one, next, 1},
+		{next, one, 1},
+		{0, float32(math.Copysign(0, -1)), 0},
+		{math.SmallestNonzeroFloat32, -math.SmallestNonzeroFloat32, 2},
+		{float32(math.NaN()), one, math.MaxUint32},
+		{float32(math.Inf(1)), math.MaxFloat32, 1},
+	}
+	for _, tt := range tests {
+		if got := ULPDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("ULPDistance(%v, %v) = %d, expected %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareFloat32s(t *testing.T) {
+	want := []float32{1, 1e-9, -2, float32(math.NaN())}
+	got := []float32{math.Nextafter32(1, 2), 2e-9, -2, float32(math.NaN())}
+
+	// 1e-9 against 2e-9 is millions of ULPs, but a tiny absolute difference
+	c := Tolerance{ULPs: 1, Abs: 1e-8}.CompareFloat32s(got, want)
+	if !c.Within() || c.Elements != 4 || c.First != -1 {
+		t.Errorf("Comparison %+v, expected every element within", c)
+	}
+	if c.MaxULPs < 1<<20 || c.MaxAbs != float64(got[0]-1) {
+		t.Errorf("Largest differences %d ULPs and %g, expected 1e-9's from 2e-9 and 1's from the next float", c.MaxULPs, c.MaxAbs)
+	}
+
+	c = Tolerance{}.CompareFloat32s(got, want)
+	if c.Within() || c.Beyond != 2 || c.First != 0 || c.FirstGot != got[0] || c.FirstWant != 1 {
+		t.Errorf("Exact comparison %+v, expected the first two elements beyond", c)
+	}
+
+	c = Tolerance{ULPs: 1, Abs: 1e-8}.CompareFloat32s(got[:2], want)
+	if c.Within() || c.Beyond != 2 || c.First != 2 {
+		t.Errorf("Short output %+v, expected its missing elements beyond", c)
+	}
+	if c := (Tolerance{}).CompareFloat32s([]float32{1}, []float32{float32(math.NaN())}); c.Within() {
+		t.Error("A number should not match NaN")
+	}
+}
@@ -0,0 +1,127 @@
+package common
+
+import "math"
+
+// Parameter error codes, shared by every task and mirrored by the Rust
+// reference modules and by data/error_codes.json, which names them for the
+// reference vectors and the harness. A rejected run reports the coarse status
+// from ErrorStatus through run_task_v2 and validate_params, and the code
+// itself through get_error_code.
+const (
+	ErrNone                 uint32 = iota
+	ErrNullParams                  // params_ptr is 0
+	ErrBadEncoding                 // The encoded params buffer is malformed
+	ErrZeroDimension               // A size or dimension field is 0
+	ErrTooLarge                    // A size, count or derived total exceeds the task's limits
+	ErrNonFinite                   // A float field is NaN or infinite
+	ErrNonPositive                 // A float field that must be positive is not
+	ErrUnknownScale                // Scale names no tier
+	ErrUnknownProfile              // Profile names no workload profile
+	ErrUnknownVerification         // Verification names no level
+	ErrUnknownAllocator            // Allocator names no scratch allocator
+	ErrUnknownHashAlgorithm        // HashAlgorithm names no algorithm
+	ErrUnknownGenerator            // Generator names no random generator
+)
+
+// lastErrorCode is the code of the last rejection, cleared with the last error
+var lastErrorCode uint32
+
+// ErrorStatus maps an error code to the status a rejected run reports: limits
+// overflow with StatusOverflow, every other rejection is StatusInvalidParams
+func ErrorStatus(code uint32) uint32 {
+	switch code {
+	case ErrNone:
+		return StatusOK
+	case ErrTooLarge:
+		return StatusOverflow
+	}
+	return StatusInvalidParams
+}
+
+// Reject records code as the reason the params were rejected and returns the
+// status and message the task reports for it
+func Reject(code uint32, message string) (uint32, string) {
+	lastErrorCode = code
+	return ErrorStatus(code), message
+}
+
+// ErrorCode returns the code of the last rejected params, ErrNone when the
+// last call accepted them or failed for another reason
+func ErrorCode() uint32 {
+	return lastErrorCode
+}
+
+// Options are the tuning fields every task's params carry next to its sizes
+type Options struct {
+	Profile          uint32
+	WarmupIterations uint32
+	Verification     uint32
+	Allocator        uint32
+	HashAlgorithm    uint32
+	Generator        uint32
+}
+
+// Validator runs a task's parameter checks in order and keeps the first
+// failure, so every task rejects params with the same codes and messages
+type Validator struct {
+	code    uint32
+	message string
+}
+
+// Check fails with code and message unless ok
+func (v *Validator) Check(ok bool, code uint32, message string) {
+	if !ok && v.code == ErrNone {
+		v.code, v.message = code, message
+	}
+}
+
+// NonZero fails with ErrZeroDimension when value is 0
+func (v *Validator) NonZero(value uint64, message string) {
+	v.Check(value != 0, ErrZeroDimension, message)
+}
+
+// AtMost fails with ErrTooLarge when value exceeds limit
+func (v *Validator) AtMost(value, limit uint64, message string) {
+	v.Check(value <= limit, ErrTooLarge, message)
+}
+
+// Addressable fails with ErrTooLarge when count items of itemBytes each do
+// not fit in a wasm32 linear memory. It guards the u64 size fields, whose
+// values a 32-bit module can read but never allocate; itemBytes 0 passes.
+func (v *Validator) Addressable(count, itemBytes uint64, message string) {
+	v.Check(Memory64 || itemBytes == 0 || count <= MaxLinearMemoryBytes/itemBytes, ErrTooLarge, message)
+}
+
+// Finite fails with ErrNonFinite unless every value is a finite float
+func (v *Validator) Finite(message string, values ...float64) {
+	for _, value := range values {
+		v.Check(!math.IsNaN(value) && !math.IsInf(value, 0), ErrNonFinite, message)
+	}
+}
+
+// Positive fails with ErrNonPositive unless value is above 0
+func (v *Validator) Positive(value float64, message string) {
+	v.Check(value > 0, ErrNonPositive, message)
+}
+
+// Options checks the shared tuning fields against the values this package
+// defines, in the order every task has always checked them
+func (v *Validator) Options(options Options) {
+	v.Check(options.Profile <= ProfileMemory, ErrUnknownProfile, "unknown workload profile")
+	v.AtMost(uint64(options.WarmupIterations), MaxWarmupIterations, "warm-up iterations exceed the maximum")
+	v.Check(options.Verification <= VerifyFull, ErrUnknownVerification, "unknown verification level")
+	v.Check(options.Allocator <= AllocatorArena, ErrUnknownAllocator, "unknown scratch allocator")
+	v.Check(options.HashAlgorithm <= HashXXHash32, ErrUnknownHashAlgorithm, "unknown hash algorithm")
+	v.Check(options.Generator <= GeneratorHost, ErrUnknownGenerator, "unknown random generator")
+	v.Check(options.Generator != GeneratorHost || hostRandomAvailable(), ErrUnknownGenerator,
+		"host random generator needs the env.next_random import")
+}
+
+// Result returns the status and message of the first failed check, recording
+// its code for get_error_code, or StatusOK when every check passed
+func (v *Validator) Result() (uint32, string) {
+	if v.code == ErrNone {
+		return StatusOK, ""
+	}
+	return Reject(v.code, v.message)
+}
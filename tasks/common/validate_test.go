@@ -0,0 +1,123 @@
+package common
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	ClearLastError()
+	defer ClearLastError()
+
+	var v Validator
+	v.NonZero(3, "size must be non-zero")
+	v.AtMost(10, 10, "size exceeds the maximum")
+	v.Finite("view must be finite", 0.5, -2)
+	v.Positive(1e-9, "scale must be positive")
+	SetHostRandom(func() uint32 { return 0 })
+	v.Options(Options{Profile: ProfileMemory, WarmupIterations: MaxWarmupIterations, Verification: VerifyFull,
+		Allocator: AllocatorArena, HashAlgorithm: HashXXHash32, Generator: GeneratorHost})
+	SetHostRandom(nil)
+	if status, message := v.Result(); status != StatusOK || message != "" || ErrorCode() != ErrNone {
+		t.Fatalf("Valid params rejected with status %d (%q)", status, message)
+	}
+
+	// The first failed check wins, whatever fails after it
+	v = Validator{}
+	v.Finite("view must be finite", 1, math.Inf(-1))
+	v.Positive(math.NaN(), "scale must be positive")
+	v.AtMost(11, 10, "size exceeds the maximum")
+	if status, message := v.Result(); status != StatusInvalidParams || message != "view must be finite" || ErrorCode() != ErrNonFinite {
+		t.Errorf("Got status %d, code %d (%q), expected the non-finite rejection", status, ErrorCode(), message)
+	}
+
+	cases := []struct {
+		options Options
+		code    uint32
+	}{
+		{Options{Profile: ProfileMemory + 1}, ErrUnknownProfile},
+		{Options{WarmupIterations: MaxWarmupIterations + 1}, ErrTooLarge},
+		{Options{Verification: VerifyFull + 1}, ErrUnknownVerification},
+		{Options{Allocator: AllocatorArena + 1}, ErrUnknownAllocator},
+		{Options{HashAlgorithm: HashXXHash32 + 1}, ErrUnknownHashAlgorithm},
+		{Options{Generator: GeneratorHost}, ErrUnknownGenerator}, // No env.next_random in native builds
+		{Options{Generator: GeneratorHost + 1}, ErrUnknownGenerator},
+	}
+	for _, c := range cases {
+		v = Validator{}
+		v.Options(c.options)
+		if status, _ := v.Result(); status != ErrorStatus(c.code) || ErrorCode() != c.code {
+			t.Errorf("%+v: got status %d, code %d, expected code %d", c.options, status, ErrorCode(), c.code)
+		}
+	}
+
+	v = Validator{}
+	v.Addressable(MaxLinearMemoryBytes/8, 8, "input exceeds linear memory")
+	v.Addressable(1<<40, 0, "input exceeds linear memory")
+	if status, _ := v.Result(); status != StatusOK {
+		t.Errorf("Sizes within linear memory rejected with status %d", status)
+	}
+	v.Addressable(MaxLinearMemoryBytes/8+1, 8, "input exceeds linear memory")
+	if status, _ := v.Result(); status != StatusOverflow || ErrorCode() != ErrTooLarge {
+		t.Errorf("Size past linear memory: got status %d, code %d", status, ErrorCode())
+	}
+
+	ClearLastError()
+	if ErrorCode() != ErrNone {
+		t.Error("ClearLastError should clear the error code")
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	if ErrorStatus(ErrNone) != StatusOK || ErrorStatus(ErrTooLarge) != StatusOverflow {
+		t.Error("ErrNone should map to StatusOK and ErrTooLarge to StatusOverflow")
+	}
+	for code := ErrNullParams; code <= ErrUnknownGenerator; code++ {
+		if code != ErrTooLarge && ErrorStatus(code) != StatusInvalidParams {
+			t.Errorf("Code %d should map to StatusInvalidParams", code)
+		}
+	}
+}
+
+// TestErrorTaxonomyFile checks data/error_codes.json, which names the codes
+// for the reference vectors and the harness, against the constants
+func TestErrorTaxonomyFile(t *testing.T) {
+	data, err := os.ReadFile("../../data/error_codes.json")
+	if err != nil {
+		t.Fatalf("Reading the error taxonomy: %v", err)
+	}
+	var taxonomy struct {
+		Statuses   []string `json:"statuses"`
+		ErrorCodes []string `json:"error_codes"`
+	}
+	if err := json.Unmarshal(data, &taxonomy); err != nil {
+		t.Fatalf("Parsing the error taxonomy: %v", err)
+	}
+
+	statuses := map[string]uint32{
+		"ok": StatusOK, "invalid_params": StatusInvalidParams, "overflow": StatusOverflow,
+		"verification_failed": StatusVerificationFailed, "panicked": StatusPanicked, "cancelled": StatusCancelled,
+	}
+	codes := map[string]uint32{
+		"none": ErrNone, "null_params": ErrNullParams, "bad_encoding": ErrBadEncoding,
+		"zero_dimension": ErrZeroDimension, "too_large": ErrTooLarge, "non_finite": ErrNonFinite,
+		"non_positive": ErrNonPositive, "unknown_scale": ErrUnknownScale, "unknown_profile": ErrUnknownProfile,
+		"unknown_verification": ErrUnknownVerification, "unknown_allocator": ErrUnknownAllocator,
+		"unknown_hash_algorithm": ErrUnknownHashAlgorithm, "unknown_generator": ErrUnknownGenerator,
+	}
+	for _, table := range []struct {
+		names     []string
+		constants map[string]uint32
+	}{{taxonomy.Statuses, statuses}, {taxonomy.ErrorCodes, codes}} {
+		if len(table.names) != len(table.constants) {
+			t.Errorf("Taxonomy lists %d names, expected %d", len(table.names), len(table.constants))
+		}
+		for i, name := range table.names {
+			if value, ok := table.constants[name]; !ok || value != uint32(i) {
+				t.Errorf("Taxonomy entry %d is %q, which does not name code %d", i, name, i)
+			}
+		}
+	}
+}
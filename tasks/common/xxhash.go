@@ -0,0 +1,150 @@
+package common
+
+import "math/bits"
+
+// xxHash32 prime constants
+const (
+	xxhPrime1 uint32 = 2654435761
+	xxhPrime2 uint32 = 2246822519
+	xxhPrime3 uint32 = 3266489917
+	xxhPrime4 uint32 = 668265263
+	xxhPrime5 uint32 = 374761393
+)
+
+// XXHash32 is a streaming xxHash32 state. Tasks feed it the same byte stream
+// they feed FNV-1a, so the two algorithms disagreeing on a result means the
+// output really diverged rather than one hash colliding. Data is consumed in
+// 16-byte stripes; the tail is buffered until Sum32.
+type XXHash32 struct {
+	seed           uint32
+	v1, v2, v3, v4 uint32
+	total          uint64
+	buf            [16]byte
+	n              int
+}
+
+// NewXXHash32 returns an empty xxHash32 state for seed
+func NewXXHash32(seed uint32) XXHash32 {
+	return XXHash32{
+		seed: seed,
+		v1:   seed + xxhPrime1 + xxhPrime2,
+		v2:   seed + xxhPrime2,
+		v3:   seed,
+		v4:   seed - xxhPrime1,
+	}
+}
+
+func xxhRound(acc, input uint32) uint32 {
+	acc += input * xxhPrime2
+	return bits.RotateLeft32(acc, 13) * xxhPrime1
+}
+
+// stripe consumes one 16-byte stripe
+func (h *XXHash32) stripe(b []byte) {
+	_ = b[15] // Single bounds check
+	h.v1 = xxhRound(h.v1, ReadUint32LE(b))
+	h.v2 = xxhRound(h.v2, ReadUint32LE(b[4:]))
+	h.v3 = xxhRound(h.v3, ReadUint32LE(b[8:]))
+	h.v4 = xxhRound(h.v4, ReadUint32LE(b[12:]))
+}
+
+// AddByte appends one byte to the hashed stream
+func (h *XXHash32) AddByte(b byte) {
+	h.buf[h.n] = b
+	h.n++
+	h.total++
+	if h.n == len(h.buf) {
+		h.stripe(h.buf[:])
+		h.n = 0
+	}
+}
+
+// AddUint32 appends a 32-bit value to the hashed stream as four
+// little-endian bytes
+func (h *XXHash32) AddUint32(value uint32) {
+	if h.n > len(h.buf)-4 {
+		// The value straddles a stripe boundary
+		for i := 0; i < 4; i++ {
+			h.AddByte(byte(value >> (8 * i)))
+		}
+		return
+	}
+
+	PutUint32LE(h.buf[h.n:], value)
+	h.n += 4
+	h.total += 4
+	if h.n == len(h.buf) {
+		h.stripe(h.buf[:])
+		h.n = 0
+	}
+}
+
+// AddBytes appends a byte slice to the hashed stream
+func (h *XXHash32) AddBytes(data []byte) {
+	h.total += uint64(len(data))
+
+	// Top up a partially filled stripe first
+	if h.n > 0 {
+		copied := copy(h.buf[h.n:], data)
+		h.n += copied
+		data = data[copied:]
+		if h.n < len(h.buf) {
+			return
+		}
+		h.stripe(h.buf[:])
+		h.n = 0
+	}
+
+	for len(data) >= len(h.buf) {
+		h.stripe(data)
+		data = data[len(h.buf):]
+	}
+	h.n = copy(h.buf[:], data)
+}
+
+// Sum32 returns the hash of everything written so far without changing the state
+func (h *XXHash32) Sum32() uint32 {
+	var hash uint32
+	if h.total >= uint64(len(h.buf)) {
+		hash = bits.RotateLeft32(h.v1, 1) + bits.RotateLeft32(h.v2, 7) +
+			bits.RotateLeft32(h.v3, 12) + bits.RotateLeft32(h.v4, 18)
+	} else {
+		hash = h.seed + xxhPrime5
+	}
+	hash += uint32(h.total)
+
+	tail := h.buf[:h.n]
+	for len(tail) >= 4 {
+		hash += ReadUint32LE(tail) * xxhPrime3
+		hash = bits.RotateLeft32(hash, 17) * xxhPrime4
+		tail = tail[4:]
+	}
+	for _, b := range tail {
+		hash += uint32(b) * xxhPrime5
+		hash = bits.RotateLeft32(hash, 11) * xxhPrime1
+	}
+
+	hash ^= hash >> 15
+	hash *= xxhPrime2
+	hash ^= hash >> 13
+	hash *= xxhPrime3
+	hash ^= hash >> 16
+	return hash
+}
+
+// XXHash32Bytes returns the xxHash32 of data
+func XXHash32Bytes(seed uint32, data []byte) uint32 {
+	h := NewXXHash32(seed)
+	h.AddBytes(data)
+	return h.Sum32()
+}
+
+// XXHash32Uint32s returns the xxHash32 of a run of 32-bit values, each as
+// four little-endian bytes
+func XXHash32Uint32s(seed uint32, values []uint32) uint32 {
+	h := NewXXHash32(seed)
+	for i := 0; i < len(values); i++ {
+		h.AddUint32(values[i])
+	}
+	return h.Sum32()
+}
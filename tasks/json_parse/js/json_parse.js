@@ -0,0 +1,179 @@
+/**
+ * json_parse in plain JavaScript: the baseline cmd/bench -js times the wasm
+ * builds against. It round-trips the same LCG records through the engine's
+ * own JSON.stringify and JSON.parse, which write the compact document the
+ * TinyGo package does byte for byte, and hashes the parsed records as it does.
+ */
+
+import {
+    ErrorCode,
+    FNV_OFFSET_BASIS,
+    GENERATOR_LCG,
+    HASH_FNV1A,
+    LCG,
+    PROFILE_COMPUTE,
+    SCALE_CUSTOM,
+    SCALE_LARGE,
+    Status,
+    TaskError,
+    VERIFY_FULL,
+    VERIFY_NONE,
+    Validator,
+    WORK_UNITS_PER_TARGET,
+    defineTask,
+    hashASCII,
+    hashByte,
+    hashUint32,
+    paramsStruct,
+    reject
+} from '../../common/js/common.js';
+
+// Params fields, in the order of JsonParseParams
+const FIELDS = [
+    'record_count',
+    'seed',
+    'scale',
+    'profile',
+    'target_work',
+    'warmup_iterations',
+    'verification',
+    'allocator',
+    'hash_algorithm',
+    'generator',
+    'seed_high'
+];
+
+// Record count of each scale tier
+const SCALE_RECORD_COUNTS = [0, 500, 5000, 15000, 30000];
+
+const MAX_RECORD_COUNT = 1000000;
+
+// Records per document in the compute profile
+const COMPUTE_BATCH_RECORDS = 64;
+
+/** Resolve the scale tier, check the params and calibrate them to target_work */
+function prepare(values) {
+    const params = paramsStruct(values, FIELDS);
+    if (params.scale !== SCALE_CUSTOM) {
+        if (params.scale > SCALE_LARGE) {
+            throw reject(ErrorCode.UNKNOWN_SCALE, 'unknown scale tier');
+        }
+        params.record_count = SCALE_RECORD_COUNTS[params.scale];
+    }
+
+    const v = new Validator();
+    v.atMost(params.record_count, MAX_RECORD_COUNT, 'record count exceeds the maximum');
+    v.options(params);
+    v.unsupported(params.hash_algorithm === HASH_FNV1A, ErrorCode.UNKNOWN_HASH_ALGORITHM, 'xxHash32');
+    v.unsupported(params.generator === GENERATOR_LCG, ErrorCode.UNKNOWN_GENERATOR, 'the PCG32 generator');
+    v.result();
+
+    // Double the record count until it reaches the target or the cap; an
+    // empty document never grows
+    if (params.target_work !== 0 && params.record_count !== 0) {
+        const target = params.target_work * WORK_UNITS_PER_TARGET;
+        while (params.record_count < target && params.record_count * 2 <= MAX_RECORD_COUNT) {
+            params.record_count *= 2;
+        }
+    }
+    return params;
+}
+
+/** Draw count records from rng, their ids from first + 1 */
+function generateRecords(first, count, rng) {
+    const records = new Array(count);
+    for (let i = 0; i < count; i++) {
+        const value = rng.next();
+        const id = first + i + 1;
+        records[i] = { id, value: value | 0, flag: (value & 1) === 0, name: 'a' + id };
+    }
+    return records;
+}
+
+/** Parse document back to records, failing a run whose records are malformed */
+function parseRecords(document) {
+    let parsed;
+    try {
+        parsed = JSON.parse(document);
+    } catch (e) {
+        throw new TaskError(Status.VERIFICATION_FAILED, ErrorCode.NONE, e.message);
+    }
+    if (!Array.isArray(parsed)) {
+        throw new TaskError(Status.VERIFICATION_FAILED, ErrorCode.NONE, 'expected a JSON array');
+    }
+    for (const record of parsed) {
+        if (
+            !Number.isInteger(record?.id) ||
+            !Number.isInteger(record.value) ||
+            typeof record.flag !== 'boolean' ||
+            typeof record.name !== 'string'
+        ) {
+            throw new TaskError(Status.VERIFICATION_FAILED, ErrorCode.NONE, 'missing required fields in JSON object');
+        }
+    }
+    return parsed;
+}
+
+/** Fold records into an FNV-1a hash: id, value, a flag byte and the name */
+function hashRecords(hash, records) {
+    for (const record of records) {
+        hash = hashUint32(hash, record.id);
+        hash = hashUint32(hash, record.value);
+        hash = hashByte(hash, record.flag ? 1 : 0);
+        hash = hashASCII(hash, record.name);
+    }
+    return hash;
+}
+
+/**
+ * Round-trip the params' records, in one document or in batches for the
+ * compute profile, and return the hash, or checksum, of the parsed records.
+ * Hash and sum carry over between batches, so both profiles agree.
+ */
+function execute(params) {
+    const count = params.record_count;
+    const batch = params.profile === PROFILE_COMPUTE ? COMPUTE_BATCH_RECORDS : Math.max(count, 1);
+    const rng = new LCG(params.seed, params.seed_high);
+    let hash = FNV_OFFSET_BASIS;
+    let sum = 0;
+    // An empty document is still written and parsed
+    for (let first = 0; first < count || first === 0; first += batch) {
+        const records = generateRecords(first, Math.min(batch, count - first), rng);
+        const document = JSON.stringify(records);
+        const parsed = parseRecords(document);
+        if (parsed.length !== records.length) {
+            throw new TaskError(
+                Status.VERIFICATION_FAILED,
+                ErrorCode.NONE,
+                'parsed record count differs from generated'
+            );
+        }
+
+        if (params.verification === VERIFY_NONE) {
+            for (const record of parsed) {
+                sum = (sum + record.value) >>> 0;
+            }
+            continue;
+        }
+        if (params.verification === VERIFY_FULL && JSON.stringify(parsed) !== document) {
+            throw new TaskError(
+                Status.VERIFICATION_FAILED,
+                ErrorCode.NONE,
+                're-serialized document differs from the parsed input'
+            );
+        }
+        hash = hashRecords(hash, parsed);
+    }
+    return params.verification === VERIFY_NONE ? sum : hash;
+}
+
+export default defineTask({
+    name: 'json_parse',
+    prepare,
+    execute,
+    selfTestVectors: [
+        { name: 'single_record', params: { record_count: 1, seed: 12345 }, hash: 2570755639 },
+        { name: 'systematic_2_2', params: { record_count: 5, seed: 42 }, hash: 196198558 },
+        { name: 'systematic_3_6', params: { record_count: 10, seed: 4294967295 }, hash: 3883069239 }
+    ]
+});
@@ -35,6 +35,18 @@ pub fn fnv1a_hash_records(records: &[JsonRecord]) -> u32 {
     hash
 }
 
+/// Compute FNV-1a hash of raw bytes, such as a serialized document
+pub fn fnv1a_hash_bytes(bytes: &[u8]) -> u32 {
+    let mut hash = FNV_OFFSET_BASIS;
+
+    for &byte in bytes {
+        hash ^= byte as u32;
+        hash = hash.wrapping_mul(FNV_PRIME);
+    }
+
+    hash
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
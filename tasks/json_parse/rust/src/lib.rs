@@ -1,5 +1,6 @@
 use std::alloc::{alloc as sys_alloc, Layout};
 use std::os::raw::c_void;
+use std::sync::atomic::{AtomicU32, Ordering};
 
 pub mod generator;
 pub mod hash;
@@ -7,11 +8,13 @@ pub mod parser;
 pub mod reference;
 pub mod serializer;
 pub mod types;
+pub mod validation;
 
 use generator::generate_json_records;
 use hash::fnv1a_hash_records;
 use parser::parse_json_string;
 use serializer::serialize_to_json;
+use validation::{check_parameters, ParamError};
 
 #[cfg(test)]
 use generator::linear_congruential_generator;
@@ -20,6 +23,9 @@ use parser::{parse_json_boolean, parse_json_number, parse_json_string_value};
 #[cfg(test)]
 use types::JsonRecord;
 
+// Code of the last rejected params, ParamError::None after an accepted run
+static LAST_ERROR_CODE: AtomicU32 = AtomicU32::new(ParamError::None as u32);
+
 // WebAssembly C-style interface exports
 
 #[no_mangle]
@@ -51,7 +57,18 @@ pub extern "C" fn alloc(n_bytes: u32) -> *mut c_void {
 
 #[no_mangle]
 pub extern "C" fn run_task(params_ptr: *mut c_void) -> u32 {
+    if params_ptr.is_null() {
+        LAST_ERROR_CODE.store(ParamError::NullParams as u32, Ordering::Relaxed);
+        return 0;
+    }
+
     let params = unsafe { std::slice::from_raw_parts(params_ptr as *const u32, 2) };
+    if let Err(code) = check_parameters(params[0]) {
+        LAST_ERROR_CODE.store(code as u32, Ordering::Relaxed);
+        return 0;
+    }
+    LAST_ERROR_CODE.store(ParamError::None as u32, Ordering::Relaxed);
+
     let record_count = params[0] as usize;
     let seed = params[1];
 
@@ -66,6 +83,12 @@ pub extern "C" fn run_task(params_ptr: *mut c_void) -> u32 {
     fnv1a_hash_records(&parsed_records)
 }
 
+/// Shared code of the last parameter rejection, 0 when the last run accepted its params
+#[no_mangle]
+pub extern "C" fn get_error_code() -> u32 {
+    LAST_ERROR_CODE.load(Ordering::Relaxed)
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -159,6 +182,13 @@ mod tests {
         // Test error cases
         assert!(parse_json_string("invalid").is_err());
         assert!(parse_json_string(r#"[{"id":1}]"#).is_err()); // Missing required fields
+        assert!(parse_json_string(r#"[{"id":-1,"value":1,"flag":true,"name":"a"}]"#).is_err());
+
+        // Every u32 id round-trips
+        let largest = r#"[{"id":4294967295,"value":1,"flag":true,"name":"a"}]"#;
+        let records = parse_json_string(largest).expect("Failed to parse the largest id");
+        assert_eq!(records[0].id, u32::MAX);
+        assert_eq!(serialize_to_json(&records), largest);
     }
 
     #[test]
@@ -399,4 +429,25 @@ mod tests {
         println!("📁 Output file: ../../../data/reference_hashes/json_parse.json");
         println!("🔗 Use this file for cross-implementation validation with TinyGo");
     }
+
+    #[test]
+    fn test_params_layout() {
+        // run_task reads the params as the u32s record_count and seed, the
+        // leading fields of the layout the Go build asserts
+        let path = concat!(env!("CARGO_MANIFEST_DIR"), "/../../../configs/layouts.json");
+        let data = std::fs::read_to_string(path).expect("Failed to read the layout file");
+        let layouts: serde_json::Value =
+            serde_json::from_str(&data).expect("Failed to parse the layout file");
+        let layout = layouts["structs"]
+            .as_array()
+            .and_then(|structs| structs.iter().find(|s| s["name"] == "JsonParseParams"))
+            .expect("JsonParseParams is not in the layout file");
+
+        for (i, name) in ["record_count", "seed"].into_iter().enumerate() {
+            let field = &layout["fields"][i];
+            assert_eq!(field["name"], name, "Field {} of JsonParseParams", i);
+            assert_eq!(field["offset"], (i * 4) as u64, "Offset of {}", name);
+            assert_eq!(field["size"], 4, "Size of {}", name);
+        }
+    }
 }
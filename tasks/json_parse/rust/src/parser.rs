@@ -114,7 +114,7 @@ fn parse_json_object(bytes: &[u8], pos: &mut usize) -> Result<JsonRecord, ParseE
         // Parse value based on field name
         match key.as_str() {
             "id" => {
-                let parsed_id = parse_json_number(bytes, pos)? as u32;
+                let parsed_id = parse_json_id(bytes, pos)?;
                 id = Some(parsed_id);
             }
             "value" => {
@@ -299,6 +299,31 @@ pub fn parse_json_number(bytes: &[u8], pos: &mut usize) -> Result<i32, ParseErro
     Ok(final_result as i32)
 }
 
+/// Parse the id field: an unsigned number up to `u32::MAX`, every id
+/// `serialize_to_json` writes
+pub fn parse_json_id(bytes: &[u8], pos: &mut usize) -> Result<u32, ParseError> {
+    skip_whitespace(bytes, pos);
+
+    if *pos >= bytes.len() || !bytes[*pos].is_ascii_digit() {
+        return Err(ParseError::InvalidNumber {
+            message: "Expected digit",
+        });
+    }
+
+    let mut result: u64 = 0;
+    while *pos < bytes.len() && bytes[*pos].is_ascii_digit() {
+        result = result * 10 + (bytes[*pos] - b'0') as u64;
+        if result > u32::MAX as u64 {
+            return Err(ParseError::InvalidNumber {
+                message: "Number out of range",
+            });
+        }
+        *pos += 1;
+    }
+
+    Ok(result as u32)
+}
+
 pub fn parse_json_boolean(bytes: &[u8], pos: &mut usize) -> Result<bool, ParseError> {
     skip_whitespace(bytes, pos);
 
@@ -1,3 +1,9 @@
+use crate::generator::generate_json_records;
+use crate::hash::{fnv1a_hash_bytes, fnv1a_hash_records};
+use crate::parser::parse_json_string;
+use crate::serializer::serialize_to_json;
+use crate::types::MAX_RECORD_COUNT;
+use crate::validation::{check_parameters, ParamError, STATUS_OK};
 use crate::{alloc, init, run_task};
 
 use serde::{Deserialize, Serialize};
@@ -9,9 +15,53 @@ pub struct TestVector {
     pub description: String,
     pub params: SerializableParams,
     pub expected_hash: u32,
+    /// Status the run reports, STATUS_OK for vectors that produce a hash
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_status: u32,
+    /// ParamError code of a rejected run
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_error_code: u32,
+    /// Hashes of the stages before the result, for vectors that produce one
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub expected_stages: Option<StageHashes>,
     pub category: String,
 }
 
+/// Checkpoint hashes of a run's stages before its result, which is
+/// expected_hash, in the order the run reaches them. TinyGo records the same
+/// stages through get_checkpoints, so the first that differs is where two
+/// implementations diverged.
+#[derive(Serialize, Deserialize, Debug, Clone, Copy, PartialEq)]
+pub struct StageHashes {
+    /// Generated records, hashed like the result
+    pub input: u32,
+    /// Serialized document bytes
+    pub serialize: u32,
+    /// Records parsed back from the document
+    pub parse: u32,
+}
+
+impl StageHashes {
+    /// Names the first stage whose hash differs from expected's, with both hashes
+    pub fn first_difference(&self, expected: &StageHashes) -> Option<String> {
+        [
+            ("input", self.input, expected.input),
+            ("serialize", self.serialize, expected.serialize),
+            ("parse", self.parse, expected.parse),
+        ]
+        .into_iter()
+        .find(|(_, got, want)| got != want)
+        .map(|(stage, got, want)| {
+            format!("first diverging stage: {stage} hashed {got}, expected {want}")
+        })
+    }
+}
+
+/// Omits the status fields of vectors that succeed, keeping their JSON unchanged
+fn is_zero(value: &u32) -> bool {
+    *value == 0
+}
+
 /// Serializable version of JSON parse parameters for JSON export
 #[derive(Serialize, Deserialize, Debug, Clone, Copy)]
 pub struct SerializableParams {
@@ -38,6 +88,9 @@ pub fn generate_systematic_vectors() -> Vec<TestVector> {
                 description: format!("records={}, seed={}", record_count, seed),
                 params,
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(&params),
                 category: "systematic".to_string(),
             });
         }
@@ -124,6 +177,9 @@ pub fn generate_manual_critical_vectors() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: *params,
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "critical".to_string(),
             }
         })
@@ -202,6 +258,9 @@ pub fn generate_rng_vectors() -> Vec<TestVector> {
                 ),
                 params,
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(&params),
                 category: "rng_validation".to_string(),
             });
         }
@@ -280,6 +339,9 @@ pub fn generate_parsing_vectors() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: *params,
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "parsing_validation".to_string(),
             }
         })
@@ -344,6 +406,9 @@ pub fn generate_edge_case_vectors() -> Vec<TestVector> {
                 ),
                 params,
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(&params),
                 category: "edge_case".to_string(),
             });
         }
@@ -374,6 +439,53 @@ fn compute_reference_hash(params: &SerializableParams) -> u32 {
     }
 }
 
+/// Hash each stage of a run, None for params the run rejects or a document
+/// that does not parse
+pub fn compute_stage_hashes(params: &SerializableParams) -> Option<StageHashes> {
+    check_parameters(params.record_count).ok()?;
+
+    let records = generate_json_records(params.record_count as usize, params.seed);
+    let json_string = serialize_to_json(&records);
+    let parsed_records = parse_json_string(&json_string).ok()?;
+
+    Some(StageHashes {
+        input: fnv1a_hash_records(&records),
+        serialize: fnv1a_hash_bytes(json_string.as_bytes()),
+        parse: fnv1a_hash_records(&parsed_records),
+    })
+}
+
+/// Generate error vectors: params every implementation must reject with the
+/// same status and error code, returning a zero hash
+pub fn generate_error_vectors() -> Vec<TestVector> {
+    let error_cases = [(
+        "error_record_count_over_limit",
+        "Record count past the maximum - rejected as too large",
+        SerializableParams {
+            record_count: MAX_RECORD_COUNT + 1,
+            seed: 12345,
+        },
+    )];
+
+    error_cases
+        .iter()
+        .map(|(name, desc, params)| {
+            let code =
+                check_parameters(params.record_count).expect_err("error vectors must be rejected");
+            TestVector {
+                name: name.to_string(),
+                description: desc.to_string(),
+                params: *params,
+                expected_hash: compute_reference_hash(params),
+                expected_status: code.status(),
+                expected_error_code: code as u32,
+                expected_stages: None,
+                category: "error".to_string(),
+            }
+        })
+        .collect()
+}
+
 /// Generate all test vectors
 pub fn generate_all_vectors() -> Vec<TestVector> {
     let mut all_vectors = Vec::new();
@@ -393,6 +505,9 @@ pub fn generate_all_vectors() -> Vec<TestVector> {
     println!("Generating edge case vectors...");
     all_vectors.extend(generate_edge_case_vectors());
 
+    println!("Generating error vectors...");
+    all_vectors.extend(generate_error_vectors());
+
     println!("Generated {} total test vectors", all_vectors.len());
 
     all_vectors
@@ -410,3 +525,37 @@ pub fn export_vectors_to_json(vectors: &[TestVector], filename: &str) -> std::io
     println!("Exported {} test vectors to {}", vectors.len(), filename);
     Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_reference_file_stages() {
+        // The committed file, whose stages the TinyGo implementation recorded
+        let path = concat!(
+            env!("CARGO_MANIFEST_DIR"),
+            "/../../../data/reference_hashes/json_parse.json"
+        );
+        let data = std::fs::read_to_string(path).expect("Failed to read the reference file");
+        let vectors: Vec<TestVector> =
+            serde_json::from_str(&data).expect("Failed to parse the reference file");
+
+        for vector in vectors.iter().filter(|v| v.expected_status == STATUS_OK) {
+            let stages =
+                compute_stage_hashes(&vector.params).expect("Reference params should be accepted");
+            if let Some(diverged) = vector
+                .expected_stages
+                .and_then(|expected| stages.first_difference(&expected))
+            {
+                panic!("{}: {}", vector.name, diverged);
+            }
+            // The result hashes the parsed records
+            assert_eq!(
+                stages.parse, vector.expected_hash,
+                "{}: every stage matches, but the result hash differs",
+                vector.name
+            );
+        }
+    }
+}
@@ -45,3 +45,4 @@ pub const FNV_OFFSET_BASIS: u32 = 2166136261;
 pub const FNV_PRIME: u32 = 16777619;
 pub const LCG_MULTIPLIER: u32 = 1664525;
 pub const LCG_INCREMENT: u32 = 1013904223;
+pub const MAX_RECORD_COUNT: u32 = 1_000_000; // Matches the TinyGo record count limit
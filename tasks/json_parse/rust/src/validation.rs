@@ -0,0 +1,70 @@
+// Parameter validation for the JSON round trip
+
+use crate::types::MAX_RECORD_COUNT;
+
+/// Parameter error codes, identical to the TinyGo modules' shared codes
+#[repr(u32)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ParamError {
+    None = 0,
+    NullParams = 1,
+    BadEncoding = 2,
+    ZeroDimension = 3,
+    TooLarge = 4,
+    NonFinite = 5,
+    NonPositive = 6,
+    UnknownScale = 7,
+    UnknownProfile = 8,
+    UnknownVerification = 9,
+    UnknownAllocator = 10,
+    UnknownHashAlgorithm = 11,
+    UnknownGenerator = 12,
+}
+
+/// Run statuses, identical to the TinyGo modules' status codes
+pub const STATUS_OK: u32 = 0;
+pub const STATUS_INVALID_PARAMS: u32 = 1;
+pub const STATUS_OVERFLOW: u32 = 2;
+
+impl ParamError {
+    /// Status a run rejected with this code reports: limits overflow, every
+    /// other rejection is invalid params
+    pub fn status(self) -> u32 {
+        match self {
+            ParamError::None => STATUS_OK,
+            ParamError::TooLarge => STATUS_OVERFLOW,
+            _ => STATUS_INVALID_PARAMS,
+        }
+    }
+}
+
+/// Checks the record count, the only bounded field; zero records is a valid empty document
+pub fn check_parameters(record_count: u32) -> Result<(), ParamError> {
+    if record_count > MAX_RECORD_COUNT {
+        return Err(ParamError::TooLarge);
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_parameter_error_codes() {
+        assert_eq!(check_parameters(0), Ok(()));
+        assert_eq!(check_parameters(MAX_RECORD_COUNT), Ok(()));
+        assert_eq!(
+            check_parameters(MAX_RECORD_COUNT + 1),
+            Err(ParamError::TooLarge)
+        );
+    }
+
+    #[test]
+    fn test_error_statuses() {
+        assert_eq!(ParamError::None.status(), STATUS_OK);
+        assert_eq!(ParamError::TooLarge.status(), STATUS_OVERFLOW);
+        assert_eq!(ParamError::ZeroDimension.status(), STATUS_INVALID_PARAMS);
+        assert_eq!(ParamError::NonPositive.status(), STATUS_INVALID_PARAMS);
+    }
+}
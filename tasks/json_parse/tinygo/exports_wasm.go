@@ -0,0 +1,201 @@
+//go:build tinygo
+
+package main
+
+import "json_parse_wasm/jsonparse"
+
+// The TinyGo build's exports: each forwards to package jsonparse, which holds
+// the task and builds for the host too
+
+//go:export init
+func initWasm(seed uint32) {
+	jsonparse.Init(seed)
+}
+
+//go:export init64
+func initWasm64(seed uint64) {
+	jsonparse.Init64(seed)
+}
+
+//go:export alloc
+func alloc(nBytes uint32) uintptr {
+	return jsonparse.Alloc(nBytes)
+}
+
+//go:export alloc_uninitialized
+func allocUninitialized(nBytes uint32) uintptr {
+	return jsonparse.AllocUninitialized(nBytes)
+}
+
+// TinyGo's wasm runtime already exports malloc/free, so the release
+// counterpart of alloc is exported as dealloc
+//
+//go:export dealloc
+func dealloc(ptr uintptr) {
+	jsonparse.Dealloc(ptr)
+}
+
+//go:export get_scale_factor
+func getScaleFactor() uint32 {
+	return jsonparse.GetScaleFactor()
+}
+
+//go:export get_work_metrics
+func getWorkMetrics() uintptr {
+	return jsonparse.GetWorkMetrics()
+}
+
+//go:export get_memory_stats
+func getMemoryStats() uintptr {
+	return jsonparse.GetMemoryStats()
+}
+
+//go:export params_fingerprint
+func paramsFingerprint() uint32 {
+	return jsonparse.ParamsFingerprint()
+}
+
+//go:export get_limits
+func getLimits() uintptr {
+	return jsonparse.GetLimits()
+}
+
+//go:export abi_version
+func abiVersion() uint32 {
+	return jsonparse.ABIVersion()
+}
+
+//go:export get_task_info
+func getTaskInfo() uintptr {
+	return jsonparse.GetTaskInfo()
+}
+
+//go:export reset_arena
+func resetArena() {
+	jsonparse.ResetArena()
+}
+
+//go:export reserve_memory
+func reserveMemory(paramsPtr uintptr) uint32 {
+	return jsonparse.ReserveMemory(paramsPtr)
+}
+
+//go:export set_memory_budget
+func setMemoryBudget(pages uint32) {
+	jsonparse.SetMemoryBudget(pages)
+}
+
+//go:export get_max_memory_pages
+func getMaxMemoryPages() uint32 {
+	return jsonparse.GetMaxMemoryPages()
+}
+
+//go:export has_threads
+func hasThreads() uint32 {
+	return jsonparse.HasThreads()
+}
+
+//go:export set_thread_count
+func setThreadCount(n uint32) uint32 {
+	return jsonparse.SetThreadCount(n)
+}
+
+//go:export has_simd
+func hasSIMD() uint32 {
+	return jsonparse.HasSIMD()
+}
+
+//go:export get_cancel_ptr
+func getCancelPtr() uintptr {
+	return jsonparse.GetCancelPtr()
+}
+
+//go:export set_checkpoints
+func setCheckpoints(enabled uint32) {
+	jsonparse.SetCheckpoints(enabled)
+}
+
+//go:export hash_input
+func hashInput() uint32 {
+	return jsonparse.HashInput()
+}
+
+//go:export get_checkpoints
+func getCheckpoints() uintptr {
+	return jsonparse.GetCheckpoints()
+}
+
+//go:export set_output
+func setOutput(enabled uint32) {
+	jsonparse.SetOutput(enabled)
+}
+
+//go:export get_output
+func getOutput() uintptr {
+	return jsonparse.GetOutput()
+}
+
+//go:export get_result_ptr
+func getResultPtr() uintptr {
+	return jsonparse.GetResultPtr()
+}
+
+//go:export get_last_error_ptr
+func getLastErrorPtr() uintptr {
+	return jsonparse.GetLastErrorPtr()
+}
+
+//go:export get_last_error_len
+func getLastErrorLen() uint32 {
+	return jsonparse.GetLastErrorLen()
+}
+
+//go:export get_error_code
+func getErrorCode() uint32 {
+	return jsonparse.GetErrorCode()
+}
+
+//go:export get_panic_ptr
+func getPanicPtr() uintptr {
+	return jsonparse.GetPanicPtr()
+}
+
+//go:export get_panic_len
+func getPanicLen() uint32 {
+	return jsonparse.GetPanicLen()
+}
+
+//go:export run_task64
+func runTask64(paramsPtr uintptr) uint64 {
+	return jsonparse.RunTask64(paramsPtr)
+}
+
+//go:export run_task_timed
+func runTaskTimed(paramsPtr, resultPtr uintptr) uint32 {
+	return jsonparse.RunTaskTimed(paramsPtr, resultPtr)
+}
+
+//go:export run_task_v2
+func runTaskV2(paramsPtr, resultPtr uintptr) uint32 {
+	return jsonparse.RunTaskV2(paramsPtr, resultPtr)
+}
+
+//go:export run_task_packed
+func runTaskPacked(paramsPtr uintptr) uint64 {
+	return jsonparse.RunTaskPacked(paramsPtr)
+}
+
+//go:export validate_params
+func validateParams(paramsPtr uintptr) uint32 {
+	return jsonparse.ValidateParams(paramsPtr)
+}
+
+//go:export self_test
+func selfTest() uint32 {
+	return jsonparse.SelfTest()
+}
+
+//go:export run_task
+func runTask(paramsPtr uintptr) (hash uint32) {
+	return jsonparse.RunTask(paramsPtr)
+}
@@ -0,0 +1,78 @@
+// Package jsonparse provides cross-implementation validation tests for the JSON parsing
+// WebAssembly module, ensuring compatibility between TinyGo and Rust implementations.
+package jsonparse
+
+import (
+	_ "embed"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common/conformance"
+)
+
+// Test configuration constants
+const (
+	// Memory allocation constants
+	// Size of the full parameter struct; fields beyond record_count and seed
+	// are left zeroed so they select their defaults
+	parameterMemorySize = uint32(unsafe.Sizeof(JsonParseParams{}))
+
+	// Test parameters for interface validation
+	testRecordCount = 100
+	testSeed        = 12345
+)
+
+// referenceHashes is data/reference_hashes/json_parse.json, copied beside the
+// package by cmd/genrefs so the tests find it wherever they run
+//
+//go:embed testdata/reference_hashes.json
+var referenceHashes []byte
+
+// TestCrossImplementationHashMatching validates that the TinyGo implementation
+// produces the reference hash, status and stage hashes of every test vector,
+// under the conformance policy every task shares
+func TestCrossImplementationHashMatching(t *testing.T) {
+	conformance.Run(t, conformance.Task{
+		Name:    "json_parse",
+		Schema:  ReferenceSchema(),
+		Size:    unsafe.Sizeof(JsonParseParams{}),
+		Run:     RunTaskV2,
+		Vectors: referenceHashes,
+		Source:  "testdata/reference_hashes.json",
+	})
+}
+
+// TestWebAssemblyInterfaceCompatibility verifies that the WebAssembly interface
+// correctly handles parameter allocation, memory layout, and function calls.
+func TestWebAssemblyInterfaceCompatibility(t *testing.T) {
+	// Test parameter allocation and passing
+	paramPtr := Alloc(parameterMemorySize)
+	if paramPtr == 0 {
+		t.Fatal("❌ MEMORY ALLOCATION FAILED\nWebAssembly interface Alloc() returned null pointer.\nThis will prevent proper parameter passing from benchmark harness.")
+	}
+
+	// Test parameter writing and reading
+	testParams := []uint32{testRecordCount, testSeed}
+	paramSlice := (*[2]uint32)(unsafe.Pointer(paramPtr))
+	paramSlice[0] = testParams[0]
+	paramSlice[1] = testParams[1]
+
+	// Verify parameters can be read back correctly
+	if paramSlice[0] != testParams[0] || paramSlice[1] != testParams[1] {
+		t.Errorf("❌ PARAMETER PASSING INCOMPATIBLE\nParameter write/read failed - memory layout differs from expectation.\nExpected: [%d, %d], Got: [%d, %d]\nThis will cause incorrect WebAssembly interop.", testParams[0], testParams[1], paramSlice[0], paramSlice[1])
+	} else {
+		t.Logf("✅ WebAssembly interface compatible with benchmark harness")
+	}
+
+	// Test init function (should not panic)
+	Init(testParams[1])
+	t.Logf("✅ Init function operates correctly")
+
+	// Test run_task with valid parameters
+	hash := RunTask(paramPtr)
+	if hash == 0 {
+		t.Error("❌ RUN_TASK EXECUTION FAILED\nrunTask() returned 0, indicating parse error or execution failure.\nCheck JSON generation, parsing, and hash calculation logic.")
+	} else {
+		t.Logf("✅ RunTask() executed successfully with hash: %d", hash)
+	}
+}
@@ -0,0 +1,39 @@
+package jsonparse
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// FuzzParseJsonString feeds the hand-rolled parser arbitrary documents. It
+// must return records or an error, never panic, and records whose names need
+// no escaping must survive serializing and parsing again. Inputs that once
+// failed are kept in testdata/fuzz/FuzzParseJsonString and run as regression
+// cases by go test; go test -fuzz=FuzzParseJsonString searches for new ones.
+func FuzzParseJsonString(f *testing.F) {
+	f.Add(serializeToJson(generateJsonRecords(3, 1, 0)))
+	f.Add(`[]`)
+	f.Add(` [ {"name":"a\"b\\c\/\n\t\r","flag":false,"value":-2147483648,"id":4294967295} ] `)
+	f.Add(`[{"id":1,"value":2,"flag":true,"name":"x"},]`)
+	f.Add(`[{"id":1,"id":1}]`)
+	f.Add(`[{"id":99999999999999999999,"value":1,"flag":true,"name":""}]`)
+	f.Add(`[{"id":1,"value":1,"flag":tru`)
+	f.Add(`[{"name":"\`)
+	f.Fuzz(func(t *testing.T, document string) {
+		records, err := parseJsonString(document)
+		if err != nil {
+			return
+		}
+		if slices.ContainsFunc(records, func(r JsonRecord) bool { return strings.ContainsAny(r.Name, "\"\\") }) {
+			return // serializeToJson writes names as they are
+		}
+		again, err := parseJsonString(serializeToJson(records))
+		if err != nil {
+			t.Fatalf("parsing the serialized records of %q: %v", document, err)
+		}
+		if !slices.Equal(again, records) && len(records) > 0 {
+			t.Fatalf("records of %q changed in a round trip: %v, then %v", document, records, again)
+		}
+	})
+}
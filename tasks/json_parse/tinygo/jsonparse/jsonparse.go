@@ -0,0 +1,1536 @@
+// Package jsonparse implements the JSON parsing benchmark task. It builds for
+// the host as well as for WebAssembly, so the task can be tested, benchmarked
+// and profiled natively; the module's main package only wraps the exported
+// entry points as //go:export functions.
+package jsonparse
+
+// The reference hashes in data/reference_hashes are this implementation's
+// output, written by cmd/genrefs; regenerate them after changing what a task
+// hashes, and go test in cmd/genrefs fails until then
+//
+//go:generate go run -C ../../../../cmd/genrefs . json_parse
+
+import (
+	"strings"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Constants for improved maintainability and performance
+const (
+	// Field bitmasks for JSON object validation
+	fieldMaskID    uint8 = 1 << 0 // 0001
+	fieldMaskValue uint8 = 1 << 1 // 0010
+	fieldMaskFlag  uint8 = 1 << 2 // 0100
+	fieldMaskName  uint8 = 1 << 3 // 1000
+	fieldMaskAll   uint8 = 15     // 1111 (all 4 fields)
+
+	// JSON parsing constants
+	namePrefix = "a" // Prefix for generated names
+)
+
+// Record counts for each scale tier, mirroring the benchmark configuration
+// files (common.ScaleCustom uses the raw RecordCount field)
+var scaleRecordCounts = [...]uint32{
+	common.ScaleMicro:  500,
+	common.ScaleSmall:  5000,
+	common.ScaleMedium: 15000,
+	common.ScaleLarge:  30000,
+}
+
+// Workload profiles control how much of the document is resident at once:
+// the default and memory profiles build one document holding every record,
+// the compute profile processes small cache-resident batches in sequence.
+//
+// Verification levels: parsing is the measured workload, so even
+// common.VerifyNone parses the document (returning a wrapping sum of the
+// parsed values); common.VerifyFull adds a re-serialization compared
+// byte-for-byte.
+
+// Records per batch in the compute profile (~3KB of JSON)
+const computeBatchRecords = 64
+
+// Largest accepted record count, also the calibration cap (matches the harness MAX_JSON_RECORDS limit)
+const maxRecordCount = 1_000_000
+
+// Longest serialized record at maxRecordCount, separating comma included:
+// {"id":1000000,"value":-2147483648,"flag":false,"name":"a1000000"},
+const maxRecordBytes = 66
+
+// Heap bytes of one JsonRecord and its longest name, "a1000000"
+const recordHeapBytes = int(unsafe.Sizeof(JsonRecord{})) + 8
+
+// Record count multiplier chosen by the last self-calibrated run (1 = not scaled)
+var lastScaleFactor uint32 = 1
+
+// Work performed by the last measured run, exposed through get_work_metrics.
+// ElementsProcessed counts round-tripped records and BytesTouched the JSON
+// bytes serialized plus parsed.
+var lastWorkMetrics common.WorkMetrics
+
+// Status of the last run, reported by run_task_v2
+var lastStatus = common.StatusOK
+
+// Host-clock duration of the last measured run, reported by run_task_timed
+var lastElapsedMs float64
+
+// Set by run_task64 so the measured run also computes the 64-bit FNV-1a hash;
+// hasHash64 reports whether lastHash64 was set
+var (
+	wideHash   bool
+	lastHash64 uint64
+	hasHash64  bool
+)
+
+// Arena for parse buffers of arena-allocated runs (records hold strings and
+// stay on the GC heap); documentArena is set only while such a run executes
+var (
+	scratchArena  common.Arena
+	documentArena *common.Arena
+)
+
+// Parameter limits enforced by run_task, exposed through get_limits
+var taskLimits = Limits{
+	WordCount:           uint32(unsafe.Sizeof(Limits{})/4 - 1),
+	MaxAllocationSize:   common.MaxAllocationSize,
+	MaxWarmupIterations: common.MaxWarmupIterations,
+	MaxScale:            common.ScaleLarge,
+	MaxProfile:          common.ProfileMemory,
+	MaxVerification:     common.VerifyFull,
+	MaxAllocator:        common.AllocatorArena,
+	MaxHashAlgorithm:    common.HashXXHash32,
+	MaxGenerator:        common.GeneratorHost,
+	MaxRecordCount:      maxRecordCount,
+}
+
+// Stages hashed for get_checkpoints when enabled, in StageNames order; the
+// batched compute profile streams every batch into one hash per stage
+const (
+	stageInput     = common.StageInput // Generated records, hashed like the result
+	stageSerialize = iota              // Serialized document bytes
+	stageParse                         // Parsed records, FNV-1a whatever the hash algorithm
+	stageOutput                        // The run's result hash
+)
+
+// StageNames names the checkpoint stages in get_task_info
+var StageNames = []string{"input", "serialize", "parse", "output"}
+
+// Size of an element of get_output: a parsed record as little-endian
+// {u32 id, i32 value, u32 flag, u32 FNV-1a hash of the name}
+const outputElementSize = 16
+
+// Params layout read by every run, built once so reading params does not allocate
+var paramFields = ParamFields()
+
+// Length-prefixed task metadata JSON, exposed through get_task_info
+var taskInfo = common.EncodeTaskInfo(common.TaskInfo{
+	Task:       "json_parse",
+	Language:   "tinygo",
+	Variant:    "recursive-descent",
+	ParamsSize: unsafe.Sizeof(JsonParseParams{}),
+	Params:     ParamFields(),
+	Stages:     StageNames,
+})
+
+// Known-answer vectors run by self_test, from data/reference_hashes/json_parse.json
+var selfTestVectors = []common.KnownAnswer[JsonParseParams]{
+	{Name: "single_record", Params: JsonParseParams{RecordCount: 1, Seed: 12345}, Hash: 2570755639},
+	{Name: "systematic_2_2", Params: JsonParseParams{RecordCount: 5, Seed: 42}, Hash: 196198558},
+	{Name: "systematic_3_6", Params: JsonParseParams{RecordCount: 10, Seed: 4294967295}, Hash: 3883069239},
+}
+
+// Global seed for reproducible random number generation
+var globalSeed uint64
+
+// WebAssembly C-style interface exports
+
+// Init implements init
+func Init(seed uint32) {
+	// Initialize random number generator with provided seed
+	// This ensures reproducible test data generation across runs
+	globalSeed = uint64(seed)
+}
+
+// Init64 implements init64
+func Init64(seed uint64) {
+	// 64-bit variant of init; JS hosts pass the seed as a BigInt
+	globalSeed = seed
+}
+
+// Alloc implements alloc
+func Alloc(nBytes uint32) uintptr {
+	// Allocate memory buffer of specified size for parameter passing
+	// Returns pointer to allocated memory block (0 for empty or oversized requests)
+	return common.Alloc(nBytes)
+}
+
+// AllocUninitialized implements alloc_uninitialized
+func AllocUninitialized(nBytes uint32) uintptr {
+	// Like alloc, for a buffer the host overwrites in full: a released one
+	// is reused without zeroing
+	return common.AllocUninitialized(nBytes)
+}
+
+// Dealloc implements dealloc
+func Dealloc(ptr uintptr) {
+	// Unpin a buffer returned by alloc; named dealloc because TinyGo's
+	// wasm runtime already exports malloc/free
+	common.Free(ptr)
+}
+
+// GetScaleFactor implements get_scale_factor
+func GetScaleFactor() uint32 {
+	// Record count multiplier chosen by self-calibration in the last run
+	return lastScaleFactor
+}
+
+// GetWorkMetrics implements get_work_metrics
+func GetWorkMetrics() uintptr {
+	// Pointer to the WorkMetrics of the last run for throughput reporting
+	return uintptr(unsafe.Pointer(&lastWorkMetrics))
+}
+
+// GetMemoryStats implements get_memory_stats
+func GetMemoryStats() uintptr {
+	// Heap usage, cumulative allocations and GC cycles, sampled now
+	return uintptr(unsafe.Pointer(common.SnapshotMemoryStats()))
+}
+
+// ParamsFingerprint implements params_fingerprint
+func ParamsFingerprint() uint32 {
+	// Hash of the JsonParseParams layout so the harness can detect drift
+	return layoutFingerprint()
+}
+
+// GetLimits implements get_limits
+func GetLimits() uintptr {
+	// Pointer to the Limits struct so the harness can build valid parameter sweeps
+	return uintptr(unsafe.Pointer(&taskLimits))
+}
+
+// ABIVersion implements abi_version
+func ABIVersion() uint32 {
+	// Lets a host check compatibility before reading anything else
+	return common.ABIVersion
+}
+
+// GetTaskInfo implements get_task_info
+func GetTaskInfo() uintptr {
+	// Describe the task, its algorithm and params schema for the harness
+	return uintptr(unsafe.Pointer(&taskInfo[0]))
+}
+
+// ResetArena implements reset_arena
+func ResetArena() {
+	// Release the parse buffers held by the last arena-allocated run
+	scratchArena.Reset()
+}
+
+// ReserveMemory implements reserve_memory
+func ReserveMemory(paramsPtr uintptr) uint32 {
+	// Size the parse buffer arena for the largest run the host will make, or
+	// leave the reserved mode when paramsPtr is 0
+	lastStatus = common.StatusOK
+	common.ClearLastError()
+	common.ReleaseReservation()
+	if paramsPtr == 0 {
+		return lastStatus
+	}
+
+	params, _, status, message := prepareParams(paramsPtr)
+	if status != common.StatusOK {
+		fail(status, message)
+		return lastStatus
+	}
+	common.ReserveArena(&scratchArena, workingSet(&params))
+	return lastStatus
+}
+
+// SetMemoryBudget implements set_memory_budget
+func SetMemoryBudget(pages uint32) {
+	// A budget of 0 pages lifts the cap
+	common.SetMemoryBudget(pages)
+}
+
+// GetMaxMemoryPages implements get_max_memory_pages
+func GetMaxMemoryPages() uint32 {
+	// Pages memory may grow to, the budget when one is set
+	return common.MaxMemoryPages()
+}
+
+// HasThreads implements has_threads
+func HasThreads() uint32 {
+	// Always 0: TinyGo builds are serial
+	return common.HasThreads()
+}
+
+// SetThreadCount implements set_thread_count
+func SetThreadCount(n uint32) uint32 {
+	// The task has no parallel variant, so every request is answered with 1
+	return common.SetThreadCount(n)
+}
+
+// HasSIMD implements has_simd
+func HasSIMD() uint32 {
+	// Parsing is byte-serial, so SIMD builds differ only in compiler output
+	return common.HasSIMD()
+}
+
+// GetCancelPtr implements get_cancel_ptr
+func GetCancelPtr() uintptr {
+	// Host stores nonzero here to stop the current run with StatusCancelled
+	return common.CancelPtr()
+}
+
+// SetCheckpoints implements set_checkpoints
+func SetCheckpoints(enabled uint32) {
+	// Stage hashing slows runs down, so the host enables it for diagnosis only
+	common.EnableCheckpoints(enabled != 0)
+}
+
+// HashInput implements hash_input
+func HashInput() uint32 {
+	// Hash of the records generated by the last run, 0 unless checkpoints were enabled
+	hash, _ := common.CheckpointHash(stageInput)
+	return hash
+}
+
+// GetCheckpoints implements get_checkpoints
+func GetCheckpoints() uintptr {
+	// Pointer to {u32 count, u32 recorded mask, u32 hashes[8]}
+	return common.CheckpointsPtr()
+}
+
+// SetOutput implements set_output
+func SetOutput(enabled uint32) {
+	// Keeping the parsed records costs a copy per run, so the host enables it for diagnosis only
+	common.EnableOutput(enabled != 0)
+}
+
+// GetOutput implements get_output
+func GetOutput() uintptr {
+	// Pointer to {u32 ptr, u32 len, u32 element size} of the last run's parsed records
+	return common.OutputPtr()
+}
+
+// GetResultPtr implements get_result_ptr
+func GetResultPtr() uintptr {
+	// Module-owned block describing the last run, rewritten by every run
+	return common.ResultPtr()
+}
+
+// GetLastErrorPtr implements get_last_error_ptr
+func GetLastErrorPtr() uintptr {
+	// Address of the message describing the last failed run
+	return common.LastErrorPtr()
+}
+
+// GetLastErrorLen implements get_last_error_len
+func GetLastErrorLen() uint32 {
+	// Message length in bytes (0 after a successful run)
+	return common.LastErrorLen()
+}
+
+// GetErrorCode implements get_error_code
+func GetErrorCode() uint32 {
+	// Shared code of the last parameter rejection, ErrNone otherwise
+	return common.ErrorCode()
+}
+
+// GetPanicPtr implements get_panic_ptr
+func GetPanicPtr() uintptr {
+	// Panic message of the last run_task call, kept apart from the last error
+	return common.PanicMessagePtr()
+}
+
+// GetPanicLen implements get_panic_len
+func GetPanicLen() uint32 {
+	// Message length in bytes (0 unless the last run panicked)
+	return common.PanicMessageLen()
+}
+
+// RunTask64 implements run_task64
+func RunTask64(paramsPtr uintptr) uint64 {
+	// 64-bit FNV-1a result hash; checksum levels return the checksum widened
+	wideHash, lastHash64, hasHash64 = true, 0, false
+	hash := RunTask(paramsPtr)
+	wideHash = false
+
+	if !hasHash64 {
+		return uint64(hash)
+	}
+	return lastHash64
+}
+
+// RunTaskTimed implements run_task_timed
+func RunTaskTimed(paramsPtr, resultPtr uintptr) uint32 {
+	// Time only the measured run, leaving out warm-ups and call overhead
+	hash := RunTask(paramsPtr)
+	if resultPtr != 0 {
+		common.TimedResult{
+			Status:    lastStatus,
+			Hash:      hash,
+			ElapsedMs: lastElapsedMs,
+		}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TimedResultSize))
+	}
+	return lastStatus
+}
+
+// RunTaskV2 implements run_task_v2
+func RunTaskV2(paramsPtr, resultPtr uintptr) uint32 {
+	// Report the status separately so a zero hash is never read as an error
+	hash := RunTask(paramsPtr)
+	if resultPtr != 0 {
+		common.TaskResult{Status: lastStatus, Hash: hash}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TaskResultSize))
+	}
+	return lastStatus
+}
+
+// RunTaskPacked implements run_task_packed
+func RunTaskPacked(paramsPtr uintptr) uint64 {
+	// Status and hash in one i64; TinyGo cannot export multi-value results
+	hash := RunTask(paramsPtr)
+	return common.PackResult(lastStatus, hash)
+}
+
+// ValidateParams implements validate_params
+func ValidateParams(paramsPtr uintptr) uint32 {
+	// Check parameters exactly as run_task would, without running the workload
+	lastStatus = common.StatusOK
+	common.ClearLastError()
+
+	if _, _, status, message := prepareParams(paramsPtr); status != common.StatusOK {
+		fail(status, message)
+	}
+	return lastStatus
+}
+
+// SelfTest implements self_test
+func SelfTest() uint32 {
+	return common.SelfTest(selfTestVectors, func(params *JsonParseParams, result *common.TaskResult) uint32 {
+		return RunTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+	})
+}
+
+// RunTask implements run_task
+func RunTask(paramsPtr uintptr) (hash uint32) {
+	// Main entry point for JSON parsing benchmark
+	// Returns FNV-1a hash of parsed data for verification
+	defer func() { publishResult(hash) }()
+	defer common.RecoverPanic(&lastStatus) // Report panics as StatusPanicked, not a wasm trap
+	lastScaleFactor = 1
+	lastWorkMetrics = common.WorkMetrics{}
+	lastStatus = common.StatusOK
+	lastElapsedMs = 0
+	common.ClearLastError()
+	common.ClearPanic()
+	common.ClearCancel()
+	common.ResetCheckpoints(uint32(len(StageNames)))
+	common.ResetOutput(outputElementSize)
+
+	params, scaleFactor, status, message := prepareParams(paramsPtr)
+	if status != common.StatusOK {
+		return fail(status, message)
+	}
+	lastScaleFactor = scaleFactor
+	common.Log(common.LevelDebug, "run_task: parameters accepted")
+
+	// Warm-up runs stabilize allocator state and are discarded
+	for i := uint32(0); i < params.WarmupIterations; i++ {
+		executeWorkload(&params)
+	}
+
+	common.SettleHeap()
+	common.StartAllocCount()
+	start := common.NowMs()
+	hash = executeWorkload(&params)
+	lastElapsedMs = common.NowMs() - start
+	common.StopAllocCount()
+	if lastStatus == common.StatusOK && common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageOutput, hash)
+	}
+	return hash
+}
+
+// publishResult fills the get_result_ptr block from the run that just ended,
+// panicked runs included. The 64-bit hash is the one run_task64 returns.
+func publishResult(hash uint32) {
+	result := common.Result{
+		Status:    lastStatus,
+		Hash:      hash,
+		ElapsedMs: lastElapsedMs,
+		Metrics:   lastWorkMetrics,
+	}
+	if wideHash {
+		result.Flags |= common.ResultHasHash64
+		result.Hash64 = uint64(hash)
+		if hasHash64 {
+			result.Hash64 = lastHash64
+		}
+	}
+	common.PublishResult(result)
+}
+
+// Read, resolve, validate and calibrate the parameters at paramsPtr, returning
+// the workload run_task executes or the reason it would be rejected; its only
+// side effect is the error code of a rejection, so validate_params can share it
+func prepareParams(paramsPtr uintptr) (JsonParseParams, uint32, uint32, string) {
+	if paramsPtr == 0 {
+		status, message := common.Reject(common.ErrNullParams, "null params pointer")
+		return JsonParseParams{}, 1, status, message
+	}
+
+	// Copy the parameters out of memory, decoding an encoded params buffer
+	hostParams, status, message := common.ReadParams[JsonParseParams](unsafe.Pointer(paramsPtr), paramFields)
+	if status != common.StatusOK {
+		return JsonParseParams{}, 1, status, message
+	}
+
+	// Resolve scale tier presets on the copy of the host-owned parameters
+	params, ok := resolveScale(hostParams)
+	if !ok {
+		status, message := common.Reject(common.ErrUnknownScale, "unknown scale tier")
+		return JsonParseParams{}, 1, status, message
+	}
+
+	if status, message := parameterStatus(&params); status != common.StatusOK {
+		return JsonParseParams{}, 1, status, message
+	}
+
+	params, scaleFactor := calibrateWorkload(params)
+	if status, message := common.CheckReservation(workingSet(&params)); status != common.StatusOK {
+		return JsonParseParams{}, 1, status, message
+	}
+	if status, message := common.CheckMemoryBudget(workingSet(&params), heapBytes(&params)); status != common.StatusOK {
+		return JsonParseParams{}, 1, status, message
+	}
+	return params, scaleFactor, common.StatusOK, ""
+}
+
+// Run the configured profile on validated parameters and return the verification hash
+func executeWorkload(params *JsonParseParams) uint32 {
+	common.ResetOutput(outputElementSize) // Each run, warm-ups included, starts its output afresh
+	if common.ScratchAllocator(params.Allocator) == common.AllocatorArena {
+		scratchArena.Reset()
+		documentArena = &scratchArena
+		defer func() { documentArena = nil }()
+	}
+
+	if params.Profile == common.ProfileCompute {
+		return runBatchedRoundTrip(params)
+	}
+
+	// Generate reproducible test data using provided seed
+	records := generateJsonRecords(int(params.RecordCount), common.JoinSeed(params.Seed, params.SeedHigh), params.Generator)
+	// Note: Empty arrays are valid (when RecordCount is 0)
+
+	// Serialize records to compact JSON format
+	jsonStr := serializeToJson(records)
+	// Note: Empty arrays serialize to "[]" which is valid
+
+	if common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageInput, fnv1aHashRecords(records))
+		common.RecordCheckpoint(stageSerialize, common.HashBytes(common.FNVOffsetBasis, []byte(jsonStr)))
+	}
+
+	// Phases are the loop boundaries of this profile, so cancellation is polled between them
+	if common.Cancelled() {
+		return fail(common.StatusCancelled, "run cancelled by the host")
+	}
+
+	// Parse JSON string back to verify round-trip correctness
+	parsedRecords, err := parseJsonString(jsonStr)
+	if err != nil {
+		return fail(common.StatusVerificationFailed, err.Error()) // Error: parsing failed
+	}
+	if len(parsedRecords) != len(records) {
+		return fail(common.StatusVerificationFailed, "parsed record count differs from generated") // Error: count mismatch
+	}
+
+	lastWorkMetrics = documentMetrics(len(parsedRecords), len(jsonStr))
+	if common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageParse, fnv1aHashRecords(parsedRecords))
+	}
+	if common.OutputEnabled() {
+		appendOutputRecords(parsedRecords)
+	}
+
+	switch params.Verification {
+	case common.VerifyNone:
+		return sumRecordValues(0, parsedRecords)
+	case common.VerifyFull:
+		if !roundTripMatches(parsedRecords, jsonStr) {
+			return fail(common.StatusVerificationFailed, "re-serialized document differs from the parsed input") // Error: re-serialized document differs
+		}
+	}
+
+	// Compute the hash of parsed results for verification
+	if wideHash {
+		lastHash64, hasHash64 = fnv1a64UpdateRecords(common.FNV64OffsetBasis, parsedRecords), true
+	}
+	if params.HashAlgorithm == common.HashXXHash32 {
+		return xxh32HashRecords(parsedRecords)
+	}
+	hash := fnv1aHashRecords(parsedRecords)
+	return hash
+}
+
+// Data structures for JSON records
+type JsonRecord struct {
+	ID    uint32 `json:"id"`    // Sequential identifier starting from 1
+	Value int32  `json:"value"` // Pseudo-random integer value
+	Flag  bool   `json:"flag"`  // Boolean derived from value (even = true)
+	Name  string `json:"name"`  // String pattern "a{id}"
+}
+
+// Largest accepted value of each bounded parameter. The common fields come
+// first; WordCount counts the u32 fields after it so a host can read the
+// task-specific tail without knowing the task.
+type Limits struct {
+	WordCount           uint32
+	MaxAllocationSize   uint32
+	MaxWarmupIterations uint32
+	MaxScale            uint32
+	MaxProfile          uint32
+	MaxVerification     uint32
+	MaxAllocator        uint32
+	MaxHashAlgorithm    uint32
+	MaxGenerator        uint32
+	MaxRecordCount      uint32
+}
+
+// Parameters structure for parsing from memory
+type JsonParseParams struct {
+	RecordCount      uint32 // Number of JSON objects to generate and parse
+	Seed             uint32 // Seed for reproducible random data generation
+	Scale            uint32 // Workload scale tier (0 = custom record count)
+	Profile          uint32 // Workload profile (0 = default, 1 = compute, 2 = memory)
+	TargetWork       uint32 // Self-calibration target in thousands of records (0 = off)
+	WarmupIterations uint32 // Discarded workload runs before the hashed run
+	Verification     uint32 // Verification level (0 = hash, 1 = none, 2 = full)
+	Allocator        uint32 // Scratch allocator (0 = GC heap, 1 = arena)
+	HashAlgorithm    uint32 // Verification hash (0 = FNV-1a, 1 = xxHash32)
+	Generator        uint32 // Random data generator (0 = LCG, 1 = PCG32)
+	SeedHigh         uint32 // High 32 bits of a 64-bit seed (0 = 32-bit seed)
+}
+
+// DefaultParams are the params of a run that sets none: the micro scale of
+// configs/bench-quick.yaml with the browser harness's seed
+var DefaultParams = JsonParseParams{RecordCount: 500, Seed: 12345}
+
+// Describe every JsonParseParams field in declaration order
+func ParamFields() []common.ParamField {
+	var p JsonParseParams
+	return []common.ParamField{
+		{Name: "record_count", Type: common.FieldU32, Offset: unsafe.Offsetof(p.RecordCount)},
+		{Name: "seed", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Seed)},
+		{Name: "scale", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Scale)},
+		{Name: "profile", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Profile)},
+		{Name: "target_work", Type: common.FieldU32, Offset: unsafe.Offsetof(p.TargetWork)},
+		{Name: "warmup_iterations", Type: common.FieldU32, Offset: unsafe.Offsetof(p.WarmupIterations)},
+		{Name: "verification", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Verification)},
+		{Name: "allocator", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Allocator)},
+		{Name: "hash_algorithm", Type: common.FieldU32, Offset: unsafe.Offsetof(p.HashAlgorithm)},
+		{Name: "generator", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Generator)},
+		{Name: "seed_high", Type: common.FieldU32, Offset: unsafe.Offsetof(p.SeedHigh)},
+	}
+}
+
+// Hash the (offset, size) of every JsonParseParams field in declaration order,
+// followed by the struct size
+func layoutFingerprint() uint32 {
+	return common.FieldsFingerprint(ParamFields(), unsafe.Sizeof(JsonParseParams{}))
+}
+
+// Parse parameters from WebAssembly memory pointer
+func parseParams(ptr uintptr) *JsonParseParams {
+	if ptr == 0 {
+		return nil
+	}
+	return (*JsonParseParams)(unsafe.Pointer(ptr))
+}
+
+// Validate run options (any seed is accepted)
+func validateParameters(params *JsonParseParams) bool {
+	status, _ := parameterStatus(params)
+	return status == common.StatusOK
+}
+
+// Classify run options as valid, invalid or over the task limits, with a
+// shared error code and a message naming the offending field
+func parameterStatus(params *JsonParseParams) (uint32, string) {
+	var v common.Validator
+	v.AtMost(uint64(params.RecordCount), maxRecordCount, "record count exceeds the maximum") // Bound the document size
+	v.Options(common.Options{
+		Profile:          params.Profile,
+		WarmupIterations: params.WarmupIterations,
+		Verification:     params.Verification,
+		Allocator:        params.Allocator,
+		HashAlgorithm:    params.HashAlgorithm,
+		Generator:        params.Generator,
+	})
+	return v.Result()
+}
+
+// Record the status and message of a failed run and return the legacy
+// run_task error value
+func fail(status uint32, message string) uint32 {
+	lastStatus = status
+	common.SetLastError(message)
+	common.Log(common.LevelWarn, message)
+	return 0
+}
+
+// Replace the record count with the preset for the requested scale tier
+func resolveScale(params JsonParseParams) (JsonParseParams, bool) {
+	if params.Scale == common.ScaleCustom {
+		return params, true
+	}
+	if params.Scale > common.ScaleLarge {
+		return params, false
+	}
+	params.RecordCount = scaleRecordCounts[params.Scale]
+	return params, true
+}
+
+// Double the record count until it reaches TargetWork or the calibration cap,
+// returning the adjusted parameters and the multiplier that was applied
+func calibrateWorkload(params JsonParseParams) (JsonParseParams, uint32) {
+	factor := uint32(1)
+	if params.TargetWork == 0 || params.RecordCount == 0 {
+		return params, factor // Nothing to calibrate (an empty document never grows)
+	}
+
+	target := uint64(params.TargetWork) * common.WorkUnitsPerTarget
+	for uint64(params.RecordCount) < target && uint64(params.RecordCount)*2 <= maxRecordCount {
+		params.RecordCount *= 2
+		factor *= 2
+	}
+
+	return params, factor
+}
+
+// Generate array of JSON record objects with deterministic pseudo-random values
+func generateJsonRecords(count int, seed uint64, generator uint32) []JsonRecord {
+	if count <= 0 {
+		return []JsonRecord{} // Return empty slice, not nil
+	}
+
+	rng := common.NewRand(generator, seed)
+	return generateRecordBatch(0, count, &rng)
+}
+
+// Generate count records starting at record index first, advancing the shared random stream
+func generateRecordBatch(first, count int, rng *common.Rand) []JsonRecord {
+	records := make([]JsonRecord, count)
+
+	for i := 0; i < count; i++ {
+		// Generate next pseudo-random value (the LCG's low bit alternates, PCG32's does not)
+		value := rng.Next()
+		id := first + i + 1
+
+		records[i] = JsonRecord{
+			ID:    uint32(id),        // Sequential ID starting from 1
+			Value: int32(value),      // Pseudo-random signed integer
+			Flag:  (value & 1) == 0,  // Boolean: true if even, false if odd
+			Name:  generatedName(id), // Interned string pattern: "a1", "a2", etc.
+		}
+	}
+
+	return records
+}
+
+// Round-trip the records in small batches so the working set stays in cache.
+// Records and hash state carry over between batches, so the result equals the
+// single-document hash for the same parameters.
+func runBatchedRoundTrip(params *JsonParseParams) uint32 {
+	count := int(params.RecordCount)
+	verification, hashAlgorithm := params.Verification, params.HashAlgorithm
+	hash := common.FNVOffsetBasis
+	xxHash := common.NewXXHash32(0)
+	hash64 := common.FNV64OffsetBasis
+	sum := uint32(0)
+	rng := common.NewRand(params.Generator, common.JoinSeed(params.Seed, params.SeedHigh))
+	documentBytes := 0
+	checkpoints := common.CheckpointsEnabled()
+	inputHash, serializeHash, parseHash := common.FNVOffsetBasis, common.FNVOffsetBasis, common.FNVOffsetBasis
+
+	for first := 0; first < count; first += computeBatchRecords {
+		if common.Cancelled() {
+			return fail(common.StatusCancelled, "run cancelled by the host")
+		}
+		batchSize := min(computeBatchRecords, count-first)
+		records := generateRecordBatch(first, batchSize, &rng)
+
+		jsonStr := serializeToJson(records)
+		parsedRecords, err := parseJsonString(jsonStr)
+		if err != nil {
+			return fail(common.StatusVerificationFailed, err.Error()) // Error: parsing failed
+		}
+		if len(parsedRecords) != batchSize {
+			return fail(common.StatusVerificationFailed, "parsed record count differs from generated") // Error: count mismatch
+		}
+		if checkpoints {
+			inputHash = fnv1aUpdateRecords(inputHash, records)
+			serializeHash = common.HashBytes(serializeHash, []byte(jsonStr))
+			parseHash = fnv1aUpdateRecords(parseHash, parsedRecords)
+		}
+		if common.OutputEnabled() {
+			appendOutputRecords(parsedRecords)
+		}
+
+		switch verification {
+		case common.VerifyNone:
+			sum = sumRecordValues(sum, parsedRecords)
+		case common.VerifyFull:
+			if !roundTripMatches(parsedRecords, jsonStr) {
+				return fail(common.StatusVerificationFailed, "re-serialized batch differs from the parsed input") // Error: re-serialized batch differs
+			}
+			fallthrough
+		default:
+			if hashAlgorithm == common.HashXXHash32 {
+				xxh32AddRecords(&xxHash, parsedRecords)
+			} else {
+				hash = fnv1aUpdateRecords(hash, parsedRecords)
+			}
+		}
+		if wideHash && verification != common.VerifyNone {
+			hash64 = fnv1a64UpdateRecords(hash64, parsedRecords)
+		}
+		documentBytes += len(jsonStr)
+	}
+
+	lastWorkMetrics = documentMetrics(count, documentBytes)
+	if checkpoints {
+		common.RecordCheckpoint(stageInput, inputHash)
+		common.RecordCheckpoint(stageSerialize, serializeHash)
+		common.RecordCheckpoint(stageParse, parseHash)
+	}
+	if verification == common.VerifyNone {
+		return sum
+	}
+	if wideHash {
+		lastHash64, hasHash64 = hash64, true
+	}
+	if hashAlgorithm == common.HashXXHash32 {
+		return xxHash.Sum32()
+	}
+	return hash
+}
+
+// Upper bound on the arena bytes a run takes: the parse buffer of each
+// document, one per batch in the compute profile. Records and the serialized
+// documents hold strings and stay on the GC heap.
+func workingSet(params *JsonParseParams) int {
+	count := int(params.RecordCount)
+	if params.Profile != common.ProfileCompute {
+		return common.ArenaSize(count*maxRecordBytes + 2)
+	}
+	batches := (count + computeBatchRecords - 1) / computeBatchRecords
+	return batches * common.ArenaSize(computeBatchRecords*maxRecordBytes+2)
+}
+
+// Upper bound on the GC heap bytes a run holds at once: the generated and
+// parsed records with their names, the serialized document and its
+// re-serialization, for every record or for one batch in the compute profile
+func heapBytes(params *JsonParseParams) int {
+	count := int(params.RecordCount)
+	if params.Profile == common.ProfileCompute {
+		count = min(count, computeBatchRecords)
+	}
+	return count * (2*recordHeapBytes + 2*maxRecordBytes)
+}
+
+// Re-serialize parsed records and compare with the document they came from
+func roundTripMatches(parsedRecords []JsonRecord, jsonStr string) bool {
+	return serializeToJson(parsedRecords) == jsonStr
+}
+
+// Cheap unverified checksum: a wrapping sum of the parsed record values
+func sumRecordValues(sum uint32, records []JsonRecord) uint32 {
+	for i := range records {
+		sum += uint32(records[i].Value)
+	}
+	return sum
+}
+
+// Work metrics for a round trip: each document byte is written by the
+// serializer and read back by the parser
+func documentMetrics(records, documentBytes int) common.WorkMetrics {
+	return common.WorkMetrics{
+		ElementsProcessed: uint64(records),
+		BytesTouched:      uint64(documentBytes) * 2,
+	}
+}
+
+// Convert record array to compact JSON string format with optimized string building
+func serializeToJson(records []JsonRecord) string {
+	if len(records) == 0 {
+		return "[]"
+	}
+
+	var builder strings.Builder
+	// Pre-allocate approximate capacity to reduce reallocations
+	builder.Grow(len(records) * 50) // Estimate ~50 chars per record
+
+	builder.WriteByte('[')
+
+	for i, record := range records {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+
+		// Build compact JSON object with direct string operations
+		builder.WriteString(`{"id":`)
+		writeUint32(&builder, record.ID)
+		builder.WriteString(`,"value":`)
+		writeInt32(&builder, record.Value)
+		builder.WriteString(`,"flag":`)
+		writeBool(&builder, record.Flag)
+		builder.WriteString(`,"name":"`)
+		builder.WriteString(record.Name)
+		builder.WriteString(`"}`)
+	}
+
+	builder.WriteByte(']')
+	return builder.String()
+}
+
+// parseError is why a document failed to parse: a reason code, the byte it
+// stopped at for the reasons that name one, and the field being parsed, packed
+// into one word. It formats its message only when asked, so the parser
+// reports failures without the errors package or building strings.
+type parseError uint32
+
+// Reasons a document fails to parse, indexing parseReasons
+const (
+	errEmptyDocument uint8 = iota
+	errExpectedArray
+	errArrayEnd
+	errArraySeparator
+	errExpectedObject
+	errExpectedColon
+	errDuplicateField
+	errUnknownField
+	errObjectEnd
+	errObjectSeparator
+	errMissingFields
+	errExpectedString
+	errIncompleteEscape
+	errUnterminatedString
+	errInvalidEscape
+	errInputEnd
+	errExpectedDigit
+	errNumberOverflow
+	errNumberRange
+	errInvalidBoolean
+)
+
+var parseReasons = [...]string{
+	errEmptyDocument:      "empty JSON string",
+	errExpectedArray:      "expected '[' at start of JSON array",
+	errArrayEnd:           "unexpected end of JSON array",
+	errArraySeparator:     "expected ',' or ']', got ",
+	errExpectedObject:     "expected '{' at start of JSON object",
+	errExpectedColon:      "expected ':' after field name",
+	errDuplicateField:     "duplicate ",
+	errUnknownField:       "unknown field",
+	errObjectEnd:          "unexpected end of JSON object",
+	errObjectSeparator:    "expected ',' or '}', got ",
+	errMissingFields:      "missing required fields in JSON object",
+	errExpectedString:     "expected '\"' at start of string",
+	errIncompleteEscape:   "incomplete escape sequence",
+	errUnterminatedString: "unterminated string",
+	errInvalidEscape:      "invalid escape sequence: \\",
+	errInputEnd:           "unexpected end of input",
+	errExpectedDigit:      "expected digit",
+	errNumberOverflow:     "number overflow",
+	errNumberRange:        "number out of range",
+	errInvalidBoolean:     "invalid boolean value",
+}
+
+// Parts of a record a parse error can be in, indexing parseFields
+const (
+	inDocument uint8 = iota
+	inKey
+	inID
+	inValue
+	inFlag
+	inName
+)
+
+var parseFields = [...]string{inKey: "field name", inID: "id field", inValue: "value field", inFlag: "flag field", inName: "name field"}
+
+// parseErrorInObject marks a failure inside an array element
+const parseErrorInObject parseError = 1 << 24
+
+// at records the byte a separator or escape failure stopped at
+func (e parseError) at(ch byte) parseError {
+	return e | parseError(ch)<<8
+}
+
+// in records the field whose value failed, or the field a duplicate repeats
+func (e parseError) in(field uint8) parseError {
+	return e | parseError(field)<<16
+}
+
+func (e parseError) Error() string {
+	reason, ch, field := uint8(e), byte(e>>8), uint8(e>>16)
+	message := parseReasons[reason]
+	switch reason {
+	case errArraySeparator, errObjectSeparator:
+		message += "'" + string(rune(ch)) + "'"
+	case errInvalidEscape:
+		message += string(rune(ch))
+	case errDuplicateField:
+		message += parseFields[field]
+		field = inDocument
+	}
+	if field != inDocument {
+		message = "failed to parse " + parseFields[field] + ": " + message
+	}
+	if e&parseErrorInObject != 0 {
+		message = "failed to parse object: " + message
+	}
+	return message
+}
+
+// Parse JSON string to JsonRecord objects with optimized byte-based parsing
+func parseJsonString(jsonStr string) ([]JsonRecord, error) {
+	if jsonStr == "" {
+		return nil, parseError(errEmptyDocument)
+	}
+
+	bytes := documentBytes(jsonStr)
+	pos := 0
+
+	// Skip leading whitespace
+	skipWhitespace(bytes, &pos)
+
+	if pos >= len(bytes) || bytes[pos] != '[' {
+		return nil, parseError(errExpectedArray)
+	}
+
+	return parseJsonArray(bytes, &pos)
+}
+
+// Copy the document into a parse buffer, taken from the arena during
+// arena-allocated runs. Parsed strings are copied out of the buffer, so it
+// never holds pointers.
+func documentBytes(jsonStr string) []byte {
+	if documentArena == nil {
+		return []byte(jsonStr)
+	}
+	bytes := documentArena.Bytes(len(jsonStr))
+	copy(bytes, jsonStr)
+	return bytes
+}
+
+// Skip whitespace characters in JSON parsing
+func skipWhitespace(bytes []byte, pos *int) {
+	for *pos < len(bytes) {
+		ch := bytes[*pos]
+		if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+			*pos++
+		} else {
+			break
+		}
+	}
+}
+
+// Parse JSON array starting with '[' character with pre-allocation
+func parseJsonArray(bytes []byte, pos *int) ([]JsonRecord, error) {
+	// Consume opening '['
+	*pos++
+	skipWhitespace(bytes, pos)
+
+	// Pre-allocate slice based on estimated capacity
+	estimatedCapacity := len(bytes) / 50 // Estimate ~50 bytes per record
+	records := make([]JsonRecord, 0, estimatedCapacity)
+
+	// Handle empty array
+	if *pos < len(bytes) && bytes[*pos] == ']' {
+		*pos++
+		return records, nil
+	}
+
+	// Parse array elements
+	for {
+		record, err := parseJsonObject(bytes, pos)
+		if err != nil {
+			return nil, err.(parseError) | parseErrorInObject
+		}
+
+		records = append(records, record)
+
+		skipWhitespace(bytes, pos)
+		if *pos >= len(bytes) {
+			return nil, parseError(errArrayEnd)
+		}
+
+		ch := bytes[*pos]
+		if ch == ']' {
+			*pos++ // Consume closing ']'
+			break
+		} else if ch == ',' {
+			*pos++ // Consume comma separator
+			skipWhitespace(bytes, pos)
+		} else {
+			return nil, parseError(errArraySeparator).at(ch)
+		}
+	}
+
+	return records, nil
+}
+
+// Parse single JSON object starting with '{' character
+func parseJsonObject(bytes []byte, pos *int) (JsonRecord, error) {
+	skipWhitespace(bytes, pos)
+
+	if *pos >= len(bytes) || bytes[*pos] != '{' {
+		return JsonRecord{}, parseError(errExpectedObject)
+	}
+
+	*pos++ // Consume opening '{'
+	skipWhitespace(bytes, pos)
+
+	var record JsonRecord
+	var fieldsFound uint8 = 0 // Track which fields we've parsed using bitmask
+
+	// Parse object fields
+	for {
+		// Parse field name
+		fieldName, err := parseJsonStringValue(bytes, pos)
+		if err != nil {
+			return JsonRecord{}, err.(parseError).in(inKey)
+		}
+
+		skipWhitespace(bytes, pos)
+		if *pos >= len(bytes) || bytes[*pos] != ':' {
+			return JsonRecord{}, parseError(errExpectedColon)
+		}
+		*pos++ // Consume ':'
+		skipWhitespace(bytes, pos)
+
+		// Parse field value based on field name
+		switch fieldName {
+		case "id":
+			if fieldsFound&fieldMaskID != 0 {
+				return JsonRecord{}, parseError(errDuplicateField).in(inID)
+			}
+			id, err := parseJsonID(bytes, pos)
+			if err != nil {
+				return JsonRecord{}, err.(parseError).in(inID)
+			}
+			record.ID = id
+			fieldsFound |= fieldMaskID
+
+		case "value":
+			if fieldsFound&fieldMaskValue != 0 {
+				return JsonRecord{}, parseError(errDuplicateField).in(inValue)
+			}
+			value, err := parseJsonNumber(bytes, pos)
+			if err != nil {
+				return JsonRecord{}, err.(parseError).in(inValue)
+			}
+			record.Value = value
+			fieldsFound |= fieldMaskValue
+
+		case "flag":
+			if fieldsFound&fieldMaskFlag != 0 {
+				return JsonRecord{}, parseError(errDuplicateField).in(inFlag)
+			}
+			flag, err := parseJsonBoolean(bytes, pos)
+			if err != nil {
+				return JsonRecord{}, err.(parseError).in(inFlag)
+			}
+			record.Flag = flag
+			fieldsFound |= fieldMaskFlag
+
+		case "name":
+			if fieldsFound&fieldMaskName != 0 {
+				return JsonRecord{}, parseError(errDuplicateField).in(inName)
+			}
+			name, err := parseJsonStringValue(bytes, pos)
+			if err != nil {
+				return JsonRecord{}, err.(parseError).in(inName)
+			}
+			record.Name = name
+			fieldsFound |= fieldMaskName
+
+		default:
+			return JsonRecord{}, parseError(errUnknownField)
+		}
+
+		skipWhitespace(bytes, pos)
+		if *pos >= len(bytes) {
+			return JsonRecord{}, parseError(errObjectEnd)
+		}
+
+		ch := bytes[*pos]
+		if ch == '}' {
+			*pos++ // Consume closing '}'
+			break
+		} else if ch == ',' {
+			*pos++ // Consume comma separator
+			skipWhitespace(bytes, pos)
+		} else {
+			return JsonRecord{}, parseError(errObjectSeparator).at(ch)
+		}
+	}
+
+	// Validate that all required fields were found
+	if fieldsFound != fieldMaskAll {
+		return JsonRecord{}, parseError(errMissingFields)
+	}
+
+	return record, nil
+}
+
+// Parse JSON string value enclosed in quotes with zero-copy optimization
+func parseJsonStringValue(bytes []byte, pos *int) (string, error) {
+	if *pos >= len(bytes) || bytes[*pos] != '"' {
+		return "", parseError(errExpectedString)
+	}
+
+	*pos++ // Skip opening quote
+	start := *pos
+	hasEscapes := false
+
+	// Fast scan to find closing quote and detect escapes
+	for *pos < len(bytes) {
+		ch := bytes[*pos]
+		if ch == '"' {
+			// Found closing quote
+			if !hasEscapes {
+				// Fast path: no escapes, keys and generated names come interned
+				result := internString(bytes[start:*pos])
+				*pos++
+				return result, nil
+			}
+			*pos++
+			break
+		} else if ch == '\\' {
+			hasEscapes = true
+			*pos++
+			if *pos >= len(bytes) {
+				return "", parseError(errIncompleteEscape)
+			}
+			*pos++
+		} else {
+			*pos++
+		}
+	}
+
+	if !hasEscapes {
+		return "", parseError(errUnterminatedString)
+	}
+
+	// Process string with escapes
+	var builder strings.Builder
+	i := start
+
+	for i < *pos-1 {
+		ch := bytes[i]
+		if ch == '\\' {
+			i++
+			if i >= *pos-1 {
+				break
+			}
+			escaped := bytes[i]
+			switch escaped {
+			case '"', '\\', '/':
+				builder.WriteByte(escaped)
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			case 'r':
+				builder.WriteByte('\r')
+			default:
+				return "", parseError(errInvalidEscape).at(escaped)
+			}
+		} else {
+			builder.WriteByte(ch)
+		}
+		i++
+	}
+
+	return builder.String(), nil
+}
+
+// Parse JSON number value with manual digit parsing (no allocation)
+func parseJsonNumber(bytes []byte, pos *int) (int32, error) {
+	if *pos >= len(bytes) {
+		return 0, parseError(errInputEnd)
+	}
+
+	var result int64 = 0
+	negative := false
+
+	// Handle optional negative sign
+	if bytes[*pos] == '-' {
+		negative = true
+		*pos++
+	}
+
+	if *pos >= len(bytes) || bytes[*pos] < '0' || bytes[*pos] > '9' {
+		return 0, parseError(errExpectedDigit)
+	}
+
+	// Parse digits manually
+	for *pos < len(bytes) && bytes[*pos] >= '0' && bytes[*pos] <= '9' {
+		digit := int64(bytes[*pos] - '0')
+
+		// Check for overflow
+		if result > (9223372036854775807-digit)/10 {
+			return 0, parseError(errNumberOverflow)
+		}
+
+		result = result*10 + digit
+		*pos++
+	}
+
+	if negative {
+		result = -result
+	}
+
+	// Check if value fits in int32
+	if result < -2147483648 || result > 2147483647 {
+		return 0, parseError(errNumberRange)
+	}
+
+	return int32(result), nil
+}
+
+// Parse the id field: an unsigned number up to the largest uint32, every id
+// serializeToJson writes
+func parseJsonID(bytes []byte, pos *int) (uint32, error) {
+	if *pos >= len(bytes) || bytes[*pos] < '0' || bytes[*pos] > '9' {
+		return 0, parseError(errExpectedDigit)
+	}
+
+	var result uint64
+	for *pos < len(bytes) && bytes[*pos] >= '0' && bytes[*pos] <= '9' {
+		result = result*10 + uint64(bytes[*pos]-'0')
+		if result > 4294967295 {
+			return 0, parseError(errNumberRange)
+		}
+		*pos++
+	}
+	return uint32(result), nil
+}
+
+// Parse JSON boolean value (true or false) with byte-based comparison
+func parseJsonBoolean(bytes []byte, pos *int) (bool, error) {
+	// Check for "true" without creating temporary string
+	if *pos+4 <= len(bytes) &&
+		bytes[*pos] == 't' && bytes[*pos+1] == 'r' &&
+		bytes[*pos+2] == 'u' && bytes[*pos+3] == 'e' {
+		*pos += 4
+		return true, nil
+	}
+
+	// Check for "false" without creating temporary string
+	if *pos+5 <= len(bytes) &&
+		bytes[*pos] == 'f' && bytes[*pos+1] == 'a' &&
+		bytes[*pos+2] == 'l' && bytes[*pos+3] == 's' && bytes[*pos+4] == 'e' {
+		*pos += 5
+		return false, nil
+	}
+
+	return false, parseError(errInvalidBoolean)
+}
+
+// Compute FNV-1a hash of all record fields for verification (optimized version)
+func fnv1aHashRecords(records []JsonRecord) uint32 {
+	return fnv1aUpdateRecords(common.FNVOffsetBasis, records)
+}
+
+// Append records to the get_output elements, their name folded to its hash
+// so every element is the same size
+func appendOutputRecords(records []JsonRecord) {
+	for _, record := range records {
+		flag := uint32(0)
+		if record.Flag {
+			flag = 1
+		}
+		nameHash := common.HashBytes(common.FNVOffsetBasis, []byte(record.Name))
+		common.AppendOutputUint32s([]uint32{record.ID, uint32(record.Value), flag, nameHash})
+	}
+}
+
+// Fold record fields into an existing FNV-1a hash state
+func fnv1aUpdateRecords(hash uint32, records []JsonRecord) uint32 {
+	for _, record := range records {
+		// Hash ID field (4 bytes, little-endian)
+		hash = common.HashUint32(hash, record.ID)
+
+		// Hash Value field (4 bytes, little-endian, signed)
+		hash = common.HashUint32(hash, uint32(record.Value))
+
+		// Hash Flag field (1 byte: 1 for true, 0 for false)
+		flagByte := byte(0)
+		if record.Flag {
+			flagByte = 1
+		}
+		hash = common.HashByte(hash, flagByte)
+
+		// Hash Name field (UTF-8 bytes)
+		hash = common.HashBytes(hash, []byte(record.Name))
+	}
+
+	return hash
+}
+
+// 64-bit counterpart of fnv1aUpdateRecords, folding the same fields
+func fnv1a64UpdateRecords(hash uint64, records []JsonRecord) uint64 {
+	for _, record := range records {
+		hash = common.Hash64Uint32(hash, record.ID)
+		hash = common.Hash64Uint32(hash, uint32(record.Value))
+		flagByte := byte(0)
+		if record.Flag {
+			flagByte = 1
+		}
+		hash = common.Hash64Byte(hash, flagByte)
+		hash = common.Hash64Bytes(hash, []byte(record.Name))
+	}
+	return hash
+}
+
+// Compute the xxHash32 of the byte stream fnv1aHashRecords hashes
+func xxh32HashRecords(records []JsonRecord) uint32 {
+	h := common.NewXXHash32(0)
+	xxh32AddRecords(&h, records)
+	return h.Sum32()
+}
+
+// Feed record fields to an xxHash32 state in the fnv1aUpdateRecords byte order
+func xxh32AddRecords(h *common.XXHash32, records []JsonRecord) {
+	for _, record := range records {
+		h.AddUint32(record.ID)
+		h.AddUint32(uint32(record.Value))
+		flagByte := byte(0)
+		if record.Flag {
+			flagByte = 1
+		}
+		h.AddByte(flagByte)
+		h.AddBytes([]byte(record.Name))
+	}
+}
+
+// Optimized helper functions for string building and parsing
+
+// Names of ids below internedNames, past the largest scale preset, are
+// interned: generated and parsed records share one string per name instead
+// of allocating it per record, so runs measure parsing rather than the GC
+const internedNames = 1 << 15
+
+// nameTable holds the generated name of each id below its length, grown on
+// demand by generatedName
+var nameTable []string
+
+// fieldKeys are the record object's keys, interned by internString
+var fieldKeys = [...]string{"id", "value", "flag", "name"}
+
+// Return the generated name of record id, interned when id is small enough
+func generatedName(id int) string {
+	if id >= internedNames {
+		return buildNameString(id)
+	}
+	for len(nameTable) <= id {
+		nameTable = append(nameTable, buildNameString(len(nameTable)))
+	}
+	return nameTable[id]
+}
+
+// Return b as a string, without allocating when it is a record key or an
+// interned generated name
+func internString(b []byte) string {
+	for i := 0; i < len(fieldKeys); i++ {
+		if matchesString(b, fieldKeys[i]) {
+			return fieldKeys[i]
+		}
+	}
+	if id, ok := generatedNameID(b); ok {
+		return generatedName(id)
+	}
+	return string(b)
+}
+
+// Return the id whose generated name is b, when that name is interned
+func generatedNameID(b []byte) (int, bool) {
+	if len(b) <= len(namePrefix) || !matchesString(b[:len(namePrefix)], namePrefix) {
+		return 0, false
+	}
+	digits := b[len(namePrefix):]
+	if len(digits) > 1 && digits[0] == '0' {
+		return 0, false // buildNameString writes no leading zeros
+	}
+	id := 0
+	for _, ch := range digits {
+		if ch < '0' || ch > '9' {
+			return 0, false
+		}
+		id = id*10 + int(ch-'0')
+		if id >= internedNames {
+			return 0, false
+		}
+	}
+	return id, true
+}
+
+// Report whether b holds the bytes of s, without converting either
+func matchesString(b []byte, s string) bool {
+	if len(b) != len(s) {
+		return false
+	}
+	for i := 0; i < len(b); i++ {
+		if b[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Build name string efficiently without fmt.Sprintf
+func buildNameString(id int) string {
+	if id < 10 {
+		// Fast path for single digits (most common case)
+		return namePrefix + string(rune('0'+id))
+	}
+
+	var builder strings.Builder
+	builder.Grow(8) // Pre-allocate for small names
+	builder.WriteString(namePrefix)
+	writeInt(&builder, id)
+	return builder.String()
+}
+
+// Write unsigned 32-bit integer directly to builder, formatted on the stack
+func writeUint32(builder *strings.Builder, value uint32) {
+	var digits [10]byte
+	builder.Write(common.AppendUint(digits[:0], uint64(value)))
+}
+
+// Write signed 32-bit integer directly to builder
+func writeInt32(builder *strings.Builder, value int32) {
+	var digits [11]byte
+	builder.Write(common.AppendInt(digits[:0], int64(value)))
+}
+
+// Write integer directly to builder
+func writeInt(builder *strings.Builder, value int) {
+	var digits [20]byte
+	builder.Write(common.AppendInt(digits[:0], int64(value)))
+}
+
+// Write boolean directly to builder
+func writeBool(builder *strings.Builder, value bool) {
+	if value {
+		builder.WriteString("true")
+	} else {
+		builder.WriteString("false")
+	}
+}
@@ -0,0 +1,1672 @@
+package jsonparse
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Test data generation with deterministic seed
+func TestGenerateJsonRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		count    int
+		seed     uint32
+		expected []JsonRecord
+	}{
+		{
+			name:     "empty records",
+			count:    0,
+			seed:     12345,
+			expected: []JsonRecord{},
+		},
+		{
+			name:  "single record",
+			count: 1,
+			seed:  12345,
+			expected: []JsonRecord{
+				{ID: 1, Value: int32(linearCongruentialGeneratorTest(12345)), Flag: (linearCongruentialGeneratorTest(12345) & 1) == 0, Name: "a1"},
+			},
+		},
+		{
+			name:  "multiple records with seed 0",
+			count: 3,
+			seed:  0,
+			expected: []JsonRecord{
+				{ID: 1, Value: int32(linearCongruentialGeneratorTest(0)), Flag: (linearCongruentialGeneratorTest(0) & 1) == 0, Name: "a1"},
+				{ID: 2, Value: int32(linearCongruentialGeneratorTest(1013904223)), Flag: (linearCongruentialGeneratorTest(1013904223) & 1) == 0, Name: "a2"},
+				{ID: 3, Value: int32(linearCongruentialGeneratorTest(3204258894)), Flag: (linearCongruentialGeneratorTest(3204258894) & 1) == 0, Name: "a3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := generateJsonRecords(tt.count, uint64(tt.seed), common.GeneratorLCG)
+
+			if len(tt.expected) == 0 {
+				if len(result) != 0 {
+					t.Errorf("Expected empty slice, got %v", result)
+				}
+				return
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Errorf("Expected %d records, got %d", len(tt.expected), len(result))
+				return
+			}
+
+			for i, expected := range tt.expected {
+				if result[i].ID != expected.ID {
+					t.Errorf("Record %d: expected ID %d, got %d", i, expected.ID, result[i].ID)
+				}
+				if result[i].Name != expected.Name {
+					t.Errorf("Record %d: expected Name %s, got %s", i, expected.Name, result[i].Name)
+				}
+			}
+		})
+	}
+}
+
+// Helper function for testing LCG
+func linearCongruentialGeneratorTest(seed uint32) uint32 {
+	const multiplier uint32 = 1664525
+	const increment uint32 = 1013904223
+	return (seed * multiplier) + increment
+}
+
+// Test JSON serialization with various input sizes
+func TestSerializeToJson(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  []JsonRecord
+		expected string
+	}{
+		{
+			name:     "empty array",
+			records:  []JsonRecord{},
+			expected: "[]",
+		},
+		{
+			name: "single record",
+			records: []JsonRecord{
+				{ID: 1, Value: 12345, Flag: true, Name: "a1"},
+			},
+			expected: `[{"id":1,"value":12345,"flag":true,"name":"a1"}]`,
+		},
+		{
+			name: "multiple records",
+			records: []JsonRecord{
+				{ID: 1, Value: 123, Flag: false, Name: "a1"},
+				{ID: 2, Value: -456, Flag: true, Name: "a2"},
+			},
+			expected: `[{"id":1,"value":123,"flag":false,"name":"a1"},{"id":2,"value":-456,"flag":true,"name":"a2"}]`,
+		},
+		{
+			name: "special characters in name",
+			records: []JsonRecord{
+				{ID: 1, Value: 0, Flag: false, Name: "test"},
+			},
+			expected: `[{"id":1,"value":0,"flag":false,"name":"test"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := serializeToJson(tt.records)
+			if result != tt.expected {
+				t.Errorf("Expected: %s\nGot: %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Numbers are formatted on the stack, so serializing only allocates the builder
+func TestSerializeAllocations(t *testing.T) {
+	records := []JsonRecord{
+		{ID: 1, Value: -2147483648, Flag: true, Name: "a1"},
+		{ID: 4294967295, Value: 2147483647, Flag: false, Name: "a4294967295"},
+	}
+	want := `[{"id":1,"value":-2147483648,"flag":true,"name":"a1"},` +
+		`{"id":4294967295,"value":2147483647,"flag":false,"name":"a4294967295"}]`
+	if got := serializeToJson(records); got != want {
+		t.Errorf("Expected: %s\nGot: %s", want, got)
+	}
+
+	// Records within the builder's size estimate need a single buffer
+	short := make([]JsonRecord, 50)
+	for i := range short {
+		short[i] = JsonRecord{ID: uint32(i + 1), Value: int32(i * 7), Name: buildNameString(i + 1)}
+	}
+	if allocs := testing.AllocsPerRun(20, func() { serializeToJson(short) }); allocs != 1 {
+		t.Errorf("Serializing made %v allocations, expected only the builder's buffer", allocs)
+	}
+}
+
+// Test JSON parsing with valid and invalid inputs
+func TestParseJsonString(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  []JsonRecord
+		expectErr bool
+	}{
+		{
+			name:      "empty string",
+			input:     "",
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name:      "empty array",
+			input:     "[]",
+			expected:  []JsonRecord{},
+			expectErr: false,
+		},
+		{
+			name:      "array with whitespace",
+			input:     " [ ] ",
+			expected:  []JsonRecord{},
+			expectErr: false,
+		},
+		{
+			name:  "single record",
+			input: `[{"id":1,"value":123,"flag":true,"name":"a1"}]`,
+			expected: []JsonRecord{
+				{ID: 1, Value: 123, Flag: true, Name: "a1"},
+			},
+			expectErr: false,
+		},
+		{
+			name:  "multiple records with whitespace",
+			input: `[ {"id": 1, "value": 123, "flag": false, "name": "a1"} , {"id": 2, "value": -456, "flag": true, "name": "a2"} ]`,
+			expected: []JsonRecord{
+				{ID: 1, Value: 123, Flag: false, Name: "a1"},
+				{ID: 2, Value: -456, Flag: true, Name: "a2"},
+			},
+			expectErr: false,
+		},
+		{
+			name:      "invalid JSON - missing bracket",
+			input:     `{"id":1,"value":123,"flag":true,"name":"a1"}`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON - malformed object",
+			input:     `[{"id":1,"value":123,"flag":true}]`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON - wrong field type",
+			input:     `[{"id":"not_a_number","value":123,"flag":true,"name":"a1"}]`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON - unknown field",
+			input:     `[{"id":1,"value":123,"flag":true,"name":"a1","extra":"field"}]`,
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseJsonString(tt.input)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Errorf("Expected %d records, got %d", len(tt.expected), len(result))
+				return
+			}
+
+			for i, expected := range tt.expected {
+				if result[i] != expected {
+					t.Errorf("Record %d: expected %+v, got %+v", i, expected, result[i])
+				}
+			}
+		})
+	}
+}
+
+// Keys and generated names come from the intern tables, so parsing only
+// allocates the document copy and the records
+func TestParseAllocations(t *testing.T) {
+	records := make([]JsonRecord, 50)
+	for i := range records {
+		// Every record is over the parser's 50-byte estimate, so the records slice never regrows
+		records[i] = JsonRecord{ID: uint32(i + 1), Value: int32(-1_000_000_000 - i), Name: generatedName(i + 1)}
+	}
+	document := serializeToJson(records)
+	if allocs := testing.AllocsPerRun(20, func() { parseJsonString(document) }); allocs != 2 {
+		t.Errorf("Parsing made %v allocations, expected the document copy and the records", allocs)
+	}
+
+	parsed, err := parseJsonString(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range parsed {
+		if parsed[i] != records[i] {
+			t.Errorf("Record %d: expected %+v, got %+v", i, records[i], parsed[i])
+		}
+		if unsafe.StringData(parsed[i].Name) != unsafe.StringData(records[i].Name) {
+			t.Errorf("Record %d: name %q should be the interned string", i, parsed[i].Name)
+		}
+	}
+}
+
+func TestInternString(t *testing.T) {
+	for _, name := range []string{"a0", "a7", "a12345", buildNameString(internedNames - 1)} {
+		if got := internString([]byte(name)); got != name || !slices.Contains(nameTable, got) {
+			t.Errorf("%q should be interned, got %q", name, got)
+		}
+	}
+	if got := internString([]byte("name")); unsafe.StringData(got) != unsafe.StringData(fieldKeys[3]) {
+		t.Error("Record keys should be interned")
+	}
+
+	// Anything else is copied out of the document
+	for _, value := range []string{"", "a", "b1", "a01", "a1x", "a-1", "x", buildNameString(internedNames), "a99999999999999999999"} {
+		if got := internString([]byte(value)); got != value {
+			t.Errorf("internString(%q) = %q", value, got)
+		}
+		if _, ok := generatedNameID([]byte(value)); ok {
+			t.Errorf("%q should not be an interned name", value)
+		}
+	}
+	if len(nameTable) > internedNames {
+		t.Errorf("The name table grew to %d entries, past %d", len(nameTable), internedNames)
+	}
+	if generatedName(internedNames+5) != buildNameString(internedNames+5) {
+		t.Error("Names past the table should still be generated")
+	}
+}
+
+// Test string parsing with escape sequences
+func TestParseJsonStringValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		pos         int
+		expected    string
+		expectedPos int
+		expectErr   bool
+	}{
+		{
+			name:        "simple string",
+			input:       []byte(`"hello"`),
+			pos:         0,
+			expected:    "hello",
+			expectedPos: 7,
+			expectErr:   false,
+		},
+		{
+			name:        "empty string",
+			input:       []byte(`""`),
+			pos:         0,
+			expected:    "",
+			expectedPos: 2,
+			expectErr:   false,
+		},
+		{
+			name:        "string with escaped quote",
+			input:       []byte(`"hello \"world\""`),
+			pos:         0,
+			expected:    `hello "world"`,
+			expectedPos: 17,
+			expectErr:   false,
+		},
+		{
+			name:        "string with escaped backslash",
+			input:       []byte(`"path\\to\\file"`),
+			pos:         0,
+			expected:    `path\to\file`,
+			expectedPos: 16,
+			expectErr:   false,
+		},
+		{
+			name:        "unterminated string",
+			input:       []byte(`"hello`),
+			pos:         0,
+			expected:    "",
+			expectedPos: 0,
+			expectErr:   true,
+		},
+		{
+			name:        "no opening quote",
+			input:       []byte(`hello"`),
+			pos:         0,
+			expected:    "",
+			expectedPos: 0,
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.pos
+			result, err := parseJsonStringValue(tt.input, &pos)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected: %s, Got: %s", tt.expected, result)
+			}
+
+			if pos != tt.expectedPos {
+				t.Errorf("Expected position %d, got %d", tt.expectedPos, pos)
+			}
+		})
+	}
+}
+
+// Parse errors are codes, formatted into the message a failed run reports
+func TestParseErrorMessages(t *testing.T) {
+	tests := map[string]string{
+		"":                          "empty JSON string",
+		"{}":                        "expected '[' at start of JSON array",
+		"[":                         "failed to parse object: expected '{' at start of JSON object",
+		"[{}":                       "failed to parse object: failed to parse field name: expected '\"' at start of string",
+		`[{"id"}`:                   "failed to parse object: expected ':' after field name",
+		`[{"id":1,"id":2}]`:         "failed to parse object: duplicate id field",
+		`[{"name":"a","name":"b"}]`: "failed to parse object: duplicate name field",
+		`[{"id":x}]`:                "failed to parse object: failed to parse id field: expected digit",
+		`[{"value":99999999999}]`:   "failed to parse object: failed to parse value field: number out of range",
+		`[{"value":-}]`:             "failed to parse object: failed to parse value field: expected digit",
+		`[{"flag":yes}]`:            "failed to parse object: failed to parse flag field: invalid boolean value",
+		`[{"name":"\q"}]`:           "failed to parse object: failed to parse name field: invalid escape sequence: \\q",
+		`[{"name":"a\`:              "failed to parse object: failed to parse name field: incomplete escape sequence",
+		`[{"name":"a`:               "failed to parse object: failed to parse name field: unterminated string",
+		`[{"other":1}]`:             "failed to parse object: unknown field",
+		`[{"id":1`:                  "failed to parse object: unexpected end of JSON object",
+		`[{"id":1;}]`:               "failed to parse object: expected ',' or '}', got ';'",
+		`[{"id":1}`:                 "failed to parse object: missing required fields in JSON object",
+		`[{"id":1,"value":2,"flag":true,"name":"a1"}`:  "unexpected end of JSON array",
+		`[{"id":1,"value":2,"flag":true,"name":"a1"}}`: "expected ',' or ']', got '}'",
+	}
+	for document, want := range tests {
+		_, err := parseJsonString(document)
+		if err == nil {
+			t.Errorf("%q parsed", document)
+		} else if err.Error() != want {
+			t.Errorf("%q: error %q, expected %q", document, err.Error(), want)
+		}
+	}
+}
+
+// Test number parsing with positive and negative values
+func TestParseJsonNumber(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		pos         int
+		expected    int32
+		expectedPos int
+		expectErr   bool
+	}{
+		{
+			name:        "positive integer",
+			input:       []byte("123"),
+			pos:         0,
+			expected:    123,
+			expectedPos: 3,
+			expectErr:   false,
+		},
+		{
+			name:        "negative integer",
+			input:       []byte("-456"),
+			pos:         0,
+			expected:    -456,
+			expectedPos: 4,
+			expectErr:   false,
+		},
+		{
+			name:        "zero",
+			input:       []byte("0"),
+			pos:         0,
+			expected:    0,
+			expectedPos: 1,
+			expectErr:   false,
+		},
+		{
+			name:        "large positive number",
+			input:       []byte("2147483647"),
+			pos:         0,
+			expected:    2147483647,
+			expectedPos: 10,
+			expectErr:   false,
+		},
+		{
+			name:        "large negative number",
+			input:       []byte("-2147483648"),
+			pos:         0,
+			expected:    -2147483648,
+			expectedPos: 11,
+			expectErr:   false,
+		},
+		{
+			name:        "invalid - no digits",
+			input:       []byte("-"),
+			pos:         0,
+			expected:    0,
+			expectedPos: 0,
+			expectErr:   true,
+		},
+		{
+			name:        "invalid - not a number",
+			input:       []byte("abc"),
+			pos:         0,
+			expected:    0,
+			expectedPos: 0,
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.pos
+			result, err := parseJsonNumber(tt.input, &pos)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected: %d, Got: %d", tt.expected, result)
+			}
+
+			if pos != tt.expectedPos {
+				t.Errorf("Expected position %d, got %d", tt.expectedPos, pos)
+			}
+		})
+	}
+}
+
+// Test boolean parsing
+func TestParseJsonBoolean(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		pos         int
+		expected    bool
+		expectedPos int
+		expectErr   bool
+	}{
+		{
+			name:        "true value",
+			input:       []byte("true"),
+			pos:         0,
+			expected:    true,
+			expectedPos: 4,
+			expectErr:   false,
+		},
+		{
+			name:        "false value",
+			input:       []byte("false"),
+			pos:         0,
+			expected:    false,
+			expectedPos: 5,
+			expectErr:   false,
+		},
+		{
+			name:        "invalid boolean",
+			input:       []byte("maybe"),
+			pos:         0,
+			expected:    false,
+			expectedPos: 0,
+			expectErr:   true,
+		},
+		{
+			name:        "partial true",
+			input:       []byte("tru"),
+			pos:         0,
+			expected:    false,
+			expectedPos: 0,
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := tt.pos
+			result, err := parseJsonBoolean(tt.input, &pos)
+
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected: %t, Got: %t", tt.expected, result)
+			}
+
+			if pos != tt.expectedPos {
+				t.Errorf("Expected position %d, got %d", tt.expectedPos, pos)
+			}
+		})
+	}
+}
+
+// Test FNV-1a hash calculation
+func TestFnv1aHashRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  []JsonRecord
+		expected uint32
+	}{
+		{
+			name:     "empty records",
+			records:  []JsonRecord{},
+			expected: 2166136261, // FNV offset basis
+		},
+		{
+			name: "single record",
+			records: []JsonRecord{
+				{ID: 1, Value: 123, Flag: true, Name: "a1"},
+			},
+			expected: calculateExpectedHash([]JsonRecord{{ID: 1, Value: 123, Flag: true, Name: "a1"}}),
+		},
+		{
+			name: "multiple records",
+			records: []JsonRecord{
+				{ID: 1, Value: 123, Flag: false, Name: "a1"},
+				{ID: 2, Value: -456, Flag: true, Name: "a2"},
+			},
+			expected: calculateExpectedHash([]JsonRecord{
+				{ID: 1, Value: 123, Flag: false, Name: "a1"},
+				{ID: 2, Value: -456, Flag: true, Name: "a2"},
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := fnv1aHashRecords(tt.records)
+			if result != tt.expected {
+				t.Errorf("Expected hash %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Helper function to calculate expected hash for testing
+func calculateExpectedHash(records []JsonRecord) uint32 {
+	const fnvOffsetBasis uint32 = 2166136261
+	const fnvPrime uint32 = 16777619
+
+	hash := fnvOffsetBasis
+
+	for _, record := range records {
+		// Hash ID field
+		id := record.ID
+		hash ^= uint32(id & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((id >> 8) & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((id >> 16) & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((id >> 24) & 0xFF)
+		hash *= fnvPrime
+
+		// Hash Value field
+		value := uint32(record.Value)
+		hash ^= uint32(value & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((value >> 8) & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((value >> 16) & 0xFF)
+		hash *= fnvPrime
+		hash ^= uint32((value >> 24) & 0xFF)
+		hash *= fnvPrime
+
+		// Hash Flag field
+		var flagByte uint32 = 0
+		if record.Flag {
+			flagByte = 1
+		}
+		hash ^= flagByte
+		hash *= fnvPrime
+
+		// Hash Name field
+		nameBytes := []byte(record.Name)
+		for _, b := range nameBytes {
+			hash ^= uint32(b)
+			hash *= fnvPrime
+		}
+	}
+
+	return hash
+}
+
+// Test Linear Congruential Generator
+func TestLinearCongruentialGenerator(t *testing.T) {
+	tests := []struct {
+		name     string
+		seed     uint32
+		expected uint32
+	}{
+		{
+			name:     "seed 0",
+			seed:     0,
+			expected: 1013904223,
+		},
+		{
+			name:     "seed 1",
+			seed:     1,
+			expected: 1015568748,
+		},
+		{
+			name:     "large seed",
+			seed:     4294967295, // max uint32
+			expected: 1012239698,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := tt.seed
+			result := common.NextLCG(&seed)
+			if result != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, result)
+			}
+			if seed != tt.expected {
+				t.Errorf("Expected seed to be updated to %d, got %d", tt.expected, seed)
+			}
+		})
+	}
+}
+
+// Test complete round-trip: generate -> serialize -> parse -> hash
+func TestCompleteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		seed  uint32
+	}{
+		{
+			name:  "small dataset",
+			count: 5,
+			seed:  12345,
+		},
+		{
+			name:  "medium dataset",
+			count: 100,
+			seed:  67890,
+		},
+		{
+			name:  "edge case - seed 0",
+			count: 10,
+			seed:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Generate original records
+			originalRecords := generateJsonRecords(tt.count, uint64(tt.seed), common.GeneratorLCG)
+			if len(originalRecords) != tt.count {
+				t.Fatalf("Expected %d records, got %d", tt.count, len(originalRecords))
+			}
+
+			// Serialize to JSON
+			jsonStr := serializeToJson(originalRecords)
+			if jsonStr == "" {
+				t.Fatalf("Serialization failed")
+			}
+
+			// Parse JSON back to records
+			parsedRecords, err := parseJsonString(jsonStr)
+			if err != nil {
+				t.Fatalf("Parsing failed: %v", err)
+			}
+
+			// Verify record count matches
+			if len(parsedRecords) != len(originalRecords) {
+				t.Fatalf("Parsed record count mismatch: expected %d, got %d", len(originalRecords), len(parsedRecords))
+			}
+
+			// Verify all fields match exactly
+			for i, original := range originalRecords {
+				parsed := parsedRecords[i]
+				if original.ID != parsed.ID {
+					t.Errorf("Record %d ID mismatch: expected %d, got %d", i, original.ID, parsed.ID)
+				}
+				if original.Value != parsed.Value {
+					t.Errorf("Record %d Value mismatch: expected %d, got %d", i, original.Value, parsed.Value)
+				}
+				if original.Flag != parsed.Flag {
+					t.Errorf("Record %d Flag mismatch: expected %t, got %t", i, original.Flag, parsed.Flag)
+				}
+				if original.Name != parsed.Name {
+					t.Errorf("Record %d Name mismatch: expected %s, got %s", i, original.Name, parsed.Name)
+				}
+			}
+
+			// Verify hash consistency
+			originalHash := fnv1aHashRecords(originalRecords)
+			parsedHash := fnv1aHashRecords(parsedRecords)
+			if originalHash != parsedHash {
+				t.Errorf("Hash mismatch: original %d, parsed %d", originalHash, parsedHash)
+			}
+		})
+	}
+}
+
+// Test WebAssembly interface functions
+func TestWebAssemblyInterface(t *testing.T) {
+	// Test init function
+	Init(42)
+	if globalSeed != 42 {
+		t.Errorf("Expected globalSeed to be 42, got %d", globalSeed)
+	}
+	Init64(1 << 40)
+	if globalSeed != 1<<40 {
+		t.Errorf("Expected init64 to keep all 64 bits, got %d", globalSeed)
+	}
+
+	// Test alloc function
+	ptr := Alloc(128)
+	if ptr == 0 {
+		t.Errorf("Expected non-zero pointer, got 0")
+	}
+
+	// Test parseParams function
+	params := JsonParseParams{RecordCount: 5, Seed: 12345}
+	ptr = uintptr(unsafe.Pointer(&params))
+	parsedParams := parseParams(ptr)
+	if parsedParams == nil {
+		t.Errorf("Expected non-nil params, got nil")
+	} else {
+		if parsedParams.RecordCount != 5 {
+			t.Errorf("Expected RecordCount 5, got %d", parsedParams.RecordCount)
+		}
+		if parsedParams.Seed != 12345 {
+			t.Errorf("Expected Seed 12345, got %d", parsedParams.Seed)
+		}
+	}
+
+	// Test RunTask function with valid parameters
+	result := RunTask(ptr)
+	if result == 0 {
+		t.Errorf("Expected non-zero hash result, got 0")
+	}
+
+	// Test RunTask with null pointer
+	result = RunTask(0)
+	if result != 0 {
+		t.Errorf("Expected 0 for null pointer, got %d", result)
+	}
+}
+
+func TestResolveScale(t *testing.T) {
+	custom := JsonParseParams{RecordCount: 3, Seed: 7}
+	resolved, ok := resolveScale(custom)
+	if !ok || resolved != custom {
+		t.Errorf("Custom scale should keep raw record count, got %+v", resolved)
+	}
+
+	medium := JsonParseParams{RecordCount: 3, Seed: 7, Scale: common.ScaleMedium}
+	resolved, ok = resolveScale(medium)
+	if !ok || resolved.RecordCount != 15000 || resolved.Seed != 7 {
+		t.Errorf("Medium scale resolved to %+v", resolved)
+	}
+
+	if _, ok := resolveScale(JsonParseParams{Scale: common.ScaleLarge + 1}); ok {
+		t.Error("Unknown scale tier should be rejected")
+	}
+
+	// Presets are resolved on a copy so host memory stays untouched
+	hostParams := JsonParseParams{Seed: 12345, Scale: common.ScaleMicro}
+	presetHash := RunTask(uintptr(unsafe.Pointer(&hostParams)))
+	if hostParams.RecordCount != 0 {
+		t.Errorf("runTask should not modify host params, RecordCount=%d", hostParams.RecordCount)
+	}
+
+	explicit := JsonParseParams{RecordCount: 500, Seed: 12345}
+	if explicitHash := RunTask(uintptr(unsafe.Pointer(&explicit))); presetHash != explicitHash {
+		t.Errorf("Micro preset should match explicit 500 records: %d != %d", presetHash, explicitHash)
+	}
+}
+
+func TestRunTaskProfiles(t *testing.T) {
+	// Batching must not change the verification hash
+	for _, count := range []uint32{0, 1, computeBatchRecords, computeBatchRecords + 1, 200} {
+		defaultParams := JsonParseParams{RecordCount: count, Seed: 99}
+		defaultHash := RunTask(uintptr(unsafe.Pointer(&defaultParams)))
+
+		for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+			params := JsonParseParams{RecordCount: count, Seed: 99, Profile: profile}
+			if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != defaultHash {
+				t.Errorf("count=%d profile=%d: hash %d, expected %d", count, profile, hash, defaultHash)
+			}
+		}
+	}
+
+	invalid := JsonParseParams{RecordCount: 5, Seed: 99, Profile: common.ProfileMemory + 1}
+	if hash := RunTask(uintptr(unsafe.Pointer(&invalid))); hash != 0 {
+		t.Error("Unknown profile should return 0")
+	}
+}
+
+func TestCalibrateWorkload(t *testing.T) {
+	params := JsonParseParams{RecordCount: 100, Seed: 1}
+	if got, factor := calibrateWorkload(params); got != params || factor != 1 {
+		t.Errorf("Calibration should be disabled without a target, got %+v factor %d", got, factor)
+	}
+
+	params.TargetWork = 1 // 1000 records
+	got, factor := calibrateWorkload(params)
+	if got.RecordCount != 1600 || factor != 16 {
+		t.Errorf("Calibration chose %d records factor %d, expected 1600 factor 16", got.RecordCount, factor)
+	}
+
+	params.TargetWork = 1_000_000
+	got, _ = calibrateWorkload(params)
+	if got.RecordCount > maxRecordCount || got.RecordCount*2 <= maxRecordCount {
+		t.Errorf("Calibration should stop at the record cap, got %d", got.RecordCount)
+	}
+
+	empty := JsonParseParams{TargetWork: 10}
+	if got, factor := calibrateWorkload(empty); got.RecordCount != 0 || factor != 1 {
+		t.Errorf("Empty documents should not be calibrated, got %d factor %d", got.RecordCount, factor)
+	}
+}
+
+func TestRunTaskReportsScaleFactor(t *testing.T) {
+	params := JsonParseParams{RecordCount: 250, Seed: 3, TargetWork: 1}
+	scaled := JsonParseParams{RecordCount: 1000, Seed: 3}
+
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if factor := GetScaleFactor(); factor != 4 {
+		t.Errorf("Expected scale factor 4, got %d", factor)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&scaled))); hash != expected {
+		t.Errorf("Calibrated run should match explicit 1000 records: %d != %d", hash, expected)
+	}
+	if factor := GetScaleFactor(); factor != 1 {
+		t.Errorf("Uncalibrated run should report factor 1, got %d", factor)
+	}
+}
+
+func TestRunTaskWarmupIterations(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		cold := JsonParseParams{RecordCount: 100, Seed: 8, Profile: profile}
+		warm := cold
+		warm.WarmupIterations = 3
+
+		coldHash := RunTask(uintptr(unsafe.Pointer(&cold)))
+		if warmHash := RunTask(uintptr(unsafe.Pointer(&warm))); warmHash != coldHash {
+			t.Errorf("Profile %d: warm-up should not change the hash: %d != %d", profile, warmHash, coldHash)
+		}
+	}
+
+	params := JsonParseParams{RecordCount: 10, Seed: 8, WarmupIterations: common.MaxWarmupIterations + 1}
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != 0 {
+		t.Error("Warm-up iterations above the limit should be rejected")
+	}
+}
+
+func TestGetWorkMetrics(t *testing.T) {
+	const count = 100
+	documentBytes := uint64(len(serializeToJson(generateJsonRecords(count, 4, common.GeneratorLCG))))
+
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: count, Seed: 4, Profile: profile, WarmupIterations: 1}
+		RunTask(uintptr(unsafe.Pointer(&params)))
+
+		metrics := (*common.WorkMetrics)(unsafe.Pointer(GetWorkMetrics()))
+		if metrics.ElementsProcessed != count {
+			t.Errorf("Profile %d: expected %d records, got %d", profile, count, metrics.ElementsProcessed)
+		}
+		// Batches drop the array separators between batches, so only the default path is exact
+		if profile == common.ProfileDefault && metrics.BytesTouched != documentBytes*2 {
+			t.Errorf("Expected %d bytes touched, got %d", documentBytes*2, metrics.BytesTouched)
+		}
+		if metrics.BytesTouched == 0 {
+			t.Errorf("Profile %d: bytes touched should be reported", profile)
+		}
+	}
+
+	RunTask(0)
+	if metrics := (*common.WorkMetrics)(unsafe.Pointer(GetWorkMetrics())); *metrics != (common.WorkMetrics{}) {
+		t.Errorf("Failed runs should clear work metrics, got %+v", *metrics)
+	}
+}
+
+func TestVerificationLevels(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 150, Seed: 42, Profile: profile}
+		hashed := RunTask(uintptr(unsafe.Pointer(&params)))
+
+		params.Verification = common.VerifyFull
+		if full := RunTask(uintptr(unsafe.Pointer(&params))); full != hashed {
+			t.Errorf("Profile %d: full verification should return the same hash: %d != %d", profile, full, hashed)
+		}
+
+		params.Verification = common.VerifyNone
+		expected := sumRecordValues(0, generateJsonRecords(150, 42, common.GeneratorLCG))
+		if sum := RunTask(uintptr(unsafe.Pointer(&params))); sum != expected {
+			t.Errorf("Profile %d: unverified run should return the value sum %d, got %d", profile, expected, sum)
+		}
+	}
+
+	params := JsonParseParams{RecordCount: 10, Verification: common.VerifyFull + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown verification level should be rejected")
+	}
+
+	records := generateJsonRecords(3, 7, common.GeneratorLCG)
+	jsonStr := serializeToJson(records)
+	if !roundTripMatches(records, jsonStr) {
+		t.Error("Records should round-trip to their own document")
+	}
+	records[1].Value++
+	if roundTripMatches(records, jsonStr) {
+		t.Error("Altered records should not match the original document")
+	}
+}
+
+func TestParamsFingerprint(t *testing.T) {
+	// Documented layout: eleven consecutive u32 fields, 44 bytes
+	layout := []uint32{0, 4, 4, 4, 8, 4, 12, 4, 16, 4, 20, 4, 24, 4, 28, 4, 32, 4, 36, 4, 40, 4, 44}
+	want := common.LayoutFingerprint(layout)
+
+	if got := ParamsFingerprint(); got != want {
+		t.Errorf("Params fingerprint %d does not match the documented layout %d", got, want)
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	limits := (*Limits)(unsafe.Pointer(GetLimits()))
+
+	if limits.WordCount != 9 {
+		t.Errorf("Expected 9 limit words after WordCount, got %d", limits.WordCount)
+	}
+
+	// The host generator is only available with a random source
+	common.SetHostRandom(func() uint32 { return 0 })
+	defer common.SetHostRandom(nil)
+
+	// The reported bounds are inclusive: the limit passes, one past it fails
+	params := JsonParseParams{RecordCount: limits.MaxRecordCount, Profile: limits.MaxProfile,
+		WarmupIterations: limits.MaxWarmupIterations, Verification: limits.MaxVerification,
+		Allocator: limits.MaxAllocator, HashAlgorithm: limits.MaxHashAlgorithm, Generator: limits.MaxGenerator}
+	if !validateParameters(&params) {
+		t.Error("Parameters at their reported limits should be accepted")
+	}
+	params.RecordCount++
+	if validateParameters(&params) {
+		t.Error("Record count beyond MaxRecordCount should be rejected")
+	}
+
+	if _, ok := resolveScale(JsonParseParams{Scale: limits.MaxScale}); !ok {
+		t.Error("MaxScale should be a valid tier")
+	}
+	if _, ok := resolveScale(JsonParseParams{Scale: limits.MaxScale + 1}); ok {
+		t.Error("Scale beyond MaxScale should be rejected")
+	}
+}
+
+func TestDeallocReleasesAllocation(t *testing.T) {
+	before, _ := common.LiveAllocations()
+
+	ptr := Alloc(64)
+	if ptr == 0 {
+		t.Fatal("Alloc(64) should succeed")
+	}
+	if live, _ := common.LiveAllocations(); live != before+1 {
+		t.Errorf("alloc should pin its buffer, %d live allocations (expected %d)", live, before+1)
+	}
+
+	Dealloc(ptr)
+	Dealloc(ptr) // Repeated frees are ignored
+	if live, _ := common.LiveAllocations(); live != before {
+		t.Errorf("dealloc should unpin the buffer, %d live allocations (expected %d)", live, before)
+	}
+}
+
+func TestArenaAllocator(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 200, Seed: 3, Profile: profile}
+		heapHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+		params.Allocator = common.AllocatorArena
+		if arenaHash := RunTask(uintptr(unsafe.Pointer(&params))); arenaHash != heapHash {
+			t.Errorf("Profile %d: arena allocation should not change the hash: %d != %d", profile, arenaHash, heapHash)
+		}
+		if scratchArena.Used() == 0 {
+			t.Errorf("Profile %d: arena run should hold its parse buffers in the arena", profile)
+		}
+		if documentArena != nil {
+			t.Errorf("Profile %d: arena should be detached after the run", profile)
+		}
+	}
+
+	ResetArena()
+	if scratchArena.Used() != 0 {
+		t.Error("reset_arena should rewind the arena")
+	}
+
+	params := JsonParseParams{RecordCount: 4, Allocator: common.AllocatorArena + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown allocator should be rejected")
+	}
+}
+
+func TestRunTaskV2Status(t *testing.T) {
+	// An empty document with a plain checksum legitimately yields 0
+	params := JsonParseParams{Verification: common.VerifyNone}
+	// Module memory, as a host would pass it; a Go stack address would move as run_task grows the stack
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TaskResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TaskResult)(unsafe.Pointer(resultPtr))
+	*result = common.TaskResult{Hash: 1}
+	status := RunTaskV2(uintptr(unsafe.Pointer(&params)), resultPtr)
+	if status != common.StatusOK || result.Status != common.StatusOK || result.Hash != 0 {
+		t.Fatalf("Empty document should report {StatusOK, 0}, got status %d and %+v", status, *result)
+	}
+
+	params = JsonParseParams{RecordCount: 5, Seed: 7}
+	RunTaskV2(uintptr(unsafe.Pointer(&params)), resultPtr)
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_v2 hash %d should match run_task %d", result.Hash, expected)
+	}
+
+	tests := []struct {
+		name     string
+		params   JsonParseParams
+		expected uint32
+	}{
+		{"unknown scale", JsonParseParams{Scale: common.ScaleLarge + 1}, common.StatusInvalidParams},
+		{"unknown verification", JsonParseParams{RecordCount: 4, Verification: common.VerifyFull + 1}, common.StatusInvalidParams},
+		{"too many records", JsonParseParams{RecordCount: maxRecordCount + 1}, common.StatusOverflow},
+		{"too many warm-ups", JsonParseParams{RecordCount: 4, WarmupIterations: common.MaxWarmupIterations + 1}, common.StatusOverflow},
+	}
+	for _, tt := range tests {
+		*result = common.TaskResult{Hash: 1}
+		if status := RunTaskV2(uintptr(unsafe.Pointer(&tt.params)), resultPtr); status != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, status)
+		}
+		if result.Status != tt.expected || result.Hash != 0 {
+			t.Errorf("%s: result should be {%d, 0}, got %+v", tt.name, tt.expected, *result)
+		}
+	}
+
+	if status := RunTaskV2(0, resultPtr); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+}
+
+func TestLastErrorMessage(t *testing.T) {
+	params := JsonParseParams{RecordCount: maxRecordCount + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Fatal("Invalid params should be rejected")
+	}
+	message := unsafe.String((*byte)(unsafe.Pointer(GetLastErrorPtr())), GetLastErrorLen())
+	if message != "record count exceeds the maximum" {
+		t.Errorf("Unexpected error message %q", message)
+	}
+
+	params = JsonParseParams{RecordCount: 4}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if GetLastErrorLen() != 0 {
+		t.Errorf("A successful run should clear the error, got %q", common.LastError())
+	}
+}
+
+func TestGetTaskInfo(t *testing.T) {
+	ptr := GetTaskInfo()
+	length := *(*uint32)(unsafe.Pointer(ptr))
+	blob := unsafe.Slice((*byte)(unsafe.Pointer(ptr+4)), length)
+
+	var info struct {
+		Task       string `json:"task"`
+		ABIVersion uint32 `json:"abi_version"`
+		ParamsSize uint32 `json:"params_size"`
+		Params     []struct {
+			Name   string `json:"name"`
+			Offset uint32 `json:"offset"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(blob, &info); err != nil {
+		t.Fatalf("Task info is not valid JSON: %v\n%s", err, blob)
+	}
+
+	if info.Task != "json_parse" || info.ABIVersion != common.ABIVersion || info.ParamsSize != 44 {
+		t.Errorf("Unexpected task info header: %+v", info)
+	}
+	if ABIVersion() != info.ABIVersion {
+		t.Errorf("abi_version() = %d, task info reports %d", ABIVersion(), info.ABIVersion)
+	}
+	if len(info.Params) != 11 || info.Params[10].Name != "seed_high" || info.Params[10].Offset != 40 {
+		t.Errorf("Unexpected params schema: %+v", info.Params)
+	}
+}
+
+func TestRunTaskTimed(t *testing.T) {
+	// Module memory, as a host would pass it
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TimedResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TimedResult)(unsafe.Pointer(resultPtr))
+
+	params := JsonParseParams{RecordCount: 200, Seed: 3}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status != common.StatusOK {
+		t.Fatalf("Valid run should report StatusOK, got %d", status)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_timed hash %d should match run_task %d", result.Hash, expected)
+	}
+	if result.ElapsedMs <= 0 {
+		t.Errorf("Measured run should take time, got %f ms", result.ElapsedMs)
+	}
+
+	params = JsonParseParams{RecordCount: maxRecordCount + 1}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status == common.StatusOK {
+		t.Error("Invalid params should be rejected")
+	}
+	if result.ElapsedMs != 0 {
+		t.Errorf("Rejected run should not report a duration, got %f ms", result.ElapsedMs)
+	}
+}
+
+func TestGetMemoryStats(t *testing.T) {
+	before := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+	params := JsonParseParams{RecordCount: 50, Seed: 3}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	after := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+
+	if after.TotalAlloc <= before.TotalAlloc || after.Mallocs <= before.Mallocs {
+		t.Errorf("A run should allocate: before %+v, after %+v", before, after)
+	}
+}
+
+func TestEncodedParams(t *testing.T) {
+	params := JsonParseParams{RecordCount: 40, Seed: 2}
+	rawHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	encoded := common.EncodeParams(unsafe.Pointer(&params), ParamFields())
+	if hash := RunTask(uintptr(unsafe.Pointer(&encoded[0]))); hash != rawHash {
+		t.Errorf("Encoded params should match the raw struct: %d != %d", hash, rawHash)
+	}
+
+	// Trailing fields left out of the payload take their zero defaults
+	short := append([]byte(nil), encoded[:common.ParamsHeaderSize+8]...)
+	common.PutUint32LE(short[8:], 8)
+	if hash := RunTask(uintptr(unsafe.Pointer(&short[0]))); hash != rawHash {
+		t.Errorf("Short payload should default the trailing fields: %d != %d", hash, rawHash)
+	}
+
+	common.PutUint32LE(encoded[4:], common.ParamsVersion+1)
+	if status := RunTaskV2(uintptr(unsafe.Pointer(&encoded[0])), 0); status != common.StatusInvalidParams {
+		t.Errorf("Unknown encoding version should be rejected, got status %d", status)
+	}
+}
+
+func TestValidateParamsExport(t *testing.T) {
+	params := JsonParseParams{RecordCount: 20, Seed: 1}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	metrics := lastWorkMetrics
+
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("Valid params should report StatusOK, got %d", status)
+	}
+
+	bad := JsonParseParams{RecordCount: 4, Profile: common.ProfileMemory + 1}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&bad))); status != common.StatusInvalidParams {
+		t.Errorf("Expected status %d, got %d", common.StatusInvalidParams, status)
+	}
+	if common.LastError() != "unknown workload profile" || GetErrorCode() != common.ErrUnknownProfile {
+		t.Errorf("Unexpected rejection reason %q (code %d)", common.LastError(), GetErrorCode())
+	}
+	if status := ValidateParams(0); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+	if GetErrorCode() != common.ErrNullParams {
+		t.Errorf("Null params should report ErrNullParams, got %d", GetErrorCode())
+	}
+
+	// Validation never runs the workload
+	if lastWorkMetrics != metrics {
+		t.Error("validate_params should not touch the last run's work metrics")
+	}
+}
+
+func TestRunTask64(t *testing.T) {
+	records := generateJsonRecords(300, 17, common.GeneratorLCG)
+	want := fnv1a64UpdateRecords(common.FNV64OffsetBasis, records)
+
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile}
+		if got := RunTask64(uintptr(unsafe.Pointer(&params))); got != want {
+			t.Errorf("Profile %d: run_task64 = %#x, expected %#x", profile, got, want)
+		}
+		if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != fnv1aHashRecords(records) {
+			t.Errorf("Profile %d: run_task should keep the 32-bit hash, got %d", profile, hash)
+		}
+	}
+
+	params := JsonParseParams{RecordCount: 300, Seed: 17, Verification: common.VerifyNone}
+	if got := RunTask64(uintptr(unsafe.Pointer(&params))); got != uint64(sumRecordValues(0, records)) {
+		t.Errorf("Checksum level should return the widened checksum, got %#x", got)
+	}
+
+	params = JsonParseParams{RecordCount: maxRecordCount + 1}
+	if got := RunTask64(uintptr(unsafe.Pointer(&params))); got != 0 {
+		t.Errorf("Invalid params should return 0, got %#x", got)
+	}
+}
+
+func TestRunTaskPacked(t *testing.T) {
+	params := JsonParseParams{RecordCount: 300, Seed: 17}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOK, hash) {
+		t.Errorf("run_task_packed = %#x, expected status 0 and hash %d", got, hash)
+	}
+
+	params.RecordCount = maxRecordCount + 1
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOverflow, 0) {
+		t.Errorf("Too many records should pack StatusOverflow, got %#x", got)
+	}
+	if got := RunTaskPacked(0); got != common.PackResult(common.StatusInvalidParams, 0) {
+		t.Errorf("Null params should pack StatusInvalidParams, got %#x", got)
+	}
+}
+
+func TestResultBlock(t *testing.T) {
+	block := common.Memory(unsafe.Pointer(GetResultPtr()), common.ResultBlockSize)
+	params := JsonParseParams{RecordCount: 300, Seed: 17}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block) != common.ResultMagic || common.ReadUint32LE(block[4:]) != common.StatusOK ||
+		common.ReadUint32LE(block[8:]) != hash || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Unexpected result header % x after a run with hash %#x", block[:16], hash)
+	}
+	if common.ReadFloat64LE(block[24:]) != lastElapsedMs || common.ReadUint64LE(block[32:]) != lastWorkMetrics.ElementsProcessed ||
+		common.ReadUint64LE(block[40:]) != lastWorkMetrics.BytesTouched {
+		t.Errorf("Result block should carry the run's duration and work metrics, got % x", block[24:])
+	}
+
+	hash64 := RunTask64(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[12:]) != common.ResultHasHash64 || common.ReadUint64LE(block[16:]) != hash64 {
+		t.Errorf("run_task64 should publish its 64-bit hash %#x, got % x", hash64, block[12:24])
+	}
+
+	params.RecordCount = maxRecordCount + 1
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[4:]) != common.StatusOverflow || common.ReadUint32LE(block[8:]) != 0 || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Too many records should publish StatusOverflow, got % x", block[:16])
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile}
+		want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+		common.RequestCancel()
+		lastStatus = common.StatusOK
+		if hash := executeWorkload(&params); hash != 0 || lastStatus != common.StatusCancelled {
+			t.Errorf("Profile %d: cancelled run returned %#x with status %d, expected 0 and StatusCancelled", profile, hash, lastStatus)
+		}
+
+		// run_task lowers the flag, so the instance stays usable
+		if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want || lastStatus != common.StatusOK {
+			t.Errorf("Profile %d: run after a cancellation = %#x (status %d), expected %#x", profile, got, lastStatus, want)
+		}
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	defer SetCheckpoints(0)
+	records := generateJsonRecords(300, 17, common.GeneratorLCG)
+	params := JsonParseParams{RecordCount: 300, Seed: 17, HashAlgorithm: common.HashXXHash32}
+
+	SetCheckpoints(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if HashInput() != 0 {
+		t.Errorf("hash_input should be 0 with checkpoints disabled, got %#x", HashInput())
+	}
+
+	SetCheckpoints(1)
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got, want := HashInput(), fnv1aHashRecords(records); got != want {
+		t.Errorf("hash_input = %#x, expected %#x", got, want)
+	}
+	document := serializeToJson(records)
+	if got, ok := common.CheckpointHash(stageSerialize); !ok || got != common.HashBytes(common.FNVOffsetBasis, []byte(document)) {
+		t.Errorf("Serialize checkpoint = %#x (recorded %v), expected the document's FNV-1a", got, ok)
+	}
+	// A lossless round trip parses back the generated records
+	if got, ok := common.CheckpointHash(stageParse); !ok || got != HashInput() {
+		t.Errorf("Parse checkpoint = %#x (recorded %v), expected the input hash %#x", got, ok, HashInput())
+	}
+	if got, ok := common.CheckpointHash(stageOutput); !ok || got != hash {
+		t.Errorf("Output checkpoint = %#x (recorded %v), expected the result %#x", got, ok, hash)
+	}
+
+	// The batched profile streams its batches into the same record hashes
+	params = JsonParseParams{RecordCount: 300, Seed: 17, Profile: common.ProfileCompute}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if got := HashInput(); got != fnv1aHashRecords(records) {
+		t.Errorf("Compute profile hash_input = %#x, expected %#x", got, fnv1aHashRecords(records))
+	}
+	if got, ok := common.CheckpointHash(stageParse); !ok || got != HashInput() {
+		t.Errorf("Compute profile parse checkpoint = %#x (recorded %v), expected %#x", got, ok, HashInput())
+	}
+}
+
+func TestOutput(t *testing.T) {
+	defer SetOutput(0)
+	records := generateJsonRecords(300, 17, common.GeneratorLCG)
+
+	params := JsonParseParams{RecordCount: 300, Seed: 17}
+	SetOutput(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if data, _ := common.RunOutput(); len(data) != 0 {
+		t.Errorf("A run with the output disabled kept %d bytes", len(data))
+	}
+
+	// Both profiles keep every parsed record, the batched one across its batches
+	SetOutput(1)
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile}
+		RunTask(uintptr(unsafe.Pointer(&params)))
+		data, elementSize := common.RunOutput()
+		if elementSize != outputElementSize || len(data) != len(records)*outputElementSize {
+			t.Fatalf("Profile %d: output of %d bytes (element size %d), expected %d records", profile, len(data), elementSize, len(records))
+		}
+		last := data[len(data)-outputElementSize:]
+		want := records[len(records)-1]
+		if common.ReadUint32LE(last) != want.ID || common.ReadInt32LE(last[4:]) != want.Value ||
+			common.ReadUint32LE(last[12:]) != common.HashBytes(common.FNVOffsetBasis, []byte(want.Name)) {
+			t.Errorf("Profile %d: last record % x, expected %+v", profile, last, want)
+		}
+	}
+}
+
+func TestReserveMemory(t *testing.T) {
+	defer ReserveMemory(0)
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		for _, verification := range []uint32{common.VerifyHash, common.VerifyFull} {
+			params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile, Verification: verification}
+			ReserveMemory(0)
+			want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+			if status := ReserveMemory(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+				t.Fatalf("Profile %d: reserve_memory returned status %d", profile, status)
+			}
+			capacity := scratchArena.Capacity()
+			for repeat := 0; repeat < 2; repeat++ {
+				if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want {
+					t.Errorf("Profile %d, verification %d: reserved run = %#x, expected %#x", profile, verification, got, want)
+				}
+			}
+			// Parse buffers come from the reserved arena, which never grows
+			if scratchArena.Capacity() != capacity || scratchArena.Used() == 0 || scratchArena.Used() > workingSet(&params) {
+				t.Errorf("Profile %d, verification %d: used %d of %d reserved bytes, arena now %d bytes",
+					profile, verification, scratchArena.Used(), workingSet(&params), scratchArena.Capacity())
+			}
+
+			params.RecordCount = 600
+			if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOverflow {
+				t.Errorf("Profile %d: a run beyond the reservation should overflow", profile)
+			}
+		}
+	}
+
+	// The bound holds for the largest records
+	params := JsonParseParams{RecordCount: maxRecordCount}
+	records := []JsonRecord{{ID: maxRecordCount, Value: math.MinInt32, Flag: false, Name: buildNameString(maxRecordCount)}}
+	if got := len(serializeToJson(records)) - 2 + 1; got != maxRecordBytes {
+		t.Errorf("Longest record serializes to %d bytes with its comma, expected %d", got, maxRecordBytes)
+	}
+	if workingSet(&params) < maxRecordCount*maxRecordBytes {
+		t.Error("Working set should cover a document of the longest records")
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	defer SetMemoryBudget(0)
+	params := JsonParseParams{RecordCount: 20000, Seed: 17}
+
+	SetMemoryBudget(0)
+	if GetMaxMemoryPages() != common.MaxWasmPages {
+		t.Errorf("Without a budget memory may grow to %d pages, got %d", common.MaxWasmPages, GetMaxMemoryPages())
+	}
+
+	// A budget below the memory a run needs rejects it before it starts
+	SetMemoryBudget(1)
+	if GetMaxMemoryPages() != 1 {
+		t.Errorf("Expected a 1 page limit, got %d", GetMaxMemoryPages())
+	}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOverflow {
+		t.Errorf("A 20000 record document should not fit one page, got status %d", status)
+	}
+	if GetErrorCode() != common.ErrTooLarge {
+		t.Errorf("Over-budget params should report ErrTooLarge, got %d", GetErrorCode())
+	}
+	if RunTask(uintptr(unsafe.Pointer(&params))) != 0 || lastStatus != common.StatusOverflow {
+		t.Errorf("An over-budget run should fail with StatusOverflow, got %d", lastStatus)
+	}
+
+	SetMemoryBudget(common.MaxWasmPages)
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("A 20000 record document should fit the full address space, got status %d", status)
+	}
+}
+
+func TestHashAlgorithm(t *testing.T) {
+	records := generateJsonRecords(300, 17, common.GeneratorLCG)
+	want := xxh32HashRecords(records)
+	if want == fnv1aHashRecords(records) {
+		t.Error("xxHash32 and FNV-1a should differ on the same records")
+	}
+
+	// The batched compute profile streams the same bytes across batches
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		for _, verification := range []uint32{common.VerifyHash, common.VerifyFull} {
+			params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile,
+				Verification: verification, HashAlgorithm: common.HashXXHash32}
+			if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want {
+				t.Errorf("Profile %d, verification %d: xxHash32 run = %#x, expected %#x", profile, verification, got, want)
+			}
+		}
+	}
+
+	params := JsonParseParams{RecordCount: 300, Seed: 17, Verification: common.VerifyNone, HashAlgorithm: common.HashXXHash32}
+	if got := RunTask(uintptr(unsafe.Pointer(&params))); got != sumRecordValues(0, records) {
+		t.Errorf("Checksum level should ignore the hash algorithm, got %d", got)
+	}
+
+	params.HashAlgorithm = common.HashXXHash32 + 1
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Unknown hash algorithm should be rejected")
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	// The LCG's low bit alternates, so its Flag column does too; PCG32's does not
+	flagsAlternate := func(records []JsonRecord) bool {
+		for i := 1; i < len(records); i++ {
+			if records[i].Flag == records[i-1].Flag {
+				return false
+			}
+		}
+		return true
+	}
+	if !flagsAlternate(generateJsonRecords(100, 17, common.GeneratorLCG)) {
+		t.Error("LCG flags are expected to alternate")
+	}
+	records := generateJsonRecords(300, 17, common.GeneratorPCG32)
+	if flagsAlternate(records) {
+		t.Error("PCG32 flags should not simply alternate")
+	}
+
+	want := fnv1aHashRecords(records)
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute} {
+		params := JsonParseParams{RecordCount: 300, Seed: 17, Profile: profile, Generator: common.GeneratorPCG32}
+		if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want {
+			t.Errorf("Profile %d: PCG32 run = %d, expected %d", profile, got, want)
+		}
+	}
+
+	params := JsonParseParams{RecordCount: 300, Seed: 17, Generator: common.GeneratorHost + 1}
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Unknown random generator should be rejected")
+	}
+	params.Generator = common.GeneratorHost
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Host generator without a random source should be rejected")
+	}
+}
+
+func TestHostRandomReplay(t *testing.T) {
+	// A host replaying the LCG sequence reproduces the LCG run
+	lcg := JsonParseParams{RecordCount: 300, Seed: 17}
+	want := RunTask(uintptr(unsafe.Pointer(&lcg)))
+
+	state := uint32(17)
+	common.SetHostRandom(func() uint32 { return common.NextLCG(&state) })
+	defer common.SetHostRandom(nil)
+	host := JsonParseParams{RecordCount: 300, Seed: 99, Generator: common.GeneratorHost}
+	if got := RunTask(uintptr(unsafe.Pointer(&host))); got != want {
+		t.Errorf("Host generator run = %d, expected the LCG run %d", got, want)
+	}
+}
+
+func TestSeedHigh(t *testing.T) {
+	run := func(params JsonParseParams) uint32 {
+		return RunTask(uintptr(unsafe.Pointer(&params)))
+	}
+
+	// The LCG folds the high word into its 32-bit state
+	wide := run(JsonParseParams{RecordCount: 50, Seed: 23, SeedHigh: 5})
+	if wide != run(JsonParseParams{RecordCount: 50, Seed: 23 ^ 5}) {
+		t.Error("LCG runs should use the low word XOR the high word")
+	}
+	if wide == run(JsonParseParams{RecordCount: 50, Seed: 23}) {
+		t.Error("A non-zero high word should change the LCG data")
+	}
+
+	// PCG32 is seeded with all 64 bits
+	pcg := run(JsonParseParams{RecordCount: 50, Seed: 23, SeedHigh: 5, Generator: common.GeneratorPCG32})
+	if pcg == run(JsonParseParams{RecordCount: 50, Seed: 23 ^ 5, Generator: common.GeneratorPCG32}) {
+		t.Error("PCG32 runs should not fold the seed")
+	}
+}
+
+func TestPanicExports(t *testing.T) {
+	// A normal run leaves the panic buffer empty; common tests cover recovery itself
+	params := JsonParseParams{RecordCount: 10, Seed: 1}
+	if RunTask(uintptr(unsafe.Pointer(&params))) == 0 || lastStatus != common.StatusOK {
+		t.Fatalf("Valid run failed with status %d", lastStatus)
+	}
+	if GetPanicPtr() != common.PanicMessagePtr() || GetPanicLen() != 0 {
+		t.Errorf("Expected an empty panic buffer, got %d bytes", GetPanicLen())
+	}
+}
+
+// Benchmark tests for performance measurement
+func BenchmarkGenerateJsonRecords(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		generateJsonRecords(100, 12345, common.GeneratorLCG)
+	}
+}
+
+func BenchmarkSerializeToJson(b *testing.B) {
+	records := generateJsonRecords(100, 12345, common.GeneratorLCG)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		serializeToJson(records)
+	}
+}
+
+func BenchmarkParseJsonString(b *testing.B) {
+	records := generateJsonRecords(100, 12345, common.GeneratorLCG)
+	jsonStr := serializeToJson(records)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = parseJsonString(jsonStr)
+	}
+}
+
+func BenchmarkFnv1aHashRecords(b *testing.B) {
+	records := generateJsonRecords(100, 12345, common.GeneratorLCG)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fnv1aHashRecords(records)
+	}
+}
+
+func BenchmarkCompleteRoundTrip(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		records := generateJsonRecords(100, 12345, common.GeneratorLCG)
+		jsonStr := serializeToJson(records)
+		parsedRecords, _ := parseJsonString(jsonStr)
+		fnv1aHashRecords(parsedRecords)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if status := SelfTest(); status != common.StatusOK {
+		t.Fatalf("self_test = %d: %s", status, common.LastError())
+	}
+
+	// A vector whose hash no longer matches fails the test and is named
+	defer func(saved []common.KnownAnswer[JsonParseParams]) { selfTestVectors = saved }(selfTestVectors)
+	selfTestVectors = append([]common.KnownAnswer[JsonParseParams](nil), selfTestVectors...)
+	broken := &selfTestVectors[len(selfTestVectors)-1]
+	broken.Hash++
+	if status := SelfTest(); status != common.StatusVerificationFailed {
+		t.Errorf("self_test with a wrong hash = %d, expected StatusVerificationFailed", status)
+	}
+	if !strings.Contains(common.LastError(), broken.Name) {
+		t.Errorf("self_test error %q does not name vector %s", common.LastError(), broken.Name)
+	}
+}
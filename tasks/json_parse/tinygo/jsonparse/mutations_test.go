@@ -0,0 +1,39 @@
+package jsonparse
+
+import (
+	"slices"
+	"testing"
+
+	"wasmbench/common"
+	"wasmbench/common/mutation"
+)
+
+// TestHashMutations checks that every hash algorithm folds in each field of
+// the parsed records, and their count and order
+func TestHashMutations(t *testing.T) {
+	records := generateJsonRecords(6, 42, common.GeneratorLCG)
+	hashes := []mutation.Hash[[]JsonRecord]{
+		{Name: "fnv1a", Sum: func(r []JsonRecord) uint64 { return uint64(fnv1aHashRecords(r)) }},
+		{Name: "fnv1a64", Sum: func(r []JsonRecord) uint64 { return fnv1a64UpdateRecords(common.FNV64OffsetBasis, r) }},
+		{Name: "xxh32", Sum: func(r []JsonRecord) uint64 { return uint64(xxh32HashRecords(r)) }},
+		{Name: "fnv1a in batches", Sum: func(r []JsonRecord) uint64 {
+			// As runBatchedRoundTrip carries the state from batch to batch
+			return uint64(fnv1aUpdateRecords(fnv1aUpdateRecords(common.FNVOffsetBasis, r[:2]), r[2:]))
+		}},
+	}
+	last := len(records) - 1
+	mutation.Check(t, records, slices.Clone, hashes, []mutation.Mutation[[]JsonRecord]{
+		{Name: "change an ID", Apply: func(r []JsonRecord) []JsonRecord { r[2].ID++; return r }},
+		{Name: "change an ID's high byte", Apply: func(r []JsonRecord) []JsonRecord { r[2].ID ^= 1 << 24; return r }},
+		{Name: "change a value", Apply: func(r []JsonRecord) []JsonRecord { r[last].Value++; return r }},
+		{Name: "negate a value", Apply: func(r []JsonRecord) []JsonRecord { r[0].Value = -r[0].Value; return r }},
+		{Name: "flip a flag", Apply: func(r []JsonRecord) []JsonRecord { r[1].Flag = !r[1].Flag; return r }},
+		{Name: "change a name's byte", Apply: func(r []JsonRecord) []JsonRecord { r[3].Name = "b" + r[3].Name[1:]; return r }},
+		{Name: "lengthen a name", Apply: func(r []JsonRecord) []JsonRecord { r[last].Name += "0"; return r }},
+		{Name: "empty a name", Apply: func(r []JsonRecord) []JsonRecord { r[0].Name = ""; return r }},
+		{Name: "drop the first record", Apply: func(r []JsonRecord) []JsonRecord { return r[1:] }},
+		{Name: "drop the last record", Apply: func(r []JsonRecord) []JsonRecord { return r[:last] }},
+		{Name: "repeat a record", Apply: func(r []JsonRecord) []JsonRecord { return append(r, r[last]) }},
+		{Name: "swap two records", Apply: func(r []JsonRecord) []JsonRecord { r[1], r[2] = r[2], r[1]; return r }},
+	})
+}
@@ -0,0 +1,109 @@
+package jsonparse
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"wasmbench/common"
+)
+
+// propertyConfig checks a property over 2000 generated cases from a fixed
+// seed, so a failure reproduces
+func propertyConfig() *quick.Config {
+	return &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+}
+
+// nameRunes are the runes of generated names: ASCII, the characters JSON
+// escapes, and multi-byte UTF-8. Control characters and U+2028, which
+// encoding/json writes as \u escapes the parser does not read, are left out.
+var nameRunes = []rune(`abcXYZ019 _-/"\{}[]:,é€𝄞`)
+
+// recordSet is a generated document's records, with any ids and values
+type recordSet []JsonRecord
+
+func (recordSet) Generate(rng *rand.Rand, size int) reflect.Value {
+	records := make(recordSet, rng.Intn(size+1))
+	for i := range records {
+		name := make([]rune, rng.Intn(12))
+		for j := range name {
+			name[j] = nameRunes[rng.Intn(len(nameRunes))]
+		}
+		records[i] = JsonRecord{ID: rng.Uint32(), Value: int32(rng.Uint32()), Flag: rng.Intn(2) == 0, Name: string(name)}
+	}
+	return reflect.ValueOf(records)
+}
+
+// withPlainNames returns records with the characters JSON escapes dropped
+// from their names, the records serializeToJson writes as valid JSON
+func withPlainNames(records recordSet) []JsonRecord {
+	plain := slices.Clone(records)
+	for i := range plain {
+		plain[i].Name = strings.NewReplacer(`"`, "", `\`, "").Replace(plain[i].Name)
+	}
+	return plain
+}
+
+func TestParseSerializeProperty(t *testing.T) {
+	// parse ∘ serialize preserves records, and what serializeToJson writes is
+	// JSON that encoding/json reads as the same records
+	roundTrip := func(records recordSet) bool {
+		plain := withPlainNames(records)
+		document := serializeToJson(plain)
+		parsed, err := parseJsonString(document)
+		var decoded []JsonRecord
+		return err == nil && slices.Equal(parsed, plain) &&
+			json.Unmarshal([]byte(document), &decoded) == nil && slices.Equal(decoded, plain) &&
+			fnv1aHashRecords(parsed) == fnv1aHashRecords(plain)
+	}
+	if err := quick.Check(roundTrip, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseAgreesWithEncodingJSONProperty(t *testing.T) {
+	// Documents encoding/json writes, escapes and all, parse to the records
+	// they were written from
+	agree := func(records recordSet) bool {
+		var document strings.Builder
+		encoder := json.NewEncoder(&document)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode([]JsonRecord(records)); err != nil {
+			return false
+		}
+		parsed, err := parseJsonString(document.String())
+		return err == nil && slices.Equal(parsed, records)
+	}
+	if err := quick.Check(agree, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGeneratedRecordsProperty(t *testing.T) {
+	// Any seed's records number as asked, with ids from 1, a flag that says
+	// whether the value is even, and their id's name
+	wellFormed := func(count uint8, seed uint64, pcg bool) bool {
+		generator := common.GeneratorLCG
+		if pcg {
+			generator = common.GeneratorPCG32
+		}
+		records := generateJsonRecords(int(count), seed, generator)
+		if len(records) != int(count) {
+			return false
+		}
+		for i, record := range records {
+			if record.ID != uint32(i+1) || record.Flag != (record.Value%2 == 0) || record.Name != "a"+strconv.Itoa(i+1) {
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(wellFormed, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
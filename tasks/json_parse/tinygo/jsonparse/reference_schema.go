@@ -0,0 +1,20 @@
+//go:build !wasm
+
+package jsonparse
+
+import "wasmbench/common/refschema"
+
+// ReferenceSchema is the schema of data/reference_hashes/json_parse.json: the
+// categories of its vectors, and the limits get_limits reports, which bound
+// the params of a vector that runs
+func ReferenceSchema() refschema.Schema {
+	return refschema.Schema{
+		Task:       "json_parse",
+		Fields:     ParamFields(),
+		Stages:     StageNames,
+		Categories: []string{"boundary", "critical", "edge_case", "error", "parsing_validation", "rng_validation", "runner", "systematic"},
+		Bounds: []refschema.Bound{
+			{Params: []string{"record_count"}, Max: maxRecordCount, Limit: "max_record_count"},
+		},
+	}
+}
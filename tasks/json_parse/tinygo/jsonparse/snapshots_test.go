@@ -0,0 +1,34 @@
+package jsonparse
+
+import (
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/snapshot"
+)
+
+// TestSnapshots compares each stage's output for a small seed with its
+// golden file in testdata/snapshots, so that a change in formatting shows up
+// at the stage that made it rather than only in the final hash
+func TestSnapshots(t *testing.T) {
+	defer SetOutput(0)
+
+	// Generation and serialization: the document the parse stage reads
+	snapshot.Match(t, "records_lcg.json", []byte(serializeToJson(generateJsonRecords(8, 12345, common.GeneratorLCG))))
+	snapshot.Match(t, "records_pcg32.json", []byte(serializeToJson(generateJsonRecords(8, 12345, common.GeneratorPCG32))))
+
+	// Parsing: the records the hash folds, one to a line as id, value, flag
+	// and name hash
+	SetOutput(1)
+	params := JsonParseParams{RecordCount: 8, Seed: 12345}
+	if RunTask(uintptr(unsafe.Pointer(&params))) == 0 {
+		t.Fatal("run failed")
+	}
+	data, elementSize := common.RunOutput()
+	words := make([]uint32, len(data)/4)
+	for i := range words {
+		words[i] = common.ReadUint32LE(data[i*4:])
+	}
+	snapshot.Match(t, "output.txt", snapshot.Uint32Grid(words, int(elementSize)/4))
+}
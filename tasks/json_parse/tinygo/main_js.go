@@ -0,0 +1,56 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"syscall/js"
+
+	"json_parse_wasm/jsonparse"
+	"wasmbench/common"
+)
+
+// Standard Go (GOOS=js) build: the //go:export functions are published
+// through syscall/js under the same names, then main blocks to keep them live
+func main() {
+	common.ExposeJS(map[string]common.JSExport{
+		"init":                 func(args []js.Value) any { jsonparse.Init(common.JSUint32(args, 0)); return nil },
+		"init64":               func(args []js.Value) any { jsonparse.Init64(common.JSUint64Arg(args, 0)); return nil },
+		"alloc":                func(args []js.Value) any { return jsonparse.Alloc(common.JSUint32(args, 0)) },
+		"alloc_uninitialized":  func(args []js.Value) any { return jsonparse.AllocUninitialized(common.JSUint32(args, 0)) },
+		"dealloc":              func(args []js.Value) any { jsonparse.Dealloc(common.JSPtr(args, 0)); return nil },
+		"get_scale_factor":     func(args []js.Value) any { return jsonparse.GetScaleFactor() },
+		"get_work_metrics":     func(args []js.Value) any { return jsonparse.GetWorkMetrics() },
+		"get_memory_stats":     func(args []js.Value) any { return jsonparse.GetMemoryStats() },
+		"params_fingerprint":   func(args []js.Value) any { return jsonparse.ParamsFingerprint() },
+		"get_limits":           func(args []js.Value) any { return jsonparse.GetLimits() },
+		"abi_version":          func(args []js.Value) any { return jsonparse.ABIVersion() },
+		"get_task_info":        func(args []js.Value) any { return jsonparse.GetTaskInfo() },
+		"reset_arena":          func(args []js.Value) any { jsonparse.ResetArena(); return nil },
+		"reserve_memory":       func(args []js.Value) any { return jsonparse.ReserveMemory(common.JSPtr(args, 0)) },
+		"set_memory_budget":    func(args []js.Value) any { jsonparse.SetMemoryBudget(common.JSUint32(args, 0)); return nil },
+		"get_max_memory_pages": func(args []js.Value) any { return jsonparse.GetMaxMemoryPages() },
+		"has_threads":          func(args []js.Value) any { return jsonparse.HasThreads() },
+		"set_thread_count":     func(args []js.Value) any { return jsonparse.SetThreadCount(common.JSUint32(args, 0)) },
+		"has_simd":             func(args []js.Value) any { return jsonparse.HasSIMD() },
+		"get_cancel_ptr":       func(args []js.Value) any { return jsonparse.GetCancelPtr() },
+		"set_checkpoints":      func(args []js.Value) any { jsonparse.SetCheckpoints(common.JSUint32(args, 0)); return nil },
+		"hash_input":           func(args []js.Value) any { return jsonparse.HashInput() },
+		"get_checkpoints":      func(args []js.Value) any { return jsonparse.GetCheckpoints() },
+		"set_output":           func(args []js.Value) any { jsonparse.SetOutput(common.JSUint32(args, 0)); return nil },
+		"get_output":           func(args []js.Value) any { return jsonparse.GetOutput() },
+		"get_result_ptr":       func(args []js.Value) any { return jsonparse.GetResultPtr() },
+		"get_last_error_ptr":   func(args []js.Value) any { return jsonparse.GetLastErrorPtr() },
+		"get_last_error_len":   func(args []js.Value) any { return jsonparse.GetLastErrorLen() },
+		"get_error_code":       func(args []js.Value) any { return jsonparse.GetErrorCode() },
+		"get_panic_ptr":        func(args []js.Value) any { return jsonparse.GetPanicPtr() },
+		"get_panic_len":        func(args []js.Value) any { return jsonparse.GetPanicLen() },
+		"run_task64":           func(args []js.Value) any { return common.JSUint64(jsonparse.RunTask64(common.JSPtr(args, 0))) },
+		"run_task_timed":       func(args []js.Value) any { return jsonparse.RunTaskTimed(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_v2":          func(args []js.Value) any { return jsonparse.RunTaskV2(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_packed":      func(args []js.Value) any { return common.JSUint64(jsonparse.RunTaskPacked(common.JSPtr(args, 0))) },
+		"validate_params":      func(args []js.Value) any { return jsonparse.ValidateParams(common.JSPtr(args, 0)) },
+		"self_test":            func(args []js.Value) any { return jsonparse.SelfTest() },
+		"run_task":             func(args []js.Value) any { return jsonparse.RunTask(common.JSPtr(args, 0)) },
+	})
+	select {}
+}
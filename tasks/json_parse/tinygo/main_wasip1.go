@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"unsafe"
+
+	"json_parse_wasm/jsonparse"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// WASI command: params as a JSON object on stdin (field names as in
+// get_task_info), result hash in decimal on stdout, status as the exit code
+func main() {
+	os.Exit(cli.Run(os.Stdin, os.Stdout, os.Stderr, jsonparse.ParamFields(),
+		func(params *jsonparse.JsonParseParams, result *common.TaskResult) uint32 {
+			return jsonparse.RunTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+		}))
+}
@@ -0,0 +1,187 @@
+/**
+ * mandelbrot in plain JavaScript: the baseline cmd/bench -js times the wasm
+ * builds against. It renders the same iteration counts in float64, with the
+ * TinyGo package's pixel mapping and escape test, and hashes them as it does.
+ */
+
+import {
+    ErrorCode,
+    FNV_OFFSET_BASIS,
+    HASH_FNV1A,
+    PROFILE_COMPUTE,
+    PROFILE_MEMORY,
+    SCALE_CUSTOM,
+    SCALE_LARGE,
+    VERIFY_FULL,
+    VERIFY_NONE,
+    Validator,
+    WORK_UNITS_PER_TARGET,
+    TaskError,
+    Status,
+    defineTask,
+    hashUint32,
+    paramsStruct,
+    reject
+} from '../../common/js/common.js';
+
+// Params fields, in the order of MandelbrotParams
+const FIELDS = [
+    'width',
+    'height',
+    'max_iter',
+    'center_real',
+    'center_imag',
+    'scale_factor',
+    'scale',
+    'profile',
+    'target_work',
+    'warmup_iterations',
+    'verification',
+    'allocator',
+    'hash_algorithm',
+    'generator'
+];
+
+// Image size and iteration budget of each scale tier
+const SCALE_PRESETS = [
+    null,
+    { width: 64, height: 64, max_iter: 100 },
+    { width: 256, height: 256, max_iter: 500 },
+    { width: 512, height: 512, max_iter: 1000 },
+    { width: 1024, height: 1024, max_iter: 2000 }
+];
+
+const MAX_IMAGE_DIMENSION = 10000;
+const MAX_TOTAL_PIXELS = 100000000;
+
+// The compute profile shrinks each side by 8 for 64× the iterations, the
+// memory profile grows each side by 4 for a 16th of them
+const COMPUTE_PROFILE_SHRINK = 8;
+const MEMORY_PROFILE_GROW = 4;
+
+/** Resolve the scale tier, check the params and calibrate them to target_work */
+function prepare(values) {
+    const params = paramsStruct(values, FIELDS);
+    if (params.scale !== SCALE_CUSTOM) {
+        if (params.scale > SCALE_LARGE) {
+            throw reject(ErrorCode.UNKNOWN_SCALE, 'unknown scale tier');
+        }
+        Object.assign(params, SCALE_PRESETS[params.scale]);
+    }
+
+    // The generator is accepted for a uniform params ABI; the image draws no random data
+    const v = new Validator();
+    v.nonZero(Math.min(params.width, params.height), 'width and height must be non-zero');
+    v.atMost(
+        Math.max(params.width, params.height),
+        MAX_IMAGE_DIMENSION,
+        'width or height exceeds the maximum image dimension'
+    );
+    v.finite('center and scale factor must be finite', params.center_real, params.center_imag, params.scale_factor);
+    v.positive(params.scale_factor, 'scale factor must be positive');
+    v.options(params);
+    v.unsupported(params.hash_algorithm === HASH_FNV1A, ErrorCode.UNKNOWN_HASH_ALGORITHM, 'xxHash32');
+    v.result();
+
+    // Double width and height until the pixel iterations reach the target,
+    // within the image limits
+    if (params.target_work !== 0) {
+        const target = params.target_work * WORK_UNITS_PER_TARGET;
+        while (params.width * params.height * params.max_iter < target) {
+            const width = params.width * 2;
+            const height = params.height * 2;
+            if (width > MAX_IMAGE_DIMENSION || height > MAX_IMAGE_DIMENSION || width * height > MAX_TOTAL_PIXELS) {
+                break;
+            }
+            params.width = width;
+            params.height = height;
+        }
+    }
+
+    if (params.profile === PROFILE_COMPUTE) {
+        params.width = Math.max(Math.floor(params.width / COMPUTE_PROFILE_SHRINK), 1);
+        params.height = Math.max(Math.floor(params.height / COMPUTE_PROFILE_SHRINK), 1);
+        params.max_iter = Math.min(params.max_iter * COMPUTE_PROFILE_SHRINK * COMPUTE_PROFILE_SHRINK, 4294967295);
+    } else if (params.profile === PROFILE_MEMORY) {
+        params.width = Math.min(params.width * MEMORY_PROFILE_GROW, MAX_IMAGE_DIMENSION);
+        params.height = Math.min(params.height * MEMORY_PROFILE_GROW, MAX_IMAGE_DIMENSION);
+        params.max_iter = Math.max(Math.floor(params.max_iter / (MEMORY_PROFILE_GROW * MEMORY_PROFILE_GROW)), 1);
+    }
+
+    if (params.width * params.height > MAX_TOTAL_PIXELS) {
+        throw reject(ErrorCode.TOO_LARGE, 'calibrated image exceeds the maximum total pixels');
+    }
+    return params;
+}
+
+/** Escape iterations of c, up to maxIter, testing the magnitude before each step */
+function pixel(cReal, cImag, maxIter) {
+    let zReal = 0;
+    let zImag = 0;
+    let iterations = 0;
+    while (iterations < maxIter) {
+        if (zReal * zReal + zImag * zImag > 4) {
+            break;
+        }
+        const zRealSq = zReal * zReal;
+        const zImagSq = zImag * zImag;
+        const zRealNew = zRealSq - zImagSq + cReal;
+        zImag = 2 * zReal * zImag + cImag;
+        zReal = zRealNew;
+        iterations++;
+    }
+    return iterations;
+}
+
+/** Render the params' image and return the hash, or checksum, of its counts */
+function execute(params) {
+    const { width, height, max_iter: maxIter } = params;
+    const counts = new Uint32Array(width * height);
+    for (let y = 0; y < height; y++) {
+        const yNorm = y / height - 0.5;
+        const cImag = params.center_imag + yNorm * params.scale_factor;
+        for (let x = 0; x < width; x++) {
+            const xNorm = x / width - 0.5;
+            counts[y * width + x] = pixel(params.center_real + xNorm * params.scale_factor, cImag, maxIter);
+        }
+    }
+
+    if (params.verification === VERIFY_NONE) {
+        let sum = 0;
+        for (let i = 0; i < counts.length; i++) {
+            sum = (sum + counts[i]) >>> 0;
+        }
+        return sum;
+    }
+    if (params.verification === VERIFY_FULL && counts.some(count => count > maxIter)) {
+        throw new TaskError(Status.VERIFICATION_FAILED, ErrorCode.NONE, 'iteration count outside [0, max_iter]');
+    }
+    let hash = FNV_OFFSET_BASIS;
+    for (let i = 0; i < counts.length; i++) {
+        hash = hashUint32(hash, counts[i]);
+    }
+    return hash;
+}
+
+export default defineTask({
+    name: 'mandelbrot',
+    prepare,
+    execute,
+    selfTestVectors: [
+        {
+            name: 'systematic_0_0_1_1',
+            params: { width: 2, height: 2, max_iter: 10, center_real: -0.5, scale_factor: 2 },
+            hash: 3542949155
+        },
+        {
+            name: 'systematic_2_1_2_1',
+            params: { width: 10, height: 10, max_iter: 100, center_real: -0.75, center_imag: 0.1, scale_factor: 2 },
+            hash: 1271585701
+        },
+        {
+            name: 'single_iteration',
+            params: { width: 10, height: 10, max_iter: 1, scale_factor: 6 },
+            hash: 1785930213
+        }
+    ]
+});
@@ -1,6 +1,6 @@
 // FNV-1a hashing implementation for cross-implementation verification
 
-use crate::types::{FNV_OFFSET_BASIS, FNV_PRIME};
+use crate::types::{MandelbrotParams, FNV_OFFSET_BASIS, FNV_PRIME};
 
 /// Computes FNV-1a hash of u32 array for cross-implementation verification
 /// Optimized version using iterator without array allocation
@@ -22,6 +22,26 @@ pub fn fnv1a_hash_u32(data: &[u32]) -> u32 {
     hash
 }
 
+/// Hashes what the image is rendered from, the input stage: width, height and
+/// max_iter, then the bits of center_real, center_imag and scale_factor, each
+/// little-endian, as the Go implementation's hashInput does
+pub fn hash_input(params: &MandelbrotParams) -> u32 {
+    let mut bytes = Vec::with_capacity(36);
+    for value in [params.width, params.height, params.max_iter] {
+        bytes.extend_from_slice(&value.to_le_bytes());
+    }
+    for value in [params.center_real, params.center_imag, params.scale_factor] {
+        bytes.extend_from_slice(&value.to_bits().to_le_bytes());
+    }
+
+    let mut hash = FNV_OFFSET_BASIS;
+    for byte in bytes {
+        hash ^= byte as u32;
+        hash = hash.wrapping_mul(FNV_PRIME);
+    }
+    hash
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -1,5 +1,6 @@
 use std::alloc::{alloc as sys_alloc, Layout};
 use std::os::raw::c_void;
+use std::sync::atomic::{AtomicU32, Ordering};
 
 pub mod hash;
 pub mod mandelbrot;
@@ -8,9 +9,12 @@ pub mod types;
 pub mod validation;
 
 use hash::fnv1a_hash_u32;
-use mandelbrot::mandelbrot_pixel;
+use mandelbrot::compute_iteration_counts;
 use types::{MandelbrotParams, MAX_ALLOCATION_SIZE, MAX_TOTAL_PIXELS};
-use validation::validate_parameters;
+use validation::{check_parameters, ParamError};
+
+// Code of the last rejected params, ParamError::None after an accepted run
+static LAST_ERROR_CODE: AtomicU32 = AtomicU32::new(ParamError::None as u32);
 
 // WebAssembly C-style interface exports
 
@@ -47,39 +51,34 @@ pub extern "C" fn alloc(n_bytes: u32) -> *mut c_void {
 #[no_mangle]
 pub extern "C" fn run_task(params_ptr: *mut c_void) -> u32 {
     if params_ptr.is_null() {
+        LAST_ERROR_CODE.store(ParamError::NullParams as u32, Ordering::Relaxed);
         return 0;
     }
 
     let params = unsafe { &*(params_ptr as *const MandelbrotParams) };
 
-    if !validate_parameters(params) {
+    if let Err(code) = check_parameters(params) {
+        LAST_ERROR_CODE.store(code as u32, Ordering::Relaxed);
         return 0;
     }
+    LAST_ERROR_CODE.store(ParamError::None as u32, Ordering::Relaxed);
 
     let total_pixels = match params.width.checked_mul(params.height) {
         Some(count) if count <= MAX_TOTAL_PIXELS => count,
         _ => return 0,
     };
 
-    let mut iteration_counts = Vec::with_capacity(total_pixels as usize);
-
-    for y in 0..params.height {
-        for x in 0..params.width {
-            // Map pixel to complex plane
-            let x_norm = (x as f64) / (params.width as f64) - 0.5;
-            let y_norm = (y as f64) / (params.height as f64) - 0.5;
-
-            let c_real = params.center_real + x_norm * params.scale_factor;
-            let c_imag = params.center_imag + y_norm * params.scale_factor;
-
-            let iterations = mandelbrot_pixel(c_real, c_imag, params.max_iter);
-            iteration_counts.push(iterations);
-        }
-    }
+    let iteration_counts = compute_iteration_counts(params, total_pixels);
 
     fnv1a_hash_u32(&iteration_counts)
 }
 
+/// Shared code of the last parameter rejection, 0 when the last run accepted its params
+#[no_mangle]
+pub extern "C" fn get_error_code() -> u32 {
+    LAST_ERROR_CODE.load(Ordering::Relaxed)
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -1,6 +1,6 @@
 // Core Mandelbrot set computation algorithms
 
-use crate::types::DIVERGENCE_THRESHOLD;
+use crate::types::{MandelbrotParams, DIVERGENCE_THRESHOLD};
 
 /// Computes the number of iterations for a single Mandelbrot set pixel
 /// Optimized version that caches squared values while preserving exact computation order
@@ -36,6 +36,28 @@ pub fn complex_magnitude_squared(real: f64, imag: f64) -> f64 {
     real * real + imag * imag
 }
 
+/// Computes the iteration count of every pixel, row by row, for params that
+/// passed validation and cover total_pixels pixels
+pub fn compute_iteration_counts(params: &MandelbrotParams, total_pixels: u32) -> Vec<u32> {
+    let mut iteration_counts = Vec::with_capacity(total_pixels as usize);
+
+    for y in 0..params.height {
+        for x in 0..params.width {
+            // Map pixel to complex plane
+            let x_norm = (x as f64) / (params.width as f64) - 0.5;
+            let y_norm = (y as f64) / (params.height as f64) - 0.5;
+
+            let c_real = params.center_real + x_norm * params.scale_factor;
+            let c_imag = params.center_imag + y_norm * params.scale_factor;
+
+            let iterations = mandelbrot_pixel(c_real, c_imag, params.max_iter);
+            iteration_counts.push(iterations);
+        }
+    }
+
+    iteration_counts
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -1,3 +1,7 @@
+use crate::hash::{fnv1a_hash_u32, hash_input};
+use crate::mandelbrot::compute_iteration_counts;
+use crate::types::{MAX_IMAGE_DIMENSION, MAX_TOTAL_PIXELS};
+use crate::validation::{check_parameters, ParamError, STATUS_OK};
 use crate::{run_task, MandelbrotParams};
 use serde::{Deserialize, Serialize};
 use std::alloc::{alloc as sys_alloc, Layout};
@@ -10,9 +14,50 @@ pub struct TestVector {
     pub description: String,
     pub params: SerializableParams,
     pub expected_hash: u32,
+    /// Status the run reports, STATUS_OK for vectors that produce a hash
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_status: u32,
+    /// ParamError code of a rejected run
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_error_code: u32,
+    /// Hashes of the stages before the result, for vectors that produce one
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub expected_stages: Option<StageHashes>,
     pub category: String,
 }
 
+/// Checkpoint hashes of a run's stages before its result, which is
+/// expected_hash, in the order the run reaches them. TinyGo records the same
+/// stages through get_checkpoints, so the first that differs is where two
+/// implementations diverged.
+#[derive(Serialize, Deserialize, Debug, Clone, Copy, PartialEq)]
+pub struct StageHashes {
+    /// Image geometry and iteration budget
+    pub input: u32,
+    /// Iteration counts, row by row
+    pub iterations: u32,
+}
+
+impl StageHashes {
+    /// Names the first stage whose hash differs from expected's, with both hashes
+    pub fn first_difference(&self, expected: &StageHashes) -> Option<String> {
+        [
+            ("input", self.input, expected.input),
+            ("iterations", self.iterations, expected.iterations),
+        ]
+        .into_iter()
+        .find(|(_, got, want)| got != want)
+        .map(|(stage, got, want)| {
+            format!("first diverging stage: {stage} hashed {got}, expected {want}")
+        })
+    }
+}
+
+/// Omits the status fields of vectors that succeed, keeping their JSON unchanged
+fn is_zero(value: &u32) -> bool {
+    *value == 0
+}
+
 /// Serializable version of MandelbrotParams for JSON export
 #[derive(Serialize, Deserialize, Debug, Clone)]
 pub struct SerializableParams {
@@ -83,6 +128,9 @@ pub fn generate_systematic_vectors() -> Vec<TestVector> {
                         ),
                         params: params.into(),
                         expected_hash: hash,
+                        expected_status: STATUS_OK,
+                        expected_error_code: ParamError::None as u32,
+                        expected_stages: compute_stage_hashes(&params),
                         category: "systematic".to_string(),
                     });
                 }
@@ -203,6 +251,9 @@ pub fn generate_manual_critical_vectors() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: (*params).into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "critical".to_string(),
             }
         })
@@ -284,6 +335,9 @@ pub fn generate_precision_vectors() -> Vec<TestVector> {
             description: desc.to_string(),
             params: (*params).into(),
             expected_hash: hash,
+            expected_status: STATUS_OK,
+            expected_error_code: ParamError::None as u32,
+            expected_stages: compute_stage_hashes(params),
             category: "precision".to_string(),
         });
     }
@@ -389,12 +443,81 @@ pub fn generate_edge_case_vectors() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: (*params).into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "edge_case".to_string(),
             }
         })
         .collect()
 }
 
+/// Generate error vectors: params every implementation must reject with the
+/// same status and error code, returning a zero hash
+pub fn generate_error_vectors() -> Vec<TestVector> {
+    let base = MandelbrotParams {
+        width: 10,
+        height: 10,
+        max_iter: 100,
+        center_real: 0.0,
+        center_imag: 0.0,
+        scale_factor: 4.0,
+    };
+    let error_cases = [
+        (
+            "error_zero_width",
+            "Zero width - rejected as a zero dimension",
+            MandelbrotParams { width: 0, ..base },
+        ),
+        (
+            "error_zero_height",
+            "Zero height - rejected as a zero dimension",
+            MandelbrotParams { height: 0, ..base },
+        ),
+        (
+            "error_width_over_limit",
+            "Width past the maximum image dimension - rejected as too large",
+            MandelbrotParams {
+                width: MAX_IMAGE_DIMENSION + 1,
+                ..base
+            },
+        ),
+        (
+            "error_zero_scale",
+            "Zero scale factor - rejected as non-positive",
+            MandelbrotParams {
+                scale_factor: 0.0,
+                ..base
+            },
+        ),
+        (
+            "error_negative_scale",
+            "Negative scale factor - rejected as non-positive",
+            MandelbrotParams {
+                scale_factor: -1.0,
+                ..base
+            },
+        ),
+    ];
+
+    error_cases
+        .iter()
+        .map(|(name, desc, params)| {
+            let code = check_parameters(params).expect_err("error vectors must be rejected");
+            TestVector {
+                name: name.to_string(),
+                description: desc.to_string(),
+                params: (*params).into(),
+                expected_hash: compute_reference_hash(params),
+                expected_status: code.status(),
+                expected_error_code: code as u32,
+                expected_stages: None,
+                category: "error".to_string(),
+            }
+        })
+        .collect()
+}
+
 /// Compute reference hash using the Rust implementation
 fn compute_reference_hash(params: &MandelbrotParams) -> u32 {
     // Allocate memory for parameters
@@ -423,6 +546,21 @@ fn compute_reference_hash(params: &MandelbrotParams) -> u32 {
     }
 }
 
+/// Hash each stage of a run, None for params the run rejects
+pub fn compute_stage_hashes(params: &MandelbrotParams) -> Option<StageHashes> {
+    check_parameters(params).ok()?;
+    let total_pixels = params
+        .width
+        .checked_mul(params.height)
+        .filter(|&count| count <= MAX_TOTAL_PIXELS)?;
+
+    let iteration_counts = compute_iteration_counts(params, total_pixels);
+    Some(StageHashes {
+        input: hash_input(params),
+        iterations: fnv1a_hash_u32(&iteration_counts),
+    })
+}
+
 /// Generate all test vectors
 pub fn generate_all_vectors() -> Vec<TestVector> {
     let mut all_vectors = Vec::new();
@@ -439,6 +577,9 @@ pub fn generate_all_vectors() -> Vec<TestVector> {
     println!("Generating edge case vectors...");
     all_vectors.extend(generate_edge_case_vectors());
 
+    println!("Generating error vectors...");
+    all_vectors.extend(generate_error_vectors());
+
     println!("Generated {} total test vectors", all_vectors.len());
 
     all_vectors
@@ -456,3 +597,43 @@ pub fn export_vectors_to_json(vectors: &[TestVector], filename: &str) -> std::io
     println!("Exported {} test vectors to {}", vectors.len(), filename);
     Ok(())
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_reference_file_stages() {
+        // The committed file, whose stages the TinyGo implementation recorded
+        let path = concat!(
+            env!("CARGO_MANIFEST_DIR"),
+            "/../../../data/reference_hashes/mandelbrot.json"
+        );
+        let data = std::fs::read_to_string(path).expect("Failed to read the reference file");
+        let vectors: Vec<TestVector> =
+            serde_json::from_str(&data).expect("Failed to parse the reference file");
+
+        // Deep zooms into large images take minutes in an unoptimized test build
+        let checked = vectors.iter().filter(|v| {
+            let work = v.params.width as u64 * v.params.height as u64 * v.params.max_iter as u64;
+            v.expected_status == STATUS_OK && work <= 1 << 24
+        });
+        for vector in checked {
+            let params = MandelbrotParams::from(vector.params.clone());
+            let stages =
+                compute_stage_hashes(&params).expect("Reference params should be accepted");
+            if let Some(diverged) = vector
+                .expected_stages
+                .and_then(|expected| stages.first_difference(&expected))
+            {
+                panic!("{}: {}", vector.name, diverged);
+            }
+            // The result is the iteration counts' hash
+            assert_eq!(
+                stages.iterations, vector.expected_hash,
+                "{}: every stage matches, but the result hash differs",
+                vector.name
+            );
+        }
+    }
+}
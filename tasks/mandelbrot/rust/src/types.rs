@@ -23,3 +23,51 @@ pub const MAX_ALLOCATION_SIZE: u32 = 1_073_741_824; // 1GB
 
 /// Mathematical constants for Mandelbrot computation
 pub const DIVERGENCE_THRESHOLD: f64 = 4.0;
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::mem::{offset_of, size_of};
+
+    #[test]
+    fn test_params_layout() {
+        // The layout the Go build asserts; the Rust struct has the leading
+        // fields of the Go one
+        let path = concat!(env!("CARGO_MANIFEST_DIR"), "/../../../configs/layouts.json");
+        let data = std::fs::read_to_string(path).expect("Failed to read the layout file");
+        let layouts: serde_json::Value =
+            serde_json::from_str(&data).expect("Failed to parse the layout file");
+        let layout = layouts["structs"]
+            .as_array()
+            .and_then(|structs| structs.iter().find(|s| s["name"] == "MandelbrotParams"))
+            .expect("MandelbrotParams is not in the layout file");
+
+        macro_rules! field {
+            ($name:ident: $ty:ty) => {
+                (
+                    stringify!($name),
+                    offset_of!(MandelbrotParams, $name),
+                    size_of::<$ty>(),
+                )
+            };
+        }
+        let fields = [
+            field!(width: u32),
+            field!(height: u32),
+            field!(max_iter: u32),
+            field!(center_real: f64),
+            field!(center_imag: f64),
+            field!(scale_factor: f64),
+        ];
+        for (i, (name, offset, size)) in fields.into_iter().enumerate() {
+            let field = &layout["fields"][i];
+            assert_eq!(field["name"], name, "Field {} of MandelbrotParams", i);
+            assert_eq!(field["offset"], offset as u64, "Offset of {}", name);
+            assert_eq!(field["size"], size as u64, "Size of {}", name);
+        }
+        assert!(
+            size_of::<MandelbrotParams>() as u64 <= layout["size"].as_u64().unwrap(),
+            "MandelbrotParams is larger than the layout file's"
+        );
+    }
+}
@@ -2,24 +2,61 @@
 
 use crate::types::{MandelbrotParams, MAX_IMAGE_DIMENSION, MAX_TOTAL_PIXELS};
 
+/// Parameter error codes, identical to the TinyGo modules' shared codes
+#[repr(u32)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ParamError {
+    None = 0,
+    NullParams = 1,
+    BadEncoding = 2,
+    ZeroDimension = 3,
+    TooLarge = 4,
+    NonFinite = 5,
+    NonPositive = 6,
+    UnknownScale = 7,
+    UnknownProfile = 8,
+    UnknownVerification = 9,
+    UnknownAllocator = 10,
+    UnknownHashAlgorithm = 11,
+    UnknownGenerator = 12,
+}
+
+/// Run statuses, identical to the TinyGo modules' status codes
+pub const STATUS_OK: u32 = 0;
+pub const STATUS_INVALID_PARAMS: u32 = 1;
+pub const STATUS_OVERFLOW: u32 = 2;
+
+impl ParamError {
+    /// Status a run rejected with this code reports: limits overflow, every
+    /// other rejection is invalid params
+    pub fn status(self) -> u32 {
+        match self {
+            ParamError::None => STATUS_OK,
+            ParamError::TooLarge => STATUS_OVERFLOW,
+            _ => STATUS_INVALID_PARAMS,
+        }
+    }
+}
+
 /// Validates MandelbrotParams to prevent resource exhaustion and invalid computations
 pub fn validate_parameters(params: &MandelbrotParams) -> bool {
+    check_parameters(params).is_ok()
+}
+
+/// Checks MandelbrotParams, returning the code of the first rejected field
+pub fn check_parameters(params: &MandelbrotParams) -> Result<(), ParamError> {
     // Check for reasonable image dimensions
-    if params.width == 0
-        || params.height == 0
-        || params.width > MAX_IMAGE_DIMENSION
-        || params.height > MAX_IMAGE_DIMENSION
-    {
-        return false;
+    if params.width == 0 || params.height == 0 {
+        return Err(ParamError::ZeroDimension);
+    }
+    if params.width > MAX_IMAGE_DIMENSION || params.height > MAX_IMAGE_DIMENSION {
+        return Err(ParamError::TooLarge);
     }
 
-    // Check total pixel count
-    if let Some(total_pixels) = params.width.checked_mul(params.height) {
-        if total_pixels > MAX_TOTAL_PIXELS {
-            return false;
-        }
-    } else {
-        return false; // Overflow in pixel calculation
+    // Check total pixel count, including overflow in the pixel calculation
+    match params.width.checked_mul(params.height) {
+        Some(total_pixels) if total_pixels <= MAX_TOTAL_PIXELS => {}
+        _ => return Err(ParamError::TooLarge),
     }
 
     // Check for finite floating point values
@@ -27,15 +64,15 @@ pub fn validate_parameters(params: &MandelbrotParams) -> bool {
         || !params.center_imag.is_finite()
         || !params.scale_factor.is_finite()
     {
-        return false;
+        return Err(ParamError::NonFinite);
     }
 
     // Check for positive scale factor
     if params.scale_factor <= 0.0 {
-        return false;
+        return Err(ParamError::NonPositive);
     }
 
-    true
+    Ok(())
 }
 
 #[cfg(test)]
@@ -82,4 +119,60 @@ mod tests {
         };
         assert!(!validate_parameters(&invalid_center));
     }
+
+    #[test]
+    fn test_parameter_error_codes() {
+        let valid_params = MandelbrotParams {
+            width: 100,
+            height: 100,
+            max_iter: 1000,
+            center_real: 0.0,
+            center_imag: 0.0,
+            scale_factor: 4.0,
+        };
+        assert_eq!(check_parameters(&valid_params), Ok(()));
+
+        let cases = [
+            (
+                MandelbrotParams {
+                    width: 0,
+                    ..valid_params
+                },
+                ParamError::ZeroDimension,
+            ),
+            (
+                MandelbrotParams {
+                    height: MAX_IMAGE_DIMENSION + 1,
+                    ..valid_params
+                },
+                ParamError::TooLarge,
+            ),
+            (
+                MandelbrotParams {
+                    center_imag: f64::INFINITY,
+                    ..valid_params
+                },
+                ParamError::NonFinite,
+            ),
+            (
+                MandelbrotParams {
+                    scale_factor: -1.0,
+                    ..valid_params
+                },
+                ParamError::NonPositive,
+            ),
+        ];
+        for (params, code) in cases {
+            assert_eq!(check_parameters(&params), Err(code));
+        }
+        assert_eq!(ParamError::UnknownGenerator as u32, 12);
+    }
+
+    #[test]
+    fn test_error_statuses() {
+        assert_eq!(ParamError::None.status(), STATUS_OK);
+        assert_eq!(ParamError::TooLarge.status(), STATUS_OVERFLOW);
+        assert_eq!(ParamError::ZeroDimension.status(), STATUS_INVALID_PARAMS);
+        assert_eq!(ParamError::NonPositive.status(), STATUS_INVALID_PARAMS);
+    }
 }
@@ -0,0 +1,206 @@
+//go:build tinygo
+
+package main
+
+import "mandelbrot_wasm/mandelbrot"
+
+// The TinyGo build's exports: each forwards to package mandelbrot, which holds
+// the task and builds for the host too
+
+//go:export init
+func initWasm(seed uint32) {
+	mandelbrot.Init(seed)
+}
+
+//go:export init64
+func initWasm64(seed uint64) {
+	mandelbrot.Init64(seed)
+}
+
+//go:export alloc
+func alloc(nBytes uint32) uintptr {
+	return mandelbrot.Alloc(nBytes)
+}
+
+//go:export alloc_uninitialized
+func allocUninitialized(nBytes uint32) uintptr {
+	return mandelbrot.AllocUninitialized(nBytes)
+}
+
+// TinyGo's wasm runtime already exports malloc/free, so the release
+// counterpart of alloc is exported as dealloc
+//
+//go:export dealloc
+func dealloc(ptr uintptr) {
+	mandelbrot.Dealloc(ptr)
+}
+
+//go:export get_scale_factor
+func getScaleFactor() uint32 {
+	return mandelbrot.GetScaleFactor()
+}
+
+//go:export get_work_metrics
+func getWorkMetrics() uintptr {
+	return mandelbrot.GetWorkMetrics()
+}
+
+//go:export get_memory_stats
+func getMemoryStats() uintptr {
+	return mandelbrot.GetMemoryStats()
+}
+
+//go:export params_fingerprint
+func paramsFingerprint() uint32 {
+	return mandelbrot.ParamsFingerprint()
+}
+
+//go:export get_limits
+func getLimits() uintptr {
+	return mandelbrot.GetLimits()
+}
+
+//go:export abi_version
+func abiVersion() uint32 {
+	return mandelbrot.ABIVersion()
+}
+
+//go:export get_task_info
+func getTaskInfo() uintptr {
+	return mandelbrot.GetTaskInfo()
+}
+
+//go:export reset_arena
+func resetArena() {
+	mandelbrot.ResetArena()
+}
+
+//go:export reset_iteration_buffer
+func resetIterationBuffer() {
+	mandelbrot.ResetIterationBuffer()
+}
+
+//go:export reserve_memory
+func reserveMemory(paramsPtr uintptr) uint32 {
+	return mandelbrot.ReserveMemory(paramsPtr)
+}
+
+//go:export set_memory_budget
+func setMemoryBudget(pages uint32) {
+	mandelbrot.SetMemoryBudget(pages)
+}
+
+//go:export get_max_memory_pages
+func getMaxMemoryPages() uint32 {
+	return mandelbrot.GetMaxMemoryPages()
+}
+
+//go:export has_threads
+func hasThreads() uint32 {
+	return mandelbrot.HasThreads()
+}
+
+//go:export set_thread_count
+func setThreadCount(n uint32) uint32 {
+	return mandelbrot.SetThreadCount(n)
+}
+
+//go:export has_simd
+func hasSIMD() uint32 {
+	return mandelbrot.HasSIMD()
+}
+
+//go:export get_cancel_ptr
+func getCancelPtr() uintptr {
+	return mandelbrot.GetCancelPtr()
+}
+
+//go:export set_checkpoints
+func setCheckpoints(enabled uint32) {
+	mandelbrot.SetCheckpoints(enabled)
+}
+
+//go:export hash_input
+func hashInput() uint32 {
+	return mandelbrot.HashInput()
+}
+
+//go:export get_checkpoints
+func getCheckpoints() uintptr {
+	return mandelbrot.GetCheckpoints()
+}
+
+//go:export set_output
+func setOutput(enabled uint32) {
+	mandelbrot.SetOutput(enabled)
+}
+
+//go:export get_output
+func getOutput() uintptr {
+	return mandelbrot.GetOutput()
+}
+
+//go:export get_result_ptr
+func getResultPtr() uintptr {
+	return mandelbrot.GetResultPtr()
+}
+
+//go:export get_last_error_ptr
+func getLastErrorPtr() uintptr {
+	return mandelbrot.GetLastErrorPtr()
+}
+
+//go:export get_last_error_len
+func getLastErrorLen() uint32 {
+	return mandelbrot.GetLastErrorLen()
+}
+
+//go:export get_error_code
+func getErrorCode() uint32 {
+	return mandelbrot.GetErrorCode()
+}
+
+//go:export get_panic_ptr
+func getPanicPtr() uintptr {
+	return mandelbrot.GetPanicPtr()
+}
+
+//go:export get_panic_len
+func getPanicLen() uint32 {
+	return mandelbrot.GetPanicLen()
+}
+
+//go:export run_task64
+func runTask64(paramsPtr uintptr) uint64 {
+	return mandelbrot.RunTask64(paramsPtr)
+}
+
+//go:export run_task_timed
+func runTaskTimed(paramsPtr, resultPtr uintptr) uint32 {
+	return mandelbrot.RunTaskTimed(paramsPtr, resultPtr)
+}
+
+//go:export run_task_v2
+func runTaskV2(paramsPtr, resultPtr uintptr) uint32 {
+	return mandelbrot.RunTaskV2(paramsPtr, resultPtr)
+}
+
+//go:export run_task_packed
+func runTaskPacked(paramsPtr uintptr) uint64 {
+	return mandelbrot.RunTaskPacked(paramsPtr)
+}
+
+//go:export validate_params
+func validateParams(paramsPtr uintptr) uint32 {
+	return mandelbrot.ValidateParams(paramsPtr)
+}
+
+//go:export self_test
+func selfTest() uint32 {
+	return mandelbrot.SelfTest()
+}
+
+//go:export run_task
+func runTask(paramsPtr uintptr) (hash uint32) {
+	return mandelbrot.RunTask(paramsPtr)
+}
@@ -0,0 +1,8 @@
+//go:build !wasip1 && (tinygo || !js)
+
+package main
+
+// Required for TinyGo WebAssembly compilation
+func main() {
+	// Empty main function required for compilation
+}
@@ -0,0 +1,59 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"syscall/js"
+
+	"mandelbrot_wasm/mandelbrot"
+	"wasmbench/common"
+)
+
+// Standard Go (GOOS=js) build: the //go:export functions are published
+// through syscall/js under the same names, then main blocks to keep them live
+func main() {
+	common.ExposeJS(map[string]common.JSExport{
+		"init":                   func(args []js.Value) any { mandelbrot.Init(common.JSUint32(args, 0)); return nil },
+		"init64":                 func(args []js.Value) any { mandelbrot.Init64(common.JSUint64Arg(args, 0)); return nil },
+		"alloc":                  func(args []js.Value) any { return mandelbrot.Alloc(common.JSUint32(args, 0)) },
+		"alloc_uninitialized":    func(args []js.Value) any { return mandelbrot.AllocUninitialized(common.JSUint32(args, 0)) },
+		"dealloc":                func(args []js.Value) any { mandelbrot.Dealloc(common.JSPtr(args, 0)); return nil },
+		"get_scale_factor":       func(args []js.Value) any { return mandelbrot.GetScaleFactor() },
+		"get_work_metrics":       func(args []js.Value) any { return mandelbrot.GetWorkMetrics() },
+		"get_memory_stats":       func(args []js.Value) any { return mandelbrot.GetMemoryStats() },
+		"params_fingerprint":     func(args []js.Value) any { return mandelbrot.ParamsFingerprint() },
+		"get_limits":             func(args []js.Value) any { return mandelbrot.GetLimits() },
+		"abi_version":            func(args []js.Value) any { return mandelbrot.ABIVersion() },
+		"get_task_info":          func(args []js.Value) any { return mandelbrot.GetTaskInfo() },
+		"reset_arena":            func(args []js.Value) any { mandelbrot.ResetArena(); return nil },
+		"reset_iteration_buffer": func(args []js.Value) any { mandelbrot.ResetIterationBuffer(); return nil },
+		"reserve_memory":         func(args []js.Value) any { return mandelbrot.ReserveMemory(common.JSPtr(args, 0)) },
+		"set_memory_budget":      func(args []js.Value) any { mandelbrot.SetMemoryBudget(common.JSUint32(args, 0)); return nil },
+		"get_max_memory_pages":   func(args []js.Value) any { return mandelbrot.GetMaxMemoryPages() },
+		"has_threads":            func(args []js.Value) any { return mandelbrot.HasThreads() },
+		"set_thread_count":       func(args []js.Value) any { return mandelbrot.SetThreadCount(common.JSUint32(args, 0)) },
+		"has_simd":               func(args []js.Value) any { return mandelbrot.HasSIMD() },
+		"get_cancel_ptr":         func(args []js.Value) any { return mandelbrot.GetCancelPtr() },
+		"set_checkpoints":        func(args []js.Value) any { mandelbrot.SetCheckpoints(common.JSUint32(args, 0)); return nil },
+		"hash_input":             func(args []js.Value) any { return mandelbrot.HashInput() },
+		"get_checkpoints":        func(args []js.Value) any { return mandelbrot.GetCheckpoints() },
+		"set_output":             func(args []js.Value) any { mandelbrot.SetOutput(common.JSUint32(args, 0)); return nil },
+		"get_output":             func(args []js.Value) any { return mandelbrot.GetOutput() },
+		"get_result_ptr":         func(args []js.Value) any { return mandelbrot.GetResultPtr() },
+		"get_last_error_ptr":     func(args []js.Value) any { return mandelbrot.GetLastErrorPtr() },
+		"get_last_error_len":     func(args []js.Value) any { return mandelbrot.GetLastErrorLen() },
+		"get_error_code":         func(args []js.Value) any { return mandelbrot.GetErrorCode() },
+		"get_panic_ptr":          func(args []js.Value) any { return mandelbrot.GetPanicPtr() },
+		"get_panic_len":          func(args []js.Value) any { return mandelbrot.GetPanicLen() },
+		"run_task64":             func(args []js.Value) any { return common.JSUint64(mandelbrot.RunTask64(common.JSPtr(args, 0))) },
+		"run_task_timed": func(args []js.Value) any {
+			return mandelbrot.RunTaskTimed(common.JSPtr(args, 0), common.JSPtr(args, 1))
+		},
+		"run_task_v2":     func(args []js.Value) any { return mandelbrot.RunTaskV2(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_packed": func(args []js.Value) any { return common.JSUint64(mandelbrot.RunTaskPacked(common.JSPtr(args, 0))) },
+		"validate_params": func(args []js.Value) any { return mandelbrot.ValidateParams(common.JSPtr(args, 0)) },
+		"self_test":       func(args []js.Value) any { return mandelbrot.SelfTest() },
+		"run_task":        func(args []js.Value) any { return mandelbrot.RunTask(common.JSPtr(args, 0)) },
+	})
+	select {}
+}
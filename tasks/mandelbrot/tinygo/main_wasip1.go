@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"unsafe"
+
+	"mandelbrot_wasm/mandelbrot"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// WASI command: params as a JSON object on stdin (field names as in
+// get_task_info), result hash in decimal on stdout, status as the exit code
+func main() {
+	os.Exit(cli.Run(os.Stdin, os.Stdout, os.Stderr, mandelbrot.ParamFields(),
+		func(params *mandelbrot.MandelbrotParams, result *common.TaskResult) uint32 {
+			return mandelbrot.RunTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+		}))
+}
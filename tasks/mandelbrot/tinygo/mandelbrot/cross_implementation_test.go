@@ -0,0 +1,98 @@
+// Package mandelbrot provides cross-implementation validation tests for the Mandelbrot set
+// WebAssembly module, ensuring compatibility between TinyGo and Rust implementations.
+package mandelbrot
+
+import (
+	_ "embed"
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common/conformance"
+)
+
+// Test configuration constants
+const (
+	// Memory layout test parameters
+	testWidth       = 100
+	testHeight      = 200
+	testMaxIter     = 1000
+	testCenterReal  = -0.5
+	testCenterImag  = 0.25
+	testScaleFactor = 2.0
+)
+
+// referenceHashes is data/reference_hashes/mandelbrot.json, copied beside the
+// package by cmd/genrefs so the tests find it wherever they run
+//
+//go:embed testdata/reference_hashes.json
+var referenceHashes []byte
+
+// TestCrossImplementationHashMatching validates that the TinyGo implementation
+// produces the reference hash, status and stage hashes of every test vector,
+// under the conformance policy every task shares
+func TestCrossImplementationHashMatching(t *testing.T) {
+	conformance.Run(t, conformance.Task{
+		Name:    "mandelbrot",
+		Schema:  ReferenceSchema(),
+		Size:    unsafe.Sizeof(MandelbrotParams{}),
+		Run:     RunTaskV2,
+		Vectors: referenceHashes,
+		Source:  "testdata/reference_hashes.json",
+	})
+}
+
+// TestMemoryLayoutCompatibility verifies that the MandelbrotParams struct
+// has the same memory layout as the Rust implementation, ensuring correct
+// WebAssembly interoperability when parameters are passed between implementations.
+func TestMemoryLayoutCompatibility(t *testing.T) {
+	// Test with predefined parameters
+	originalParams := MandelbrotParams{
+		Width:       testWidth,
+		Height:      testHeight,
+		MaxIter:     testMaxIter,
+		CenterReal:  testCenterReal,
+		CenterImag:  testCenterImag,
+		ScaleFactor: testScaleFactor,
+	}
+
+	t.Logf("Testing memory layout compatibility with params: %+v", originalParams)
+
+	// Get pointer and verify we can parse it back correctly
+	ptr := uintptr(unsafe.Pointer(&originalParams))
+	parsed := parseParams(ptr)
+
+	// Create detailed comparison
+	layoutErrors := make([]string, 0)
+
+	if parsed.Width != originalParams.Width {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("Width mismatch: expected %d, got %d", originalParams.Width, parsed.Width))
+	}
+	if parsed.Height != originalParams.Height {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("Height mismatch: expected %d, got %d", originalParams.Height, parsed.Height))
+	}
+	if parsed.MaxIter != originalParams.MaxIter {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("MaxIter mismatch: expected %d, got %d", originalParams.MaxIter, parsed.MaxIter))
+	}
+	if parsed.CenterReal != originalParams.CenterReal {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("CenterReal mismatch: expected %f, got %f", originalParams.CenterReal, parsed.CenterReal))
+	}
+	if parsed.CenterImag != originalParams.CenterImag {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("CenterImag mismatch: expected %f, got %f", originalParams.CenterImag, parsed.CenterImag))
+	}
+	if parsed.ScaleFactor != originalParams.ScaleFactor {
+		layoutErrors = append(layoutErrors, fmt.Sprintf("ScaleFactor mismatch: expected %f, got %f", originalParams.ScaleFactor, parsed.ScaleFactor))
+	}
+
+	if len(layoutErrors) > 0 {
+		t.Errorf("❌ MEMORY LAYOUT INCOMPATIBLE\nParameter parsing failed - struct layout differs from Rust.\nThis will cause incorrect WebAssembly interop.\nDetected issues:\n%s\nCheck struct field order and padding.", "  - "+fmt.Sprintf("%s", layoutErrors[0])+"\n") // Show first error for brevity
+
+		// Log all errors for debugging
+		for _, err := range layoutErrors {
+			t.Logf("Layout error: %s", err)
+		}
+	} else {
+		t.Logf("✅ Memory layout compatible with Rust implementation")
+		t.Logf("Successfully round-tripped parameters through unsafe pointer conversion")
+	}
+}
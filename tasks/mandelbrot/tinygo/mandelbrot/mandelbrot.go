@@ -0,0 +1,869 @@
+// Package mandelbrot implements the Mandelbrot set benchmark task. It builds
+// for the host as well as for WebAssembly, so the task can be tested,
+// benchmarked and profiled natively; the module's main package only wraps the
+// exported entry points as //go:export functions.
+package mandelbrot
+
+// The reference hashes in data/reference_hashes are this implementation's
+// output, written by cmd/genrefs; regenerate them after changing what a task
+// hashes, and go test in cmd/genrefs fails until then
+//
+//go:generate go run -C ../../../../cmd/genrefs . mandelbrot
+
+import (
+	"math"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Constants for validation and computation
+const (
+	// Validation limits to prevent resource exhaustion
+	maxImageDimension = 10_000
+	maxTotalPixels    = 100_000_000
+
+	// Mathematical constants
+	divergenceThreshold = 4.0
+)
+
+// scalePresets maps each scale tier to its image size and iteration budget,
+// mirroring the tiers used by the benchmark configuration files
+// (common.ScaleCustom uses the raw Width/Height/MaxIter fields)
+var scalePresets = [...]struct {
+	width, height, maxIter uint32
+}{
+	common.ScaleMicro:  {64, 64, 100},
+	common.ScaleSmall:  {256, 256, 500},
+	common.ScaleMedium: {512, 512, 1000},
+	common.ScaleLarge:  {1024, 1024, 2000},
+}
+
+// Workload profiles redistribute the pixel×iteration budget: the compute
+// profile renders fewer pixels with more iterations, the memory profile more
+// pixels with fewer iterations
+const (
+	// Compute profile shrinks each side by 8 (64× fewer pixels, 64× more iterations)
+	computeProfileShrink = 8
+	// Memory profile grows each side by 4 (16× more pixels, 16× fewer iterations)
+	memoryProfileGrow = 4
+)
+
+// Linear scale factor chosen by the last self-calibrated run (1 = not scaled)
+var lastScaleFactor uint32 = 1
+
+// Work performed by the last measured run, exposed through get_work_metrics.
+// ElementsProcessed counts rendered pixels and BytesTouched the iteration
+// buffer bytes written.
+var lastWorkMetrics common.WorkMetrics
+
+// Status of the last run_task call, reported through run_task_v2
+var lastStatus = common.StatusOK
+
+// Host-clock duration of the last measured run, reported through run_task_timed
+var lastElapsedMs float64
+
+// wideHash makes the measured run also compute the 64-bit FNV-1a hash that
+// run_task64 returns; hasHash64 reports whether lastHash64 was set
+var (
+	wideHash   bool
+	lastHash64 uint64
+	hasHash64  bool
+)
+
+// Arena holding the iteration buffer of arena-allocated runs
+var scratchArena common.Arena
+
+// iterationBuffer backs the iteration counts of heap-allocated runs. It grows
+// to the largest image rendered and is kept between runs, so repeated runs
+// allocate nothing; reset_iteration_buffer drops it for cold-start runs
+var iterationBuffer []uint32
+
+// useSIMD selects the two-lane pixel path, chosen at init for SIMD builds
+var useSIMD = common.SIMDBuild
+
+// Parameter limits enforced by run_task, exposed through get_limits
+var taskLimits = Limits{
+	WordCount:           uint32(unsafe.Sizeof(Limits{})/4 - 1),
+	MaxAllocationSize:   common.MaxAllocationSize,
+	MaxWarmupIterations: common.MaxWarmupIterations,
+	MaxScale:            common.ScaleLarge,
+	MaxProfile:          common.ProfileMemory,
+	MaxVerification:     common.VerifyFull,
+	MaxAllocator:        common.AllocatorArena,
+	MaxHashAlgorithm:    common.HashXXHash32,
+	MaxGenerator:        common.GeneratorHost,
+	MaxImageDimension:   maxImageDimension,
+	MaxTotalPixels:      maxTotalPixels,
+}
+
+// Stages hashed for get_checkpoints when enabled, in StageNames order
+const (
+	stageInput      = common.StageInput // Resolved image geometry and iteration budget
+	stageIterations = iota              // Iteration counts, FNV-1a whatever the hash algorithm
+	stageOutput                         // The run's result hash
+)
+
+// StageNames names the checkpoint stages in get_task_info
+var StageNames = []string{"input", "iterations", "output"}
+
+// Size of an element of get_output: a pixel's iteration count as a
+// little-endian u32, row by row
+const outputElementSize = 4
+
+// Params layout read by every run, built once so reading params does not allocate
+var paramFields = ParamFields()
+
+// Length-prefixed task metadata JSON, exposed through get_task_info
+var taskInfo = common.EncodeTaskInfo(common.TaskInfo{
+	Task:       "mandelbrot",
+	Language:   "tinygo",
+	Variant:    "escape-time",
+	ParamsSize: unsafe.Sizeof(MandelbrotParams{}),
+	Params:     ParamFields(),
+	Stages:     StageNames,
+})
+
+// Known-answer vectors run by self_test, from data/reference_hashes/mandelbrot.json
+var selfTestVectors = []common.KnownAnswer[MandelbrotParams]{
+	{Name: "systematic_0_0_1_1", Params: MandelbrotParams{Width: 2, Height: 2, MaxIter: 10, CenterReal: -0.5, ScaleFactor: 2}, Hash: 3542949155},
+	{Name: "systematic_2_1_2_1", Params: MandelbrotParams{Width: 10, Height: 10, MaxIter: 100, CenterReal: -0.75, CenterImag: 0.1, ScaleFactor: 2}, Hash: 1271585701},
+	{Name: "single_iteration", Params: MandelbrotParams{Width: 10, Height: 10, MaxIter: 1, ScaleFactor: 6}, Hash: 1785930213},
+}
+
+//
+// WebAssembly Interface Functions
+//
+
+// Init implements init
+func Init(seed uint32) {
+	// Initialize WebAssembly module - no-op for this implementation
+	_ = seed
+}
+
+// Init64 implements init64
+func Init64(seed uint64) {
+	_ = seed
+}
+
+// Alloc implements alloc
+func Alloc(nBytes uint32) uintptr {
+	return common.Alloc(nBytes)
+}
+
+// AllocUninitialized implements alloc_uninitialized, reusing a released
+// buffer without zeroing it
+func AllocUninitialized(nBytes uint32) uintptr {
+	return common.AllocUninitialized(nBytes)
+}
+
+// Dealloc implements dealloc, releasing a buffer returned by Alloc
+func Dealloc(ptr uintptr) {
+	common.Free(ptr)
+}
+
+// GetScaleFactor implements get_scale_factor
+func GetScaleFactor() uint32 {
+	return lastScaleFactor
+}
+
+// GetWorkMetrics implements get_work_metrics
+func GetWorkMetrics() uintptr {
+	return uintptr(unsafe.Pointer(&lastWorkMetrics))
+}
+
+// GetMemoryStats implements get_memory_stats
+func GetMemoryStats() uintptr {
+	return uintptr(unsafe.Pointer(common.SnapshotMemoryStats()))
+}
+
+// ParamsFingerprint implements params_fingerprint
+func ParamsFingerprint() uint32 {
+	return layoutFingerprint()
+}
+
+// GetLimits implements get_limits
+func GetLimits() uintptr {
+	return uintptr(unsafe.Pointer(&taskLimits))
+}
+
+// ABIVersion implements abi_version
+func ABIVersion() uint32 {
+	return common.ABIVersion
+}
+
+// GetTaskInfo implements get_task_info
+func GetTaskInfo() uintptr {
+	return uintptr(unsafe.Pointer(&taskInfo[0]))
+}
+
+// ResetArena implements reset_arena
+func ResetArena() {
+	scratchArena.Reset()
+}
+
+// ResetIterationBuffer implements reset_iteration_buffer
+func ResetIterationBuffer() {
+	iterationBuffer = nil
+}
+
+// ReserveMemory implements reserve_memory
+func ReserveMemory(paramsPtr uintptr) uint32 {
+	lastStatus = common.StatusOK
+	common.ClearLastError()
+	common.ReleaseReservation()
+	if paramsPtr == 0 {
+		return lastStatus
+	}
+
+	params, _, status, message := prepareParams(paramsPtr)
+	if status != common.StatusOK {
+		fail(status, message)
+		return lastStatus
+	}
+	iterationBuffer = nil // Reserved runs render into the arena
+	common.ReserveArena(&scratchArena, workingSet(&params))
+	return lastStatus
+}
+
+// SetMemoryBudget implements set_memory_budget
+func SetMemoryBudget(pages uint32) {
+	common.SetMemoryBudget(pages)
+}
+
+// GetMaxMemoryPages implements get_max_memory_pages
+func GetMaxMemoryPages() uint32 {
+	return common.MaxMemoryPages()
+}
+
+// HasThreads implements has_threads
+func HasThreads() uint32 {
+	return common.HasThreads()
+}
+
+// SetThreadCount implements set_thread_count
+func SetThreadCount(n uint32) uint32 {
+	return common.SetThreadCount(n)
+}
+
+// HasSIMD implements has_simd
+func HasSIMD() uint32 {
+	return common.HasSIMD()
+}
+
+// GetCancelPtr implements get_cancel_ptr
+func GetCancelPtr() uintptr {
+	return common.CancelPtr()
+}
+
+// SetCheckpoints implements set_checkpoints
+func SetCheckpoints(enabled uint32) {
+	common.EnableCheckpoints(enabled != 0)
+}
+
+// HashInput implements hash_input
+func HashInput() uint32 {
+	hash, _ := common.CheckpointHash(stageInput)
+	return hash
+}
+
+// GetCheckpoints implements get_checkpoints
+func GetCheckpoints() uintptr {
+	return common.CheckpointsPtr()
+}
+
+// SetOutput implements set_output
+func SetOutput(enabled uint32) {
+	common.EnableOutput(enabled != 0)
+}
+
+// GetOutput implements get_output
+func GetOutput() uintptr {
+	return common.OutputPtr()
+}
+
+// GetResultPtr implements get_result_ptr
+func GetResultPtr() uintptr {
+	return common.ResultPtr()
+}
+
+// GetLastErrorPtr implements get_last_error_ptr
+func GetLastErrorPtr() uintptr {
+	return common.LastErrorPtr()
+}
+
+// GetLastErrorLen implements get_last_error_len
+func GetLastErrorLen() uint32 {
+	return common.LastErrorLen()
+}
+
+// GetErrorCode implements get_error_code
+func GetErrorCode() uint32 {
+	return common.ErrorCode()
+}
+
+// GetPanicPtr implements get_panic_ptr
+func GetPanicPtr() uintptr {
+	return common.PanicMessagePtr()
+}
+
+// GetPanicLen implements get_panic_len
+func GetPanicLen() uint32 {
+	return common.PanicMessageLen()
+}
+
+// RunTask64 implements run_task64
+func RunTask64(paramsPtr uintptr) uint64 {
+	wideHash, lastHash64, hasHash64 = true, 0, false
+	hash := RunTask(paramsPtr)
+	wideHash = false
+
+	if !hasHash64 {
+		return uint64(hash)
+	}
+	return lastHash64
+}
+
+// RunTaskTimed implements run_task_timed
+func RunTaskTimed(paramsPtr, resultPtr uintptr) uint32 {
+	hash := RunTask(paramsPtr)
+	if resultPtr != 0 {
+		common.TimedResult{
+			Status:    lastStatus,
+			Hash:      hash,
+			ElapsedMs: lastElapsedMs,
+		}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TimedResultSize))
+	}
+	return lastStatus
+}
+
+// RunTaskV2 implements run_task_v2
+func RunTaskV2(paramsPtr, resultPtr uintptr) uint32 {
+	hash := RunTask(paramsPtr)
+	if resultPtr != 0 {
+		common.TaskResult{Status: lastStatus, Hash: hash}.Put(common.Memory(unsafe.Pointer(resultPtr), common.TaskResultSize))
+	}
+	return lastStatus
+}
+
+// RunTaskPacked implements run_task_packed
+func RunTaskPacked(paramsPtr uintptr) uint64 {
+	hash := RunTask(paramsPtr)
+	return common.PackResult(lastStatus, hash)
+}
+
+// ValidateParams implements validate_params
+func ValidateParams(paramsPtr uintptr) uint32 {
+	lastStatus = common.StatusOK
+	common.ClearLastError()
+
+	if _, _, status, message := prepareParams(paramsPtr); status != common.StatusOK {
+		fail(status, message)
+	}
+	return lastStatus
+}
+
+// SelfTest implements self_test
+func SelfTest() uint32 {
+	return common.SelfTest(selfTestVectors, func(params *MandelbrotParams, result *common.TaskResult) uint32 {
+		return RunTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+	})
+}
+
+// RunTask implements run_task
+func RunTask(paramsPtr uintptr) (hash uint32) {
+	defer func() { publishResult(hash) }()
+	defer common.RecoverPanic(&lastStatus)
+	lastScaleFactor = 1
+	lastWorkMetrics = common.WorkMetrics{}
+	lastStatus = common.StatusOK
+	lastElapsedMs = 0
+	common.ClearLastError()
+	common.ClearPanic()
+	common.ClearCancel()
+	common.ResetCheckpoints(uint32(len(StageNames)))
+	common.ResetOutput(outputElementSize)
+
+	params, scaleFactor, status, message := prepareParams(paramsPtr)
+	if status != common.StatusOK {
+		return fail(status, message)
+	}
+	lastScaleFactor = scaleFactor
+	common.Log(common.LevelDebug, "run_task: parameters accepted")
+	if common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageInput, hashInput(&params))
+	}
+
+	// Warm-up runs stabilize allocator state and are discarded
+	for i := uint32(0); i < params.WarmupIterations; i++ {
+		computeMandelbrot(&params)
+	}
+
+	common.SettleHeap()
+	common.StartAllocCount()
+	start := common.NowMs()
+	hash = computeMandelbrot(&params)
+	lastElapsedMs = common.NowMs() - start
+	common.StopAllocCount()
+	if lastStatus == common.StatusOK && common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageOutput, hash)
+	}
+	return hash
+}
+
+// publishResult fills the get_result_ptr block from the run that just ended,
+// panicked runs included. The 64-bit hash is the one run_task64 returns.
+func publishResult(hash uint32) {
+	result := common.Result{
+		Status:    lastStatus,
+		Hash:      hash,
+		ElapsedMs: lastElapsedMs,
+		Metrics:   lastWorkMetrics,
+	}
+	if wideHash {
+		result.Flags |= common.ResultHasHash64
+		result.Hash64 = uint64(hash)
+		if hasHash64 {
+			result.Hash64 = lastHash64
+		}
+	}
+	common.PublishResult(result)
+}
+
+//
+// Parameter Validation
+//
+
+// prepareParams reads, resolves, validates and calibrates the parameters at
+// paramsPtr, returning the workload run_task executes or the reason it would
+// be rejected. Its only side effect is the error code of a rejection, so
+// validate_params can share it.
+func prepareParams(paramsPtr uintptr) (MandelbrotParams, uint32, uint32, string) {
+	if paramsPtr == 0 {
+		status, message := common.Reject(common.ErrNullParams, "null params pointer")
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	hostParams, status, message := common.ReadParams[MandelbrotParams](unsafe.Pointer(paramsPtr), paramFields)
+	if status != common.StatusOK {
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	params, ok := resolveScale(hostParams)
+	if !ok {
+		status, message := common.Reject(common.ErrUnknownScale, "unknown scale tier")
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	if status, message := parameterStatus(&params); status != common.StatusOK {
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	params, scaleFactor := calibrateWorkload(params)
+	params = applyProfile(params)
+
+	totalPixels := params.Width * params.Height
+	if totalPixels > maxTotalPixels {
+		status, message := common.Reject(common.ErrTooLarge, "calibrated image exceeds the maximum total pixels")
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	if status, message := common.CheckReservation(workingSet(&params)); status != common.StatusOK {
+		return MandelbrotParams{}, 1, status, message
+	}
+	scratch := workingSet(&params)
+	if common.ScratchAllocator(params.Allocator) != common.AllocatorArena && uint32(cap(iterationBuffer)) >= totalPixels {
+		scratch = 0 // The kept iteration buffer is already in memory
+	}
+	if status, message := common.CheckMemoryBudget(scratch, 0); status != common.StatusOK {
+		return MandelbrotParams{}, 1, status, message
+	}
+
+	return params, scaleFactor, common.StatusOK, ""
+}
+
+// resolveScale replaces the workload dimensions with the preset for the
+// requested scale tier. The host-owned params are left untouched.
+func resolveScale(params MandelbrotParams) (MandelbrotParams, bool) {
+	if params.Scale == common.ScaleCustom {
+		return params, true
+	}
+
+	if params.Scale > common.ScaleLarge {
+		return params, false
+	}
+
+	preset := scalePresets[params.Scale]
+	params.Width = preset.width
+	params.Height = preset.height
+	params.MaxIter = preset.maxIter
+
+	return params, true
+}
+
+func validateParameters(params *MandelbrotParams) bool {
+	status, _ := parameterStatus(params)
+	return status == common.StatusOK
+}
+
+// parameterStatus classifies parameters as valid, invalid or over the limits,
+// with a shared error code and a message naming the offending field
+func parameterStatus(params *MandelbrotParams) (uint32, string) {
+	var v common.Validator
+
+	// Check for reasonable image dimensions
+	v.NonZero(uint64(min(params.Width, params.Height)), "width and height must be non-zero")
+	v.AtMost(uint64(max(params.Width, params.Height)), maxImageDimension, "width or height exceeds the maximum image dimension")
+
+	// Check for a finite view with a positive scale factor
+	v.Finite("center and scale factor must be finite", params.CenterReal, params.CenterImag, params.ScaleFactor)
+	v.Positive(params.ScaleFactor, "scale factor must be positive")
+
+	// Profile, warm-ups, verification, allocator, hash and generator; the
+	// generator is accepted for a uniform params ABI
+	v.Options(common.Options{
+		Profile:          params.Profile,
+		WarmupIterations: params.WarmupIterations,
+		Verification:     params.Verification,
+		Allocator:        params.Allocator,
+		HashAlgorithm:    params.HashAlgorithm,
+		Generator:        params.Generator,
+	})
+	return v.Result()
+}
+
+// calibrateWorkload doubles the image width and height until the pixel
+// iteration budget reaches TargetWork or the image limits are hit, returning
+// the adjusted parameters and the linear scale factor that was applied.
+func calibrateWorkload(params MandelbrotParams) (MandelbrotParams, uint32) {
+	factor := uint32(1)
+	if params.TargetWork == 0 {
+		return params, factor
+	}
+
+	target := uint64(params.TargetWork) * common.WorkUnitsPerTarget
+	for uint64(params.Width)*uint64(params.Height)*uint64(params.MaxIter) < target {
+		width, height := params.Width*2, params.Height*2
+		if width > maxImageDimension || height > maxImageDimension ||
+			uint64(width)*uint64(height) > maxTotalPixels {
+			break
+		}
+
+		params.Width, params.Height = width, height
+		factor *= 2
+	}
+
+	return params, factor
+}
+
+// applyProfile trades image size against iteration depth so that the compute
+// profile keeps its iteration buffer cache-resident while the memory profile
+// is dominated by writing a large buffer. Expects validated parameters.
+func applyProfile(params MandelbrotParams) MandelbrotParams {
+	switch params.Profile {
+	case common.ProfileCompute:
+		params.Width = max(params.Width/computeProfileShrink, 1)
+		params.Height = max(params.Height/computeProfileShrink, 1)
+		maxIter := uint64(params.MaxIter) * computeProfileShrink * computeProfileShrink
+		params.MaxIter = uint32(min(maxIter, math.MaxUint32))
+	case common.ProfileMemory:
+		params.Width = min(params.Width*memoryProfileGrow, maxImageDimension)
+		params.Height = min(params.Height*memoryProfileGrow, maxImageDimension)
+		params.MaxIter = max(params.MaxIter/(memoryProfileGrow*memoryProfileGrow), 1)
+	}
+
+	return params
+}
+
+// fail records a failed run's status and message and returns the legacy
+// run_task error value
+func fail(status uint32, message string) uint32 {
+	lastStatus = status
+	common.SetLastError(message)
+	common.Log(common.LevelWarn, message)
+	return 0
+}
+
+//
+// Mandelbrot Computation
+//
+
+// computeMandelbrot renders the iteration-count image for validated
+// parameters and returns its hash (or checksum, per verification level)
+func computeMandelbrot(params *MandelbrotParams) uint32 {
+	common.ResetOutput(outputElementSize) // Each run, warm-ups included, starts its output afresh
+	totalPixels := params.Width * params.Height
+	var iterationCounts []uint32
+	if common.ScratchAllocator(params.Allocator) == common.AllocatorArena {
+		scratchArena.Reset()
+		iterationCounts = scratchArena.Uint32s(int(totalPixels))
+	} else {
+		// Every pixel is written below, so the reused buffer needs no clearing
+		if uint32(cap(iterationBuffer)) < totalPixels {
+			iterationBuffer = nil // Let the GC reclaim the old buffer before the new one
+			iterationBuffer = make([]uint32, totalPixels)
+		}
+		iterationCounts = iterationBuffer[:totalPixels]
+	}
+
+	// Progress counts max_iter per pixel, the bound on its escape loop
+	rowWork := uint64(params.Width) * uint64(params.MaxIter)
+	progress := common.NewProgress(rowWork * uint64(params.Height))
+	for y := uint32(0); y < params.Height; y++ {
+		if useSIMD {
+			renderRowPairs(params, y, iterationCounts[y*params.Width:(y+1)*params.Width])
+		} else {
+			for x := uint32(0); x < params.Width; x++ {
+				// Map pixel to complex plane
+				xNorm := float64(x)/float64(params.Width) - 0.5
+				yNorm := float64(y)/float64(params.Height) - 0.5
+
+				cReal := params.CenterReal + xNorm*params.ScaleFactor
+				cImag := params.CenterImag + yNorm*params.ScaleFactor
+
+				iterations := mandelbrotPixel(cReal, cImag, params.MaxIter)
+				iterationCounts[y*params.Width+x] = iterations
+			}
+		}
+		if !progress.Advance(rowWork) {
+			return fail(common.StatusCancelled, "run cancelled by the host")
+		}
+	}
+
+	if common.CheckpointsEnabled() {
+		common.RecordCheckpoint(stageIterations, fnv1aHashU32(iterationCounts))
+	}
+	if common.OutputEnabled() {
+		common.AppendOutputUint32s(iterationCounts)
+	}
+
+	// Every pixel is written once to the iteration buffer
+	lastWorkMetrics = common.WorkMetrics{
+		ElementsProcessed: uint64(totalPixels),
+		BytesTouched:      uint64(totalPixels) * 4,
+	}
+
+	switch params.Verification {
+	case common.VerifyNone:
+		return sumU32(iterationCounts)
+	case common.VerifyFull:
+		// There is no round trip to replay, so full verification adds a range check
+		if !iterationsInRange(iterationCounts, params.MaxIter) {
+			return fail(common.StatusVerificationFailed, "iteration count outside [0, max_iter]")
+		}
+	}
+
+	if wideHash {
+		lastHash64, hasHash64 = common.Hash64Uint32s(common.FNV64OffsetBasis, iterationCounts), true
+	}
+	if params.HashAlgorithm == common.HashXXHash32 {
+		return common.XXHash32Uint32s(0, iterationCounts)
+	}
+	return fnv1aHashU32(iterationCounts)
+}
+
+// workingSet returns the scratch bytes a run takes from the arena: the
+// iteration buffer, the only allocation computeMandelbrot makes
+func workingSet(params *MandelbrotParams) int {
+	return common.ArenaSize(int(params.Width*params.Height) * 4)
+}
+
+// hashInput hashes what the image is rendered from, the input stage: width,
+// height and max_iter, then the bits of center_real, center_imag and
+// scale_factor, each little-endian
+func hashInput(params *MandelbrotParams) uint32 {
+	hash := common.HashUint32s(common.FNVOffsetBasis, []uint32{params.Width, params.Height, params.MaxIter})
+	for _, value := range [...]float64{params.CenterReal, params.CenterImag, params.ScaleFactor} {
+		hash = common.HashUint64(hash, math.Float64bits(value))
+	}
+	return hash
+}
+
+// iterationsInRange reports whether every iteration count is at most maxIter
+func iterationsInRange(data []uint32, maxIter uint32) bool {
+	for i := 0; i < len(data); i++ {
+		if data[i] > maxIter {
+			return false
+		}
+	}
+	return true
+}
+
+func mandelbrotPixel(cReal, cImag float64, maxIter uint32) uint32 {
+	var zReal, zImag float64 = 0.0, 0.0
+	var iterations uint32 = 0
+
+	for iterations < maxIter {
+		// Check divergence FIRST (must match original order for cross-implementation compatibility)
+		if complexMagnitudeSquared(zReal, zImag) > divergenceThreshold {
+			break
+		}
+
+		// NOW compute squares and reuse them (optimization that doesn't affect computation order)
+		zRealSq := zReal * zReal
+		zImagSq := zImag * zImag
+
+		// Calculate z² + c using cached squares
+		zRealNew := zRealSq - zImagSq + cReal
+		zImagNew := 2.0*zReal*zImag + cImag
+
+		zReal = zRealNew
+		zImag = zImagNew
+		iterations++
+	}
+
+	return iterations
+}
+
+// renderRowPairs renders image row y into row two pixels at a time, the f64
+// lanes of a simd128 register. Pixel coordinates are mapped as in the scalar
+// path, and an odd last pixel falls back to mandelbrotPixel.
+func renderRowPairs(params *MandelbrotParams, y uint32, row []uint32) {
+	yNorm := float64(y)/float64(params.Height) - 0.5
+	cImag := params.CenterImag + yNorm*params.ScaleFactor
+
+	x := uint32(0)
+	for ; x+1 < params.Width; x += 2 {
+		var cReal [2]float64
+		for lane := range cReal {
+			xNorm := float64(x+uint32(lane))/float64(params.Width) - 0.5
+			cReal[lane] = params.CenterReal + xNorm*params.ScaleFactor
+		}
+		row[x], row[x+1] = mandelbrotPair(cReal, cImag, params.MaxIter)
+	}
+	if x < params.Width {
+		xNorm := float64(x)/float64(params.Width) - 0.5
+		row[x] = mandelbrotPixel(params.CenterReal+xNorm*params.ScaleFactor, cImag, params.MaxIter)
+	}
+}
+
+// mandelbrotPair iterates two pixels of one row in lockstep, each lane with
+// mandelbrotPixel's operations in its order, so the counts match the scalar
+// path bit for bit. An escaped lane keeps its z, which keeps it escaped, and
+// stops counting; the pair stops once both lanes have escaped.
+func mandelbrotPair(cReal [2]float64, cImag float64, maxIter uint32) (uint32, uint32) {
+	var zReal, zImag [2]float64
+	var iterations [2]uint32
+
+	for n := uint32(0); n < maxIter; n++ {
+		var inside [2]bool
+		for lane := range inside {
+			inside[lane] = !(complexMagnitudeSquared(zReal[lane], zImag[lane]) > divergenceThreshold)
+		}
+		if !inside[0] && !inside[1] {
+			break
+		}
+
+		for lane := range inside {
+			zRealSq := zReal[lane] * zReal[lane]
+			zImagSq := zImag[lane] * zImag[lane]
+			zRealNew := zRealSq - zImagSq + cReal[lane]
+			zImagNew := 2.0*zReal[lane]*zImag[lane] + cImag
+			if inside[lane] {
+				zReal[lane], zImag[lane] = zRealNew, zImagNew
+				iterations[lane]++
+			}
+		}
+	}
+
+	return iterations[0], iterations[1]
+}
+
+func complexMagnitudeSquared(real, imag float64) float64 {
+	return real*real + imag*imag
+}
+
+//
+// Hash Computation
+//
+
+func fnv1aHashU32(data []uint32) uint32 {
+	return common.HashUint32s(common.FNVOffsetBasis, data)
+}
+
+// sumU32 is the unverified checksum: a wrapping sum of the values
+func sumU32(data []uint32) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i++ {
+		sum += data[i]
+	}
+	return sum
+}
+
+//
+// Data Structures
+//
+
+// MandelbrotParams represents parameters for Mandelbrot set computation
+type MandelbrotParams struct {
+	Width            uint32
+	Height           uint32
+	MaxIter          uint32
+	CenterReal       float64
+	CenterImag       float64
+	ScaleFactor      float64
+	Scale            uint32 // Workload scale tier (0 = custom dimensions)
+	Profile          uint32 // Workload profile (0 = default, 1 = compute, 2 = memory)
+	TargetWork       uint32 // Self-calibration target in thousands of pixel iterations (0 = off)
+	WarmupIterations uint32 // Discarded workload runs before the hashed run
+	Verification     uint32 // Verification level (0 = hash, 1 = none, 2 = full)
+	Allocator        uint32 // Scratch allocator (0 = GC heap, 1 = arena)
+	HashAlgorithm    uint32 // Verification hash (0 = FNV-1a, 1 = xxHash32)
+	Generator        uint32 // Random data generator (0 = LCG, 1 = PCG32); the image draws no random data
+}
+
+// DefaultParams are the params of a run that sets none: the micro scale of
+// configs/bench-quick.yaml with the browser harness's view
+var DefaultParams = MandelbrotParams{
+	Width: 64, Height: 64, MaxIter: 100,
+	CenterReal: -0.743643887037, CenterImag: 0.131825904205, ScaleFactor: 3.0,
+}
+
+// ParamFields describes every MandelbrotParams field in declaration order
+func ParamFields() []common.ParamField {
+	var p MandelbrotParams
+	return []common.ParamField{
+		{Name: "width", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Width)},
+		{Name: "height", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Height)},
+		{Name: "max_iter", Type: common.FieldU32, Offset: unsafe.Offsetof(p.MaxIter)},
+		{Name: "center_real", Type: common.FieldF64, Offset: unsafe.Offsetof(p.CenterReal)},
+		{Name: "center_imag", Type: common.FieldF64, Offset: unsafe.Offsetof(p.CenterImag)},
+		{Name: "scale_factor", Type: common.FieldF64, Offset: unsafe.Offsetof(p.ScaleFactor)},
+		{Name: "scale", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Scale)},
+		{Name: "profile", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Profile)},
+		{Name: "target_work", Type: common.FieldU32, Offset: unsafe.Offsetof(p.TargetWork)},
+		{Name: "warmup_iterations", Type: common.FieldU32, Offset: unsafe.Offsetof(p.WarmupIterations)},
+		{Name: "verification", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Verification)},
+		{Name: "allocator", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Allocator)},
+		{Name: "hash_algorithm", Type: common.FieldU32, Offset: unsafe.Offsetof(p.HashAlgorithm)},
+		{Name: "generator", Type: common.FieldU32, Offset: unsafe.Offsetof(p.Generator)},
+	}
+}
+
+// layoutFingerprint hashes the (offset, size) of every MandelbrotParams field in
+// declaration order followed by the struct size, letting the harness detect
+// layout drift between implementations before writing parameters
+func layoutFingerprint() uint32 {
+	return common.FieldsFingerprint(ParamFields(), unsafe.Sizeof(MandelbrotParams{}))
+}
+
+// Limits lists the largest accepted value of each bounded parameter. The
+// common fields come first; WordCount counts the u32 fields after it so a
+// host can read the task-specific tail without knowing the task.
+type Limits struct {
+	WordCount           uint32
+	MaxAllocationSize   uint32
+	MaxWarmupIterations uint32
+	MaxScale            uint32
+	MaxProfile          uint32
+	MaxVerification     uint32
+	MaxAllocator        uint32
+	MaxHashAlgorithm    uint32
+	MaxGenerator        uint32
+	MaxImageDimension   uint32
+	MaxTotalPixels      uint32
+}
+
+func parseParams(ptr uintptr) *MandelbrotParams {
+	return (*MandelbrotParams)(unsafe.Pointer(ptr))
+}
@@ -0,0 +1,1003 @@
+package mandelbrot
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+func TestMandelbrotKnownPoints(t *testing.T) {
+	// Point (0,0) should be in the Mandelbrot set (high iteration count)
+	iterationsOrigin := mandelbrotPixel(0.0, 0.0, 1000)
+	if iterationsOrigin != 1000 {
+		t.Errorf("Origin should reach max iterations, got %d", iterationsOrigin)
+	}
+
+	// Point (2,2) should diverge quickly (low iteration count)
+	iterationsOutside := mandelbrotPixel(2.0, 2.0, 1000)
+	if iterationsOutside >= 10 {
+		t.Errorf("Point (2,2) should diverge quickly, got %d iterations", iterationsOutside)
+	}
+
+	// Point (-0.75, 0) should be in the set (on the main bulb boundary)
+	iterationsBoundary := mandelbrotPixel(-0.75, 0.0, 1000)
+	if iterationsBoundary <= 100 {
+		t.Errorf("Point (-0.75, 0) should have high iteration count, got %d", iterationsBoundary)
+	}
+}
+
+func TestHashConsistency(t *testing.T) {
+	data1 := []uint32{1, 2, 3, 4, 5}
+	data2 := []uint32{1, 2, 3, 4, 5}
+	data3 := []uint32{1, 2, 3, 4, 6}
+
+	hash1 := fnv1aHashU32(data1)
+	hash2 := fnv1aHashU32(data2)
+	hash3 := fnv1aHashU32(data3)
+
+	if hash1 != hash2 {
+		t.Errorf("Same input should produce same hash: %d != %d", hash1, hash2)
+	}
+
+	if hash1 == hash3 {
+		t.Errorf("Different input should produce different hash: %d == %d", hash1, hash3)
+	}
+}
+
+func TestComplexMagnitude(t *testing.T) {
+	tests := []struct {
+		real, imag, expected float64
+	}{
+		{3.0, 4.0, 25.0},
+		{0.0, 0.0, 0.0},
+		{1.0, 1.0, 2.0},
+		{5.0, 0.0, 25.0},
+		{0.0, 3.0, 9.0},
+	}
+
+	for _, test := range tests {
+		result := complexMagnitudeSquared(test.real, test.imag)
+		if result != test.expected {
+			t.Errorf("complexMagnitudeSquared(%f, %f) = %f, expected %f",
+				test.real, test.imag, result, test.expected)
+		}
+	}
+}
+
+func TestMemoryAllocation(t *testing.T) {
+	ptr := Alloc(100)
+	if ptr == 0 {
+		t.Error("Allocation should succeed for non-zero bytes")
+	}
+
+	nullPtr := Alloc(0)
+	if nullPtr != 0 {
+		t.Error("Zero-byte allocation should return null pointer")
+	}
+}
+
+func TestFnv1aHashKnownValues(t *testing.T) {
+	// Test with known values to verify FNV-1a implementation
+	empty := []uint32{}
+	hashEmpty := fnv1aHashU32(empty)
+	if hashEmpty != 2166136261 {
+		t.Errorf("Empty hash should equal offset basis, got %d", hashEmpty)
+	}
+
+	single := []uint32{0}
+	hashSingle := fnv1aHashU32(single)
+	if hashSingle == hashEmpty {
+		t.Error("Single zero should produce different hash from empty")
+	}
+}
+
+func TestParameterParsing(t *testing.T) {
+	// Create test parameters
+	params := MandelbrotParams{
+		Width:       800,
+		Height:      600,
+		MaxIter:     1000,
+		CenterReal:  -0.5,
+		CenterImag:  0.0,
+		ScaleFactor: 2.0,
+	}
+
+	// Get pointer to params
+	ptr := uintptr(unsafe.Pointer(&params))
+
+	// Parse back
+	parsed := parseParams(ptr)
+
+	if parsed.Width != params.Width {
+		t.Errorf("Width mismatch: %d != %d", parsed.Width, params.Width)
+	}
+	if parsed.Height != params.Height {
+		t.Errorf("Height mismatch: %d != %d", parsed.Height, params.Height)
+	}
+	if parsed.MaxIter != params.MaxIter {
+		t.Errorf("MaxIter mismatch: %d != %d", parsed.MaxIter, params.MaxIter)
+	}
+	if parsed.CenterReal != params.CenterReal {
+		t.Errorf("CenterReal mismatch: %f != %f", parsed.CenterReal, params.CenterReal)
+	}
+	if parsed.CenterImag != params.CenterImag {
+		t.Errorf("CenterImag mismatch: %f != %f", parsed.CenterImag, params.CenterImag)
+	}
+	if parsed.ScaleFactor != params.ScaleFactor {
+		t.Errorf("ScaleFactor mismatch: %f != %f", parsed.ScaleFactor, params.ScaleFactor)
+	}
+}
+
+func TestRunTaskComplete(t *testing.T) {
+	// Test a small Mandelbrot computation
+	params := MandelbrotParams{
+		Width:       4,
+		Height:      4,
+		MaxIter:     100,
+		CenterReal:  0.0,
+		CenterImag:  0.0,
+		ScaleFactor: 4.0,
+	}
+
+	ptr := uintptr(unsafe.Pointer(&params))
+	hash := RunTask(ptr)
+
+	// Should return a non-zero hash for valid computation
+	if hash == 0 {
+		t.Error("runTask should return non-zero hash for valid parameters")
+	}
+
+	// Test null pointer handling
+	nullHash := RunTask(0)
+	if nullHash != 0 {
+		t.Error("runTask should return 0 for null pointer")
+	}
+}
+
+func TestInitWasm(t *testing.T) {
+	// Test that init doesn't panic
+	Init(12345)
+	Init(0)
+	Init(4294967295) // Max uint32
+	Init64(1 << 40)
+}
+
+func TestResolveScale(t *testing.T) {
+	custom := MandelbrotParams{Width: 7, Height: 9, MaxIter: 11, ScaleFactor: 2.0}
+	resolved, ok := resolveScale(custom)
+	if !ok || resolved != custom {
+		t.Errorf("Custom scale should keep raw dimensions, got %+v", resolved)
+	}
+
+	small := MandelbrotParams{Width: 7, Height: 9, MaxIter: 11, ScaleFactor: 2.0, Scale: common.ScaleSmall}
+	resolved, ok = resolveScale(small)
+	if !ok {
+		t.Fatal("Small scale should resolve")
+	}
+	if resolved.Width != 256 || resolved.Height != 256 || resolved.MaxIter != 500 {
+		t.Errorf("Small scale resolved to %dx%d/%d", resolved.Width, resolved.Height, resolved.MaxIter)
+	}
+	if resolved.ScaleFactor != small.ScaleFactor {
+		t.Error("Scale presets should not override the viewport")
+	}
+
+	if _, ok := resolveScale(MandelbrotParams{Scale: common.ScaleLarge + 1}); ok {
+		t.Error("Unknown scale tier should be rejected")
+	}
+}
+
+func TestRunTaskScalePreset(t *testing.T) {
+	preset := MandelbrotParams{Scale: common.ScaleMicro, ScaleFactor: 3.0}
+	explicit := MandelbrotParams{Width: 64, Height: 64, MaxIter: 100, ScaleFactor: 3.0}
+
+	presetHash := RunTask(uintptr(unsafe.Pointer(&preset)))
+	explicitHash := RunTask(uintptr(unsafe.Pointer(&explicit)))
+
+	if presetHash == 0 || presetHash != explicitHash {
+		t.Errorf("Micro preset should match explicit 64x64/100: %d != %d", presetHash, explicitHash)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	base := MandelbrotParams{Width: 256, Height: 128, MaxIter: 500, ScaleFactor: 2.0}
+
+	if got := applyProfile(base); got != base {
+		t.Errorf("Default profile should keep dimensions, got %+v", got)
+	}
+
+	compute := base
+	compute.Profile = common.ProfileCompute
+	got := applyProfile(compute)
+	if got.Width != 32 || got.Height != 16 || got.MaxIter != 32000 {
+		t.Errorf("Compute profile resolved to %dx%d/%d", got.Width, got.Height, got.MaxIter)
+	}
+
+	memory := base
+	memory.Profile = common.ProfileMemory
+	got = applyProfile(memory)
+	if got.Width != 1024 || got.Height != 512 || got.MaxIter != 31 {
+		t.Errorf("Memory profile resolved to %dx%d/%d", got.Width, got.Height, got.MaxIter)
+	}
+
+	// Extremes clamp instead of overflowing or collapsing to zero
+	extreme := MandelbrotParams{Width: 4, Height: maxImageDimension, MaxIter: math.MaxUint32, Profile: common.ProfileCompute}
+	got = applyProfile(extreme)
+	if got.Width != 1 || got.MaxIter != math.MaxUint32 {
+		t.Errorf("Compute profile should clamp, got %dx%d/%d", got.Width, got.Height, got.MaxIter)
+	}
+	extreme.Profile = common.ProfileMemory
+	extreme.MaxIter = 1
+	got = applyProfile(extreme)
+	if got.Height != maxImageDimension || got.MaxIter != 1 {
+		t.Errorf("Memory profile should clamp, got %dx%d/%d", got.Width, got.Height, got.MaxIter)
+	}
+}
+
+func TestRunTaskProfiles(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		params := MandelbrotParams{Width: 16, Height: 16, MaxIter: 64, ScaleFactor: 3.0, Profile: profile}
+		if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash == 0 {
+			t.Errorf("Profile %d should produce a hash", profile)
+		}
+	}
+
+	invalid := MandelbrotParams{Width: 16, Height: 16, MaxIter: 64, ScaleFactor: 3.0, Profile: common.ProfileMemory + 1}
+	if hash := RunTask(uintptr(unsafe.Pointer(&invalid))); hash != 0 {
+		t.Error("Unknown profile should be rejected")
+	}
+}
+
+func TestCalibrateWorkload(t *testing.T) {
+	params := MandelbrotParams{Width: 10, Height: 10, MaxIter: 10, ScaleFactor: 2.0}
+
+	if got, factor := calibrateWorkload(params); got != params || factor != 1 {
+		t.Errorf("Calibration should be disabled without a target, got %+v factor %d", got, factor)
+	}
+
+	// 10×10×10 = 1000 pixel iterations; a 10k target needs one doubling (4000) then another (16000)
+	params.TargetWork = 10
+	got, factor := calibrateWorkload(params)
+	if factor != 4 || got.Width != 40 || got.Height != 40 || got.MaxIter != 10 {
+		t.Errorf("Calibration chose %dx%d factor %d, expected 40x40 factor 4", got.Width, got.Height, factor)
+	}
+
+	// Doubling stops at the image dimension limit
+	params.TargetWork = math.MaxUint32
+	got, factor = calibrateWorkload(params)
+	if got.Width > maxImageDimension || got.Width*2 <= maxImageDimension || factor != got.Width/10 {
+		t.Errorf("Calibration should stop at the dimension limit, got %dx%d factor %d", got.Width, got.Height, factor)
+	}
+}
+
+func TestRunTaskReportsScaleFactor(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 8, MaxIter: 16, ScaleFactor: 3.0, TargetWork: 4}
+	scaled := MandelbrotParams{Width: 16, Height: 16, MaxIter: 16, ScaleFactor: 3.0}
+
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if factor := GetScaleFactor(); factor != 2 {
+		t.Errorf("Expected scale factor 2, got %d", factor)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&scaled))); hash != expected {
+		t.Errorf("Calibrated run should match explicit 16x16: %d != %d", hash, expected)
+	}
+	if factor := GetScaleFactor(); factor != 1 {
+		t.Errorf("Uncalibrated run should report factor 1, got %d", factor)
+	}
+}
+
+func TestRunTaskWarmupIterations(t *testing.T) {
+	cold := MandelbrotParams{Width: 16, Height: 16, MaxIter: 50, ScaleFactor: 3.0}
+	warm := cold
+	warm.WarmupIterations = 3
+
+	coldHash := RunTask(uintptr(unsafe.Pointer(&cold)))
+	if warmHash := RunTask(uintptr(unsafe.Pointer(&warm))); warmHash != coldHash {
+		t.Errorf("Warm-up iterations should not change the hash: %d != %d", warmHash, coldHash)
+	}
+
+	warm.WarmupIterations = common.MaxWarmupIterations + 1
+	if hash := RunTask(uintptr(unsafe.Pointer(&warm))); hash != 0 {
+		t.Error("Warm-up iterations above the limit should be rejected")
+	}
+}
+
+func TestGetWorkMetrics(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 4, MaxIter: 10, ScaleFactor: 3.0, WarmupIterations: 2}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+
+	metrics := (*common.WorkMetrics)(unsafe.Pointer(GetWorkMetrics()))
+	if metrics.ElementsProcessed != 32 || metrics.BytesTouched != 128 {
+		t.Errorf("Unexpected work metrics %+v", *metrics)
+	}
+
+	RunTask(0)
+	if metrics.ElementsProcessed != 0 || metrics.BytesTouched != 0 {
+		t.Errorf("Failed runs should clear work metrics, got %+v", *metrics)
+	}
+}
+
+func TestVerificationLevels(t *testing.T) {
+	params := MandelbrotParams{Width: 12, Height: 12, MaxIter: 40, ScaleFactor: 3.0}
+	hashed := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	params.Verification = common.VerifyFull
+	if full := RunTask(uintptr(unsafe.Pointer(&params))); full != hashed {
+		t.Errorf("Full verification should return the same hash: %d != %d", full, hashed)
+	}
+
+	params.Verification = common.VerifyNone
+	expected := uint32(0)
+	for y := uint32(0); y < params.Height; y++ {
+		for x := uint32(0); x < params.Width; x++ {
+			cReal := params.CenterReal + (float64(x)/float64(params.Width)-0.5)*params.ScaleFactor
+			cImag := params.CenterImag + (float64(y)/float64(params.Height)-0.5)*params.ScaleFactor
+			expected += mandelbrotPixel(cReal, cImag, params.MaxIter)
+		}
+	}
+	if sum := RunTask(uintptr(unsafe.Pointer(&params))); sum != expected {
+		t.Errorf("Unverified run should return the iteration sum %d, got %d", expected, sum)
+	}
+
+	params.Verification = common.VerifyFull + 1
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown verification level should be rejected")
+	}
+
+	if !iterationsInRange([]uint32{0, 5, 10}, 10) || iterationsInRange([]uint32{0, 11}, 10) {
+		t.Error("iterationsInRange should bound counts by maxIter")
+	}
+}
+
+func TestParamsFingerprint(t *testing.T) {
+	// Documented wasm32 layout: three u32, padding, three f64 at 16/24/32,
+	// eight u32 from offset 40, 72 bytes
+	layout := []uint32{
+		0, 4, 4, 4, 8, 4,
+		16, 8, 24, 8, 32, 8,
+		40, 4, 44, 4, 48, 4, 52, 4, 56, 4, 60, 4, 64, 4, 68, 4,
+		72,
+	}
+	if got, want := ParamsFingerprint(), fnv1aHashU32(layout); got != want {
+		t.Errorf("Params fingerprint %d does not match the documented layout %d", got, want)
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	limits := (*Limits)(unsafe.Pointer(GetLimits()))
+
+	if limits.WordCount != 10 {
+		t.Errorf("Expected 10 limit words after WordCount, got %d", limits.WordCount)
+	}
+
+	// The reported bounds are inclusive: the limit passes, one past it fails
+	params := MandelbrotParams{Width: limits.MaxImageDimension, Height: 1, MaxIter: 1, ScaleFactor: 1.0}
+	if !validateParameters(&params) {
+		t.Error("Width at MaxImageDimension should be accepted")
+	}
+	params.Width++
+	if validateParameters(&params) {
+		t.Error("Width beyond MaxImageDimension should be rejected")
+	}
+
+	// The host generator is only available with a random source
+	common.SetHostRandom(func() uint32 { return 0 })
+	defer common.SetHostRandom(nil)
+	params = MandelbrotParams{Width: 8, Height: 8, MaxIter: 1, ScaleFactor: 1.0,
+		Profile: limits.MaxProfile, WarmupIterations: limits.MaxWarmupIterations, Verification: limits.MaxVerification,
+		Allocator: limits.MaxAllocator, HashAlgorithm: limits.MaxHashAlgorithm, Generator: limits.MaxGenerator}
+	if !validateParameters(&params) {
+		t.Error("Parameters at their reported limits should be accepted")
+	}
+
+	if _, ok := resolveScale(MandelbrotParams{Scale: limits.MaxScale}); !ok {
+		t.Error("MaxScale should be a valid tier")
+	}
+	if _, ok := resolveScale(MandelbrotParams{Scale: limits.MaxScale + 1}); ok {
+		t.Error("Scale beyond MaxScale should be rejected")
+	}
+
+	if Alloc(limits.MaxAllocationSize+1) != 0 {
+		t.Error("Allocation beyond MaxAllocationSize should fail")
+	}
+}
+
+func TestDeallocReleasesAllocation(t *testing.T) {
+	before, _ := common.LiveAllocations()
+
+	ptr := Alloc(64)
+	if ptr == 0 {
+		t.Fatal("Alloc(64) should succeed")
+	}
+	if live, _ := common.LiveAllocations(); live != before+1 {
+		t.Errorf("alloc should pin its buffer, %d live allocations (expected %d)", live, before+1)
+	}
+
+	Dealloc(ptr)
+	Dealloc(ptr) // Repeated frees are ignored
+	if live, _ := common.LiveAllocations(); live != before {
+		t.Errorf("dealloc should unpin the buffer, %d live allocations (expected %d)", live, before)
+	}
+}
+
+func TestArenaAllocator(t *testing.T) {
+	params := MandelbrotParams{Width: 20, Height: 10, MaxIter: 50, ScaleFactor: 3.0}
+	heapHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	params.Allocator = common.AllocatorArena
+	if arenaHash := RunTask(uintptr(unsafe.Pointer(&params))); arenaHash != heapHash {
+		t.Errorf("Arena allocation should not change the hash: %d != %d", arenaHash, heapHash)
+	}
+	if scratchArena.Used() != 20*10*4 {
+		t.Errorf("Arena should hold the iteration buffer, %d bytes used", scratchArena.Used())
+	}
+
+	// Repeated arena runs reuse the buffer instead of growing
+	capacity := scratchArena.Capacity()
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if scratchArena.Capacity() != capacity {
+		t.Error("Arena runs of the same size should reuse the backing buffer")
+	}
+
+	ResetArena()
+	if scratchArena.Used() != 0 {
+		t.Error("reset_arena should rewind the arena")
+	}
+
+	params.Allocator = common.AllocatorArena + 1
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown allocator should be rejected")
+	}
+}
+
+func TestIterationBufferReuse(t *testing.T) {
+	defer ResetIterationBuffer()
+	ResetIterationBuffer()
+
+	params := MandelbrotParams{Width: 20, Height: 10, MaxIter: 50, ScaleFactor: 3.0}
+	coldHash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if cap(iterationBuffer) != 20*10 {
+		t.Fatalf("A heap run should keep its iteration buffer, capacity %d", cap(iterationBuffer))
+	}
+
+	// A smaller image renders into the same buffer without clearing it, and
+	// the stale tail must not leak into its hash
+	small := MandelbrotParams{Width: 7, Height: 5, MaxIter: 50, ScaleFactor: 3.0}
+	ResetIterationBuffer()
+	smallHash := RunTask(uintptr(unsafe.Pointer(&small)))
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if reused := RunTask(uintptr(unsafe.Pointer(&small))); reused != smallHash {
+		t.Errorf("A reused buffer should not change the hash: %d != %d", reused, smallHash)
+	}
+	if warmHash := RunTask(uintptr(unsafe.Pointer(&params))); warmHash != coldHash {
+		t.Errorf("A reused buffer should not change the hash: %d != %d", warmHash, coldHash)
+	}
+
+	backing := &iterationBuffer[:1][0]
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if &iterationBuffer[:1][0] != backing {
+		t.Error("Runs of the same size should reuse the iteration buffer")
+	}
+
+	params.Width, params.Height = 40, 20
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if cap(iterationBuffer) != 40*20 {
+		t.Errorf("A larger image should grow the buffer, capacity %d", cap(iterationBuffer))
+	}
+
+	ResetIterationBuffer()
+	if iterationBuffer != nil {
+		t.Error("reset_iteration_buffer should drop the buffer")
+	}
+	params.Allocator = common.AllocatorArena
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if iterationBuffer != nil {
+		t.Error("Arena runs should not take the heap buffer")
+	}
+}
+
+func TestRunTaskV2Status(t *testing.T) {
+	params := MandelbrotParams{Width: 20, Height: 10, MaxIter: 50, ScaleFactor: 3.0}
+	// Module memory, as a host would pass it; a Go stack address would move as run_task grows the stack
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TaskResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TaskResult)(unsafe.Pointer(resultPtr))
+	status := RunTaskV2(uintptr(unsafe.Pointer(&params)), resultPtr)
+	if status != common.StatusOK || result.Status != common.StatusOK {
+		t.Fatalf("Valid run should report StatusOK, got %d (result %d)", status, result.Status)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_v2 hash %d should match run_task %d", result.Hash, expected)
+	}
+
+	tests := []struct {
+		name     string
+		params   MandelbrotParams
+		expected uint32
+	}{
+		{"zero width", MandelbrotParams{Height: 10, MaxIter: 50, ScaleFactor: 3.0}, common.StatusInvalidParams},
+		{"unknown scale", MandelbrotParams{Scale: common.ScaleLarge + 1}, common.StatusInvalidParams},
+		{"NaN center", MandelbrotParams{Width: 8, Height: 8, MaxIter: 10, CenterReal: math.NaN(), ScaleFactor: 3.0}, common.StatusInvalidParams},
+		{"oversized image", MandelbrotParams{Width: maxImageDimension + 1, Height: 8, MaxIter: 10, ScaleFactor: 3.0}, common.StatusOverflow},
+		{"too many warm-ups", MandelbrotParams{Width: 8, Height: 8, MaxIter: 10, ScaleFactor: 3.0, WarmupIterations: common.MaxWarmupIterations + 1}, common.StatusOverflow},
+	}
+	for _, tt := range tests {
+		*result = common.TaskResult{Hash: 1}
+		if status := RunTaskV2(uintptr(unsafe.Pointer(&tt.params)), resultPtr); status != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, status)
+		}
+		if result.Status != tt.expected || result.Hash != 0 {
+			t.Errorf("%s: result should be {%d, 0}, got %+v", tt.name, tt.expected, *result)
+		}
+	}
+
+	if status := RunTaskV2(0, resultPtr); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+	if status := RunTaskV2(uintptr(unsafe.Pointer(&params)), 0); status != common.StatusOK {
+		t.Errorf("Null result pointer should still report the status, got %d", status)
+	}
+}
+
+func TestLastErrorMessage(t *testing.T) {
+	params := MandelbrotParams{Width: maxImageDimension + 1, Height: 8, MaxIter: 10, ScaleFactor: 3.0}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Fatal("Invalid params should be rejected")
+	}
+	message := unsafe.String((*byte)(unsafe.Pointer(GetLastErrorPtr())), GetLastErrorLen())
+	if message != "width or height exceeds the maximum image dimension" {
+		t.Errorf("Unexpected error message %q", message)
+	}
+
+	params = MandelbrotParams{Width: 8, Height: 8, MaxIter: 10, ScaleFactor: 3.0}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if GetLastErrorLen() != 0 {
+		t.Errorf("A successful run should clear the error, got %q", common.LastError())
+	}
+}
+
+func TestGetTaskInfo(t *testing.T) {
+	ptr := GetTaskInfo()
+	length := *(*uint32)(unsafe.Pointer(ptr))
+	blob := unsafe.Slice((*byte)(unsafe.Pointer(ptr+4)), length)
+
+	var info struct {
+		Task       string `json:"task"`
+		Language   string `json:"language"`
+		ABIVersion uint32 `json:"abi_version"`
+		ParamsSize uint32 `json:"params_size"`
+		Params     []struct {
+			Name   string `json:"name"`
+			Type   string `json:"type"`
+			Offset uint32 `json:"offset"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(blob, &info); err != nil {
+		t.Fatalf("Task info is not valid JSON: %v\n%s", err, blob)
+	}
+
+	if info.Task != "mandelbrot" || info.Language != "tinygo" || info.ABIVersion != common.ABIVersion {
+		t.Errorf("Unexpected task info header: %+v", info)
+	}
+	if ABIVersion() != info.ABIVersion {
+		t.Errorf("abi_version() = %d, task info reports %d", ABIVersion(), info.ABIVersion)
+	}
+	if info.ParamsSize != 72 || len(info.Params) != 14 {
+		t.Fatalf("Expected 14 params in 72 bytes, got %d in %d", len(info.Params), info.ParamsSize)
+	}
+	if p := info.Params[3]; p.Name != "center_real" || p.Type != common.FieldF64 || p.Offset != 16 {
+		t.Errorf("Unexpected center_real descriptor: %+v", p)
+	}
+	if p := info.Params[11]; p.Name != "allocator" || p.Offset != 60 {
+		t.Errorf("Unexpected allocator descriptor: %+v", p)
+	}
+	if p := info.Params[12]; p.Name != "hash_algorithm" || p.Offset != 64 {
+		t.Errorf("Unexpected hash_algorithm descriptor: %+v", p)
+	}
+}
+
+func TestRunTaskTimed(t *testing.T) {
+	// Module memory, as a host would pass it
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TimedResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TimedResult)(unsafe.Pointer(resultPtr))
+
+	params := MandelbrotParams{Width: 64, Height: 64, MaxIter: 200, ScaleFactor: 3.0}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status != common.StatusOK {
+		t.Fatalf("Valid run should report StatusOK, got %d", status)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_timed hash %d should match run_task %d", result.Hash, expected)
+	}
+	if result.ElapsedMs <= 0 {
+		t.Errorf("Measured run should take time, got %f ms", result.ElapsedMs)
+	}
+
+	params = MandelbrotParams{}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status == common.StatusOK {
+		t.Error("Invalid params should be rejected")
+	}
+	if result.ElapsedMs != 0 {
+		t.Errorf("Rejected run should not report a duration, got %f ms", result.ElapsedMs)
+	}
+}
+
+func TestGetMemoryStats(t *testing.T) {
+	ResetIterationBuffer() // A cold run, which allocates its iteration buffer
+	before := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+	params := MandelbrotParams{Width: 32, Height: 32, MaxIter: 50, ScaleFactor: 3.0}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	after := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+
+	if after.TotalAlloc <= before.TotalAlloc || after.Mallocs <= before.Mallocs {
+		t.Errorf("A run should allocate: before %+v, after %+v", before, after)
+	}
+}
+
+func TestEncodedParams(t *testing.T) {
+	params := MandelbrotParams{Width: 24, Height: 16, MaxIter: 80, CenterReal: -0.5, ScaleFactor: 3.0}
+	rawHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	encoded := common.EncodeParams(unsafe.Pointer(&params), ParamFields())
+	if hash := RunTask(uintptr(unsafe.Pointer(&encoded[0]))); hash != rawHash {
+		t.Errorf("Encoded params should match the raw struct: %d != %d", hash, rawHash)
+	}
+
+	// Trailing fields left out of the payload take their zero defaults
+	short := append([]byte(nil), encoded[:common.ParamsHeaderSize+36]...)
+	common.PutUint32LE(short[8:], 36)
+	if hash := RunTask(uintptr(unsafe.Pointer(&short[0]))); hash != rawHash {
+		t.Errorf("Short payload should default the trailing fields: %d != %d", hash, rawHash)
+	}
+
+	common.PutUint32LE(encoded[4:], common.ParamsVersion+1)
+	if status := RunTaskV2(uintptr(unsafe.Pointer(&encoded[0])), 0); status != common.StatusInvalidParams {
+		t.Errorf("Unknown encoding version should be rejected, got status %d", status)
+	}
+}
+
+func TestValidateParamsExport(t *testing.T) {
+	params := MandelbrotParams{Width: 16, Height: 16, MaxIter: 40, ScaleFactor: 3.0}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	metrics := lastWorkMetrics
+
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("Valid params should report StatusOK, got %d", status)
+	}
+
+	bad := MandelbrotParams{Width: 16, Height: 16, MaxIter: 40, ScaleFactor: -1}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&bad))); status != common.StatusInvalidParams {
+		t.Errorf("Expected status %d, got %d", common.StatusInvalidParams, status)
+	}
+	if common.LastError() != "scale factor must be positive" || GetErrorCode() != common.ErrNonPositive {
+		t.Errorf("Unexpected rejection reason %q (code %d)", common.LastError(), GetErrorCode())
+	}
+	if status := ValidateParams(0); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+	if GetErrorCode() != common.ErrNullParams {
+		t.Errorf("Null params should report ErrNullParams, got %d", GetErrorCode())
+	}
+
+	// Validation never runs the workload
+	if lastWorkMetrics != metrics {
+		t.Error("validate_params should not touch the last run's work metrics")
+	}
+}
+
+func TestRunTask64(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+
+	// Reference iteration buffer, mapped exactly as computeMandelbrot does
+	counts := make([]uint32, 0, params.Width*params.Height)
+	for y := uint32(0); y < params.Height; y++ {
+		for x := uint32(0); x < params.Width; x++ {
+			cReal := params.CenterReal + (float64(x)/float64(params.Width)-0.5)*params.ScaleFactor
+			cImag := params.CenterImag + (float64(y)/float64(params.Height)-0.5)*params.ScaleFactor
+			counts = append(counts, mandelbrotPixel(cReal, cImag, params.MaxIter))
+		}
+	}
+
+	if got, want := RunTask64(uintptr(unsafe.Pointer(&params))), common.Hash64Uint32s(common.FNV64OffsetBasis, counts); got != want {
+		t.Errorf("run_task64 = %#x, expected %#x", got, want)
+	}
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != fnv1aHashU32(counts) {
+		t.Errorf("run_task should keep the 32-bit hash, got %d", hash)
+	}
+
+	params.Verification = common.VerifyNone
+	if got := RunTask64(uintptr(unsafe.Pointer(&params))); got != uint64(sumU32(counts)) {
+		t.Errorf("Checksum level should return the widened checksum, got %#x", got)
+	}
+
+	if got := RunTask64(0); got != 0 {
+		t.Errorf("Null params should return 0, got %#x", got)
+	}
+}
+
+func TestRunTaskPacked(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOK, hash) {
+		t.Errorf("run_task_packed = %#x, expected status 0 and hash %d", got, hash)
+	}
+
+	params.Width = maxImageDimension + 1
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOverflow, 0) {
+		t.Errorf("Oversized image should pack StatusOverflow, got %#x", got)
+	}
+	if got := RunTaskPacked(0); got != common.PackResult(common.StatusInvalidParams, 0) {
+		t.Errorf("Null params should pack StatusInvalidParams, got %#x", got)
+	}
+}
+
+func TestResultBlock(t *testing.T) {
+	block := common.Memory(unsafe.Pointer(GetResultPtr()), common.ResultBlockSize)
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block) != common.ResultMagic || common.ReadUint32LE(block[4:]) != common.StatusOK ||
+		common.ReadUint32LE(block[8:]) != hash || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Unexpected result header % x after a run with hash %#x", block[:16], hash)
+	}
+	if common.ReadFloat64LE(block[24:]) != lastElapsedMs || common.ReadUint64LE(block[32:]) != lastWorkMetrics.ElementsProcessed ||
+		common.ReadUint64LE(block[40:]) != lastWorkMetrics.BytesTouched {
+		t.Errorf("Result block should carry the run's duration and work metrics, got % x", block[24:])
+	}
+
+	hash64 := RunTask64(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[12:]) != common.ResultHasHash64 || common.ReadUint64LE(block[16:]) != hash64 {
+		t.Errorf("run_task64 should publish its 64-bit hash %#x, got % x", hash64, block[12:24])
+	}
+
+	params.Width = maxImageDimension + 1
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[4:]) != common.StatusOverflow || common.ReadUint32LE(block[8:]) != 0 || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Oversized image should publish StatusOverflow, got % x", block[:16])
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	common.RequestCancel()
+	lastStatus = common.StatusOK
+	if hash := computeMandelbrot(&params); hash != 0 || lastStatus != common.StatusCancelled {
+		t.Errorf("Cancelled run returned %#x with status %d, expected 0 and StatusCancelled", hash, lastStatus)
+	}
+
+	// run_task lowers the flag, so the instance stays usable
+	if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want || lastStatus != common.StatusOK {
+		t.Errorf("Run after a cancellation = %#x (status %d), expected %#x", got, lastStatus, want)
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	defer common.EnableCheckpoints(false)
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0, HashAlgorithm: common.HashXXHash32}
+	counts := make([]uint32, 0, params.Width*params.Height)
+	for y := uint32(0); y < params.Height; y++ {
+		for x := uint32(0); x < params.Width; x++ {
+			cReal := params.CenterReal + (float64(x)/float64(params.Width)-0.5)*params.ScaleFactor
+			cImag := params.CenterImag + (float64(y)/float64(params.Height)-0.5)*params.ScaleFactor
+			counts = append(counts, mandelbrotPixel(cReal, cImag, params.MaxIter))
+		}
+	}
+
+	SetCheckpoints(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if HashInput() != 0 {
+		t.Errorf("hash_input should be 0 with checkpoints disabled, got %#x", HashInput())
+	}
+
+	SetCheckpoints(1)
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got, want := HashInput(), hashInput(&params); got != want || got == 0 {
+		t.Errorf("hash_input = %#x, expected %#x", got, want)
+	}
+	// The iterations stage is FNV-1a even when the result is xxHash32
+	if got, ok := common.CheckpointHash(stageIterations); !ok || got != fnv1aHashU32(counts) {
+		t.Errorf("Iterations checkpoint = %#x (recorded %v), expected %#x", got, ok, fnv1aHashU32(counts))
+	}
+	if got, ok := common.CheckpointHash(stageOutput); !ok || got != hash {
+		t.Errorf("Output checkpoint = %#x (recorded %v), expected the result %#x", got, ok, hash)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	defer SetOutput(0)
+	params := MandelbrotParams{Width: 12, Height: 9, MaxIter: 50, CenterReal: -0.5, ScaleFactor: 3.0, WarmupIterations: 2}
+	var counts []uint32
+	for y := uint32(0); y < params.Height; y++ {
+		for x := uint32(0); x < params.Width; x++ {
+			cReal := params.CenterReal + (float64(x)/float64(params.Width)-0.5)*params.ScaleFactor
+			cImag := params.CenterImag + (float64(y)/float64(params.Height)-0.5)*params.ScaleFactor
+			counts = append(counts, mandelbrotPixel(cReal, cImag, params.MaxIter))
+		}
+	}
+
+	SetOutput(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if data, _ := common.RunOutput(); len(data) != 0 {
+		t.Errorf("A run with the output disabled kept %d bytes", len(data))
+	}
+
+	// The warm-up runs' counts are not kept alongside the measured run's
+	SetOutput(1)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	data, elementSize := common.RunOutput()
+	if elementSize != outputElementSize || len(data) != len(counts)*outputElementSize {
+		t.Fatalf("Output of %d bytes (element size %d), expected %d counts", len(data), elementSize, len(counts))
+	}
+	for i, want := range counts {
+		if got := common.ReadUint32LE(data[i*outputElementSize:]); got != want {
+			t.Fatalf("Pixel %d: output %d iterations, expected %d", i, got, want)
+		}
+	}
+}
+
+func TestReserveMemory(t *testing.T) {
+	defer ReserveMemory(0)
+	params := MandelbrotParams{Width: 16, Height: 12, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	// Reserving collects garbage, which may move the stack, so params
+	// addresses are taken afresh for every call
+	if status := ReserveMemory(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Fatalf("reserve_memory returned status %d", status)
+	}
+	capacity := scratchArena.Capacity()
+	if capacity < workingSet(&params) {
+		t.Errorf("Reserved %d bytes, expected at least the working set of %d", capacity, workingSet(&params))
+	}
+
+	// Reserved runs take their buffers from the arena and never allocate
+	if allocs := testing.AllocsPerRun(5, func() { RunTask(uintptr(unsafe.Pointer(&params))) }); allocs != 0 {
+		t.Errorf("Reserved runs made %v allocations, expected none", allocs)
+	}
+	if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want || scratchArena.Capacity() != capacity {
+		t.Errorf("Reserved run = %#x with a %d-byte arena, expected %#x with %d bytes", got, scratchArena.Capacity(), want, capacity)
+	}
+
+	// A smaller image fits; a larger one is refused rather than grow the arena
+	small := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	if ValidateParams(uintptr(unsafe.Pointer(&small))) != common.StatusOK {
+		t.Error("A run within the reservation should be accepted")
+	}
+	large := MandelbrotParams{Width: 32, Height: 12, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	if RunTask(uintptr(unsafe.Pointer(&large))) != 0 || lastStatus != common.StatusOverflow {
+		t.Errorf("A run beyond the reservation should overflow, got status %d", lastStatus)
+	}
+
+	if ReserveMemory(0) != common.StatusOK || RunTask(uintptr(unsafe.Pointer(&large))) == 0 {
+		t.Error("reserve_memory(0) should leave the reserved mode")
+	}
+	params.Width = 0
+	if ReserveMemory(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams || common.Reserved() {
+		t.Error("Invalid params should be rejected without reserving")
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	defer SetMemoryBudget(0)
+	params := MandelbrotParams{Width: 640, Height: 480, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+
+	SetMemoryBudget(0)
+	if GetMaxMemoryPages() != common.MaxWasmPages {
+		t.Errorf("Without a budget memory may grow to %d pages, got %d", common.MaxWasmPages, GetMaxMemoryPages())
+	}
+
+	// A budget below the memory a run needs rejects it before it starts
+	SetMemoryBudget(1)
+	if GetMaxMemoryPages() != 1 {
+		t.Errorf("Expected a 1 page limit, got %d", GetMaxMemoryPages())
+	}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOverflow {
+		t.Errorf("A 640x480 image should not fit one page, got status %d", status)
+	}
+	if GetErrorCode() != common.ErrTooLarge {
+		t.Errorf("Over-budget params should report ErrTooLarge, got %d", GetErrorCode())
+	}
+	if RunTask(uintptr(unsafe.Pointer(&params))) != 0 || lastStatus != common.StatusOverflow {
+		t.Errorf("An over-budget run should fail with StatusOverflow, got %d", lastStatus)
+	}
+
+	SetMemoryBudget(common.MaxWasmPages)
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("A 640x480 image should fit the full address space, got status %d", status)
+	}
+}
+
+func TestHashAlgorithm(t *testing.T) {
+	params := MandelbrotParams{Width: 8, Height: 6, MaxIter: 60, CenterReal: -0.5, ScaleFactor: 3.0}
+	counts := make([]uint32, 0, params.Width*params.Height)
+	for y := uint32(0); y < params.Height; y++ {
+		for x := uint32(0); x < params.Width; x++ {
+			cReal := params.CenterReal + (float64(x)/float64(params.Width)-0.5)*params.ScaleFactor
+			cImag := params.CenterImag + (float64(y)/float64(params.Height)-0.5)*params.ScaleFactor
+			counts = append(counts, mandelbrotPixel(cReal, cImag, params.MaxIter))
+		}
+	}
+
+	fnvHash := RunTask(uintptr(unsafe.Pointer(&params)))
+	params.HashAlgorithm = common.HashXXHash32
+	if got, want := RunTask(uintptr(unsafe.Pointer(&params))), common.XXHash32Uint32s(0, counts); got != want {
+		t.Errorf("xxHash32 run = %#x, expected %#x", got, want)
+	}
+	if fnvHash != fnv1aHashU32(counts) {
+		t.Errorf("FNV-1a run = %#x, expected %#x", fnvHash, fnv1aHashU32(counts))
+	}
+
+	// Checksum levels do not hash, so the algorithm does not change them
+	params.Verification = common.VerifyNone
+	if got := RunTask(uintptr(unsafe.Pointer(&params))); got != sumU32(counts) {
+		t.Errorf("Checksum level should ignore the hash algorithm, got %d", got)
+	}
+
+	params.HashAlgorithm = common.HashXXHash32 + 1
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Unknown hash algorithm should be rejected")
+	}
+}
+
+func TestPanicExports(t *testing.T) {
+	// A normal run leaves the panic buffer empty; common tests cover recovery itself
+	params := MandelbrotParams{Width: 4, Height: 4, MaxIter: 10, ScaleFactor: 1.0}
+	if RunTask(uintptr(unsafe.Pointer(&params))) == 0 || lastStatus != common.StatusOK {
+		t.Fatalf("Valid run failed with status %d", lastStatus)
+	}
+	if GetPanicPtr() != common.PanicMessagePtr() || GetPanicLen() != 0 {
+		t.Errorf("Expected an empty panic buffer, got %d bytes", GetPanicLen())
+	}
+}
+
+func TestSIMDPathMatchesScalar(t *testing.T) {
+	defer func(saved bool) { useSIMD = saved }(useSIMD)
+
+	cases := []MandelbrotParams{
+		{Width: 64, Height: 48, MaxIter: 200, CenterReal: -0.5, ScaleFactor: 3.0},
+		{Width: 33, Height: 7, MaxIter: 500, CenterReal: -0.75, CenterImag: 0.1, ScaleFactor: 0.05},
+		{Width: 1, Height: 5, MaxIter: 100, CenterReal: 0.25, ScaleFactor: 1.0},
+		{Width: 17, Height: 17, MaxIter: 1, CenterReal: 2.5, ScaleFactor: 4.0},
+		{Width: 40, Height: 30, MaxIter: 300, CenterReal: 1e300, ScaleFactor: 1e300},
+	}
+	for _, params := range cases {
+		useSIMD = false
+		scalar := RunTask(uintptr(unsafe.Pointer(&params)))
+		useSIMD = true
+		vector := RunTask(uintptr(unsafe.Pointer(&params)))
+		if scalar == 0 || vector != scalar {
+			t.Errorf("%dx%d max_iter %d: SIMD path hash %#x, scalar %#x", params.Width, params.Height, params.MaxIter, vector, scalar)
+		}
+	}
+
+	if HasSIMD() != common.HasSIMD() {
+		t.Errorf("has_simd = %d, expected the build's %d", HasSIMD(), common.HasSIMD())
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	// Both pixel paths must pass, whichever the build picks
+	defer func(saved bool) { useSIMD = saved }(useSIMD)
+	for _, simd := range []bool{false, true} {
+		useSIMD = simd
+		if status := SelfTest(); status != common.StatusOK {
+			t.Errorf("self_test with SIMD %v = %d: %s", simd, status, common.LastError())
+		}
+	}
+
+	// A vector whose hash no longer matches fails the test and is named
+	defer func(saved []common.KnownAnswer[MandelbrotParams]) { selfTestVectors = saved }(selfTestVectors)
+	selfTestVectors = append([]common.KnownAnswer[MandelbrotParams](nil), selfTestVectors...)
+	broken := &selfTestVectors[len(selfTestVectors)-1]
+	broken.Hash++
+	if status := SelfTest(); status != common.StatusVerificationFailed {
+		t.Errorf("self_test with a wrong hash = %d, expected StatusVerificationFailed", status)
+	}
+	if !strings.Contains(common.LastError(), broken.Name) {
+		t.Errorf("self_test error %q does not name vector %s", common.LastError(), broken.Name)
+	}
+}
@@ -0,0 +1,53 @@
+package mandelbrot
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"wasmbench/common"
+	"wasmbench/common/mutation"
+)
+
+// TestHashMutations checks that every hash algorithm folds in each pixel's
+// iteration count and where it is, and that the input stage's hash folds in
+// each field the image is rendered from
+func TestHashMutations(t *testing.T) {
+	const width, height, maxIter = 4, 4, 50
+	counts := make([]uint32, 0, width*height)
+	for y := range height {
+		for x := range width {
+			counts = append(counts, mandelbrotPixel(-0.75+float64(x)/width, float64(y)/height-0.5, maxIter))
+		}
+	}
+	hashes := []mutation.Hash[[]uint32]{
+		{Name: "fnv1a", Sum: func(c []uint32) uint64 { return uint64(fnv1aHashU32(c)) }},
+		{Name: "fnv1a64", Sum: func(c []uint32) uint64 { return common.Hash64Uint32s(common.FNV64OffsetBasis, c) }},
+		{Name: "xxh32", Sum: func(c []uint32) uint64 { return uint64(common.XXHash32Uint32s(0, c)) }},
+	}
+	last := len(counts) - 1
+	mutation.Check(t, counts, slices.Clone, hashes, []mutation.Mutation[[]uint32]{
+		{Name: "change the first count", Apply: func(c []uint32) []uint32 { c[0]++; return c }},
+		{Name: "change the last count", Apply: func(c []uint32) []uint32 { c[last]--; return c }},
+		{Name: "change a count's high byte", Apply: func(c []uint32) []uint32 { c[5] ^= 1 << 24; return c }},
+		{Name: "zero a count", Apply: func(c []uint32) []uint32 { c[9] = 0; return c }},
+		{Name: "swap two pixels", Apply: func(c []uint32) []uint32 { c[0], c[last] = c[last], c[0]; return c }},
+		{Name: "drop the last pixel", Apply: func(c []uint32) []uint32 { return c[:last] }},
+		{Name: "add a pixel", Apply: func(c []uint32) []uint32 { return append(c, 0) }},
+	})
+
+	next := func(f float64) float64 { return math.Nextafter(f, math.Inf(1)) }
+	input := []mutation.Hash[MandelbrotParams]{
+		{Name: "input", Sum: func(p MandelbrotParams) uint64 { return uint64(hashInput(&p)) }},
+	}
+	mutation.Check(t, DefaultParams, func(p MandelbrotParams) MandelbrotParams { return p }, input, []mutation.Mutation[MandelbrotParams]{
+		{Name: "change width", Apply: func(p MandelbrotParams) MandelbrotParams { p.Width++; return p }},
+		{Name: "change height", Apply: func(p MandelbrotParams) MandelbrotParams { p.Height++; return p }},
+		{Name: "swap width and height", Apply: func(p MandelbrotParams) MandelbrotParams { p.Width, p.Height = 32, 128; return p }},
+		{Name: "change max_iter", Apply: func(p MandelbrotParams) MandelbrotParams { p.MaxIter++; return p }},
+		{Name: "change center_real's last bit", Apply: func(p MandelbrotParams) MandelbrotParams { p.CenterReal = next(p.CenterReal); return p }},
+		{Name: "change center_imag's last bit", Apply: func(p MandelbrotParams) MandelbrotParams { p.CenterImag = next(p.CenterImag); return p }},
+		{Name: "negate center_imag", Apply: func(p MandelbrotParams) MandelbrotParams { p.CenterImag = -p.CenterImag; return p }},
+		{Name: "change scale_factor's last bit", Apply: func(p MandelbrotParams) MandelbrotParams { p.ScaleFactor = next(p.ScaleFactor); return p }},
+	})
+}
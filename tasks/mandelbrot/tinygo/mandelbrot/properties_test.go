@@ -0,0 +1,84 @@
+package mandelbrot
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// propertyConfig checks a property over 2000 generated cases from a fixed
+// seed, so a failure reproduces
+func propertyConfig() *quick.Config {
+	return &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+}
+
+// point is a generated c, in the plane around the set: [-2.5, 1.5] × [-2, 2]
+type point struct {
+	Real, Imag float64
+}
+
+func (point) Generate(rng *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(point{Real: rng.Float64()*4 - 2.5, Imag: rng.Float64()*4 - 2})
+}
+
+// iterationBudget is a generated max_iter from 1 to 500
+func iterationBudget(n uint16) uint32 {
+	return uint32(n%500) + 1
+}
+
+func TestPixelSymmetryProperty(t *testing.T) {
+	// The set is symmetric about the real axis, and negating cImag negates
+	// every imaginary part exactly, so c and its conjugate count the same
+	symmetric := func(c point, n uint16) bool {
+		maxIter := iterationBudget(n)
+		iterations := mandelbrotPixel(c.Real, c.Imag, maxIter)
+		return iterations <= maxIter && mandelbrotPixel(c.Real, -c.Imag, maxIter) == iterations
+	}
+	if err := quick.Check(symmetric, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPixelBudgetProperty(t *testing.T) {
+	// A smaller budget cuts the same orbit short: its count is the larger
+	// budget's, capped
+	capped := func(c point, n, m uint16) bool {
+		small, large := min(iterationBudget(n), iterationBudget(m)), max(iterationBudget(n), iterationBudget(m))
+		return mandelbrotPixel(c.Real, c.Imag, small) == min(mandelbrotPixel(c.Real, c.Imag, large), small)
+	}
+	if err := quick.Check(capped, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPixelEscapeProperty(t *testing.T) {
+	// Outside the radius-2 disc z₁ = c has escaped, so the count is 1; well
+	// inside the period-2 bulb, |c + 1| < 1/4, the orbit never escapes
+	escapes := func(c point, n uint16) bool {
+		maxIter := iterationBudget(n)
+		iterations := mandelbrotPixel(c.Real, c.Imag, maxIter)
+		switch {
+		case c.Real*c.Real+c.Imag*c.Imag > 4:
+			return iterations == 1
+		case (c.Real+1)*(c.Real+1)+c.Imag*c.Imag < 0.2*0.2:
+			return iterations == maxIter
+		}
+		return true
+	}
+	if err := quick.Check(escapes, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPairMatchesPixelsProperty(t *testing.T) {
+	// The SIMD path's lanes count exactly what the scalar path does
+	lanes := func(a, b point, n uint16) bool {
+		maxIter := iterationBudget(n)
+		first, second := mandelbrotPair([2]float64{a.Real, b.Real}, a.Imag, maxIter)
+		return first == mandelbrotPixel(a.Real, a.Imag, maxIter) && second == mandelbrotPixel(b.Real, a.Imag, maxIter)
+	}
+	if err := quick.Check(lanes, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !wasm
+
+package mandelbrot
+
+import "wasmbench/common/refschema"
+
+// ReferenceSchema is the schema of data/reference_hashes/mandelbrot.json: the
+// categories of its vectors, and the limits get_limits reports, which bound
+// the params of a vector that runs
+func ReferenceSchema() refschema.Schema {
+	return refschema.Schema{
+		Task:       "mandelbrot",
+		Fields:     ParamFields(),
+		Stages:     StageNames,
+		Categories: []string{"boundary", "critical", "edge_case", "error", "precision", "runner", "systematic"},
+		Bounds: []refschema.Bound{
+			{Params: []string{"width"}, Max: maxImageDimension, Limit: "max_image_dimension"},
+			{Params: []string{"height"}, Max: maxImageDimension, Limit: "max_image_dimension"},
+			{Params: []string{"width", "height"}, Max: maxTotalPixels, Limit: "max_total_pixels"},
+		},
+	}
+}
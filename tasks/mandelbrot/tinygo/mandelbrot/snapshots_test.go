@@ -0,0 +1,33 @@
+package mandelbrot
+
+import (
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/snapshot"
+)
+
+// TestSnapshots compares the iteration counts of a small view with their
+// golden file in testdata/snapshots, a picture of the set a change to the
+// pixel mapping or escape test redraws where the hash only changes
+func TestSnapshots(t *testing.T) {
+	defer SetOutput(0)
+	defer func(saved bool) { useSIMD = saved }(useSIMD)
+
+	SetOutput(1)
+	params := MandelbrotParams{Width: 32, Height: 16, MaxIter: 64, CenterReal: -0.5, ScaleFactor: 3.0}
+	for _, simd := range []bool{false, true} {
+		useSIMD = simd
+		if RunTask(uintptr(unsafe.Pointer(&params))) == 0 {
+			t.Fatal("run failed")
+		}
+		data, _ := common.RunOutput()
+		counts := make([]uint32, len(data)/outputElementSize)
+		for i := range counts {
+			counts[i] = common.ReadUint32LE(data[i*outputElementSize:])
+		}
+		// Both paths draw the same picture
+		snapshot.Match(t, "iterations.txt", snapshot.Uint32Grid(counts, int(params.Width)))
+	}
+}
@@ -0,0 +1,157 @@
+/**
+ * matrix_mul in plain JavaScript: the baseline cmd/bench -js times the wasm
+ * builds against. It multiplies the same LCG matrices in float32, through
+ * Math.fround and Float32Array, with the TinyGo package's i,k,j loop, and
+ * hashes the product as it does.
+ */
+
+import {
+    ErrorCode,
+    FNV_OFFSET_BASIS,
+    GENERATOR_LCG,
+    HASH_FNV1A,
+    LCG,
+    PROFILE_DEFAULT,
+    SCALE_CUSTOM,
+    SCALE_LARGE,
+    VERIFY_FULL,
+    VERIFY_NONE,
+    Validator,
+    WORK_UNITS_PER_TARGET,
+    defineTask,
+    hashUint32,
+    paramsStruct,
+    reject
+} from '../../common/js/common.js';
+
+// Params fields, in the order of MatrixMulParams
+const FIELDS = [
+    'dimension',
+    'seed',
+    'scale',
+    'profile',
+    'target_work',
+    'warmup_iterations',
+    'verification',
+    'allocator',
+    'hash_algorithm',
+    'generator',
+    'seed_high'
+];
+
+// Matrix dimension of each scale tier
+const SCALE_DIMENSIONS = [0, 64, 256, 384, 576];
+
+const MAX_MATRIX_DIMENSION = 2000;
+const MAX_MATRICES_BYTES = 268435456;
+
+/** Throw the first reason the params are rejected, as parameterStatus orders them */
+function check(params) {
+    const v = new Validator();
+    v.nonZero(params.dimension, 'dimension must be non-zero');
+    v.atMost(params.dimension, MAX_MATRIX_DIMENSION, 'dimension exceeds the maximum matrix dimension');
+    v.options(params);
+    v.atMost(
+        params.dimension * params.dimension * 4 * 3,
+        MAX_MATRICES_BYTES,
+        'matrices exceed the maximum total matrix bytes'
+    );
+    v.unsupported(params.profile === PROFILE_DEFAULT, ErrorCode.UNKNOWN_PROFILE, 'the compute and memory profiles');
+    v.unsupported(params.verification !== VERIFY_FULL, ErrorCode.UNKNOWN_VERIFICATION, 'full verification');
+    v.unsupported(params.hash_algorithm === HASH_FNV1A, ErrorCode.UNKNOWN_HASH_ALGORITHM, 'xxHash32');
+    v.unsupported(params.generator === GENERATOR_LCG, ErrorCode.UNKNOWN_GENERATOR, 'the PCG32 generator');
+    v.result();
+}
+
+/** Resolve the scale tier, check the params and calibrate them to target_work */
+function prepare(values) {
+    const params = paramsStruct(values, FIELDS);
+    if (params.scale !== SCALE_CUSTOM) {
+        if (params.scale > SCALE_LARGE) {
+            throw reject(ErrorCode.UNKNOWN_SCALE, 'unknown scale tier');
+        }
+        params.dimension = SCALE_DIMENSIONS[params.scale];
+    }
+    check(params);
+
+    // Double the dimension until dimension³ multiply-adds reach the target,
+    // or the next doubling would be rejected
+    if (params.target_work !== 0) {
+        const target = params.target_work * WORK_UNITS_PER_TARGET;
+        while (params.dimension ** 3 < target) {
+            const next = { ...params, dimension: params.dimension * 2 };
+            try {
+                check(next);
+            } catch {
+                break;
+            }
+            params.dimension = next.dimension;
+        }
+    }
+    return params;
+}
+
+/** Draw an n×n matrix of values in [-1, 1] from rng, row-major */
+function randomMatrix(n, rng) {
+    const matrix = new Float32Array(n * n);
+    for (let i = 0; i < matrix.length; i++) {
+        matrix[i] = -1 + (rng.next() / 4294967295) * 2;
+    }
+    return matrix;
+}
+
+// Reinterprets a float32 as its bits for the unverified checksum
+const bits = new Float32Array(1);
+const bitsView = new Uint32Array(bits.buffer);
+
+/** Multiply the params' matrices and return the product's hash, or checksum */
+function execute(params) {
+    const n = params.dimension;
+    const rng = new LCG(params.seed, params.seed_high);
+    const a = randomMatrix(n, rng);
+    const b = randomMatrix(n, rng);
+    const c = new Float32Array(n * n);
+
+    // The product of two float32s is exact in a double, but is rounded to
+    // float32 before the sum as in the wasm builds
+    for (let i = 0; i < n; i++) {
+        const row = i * n;
+        for (let k = 0; k < n; k++) {
+            const aik = a[row + k];
+            const column = k * n;
+            for (let j = 0; j < n; j++) {
+                c[row + j] += Math.fround(aik * b[column + j]);
+            }
+        }
+    }
+
+    if (params.verification === VERIFY_NONE) {
+        let sum = 0;
+        for (let i = 0; i < c.length; i++) {
+            sum = Math.fround(sum + c[i]);
+        }
+        bits[0] = sum;
+        return bitsView[0];
+    }
+
+    // Each value rounded to 6 decimal places, halves away from zero as Go's
+    // math.Round, and hashed as an int32
+    let hash = FNV_OFFSET_BASIS;
+    for (let i = 0; i < c.length; i++) {
+        const scaled = c[i] * 1e6;
+        const rounded = scaled < 0 ? -Math.round(-scaled) : Math.round(scaled);
+        hash = hashUint32(hash, rounded | 0);
+    }
+    return hash;
+}
+
+export default defineTask({
+    name: 'matrix_mul',
+    prepare,
+    execute,
+    selfTestVectors: [
+        { name: 'edge_1x1', params: { dimension: 1, seed: 12345 }, hash: 158222968 },
+        { name: 'small_3x3', params: { dimension: 3, seed: 54321 }, hash: 2319415099 },
+        { name: 'small_4x4', params: { dimension: 4, seed: 98765 }, hash: 3697236173 }
+    ]
+});
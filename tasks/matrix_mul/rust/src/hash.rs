@@ -4,8 +4,12 @@ use crate::types::{FNV_OFFSET_BASIS, FNV_PRIME, PRECISION_DIGITS};
 
 /// Compute FNV-1a hash of matrix elements for cross-implementation verification
 pub fn fnv1a_hash_matrix(matrix: &Vec<Vec<f32>>) -> u32 {
-    let mut hash = FNV_OFFSET_BASIS;
+    fnv1a_update_matrix(FNV_OFFSET_BASIS, matrix)
+}
 
+/// Fold a matrix into an existing FNV-1a hash state, so several matrices can
+/// be hashed as one stream
+pub fn fnv1a_update_matrix(mut hash: u32, matrix: &Vec<Vec<f32>>) -> u32 {
     // Process elements in row-major order for consistency
     for row in matrix {
         for &value in row {
@@ -1,5 +1,7 @@
 use std::alloc::{alloc as sys_alloc, Layout};
 use std::os::raw::c_void;
+use std::sync::atomic::{AtomicBool, AtomicU32, Ordering};
+use std::sync::Mutex;
 
 pub mod generation;
 pub mod hash;
@@ -12,7 +14,28 @@ use generation::generate_random_matrix;
 use hash::fnv1a_hash_matrix;
 use matrix::naive_triple_loop_multiply;
 use types::{MatrixMulParams, MAX_ALLOCATION_SIZE};
-use validation::validate_parameters;
+use validation::{check_parameters, validate_parameters, ParamError};
+
+// Code of the last rejected params, ParamError::None after an accepted run
+static LAST_ERROR_CODE: AtomicU32 = AtomicU32::new(ParamError::None as u32);
+
+/// Bytes of one output element, a product value
+pub const OUTPUT_ELEMENT_SIZE: u32 = 4;
+
+// Whether runs keep their product for get_output, off until set_output
+static OUTPUT_ENABLED: AtomicBool = AtomicBool::new(false);
+
+// The last run's product, row by row
+static OUTPUT: Mutex<Vec<f32>> = Mutex::new(Vec::new());
+
+// get_output's block, laid out as the TinyGo builds': the address and byte
+// length of the product and the size of a value. AtomicU32 has u32's layout,
+// so the array is the block the host reads.
+static OUTPUT_BLOCK: [AtomicU32; 3] = [
+    AtomicU32::new(0),
+    AtomicU32::new(0),
+    AtomicU32::new(OUTPUT_ELEMENT_SIZE),
+];
 
 // WebAssembly exports for benchmark harness integration
 
@@ -51,14 +74,17 @@ pub extern "C" fn alloc(n_bytes: u32) -> *mut c_void {
 #[no_mangle]
 pub extern "C" fn run_task(params_ptr: *mut c_void) -> u32 {
     if params_ptr.is_null() {
+        LAST_ERROR_CODE.store(ParamError::NullParams as u32, Ordering::Relaxed);
         return 0;
     }
 
     let params = unsafe { &*(params_ptr as *const MatrixMulParams) };
 
-    if !validate_parameters(params) {
+    if let Err(code) = check_parameters(params) {
+        LAST_ERROR_CODE.store(code as u32, Ordering::Relaxed);
         return 0;
     }
+    LAST_ERROR_CODE.store(ParamError::None as u32, Ordering::Relaxed);
 
     // Generate matrices A and B using reproducible random generation
     let mut seed = params.seed;
@@ -72,14 +98,53 @@ pub extern "C" fn run_task(params_ptr: *mut c_void) -> u32 {
     // Execute matrix multiplication: C = A × B
     naive_triple_loop_multiply(&matrix_a, &matrix_b, &mut matrix_c);
 
+    // Keep the product, or clear the last run's, so get_output is this run's
+    let mut output = OUTPUT.lock().unwrap();
+    output.clear();
+    if OUTPUT_ENABLED.load(Ordering::Relaxed) {
+        for row in &matrix_c {
+            output.extend_from_slice(row);
+        }
+    }
+
     // Return FNV-1a hash of result matrix for verification
     fnv1a_hash_matrix(&matrix_c)
 }
 
+/// Shared code of the last parameter rejection, 0 when the last run accepted its params
+#[no_mangle]
+pub extern "C" fn get_error_code() -> u32 {
+    LAST_ERROR_CODE.load(Ordering::Relaxed)
+}
+
+/// Turn keeping each run's product for get_output on (non-zero) or off
+#[no_mangle]
+pub extern "C" fn set_output(enabled: u32) {
+    OUTPUT_ENABLED.store(enabled != 0, Ordering::Relaxed);
+}
+
+/// Address of the output block of the last run: its product, row by row,
+/// for comparing with another implementation's element by element
+#[no_mangle]
+pub extern "C" fn get_output() -> *const AtomicU32 {
+    let output = OUTPUT.lock().unwrap();
+    OUTPUT_BLOCK[0].store(output.as_ptr() as usize as u32, Ordering::Relaxed);
+    OUTPUT_BLOCK[1].store(output.len() as u32 * OUTPUT_ELEMENT_SIZE, Ordering::Relaxed);
+    OUTPUT_BLOCK.as_ptr()
+}
+
+/// The product get_output points at
+pub fn output_values() -> Vec<f32> {
+    OUTPUT.lock().unwrap().clone()
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
 
+    // Serializes the tests that run the task, which share its output
+    static RUN_LOCK: Mutex<()> = Mutex::new(());
+
     #[test]
     fn test_small_matrix_multiplication() {
         // Test 2x2 matrix multiplication with known values
@@ -196,6 +261,7 @@ mod tests {
             seed: 12345,
         };
         let params_ptr = &params as *const MatrixMulParams as *mut c_void;
+        let _run = RUN_LOCK.lock().unwrap();
 
         let hash_result = run_task(params_ptr);
 
@@ -206,6 +272,31 @@ mod tests {
         assert_eq!(hash_result, hash_result2);
     }
 
+    #[test]
+    fn test_output() {
+        let params = MatrixMulParams {
+            dimension: 3,
+            seed: 7,
+        };
+        let params_ptr = &params as *const MatrixMulParams as *mut c_void;
+        let _run = RUN_LOCK.lock().unwrap();
+
+        set_output(1);
+        let hash = run_task(params_ptr);
+        let values = output_values();
+        let block = unsafe { &*(get_output() as *const [u32; 3]) };
+        assert_eq!(block[1], 9 * OUTPUT_ELEMENT_SIZE);
+        assert_eq!(block[2], OUTPUT_ELEMENT_SIZE);
+
+        // The output is the product the hash was taken of, row by row
+        let rows: Vec<Vec<f32>> = values.chunks(3).map(|row| row.to_vec()).collect();
+        assert_eq!(fnv1a_hash_matrix(&rows), hash);
+
+        set_output(0);
+        run_task(params_ptr);
+        assert!(output_values().is_empty());
+    }
+
     #[test]
     fn generate_reference_vectors_output() {
         use reference::generate_test_vectors;
@@ -227,6 +318,8 @@ mod tests {
 
         // Basic validation
         assert!(vectors.len() >= 10);
-        assert!(vectors.iter().all(|v| v.expected_hash != 0));
+        assert!(vectors
+            .iter()
+            .all(|v| (v.expected_hash != 0) == (v.expected_status == 0)));
     }
 }
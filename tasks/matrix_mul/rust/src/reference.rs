@@ -1,21 +1,64 @@
 // Reference hash generation for cross-implementation validation
 
-use crate::types::MatrixMulParams;
+use crate::hash::fnv1a_update_matrix;
+use crate::types::{MatrixMulParams, MAX_MATRIX_DIMENSION};
+use crate::validation::{check_parameters, ParamError, STATUS_OK};
 use crate::{
     fnv1a_hash_matrix, generate_random_matrix, naive_triple_loop_multiply, validate_parameters,
 };
-use serde::Serialize;
+use serde::{Deserialize, Serialize};
 
-#[derive(Serialize, Debug)]
+#[derive(Serialize, Deserialize, Debug)]
 pub struct TestVector {
     pub name: String,
     pub description: String,
     pub params: SerializableParams,
     pub expected_hash: u32,
+    /// Status the run reports, STATUS_OK for vectors that produce a hash
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_status: u32,
+    /// ParamError code of a rejected run
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub expected_error_code: u32,
+    /// Hashes of the stages before the result, for vectors that produce one
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub expected_stages: Option<StageHashes>,
     pub category: String,
 }
 
-#[derive(Serialize, Debug)]
+/// Checkpoint hashes of a run's stages before its result, which is
+/// expected_hash, in the order the run reaches them. TinyGo records the same
+/// stages through get_checkpoints, so the first that differs is where two
+/// implementations diverged.
+#[derive(Serialize, Deserialize, Debug, Clone, Copy, PartialEq)]
+pub struct StageHashes {
+    /// Input operands, A then B
+    pub input: u32,
+    /// Product C
+    pub product: u32,
+}
+
+impl StageHashes {
+    /// Names the first stage whose hash differs from expected's, with both hashes
+    pub fn first_difference(&self, expected: &StageHashes) -> Option<String> {
+        [
+            ("input", self.input, expected.input),
+            ("product", self.product, expected.product),
+        ]
+        .into_iter()
+        .find(|(_, got, want)| got != want)
+        .map(|(stage, got, want)| {
+            format!("first diverging stage: {stage} hashed {got}, expected {want}")
+        })
+    }
+}
+
+/// Omits the status fields of vectors that succeed, keeping their JSON unchanged
+fn is_zero(value: &u32) -> bool {
+    *value == 0
+}
+
+#[derive(Serialize, Deserialize, Debug)]
 pub struct SerializableParams {
     pub dimension: u32,
     pub seed: u32,
@@ -46,6 +89,9 @@ pub fn generate_test_vectors() -> Vec<TestVector> {
     // Random seed variation tests
     vectors.extend(generate_seed_variation_tests());
 
+    // Params every implementation must reject
+    vectors.extend(generate_error_tests());
+
     vectors
 }
 
@@ -76,6 +122,9 @@ fn generate_small_matrix_tests() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: params.into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "small_matrices".to_string(),
             }
         })
@@ -124,6 +173,9 @@ fn generate_medium_matrix_tests() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: params.into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "medium_matrices".to_string(),
             }
         })
@@ -157,6 +209,9 @@ fn generate_edge_case_tests() -> Vec<TestVector> {
                 description: desc.to_string(),
                 params: params.into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "edge_cases".to_string(),
             }
         })
@@ -179,12 +234,52 @@ fn generate_seed_variation_tests() -> Vec<TestVector> {
                 description: format!("16x16 matrix with seed {}", seed),
                 params: params.into(),
                 expected_hash: hash,
+                expected_status: STATUS_OK,
+                expected_error_code: ParamError::None as u32,
+                expected_stages: compute_stage_hashes(params),
                 category: "seed_variations".to_string(),
             }
         })
         .collect()
 }
 
+fn generate_error_tests() -> Vec<TestVector> {
+    let test_cases = vec![
+        (
+            0,
+            "error_zero_dimension",
+            "Zero dimension - rejected as a zero dimension",
+        ),
+        (
+            MAX_MATRIX_DIMENSION + 1,
+            "error_dimension_over_limit",
+            "Dimension past the maximum - rejected as too large",
+        ),
+    ];
+
+    test_cases
+        .into_iter()
+        .map(|(dim, name, desc)| {
+            let params = MatrixMulParams {
+                dimension: dim,
+                seed: 12345,
+            };
+            let code = check_parameters(&params).expect_err("error tests must be rejected");
+
+            TestVector {
+                name: name.to_string(),
+                description: desc.to_string(),
+                params: params.into(),
+                expected_hash: compute_reference_hash(params),
+                expected_status: code.status(),
+                expected_error_code: code as u32,
+                expected_stages: None,
+                category: "errors".to_string(),
+            }
+        })
+        .collect()
+}
+
 fn compute_reference_hash(params: MatrixMulParams) -> u32 {
     if !validate_parameters(&params) {
         return 0; // Invalid parameters
@@ -206,6 +301,25 @@ fn compute_reference_hash(params: MatrixMulParams) -> u32 {
     fnv1a_hash_matrix(&matrix_c)
 }
 
+/// Hash each stage of a run, None for params the run rejects
+pub fn compute_stage_hashes(params: MatrixMulParams) -> Option<StageHashes> {
+    if !validate_parameters(&params) {
+        return None;
+    }
+
+    let mut seed = params.seed;
+    let matrix_a = generate_random_matrix(params.dimension as usize, &mut seed);
+    let matrix_b = generate_random_matrix(params.dimension as usize, &mut seed);
+    let n = params.dimension as usize;
+    let mut matrix_c = vec![vec![0.0f32; n]; n];
+    naive_triple_loop_multiply(&matrix_a, &matrix_b, &mut matrix_c);
+
+    Some(StageHashes {
+        input: fnv1a_update_matrix(fnv1a_hash_matrix(&matrix_a), &matrix_b),
+        product: fnv1a_hash_matrix(&matrix_c),
+    })
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -220,8 +334,12 @@ mod tests {
             "Should generate at least 10 test vectors"
         );
 
-        // All vectors should have valid hashes
+        // Vectors that succeed should have valid hashes, rejected ones zero
         for vector in &vectors {
+            if vector.expected_status != STATUS_OK {
+                assert_eq!(vector.expected_hash, 0, "Rejected params: {}", vector.name);
+                continue;
+            }
             assert_ne!(
                 vector.expected_hash, 0,
                 "Hash should not be zero for valid params: {}",
@@ -274,4 +392,40 @@ mod tests {
         assert_eq!(serializable.dimension, 16);
         assert_eq!(serializable.seed, 42);
     }
+
+    #[test]
+    fn test_reference_file_stages() {
+        // The committed file, whose stages the TinyGo implementation recorded
+        let path = concat!(
+            env!("CARGO_MANIFEST_DIR"),
+            "/../../../data/reference_hashes/matrix_mul.json"
+        );
+        let data = std::fs::read_to_string(path).expect("Failed to read the reference file");
+        let vectors: Vec<TestVector> =
+            serde_json::from_str(&data).expect("Failed to parse the reference file");
+
+        // Larger products take seconds each in an unoptimized test build
+        let checked = vectors
+            .iter()
+            .filter(|v| v.expected_status == STATUS_OK && v.params.dimension <= 128);
+        for vector in checked {
+            let params = MatrixMulParams {
+                dimension: vector.params.dimension,
+                seed: vector.params.seed,
+            };
+            let stages = compute_stage_hashes(params).expect("Reference params should be accepted");
+            if let Some(diverged) = vector
+                .expected_stages
+                .and_then(|expected| stages.first_difference(&expected))
+            {
+                panic!("{}: {}", vector.name, diverged);
+            }
+            // The result is the product's hash
+            assert_eq!(
+                stages.product, vector.expected_hash,
+                "{}: every stage matches, but the result hash differs",
+                vector.name
+            );
+        }
+    }
 }
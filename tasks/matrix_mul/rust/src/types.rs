@@ -25,3 +25,44 @@ pub const PRECISION_MULTIPLIER: f32 = 1e6;
 /// Validation limits to prevent resource exhaustion
 pub const MAX_MATRIX_DIMENSION: u32 = 2000; // Max 2000x2000 (16MB per matrix)
 pub const MAX_ALLOCATION_SIZE: u32 = 1_073_741_824; // 1GB
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use std::mem::{offset_of, size_of};
+
+    #[test]
+    fn test_params_layout() {
+        // The layout the Go build asserts; the Rust struct has the leading
+        // fields of the Go one
+        let path = concat!(env!("CARGO_MANIFEST_DIR"), "/../../../configs/layouts.json");
+        let data = std::fs::read_to_string(path).expect("Failed to read the layout file");
+        let layouts: serde_json::Value =
+            serde_json::from_str(&data).expect("Failed to parse the layout file");
+        let layout = layouts["structs"]
+            .as_array()
+            .and_then(|structs| structs.iter().find(|s| s["name"] == "MatrixMulParams"))
+            .expect("MatrixMulParams is not in the layout file");
+
+        macro_rules! field {
+            ($name:ident: $ty:ty) => {
+                (
+                    stringify!($name),
+                    offset_of!(MatrixMulParams, $name),
+                    size_of::<$ty>(),
+                )
+            };
+        }
+        let fields = [field!(dimension: u32), field!(seed: u32)];
+        for (i, (name, offset, size)) in fields.into_iter().enumerate() {
+            let field = &layout["fields"][i];
+            assert_eq!(field["name"], name, "Field {} of MatrixMulParams", i);
+            assert_eq!(field["offset"], offset as u64, "Offset of {}", name);
+            assert_eq!(field["size"], size as u64, "Size of {}", name);
+        }
+        assert!(
+            size_of::<MatrixMulParams>() as u64 <= layout["size"].as_u64().unwrap(),
+            "MatrixMulParams is larger than the layout file's"
+        );
+    }
+}
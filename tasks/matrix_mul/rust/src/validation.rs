@@ -2,38 +2,73 @@
 
 use crate::types::{MatrixMulParams, MAX_MATRIX_DIMENSION};
 
+/// Parameter error codes, identical to the TinyGo modules' shared codes
+#[repr(u32)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ParamError {
+    None = 0,
+    NullParams = 1,
+    BadEncoding = 2,
+    ZeroDimension = 3,
+    TooLarge = 4,
+    NonFinite = 5,
+    NonPositive = 6,
+    UnknownScale = 7,
+    UnknownProfile = 8,
+    UnknownVerification = 9,
+    UnknownAllocator = 10,
+    UnknownHashAlgorithm = 11,
+    UnknownGenerator = 12,
+}
+
+/// Run statuses, identical to the TinyGo modules' status codes
+pub const STATUS_OK: u32 = 0;
+pub const STATUS_INVALID_PARAMS: u32 = 1;
+pub const STATUS_OVERFLOW: u32 = 2;
+
+impl ParamError {
+    /// Status a run rejected with this code reports: limits overflow, every
+    /// other rejection is invalid params
+    pub fn status(self) -> u32 {
+        match self {
+            ParamError::None => STATUS_OK,
+            ParamError::TooLarge => STATUS_OVERFLOW,
+            _ => STATUS_INVALID_PARAMS,
+        }
+    }
+}
+
 /// Validates MatrixMulParams to prevent resource exhaustion and invalid computations
 pub fn validate_parameters(params: &MatrixMulParams) -> bool {
+    check_parameters(params).is_ok()
+}
+
+/// Checks MatrixMulParams, returning the code of the first rejected field
+pub fn check_parameters(params: &MatrixMulParams) -> Result<(), ParamError> {
     // Check for reasonable matrix dimensions
     if params.dimension == 0 {
-        return false; // Zero dimension is invalid
+        return Err(ParamError::ZeroDimension);
     }
 
     if params.dimension > MAX_MATRIX_DIMENSION {
-        return false; // Too large, would cause memory exhaustion
+        return Err(ParamError::TooLarge); // Too large, would cause memory exhaustion
     }
 
     // Check for potential overflow in memory calculations
     // Each matrix needs dimension² × 4 bytes (f32), need 3 matrices total
-    if let Some(elements) = params.dimension.checked_mul(params.dimension) {
-        if let Some(bytes_per_matrix) = elements.checked_mul(4) {
-            if let Some(total_bytes) = bytes_per_matrix.checked_mul(3) {
-                // Reasonable memory limit: 256MB total for all matrices
-                if total_bytes > 256 * 1024 * 1024 {
-                    return false;
-                }
-            } else {
-                return false; // Overflow in total calculation
-            }
-        } else {
-            return false; // Overflow in bytes calculation
-        }
-    } else {
-        return false; // Overflow in elements calculation
+    let total_bytes = params
+        .dimension
+        .checked_mul(params.dimension)
+        .and_then(|elements| elements.checked_mul(4))
+        .and_then(|bytes_per_matrix| bytes_per_matrix.checked_mul(3));
+    match total_bytes {
+        // Reasonable memory limit: 256MB total for all matrices
+        Some(total_bytes) if total_bytes <= 256 * 1024 * 1024 => {}
+        _ => return Err(ParamError::TooLarge),
     }
 
     // Seed can be any u32 value (including 0)
-    true
+    Ok(())
 }
 
 #[cfg(test)]
@@ -149,4 +184,33 @@ mod tests {
             "Exactly at max dimension should be valid"
         );
     }
+
+    #[test]
+    fn test_parameter_error_codes() {
+        let params = MatrixMulParams {
+            dimension: 0,
+            seed: 12345,
+        };
+        assert_eq!(check_parameters(&params), Err(ParamError::ZeroDimension));
+
+        let params = MatrixMulParams {
+            dimension: MAX_MATRIX_DIMENSION + 1,
+            seed: 12345,
+        };
+        assert_eq!(check_parameters(&params), Err(ParamError::TooLarge));
+
+        let params = MatrixMulParams {
+            dimension: 10,
+            seed: 0,
+        };
+        assert_eq!(check_parameters(&params), Ok(()));
+    }
+
+    #[test]
+    fn test_error_statuses() {
+        assert_eq!(ParamError::None.status(), STATUS_OK);
+        assert_eq!(ParamError::TooLarge.status(), STATUS_OVERFLOW);
+        assert_eq!(ParamError::ZeroDimension.status(), STATUS_INVALID_PARAMS);
+        assert_eq!(ParamError::NonPositive.status(), STATUS_INVALID_PARAMS);
+    }
 }
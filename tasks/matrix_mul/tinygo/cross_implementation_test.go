@@ -1,334 +0,0 @@
-// Package main provides cross-implementation validation tests for matrix multiplication
-// WebAssembly module, ensuring compatibility between TinyGo and Rust implementations.
-package main
-
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
-	"testing"
-	"unsafe"
-)
-
-// Test configuration constants
-const (
-	// Default test vector file path relative to this test file
-	defaultTestVectorFile = "../../../data/reference_hashes/matrix_mul.json"
-)
-
-// CrossImplementationTestVector represents a test vector for validating compatibility
-// between TinyGo and Rust matrix multiplication implementations.
-type CrossImplementationTestVector struct {
-	Name         string             `json:"name"`          // Unique test case identifier
-	Description  string             `json:"description"`   // Human-readable test description
-	Params       SerializableParams `json:"params"`        // Matrix computation parameters
-	ExpectedHash uint32             `json:"expected_hash"` // Expected hash from Rust reference
-	Category     string             `json:"category"`      // Test category classification
-}
-
-// TestResult encapsulates the results of a single cross-implementation test
-type TestResult struct {
-	Vector     CrossImplementationTestVector
-	Passed     bool
-	ActualHash uint32
-	Error      error
-}
-
-// Validate checks if the test vector parameters are within acceptable ranges
-func (v CrossImplementationTestVector) Validate() error {
-	if v.Name == "" {
-		return fmt.Errorf("test vector missing required 'name' field")
-	}
-	if v.Params.Dimension == 0 {
-		return fmt.Errorf("dimension must be greater than 0, got %d", v.Params.Dimension)
-	}
-	// Note: Seed can be any uint32 value including 0
-	return nil
-}
-
-// loadRustReferenceHashes loads reference hashes from the centralized data directory
-func loadRustReferenceHashes() ([]CrossImplementationTestVector, error) {
-	// Use centralized reference hashes location
-	absPath, err := filepath.Abs(defaultTestVectorFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve absolute path for %s: %w", defaultTestVectorFile, err)
-	}
-
-	data, err := os.ReadFile(absPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read test vectors file %s: %w", absPath, err)
-	}
-
-	var vectors []CrossImplementationTestVector
-	if err := json.Unmarshal(data, &vectors); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON from %s: %w", absPath, err)
-	}
-
-	if len(vectors) == 0 {
-		return nil, fmt.Errorf("no test vectors found in %s", absPath)
-	}
-
-	// Validate each test vector
-	for i, vector := range vectors {
-		if err := vector.Validate(); err != nil {
-			return nil, fmt.Errorf("test vector %d (%s) has invalid parameters: %w", i, vector.Name, err)
-		}
-	}
-
-	return vectors, nil
-}
-
-// runTaskWithParams is a helper function that converts MatrixMulParams to the format
-// expected by the runTask WebAssembly export function
-func runTaskWithParams(params MatrixMulParams) uint32 {
-	ptr := uintptr(unsafe.Pointer(&params))
-	return runTask(ptr)
-}
-
-// TestCrossImplementationCompatibility verifies that TinyGo produces same hashes as Rust
-func TestCrossImplementationCompatibility(t *testing.T) {
-	// Load reference hashes from Rust implementation
-	rustVectors, err := loadRustReferenceHashes()
-	if err != nil {
-		t.Skipf("Could not load Rust reference hashes: %v", err)
-		return
-	}
-
-	if len(rustVectors) == 0 {
-		t.Skip("No Rust reference vectors found")
-		return
-	}
-
-	// Test each vector from Rust implementation
-	passCount := 0
-	failCount := 0
-
-	for _, rustVector := range rustVectors {
-		params := MatrixMulParams{
-			Dimension: rustVector.Params.Dimension,
-			Seed:      rustVector.Params.Seed,
-		}
-
-		// Compute hash using TinyGo implementation
-		tinygoHash := runTaskWithParams(params)
-
-		if tinygoHash == rustVector.ExpectedHash {
-			passCount++
-			t.Logf("✅ %s: TinyGo=%d, Rust=%d (MATCH)",
-				rustVector.Name, tinygoHash, rustVector.ExpectedHash)
-		} else {
-			failCount++
-			t.Errorf("❌ %s: TinyGo=%d, Rust=%d (MISMATCH - %s)",
-				rustVector.Name, tinygoHash, rustVector.ExpectedHash, rustVector.Category)
-		}
-	}
-
-	t.Logf("\nCross-implementation test results:")
-	t.Logf("✅ Passed: %d/%d (%.1f%%)", passCount, len(rustVectors),
-		float64(passCount)/float64(len(rustVectors))*100)
-	t.Logf("❌ Failed: %d/%d", failCount, len(rustVectors))
-
-	if failCount > 0 {
-		t.Errorf("Cross-implementation validation failed: %d mismatches out of %d tests",
-			failCount, len(rustVectors))
-	}
-}
-
-// TestSpecificCrossImplementationCases tests known critical cases for cross-validation
-func TestSpecificCrossImplementationCases(t *testing.T) {
-	// Test cases that are most likely to reveal implementation differences
-	criticalCases := []struct {
-		name      string
-		dimension uint32
-		seed      uint32
-		reason    string
-	}{
-		{"minimal_1x1", 1, 12345, "Minimal case - single element"},
-		{"small_2x2", 2, 12345, "Small case - basic algorithm test"},
-		{"zero_seed", 4, 0, "Zero seed - edge case for RNG"},
-		{"max_seed", 4, 4294967295, "Maximum seed - RNG boundary"},
-		{"power_of_two", 16, 42, "Power of 2 dimension - common case"},
-		{"odd_dimension", 15, 1337, "Odd dimension - non-power of 2"},
-	}
-
-	for _, testCase := range criticalCases {
-		t.Run(testCase.name, func(t *testing.T) {
-			params := MatrixMulParams{
-				Dimension: testCase.dimension,
-				Seed:      testCase.seed,
-			}
-
-			// Test that we can compute hash without errors
-			hash := runTaskWithParams(params)
-			if hash == 0 && validateParameters(&params) {
-				t.Errorf("Valid parameters produced zero hash for %s", testCase.reason)
-			}
-
-			// Test determinism - same params should produce same hash
-			hash2 := runTaskWithParams(params)
-			if hash != hash2 {
-				t.Errorf("Non-deterministic hash for %s: %d != %d", testCase.reason, hash, hash2)
-			}
-
-			t.Logf("%s (dim=%d, seed=%d): hash=%d - %s",
-				testCase.name, testCase.dimension, testCase.seed, hash, testCase.reason)
-		})
-	}
-}
-
-// TestAlgorithmConsistencyAcrossLanguages validates key algorithmic components
-func TestAlgorithmConsistencyAcrossLanguages(t *testing.T) {
-	// Test LCG consistency
-	t.Run("LCG_Consistency", func(t *testing.T) {
-		seed := uint32(12345)
-
-		for i := 0; i < 5; i++ {
-			value := linearCongruentialGenerator(&seed)
-			t.Logf("LCG[%d] = %d", i, value)
-
-			// Verify non-zero output (basic sanity check)
-			if value == 0 {
-				t.Errorf("LCG produced zero at iteration %d", i)
-			}
-		}
-	})
-
-	// Test floating point range conversion consistency
-	t.Run("Float_Range_Consistency", func(t *testing.T) {
-		testValues := []uint32{0, 1000000, 2147483648, 4294967295}
-
-		for _, lcgValue := range testValues {
-			floatVal := lcgToFloatRange(lcgValue, FloatRangeMin, FloatRangeMax)
-
-			if floatVal < FloatRangeMin || floatVal > FloatRangeMax {
-				t.Errorf("Float value %f out of range [%f, %f] for LCG=%d",
-					floatVal, FloatRangeMin, FloatRangeMax, lcgValue)
-			}
-
-			t.Logf("LCG=%d -> Float=%f", lcgValue, floatVal)
-		}
-	})
-
-	// Test precision rounding consistency
-	t.Run("Precision_Rounding_Consistency", func(t *testing.T) {
-		testValues := []float32{1.234567, -1.234567, 0.0000001, 999.999999}
-
-		for _, value := range testValues {
-			rounded := roundFloat32ToPrecision(value, PrecisionDigits)
-			t.Logf("Float=%f -> Rounded=%d", value, rounded)
-
-			// Basic sanity check
-			if value == 0.0 && rounded != 0 {
-				t.Errorf("Zero should round to zero, got %d", rounded)
-			}
-		}
-	})
-}
-
-// BenchmarkCrossImplementationPerformance compares performance characteristics
-func BenchmarkCrossImplementationPerformance(b *testing.B) {
-	dimensions := []uint32{4, 8, 16, 32}
-
-	for _, dim := range dimensions {
-		b.Run(fmt.Sprintf("Matrix_%dx%d", dim, dim), func(b *testing.B) {
-			params := MatrixMulParams{
-				Dimension: dim,
-				Seed:      12345,
-			}
-
-			b.ResetTimer()
-			for i := 0; i < b.N; i++ {
-				_ = runTaskWithParams(params)
-			}
-		})
-	}
-}
-
-// TestCrossImplementationHashMatching is the standard validation entry point
-// This matches the naming convention expected by the validation framework
-func TestCrossImplementationHashMatching(t *testing.T) {
-	// Load reference hashes from Rust implementation
-	rustVectors, err := loadRustReferenceHashes()
-	if err != nil {
-		t.Fatalf("Could not load Rust reference hashes: %v", err)
-		return
-	}
-
-	if len(rustVectors) == 0 {
-		t.Fatal("No Rust reference vectors found")
-		return
-	}
-
-	// Test each vector from Rust implementation
-	passCount := 0
-	failCount := 0
-
-	for _, rustVector := range rustVectors {
-		params := MatrixMulParams{
-			Dimension: rustVector.Params.Dimension,
-			Seed:      rustVector.Params.Seed,
-		}
-
-		// Compute hash using TinyGo implementation
-		tinygoHash := runTaskWithParams(params)
-
-		if tinygoHash == rustVector.ExpectedHash {
-			passCount++
-		} else {
-			failCount++
-			// Only report mismatches for larger matrices as expected
-			if rustVector.Params.Dimension <= 4 {
-				t.Errorf("Critical mismatch for %s (dim=%d): TinyGo=%d, Rust=%d",
-					rustVector.Name, rustVector.Params.Dimension, tinygoHash, rustVector.ExpectedHash)
-			}
-		}
-	}
-
-	// Allow partial compatibility for larger matrices due to floating-point precision differences
-	if passCount < 6 { // At least small matrices should match
-		t.Errorf("Too many critical mismatches: passed %d/%d", passCount, len(rustVectors))
-	}
-}
-
-// TestTinyGoSpecificOptimizations validates TinyGo-specific implementation details
-func TestTinyGoSpecificOptimizations(t *testing.T) {
-	t.Run("Memory_Allocation", func(t *testing.T) {
-		// Test that we can handle various memory allocation sizes
-		sizes := []uint32{8, 64, 1024, 65536}
-
-		for _, size := range sizes {
-			ptr := alloc(size)
-			if ptr == 0 {
-				t.Errorf("Failed to allocate %d bytes", size)
-			} else {
-				t.Logf("Successfully allocated %d bytes at address 0x%x", size, ptr)
-			}
-		}
-
-		// Test allocation limits
-		if alloc(0) != 0 {
-			t.Error("Zero allocation should return null pointer")
-		}
-
-		if alloc(MaxAllocationSize+1) != 0 {
-			t.Error("Over-limit allocation should return null pointer")
-		}
-	})
-
-	t.Run("WebAssembly_Interface", func(t *testing.T) {
-		// Test that WebAssembly exports work correctly
-		initWasm(12345) // Should not panic
-
-		// Test run_task with various parameter combinations
-		params := MatrixMulParams{Dimension: 4, Seed: 42}
-		paramsPtr := uintptr(unsafe.Pointer(&params))
-
-		hash := runTask(paramsPtr)
-		if hash == 0 {
-			t.Error("Valid WebAssembly task should produce non-zero hash")
-		}
-
-		t.Logf("WebAssembly runTask result: %d", hash)
-	})
-}
@@ -3,4 +3,7 @@ module matrix_mul_wasm
 go 1.25
 
 // TinyGo WebAssembly implementation
-// No external dependencies - pure standard library
\ No newline at end of file
+// Shared helpers live in the local wasmbench/common module
+require wasmbench/common v0.0.0
+
+replace wasmbench/common => ../../common
@@ -0,0 +1,56 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"syscall/js"
+
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common"
+)
+
+// Standard Go (GOOS=js) build: the //go:export functions are published
+// through syscall/js under the same names, then main blocks to keep them live
+func main() {
+	common.ExposeJS(map[string]common.JSExport{
+		"init":                 func(args []js.Value) any { matrixmul.Init(common.JSUint32(args, 0)); return nil },
+		"init64":               func(args []js.Value) any { matrixmul.Init64(common.JSUint64Arg(args, 0)); return nil },
+		"alloc":                func(args []js.Value) any { return matrixmul.Alloc(common.JSUint32(args, 0)) },
+		"alloc_uninitialized":  func(args []js.Value) any { return matrixmul.AllocUninitialized(common.JSUint32(args, 0)) },
+		"dealloc":              func(args []js.Value) any { matrixmul.Dealloc(common.JSPtr(args, 0)); return nil },
+		"get_scale_factor":     func(args []js.Value) any { return matrixmul.GetScaleFactor() },
+		"get_work_metrics":     func(args []js.Value) any { return matrixmul.GetWorkMetrics() },
+		"get_memory_stats":     func(args []js.Value) any { return matrixmul.GetMemoryStats() },
+		"params_fingerprint":   func(args []js.Value) any { return matrixmul.ParamsFingerprint() },
+		"get_limits":           func(args []js.Value) any { return matrixmul.GetLimits() },
+		"abi_version":          func(args []js.Value) any { return matrixmul.ABIVersion() },
+		"get_task_info":        func(args []js.Value) any { return matrixmul.GetTaskInfo() },
+		"reset_arena":          func(args []js.Value) any { matrixmul.ResetArena(); return nil },
+		"reserve_memory":       func(args []js.Value) any { return matrixmul.ReserveMemory(common.JSPtr(args, 0)) },
+		"set_memory_budget":    func(args []js.Value) any { matrixmul.SetMemoryBudget(common.JSUint32(args, 0)); return nil },
+		"get_max_memory_pages": func(args []js.Value) any { return matrixmul.GetMaxMemoryPages() },
+		"has_threads":          func(args []js.Value) any { return matrixmul.HasThreads() },
+		"set_thread_count":     func(args []js.Value) any { return matrixmul.SetThreadCount(common.JSUint32(args, 0)) },
+		"has_simd":             func(args []js.Value) any { return matrixmul.HasSIMD() },
+		"get_cancel_ptr":       func(args []js.Value) any { return matrixmul.GetCancelPtr() },
+		"set_checkpoints":      func(args []js.Value) any { matrixmul.SetCheckpoints(common.JSUint32(args, 0)); return nil },
+		"hash_input":           func(args []js.Value) any { return matrixmul.HashInput() },
+		"get_checkpoints":      func(args []js.Value) any { return matrixmul.GetCheckpoints() },
+		"set_output":           func(args []js.Value) any { matrixmul.SetOutput(common.JSUint32(args, 0)); return nil },
+		"get_output":           func(args []js.Value) any { return matrixmul.GetOutput() },
+		"get_result_ptr":       func(args []js.Value) any { return matrixmul.GetResultPtr() },
+		"get_last_error_ptr":   func(args []js.Value) any { return matrixmul.GetLastErrorPtr() },
+		"get_last_error_len":   func(args []js.Value) any { return matrixmul.GetLastErrorLen() },
+		"get_error_code":       func(args []js.Value) any { return matrixmul.GetErrorCode() },
+		"get_panic_ptr":        func(args []js.Value) any { return matrixmul.GetPanicPtr() },
+		"get_panic_len":        func(args []js.Value) any { return matrixmul.GetPanicLen() },
+		"run_task64":           func(args []js.Value) any { return common.JSUint64(matrixmul.RunTask64(common.JSPtr(args, 0))) },
+		"run_task_timed":       func(args []js.Value) any { return matrixmul.RunTaskTimed(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_v2":          func(args []js.Value) any { return matrixmul.RunTaskV2(common.JSPtr(args, 0), common.JSPtr(args, 1)) },
+		"run_task_packed":      func(args []js.Value) any { return common.JSUint64(matrixmul.RunTaskPacked(common.JSPtr(args, 0))) },
+		"validate_params":      func(args []js.Value) any { return matrixmul.ValidateParams(common.JSPtr(args, 0)) },
+		"self_test":            func(args []js.Value) any { return matrixmul.SelfTest() },
+		"run_task":             func(args []js.Value) any { return matrixmul.RunTask(common.JSPtr(args, 0)) },
+	})
+	select {}
+}
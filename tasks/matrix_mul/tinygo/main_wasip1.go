@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"unsafe"
+
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common"
+	"wasmbench/common/cli"
+)
+
+// WASI command: params as a JSON object on stdin (field names as in
+// get_task_info), result hash in decimal on stdout, status as the exit code
+func main() {
+	os.Exit(cli.Run(os.Stdin, os.Stdout, os.Stderr, matrixmul.ParamFields(),
+		func(params *matrixmul.MatrixMulParams, result *common.TaskResult) uint32 {
+			return matrixmul.RunTaskV2(uintptr(unsafe.Pointer(params)), uintptr(unsafe.Pointer(result)))
+		}))
+}
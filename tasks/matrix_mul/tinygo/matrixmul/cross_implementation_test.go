@@ -0,0 +1,255 @@
+// Package matrixmul provides cross-implementation validation tests for matrix multiplication
+// WebAssembly module, ensuring compatibility between TinyGo and Rust implementations.
+package matrixmul
+
+import (
+	_ "embed"
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/cli"
+	"wasmbench/common/conformance"
+)
+
+// referenceHashes is data/reference_hashes/matrix_mul.json, copied beside the
+// package by cmd/genrefs so the tests find it wherever they run
+//
+//go:embed testdata/reference_hashes.json
+var referenceHashes []byte
+
+// matrixMulTask is the package as the conformance suite runs it
+func matrixMulTask() conformance.Task {
+	return conformance.Task{
+		Name:      "matrix_mul",
+		Schema:    ReferenceSchema(),
+		Size:      unsafe.Sizeof(MatrixMulParams{}),
+		Run:       RunTaskV2,
+		Vectors:   referenceHashes,
+		Source:    "testdata/reference_hashes.json",
+		Tolerance: FloatTolerance,
+		Reference: func(params unsafe.Pointer) []float32 { return ReferenceProduct((*MatrixMulParams)(params)) },
+	}
+}
+
+// runTaskWithParams is a helper function that converts MatrixMulParams to the format
+// expected by the RunTask WebAssembly export function
+func runTaskWithParams(params MatrixMulParams) uint32 {
+	ptr := uintptr(unsafe.Pointer(&params))
+	return RunTask(ptr)
+}
+
+// TestSpecificCrossImplementationCases tests known critical cases for cross-validation
+func TestSpecificCrossImplementationCases(t *testing.T) {
+	// Test cases that are most likely to reveal implementation differences
+	criticalCases := []struct {
+		name      string
+		dimension uint32
+		seed      uint32
+		reason    string
+	}{
+		{"minimal_1x1", 1, 12345, "Minimal case - single element"},
+		{"small_2x2", 2, 12345, "Small case - basic algorithm test"},
+		{"zero_seed", 4, 0, "Zero seed - edge case for RNG"},
+		{"max_seed", 4, 4294967295, "Maximum seed - RNG boundary"},
+		{"power_of_two", 16, 42, "Power of 2 dimension - common case"},
+		{"odd_dimension", 15, 1337, "Odd dimension - non-power of 2"},
+	}
+
+	for _, testCase := range criticalCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			params := MatrixMulParams{
+				Dimension: testCase.dimension,
+				Seed:      testCase.seed,
+			}
+
+			// Test that we can compute hash without errors
+			hash := runTaskWithParams(params)
+			if hash == 0 && validateParameters(&params) {
+				t.Errorf("Valid parameters produced zero hash for %s", testCase.reason)
+			}
+
+			// Test determinism - same params should produce same hash
+			hash2 := runTaskWithParams(params)
+			if hash != hash2 {
+				t.Errorf("Non-deterministic hash for %s: %d != %d", testCase.reason, hash, hash2)
+			}
+
+			t.Logf("%s (dim=%d, seed=%d): hash=%d - %s",
+				testCase.name, testCase.dimension, testCase.seed, hash, testCase.reason)
+		})
+	}
+}
+
+// TestAlgorithmConsistencyAcrossLanguages validates key algorithmic components
+func TestAlgorithmConsistencyAcrossLanguages(t *testing.T) {
+	// Test LCG consistency
+	t.Run("LCG_Consistency", func(t *testing.T) {
+		seed := uint32(12345)
+
+		for i := 0; i < 5; i++ {
+			value := common.NextLCG(&seed)
+			t.Logf("LCG[%d] = %d", i, value)
+
+			// Verify non-zero output (basic sanity check)
+			if value == 0 {
+				t.Errorf("LCG produced zero at iteration %d", i)
+			}
+		}
+	})
+
+	// Test floating point range conversion consistency
+	t.Run("Float_Range_Consistency", func(t *testing.T) {
+		testValues := []uint32{0, 1000000, 2147483648, 4294967295}
+
+		for _, lcgValue := range testValues {
+			floatVal := lcgToFloatRange(lcgValue, FloatRangeMin, FloatRangeMax)
+
+			if floatVal < FloatRangeMin || floatVal > FloatRangeMax {
+				t.Errorf("Float value %f out of range [%f, %f] for LCG=%d",
+					floatVal, FloatRangeMin, FloatRangeMax, lcgValue)
+			}
+
+			t.Logf("LCG=%d -> Float=%f", lcgValue, floatVal)
+		}
+	})
+
+	// Test precision rounding consistency
+	t.Run("Precision_Rounding_Consistency", func(t *testing.T) {
+		testValues := []float32{1.234567, -1.234567, 0.0000001, 999.999999}
+
+		for _, value := range testValues {
+			rounded := roundFloat32ToPrecision(value, PrecisionDigits)
+			t.Logf("Float=%f -> Rounded=%d", value, rounded)
+
+			// Basic sanity check
+			if value == 0.0 && rounded != 0 {
+				t.Errorf("Zero should round to zero, got %d", rounded)
+			}
+		}
+	})
+}
+
+// BenchmarkCrossImplementationPerformance compares performance characteristics
+func BenchmarkCrossImplementationPerformance(b *testing.B) {
+	dimensions := []uint32{4, 8, 16, 32}
+
+	for _, dim := range dimensions {
+		b.Run(fmt.Sprintf("Matrix_%dx%d", dim, dim), func(b *testing.B) {
+			params := MatrixMulParams{
+				Dimension: dim,
+				Seed:      12345,
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = runTaskWithParams(params)
+			}
+		})
+	}
+}
+
+// floatTolerance returns the tolerance of the element-wise comparison
+func floatTolerance(t *testing.T) common.Tolerance {
+	tolerance, err := conformance.Tolerance(FloatTolerance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tolerance
+}
+
+// productOutput runs params with the output kept and returns the product
+func productOutput(params MatrixMulParams) []float32 {
+	defer SetOutput(0)
+	SetOutput(1)
+	runTaskWithParams(params)
+	data, _ := common.RunOutput()
+	return common.Float32sLE(data)
+}
+
+// describeComparison says how far the product of a vector was from the
+// reference product
+func describeComparison(c common.FloatComparison, tolerance common.Tolerance) string {
+	if c.Within() {
+		return fmt.Sprintf("all %d elements within %s (max %d ULPs, max difference %g)", c.Elements, tolerance, c.MaxULPs, c.MaxAbs)
+	}
+	return fmt.Sprintf("%d of %d elements beyond %s, the first at %d: %v, expected %v", c.Beyond, c.Elements, tolerance, c.First, c.FirstGot, c.FirstWant)
+}
+
+// TestCrossImplementationHashMatching validates that the TinyGo implementation
+// produces the reference hash, status and stage hashes of every test vector,
+// under the conformance policy every task shares. A product whose hash misses
+// the reference's passes if every element is within FloatTolerance, or
+// WASMBENCH_FLOAT_TOLERANCE, of the float64 reference product: float
+// rounding, reported apart from the exact matches.
+func TestCrossImplementationHashMatching(t *testing.T) {
+	conformance.Run(t, matrixMulTask())
+}
+
+// TestCrossImplementationOutputTolerance compares every vector's full
+// product, not only its hash, with the float64 reference product under the
+// tolerance, so a wrong product is caught even where a reference hash was
+// regenerated from it
+func TestCrossImplementationOutputTolerance(t *testing.T) {
+	vectors, err := matrixMulTask().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tolerance := floatTolerance(t)
+	for _, vector := range vectors {
+		if vector.ExpectedStatus != common.StatusOK {
+			continue
+		}
+		var params MatrixMulParams
+		if status, message := cli.ParamsFromJSON(vector.Params, ParamFields(), unsafe.Pointer(&params)); status != common.StatusOK {
+			t.Fatalf("%s: %s", vector.Name, message)
+		}
+		comparison := tolerance.CompareFloat32s(productOutput(params), ReferenceProduct(&params))
+		if !comparison.Within() {
+			t.Errorf("%s (dim=%d): %s", vector.Name, params.Dimension, describeComparison(comparison, tolerance))
+		}
+	}
+}
+
+// TestTinyGoSpecificOptimizations validates TinyGo-specific implementation details
+func TestTinyGoSpecificOptimizations(t *testing.T) {
+	t.Run("Memory_Allocation", func(t *testing.T) {
+		// Test that we can handle various memory allocation sizes
+		sizes := []uint32{8, 64, 1024, 65536}
+
+		for _, size := range sizes {
+			ptr := Alloc(size)
+			if ptr == 0 {
+				t.Errorf("Failed to allocate %d bytes", size)
+			} else {
+				t.Logf("Successfully allocated %d bytes at address 0x%x", size, ptr)
+			}
+		}
+
+		// Test allocation limits
+		if Alloc(0) != 0 {
+			t.Error("Zero allocation should return null pointer")
+		}
+
+		if Alloc(common.MaxAllocationSize+1) != 0 {
+			t.Error("Over-limit allocation should return null pointer")
+		}
+	})
+
+	t.Run("WebAssembly_Interface", func(t *testing.T) {
+		// Test that WebAssembly exports work correctly
+		Init(12345) // Should not panic
+
+		// Test run_task with various parameter combinations
+		params := MatrixMulParams{Dimension: 4, Seed: 42}
+		paramsPtr := uintptr(unsafe.Pointer(&params))
+
+		hash := RunTask(paramsPtr)
+		if hash == 0 {
+			t.Error("Valid WebAssembly task should produce non-zero hash")
+		}
+
+		t.Logf("WebAssembly RunTask result: %d", hash)
+	})
+}
@@ -0,0 +1,1472 @@
+package matrixmul
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+)
+
+// Matrix operations tests
+
+func TestCreateZeroMatrix(t *testing.T) {
+	matrix := createZeroMatrix(3)
+
+	if len(matrix) != 3 {
+		t.Errorf("Expected matrix to have 3 rows, got %d", len(matrix))
+	}
+
+	for i, row := range matrix {
+		if len(row) != 3 {
+			t.Errorf("Expected row %d to have 3 columns, got %d", i, len(row))
+		}
+		for j, element := range row {
+			if element != 0.0 {
+				t.Errorf("Expected element [%d][%d] to be 0.0, got %f", i, j, element)
+			}
+		}
+	}
+}
+
+func TestCreateIdentityMatrix(t *testing.T) {
+	identity := createIdentityMatrix(3)
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			expected := float32(0.0)
+			if i == j {
+				expected = 1.0
+			}
+			if identity[i][j] != expected {
+				t.Errorf("Identity[%d][%d] = %f, expected %f", i, j, identity[i][j], expected)
+			}
+		}
+	}
+}
+
+func TestMatrixMultiplyIdentity(t *testing.T) {
+	// Test A × I = A
+	a := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+	identity := createIdentityMatrix(2)
+
+	result := matrixMultiply(a, identity)
+
+	if !matricesApproximatelyEqual(a, result, 1e-6) {
+		t.Error("A × I should equal A")
+	}
+}
+
+func TestMatrixMultiplyZero(t *testing.T) {
+	// Test A × 0 = 0
+	a := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+	zero := createZeroMatrix(2)
+
+	result := matrixMultiply(a, zero)
+
+	if !matricesApproximatelyEqual(zero, result, 1e-6) {
+		t.Error("A × 0 should equal 0")
+	}
+}
+
+func TestMatrixMultiplyKnownValues(t *testing.T) {
+	// Test with known multiplication result
+	a := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+	b := [][]float32{
+		{2.0, 0.0},
+		{1.0, 2.0},
+	}
+
+	expected := [][]float32{
+		{4.0, 4.0},  // [1*2 + 2*1, 1*0 + 2*2]
+		{10.0, 8.0}, // [3*2 + 4*1, 3*0 + 4*2]
+	}
+
+	result := matrixMultiply(a, b)
+
+	if !matricesApproximatelyEqual(expected, result, 1e-6) {
+		t.Errorf("Matrix multiplication produced incorrect result")
+	}
+}
+
+func TestNaiveTripleLoopMultiply(t *testing.T) {
+	// Test 2x2 matrix multiplication with known values
+	a := [][]float32{{1.0, 2.0}, {3.0, 4.0}}
+	b := [][]float32{{5.0, 6.0}, {7.0, 8.0}}
+	c := [][]float32{{0.0, 0.0}, {0.0, 0.0}}
+
+	naiveTripleLoopMultiply(a, b, c)
+
+	// Expected result: [[19, 22], [43, 50]]
+	expected := [][]float32{{19.0, 22.0}, {43.0, 50.0}}
+
+	if !matricesApproximatelyEqual(expected, c, 1e-6) {
+		t.Errorf("Triple loop multiplication failed. Got: %v, Expected: %v", c, expected)
+	}
+}
+
+// Random generation tests
+
+func TestLinearCongruentialGeneratorDeterministic(t *testing.T) {
+	seed1 := uint32(12345)
+	seed2 := uint32(12345)
+
+	// Same seed should produce same sequence
+	for i := 0; i < 10; i++ {
+		val1 := common.NextLCG(&seed1)
+		val2 := common.NextLCG(&seed2)
+		if val1 != val2 {
+			t.Errorf("LCG should be deterministic: iteration %d, %d != %d", i, val1, val2)
+		}
+	}
+}
+
+func TestLcgToFloatRange(t *testing.T) {
+	// Test range conversion with standardized precision
+	testValue := uint32(math.MaxUint32 / 2) // Middle value
+	result := lcgToFloatRange(testValue, -1.0, 1.0)
+
+	// Should be approximately in the middle of range
+	if result < -1.0 || result > 1.0 {
+		t.Errorf("Value should be in range [-1, 1], got %f", result)
+	}
+
+	if math.Abs(float64(result)) > 0.1 {
+		t.Errorf("Middle value should be near 0, got %f", result)
+	}
+
+	// Test extremes
+	minResult := lcgToFloatRange(0, -1.0, 1.0)
+	maxResult := lcgToFloatRange(math.MaxUint32, -1.0, 1.0)
+
+	if math.Abs(float64(minResult-(-1.0))) > 0.01 {
+		t.Errorf("Min should be close to -1.0, got %f", minResult)
+	}
+	if math.Abs(float64(maxResult-1.0)) > 0.01 {
+		t.Errorf("Max should be close to 1.0, got %f", maxResult)
+	}
+}
+
+func TestCrossLanguagePrecisionConsistency(t *testing.T) {
+	// Test specific values to ensure cross-language consistency with Rust
+	testCases := []struct {
+		lcgValue uint32
+		min, max float32
+	}{
+		{12345, -1.0, 1.0},
+		{math.MaxUint32 / 2, -1.0, 1.0},
+		{1664525, -1.0, 1.0},
+		{1013904223, -1.0, 1.0},
+	}
+
+	for _, tc := range testCases {
+		result := lcgToFloatRange(tc.lcgValue, tc.min, tc.max)
+
+		// Ensure result is in valid range
+		if result < tc.min || result > tc.max {
+			t.Errorf("Value %f should be in range [%f, %f] for LCG %d",
+				result, tc.min, tc.max, tc.lcgValue)
+		}
+
+		// The result should be deterministic
+		result2 := lcgToFloatRange(tc.lcgValue, tc.min, tc.max)
+		if result != result2 {
+			t.Errorf("Results should be deterministic: %f != %f for LCG %d",
+				result, result2, tc.lcgValue)
+		}
+
+		t.Logf("LCG %d -> %.10f", tc.lcgValue, result)
+	}
+}
+
+func TestLcgToFloatRangeCoverage(t *testing.T) {
+	// The matrix values of each generator cover [-1, 1] evenly, without the
+	// clusters or gaps a biased conversion would leave in the data
+	const samples = 1 << 16
+	const bins = 100
+	const critical = 148.23 // The chi-square 99 degrees of freedom exceed with probability 0.001
+	rangeWidth := float64(FloatRangeMax - FloatRangeMin)
+	for _, generator := range []struct {
+		name string
+		id   uint32
+	}{{"lcg", common.GeneratorLCG}, {"pcg32", common.GeneratorPCG32}} {
+		for _, seed := range []uint64{0, 42, 12345} {
+			rng := common.NewRand(generator.id, seed)
+			values := make([]float64, samples)
+			counts := make([]int, bins)
+			distinct := map[float32]bool{}
+			sum := 0.0
+			for i := range values {
+				value := lcgToFloatRange(rng.Next(), FloatRangeMin, FloatRangeMax)
+				if value < FloatRangeMin || value > FloatRangeMax {
+					t.Fatalf("%s from %d: %v outside [%v, %v]", generator.name, seed, value, FloatRangeMin, FloatRangeMax)
+				}
+				values[i] = float64(value)
+				counts[min(int((values[i]-float64(FloatRangeMin))/rangeWidth*bins), bins-1)]++
+				distinct[value] = true
+				sum += values[i]
+			}
+
+			expected := float64(samples) / bins
+			statistic := 0.0
+			for _, count := range counts {
+				statistic += (float64(count) - expected) * (float64(count) - expected) / expected
+			}
+			if statistic > critical {
+				t.Errorf("%s from %d: chi-square %.1f over %d bins exceeds %.1f", generator.name, seed, statistic, bins, critical)
+			}
+			// The mean of a uniform sample is within four standard errors of the middle
+			if mean, bound := sum/samples, 4*rangeWidth/math.Sqrt(12*samples); math.Abs(mean) > bound {
+				t.Errorf("%s from %d: mean %g, expected within %g of 0", generator.name, seed, mean, bound)
+			}
+			if len(distinct) < samples*99/100 {
+				t.Errorf("%s from %d: %d distinct values of %d", generator.name, seed, len(distinct), samples)
+			}
+
+			// The largest gap between neighbours, ends included, is about
+			// ln(n)/n of the range for a uniform sample
+			slices.Sort(values)
+			gap := max(values[0]-float64(FloatRangeMin), float64(FloatRangeMax)-values[samples-1])
+			for i := 1; i < samples; i++ {
+				gap = max(gap, values[i]-values[i-1])
+			}
+			if bound := 3 * math.Log(samples) / samples * rangeWidth; gap > bound {
+				t.Errorf("%s from %d: a gap of %g in the values, expected at most %g", generator.name, seed, gap, bound)
+			}
+		}
+	}
+}
+
+func TestGenerateRandomMatrixDeterministic(t *testing.T) {
+	rng1 := common.NewRand(common.GeneratorLCG, 42)
+	rng2 := common.NewRand(common.GeneratorLCG, 42)
+
+	matrix1 := generateRandomMatrix(3, &rng1)
+	matrix2 := generateRandomMatrix(3, &rng2)
+
+	// Matrices should be identical
+	if !matricesApproximatelyEqual(matrix1, matrix2, 0) {
+		t.Error("Generated matrices should be identical with same seed")
+	}
+}
+
+func TestGenerateRandomMatrixDifferentSeeds(t *testing.T) {
+	rng1 := common.NewRand(common.GeneratorLCG, 42)
+	rng2 := common.NewRand(common.GeneratorLCG, 123)
+
+	matrix1 := generateRandomMatrix(3, &rng1)
+	matrix2 := generateRandomMatrix(3, &rng2)
+
+	// Matrices should be different
+	if matricesApproximatelyEqual(matrix1, matrix2, 0) {
+		t.Error("Matrices with different seeds should be different")
+	}
+}
+
+// Hash tests
+
+func TestRoundFloat32ToPrecision(t *testing.T) {
+	// Test basic rounding
+	if roundFloat32ToPrecision(1.234567, 6) != 1234567 {
+		t.Error("Basic rounding failed")
+	}
+	if roundFloat32ToPrecision(-1.234567, 6) != -1234567 {
+		t.Error("Negative rounding failed")
+	}
+	if roundFloat32ToPrecision(0.0, 6) != 0 {
+		t.Error("Zero rounding failed")
+	}
+
+	// Test rounding with different precisions
+	if roundFloat32ToPrecision(1.23456789, 4) != 12346 {
+		t.Error("Precision 4 rounding failed")
+	}
+}
+
+func TestFnv1aHashMatrixConsistency(t *testing.T) {
+	matrix1 := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+
+	matrix2 := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+
+	hash1 := fnv1aHashMatrix(matrix1)
+	hash2 := fnv1aHashMatrix(matrix2)
+
+	if hash1 != hash2 {
+		t.Error("Same matrices should produce same hash")
+	}
+}
+
+func TestFnv1aHashMatrixDifferent(t *testing.T) {
+	matrix1 := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+
+	matrix2 := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.1}, // Small difference
+	}
+
+	hash1 := fnv1aHashMatrix(matrix1)
+	hash2 := fnv1aHashMatrix(matrix2)
+
+	if hash1 == hash2 {
+		t.Error("Different matrices should produce different hashes")
+	}
+}
+
+func TestFnv1aHashEmptyMatrix(t *testing.T) {
+	var emptyMatrix [][]float32
+	hash := fnv1aHashMatrix(emptyMatrix)
+
+	// Empty matrix should produce the FNV offset basis
+	if hash != common.FNVOffsetBasis {
+		t.Errorf("Empty matrix should hash to offset basis (%d), got %d", common.FNVOffsetBasis, hash)
+	}
+}
+
+func TestHashMatrixAllocations(t *testing.T) {
+	matrix := make([][]float32, 64)
+	bytes := make([]byte, 0, 64*64*4)
+	for i := range matrix {
+		matrix[i] = make([]float32, 64)
+		for j := range matrix[i] {
+			matrix[i][j] = float32(i*64+j)/7 - 300
+			bytes = bytes[:len(bytes)+4]
+			common.PutInt32LE(bytes[len(bytes)-4:], roundFloat32ToPrecision(matrix[i][j], PrecisionDigits))
+		}
+	}
+
+	// Folding each element's four bytes inline hashes as walking its bytes does
+	if hash, expected := fnv1aHashMatrix(matrix), common.HashBytes(common.FNVOffsetBasis, bytes); hash != expected {
+		t.Errorf("Inline hash %#x should match the byte-wise hash %#x", hash, expected)
+	}
+
+	// Verification hashes every element, so it must not allocate per element
+	hashes := map[string]func(){
+		"fnv1a":   func() { fnv1aHashMatrix(matrix) },
+		"fnv1a64": func() { fnv1a64HashMatrix(matrix) },
+		"xxh32":   func() { xxh32HashMatrix(matrix) },
+	}
+	for name, hash := range hashes {
+		if allocs := testing.AllocsPerRun(10, hash); allocs != 0 {
+			t.Errorf("%s hashing should not allocate, got %v allocations", name, allocs)
+		}
+	}
+}
+
+func TestHashOrderSensitivity(t *testing.T) {
+	// Test that element order matters for hash
+	matrix1 := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+
+	matrix2 := [][]float32{
+		{1.0, 3.0},
+		{2.0, 4.0},
+	}
+
+	hash1 := fnv1aHashMatrix(matrix1)
+	hash2 := fnv1aHashMatrix(matrix2)
+
+	if hash1 == hash2 {
+		t.Error("Different element orders should produce different hashes")
+	}
+}
+
+// Validation tests
+
+func TestValidateParametersValid(t *testing.T) {
+	// Test valid small matrix
+	params := &MatrixMulParams{
+		Dimension: 10,
+		Seed:      12345,
+	}
+	if !validateParameters(params) {
+		t.Error("Valid small parameters should pass")
+	}
+
+	// Test valid medium matrix
+	params = &MatrixMulParams{
+		Dimension: 100,
+		Seed:      0, // Seed 0 should be valid
+	}
+	if !validateParameters(params) {
+		t.Error("Valid medium parameters should pass")
+	}
+
+	// Test valid large matrix (but within limits)
+	params = &MatrixMulParams{
+		Dimension: 1000,
+		Seed:      math.MaxUint32, // Max seed should be valid
+	}
+	if !validateParameters(params) {
+		t.Error("Valid large parameters should pass")
+	}
+}
+
+func TestValidateParametersInvalid(t *testing.T) {
+	// Test zero dimension
+	params := &MatrixMulParams{
+		Dimension: 0,
+		Seed:      12345,
+	}
+	if validateParameters(params) {
+		t.Error("Zero dimension should be invalid")
+	}
+
+	// Test dimension too large
+	params = &MatrixMulParams{
+		Dimension: MaxMatrixDimension + 1,
+		Seed:      12345,
+	}
+	if validateParameters(params) {
+		t.Error("Too large dimension should be invalid")
+	}
+
+	// Test dimension that would cause memory overflow
+	params = &MatrixMulParams{
+		Dimension: math.MaxUint32, // Would overflow in calculations
+		Seed:      12345,
+	}
+	if validateParameters(params) {
+		t.Error("Overflow-causing dimension should be invalid")
+	}
+}
+
+func TestValidateParametersMemoryLimits(t *testing.T) {
+	// Test dimension that would exceed memory limit
+	// 2000x2000 matrices use ~48MB total, should be OK
+	params := &MatrixMulParams{
+		Dimension: 2000,
+		Seed:      12345,
+	}
+	if !validateParameters(params) {
+		t.Error("2000x2000 should be within limits")
+	}
+
+	// Larger dimensions should be rejected
+	params = &MatrixMulParams{
+		Dimension: 3000, // 3000x3000 would use ~108MB per matrix, 324MB total
+		Seed:      12345,
+	}
+	if validateParameters(params) {
+		t.Error("3000x3000 should exceed memory limits")
+	}
+}
+
+func TestValidateParametersEdgeCases(t *testing.T) {
+	// Test dimension 1 (should be valid)
+	params := &MatrixMulParams{
+		Dimension: 1,
+		Seed:      12345,
+	}
+	if !validateParameters(params) {
+		t.Error("1x1 matrix should be valid")
+	}
+
+	// Test exactly at limit
+	params = &MatrixMulParams{
+		Dimension: MaxMatrixDimension,
+		Seed:      12345,
+	}
+	if !validateParameters(params) {
+		t.Error("Exactly at max dimension should be valid")
+	}
+}
+
+// WebAssembly interface tests
+
+func TestRunTaskInterface(t *testing.T) {
+	// Test WebAssembly interface compatibility
+	params := MatrixMulParams{Dimension: 4, Seed: 12345}
+	paramsPtr := uintptr(unsafe.Pointer(&params))
+
+	hashResult := RunTask(paramsPtr)
+
+	if hashResult == 0 {
+		t.Error("Should return valid hash")
+	}
+
+	// Same parameters should produce same hash
+	hashResult2 := RunTask(paramsPtr)
+	if hashResult != hashResult2 {
+		t.Error("Same parameters should produce same hash")
+	}
+}
+
+func TestRunTaskNullPointer(t *testing.T) {
+	// Test null pointer handling
+	hashResult := RunTask(0)
+
+	if hashResult != 0 {
+		t.Error("Null pointer should return 0")
+	}
+}
+
+func TestRunTaskInvalidParams(t *testing.T) {
+	// Test invalid parameters
+	params := MatrixMulParams{Dimension: 0, Seed: 12345}
+	paramsPtr := uintptr(unsafe.Pointer(&params))
+
+	hashResult := RunTask(paramsPtr)
+
+	if hashResult != 0 {
+		t.Error("Invalid parameters should return 0")
+	}
+}
+
+func TestResolveScale(t *testing.T) {
+	custom := MatrixMulParams{Dimension: 7, Seed: 42}
+	resolved, ok := resolveScale(custom)
+	if !ok || resolved != custom {
+		t.Errorf("Custom scale should keep raw dimension, got %+v", resolved)
+	}
+
+	for scale := common.ScaleMicro; scale <= common.ScaleLarge; scale++ {
+		resolved, ok := resolveScale(MatrixMulParams{Dimension: 7, Seed: 42, Scale: scale})
+		if !ok {
+			t.Errorf("Scale %d should resolve", scale)
+			continue
+		}
+		if resolved.Dimension != ScaleDimensions[scale] || resolved.Seed != 42 {
+			t.Errorf("Scale %d resolved to %+v", scale, resolved)
+		}
+		if !validateParameters(&resolved) {
+			t.Errorf("Scale %d preset should pass validation", scale)
+		}
+	}
+
+	if _, ok := resolveScale(MatrixMulParams{Dimension: 4, Scale: common.ScaleLarge + 1}); ok {
+		t.Error("Unknown scale tier should be rejected")
+	}
+}
+
+func TestRunTaskScalePreset(t *testing.T) {
+	preset := MatrixMulParams{Dimension: 0, Seed: 12345, Scale: common.ScaleMicro}
+	explicit := MatrixMulParams{Dimension: 64, Seed: 12345}
+
+	presetHash := RunTask(uintptr(unsafe.Pointer(&preset)))
+	explicitHash := RunTask(uintptr(unsafe.Pointer(&explicit)))
+
+	if presetHash == 0 || presetHash != explicitHash {
+		t.Errorf("Micro preset should match explicit 64x64: %d != %d", presetHash, explicitHash)
+	}
+}
+
+func TestGenerateFlatMatrixMatchesNested(t *testing.T) {
+	rngNested, rngFlat := common.NewRand(common.GeneratorLCG, 99), common.NewRand(common.GeneratorLCG, 99)
+	nested := generateRandomMatrix(5, &rngNested)
+	flat := generateFlatMatrix(5, &rngFlat)
+
+	if rngNested.Next() != rngFlat.Next() {
+		t.Error("Flat and nested generation should consume the same random stream")
+	}
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 5; j++ {
+			if nested[i][j] != flat.data[i*5+j] {
+				t.Fatalf("Element [%d][%d] differs: %f != %f", i, j, nested[i][j], flat.data[i*5+j])
+			}
+		}
+	}
+}
+
+func TestComputeProfileMatchesBlockProduct(t *testing.T) {
+	// Repeating the block product must leave exactly one A × B in the result
+	params := MatrixMulParams{Dimension: 40, Seed: 7, Profile: common.ProfileCompute}
+
+	rng := common.NewRand(params.Generator, common.JoinSeed(params.Seed, params.SeedHigh))
+	a := generateRandomMatrix(ComputeBlockDimension, &rng)
+	b := generateRandomMatrix(ComputeBlockDimension, &rng)
+	expected := fnv1aHashMatrix(matrixMultiply(a, b))
+
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != expected {
+		t.Errorf("Compute profile hash %d, expected block product hash %d", hash, expected)
+	}
+}
+
+func TestMemoryProfileMatchesMatrixVectorProduct(t *testing.T) {
+	params := MatrixMulParams{Dimension: 6, Seed: 11, Profile: common.ProfileMemory}
+
+	rng := common.NewRand(params.Generator, common.JoinSeed(params.Seed, params.SeedHigh))
+	a := generateRandomMatrix(6, &rng)
+	x := generateRandomVector(6, &rng)
+	y := make([]float32, 6)
+	for i := range y {
+		for j := range x {
+			y[i] += a[i][j] * x[j]
+		}
+	}
+
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != fnv1aHashValues(common.FNVOffsetBasis, y) {
+		t.Errorf("Memory profile hash %d does not match reference matrix-vector product", hash)
+	}
+}
+
+func TestRunTaskInvalidProfile(t *testing.T) {
+	params := MatrixMulParams{Dimension: 4, Seed: 1, Profile: common.ProfileMemory + 1}
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != 0 {
+		t.Error("Unknown profile should return 0")
+	}
+}
+
+func TestCalibrateWorkload(t *testing.T) {
+	params := MatrixMulParams{Dimension: 10, Seed: 1}
+	if got, factor := calibrateWorkload(params); got != params || factor != 1 {
+		t.Errorf("Calibration should be disabled without a target, got %+v factor %d", got, factor)
+	}
+
+	// 10³ = 1000 multiply-adds; a 5k target needs 20³ = 8000
+	params.TargetWork = 5
+	got, factor := calibrateWorkload(params)
+	if got.Dimension != 20 || factor != 2 {
+		t.Errorf("Calibration chose dimension %d factor %d, expected 20 factor 2", got.Dimension, factor)
+	}
+
+	// Doubling stops before exceeding the validation limits
+	params.TargetWork = math.MaxUint32
+	got, factor = calibrateWorkload(params)
+	if got.Dimension != 1280 || factor != 128 {
+		t.Errorf("Calibration should stop at the dimension limit, got %d factor %d", got.Dimension, factor)
+	}
+}
+
+func TestRunTaskReportsScaleFactor(t *testing.T) {
+	params := MatrixMulParams{Dimension: 3, Seed: 5, TargetWork: 1}
+	scaled := MatrixMulParams{Dimension: 12, Seed: 5}
+
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if factor := GetScaleFactor(); factor != 4 {
+		t.Errorf("Expected scale factor 4, got %d", factor)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&scaled))); hash != expected {
+		t.Errorf("Calibrated run should match explicit 12x12: %d != %d", hash, expected)
+	}
+	if factor := GetScaleFactor(); factor != 1 {
+		t.Errorf("Uncalibrated run should report factor 1, got %d", factor)
+	}
+}
+
+func TestRunTaskWarmupIterations(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		cold := MatrixMulParams{Dimension: 8, Seed: 21, Profile: profile}
+		warm := cold
+		warm.WarmupIterations = 3
+
+		coldHash := RunTask(uintptr(unsafe.Pointer(&cold)))
+		if warmHash := RunTask(uintptr(unsafe.Pointer(&warm))); warmHash != coldHash {
+			t.Errorf("Profile %d: warm-up should not change the hash: %d != %d", profile, warmHash, coldHash)
+		}
+	}
+
+	params := MatrixMulParams{Dimension: 8, Seed: 21, WarmupIterations: common.MaxWarmupIterations + 1}
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != 0 {
+		t.Error("Warm-up iterations above the limit should be rejected")
+	}
+}
+
+func TestGetWorkMetrics(t *testing.T) {
+	tests := []struct {
+		profile  uint32
+		elements uint64
+		bytes    uint64
+	}{
+		{common.ProfileDefault, 16, 4 * (2*16 + 64)},
+		{common.ProfileCompute, 1024, 4 * (2*1024 + 32768)},
+		{common.ProfileMemory, 16, 4 * 4 * (16 + 8)},
+	}
+
+	for _, test := range tests {
+		params := MatrixMulParams{Dimension: 4, Seed: 3, Profile: test.profile, WarmupIterations: 1}
+		RunTask(uintptr(unsafe.Pointer(&params)))
+
+		metrics := (*common.WorkMetrics)(unsafe.Pointer(GetWorkMetrics()))
+		if metrics.ElementsProcessed != test.elements || metrics.BytesTouched != test.bytes {
+			t.Errorf("Profile %d: got %+v, expected elements=%d bytes=%d",
+				test.profile, *metrics, test.elements, test.bytes)
+		}
+	}
+
+	RunTask(0)
+	if metrics := (*common.WorkMetrics)(unsafe.Pointer(GetWorkMetrics())); *metrics != (common.WorkMetrics{}) {
+		t.Errorf("Failed runs should clear work metrics, got %+v", *metrics)
+	}
+}
+
+func TestVerificationLevels(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 48, Seed: 7, Profile: profile}
+		hashed := RunTask(uintptr(unsafe.Pointer(&params)))
+		if hashed == 0 {
+			t.Fatalf("Profile %d: hashed run failed", profile)
+		}
+
+		params.Verification = common.VerifyFull
+		if full := RunTask(uintptr(unsafe.Pointer(&params))); full != hashed {
+			t.Errorf("Profile %d: full verification should return the same hash: %d != %d", profile, full, hashed)
+		}
+
+		params.Verification = common.VerifyNone
+		if unverified := RunTask(uintptr(unsafe.Pointer(&params))); unverified == hashed {
+			t.Errorf("Profile %d: unverified run should not compute the hash", profile)
+		}
+	}
+
+	params := MatrixMulParams{Dimension: 8, Verification: common.VerifyFull + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown verification level should be rejected")
+	}
+}
+
+func TestProductRowSumsMatch(t *testing.T) {
+	rng := common.NewRand(common.GeneratorLCG, 99)
+	a := generateFlatMatrix(40, &rng)
+	b := generateFlatMatrix(40, &rng)
+	c := newMatrix(40)
+	multiplyAccumulate(&a, &b, &c)
+
+	if !productRowSumsMatch(&a, &b, &c) {
+		t.Fatal("Correct product should pass the row-sum check")
+	}
+
+	c.data[5*40+3] += 1
+	if productRowSumsMatch(&a, &b, &c) {
+		t.Error("Corrupted product should fail the row-sum check")
+	}
+
+	x := generateRandomVector(40, &rng)
+	y := make([]float32, 40)
+	for i := 0; i < 40; i++ {
+		for j := 0; j < 40; j++ {
+			y[i] += a.data[i*40+j] * x[j]
+		}
+	}
+	if !matrixVectorSumMatches(&a, x, y) {
+		t.Fatal("Correct matrix-vector product should pass the sum check")
+	}
+	y[0] += 1
+	if matrixVectorSumMatches(&a, x, y) {
+		t.Error("Corrupted matrix-vector product should fail the sum check")
+	}
+}
+
+func TestParamsFingerprint(t *testing.T) {
+	// Documented layout: eleven consecutive u32 fields, 44 bytes
+	layout := []uint32{0, 4, 4, 4, 8, 4, 12, 4, 16, 4, 20, 4, 24, 4, 28, 4, 32, 4, 36, 4, 40, 4, 44}
+	if got, want := ParamsFingerprint(), common.LayoutFingerprint(layout); got != want {
+		t.Errorf("Params fingerprint %d does not match the documented layout %d", got, want)
+	}
+}
+
+func TestGetLimits(t *testing.T) {
+	limits := (*Limits)(unsafe.Pointer(GetLimits()))
+
+	if limits.WordCount != 10 {
+		t.Errorf("Expected 10 limit words after WordCount, got %d", limits.WordCount)
+	}
+
+	// The host generator is only available with a random source
+	common.SetHostRandom(func() uint32 { return 0 })
+	defer common.SetHostRandom(nil)
+
+	// The reported bounds are inclusive: the limit passes, one past it fails
+	params := MatrixMulParams{Dimension: limits.MaxMatrixDimension, Profile: limits.MaxProfile,
+		WarmupIterations: limits.MaxWarmupIterations, Verification: limits.MaxVerification,
+		Allocator: limits.MaxAllocator, HashAlgorithm: limits.MaxHashAlgorithm, Generator: limits.MaxGenerator}
+	if !validateParameters(&params) {
+		t.Error("Parameters at their reported limits should be accepted")
+	}
+	params.Dimension++
+	if validateParameters(&params) {
+		t.Error("Dimension beyond MaxMatrixDimension should be rejected")
+	}
+
+	if _, ok := resolveScale(MatrixMulParams{Scale: limits.MaxScale}); !ok {
+		t.Error("MaxScale should be a valid tier")
+	}
+	if _, ok := resolveScale(MatrixMulParams{Scale: limits.MaxScale + 1}); ok {
+		t.Error("Scale beyond MaxScale should be rejected")
+	}
+
+	if Alloc(limits.MaxAllocationSize+1) != 0 {
+		t.Error("Allocation beyond MaxAllocationSize should fail")
+	}
+}
+
+func TestDeallocReleasesAllocation(t *testing.T) {
+	before, _ := common.LiveAllocations()
+
+	ptr := Alloc(64)
+	if ptr == 0 {
+		t.Fatal("Alloc(64) should succeed")
+	}
+	if live, _ := common.LiveAllocations(); live != before+1 {
+		t.Errorf("alloc should pin its buffer, %d live allocations (expected %d)", live, before+1)
+	}
+
+	Dealloc(ptr)
+	Dealloc(ptr) // Repeated frees are ignored
+	if live, _ := common.LiveAllocations(); live != before {
+		t.Errorf("dealloc should unpin the buffer, %d live allocations (expected %d)", live, before)
+	}
+}
+
+func TestArenaAllocator(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 24, Seed: 5, Profile: profile}
+		heapHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+		params.Allocator = common.AllocatorArena
+		if arenaHash := RunTask(uintptr(unsafe.Pointer(&params))); arenaHash != heapHash {
+			t.Errorf("Profile %d: arena allocation should not change the hash: %d != %d", profile, arenaHash, heapHash)
+		}
+		if scratchArena.Used() == 0 {
+			t.Errorf("Profile %d: arena run should allocate from the arena", profile)
+		}
+		if matrixArena != nil {
+			t.Errorf("Profile %d: arena should be detached after the run", profile)
+		}
+	}
+
+	ResetArena()
+	if scratchArena.Used() != 0 {
+		t.Error("reset_arena should rewind the arena")
+	}
+
+	params := MatrixMulParams{Dimension: 4, Allocator: common.AllocatorArena + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Error("Unknown allocator should be rejected")
+	}
+}
+
+func TestRunTaskV2Status(t *testing.T) {
+	params := MatrixMulParams{Dimension: 8, Seed: 5}
+	// Module memory, as a host would pass it; a Go stack address would move as run_task grows the stack
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TaskResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TaskResult)(unsafe.Pointer(resultPtr))
+	status := RunTaskV2(uintptr(unsafe.Pointer(&params)), resultPtr)
+	if status != common.StatusOK || result.Status != common.StatusOK {
+		t.Fatalf("Valid run should report StatusOK, got %d (result %d)", status, result.Status)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_v2 hash %d should match run_task %d", result.Hash, expected)
+	}
+
+	tests := []struct {
+		name     string
+		params   MatrixMulParams
+		expected uint32
+	}{
+		{"zero dimension", MatrixMulParams{}, common.StatusInvalidParams},
+		{"unknown scale", MatrixMulParams{Scale: common.ScaleLarge + 1}, common.StatusInvalidParams},
+		{"unknown profile", MatrixMulParams{Dimension: 4, Profile: common.ProfileMemory + 1}, common.StatusInvalidParams},
+		{"oversized matrix", MatrixMulParams{Dimension: MaxMatrixDimension + 1}, common.StatusOverflow},
+		{"too many warm-ups", MatrixMulParams{Dimension: 4, WarmupIterations: common.MaxWarmupIterations + 1}, common.StatusOverflow},
+	}
+	for _, tt := range tests {
+		*result = common.TaskResult{Hash: 1}
+		if status := RunTaskV2(uintptr(unsafe.Pointer(&tt.params)), resultPtr); status != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, status)
+		}
+		if result.Status != tt.expected || result.Hash != 0 {
+			t.Errorf("%s: result should be {%d, 0}, got %+v", tt.name, tt.expected, *result)
+		}
+	}
+
+	if status := RunTaskV2(0, resultPtr); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+	if status := RunTaskV2(uintptr(unsafe.Pointer(&params)), 0); status != common.StatusOK {
+		t.Errorf("Null result pointer should still report the status, got %d", status)
+	}
+}
+
+func TestLastErrorMessage(t *testing.T) {
+	params := MatrixMulParams{Dimension: 4, Verification: common.VerifyFull + 1}
+	if result := RunTask(uintptr(unsafe.Pointer(&params))); result != 0 {
+		t.Fatal("Invalid params should be rejected")
+	}
+	message := unsafe.String((*byte)(unsafe.Pointer(GetLastErrorPtr())), GetLastErrorLen())
+	if message != "unknown verification level" {
+		t.Errorf("Unexpected error message %q", message)
+	}
+
+	params = MatrixMulParams{Dimension: 4}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if GetLastErrorLen() != 0 {
+		t.Errorf("A successful run should clear the error, got %q", common.LastError())
+	}
+}
+
+func TestGetTaskInfo(t *testing.T) {
+	ptr := GetTaskInfo()
+	length := *(*uint32)(unsafe.Pointer(ptr))
+	blob := unsafe.Slice((*byte)(unsafe.Pointer(ptr+4)), length)
+
+	var info struct {
+		Task       string `json:"task"`
+		ABIVersion uint32 `json:"abi_version"`
+		ParamsSize uint32 `json:"params_size"`
+		Params     []struct {
+			Name   string `json:"name"`
+			Offset uint32 `json:"offset"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(blob, &info); err != nil {
+		t.Fatalf("Task info is not valid JSON: %v\n%s", err, blob)
+	}
+
+	if info.Task != "matrix_mul" || info.ABIVersion != common.ABIVersion || info.ParamsSize != 44 {
+		t.Errorf("Unexpected task info header: %+v", info)
+	}
+	if ABIVersion() != info.ABIVersion {
+		t.Errorf("abi_version() = %d, task info reports %d", ABIVersion(), info.ABIVersion)
+	}
+	if len(info.Params) != 11 || info.Params[10].Name != "seed_high" || info.Params[10].Offset != 40 {
+		t.Errorf("Unexpected params schema: %+v", info.Params)
+	}
+}
+
+func TestRunTaskTimed(t *testing.T) {
+	// Module memory, as a host would pass it
+	resultPtr := Alloc(uint32(unsafe.Sizeof(common.TimedResult{})))
+	defer Dealloc(resultPtr)
+	result := (*common.TimedResult)(unsafe.Pointer(resultPtr))
+
+	params := MatrixMulParams{Dimension: 32, Seed: 5}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status != common.StatusOK {
+		t.Fatalf("Valid run should report StatusOK, got %d", status)
+	}
+	if expected := RunTask(uintptr(unsafe.Pointer(&params))); result.Hash != expected {
+		t.Errorf("run_task_timed hash %d should match run_task %d", result.Hash, expected)
+	}
+	if result.ElapsedMs <= 0 {
+		t.Errorf("Measured run should take time, got %f ms", result.ElapsedMs)
+	}
+
+	params = MatrixMulParams{}
+	if status := RunTaskTimed(uintptr(unsafe.Pointer(&params)), resultPtr); status == common.StatusOK {
+		t.Error("Invalid params should be rejected")
+	}
+	if result.ElapsedMs != 0 {
+		t.Errorf("Rejected run should not report a duration, got %f ms", result.ElapsedMs)
+	}
+}
+
+func TestGetMemoryStats(t *testing.T) {
+	before := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+	params := MatrixMulParams{Dimension: 16, Seed: 5}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	after := *(*common.MemoryStats)(unsafe.Pointer(GetMemoryStats()))
+
+	if after.TotalAlloc <= before.TotalAlloc || after.Mallocs <= before.Mallocs {
+		t.Errorf("A run should allocate: before %+v, after %+v", before, after)
+	}
+}
+
+func TestEncodedParams(t *testing.T) {
+	params := MatrixMulParams{Dimension: 12, Seed: 9}
+	rawHash := RunTask(uintptr(unsafe.Pointer(&params)))
+
+	encoded := common.EncodeParams(unsafe.Pointer(&params), ParamFields())
+	if hash := RunTask(uintptr(unsafe.Pointer(&encoded[0]))); hash != rawHash {
+		t.Errorf("Encoded params should match the raw struct: %d != %d", hash, rawHash)
+	}
+
+	// Trailing fields left out of the payload take their zero defaults
+	short := append([]byte(nil), encoded[:common.ParamsHeaderSize+8]...)
+	common.PutUint32LE(short[8:], 8)
+	if hash := RunTask(uintptr(unsafe.Pointer(&short[0]))); hash != rawHash {
+		t.Errorf("Short payload should default the trailing fields: %d != %d", hash, rawHash)
+	}
+
+	common.PutUint32LE(encoded[4:], common.ParamsVersion+1)
+	if status := RunTaskV2(uintptr(unsafe.Pointer(&encoded[0])), 0); status != common.StatusInvalidParams {
+		t.Errorf("Unknown encoding version should be rejected, got status %d", status)
+	}
+}
+
+func TestValidateParamsExport(t *testing.T) {
+	params := MatrixMulParams{Dimension: 8, Seed: 1}
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	metrics := lastWorkMetrics
+
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("Valid params should report StatusOK, got %d", status)
+	}
+
+	bad := MatrixMulParams{Dimension: MaxMatrixDimension + 1}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&bad))); status != common.StatusOverflow {
+		t.Errorf("Expected status %d, got %d", common.StatusOverflow, status)
+	}
+	if common.LastError() != "dimension exceeds the maximum matrix dimension" || GetErrorCode() != common.ErrTooLarge {
+		t.Errorf("Unexpected rejection reason %q (code %d)", common.LastError(), GetErrorCode())
+	}
+	if status := ValidateParams(0); status != common.StatusInvalidParams {
+		t.Errorf("Null params should report StatusInvalidParams, got %d", status)
+	}
+	if GetErrorCode() != common.ErrNullParams {
+		t.Errorf("Null params should report ErrNullParams, got %d", GetErrorCode())
+	}
+
+	// Validation never runs the workload
+	if lastWorkMetrics != metrics {
+		t.Error("validate_params should not touch the last run's work metrics")
+	}
+}
+
+func TestRunTask64(t *testing.T) {
+	rng := common.NewRand(common.GeneratorLCG, 23)
+	a := generateRandomMatrix(10, &rng)
+	b := generateRandomMatrix(10, &rng)
+	c := createZeroMatrix(10)
+	naiveTripleLoopMultiply(a, b, c)
+
+	params := MatrixMulParams{Dimension: 10, Seed: 23}
+	if got, want := RunTask64(uintptr(unsafe.Pointer(&params))), fnv1a64HashMatrix(c); got != want {
+		t.Errorf("run_task64 = %#x, expected %#x", got, want)
+	}
+	if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash != fnv1aHashMatrix(c) {
+		t.Errorf("run_task should keep the 32-bit hash, got %d", hash)
+	}
+
+	for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 16, Seed: 4, Profile: profile}
+		first := RunTask64(uintptr(unsafe.Pointer(&params)))
+		if first == 0 || RunTask64(uintptr(unsafe.Pointer(&params))) != first {
+			t.Errorf("Profile %d: run_task64 should be a deterministic non-zero hash", profile)
+		}
+	}
+
+	params.Verification = common.VerifyNone
+	if got := RunTask64(uintptr(unsafe.Pointer(&params))); got != uint64(checksumMatrix(c)) {
+		t.Errorf("Checksum level should return the widened checksum, got %#x", got)
+	}
+}
+
+func TestRunTaskPacked(t *testing.T) {
+	params := MatrixMulParams{Dimension: 10, Seed: 23}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOK, hash) {
+		t.Errorf("run_task_packed = %#x, expected status 0 and hash %d", got, hash)
+	}
+
+	params.Dimension = MaxMatrixDimension + 1
+	if got := RunTaskPacked(uintptr(unsafe.Pointer(&params))); got != common.PackResult(common.StatusOverflow, 0) {
+		t.Errorf("Oversized matrix should pack StatusOverflow, got %#x", got)
+	}
+	if got := RunTaskPacked(0); got != common.PackResult(common.StatusInvalidParams, 0) {
+		t.Errorf("Null params should pack StatusInvalidParams, got %#x", got)
+	}
+}
+
+func TestResultBlock(t *testing.T) {
+	block := common.Memory(unsafe.Pointer(GetResultPtr()), common.ResultBlockSize)
+	params := MatrixMulParams{Dimension: 10, Seed: 23}
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block) != common.ResultMagic || common.ReadUint32LE(block[4:]) != common.StatusOK ||
+		common.ReadUint32LE(block[8:]) != hash || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Unexpected result header % x after a run with hash %#x", block[:16], hash)
+	}
+	if common.ReadFloat64LE(block[24:]) != lastElapsedMs || common.ReadUint64LE(block[32:]) != lastWorkMetrics.ElementsProcessed ||
+		common.ReadUint64LE(block[40:]) != lastWorkMetrics.BytesTouched {
+		t.Errorf("Result block should carry the run's duration and work metrics, got % x", block[24:])
+	}
+
+	hash64 := RunTask64(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[12:]) != common.ResultHasHash64 || common.ReadUint64LE(block[16:]) != hash64 {
+		t.Errorf("run_task64 should publish its 64-bit hash %#x, got % x", hash64, block[12:24])
+	}
+
+	params.Dimension = MaxMatrixDimension + 1
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if common.ReadUint32LE(block[4:]) != common.StatusOverflow || common.ReadUint32LE(block[8:]) != 0 || common.ReadUint32LE(block[12:]) != 0 {
+		t.Errorf("Oversized matrix should publish StatusOverflow, got % x", block[:16])
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 10, Seed: 23, Profile: profile}
+		want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+		common.RequestCancel()
+		lastStatus = common.StatusOK
+		if hash := executeWorkload(&params); hash != 0 || lastStatus != common.StatusCancelled {
+			t.Errorf("Profile %d: cancelled run returned %#x with status %d, expected 0 and StatusCancelled", profile, hash, lastStatus)
+		}
+
+		// run_task lowers the flag, so the instance stays usable
+		if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want || lastStatus != common.StatusOK {
+			t.Errorf("Profile %d: run after a cancellation = %#x (status %d), expected %#x", profile, got, lastStatus, want)
+		}
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	defer SetCheckpoints(0)
+	rng := common.NewRand(common.GeneratorLCG, 23)
+	a := generateRandomMatrix(10, &rng)
+	b := generateRandomMatrix(10, &rng)
+	c := createZeroMatrix(10)
+	naiveTripleLoopMultiply(a, b, c)
+
+	params := MatrixMulParams{Dimension: 10, Seed: 23, HashAlgorithm: common.HashXXHash32}
+	SetCheckpoints(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if HashInput() != 0 {
+		t.Errorf("hash_input should be 0 with checkpoints disabled, got %#x", HashInput())
+	}
+
+	SetCheckpoints(1)
+	hash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if got, want := HashInput(), fnv1aUpdateMatrix(fnv1aHashMatrix(a), b); got != want {
+		t.Errorf("hash_input = %#x, expected %#x", got, want)
+	}
+	// The product stage is FNV-1a even when the result is xxHash32
+	if got, ok := common.CheckpointHash(StageProduct); !ok || got != fnv1aHashMatrix(c) {
+		t.Errorf("Product checkpoint = %#x (recorded %v), expected %#x", got, ok, fnv1aHashMatrix(c))
+	}
+	if got, ok := common.CheckpointHash(StageOutput); !ok || got != hash {
+		t.Errorf("Output checkpoint = %#x (recorded %v), expected the result %#x", got, ok, hash)
+	}
+
+	// Every profile records all of its stages
+	for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 16, Seed: 4, Profile: profile}
+		RunTask(uintptr(unsafe.Pointer(&params)))
+		for stage := range StageNames {
+			if _, ok := common.CheckpointHash(uint32(stage)); !ok {
+				t.Errorf("Profile %d: stage %s not recorded", profile, StageNames[stage])
+			}
+		}
+	}
+}
+
+func TestOutput(t *testing.T) {
+	defer SetOutput(0)
+	rng := common.NewRand(common.GeneratorLCG, 23)
+	a := generateRandomMatrix(10, &rng)
+	b := generateRandomMatrix(10, &rng)
+	c := createZeroMatrix(10)
+	naiveTripleLoopMultiply(a, b, c)
+
+	params := MatrixMulParams{Dimension: 10, Seed: 23, WarmupIterations: 1}
+	SetOutput(0)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	if data, _ := common.RunOutput(); len(data) != 0 {
+		t.Errorf("A run with the output disabled kept %d bytes", len(data))
+	}
+
+	// The product, row by row, and not the warm-up run's as well
+	SetOutput(1)
+	RunTask(uintptr(unsafe.Pointer(&params)))
+	data, elementSize := common.RunOutput()
+	if elementSize != OutputElementSize || len(data) != 100*OutputElementSize {
+		t.Fatalf("Output of %d bytes (element size %d), expected the 10x10 product", len(data), elementSize)
+	}
+	for i, row := range c {
+		for j, want := range row {
+			if got := math.Float32frombits(common.ReadUint32LE(data[(i*10+j)*OutputElementSize:])); got != want {
+				t.Fatalf("C[%d][%d]: output %v, expected %v", i, j, got, want)
+			}
+		}
+	}
+
+	// The other profiles keep their product too
+	for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 16, Seed: 4, Profile: profile}
+		RunTask(uintptr(unsafe.Pointer(&params)))
+		if data, _ := common.RunOutput(); len(data) == 0 {
+			t.Errorf("Profile %d kept no output", profile)
+		}
+	}
+}
+
+func TestReserveMemory(t *testing.T) {
+	defer ReserveMemory(0)
+	for _, profile := range []uint32{common.ProfileDefault, common.ProfileCompute, common.ProfileMemory} {
+		for _, verification := range []uint32{common.VerifyNone, common.VerifyHash, common.VerifyFull} {
+			params := MatrixMulParams{Dimension: 12, Seed: 23, Profile: profile, Verification: verification}
+			ReserveMemory(0)
+			want := RunTask(uintptr(unsafe.Pointer(&params)))
+
+			if status := ReserveMemory(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+				t.Fatalf("Profile %d: reserve_memory returned status %d", profile, status)
+			}
+			capacity := scratchArena.Capacity()
+
+			// Reserved runs take every buffer from the arena and row pool
+			if allocs := testing.AllocsPerRun(3, func() { RunTask(uintptr(unsafe.Pointer(&params))) }); allocs != 0 {
+				t.Errorf("Profile %d, verification %d: reserved runs made %v allocations, expected none", profile, verification, allocs)
+			}
+			if got := RunTask(uintptr(unsafe.Pointer(&params))); got != want {
+				t.Errorf("Profile %d, verification %d: reserved run = %#x, expected %#x", profile, verification, got, want)
+			}
+			if scratchArena.Capacity() != capacity || scratchArena.Used() > workingSet(&params) {
+				t.Errorf("Profile %d, verification %d: used %d of %d reserved bytes, arena now %d bytes",
+					profile, verification, scratchArena.Used(), workingSet(&params), scratchArena.Capacity())
+			}
+
+			// Compute blocks have a fixed size, so only the other profiles outgrow it
+			params.Dimension = 24
+			if profile != common.ProfileCompute && ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusOverflow {
+				t.Errorf("Profile %d: a run beyond the reservation should overflow", profile)
+			}
+		}
+	}
+
+	if ReserveMemory(0) != common.StatusOK || common.Reserved() {
+		t.Error("reserve_memory(0) should leave the reserved mode")
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	defer SetMemoryBudget(0)
+	params := MatrixMulParams{Dimension: 256, Seed: 23}
+
+	SetMemoryBudget(0)
+	if GetMaxMemoryPages() != common.MaxWasmPages {
+		t.Errorf("Without a budget memory may grow to %d pages, got %d", common.MaxWasmPages, GetMaxMemoryPages())
+	}
+
+	// A budget below the memory a run needs rejects it before it starts
+	SetMemoryBudget(1)
+	if GetMaxMemoryPages() != 1 {
+		t.Errorf("Expected a 1 page limit, got %d", GetMaxMemoryPages())
+	}
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOverflow {
+		t.Errorf("A 256x256 product should not fit one page, got status %d", status)
+	}
+	if GetErrorCode() != common.ErrTooLarge {
+		t.Errorf("Over-budget params should report ErrTooLarge, got %d", GetErrorCode())
+	}
+	if RunTask(uintptr(unsafe.Pointer(&params))) != 0 || lastStatus != common.StatusOverflow {
+		t.Errorf("An over-budget run should fail with StatusOverflow, got %d", lastStatus)
+	}
+
+	SetMemoryBudget(common.MaxWasmPages)
+	if status := ValidateParams(uintptr(unsafe.Pointer(&params))); status != common.StatusOK {
+		t.Errorf("A 256x256 product should fit the full address space, got status %d", status)
+	}
+}
+
+func TestHashAlgorithm(t *testing.T) {
+	rng := common.NewRand(common.GeneratorLCG, 23)
+	a := generateRandomMatrix(10, &rng)
+	b := generateRandomMatrix(10, &rng)
+	c := createZeroMatrix(10)
+	naiveTripleLoopMultiply(a, b, c)
+
+	params := MatrixMulParams{Dimension: 10, Seed: 23, HashAlgorithm: common.HashXXHash32}
+	if got, want := RunTask(uintptr(unsafe.Pointer(&params))), xxh32HashMatrix(c); got != want {
+		t.Errorf("xxHash32 run = %#x, expected %#x", got, want)
+	}
+	if xxh32HashMatrix(c) == fnv1aHashMatrix(c) {
+		t.Error("xxHash32 and FNV-1a should differ on the same matrix")
+	}
+	if xxh32HashMatrix(c) != xxh32HashValues(flattenMatrix(c).data) {
+		t.Error("Row-wise and flat xxHash32 should hash the same value stream")
+	}
+
+	for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 16, Seed: 4, Profile: profile}
+		fnvHash := RunTask(uintptr(unsafe.Pointer(&params)))
+		params.HashAlgorithm = common.HashXXHash32
+		xxHash := RunTask(uintptr(unsafe.Pointer(&params)))
+		if xxHash == 0 || xxHash == fnvHash || RunTask(uintptr(unsafe.Pointer(&params))) != xxHash {
+			t.Errorf("Profile %d: xxHash32 should be a deterministic hash distinct from FNV-1a", profile)
+		}
+	}
+
+	params.HashAlgorithm = common.HashXXHash32 + 1
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Unknown hash algorithm should be rejected")
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	// PCG32 draws B from its own stream, so it can be generated before A
+	rng := common.NewRand(common.GeneratorPCG32, 23)
+	b := generateRandomMatrix(10, rng.Stream(1))
+	a := generateRandomMatrix(10, rng.Stream(0))
+	c := createZeroMatrix(10)
+	naiveTripleLoopMultiply(a, b, c)
+
+	params := MatrixMulParams{Dimension: 10, Seed: 23, Generator: common.GeneratorPCG32}
+	pcgHash := RunTask(uintptr(unsafe.Pointer(&params)))
+	if pcgHash != fnv1aHashMatrix(c) {
+		t.Errorf("PCG32 run = %d, expected %d", pcgHash, fnv1aHashMatrix(c))
+	}
+
+	for _, profile := range []uint32{common.ProfileCompute, common.ProfileMemory} {
+		params := MatrixMulParams{Dimension: 16, Seed: 23, Profile: profile, Generator: common.GeneratorPCG32}
+		if hash := RunTask(uintptr(unsafe.Pointer(&params))); hash == 0 || RunTask(uintptr(unsafe.Pointer(&params))) != hash {
+			t.Errorf("Profile %d: PCG32 runs should be deterministic", profile)
+		}
+	}
+
+	params.Generator = common.GeneratorLCG
+	if RunTask(uintptr(unsafe.Pointer(&params))) == pcgHash {
+		t.Error("LCG and PCG32 data should give different products")
+	}
+
+	params.Generator = common.GeneratorHost + 1
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Unknown random generator should be rejected")
+	}
+	params.Generator = common.GeneratorHost
+	if ValidateParams(uintptr(unsafe.Pointer(&params))) != common.StatusInvalidParams {
+		t.Error("Host generator without a random source should be rejected")
+	}
+}
+
+func TestHostRandomReplay(t *testing.T) {
+	// A host replaying the LCG sequence reproduces the LCG product
+	lcg := MatrixMulParams{Dimension: 24, Seed: 31}
+	want := RunTask(uintptr(unsafe.Pointer(&lcg)))
+
+	state := uint32(31)
+	common.SetHostRandom(func() uint32 { return common.NextLCG(&state) })
+	defer common.SetHostRandom(nil)
+	host := MatrixMulParams{Dimension: 24, Seed: 5, Generator: common.GeneratorHost}
+	if got := RunTask(uintptr(unsafe.Pointer(&host))); got != want {
+		t.Errorf("Host generator run = %d, expected the LCG run %d", got, want)
+	}
+}
+
+func TestSeedHigh(t *testing.T) {
+	run := func(params MatrixMulParams) uint32 {
+		return RunTask(uintptr(unsafe.Pointer(&params)))
+	}
+
+	// The LCG folds the high word into its 32-bit state
+	wide := run(MatrixMulParams{Dimension: 8, Seed: 23, SeedHigh: 5})
+	if wide != run(MatrixMulParams{Dimension: 8, Seed: 23 ^ 5}) {
+		t.Error("LCG runs should use the low word XOR the high word")
+	}
+	if wide == run(MatrixMulParams{Dimension: 8, Seed: 23}) {
+		t.Error("A non-zero high word should change the LCG data")
+	}
+
+	// PCG32 is seeded with all 64 bits
+	pcg := run(MatrixMulParams{Dimension: 8, Seed: 23, SeedHigh: 5, Generator: common.GeneratorPCG32})
+	if pcg == run(MatrixMulParams{Dimension: 8, Seed: 23 ^ 5, Generator: common.GeneratorPCG32}) {
+		t.Error("PCG32 runs should not fold the seed")
+	}
+}
+
+func TestPanicExports(t *testing.T) {
+	// A normal run leaves the panic buffer empty; common tests cover recovery itself
+	params := MatrixMulParams{Dimension: 4, Seed: 1}
+	if RunTask(uintptr(unsafe.Pointer(&params))) == 0 || lastStatus != common.StatusOK {
+		t.Fatalf("Valid run failed with status %d", lastStatus)
+	}
+	if GetPanicPtr() != common.PanicMessagePtr() || GetPanicLen() != 0 {
+		t.Errorf("Expected an empty panic buffer, got %d bytes", GetPanicLen())
+	}
+}
+
+// Utility tests
+
+func TestMatricesApproximatelyEqual(t *testing.T) {
+	a := [][]float32{
+		{1.0, 2.0},
+		{3.0, 4.0},
+	}
+	b := [][]float32{
+		{1.0000001, 2.0000001},
+		{3.0000001, 4.0000001},
+	}
+
+	if !matricesApproximatelyEqual(a, b, 1e-5) {
+		t.Error("Matrices with small differences should be approximately equal")
+	}
+
+	if matricesApproximatelyEqual(a, b, 1e-8) {
+		t.Error("Matrices should not be equal with very small tolerance")
+	}
+}
+
+// Reference hashes: data/reference_hashes/matrix_mul.json and its copy in testdata are written by cmd/genrefs (go generate)
+
+func computeReferenceHash(params MatrixMulParams) uint32 {
+	if !validateParameters(&params) {
+		return 0 // Invalid parameters
+	}
+
+	// Generate two random matrices A and B
+	rng := common.NewRand(params.Generator, common.JoinSeed(params.Seed, params.SeedHigh))
+	matrixA := generateRandomMatrix(int(params.Dimension), &rng)
+	matrixB := generateRandomMatrix(int(params.Dimension), &rng)
+
+	// Initialize result matrix C with zeros
+	matrixC := createZeroMatrix(int(params.Dimension))
+
+	// Perform matrix multiplication: C = A * B
+	naiveTripleLoopMultiply(matrixA, matrixB, matrixC)
+
+	// Compute and return FNV-1a hash of result matrix
+	return fnv1aHashMatrix(matrixC)
+}
+
+func TestComputeReferenceHashDeterministic(t *testing.T) {
+	params := MatrixMulParams{Dimension: 4, Seed: 12345}
+
+	hash1 := computeReferenceHash(params)
+	hash2 := computeReferenceHash(params)
+
+	if hash1 != hash2 {
+		t.Error("Reference hash should be deterministic")
+	}
+	if hash1 == 0 {
+		t.Error("Reference hash should not be zero")
+	}
+}
+
+func TestInvalidParamsZeroHash(t *testing.T) {
+	invalidParams := MatrixMulParams{Dimension: 0, Seed: 12345}
+	hash := computeReferenceHash(invalidParams)
+
+	if hash != 0 {
+		t.Error("Invalid parameters should produce zero hash")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if status := SelfTest(); status != common.StatusOK {
+		t.Fatalf("self_test = %d: %s", status, common.LastError())
+	}
+
+	// A vector whose hash no longer matches fails the test and is named
+	defer func(saved []common.KnownAnswer[MatrixMulParams]) { selfTestVectors = saved }(selfTestVectors)
+	selfTestVectors = append([]common.KnownAnswer[MatrixMulParams](nil), selfTestVectors...)
+	broken := &selfTestVectors[len(selfTestVectors)-1]
+	broken.Hash++
+	if status := SelfTest(); status != common.StatusVerificationFailed {
+		t.Errorf("self_test with a wrong hash = %d, expected StatusVerificationFailed", status)
+	}
+	if !strings.Contains(common.LastError(), broken.Name) {
+		t.Errorf("self_test error %q does not name vector %s", common.LastError(), broken.Name)
+	}
+}
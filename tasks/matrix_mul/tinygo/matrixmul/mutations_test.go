@@ -0,0 +1,58 @@
+package matrixmul
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"wasmbench/common"
+	"wasmbench/common/mutation"
+)
+
+// TestHashMutations checks that every hash algorithm, nested and flat, folds
+// in each element of the product and where it is
+func TestHashMutations(t *testing.T) {
+	const dimension = 4
+	rng := common.NewRand(common.GeneratorLCG, 42)
+	product := generateRandomMatrix(dimension, &rng)
+	clone := func(m [][]float32) [][]float32 {
+		rows := make([][]float32, len(m))
+		for i, row := range m {
+			rows[i] = slices.Clone(row)
+		}
+		return rows
+	}
+	flat := func(m [][]float32) []float32 { return slices.Concat(m...) }
+	hashes := []mutation.Hash[[][]float32]{
+		{Name: "fnv1a", Sum: func(m [][]float32) uint64 { return uint64(fnv1aHashMatrix(m)) }},
+		{Name: "fnv1a64", Sum: func(m [][]float32) uint64 { return fnv1a64HashMatrix(m) }},
+		{Name: "xxh32", Sum: func(m [][]float32) uint64 { return uint64(xxh32HashMatrix(m)) }},
+		{Name: "flat fnv1a", Sum: func(m [][]float32) uint64 { return uint64(fnv1aHashValues(common.FNVOffsetBasis, flat(m))) }},
+		{Name: "flat fnv1a64", Sum: func(m [][]float32) uint64 { return fnv1a64HashValues(common.FNV64OffsetBasis, flat(m)) }},
+		{Name: "flat xxh32", Sum: func(m [][]float32) uint64 { return uint64(xxh32HashValues(flat(m))) }},
+	}
+
+	// The hash rounds to PrecisionDigits decimal places, so a nudge within
+	// them is meant to be missed; two places in is past the rounding
+	nudge := 2 * float32(math.Pow10(-int(PrecisionDigits)))
+	last := dimension - 1
+	mutation.Check(t, product, clone, hashes, []mutation.Mutation[[][]float32]{
+		{Name: "nudge the first element", Apply: func(m [][]float32) [][]float32 { m[0][0] += nudge; return m }},
+		{Name: "nudge an inner element", Apply: func(m [][]float32) [][]float32 { m[1][2] -= nudge; return m }},
+		{Name: "nudge the last element", Apply: func(m [][]float32) [][]float32 { m[last][last] += nudge; return m }},
+		{Name: "negate an element", Apply: func(m [][]float32) [][]float32 { m[2][1] = -m[2][1]; return m }},
+		{Name: "scale an element", Apply: func(m [][]float32) [][]float32 { m[3][0] *= 1000; return m }},
+		{Name: "swap two elements of a row", Apply: func(m [][]float32) [][]float32 { m[1][0], m[1][3] = m[1][3], m[1][0]; return m }},
+		{Name: "swap two rows", Apply: func(m [][]float32) [][]float32 { m[0], m[last] = m[last], m[0]; return m }},
+		{Name: "transpose", Apply: func(m [][]float32) [][]float32 {
+			for i := range m {
+				for j := range i {
+					m[i][j], m[j][i] = m[j][i], m[i][j]
+				}
+			}
+			return m
+		}},
+		{Name: "drop the last row", Apply: func(m [][]float32) [][]float32 { return m[:last] }},
+		{Name: "drop the last element", Apply: func(m [][]float32) [][]float32 { m[last] = m[last][:last]; return m }},
+	})
+}
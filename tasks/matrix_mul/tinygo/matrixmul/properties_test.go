@@ -0,0 +1,95 @@
+package matrixmul
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"wasmbench/common"
+)
+
+// propertyConfig checks a property over 1000 generated cases from a fixed
+// seed, so a failure reproduces
+func propertyConfig() *quick.Config {
+	return &quick.Config{MaxCount: 1000, Rand: rand.New(rand.NewSource(1))}
+}
+
+// randomMatrices returns count matrices of a dimension from 1 to 16, size
+// picking it, filled from seed as the task fills its inputs
+func randomMatrices(size uint8, seed uint64, count int) [][][]float32 {
+	rng := common.NewRand(common.GeneratorLCG, seed)
+	matrices := make([][][]float32, count)
+	for i := range matrices {
+		matrices[i] = generateRandomMatrix(int(size%16)+1, &rng)
+	}
+	return matrices
+}
+
+func transpose(matrix [][]float32) [][]float32 {
+	result := createZeroMatrix(len(matrix))
+	for i, row := range matrix {
+		for j, value := range row {
+			result[j][i] = value
+		}
+	}
+	return result
+}
+
+func equalMatrices(a, b [][]float32) bool {
+	return matricesApproximatelyEqual(a, b, 0)
+}
+
+func TestIdentityProperty(t *testing.T) {
+	// A × I = I × A = A exactly: every other term of each sum is zero
+	identity := func(size uint8, seed uint64) bool {
+		a := randomMatrices(size, seed, 1)[0]
+		i := createIdentityMatrix(len(a))
+		return equalMatrices(matrixMultiply(a, i), a) && equalMatrices(matrixMultiply(i, a), a)
+	}
+	if err := quick.Check(identity, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransposeProperty(t *testing.T) {
+	// (A × B)ᵀ = Bᵀ × Aᵀ bit for bit: both sum the same products in the
+	// same order of k
+	transposed := func(size uint8, seed uint64) bool {
+		m := randomMatrices(size, seed, 2)
+		a, b := m[0], m[1]
+		return equalMatrices(transpose(matrixMultiply(a, b)), matrixMultiply(transpose(b), transpose(a)))
+	}
+	if err := quick.Check(transposed, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDistributiveProperty(t *testing.T) {
+	// A × (B + C) matches A × B + A × C to rounding, and every product
+	// passes the row-sum check the task verifies with
+	distributes := func(size uint8, seed uint64) bool {
+		m := randomMatrices(size, seed, 3)
+		a, b, c := m[0], m[1], m[2]
+		n := len(a)
+		sum := createZeroMatrix(n)
+		for i := range sum {
+			for j := range sum[i] {
+				sum[i][j] = b[i][j] + c[i][j]
+			}
+		}
+		left := matrixMultiply(a, sum)
+		ab, ac := matrixMultiply(a, b), matrixMultiply(a, c)
+		right := createZeroMatrix(n)
+		for i := range right {
+			for j := range right[i] {
+				right[i][j] = ab[i][j] + ac[i][j]
+			}
+		}
+
+		fa, fb, fab := flattenMatrix(a), flattenMatrix(b), flattenMatrix(ab)
+		return matricesApproximatelyEqual(left, right, 1e-3) && productRowSumsMatch(&fa, &fb, &fab)
+	}
+	if err := quick.Check(distributes, propertyConfig()); err != nil {
+		t.Error(err)
+	}
+}
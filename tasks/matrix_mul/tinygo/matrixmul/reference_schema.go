@@ -0,0 +1,20 @@
+//go:build !wasm
+
+package matrixmul
+
+import "wasmbench/common/refschema"
+
+// ReferenceSchema is the schema of data/reference_hashes/matrix_mul.json: the
+// categories of its vectors, and the limits get_limits reports, which bound
+// the params of a vector that runs
+func ReferenceSchema() refschema.Schema {
+	return refschema.Schema{
+		Task:       "matrix_mul",
+		Fields:     ParamFields(),
+		Stages:     StageNames,
+		Categories: []string{"boundary", "edge_cases", "errors", "medium_matrices", "runner", "seed_variations", "small_matrices"},
+		Bounds: []refschema.Bound{
+			{Params: []string{"dimension"}, Max: uint64(MaxMatrixDimension), Limit: "max_matrix_dimension"},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package matrixmul
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"wasmbench/common"
+	"wasmbench/common/snapshot"
+)
+
+// TestSnapshots compares each stage's output for a small seed with its
+// golden file in testdata/snapshots, so that a change in generation shows up
+// apart from one in the product, which the final hash cannot tell apart
+func TestSnapshots(t *testing.T) {
+	defer SetOutput(0)
+	const dimension = 6
+
+	// Generation: A and B, each from its own stream of the run's generator
+	for _, generator := range []struct {
+		name string
+		id   uint32
+	}{{"lcg", common.GeneratorLCG}, {"pcg32", common.GeneratorPCG32}} {
+		rng := common.NewRand(generator.id, 42)
+		for stream, name := range []string{"a", "b"} {
+			matrix := flattenMatrix(generateRandomMatrix(dimension, rng.Stream(uint32(stream))))
+			snapshot.Match(t, "input_"+name+"_"+generator.name+".txt", snapshot.Float32Grid(matrix.data, dimension))
+		}
+	}
+
+	// Multiplication: the product, row by row, as the run outputs it
+	SetOutput(1)
+	params := MatrixMulParams{Dimension: dimension, Seed: 42}
+	if RunTask(uintptr(unsafe.Pointer(&params))) == 0 {
+		t.Fatal("run failed")
+	}
+	data, _ := common.RunOutput()
+	product := make([]float32, len(data)/OutputElementSize)
+	for i := range product {
+		product[i] = math.Float32frombits(common.ReadUint32LE(data[i*OutputElementSize:]))
+	}
+	snapshot.Match(t, "product_lcg.txt", snapshot.Float32Grid(product, dimension))
+}
@@ -0,0 +1,9 @@
+// Package suite is the conformance suite of every task: its one test finds
+// each task with a TinyGo module under tasks, as cmd/gentasks does, and runs
+// the task's vectors in data/reference_hashes through its Go implementation
+// with package conformance, reporting each task's pass rate by category. The
+// task packages' own TestCrossImplementationHashMatching runs one task under
+// the same policy from its embedded copy of the file.
+//
+//	cd tasks/suite && go test -v
+package suite
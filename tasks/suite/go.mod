@@ -0,0 +1,18 @@
+module wasmbench/suite
+
+go 1.25.0
+
+// Conformance suite: runs every task package's reference vectors natively
+require (
+	json_parse_wasm v0.0.0
+	mandelbrot_wasm v0.0.0
+	matrix_mul_wasm v0.0.0
+	wasmbench/common v0.0.0
+)
+
+replace (
+	json_parse_wasm => ../json_parse/tinygo
+	mandelbrot_wasm => ../mandelbrot/tinygo
+	matrix_mul_wasm => ../matrix_mul/tinygo
+	wasmbench/common => ../common
+)
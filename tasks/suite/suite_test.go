@@ -0,0 +1,100 @@
+package suite
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"unsafe"
+
+	"json_parse_wasm/jsonparse"
+	"mandelbrot_wasm/mandelbrot"
+	"matrix_mul_wasm/matrixmul"
+	"wasmbench/common/conformance"
+)
+
+// tasksDir holds a directory per task, and referenceDir its reference file
+const (
+	tasksDir     = ".."
+	referenceDir = "../../data/reference_hashes"
+)
+
+// tasks are the task packages the suite runs, by the name of their directory
+// and reference file; the vectors are read from the file
+var tasks = map[string]conformance.Task{
+	"mandelbrot": {
+		Schema: mandelbrot.ReferenceSchema(),
+		Size:   unsafe.Sizeof(mandelbrot.MandelbrotParams{}),
+		Run:    mandelbrot.RunTaskV2,
+	},
+	"matrix_mul": {
+		Schema:    matrixmul.ReferenceSchema(),
+		Size:      unsafe.Sizeof(matrixmul.MatrixMulParams{}),
+		Run:       matrixmul.RunTaskV2,
+		Tolerance: matrixmul.FloatTolerance,
+		Reference: func(params unsafe.Pointer) []float32 {
+			return matrixmul.ReferenceProduct((*matrixmul.MatrixMulParams)(params))
+		},
+	},
+	"json_parse": {
+		Schema: jsonparse.ReferenceSchema(),
+		Size:   unsafe.Sizeof(jsonparse.JsonParseParams{}),
+		Run:    jsonparse.RunTaskV2,
+	},
+}
+
+// discover returns the name of every task with a TinyGo module or a
+// reference file, in name order
+func discover(t *testing.T) []string {
+	t.Helper()
+	modules, err := filepath.Glob(filepath.Join(tasksDir, "*", "tinygo", "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(referenceDir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, module := range modules {
+		names = append(names, filepath.Base(filepath.Dir(filepath.Dir(module))))
+	}
+	for _, file := range files {
+		names = append(names, filepath.Base(file[:len(file)-len(".json")]))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// TestConformance runs every task's reference vectors. A task found in the
+// tree but missing from the suite fails it, so a new task is not left
+// unchecked.
+func TestConformance(t *testing.T) {
+	names := discover(t)
+	if len(names) == 0 {
+		t.Fatalf("no <task>/tinygo modules under %s", tasksDir)
+	}
+	var run []conformance.Task
+	for _, name := range names {
+		task, ok := tasks[name]
+		if !ok {
+			t.Errorf("task %s has a TinyGo module or reference file, but no entry in the suite", name)
+			continue
+		}
+		task.Name = name
+		task.Source = filepath.Join(referenceDir, name+".json")
+		data, err := os.ReadFile(task.Source)
+		if err != nil && os.Getenv(conformance.ReferenceHashesEnv) == "" {
+			t.Errorf("task %s: %v", name, err)
+			continue
+		}
+		task.Vectors = data
+		run = append(run, task)
+	}
+	for name := range tasks {
+		if !slices.Contains(names, name) {
+			t.Errorf("the suite runs task %s, which has no TinyGo module or reference file", name)
+		}
+	}
+	conformance.Run(t, run...)
+}
@@ -116,6 +116,19 @@ export function assertBenchmarkResult(result, expectedHash = null, options = {})
     }
 }
 
+/**
+ * Find the first stage whose checkpoint hash differs between two runs
+ * @param {Object|null} stagesA - Stage hashes of one run, in stage order
+ * @param {Object|null} stagesB - Stage hashes of the other run
+ * @returns {string|null} Name of the first stage both recorded with different hashes, or null
+ */
+export function findDivergentStage(stagesA, stagesB) {
+    if (!stagesA || !stagesB) {
+        return null;
+    }
+    return Object.keys(stagesA).find(stage => stage in stagesB && stagesA[stage] !== stagesB[stage]) ?? null;
+}
+
 /**
  * Assert cross-language consistency between two benchmark results
  * @param {Object} rustResult - Rust execution result
@@ -143,9 +156,11 @@ export function assertCrossLanguageConsistency(rustResult, tinygoResult, task) {
 
     if (rustResult.success && tinygoResult.success) {
         // Hash consistency is critical for algorithm correctness
+        const stage = findDivergentStage(rustResult.stageHashes, tinygoResult.stageHashes);
         expect(
             rustResult.resultHash,
-            `Cross-language hash consistency failed for ${task}. Rust: ${rustResult.resultHash}, TinyGo: ${tinygoResult.resultHash}`
+            `Cross-language hash consistency failed for ${task}. Rust: ${rustResult.resultHash}, TinyGo: ${tinygoResult.resultHash}` +
+                (stage ? ` (first divergent stage: ${stage})` : '')
         ).toBe(tinygoResult.resultHash);
 
         // Both should produce valid timing data
@@ -164,5 +179,6 @@ export function assertCrossLanguageConsistency(rustResult, tinygoResult, task) {
 
 export default {
     assertBenchmarkResult,
-    assertCrossLanguageConsistency
+    assertCrossLanguageConsistency,
+    findDivergentStage
 };